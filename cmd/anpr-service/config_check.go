@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/storage"
+)
+
+const configUsage = "usage: anpr-service config check"
+
+// runConfig выполняет команду anpr-service config check: проверяет конфигурацию и настройки хранилища
+// фото без подключения к БД и печатает все найденные ошибки. Код 1 — в конфигурации есть ошибки.
+func runConfig(args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	if _, problems := loadConfig(); len(problems) > 0 {
+		printProblems(os.Stderr, problems)
+		return 1
+	}
+	fmt.Println("config OK")
+	return 0
+}
+
+// loadConfig загружает конфигурацию и проверяет настройки хранилища фото; возвращает все найденные ошибки
+func loadConfig() (*config.Config, []string) {
+	cfg, err := config.Load()
+	var problems []string
+	var validationErr *config.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problems = append(problems, validationErr.Problems...)
+	case err != nil:
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, storage.CheckEnv()...)
+	return cfg, problems
+}
+
+func printProblems(w io.Writer, problems []string) {
+	fmt.Fprintf(w, "invalid config, %d problem(s):\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  - %s\n", problem)
+	}
+}
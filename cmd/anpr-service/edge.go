@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/edge"
+	"anpr-service/internal/lifecycle"
+)
+
+// runEdge запускает шлюз: приём запросов камер в очередь и их пересылку центральному сервису. При
+// RUN_MODE=relay очередь хранится файлами в RELAY_QUEUE_DIR, при STORAGE_PROFILE=edge — в SQLite.
+// Возвращает код завершения.
+func runEdge(cfg *config.Config, log zerolog.Logger) int {
+	profile, path := config.StorageProfileEdge, cfg.Edge.SQLitePath
+	if cfg.RunMode == config.RunModeRelay {
+		profile, path = config.RunModeRelay, cfg.Edge.QueueDir
+	}
+
+	var (
+		queue edge.Queue
+		err   error
+	)
+	if profile == config.RunModeRelay {
+		queue, err = edge.OpenDirQueue(path)
+	} else {
+		queue, err = edge.OpenSpool(path)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to open edge spool")
+		return 1
+	}
+	defer func() {
+		if err := queue.Close(); err != nil {
+			log.Warn().Err(err).Msg("failed to close edge spool")
+		}
+	}()
+
+	forwarder := edge.NewForwarder(queue, cfg.Edge, clock.System(), log)
+	workers := lifecycle.New(log)
+	workers.Go("edge_forwarder", forwarder.Run)
+
+	addr := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: edge.NewRouter(queue, forwarder, profile, cfg.Ingest.MaxBodyBytes, cfg.Environment, clock.System(), log),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+	log.Info().
+		Str("addr", addr).
+		Str("mode", profile).
+		Str("spool", path).
+		Str("device_id", cfg.Edge.DeviceID).
+		Msg("starting ANPR edge gateway")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	code := 0
+	select {
+	case <-quit:
+	case err := <-serveErr:
+		log.Error().Err(err).Msg("failed to start server")
+		code = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("server forced to shutdown")
+	}
+	if err := workers.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("background workers forced to stop")
+	}
+	log.Info().Msg("edge gateway exited")
+	return code
+}
@@ -4,52 +4,179 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
-	"time"
+
+	"github.com/rs/zerolog"
 
 	"anpr-service/internal/auth"
+	"anpr-service/internal/buildinfo"
+	"anpr-service/internal/client/roles"
+	"anpr-service/internal/client/servicetoken"
+	"anpr-service/internal/clock"
 	"anpr-service/internal/config"
 	"anpr-service/internal/db"
+	"anpr-service/internal/eventbus"
+	"anpr-service/internal/ftpingest"
+	"anpr-service/internal/hikconnect"
 	httphandler "anpr-service/internal/http"
 	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/leader"
+	"anpr-service/internal/lifecycle"
 	"anpr-service/internal/logger"
+	"anpr-service/internal/mailer"
+	"anpr-service/internal/mqtt"
 	"anpr-service/internal/repository"
 	"anpr-service/internal/service"
 	"anpr-service/internal/storage"
+	"anpr-service/internal/telegram"
+	"anpr-service/internal/tracing"
+	"anpr-service/internal/weather"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:]))
+	}
+
+	cfg, problems := loadConfig()
+	if len(problems) > 0 {
+		printProblems(os.Stderr, problems)
 		os.Exit(1)
 	}
 
 	appLogger := logger.New(cfg.Environment)
+	logBuildInfo(appLogger, cfg)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, appLogger, os.Args[2:]))
+	}
+
+	// Шлюз полигона без PostgreSQL: только приём в очередь и пересылка центральному сервису
+	if cfg.RunMode == config.RunModeRelay || cfg.StorageProfile == config.StorageProfileEdge {
+		os.Exit(runEdge(cfg, appLogger))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, appLogger)
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to initialize tracing")
+	}
 
 	database, err := db.New(cfg, appLogger)
 	if err != nil {
 		appLogger.Fatal().Err(err).Msg("failed to connect database")
 	}
 
-	anprRepo := repository.NewANPRRepository(database)
-	anprService := service.NewANPRService(anprRepo, appLogger)
+	bus, err := eventbus.New(cfg.EventBus, appLogger)
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to initialize event bus")
+	}
+	defer func() {
+		if err := bus.Close(); err != nil {
+			appLogger.Warn().Err(err).Msg("failed to close event bus")
+		}
+	}()
+
+	anprRepo := repository.NewANPRRepository(database, clock.System(), idgen.Random())
+	anprService := service.NewANPRService(anprRepo, bus, cfg, appLogger, clock.System(), idgen.Random())
+	if sqlDB, err := database.DB(); err == nil {
+		anprService.UseDBStats(sqlDB)
+	}
+
+	// Транспорт подрядчиков из roles-сервиса вместо таблицы vehicles общей БД (VEHICLES_SOURCE=roles)
+	if cfg.Roles.VehicleSource == config.VehicleSourceRoles {
+		tokens := servicetoken.NewSource(cfg.Auth.ServiceTokenURL, cfg.Auth.ServiceClientID, cfg.Auth.ServiceClientSecret, cfg.Roles.Timeout, clock.System())
+		vehicles := roles.NewVehicles(roles.NewClient(cfg.Roles.URL, tokens, cfg.Roles.Timeout), roles.VehicleOptions{
+			CacheTTL:         cfg.Roles.VehicleCacheTTL,
+			BreakerThreshold: cfg.Roles.BreakerThreshold,
+			BreakerCooldown:  cfg.Roles.BreakerCooldown,
+		}, clock.System())
+		anprService.UseVehicleDirectory(vehicles)
+		appLogger.Info().Str("url", cfg.Roles.URL).Msg("vehicle lookups use roles service")
+	}
 
-	// Initialize R2 client (optional, won't fail if not configured)
-	r2Client, err := storage.NewR2ClientFromEnv()
+	// Основное хранилище фото по STORAGE_BACKEND (R2, S3, MinIO или локальный каталог); без него фото не загружаются
+	primaryStore, err := storage.NewPrimaryFromEnv()
 	if err != nil && !errors.Is(err, storage.ErrNotConfigured) {
-		appLogger.Fatal().Err(err).Msg("failed to initialize R2 client")
+		appLogger.Fatal().Err(err).Msg("failed to initialize photo storage")
 	}
 	if err != nil {
-		appLogger.Warn().Msg("R2 storage not configured, photo uploads will be disabled")
+		appLogger.Warn().Str("backend", storage.BackendFromEnv()).Msg("photo storage not configured, photo uploads will be disabled")
+	}
+
+	// Резервное хранилище фото (локальный диск или второй бакет) на время сбоев основного
+	var photoStore *storage.FailoverStore
+	if primaryStore != nil {
+		secondary, err := storage.NewSecondaryFromEnv()
+		if err != nil && !errors.Is(err, storage.ErrNotConfigured) {
+			appLogger.Fatal().Err(err).Msg("failed to initialize secondary storage")
+		}
+		photoStore = storage.NewFailoverStore(primaryStore, secondary, storage.FailoverOptionsFromEnv(), appLogger)
+		anprService.UsePayloadArchive(photoStore)
+	} else if cfg.Ingest.RawPayloadStorage == config.RawPayloadStorageObject {
+		appLogger.Warn().Msg("INGEST_RAW_PAYLOAD_STORAGE=storage requires photo storage, raw payloads will be kept in the database")
 	}
 
+	// Ночная выгрузка событий для аналитиков: отдельный бакет DATALAKE_BUCKET или бакет фото
+	if cfg.DataLake.Enabled {
+		dataLake, err := storage.NewDataLakeFromEnv()
+		switch {
+		case err == nil:
+			anprService.UseDataLake(dataLake)
+		case !errors.Is(err, storage.ErrNotConfigured):
+			appLogger.Fatal().Err(err).Msg("failed to initialize data lake storage")
+		case photoStore != nil:
+			anprService.UseDataLake(photoStore)
+		default:
+			appLogger.Warn().Msg("DATALAKE_EXPORT_ENABLED requires DATALAKE_BUCKET or photo storage, export will fail")
+		}
+	}
+
+	// Выгрузки сохранённых поисков по расписанию: в Telegram тем же ботом, на почту через SMTP_HOST
+	var reportTelegram service.ReportTelegram
+	if cfg.Telegram.BotToken != "" {
+		reportTelegram = telegram.NewClient(cfg.Telegram.APIURL, cfg.Telegram.BotToken, cfg.Telegram.Timeout)
+	}
+	var reportMailer service.ReportMailer
+	if cfg.Email.SMTPHost != "" {
+		reportMailer = mailer.NewClient(cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.Username, cfg.Email.Password, cfg.Email.From, cfg.Email.Timeout)
+	}
+	anprService.UseReportDelivery(reportTelegram, reportMailer)
+
 	tokenParser := auth.NewParser(cfg.Auth.AccessSecret)
 
-	handler := httphandler.NewHandler(anprService, cfg, appLogger, r2Client)
+	// В режиме async события сохраняются пулом воркеров, а камера получает 202 сразу
+	var ingestQueue *service.IngestQueue
+	if cfg.Ingest.Mode == config.IngestModeAsync {
+		ingestQueue = service.NewIngestQueue(anprService, cfg.Ingest.QueueSize, cfg.Ingest.QueueWorkers, appLogger)
+		ingestQueue.Start()
+	}
+
+	// Периодические задачи при нескольких репликах выполняются только на лидере
+	var elector *leader.Elector
+	if cfg.Leader.Enabled {
+		sqlDB, err := database.DB()
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to get database handle for leader election")
+		}
+		elector = leader.New(sqlDB, leader.Options{
+			LockKey:       cfg.Leader.LockKey,
+			RetryInterval: cfg.Leader.RetryInterval,
+			CheckInterval: cfg.Leader.CheckInterval,
+		}, appLogger)
+	}
+
+	jobScheduler, err := anprService.NewScheduler()
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to initialize job scheduler")
+	}
+
+	handler := httphandler.NewHandler(anprService, cfg, appLogger, photoStore, ingestQueue, elector)
 	authMiddleware := middleware.Auth(tokenParser)
 	router := httphandler.NewRouter(handler, authMiddleware, cfg.Environment, database)
 
@@ -68,18 +195,165 @@ func main() {
 		}
 	}()
 
+	// Фоновые воркеры: при остановке перестают брать новую работу и доделывают начатую
+	workers := lifecycle.New(appLogger)
+	if elector != nil {
+		workers.Go("leader_election", elector.Run)
+	}
+	// leaderJob запускает задачу, общую для всех реплик, только на лидере
+	leaderJob := func(name string, job func(context.Context)) {
+		workers.Go(name, func(ctx context.Context) {
+			elector.RunWhileLeader(ctx, name, job)
+		})
+	}
+	// Очистка, обслуживание секций, выгрузка белого списка и т. п. — по расписанию планировщика
+	leaderJob("scheduler", jobScheduler.Run)
+	// Резервное хранилище фото и кэш списков у каждой реплики свои
+	workers.Go("photo_replication", photoStore.RunReplication)
+	workers.Go("list_cache_refresh", func(ctx context.Context) {
+		anprService.RunListCacheRefresh(ctx, cfg.Lists.CacheRefreshInterval)
+	})
+	// Счётчики использования копятся в памяти каждой реплики и сбрасываются ею же
+	workers.Go("usage_metering", anprService.RunUsageMetering)
+
+	// Изменения app.env применяются без перезапуска для настроек из config.ReloadableKeys
+	anprService.WatchConfig(workers.Context())
+
+	// Приём выгрузки камер по FTP из каталога, общего с FTP-сервером
+	if cfg.Ingest.FTPDir != "" {
+		watcher := ftpingest.NewWatcher(ftpingest.Options{
+			Dir:          cfg.Ingest.FTPDir,
+			PollInterval: cfg.Ingest.FTPPollInterval,
+			Settle:       cfg.Ingest.FTPSettle,
+			OrphanAge:    cfg.Ingest.FTPOrphanAge,
+		}, handler.IngestFTPUpload, appLogger)
+		// Каталог общий для реплик: файлы разбирает только лидер
+		leaderJob("ftp_ingest", watcher.Run)
+	}
+
+	// Приём тревог камер за NAT из очереди сообщений Hik-Connect
+	if cfg.Camera.HikConnectAppKey != "" {
+		subscriber := hikconnect.NewSubscriber(hikconnect.Options{
+			Domain:       cfg.Camera.HikConnect,
+			AppKey:       cfg.Camera.HikConnectAppKey,
+			SecretKey:    cfg.Camera.HikConnectSecretKey,
+			PollInterval: cfg.Camera.HikConnectPollInterval,
+		}, handler.IngestHikConnectMessage, handler.DeadLetterHikConnectMessage, appLogger)
+		// Очередь одна на приложение: её разбирает только лидер
+		leaderJob("hik_connect", subscriber.Run)
+	}
+
+	// Вебхуки: события из шины ставятся в очередь доставки, отправка — фоновым воркером
+	if cfg.Webhooks.Enabled {
+		unsubscribe, err := bus.Subscribe(eventbus.TopicEventCreated, anprService.EnqueueWebhooks)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to subscribe webhooks to event bus")
+		}
+		defer unsubscribe()
+		workers.Go("webhook_delivery", func(ctx context.Context) {
+			anprService.RunWebhookDelivery(ctx, cfg.Webhooks.PollInterval)
+		})
+	}
+
+	// MQTT: компактные сообщения о событиях для SCADA полигона, через outbox как и вебхуки
+	if cfg.MQTT.BrokerURL != "" {
+		publisher, err := mqtt.NewPublisher(cfg.MQTT, appLogger)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to initialize mqtt publisher")
+		}
+		defer publisher.Close()
+		unsubscribe, err := bus.Subscribe(eventbus.TopicEventCreated, anprService.EnqueueMQTT)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to subscribe mqtt publisher to event bus")
+		}
+		defer unsubscribe()
+		workers.Go("mqtt_relay", func(ctx context.Context) {
+			anprService.RunMQTTRelay(ctx, cfg.MQTT.PollInterval, publisher)
+		})
+	}
+
+	// Уведомления в Telegram: диспетчерам (чёрный список, перегруз, молчащие камеры),
+	// инспекторам об отслеживаемых номерах и ночные сводки подрядчикам
+	if cfg.Telegram.BotToken != "" {
+		unsubscribe, err := bus.Subscribe(eventbus.TopicEventCreated, anprService.NotifyTelegram)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to subscribe telegram notifier to event bus")
+		}
+		defer unsubscribe()
+		unsubscribeWatches, err := bus.Subscribe(eventbus.TopicEventCreated, anprService.NotifyPlateWatches)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to subscribe plate watches to event bus")
+		}
+		defer unsubscribeWatches()
+		leaderJob("camera_offline_notifier", func(ctx context.Context) {
+			anprService.RunCameraOfflineNotifier(ctx, cfg.Telegram.CameraCheckInterval)
+		})
+		leaderJob("nightly_summaries", anprService.RunNightlySummaries)
+		telegramClient := telegram.NewClient(cfg.Telegram.APIURL, cfg.Telegram.BotToken, cfg.Telegram.Timeout)
+		workers.Go("telegram_relay", func(ctx context.Context) {
+			anprService.RunTelegramRelay(ctx, telegramClient)
+		})
+	}
+
+	// Погода на полигонах для событий и отчётов
+	if cfg.Weather.Enabled {
+		provider := weather.NewClient(cfg.Weather.APIURL, cfg.Weather.Timeout)
+		leaderJob("weather_sync", func(ctx context.Context) {
+			anprService.RunWeatherSync(ctx, provider)
+		})
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	appLogger.Info().Msg("shutting down server")
+	appLogger.Info().Dur("timeout", cfg.HTTP.ShutdownTimeout).Msg("shutting down server")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Все этапы остановки укладываются в общий SHUTDOWN_TIMEOUT
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 
+	// Сначала перестаём принимать запросы и дожидаемся начатых
 	if err := srv.Shutdown(ctx); err != nil {
 		appLogger.Error().Err(err).Msg("server forced to shutdown")
 	}
 
+	// Дожидаемся сохранения событий, уже принятых с ответом 202
+	if err := ingestQueue.Close(ctx); err != nil {
+		appLogger.Error().Err(err).Msg("failed to drain ingest queue")
+	}
+
+	// Воркеры доделывают начатую пачку (outbox, файл FTP, запуск задачи) и выходят
+	if err := workers.Shutdown(ctx); err != nil {
+		appLogger.Error().Err(err).Msg("background workers forced to stop")
+	}
+
+	// Ручные запуски задач выполняются вне воркеров
+	if err := jobScheduler.Wait(ctx); err != nil {
+		appLogger.Error().Err(err).Msg("job runs interrupted")
+	}
+
+	// Отправляем накопленные спаны до выхода
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.Warn().Err(err).Msg("failed to flush traces")
+	}
+
 	appLogger.Info().Msg("server exited")
 }
+
+// logBuildInfo пишет в лог при старте сборку и включённые возможности — то же, что отдаёт GET /version
+func logBuildInfo(log zerolog.Logger, cfg *config.Config) {
+	info := buildinfo.Get()
+	features := cfg.Features()
+	enabled := slices.DeleteFunc(slices.Sorted(maps.Keys(features)), func(name string) bool { return !features[name] })
+	log.Info().
+		Str("git_sha", info.GitSHA).
+		Str("build_time", info.BuildTime).
+		Str("go_version", info.GoVersion).
+		Bool("modified", info.Modified).
+		Str("environment", cfg.Environment).
+		Str("run_mode", cfg.RunMode).
+		Str("storage_profile", cfg.StorageProfile).
+		Strs("features", enabled).
+		Msg("anpr-service build")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/db"
+)
+
+const migrateUsage = "usage: anpr-service migrate up|down|status"
+
+// runMigrate выполняет команду anpr-service migrate up|down|status и возвращает код завершения
+func runMigrate(cfg *config.Config, log zerolog.Logger, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	database, err := db.Open(cfg, log)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to connect database")
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		err = db.MigrateUp(ctx, database, log)
+	case "down":
+		err = db.MigrateDown(ctx, database, log)
+	case "status":
+		err = printMigrationStatus(ctx, database)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		log.Error().Err(err).Str("command", args[0]).Msg("migration command failed")
+		return 1
+	}
+	return 0
+}
+
+func printMigrationStatus(ctx context.Context, database *gorm.DB) error {
+	states, err := db.MigrationStatus(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, state := range states {
+		appliedAt := "pending"
+		if state.AppliedAt != nil {
+			appliedAt = state.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", state.Version, state.Name, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// Распределения номеров в потоке событий
+const (
+	distUniform = "uniform" // все номера пула встречаются одинаково часто
+	distZipf    = "zipf"    // несколько «рабочих» машин дают основную часть проездов, как на полигоне
+)
+
+// Особенности прошивок камер, которые можно включить флагом -quirks
+const (
+	quirkLocalTime      = "local-time"       // dateTime без смещения, в поясе камеры
+	quirkXMLField       = "xml-field"        // XML обычным полем формы, а не файлом
+	quirkDeviceIDOnly   = "device-id-only"   // пустой channelID, камера определяется по deviceID
+	quirkLowercasePlate = "lowercase-plate"  // номер в нижнем регистре
+	quirkSeparators     = "plate-separators" // номер с пробелами и дефисами
+	quirkGATOnly        = "gat-only"         // тип ТС только кодом GAT, без текстового vehicleType
+	quirkNoPictures     = "no-pictures"      // уведомление без JPEG-частей
+	quirkUnknownDir     = "unknown-direction"
+)
+
+var knownQuirks = []string{
+	quirkLocalTime, quirkXMLField, quirkDeviceIDOnly, quirkLowercasePlate,
+	quirkSeparators, quirkGATOnly, quirkNoPictures, quirkUnknownDir,
+}
+
+// Буквы, совпадающие в латинице и кириллице: такие номера камеры распознают чаще всего
+const plateLetters = "ABCEHKMOPTXY"
+
+var (
+	vehicleTypes  = []string{"truck", "car", "bus", "vehicle", "SUVMPV"}
+	gatTypes      = []string{"K33", "H11", "H21", "K31"}
+	vehicleColors = []string{"white", "blue", "gray", "red", "yellow", "black"}
+	directions    = []string{"forward", "reverse"}
+)
+
+// simEvent — одно сгенерированное событие, из которого собираются запросы всех форматов
+type simEvent struct {
+	CameraID    string
+	DeviceName  string
+	Plate       string
+	Confidence  float64
+	Direction   string
+	Lane        int
+	VehicleType string
+	GATType     string
+	Color       string
+	Speed       float64
+	Time        time.Time
+	Quirks      map[string]bool
+}
+
+// generator выдаёт события по заданному распределению номеров. Не безопасен для параллельного
+// использования: события генерирует один цикл, отправляют воркеры.
+type generator struct {
+	rnd          *rand.Rand
+	plates       []string
+	zipf         *rand.Zipf
+	cameras      []string
+	unknownRatio float64
+	quirks       map[string]bool
+	location     *time.Location
+	skew         time.Duration
+}
+
+func newGenerator(opts options) (*generator, error) {
+	rnd := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+
+	quirks := map[string]bool{}
+	for _, q := range opts.Quirks {
+		quirks[q] = true
+	}
+
+	loc, err := time.LoadLocation(opts.CameraTimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid camera time zone %q: %w", opts.CameraTimeZone, err)
+	}
+
+	g := &generator{
+		rnd:          rnd,
+		plates:       make([]string, 0, opts.Plates),
+		unknownRatio: opts.UnknownRatio,
+		quirks:       quirks,
+		location:     loc,
+		skew:         opts.ClockSkew,
+	}
+
+	seen := map[string]bool{}
+	for len(g.plates) < opts.Plates {
+		plate := g.randomPlate()
+		if !seen[plate] {
+			seen[plate] = true
+			g.plates = append(g.plates, plate)
+		}
+	}
+
+	if opts.Distribution == distZipf && opts.Plates > 1 {
+		g.zipf = rand.NewZipf(rnd, opts.ZipfS, 1, uint64(opts.Plates-1))
+	}
+
+	if len(opts.CameraIDs) > 0 {
+		g.cameras = opts.CameraIDs
+	} else {
+		for i := 1; i <= opts.Cameras; i++ {
+			g.cameras = append(g.cameras, fmt.Sprintf("sim-camera-%02d", i))
+		}
+	}
+	return g, nil
+}
+
+// randomPlate возвращает номер в формате 123ABC02 (цифры, буквы, код региона)
+func (g *generator) randomPlate() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%03d", g.rnd.IntN(1000))
+	for i := 0; i < 3; i++ {
+		b.WriteByte(plateLetters[g.rnd.IntN(len(plateLetters))])
+	}
+	fmt.Fprintf(&b, "%02d", 1+g.rnd.IntN(20))
+	return b.String()
+}
+
+func (g *generator) nextPlate() string {
+	if g.unknownRatio > 0 && g.rnd.Float64() < g.unknownRatio {
+		return g.randomPlate()
+	}
+	if g.zipf != nil {
+		return g.plates[g.zipf.Uint64()]
+	}
+	return g.plates[g.rnd.IntN(len(g.plates))]
+}
+
+func (g *generator) next(now time.Time) simEvent {
+	plate := g.nextPlate()
+	if g.quirks[quirkSeparators] {
+		plate = plate[:3] + " " + plate[3:6] + "-" + plate[6:]
+	}
+	if g.quirks[quirkLowercasePlate] {
+		plate = strings.ToLower(plate)
+	}
+
+	direction := directions[g.rnd.IntN(len(directions))]
+	if g.quirks[quirkUnknownDir] {
+		direction = "unknown"
+	}
+
+	camera := g.cameras[g.rnd.IntN(len(g.cameras))]
+	return simEvent{
+		CameraID:    camera,
+		DeviceName:  "DS-TCG405-E",
+		Plate:       plate,
+		Confidence:  70 + g.rnd.Float64()*30,
+		Direction:   direction,
+		Lane:        1 + g.rnd.IntN(2),
+		VehicleType: vehicleTypes[g.rnd.IntN(len(vehicleTypes))],
+		GATType:     gatTypes[g.rnd.IntN(len(gatTypes))],
+		Color:       vehicleColors[g.rnd.IntN(len(vehicleColors))],
+		Speed:       float64(5 + g.rnd.IntN(40)),
+		Time:        now.Add(g.skew).In(g.location),
+		Quirks:      g.quirks,
+	}
+}
+
+// hikvisionAlert повторяет структуру EventNotificationAlert, которую камеры Hikvision
+// отправляют в режиме HTTP listening
+type hikvisionAlert struct {
+	XMLName          xml.Name `xml:"EventNotificationAlert"`
+	Version          string   `xml:"version,attr"`
+	XMLNS            string   `xml:"xmlns,attr"`
+	IPAddress        string   `xml:"ipAddress"`
+	PortNo           int      `xml:"portNo"`
+	ProtocolType     string   `xml:"protocolType"`
+	MacAddress       string   `xml:"macAddress"`
+	ChannelID        string   `xml:"channelID"`
+	DateTime         string   `xml:"dateTime"`
+	ActivePostCount  int      `xml:"activePostCount"`
+	EventType        string   `xml:"eventType"`
+	EventState       string   `xml:"eventState"`
+	EventDescription string   `xml:"eventDescription"`
+	DeviceID         string   `xml:"deviceID,omitempty"`
+	DeviceName       string   `xml:"deviceName,omitempty"`
+	ANPR             struct {
+		Country         string  `xml:"country"`
+		LicensePlate    string  `xml:"licensePlate"`
+		Line            int     `xml:"line"`
+		Direction       string  `xml:"direction"`
+		ConfidenceLevel float64 `xml:"confidenceLevel"`
+		PlateType       string  `xml:"plateType"`
+		PlateColor      string  `xml:"plateColor"`
+		VehicleType     string  `xml:"vehicleType,omitempty"`
+		LaneNo          int     `xml:"laneNo"`
+		Speed           float64 `xml:"speed"`
+	} `xml:"ANPR"`
+	VehicleInfo struct {
+		Index            int    `xml:"index"`
+		Color            string `xml:"color"`
+		VehicleLogoRecog int    `xml:"vehicleLogoRecog"`
+		VehileModel      int    `xml:"vehileModel"`
+	} `xml:"vehicleInfo"`
+	VehicleGATInfo *struct {
+		VehicleTypeByGAT string `xml:"vehicleTypeByGAT"`
+	} `xml:"VehicleGATInfo,omitempty"`
+}
+
+func (e simEvent) hikvisionXML() ([]byte, error) {
+	alert := hikvisionAlert{
+		Version:          "2.0",
+		XMLNS:            "http://www.hikvision.com/ver20/XMLSchema",
+		IPAddress:        "192.0.2.10",
+		PortNo:           80,
+		ProtocolType:     "HTTP",
+		MacAddress:       "00:00:5e:00:53:01",
+		ChannelID:        e.CameraID,
+		ActivePostCount:  1,
+		EventType:        "ANPR",
+		EventState:       "active",
+		EventDescription: "ANPR",
+		DeviceName:       e.DeviceName,
+	}
+	if e.Quirks[quirkDeviceIDOnly] {
+		alert.ChannelID = ""
+		alert.DeviceID = e.CameraID
+	}
+	if e.Quirks[quirkLocalTime] {
+		alert.DateTime = e.Time.Format("2006-01-02T15:04:05")
+	} else {
+		alert.DateTime = e.Time.Format("2006-01-02T15:04:05-07:00")
+	}
+
+	alert.ANPR.Country = "KZ"
+	alert.ANPR.LicensePlate = e.Plate
+	alert.ANPR.Line = 1
+	alert.ANPR.Direction = e.Direction
+	alert.ANPR.ConfidenceLevel = float64(int(e.Confidence*10)) / 10
+	alert.ANPR.PlateType = "unknown"
+	alert.ANPR.PlateColor = "white"
+	alert.ANPR.LaneNo = e.Lane
+	alert.ANPR.Speed = e.Speed
+	alert.VehicleInfo.Index = 1
+	alert.VehicleInfo.Color = e.Color
+	if e.Quirks[quirkGATOnly] {
+		alert.VehicleGATInfo = &struct {
+			VehicleTypeByGAT string `xml:"vehicleTypeByGAT"`
+		}{VehicleTypeByGAT: e.GATType}
+	} else {
+		alert.ANPR.VehicleType = e.VehicleType
+	}
+
+	body, err := xml.MarshalIndent(alert, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// jsonEvent — тело запроса POST /api/v1/anpr/events
+func (e simEvent) jsonEvent() map[string]interface{} {
+	vehicleType := e.VehicleType
+	if e.Quirks[quirkGATOnly] {
+		vehicleType = e.GATType
+	}
+	// JSON-интеграции присылают направление в терминах сервиса, а не камеры
+	direction := e.Direction
+	switch direction {
+	case "forward":
+		direction = "entry"
+	case "reverse":
+		direction = "exit"
+	}
+	return map[string]interface{}{
+		"camera_id":    e.CameraID,
+		"camera_model": e.DeviceName,
+		"plate":        e.Plate,
+		"confidence":   float64(int(e.Confidence*10)) / 10,
+		"direction":    direction,
+		"lane":         e.Lane,
+		"event_time":   e.Time.Format(time.RFC3339),
+		"vehicle": map[string]interface{}{
+			"color":   e.Color,
+			"type":    vehicleType,
+			"country": "KZ",
+			"speed":   e.Speed,
+		},
+	}
+}
+
+// snapshotJPEG рисует кадр размером width×height: однотонный фон и светлая полоса на месте номера.
+// Содержимое не важно для сервиса, но размер влияет на нагрузку на загрузку фото.
+func snapshotJPEG(width, height int, seed uint64) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	background := color.RGBA{R: uint8(seed), G: uint8(seed >> 8), B: uint8(seed >> 16), A: 255}
+	plate := image.Rect(width*2/5, height*3/4, width*3/5, height*3/4+height/12+1)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (image.Point{X: x, Y: y}).In(plate) {
+				img.Set(x, y, color.White)
+				continue
+			}
+			img.Set(x, y, background)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
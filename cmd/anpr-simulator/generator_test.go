@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testOptions() options {
+	return options{
+		Cameras:        2,
+		CameraTimeZone: "Asia/Almaty",
+		Plates:         50,
+		Distribution:   distZipf,
+		ZipfS:          1.2,
+		Seed:           42,
+	}
+}
+
+func TestGeneratorPlates(t *testing.T) {
+	gen, err := newGenerator(testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := map[string]bool{}
+	for _, p := range gen.plates {
+		pool[p] = true
+	}
+	if len(pool) != 50 {
+		t.Fatalf("pool has %d unique plates, want 50", len(pool))
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		plate := gen.next(time.Now()).Plate
+		if !pool[plate] {
+			t.Fatalf("plate %q is outside the pool with unknown-ratio 0", plate)
+		}
+		counts[plate]++
+	}
+	// Zipf: самый частый номер встречается заметно чаще среднего
+	if counts[gen.plates[0]] < 1000/50*3 {
+		t.Errorf("top plate seen %d times, expected a skewed distribution", counts[gen.plates[0]])
+	}
+}
+
+func TestGeneratorSameSeed(t *testing.T) {
+	a, _ := newGenerator(testOptions())
+	b, _ := newGenerator(testOptions())
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		if ea, eb := a.next(now), b.next(now); ea.Plate != eb.Plate || ea.CameraID != eb.CameraID {
+			t.Fatalf("event %d differs for the same seed: %+v vs %+v", i, ea, eb)
+		}
+	}
+}
+
+func TestHikvisionXMLQuirks(t *testing.T) {
+	opts := testOptions()
+	opts.Quirks = []string{quirkLocalTime, quirkDeviceIDOnly, quirkSeparators, quirkGATOnly}
+	gen, err := newGenerator(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := gen.next(time.Date(2025, 1, 15, 18, 30, 0, 0, time.UTC))
+
+	body, err := event.hikvisionXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		XMLName   xml.Name `xml:"EventNotificationAlert"`
+		ChannelID string   `xml:"channelID"`
+		DeviceID  string   `xml:"deviceID"`
+		DateTime  string   `xml:"dateTime"`
+		ANPR      struct {
+			LicensePlate string `xml:"licensePlate"`
+			VehicleType  string `xml:"vehicleType"`
+		} `xml:"ANPR"`
+		GAT struct {
+			VehicleTypeByGAT string `xml:"vehicleTypeByGAT"`
+		} `xml:"VehicleGATInfo"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		t.Fatalf("generated XML does not parse: %v\n%s", err, body)
+	}
+
+	if parsed.ChannelID != "" || parsed.DeviceID != event.CameraID {
+		t.Errorf("device-id-only: channelID=%q deviceID=%q", parsed.ChannelID, parsed.DeviceID)
+	}
+	// Asia/Almaty: UTC+5, без смещения в строке
+	if parsed.DateTime != "2025-01-15T23:30:00" {
+		t.Errorf("local-time: dateTime = %q", parsed.DateTime)
+	}
+	if !strings.Contains(parsed.ANPR.LicensePlate, " ") || !strings.Contains(parsed.ANPR.LicensePlate, "-") {
+		t.Errorf("plate-separators: plate = %q", parsed.ANPR.LicensePlate)
+	}
+	if parsed.ANPR.VehicleType != "" || parsed.GAT.VehicleTypeByGAT == "" {
+		t.Errorf("gat-only: vehicleType=%q gat=%q", parsed.ANPR.VehicleType, parsed.GAT.VehicleTypeByGAT)
+	}
+}
+
+func TestHikvisionBodyParts(t *testing.T) {
+	gen, err := newGenerator(testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &sender{plateJPEG: []byte("plate"), sceneJPEG: []byte("scene")}
+
+	body, contentType, err := s.hikvisionBody(gen.next(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []string
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, part.FileName()+" "+part.Header.Get("Content-Type"))
+	}
+
+	want := []string{
+		"anpr.xml application/xml",
+		"licensePlatePicture.jpg image/jpeg",
+		"detectionPicture.jpg image/jpeg",
+	}
+	if strings.Join(parts, ";") != strings.Join(want, ";") {
+		t.Fatalf("parts = %v, want %v", parts, want)
+	}
+}
@@ -0,0 +1,259 @@
+// anpr-simulator генерирует события камер (multipart Hikvision с XML и JPEG, JSON и multipart
+// с фото) с заданной частотой и распределением номеров и отправляет их в anpr-service.
+// Используется для нагрузочного тестирования приёма событий и для проверки особенностей
+// новых прошивок камер (флаг -quirks).
+//
+//	go run ./cmd/anpr-simulator -target http://localhost:8082 -rate 20 -duration 5m -mode mixed
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type options struct {
+	Target         string
+	Mode           string
+	Rate           float64
+	Duration       time.Duration
+	Count          int
+	Concurrency    int
+	Timeout        time.Duration
+	Cameras        int
+	CameraIDs      []string
+	CameraTimeZone string
+	Plates         int
+	Distribution   string
+	ZipfS          float64
+	UnknownRatio   float64
+	Quirks         []string
+	ClockSkew      time.Duration
+	PhotoWidth     int
+	PhotoHeight    int
+	Headers        map[string]string
+	Seed           uint64
+	ReportEvery    time.Duration
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func parseOptions(args []string) (options, error) {
+	var (
+		opts      options
+		cameraIDs string
+		quirks    string
+		headers   headerFlags
+	)
+	fs := flag.NewFlagSet("anpr-simulator", flag.ContinueOnError)
+	fs.StringVar(&opts.Target, "target", "http://localhost:8082", "base URL of anpr-service")
+	fs.StringVar(&opts.Mode, "mode", modeHikvision, "request format: "+strings.Join(knownModes, ", "))
+	fs.Float64Var(&opts.Rate, "rate", 5, "events per second")
+	fs.DurationVar(&opts.Duration, "duration", time.Minute, "how long to send events (0 = until -count or Ctrl+C)")
+	fs.IntVar(&opts.Count, "count", 0, "stop after this many events (0 = no limit)")
+	fs.IntVar(&opts.Concurrency, "concurrency", 8, "parallel HTTP requests")
+	fs.DurationVar(&opts.Timeout, "timeout", 10*time.Second, "HTTP request timeout")
+	fs.IntVar(&opts.Cameras, "cameras", 3, "number of simulated cameras (sim-camera-01...)")
+	fs.StringVar(&cameraIDs, "camera-ids", "", "comma-separated camera IDs (overrides -cameras)")
+	fs.StringVar(&opts.CameraTimeZone, "camera-tz", "UTC", "camera time zone for dateTime")
+	fs.IntVar(&opts.Plates, "plates", 200, "size of the plate pool")
+	fs.StringVar(&opts.Distribution, "distribution", distZipf, "plate distribution: uniform, zipf")
+	fs.Float64Var(&opts.ZipfS, "zipf-s", 1.2, "zipf skew (> 1, larger = fewer plates dominate)")
+	fs.Float64Var(&opts.UnknownRatio, "unknown-ratio", 0.05, "share of one-off plates outside the pool")
+	fs.StringVar(&quirks, "quirks", "", "comma-separated firmware quirks: "+strings.Join(knownQuirks, ", "))
+	fs.DurationVar(&opts.ClockSkew, "clock-skew", 0, "shift event time from the current time (camera clock drift)")
+	fs.IntVar(&opts.PhotoWidth, "photo-width", 1280, "JPEG width")
+	fs.IntVar(&opts.PhotoHeight, "photo-height", 720, "JPEG height")
+	fs.Var(&headers, "header", "extra request header \"Name: value\" (repeatable)")
+	fs.Uint64Var(&opts.Seed, "seed", uint64(time.Now().UnixNano()), "random seed (same seed = same plate pool and sequence)")
+	fs.DurationVar(&opts.ReportEvery, "report", 5*time.Second, "progress report interval")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts.CameraIDs = splitList(cameraIDs)
+	opts.Quirks = splitList(quirks)
+	opts.Headers = headers
+	return opts, opts.validate()
+}
+
+func (o options) validate() error {
+	if !slices.Contains(knownModes, o.Mode) {
+		return fmt.Errorf("-mode must be one of %s", strings.Join(knownModes, ", "))
+	}
+	if o.Distribution != distUniform && o.Distribution != distZipf {
+		return fmt.Errorf("-distribution must be %q or %q", distUniform, distZipf)
+	}
+	if o.Rate <= 0 {
+		return errors.New("-rate must be positive")
+	}
+	if o.Concurrency <= 0 {
+		return errors.New("-concurrency must be positive")
+	}
+	if o.Plates <= 0 {
+		return errors.New("-plates must be positive")
+	}
+	if o.Cameras <= 0 && len(o.CameraIDs) == 0 {
+		return errors.New("-cameras must be positive")
+	}
+	if o.ZipfS <= 1 {
+		return errors.New("-zipf-s must be greater than 1")
+	}
+	if o.UnknownRatio < 0 || o.UnknownRatio > 1 {
+		return errors.New("-unknown-ratio must be between 0 and 1")
+	}
+	if o.PhotoWidth <= 0 || o.PhotoHeight <= 0 {
+		return errors.New("-photo-width and -photo-height must be positive")
+	}
+	for _, q := range o.Quirks {
+		if !slices.Contains(knownQuirks, q) {
+			return fmt.Errorf("unknown quirk %q (known: %s)", q, strings.Join(knownQuirks, ", "))
+		}
+	}
+	return nil
+}
+
+func run(ctx context.Context, opts options) error {
+	gen, err := newGenerator(opts)
+	if err != nil {
+		return err
+	}
+	plateJPEG, err := snapshotJPEG(opts.PhotoWidth/4, opts.PhotoHeight/4, opts.Seed)
+	if err != nil {
+		return fmt.Errorf("generate plate picture: %w", err)
+	}
+	sceneJPEG, err := snapshotJPEG(opts.PhotoWidth, opts.PhotoHeight, opts.Seed>>24)
+	if err != nil {
+		return fmt.Errorf("generate scene picture: %w", err)
+	}
+
+	s := &sender{
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        opts.Concurrency,
+				MaxIdleConnsPerHost: opts.Concurrency,
+			},
+		},
+		target:    opts.Target,
+		headers:   opts.Headers,
+		plateJPEG: plateJPEG,
+		sceneJPEG: sceneJPEG,
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	fmt.Printf("sending %s events to %s: %.1f/s, %d cameras, %d plates (%s), quirks: %s\n",
+		opts.Mode, opts.Target, opts.Rate, len(gen.cameras), opts.Plates, opts.Distribution, strings.Join(opts.Quirks, ","))
+
+	type job struct {
+		mode  string
+		event simEvent
+	}
+	jobs := make(chan job, opts.Concurrency)
+	st := newStats()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// Запросы в полёте доотправляются и после остановки генерации
+				st.record(s.send(context.WithoutCancel(ctx), j.mode, j.event))
+			}
+		}()
+	}
+
+	reportTicker := time.NewTicker(opts.ReportEvery)
+	defer reportTicker.Stop()
+	sendTicker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+	defer sendTicker.Stop()
+
+	modes := []string{modeHikvision, modeJSON, modeMultipart}
+	sent := 0
+loop:
+	for opts.Count == 0 || sent < opts.Count {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-reportTicker.C:
+			st.print(os.Stdout, false)
+			continue
+		case now := <-sendTicker.C:
+			mode := opts.Mode
+			if mode == modeMixed {
+				mode = modes[gen.rnd.IntN(len(modes))]
+			}
+			select {
+			case jobs <- job{mode: mode, event: gen.next(now)}:
+				sent++
+			default:
+				// Все воркеры заняты: сервис не успевает, событие считается пропущенным,
+				// иначе фактическая частота незаметно упадёт ниже -rate
+				st.skip()
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	st.print(os.Stdout, true)
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// headerFlags — повторяемый флаг -header "Name: value"
+type headerFlags map[string]string
+
+func (h *headerFlags) String() string {
+	return fmt.Sprint(map[string]string(*h))
+}
+
+func (h *headerFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must be \"Name: value\", got %q", value)
+	}
+	if *h == nil {
+		*h = headerFlags{}
+	}
+	(*h)[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
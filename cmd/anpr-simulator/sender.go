@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Форматы запросов, которые умеет отправлять симулятор
+const (
+	modeHikvision = "hikvision" // multipart с XML и JPEG на /api/v1/anpr/hikvision
+	modeJSON      = "json"      // JSON на /api/v1/anpr/events
+	modeMultipart = "multipart" // поле event + фото photos на /api/v1/anpr/events
+	modeMixed     = "mixed"     // случайная смесь трёх форматов
+)
+
+var knownModes = []string{modeHikvision, modeJSON, modeMultipart, modeMixed}
+
+// sender собирает HTTP-запросы из событий и отправляет их на целевой сервис
+type sender struct {
+	client  *http.Client
+	target  string
+	headers map[string]string
+	// plateJPEG и sceneJPEG — заранее сжатые кадры: генерировать JPEG на каждое событие
+	// дороже, чем его отправка, и упирает симулятор в CPU
+	plateJPEG []byte
+	sceneJPEG []byte
+}
+
+type sendResult struct {
+	Status   int
+	Duration time.Duration
+	Err      error
+}
+
+func (s *sender) send(ctx context.Context, mode string, event simEvent) sendResult {
+	req, err := s.buildRequest(ctx, mode, event)
+	if err != nil {
+		return sendResult{Err: err}
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return sendResult{Duration: time.Since(start), Err: err}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return sendResult{Status: resp.StatusCode, Duration: time.Since(start)}
+}
+
+func (s *sender) buildRequest(ctx context.Context, mode string, event simEvent) (*http.Request, error) {
+	var (
+		path        string
+		body        []byte
+		contentType string
+		err         error
+	)
+	switch mode {
+	case modeHikvision:
+		path = "/api/v1/anpr/hikvision"
+		body, contentType, err = s.hikvisionBody(event)
+	case modeJSON:
+		path = "/api/v1/anpr/events"
+		body, err = json.Marshal(event.jsonEvent())
+		contentType = "application/json"
+	case modeMultipart:
+		path = "/api/v1/anpr/events"
+		body, contentType, err = s.multipartBody(event)
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("build %s request: %w", mode, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.target, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	// Сервис помечает события источником simulator, чтобы их можно было исключить из отчётов
+	req.Header.Set("X-Event-Source", "simulator")
+	return req, nil
+}
+
+// hikvisionBody собирает multipart так же, как камера: anpr.xml и два кадра
+// (номерная пластина и общий план)
+func (s *sender) hikvisionBody(event simEvent) ([]byte, string, error) {
+	xmlBody, err := event.hikvisionXML()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if event.Quirks[quirkXMLField] {
+		if err := w.WriteField("anpr.xml", string(xmlBody)); err != nil {
+			return nil, "", err
+		}
+	} else if err := writePart(w, "anpr.xml", "anpr.xml", "application/xml", xmlBody); err != nil {
+		return nil, "", err
+	}
+	if !event.Quirks[quirkNoPictures] {
+		if err := writePart(w, "licensePlatePicture.jpg", "licensePlatePicture.jpg", "image/jpeg", s.plateJPEG); err != nil {
+			return nil, "", err
+		}
+		if err := writePart(w, "detectionPicture.jpg", "detectionPicture.jpg", "image/jpeg", s.sceneJPEG); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+func (s *sender) multipartBody(event simEvent) ([]byte, string, error) {
+	eventJSON, err := json.Marshal(event.jsonEvent())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.WriteField("event", string(eventJSON)); err != nil {
+		return nil, "", err
+	}
+	if !event.Quirks[quirkNoPictures] {
+		if err := writePart(w, "photos", "event-photo-1.jpg", "image/jpeg", s.sceneJPEG); err != nil {
+			return nil, "", err
+		}
+		if err := writePart(w, "photos", "event-photo-2.jpg", "image/jpeg", s.plateJPEG); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+func writePart(w *multipart.Writer, field, filename, contentType string, data []byte) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, filename))
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats накапливает результаты отправки: коды ответов, ошибки и задержки
+type stats struct {
+	mu        sync.Mutex
+	start     time.Time
+	statuses  map[int]int
+	errors    map[string]int
+	skipped   int
+	latencies []time.Duration
+}
+
+func newStats() *stats {
+	return &stats{
+		start:    time.Now(),
+		statuses: map[int]int{},
+		errors:   map[string]int{},
+	}
+}
+
+func (s *stats) record(r sendResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Err != nil {
+		s.errors[errorKind(r.Err)]++
+		return
+	}
+	s.statuses[r.Status]++
+	s.latencies = append(s.latencies, r.Duration)
+}
+
+func (s *stats) skip() {
+	s.mu.Lock()
+	s.skipped++
+	s.mu.Unlock()
+}
+
+// print выводит сводку; final добавляет разбивку по кодам ответа и ошибкам
+func (s *stats) print(w io.Writer, final bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := len(s.latencies)
+	failed := 0
+	for _, n := range s.errors {
+		failed += n
+	}
+	elapsed := time.Since(s.start)
+	fmt.Fprintf(w, "[%s] responses=%d errors=%d skipped=%d rate=%.1f/s p50=%s p95=%s p99=%s\n",
+		elapsed.Round(time.Second), total, failed, s.skipped,
+		float64(total+failed)/elapsed.Seconds(),
+		percentile(s.latencies, 50), percentile(s.latencies, 95), percentile(s.latencies, 99))
+
+	if !final {
+		return
+	}
+	codes := make([]int, 0, len(s.statuses))
+	for code := range s.statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  HTTP %d: %d\n", code, s.statuses[code])
+	}
+	kinds := make([]string, 0, len(s.errors))
+	for kind := range s.errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "  error %s: %d\n", kind, s.errors[kind])
+	}
+}
+
+// percentile возвращает p-й перцентиль задержек (без изменения исходного среза)
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	idx := int(float64(len(sorted)-1) * p / 100)
+	return sorted[idx].Round(time.Millisecond)
+}
+
+// errorKind сокращает ошибку до вида, пригодного для группировки (без адресов и портов)
+func errorKind(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return "connection refused"
+	case strings.Contains(msg, "Client.Timeout"), strings.Contains(msg, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(msg, "connection reset"):
+		return "connection reset"
+	case strings.Contains(msg, "EOF"):
+		return "EOF"
+	}
+	return msg
+}
@@ -0,0 +1,26 @@
+// Команда openapi-gen записывает спецификацию OpenAPI, построенную по описанию маршрутов
+// internal/http.APIRoutes. Запускается через go generate ./internal/http.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	httphandler "anpr-service/internal/http"
+)
+
+func main() {
+	out := flag.String("out", "openapi.json", "output file")
+	flag.Parse()
+
+	spec, err := httphandler.OpenAPISpec()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "build openapi spec: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "write %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+}
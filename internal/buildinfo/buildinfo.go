@@ -0,0 +1,72 @@
+// Package buildinfo — сведения о сборке для /version и стартового лога. GitSHA и BuildTime задаются при сборке:
+//
+//	go build -ldflags "-X anpr-service/internal/buildinfo.GitSHA=$(git rev-parse HEAD) \
+//	  -X anpr-service/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/anpr-service
+//
+// Без ldflags берутся сведения VCS, которые go build записывает в бинарник сам (vcs.revision, vcs.time).
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Задаются через -ldflags "-X ..."
+var (
+	GitSHA    string
+	BuildTime string
+)
+
+// unknown — значение, если сведений о сборке нет (go run, сборка вне git)
+const unknown = "unknown"
+
+// Info — сведения о сборке
+type Info struct {
+	GitSHA    string `json:"git_sha"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	// Modified — бинарник собран из рабочей копии с незакоммиченными изменениями (известно только из VCS)
+	Modified bool `json:"modified,omitempty"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get возвращает сведения о сборке
+func Get() Info {
+	once.Do(func() {
+		bi, _ := debug.ReadBuildInfo()
+		info = resolve(GitSHA, BuildTime, bi)
+	})
+	return info
+}
+
+func resolve(sha, buildTime string, bi *debug.BuildInfo) Info {
+	result := Info{GitSHA: sha, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if bi != nil {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if result.GitSHA == "" {
+					result.GitSHA = setting.Value
+				}
+			case "vcs.time":
+				if result.BuildTime == "" {
+					result.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				result.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if result.GitSHA == "" {
+		result.GitSHA = unknown
+	}
+	if result.BuildTime == "" {
+		result.BuildTime = unknown
+	}
+	return result
+}
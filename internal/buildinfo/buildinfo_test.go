@@ -0,0 +1,48 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	vcs := &debug.BuildInfo{Settings: []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "4f2c9e1"},
+		{Key: "vcs.time", Value: "2025-01-15T10:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}}
+
+	tests := []struct {
+		name      string
+		sha       string
+		buildTime string
+		bi        *debug.BuildInfo
+		want      Info
+	}{
+		{
+			name:      "ldflags win over vcs",
+			sha:       "a1b2c3d",
+			buildTime: "2025-01-16T08:30:00Z",
+			bi:        vcs,
+			want:      Info{GitSHA: "a1b2c3d", BuildTime: "2025-01-16T08:30:00Z", Modified: true},
+		},
+		{
+			name: "vcs fallback",
+			bi:   vcs,
+			want: Info{GitSHA: "4f2c9e1", BuildTime: "2025-01-15T10:00:00Z", Modified: true},
+		},
+		{
+			name: "nothing known",
+			want: Info{GitSHA: unknown, BuildTime: unknown},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolve(tt.sha, tt.buildTime, tt.bi)
+			got.GoVersion = ""
+			if got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package roles — клиент roles-сервиса SnowOps (организации и транспорт подрядчиков).
+package roles
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenSource выдаёт токен сервиса (реализуется *servicetoken.Source)
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Invalidate()
+}
+
+// Vehicle — транспорт подрядчика в roles-сервисе
+type Vehicle struct {
+	ID           uuid.UUID  `json:"id"`
+	PlateNumber  string     `json:"plate_number"`
+	Brand        string     `json:"brand"`
+	Model        string     `json:"model"`
+	Color        string     `json:"color"`
+	Year         int        `json:"year"`
+	BodyVolumeM3 float64    `json:"body_volume_m3"`
+	ContractorID *uuid.UUID `json:"contractor_id"`
+	IsActive     bool       `json:"is_active"`
+}
+
+// Client вызывает roles-сервис от имени ANPR-сервиса
+type Client struct {
+	baseURL string
+	tokens  TokenSource
+	http    *http.Client
+}
+
+func NewClient(baseURL string, tokens TokenSource, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		tokens:  tokens,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// VehicleByPlate возвращает активный транспорт с нормализованным номером plate; nil — такого нет
+func (c *Client) VehicleByPlate(ctx context.Context, plate string) (*Vehicle, error) {
+	query := url.Values{}
+	query.Set("plate_number", plate)
+
+	var vehicles []Vehicle
+	if err := c.get(ctx, "/api/v1/vehicles?"+query.Encode(), &vehicles); err != nil {
+		return nil, err
+	}
+	for i := range vehicles {
+		if vehicles[i].IsActive {
+			return &vehicles[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// get выполняет GET и разбирает поле data ответа в out. На 401 токен запрашивается заново один раз.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	resp, err := c.do(ctx, path)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		c.tokens.Invalidate()
+		resp, err = c.do(ctx, path)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data  json.RawMessage `json:"data"`
+		Error string          `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("roles: unexpected response with status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("roles: status %d: %s", resp.StatusCode, result.Error)
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("roles: decode data: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, path string) (*http.Response, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("roles request failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package roles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticTokens struct {
+	tokens      []string
+	invalidated int
+}
+
+func (s *staticTokens) Token(context.Context) (string, error) {
+	return s.tokens[s.invalidated], nil
+}
+
+func (s *staticTokens) Invalidate() {
+	s.invalidated++
+}
+
+func TestVehicleByPlate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"token expired"}`))
+			return
+		}
+		if r.URL.Path != "/api/v1/vehicles" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("plate_number") {
+		case "123ABC02":
+			_, _ = w.Write([]byte(`{"data":[
+				{"plate_number":"123ABC02","brand":"MAN","is_active":false},
+				{"plate_number":"123ABC02","brand":"KAMAZ","body_volume_m3":20,"contractor_id":"660e8400-e29b-41d4-a716-446655440001","is_active":true}
+			]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	tokens := &staticTokens{tokens: []string{"stale", "fresh"}}
+	client := NewClient(srv.URL, tokens, time.Second)
+
+	vehicle, err := client.VehicleByPlate(context.Background(), "123ABC02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vehicle == nil || vehicle.Brand != "KAMAZ" || vehicle.BodyVolumeM3 != 20 || vehicle.ContractorID == nil {
+		t.Fatalf("unexpected vehicle %+v", vehicle)
+	}
+	if tokens.invalidated != 1 {
+		t.Errorf("token invalidated %d times, want 1 (retry after 401)", tokens.invalidated)
+	}
+
+	vehicle, err = client.VehicleByPlate(context.Background(), "999ZZZ01")
+	if err != nil || vehicle != nil {
+		t.Fatalf("VehicleByPlate() = %+v, %v; want nil, nil", vehicle, err)
+	}
+}
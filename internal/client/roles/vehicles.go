@@ -0,0 +1,101 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"anpr-service/internal/clock"
+)
+
+// ErrUnavailable — выключатель разомкнут после серии ошибок roles-сервиса, запрос не отправлялся
+var ErrUnavailable = errors.New("roles service unavailable")
+
+// VehicleOptions — кеш и выключатель поиска транспорта
+type VehicleOptions struct {
+	// CacheTTL — сколько ответ (в том числе «не найден») считается свежим; 0 — без кеша
+	CacheTTL time.Duration
+	// BreakerThreshold — после скольких ошибок подряд запросы перестают отправляться
+	BreakerThreshold int
+	// BreakerCooldown — сколько выключатель разомкнут до пробного запроса
+	BreakerCooldown time.Duration
+}
+
+type vehicleEntry struct {
+	vehicle   *Vehicle
+	fetchedAt time.Time
+}
+
+// Vehicles ищет транспорт через Client с кешем и выключателем: пока roles-сервис недоступен,
+// запросы не ждут таймаута, а номера из кеша отдаются и после истечения TTL.
+type Vehicles struct {
+	client *Client
+	opts   VehicleOptions
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	entries   map[string]vehicleEntry
+	failures  int
+	openUntil time.Time
+	// tripped — выключатель срабатывал и ещё не было успешного запроса: ошибка пробного запроса
+	// сразу размыкает его снова
+	tripped bool
+}
+
+func NewVehicles(client *Client, opts VehicleOptions, clk clock.Clock) *Vehicles {
+	return &Vehicles{
+		client:  client,
+		opts:    opts,
+		clock:   clk,
+		entries: map[string]vehicleEntry{},
+	}
+}
+
+// VehicleByPlate возвращает активный транспорт с нормализованным номером plate; nil — такого нет
+func (v *Vehicles) VehicleByPlate(ctx context.Context, plate string) (*Vehicle, error) {
+	now := v.clock.Now()
+	v.mu.Lock()
+	entry, cached := v.entries[plate]
+	open := now.Before(v.openUntil)
+	v.mu.Unlock()
+
+	if cached && now.Sub(entry.fetchedAt) < v.opts.CacheTTL {
+		return entry.vehicle, nil
+	}
+	if open {
+		if cached {
+			return entry.vehicle, nil
+		}
+		return nil, ErrUnavailable
+	}
+
+	vehicle, err := v.client.VehicleByPlate(ctx, plate)
+	if err != nil {
+		v.recordFailure()
+		if cached && ctx.Err() == nil {
+			return entry.vehicle, nil
+		}
+		return nil, err
+	}
+
+	v.mu.Lock()
+	v.failures = 0
+	v.tripped = false
+	if v.opts.CacheTTL > 0 {
+		v.entries[plate] = vehicleEntry{vehicle: vehicle, fetchedAt: v.clock.Now()}
+	}
+	v.mu.Unlock()
+	return vehicle, nil
+}
+
+func (v *Vehicles) recordFailure() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.failures++
+	if v.opts.BreakerThreshold > 0 && (v.tripped || v.failures >= v.opts.BreakerThreshold) {
+		v.openUntil = v.clock.Now().Add(v.opts.BreakerCooldown)
+		v.failures = 0
+		v.tripped = true
+	}
+}
@@ -0,0 +1,79 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"anpr-service/internal/clock"
+)
+
+func TestVehiclesCacheAndBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"error":"upstream"}`))
+			return
+		}
+		if r.URL.Query().Get("plate_number") == "123ABC02" {
+			_, _ = w.Write([]byte(`{"data":[{"plate_number":"123ABC02","brand":"KAMAZ","is_active":true}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	clk := clock.NewManual(time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC))
+	vehicles := NewVehicles(NewClient(srv.URL, &staticTokens{tokens: []string{"fresh"}}, time.Second), VehicleOptions{
+		CacheTTL:         time.Minute,
+		BreakerThreshold: 2,
+		BreakerCooldown:  30 * time.Second,
+	}, clk)
+	ctx := context.Background()
+
+	// Ответ, в том числе «не найден», кешируется на TTL
+	for i := 0; i < 2; i++ {
+		if v, err := vehicles.VehicleByPlate(ctx, "123ABC02"); err != nil || v == nil {
+			t.Fatalf("VehicleByPlate() = %v, %v", v, err)
+		}
+		if v, err := vehicles.VehicleByPlate(ctx, "999ZZZ01"); err != nil || v != nil {
+			t.Fatalf("VehicleByPlate(unknown) = %v, %v", v, err)
+		}
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+
+	// После истечения TTL при сбое отдаётся устаревший ответ, а номер без кеша — ошибка
+	failing.Store(true)
+	clk.Advance(2 * time.Minute)
+	if v, err := vehicles.VehicleByPlate(ctx, "123ABC02"); err != nil || v == nil {
+		t.Fatalf("stale VehicleByPlate() = %v, %v", v, err)
+	}
+	if _, err := vehicles.VehicleByPlate(ctx, "555AAA05"); err == nil {
+		t.Fatal("expected error for uncached plate")
+	}
+
+	// Две ошибки подряд размыкают выключатель: запросы не отправляются до конца паузы
+	sent := requests.Load()
+	if _, err := vehicles.VehicleByPlate(ctx, "555AAA05"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("error = %v, want ErrUnavailable", err)
+	}
+	if requests.Load() != sent {
+		t.Fatal("request sent while breaker is open")
+	}
+
+	// Пробный запрос после паузы замыкает выключатель
+	failing.Store(false)
+	clk.Advance(31 * time.Second)
+	if _, err := vehicles.VehicleByPlate(ctx, "555AAA05"); err != nil {
+		t.Fatalf("VehicleByPlate() after cooldown error = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package servicetoken получает токен сервиса для вызовов других сервисов SnowOps (client credentials).
+package servicetoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"anpr-service/internal/clock"
+)
+
+// refreshBefore — за сколько до истечения токен запрашивается заново, чтобы он не истёк в пути
+const refreshBefore = 30 * time.Second
+
+// defaultLifetime — срок токена, если сервер авторизации не прислал expires_in
+const defaultLifetime = 5 * time.Minute
+
+// Source выдаёт токен сервиса, запрашивая его у сервера авторизации по client_id и client_secret
+// и кешируя до истечения. Безопасен для конкурентного использования.
+type Source struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	http         *http.Client
+	clock        clock.Clock
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewSource(tokenURL, clientID, clientSecret string, timeout time.Duration, clk clock.Clock) *Source {
+	return &Source{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		http:         &http.Client{Timeout: timeout},
+		clock:        clk,
+	}
+}
+
+// Token возвращает действующий токен, при необходимости запрашивая новый
+func (s *Source) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.clock.Now().Before(s.expiresAt.Add(-refreshBefore)) {
+		return s.token, nil
+	}
+	token, lifetime, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.expiresAt = s.clock.Now().Add(lifetime)
+	return s.token, nil
+}
+
+// Invalidate сбрасывает кешированный токен (сервис ответил 401: токен отозван или сменился секрет)
+func (s *Source) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+func (s *Source) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", s.clientID)
+	form.Set("client_secret", s.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("service token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return "", 0, fmt.Errorf("service token: unexpected response with status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", 0, fmt.Errorf("service token: status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	lifetime := defaultLifetime
+	if result.ExpiresIn > 0 {
+		lifetime = time.Duration(result.ExpiresIn) * time.Second
+	}
+	return result.AccessToken, lifetime, nil
+}
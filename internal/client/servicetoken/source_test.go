@@ -0,0 +1,69 @@
+package servicetoken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"anpr-service/internal/clock"
+)
+
+func TestSourceCachesAndRefreshes(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_id") != "anpr" || r.PostForm.Get("client_secret") != "secret" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		n := calls.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":300}`, n)
+	}))
+	defer srv.Close()
+
+	clk := clock.NewManual(time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC))
+	source := NewSource(srv.URL, "anpr", "secret", time.Second, clk)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    string
+	}{
+		{name: "first call fetches", want: "token-1"},
+		{name: "cached while valid", advance: 4 * time.Minute, want: "token-1"},
+		{name: "refreshed before expiry", advance: 45 * time.Second, want: "token-2"},
+	}
+	for _, step := range steps {
+		clk.Advance(step.advance)
+		got, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Token() error = %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: Token() = %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	source.Invalidate()
+	if got, _ := source.Token(context.Background()); got != "token-3" {
+		t.Errorf("Token() after Invalidate = %q, want token-3", got)
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer srv.Close()
+
+	source := NewSource(srv.URL, "anpr", "wrong", time.Second, clock.System())
+	if _, err := source.Token(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
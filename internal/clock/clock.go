@@ -0,0 +1,81 @@
+// Package clock — источник текущего времени. Сервис и репозиторий получают Clock через конструктор,
+// поэтому окна дедупликации, сроки хранения и расписания можно проверять в тестах на фиксированном времени.
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock возвращает текущее время
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System возвращает системные часы
+func System() Clock {
+	return systemClock{}
+}
+
+// Since возвращает время, прошедшее с t по часам clk
+func Since(clk Clock, t time.Time) time.Duration {
+	return clk.Now().Sub(t)
+}
+
+// Sleep ждёт d по часам clk; раньше возвращает ctx.Err(), если ctx отменён. Ручные часы не ждут,
+// а сдвигаются на d, поэтому паузы в тестах проходят мгновенно.
+func Sleep(ctx context.Context, clk Clock, d time.Duration) error {
+	if m, ok := clk.(*Manual); ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.Advance(d)
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Manual — часы, которые идут только вручную (для тестов)
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual создаёт часы, остановленные на now
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set переставляет часы на t
+func (m *Manual) Set(t time.Time) {
+	m.mu.Lock()
+	m.now = t
+	m.mu.Unlock()
+}
+
+// Advance сдвигает часы на d
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	m.now = m.now.Add(d)
+	m.mu.Unlock()
+}
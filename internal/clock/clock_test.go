@@ -0,0 +1,50 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManual(t *testing.T) {
+	start := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	clk := NewManual(start)
+
+	if got := clk.Now(); !got.Equal(start) {
+		t.Fatalf("Now() = %v, want %v", got, start)
+	}
+	clk.Advance(90 * time.Second)
+	if got := clk.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Fatalf("Now() after Advance = %v", got)
+	}
+	clk.Set(start)
+	if got := clk.Now(); !got.Equal(start) {
+		t.Fatalf("Now() after Set = %v, want %v", got, start)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	start := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	clk := NewManual(start)
+
+	// Ручные часы не ждут, а сдвигаются
+	if err := Sleep(context.Background(), clk, time.Hour); err != nil {
+		t.Fatalf("Sleep() error = %v", err)
+	}
+	if got := Since(clk, start); got != time.Hour {
+		t.Fatalf("Since() after Sleep = %v, want 1h", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Sleep(ctx, clk, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep(canceled, manual) error = %v, want context.Canceled", err)
+	}
+	if err := Sleep(ctx, System(), time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep(canceled, system) error = %v, want context.Canceled", err)
+	}
+	if got := Since(clk, start); got != time.Hour {
+		t.Errorf("canceled Sleep moved the clock to %v", got)
+	}
+}
@@ -15,19 +15,27 @@ import (
 	"anpr-service/internal/config"
 )
 
+// New подключается к БД и, если включён DB_AUTO_MIGRATE, применяет новые миграции
 func New(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
+	database, err := Open(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.DB.AutoMigrate {
+		return database, nil
+	}
+	if err := MigrateUp(context.Background(), database, log); err != nil {
+		return nil, fmt.Errorf("run migrations: %w", err)
+	}
+	return database, nil
+}
+
+// Open подключается к БД без применения миграций (для команды anpr-service migrate)
+func Open(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
 	dbCfg := cfg.DB
 	dsn := applyDBTimeZoneToDSN(dbCfg.DSN, dbCfg.TimeZone)
 
-	gormLog := gormlogger.New(
-		zerologWriter{logger: log},
-		gormlogger.Config{
-			SlowThreshold:             time.Second,
-			Colorful:                  false,
-			IgnoreRecordNotFoundError: true,
-			LogLevel:                  selectLogLevel(cfg.Environment),
-		},
-	)
+	gormLog := newGormLogger(log, selectLogLevel(cfg.Environment), time.Second)
 
 	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: gormLog,
@@ -35,6 +43,9 @@ func New(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := database.Use(tracingPlugin{}); err != nil {
+		return nil, fmt.Errorf("register tracing plugin: %w", err)
+	}
 
 	sqlDB, err := database.DB()
 	if err != nil {
@@ -50,10 +61,15 @@ func New(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
 	if dbCfg.ConnMaxLifetime > 0 {
 		sqlDB.SetConnMaxLifetime(dbCfg.ConnMaxLifetime)
 	}
-
-	if err := runMigrations(database); err != nil {
-		return nil, fmt.Errorf("run migrations: %w", err)
+	if dbCfg.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(dbCfg.ConnMaxIdleTime)
 	}
+	log.Info().
+		Int("max_open_conns", dbCfg.MaxOpenConns).
+		Int("max_idle_conns", dbCfg.MaxIdleConns).
+		Dur("conn_max_lifetime", dbCfg.ConnMaxLifetime).
+		Dur("conn_max_idle_time", dbCfg.ConnMaxIdleTime).
+		Msg("database connection pool configured")
 
 	return database, nil
 }
@@ -99,6 +115,40 @@ func applyDBTimeZoneToDSN(dsn, timeZone string) string {
 	return trimmedDSN + " TimeZone=" + tz
 }
 
+// PoolStats — состояние пула соединений для /health/full
+type PoolStats struct {
+	MaxOpen int `json:"max_open"`
+	Open    int `json:"open"`
+	InUse   int `json:"in_use"`
+	Idle    int `json:"idle"`
+	// WaitCount, WaitDurationMs — сколько раз и как долго запросы ждали свободного соединения
+	WaitCount         int64 `json:"wait_count"`
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+// Pool возвращает состояние пула соединений
+func Pool(db *gorm.DB) (PoolStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return PoolStats{}, err
+	}
+	stats := sqlDB.Stats()
+	return PoolStats{
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDurationMs:    stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}, nil
+}
+
 func HealthCheck(ctx context.Context, db *gorm.DB) error {
 	return db.WithContext(ctx).Exec("SELECT 1").Error
 }
@@ -109,11 +159,3 @@ func selectLogLevel(env string) gormlogger.LogLevel {
 	}
 	return gormlogger.Warn
 }
-
-type zerologWriter struct {
-	logger zerolog.Logger
-}
-
-func (w zerologWriter) Printf(msg string, args ...interface{}) {
-	w.logger.Info().Msgf(msg, args...)
-}
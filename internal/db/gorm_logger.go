@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"anpr-service/internal/logctx"
+)
+
+// gormLogger пишет логи GORM в zerolog-логгер запроса (с request_id), если он есть в контексте,
+// иначе — в общий логгер сервиса
+type gormLogger struct {
+	log           zerolog.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(log zerolog.Logger, level gormlogger.LogLevel, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{log: log, level: level, slowThreshold: slowThreshold}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logctx.From(ctx, &l.log).Info().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logctx.From(ctx, &l.log).Warn().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logctx.From(ctx, &l.log).Error().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := logctx.From(ctx, &l.log)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Error().Err(err).Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msg("sql query failed")
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.Warn().Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msgf("slow sql query >= %v", l.slowThreshold)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		log.Info().Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msg("sql query")
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/tracing"
+)
+
+// gormSpanKey — ключ спана в gorm.Statement между before- и after-колбэками
+const gormSpanKey = "otel:span"
+
+// tracingPlugin оборачивает каждый запрос GORM в спан с текстом SQL и числом строк
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+func (tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("otel:before_create", startGormSpan("gorm.create")),
+		cb.Create().After("gorm:create").Register("otel:after_create", endGormSpan),
+		cb.Query().Before("gorm:query").Register("otel:before_query", startGormSpan("gorm.query")),
+		cb.Query().After("gorm:query").Register("otel:after_query", endGormSpan),
+		cb.Update().Before("gorm:update").Register("otel:before_update", startGormSpan("gorm.update")),
+		cb.Update().After("gorm:update").Register("otel:after_update", endGormSpan),
+		cb.Delete().Before("gorm:delete").Register("otel:before_delete", startGormSpan("gorm.delete")),
+		cb.Delete().After("gorm:delete").Register("otel:after_delete", endGormSpan),
+		cb.Row().Before("gorm:row").Register("otel:before_row", startGormSpan("gorm.row")),
+		cb.Row().After("gorm:row").Register("otel:after_row", endGormSpan),
+		cb.Raw().Before("gorm:raw").Register("otel:before_raw", startGormSpan("gorm.raw")),
+		cb.Raw().After("gorm:raw").Register("otel:after_raw", endGormSpan),
+	)
+}
+
+func startGormSpan(name string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement == nil || tx.Statement.Context == nil {
+			return
+		}
+		// Запросы вне трассируемого запроса (миграции, фоновые задачи без спана) не трассируются
+		if !trace.SpanContextFromContext(tx.Statement.Context).IsValid() {
+			return
+		}
+		ctx, span := tracing.Start(tx.Statement.Context, name,
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.sql.table", tx.Statement.Table),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endGormSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		tracing.RecordError(span, tx.Error)
+	}
+}
@@ -1,261 +1,198 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
+
+	"anpr-service/internal/domain/anpr"
 )
 
-var migrationStatements = []string{
-	`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`,
+// Миграции схемы — файлы migrations/NNNNN_name.sql в формате goose (секции Up и Down).
+// Применённые версии записываются в goose_db_version; новые изменения схемы добавляются новым файлом
+// со следующим номером, уже выпущенные файлы не редактируются.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
-	// Таблица plates - хранит все уникальные номера с нормализацией
-	// Связь с vehicles через normalized (логическая связь через vehicles.plate_number)
-	`CREATE TABLE IF NOT EXISTS anpr_plates (
-		id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		number          TEXT NOT NULL,
-		normalized      TEXT NOT NULL,
-		country         TEXT,
-		region          TEXT,
-		created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_plates_normalized ON anpr_plates(normalized);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_plates_number ON anpr_plates(number);`,
+// vehicleTypeBackfillVersion — Go-миграция: SQL строится из таблицы алиасов anpr.VehicleTypeAliases
+const vehicleTypeBackfillVersion = 2
 
-	// Таблица anpr_events - события распознавания номеров
-	// camera_id может быть UUID (если камера из основной БД) или TEXT (внешний ID камеры)
-	`CREATE TABLE IF NOT EXISTS anpr_events (
-		id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		plate_id        UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
-		camera_id       TEXT NOT NULL,
-		camera_uuid     UUID,
-		polygon_id      UUID,
-		camera_model    TEXT,
-		direction       TEXT,
-		lane            INT,
-		raw_plate       TEXT NOT NULL,
-		normalized_plate TEXT NOT NULL,
-		confidence      NUMERIC(5,2),
-		vehicle_color   TEXT,
-		vehicle_type    TEXT,
-		vehicle_brand   TEXT,
-		vehicle_model   TEXT,
-		vehicle_country TEXT,
-		vehicle_plate_color TEXT,
-		vehicle_speed   NUMERIC(7,2),
-		snapshot_url    TEXT,
-		event_time      TIMESTAMPTZ NOT NULL,
-		raw_payload     JSONB,
-		created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);`,
-	// Добавляем столбец camera_uuid, если его нет (для существующих таблиц)
-	`DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'camera_uuid') THEN
-			ALTER TABLE anpr_events ADD COLUMN camera_uuid UUID;
-		END IF;
-	END
-	$$;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;`,
-	// Добавляем столбец polygon_id, если его нет (для существующих таблиц)
-	`DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'polygon_id') THEN
-			ALTER TABLE anpr_events ADD COLUMN polygon_id UUID;
-		END IF;
-	END
-	$$;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;`,
-	// Добавляем столбец contractor_id, если его нет (для привязки к подрядчику)
-	`DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'contractor_id') THEN
-			ALTER TABLE anpr_events ADD COLUMN contractor_id UUID;
-		END IF;
-	END
-	$$;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_contractor_id ON anpr_events(contractor_id) WHERE contractor_id IS NOT NULL;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_brand TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_model TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_country TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_plate_color TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_speed NUMERIC(7,2);`,
-	// Поля для данных о снеге (snow_event_time и snow_camera_id убраны - используем event_time/created_at и camera_id)
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_percentage NUMERIC(5,2);`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_confidence NUMERIC(5,2);`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_m3 NUMERIC(10,2);`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS matched_snow BOOLEAN DEFAULT FALSE;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;`,
-	// Удаляем snow_direction_ai, если он существует (больше не используется)
-	`DO $$
-	BEGIN
-		IF EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'snow_direction_ai') THEN
-			ALTER TABLE anpr_events DROP COLUMN snow_direction_ai;
-		END IF;
-	END
-	$$;`,
-	// Удаляем дублирующие поля, если они существуют
-	`DO $$
-	BEGIN
-		IF EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'snow_event_time') THEN
-			ALTER TABLE anpr_events DROP COLUMN snow_event_time;
-		END IF;
-		IF EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'snow_camera_id') THEN
-			ALTER TABLE anpr_events DROP COLUMN snow_camera_id;
-		END IF;
-	END
-	$$;`,
-	// Удаляем индекс для snow_event_time, если он существует
-	`DROP INDEX IF EXISTS idx_anpr_events_snow_event_time;`,
+// MigrationState — состояние одной миграции для anpr-service migrate status
+type MigrationState struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
 
-	// Таблица lists - списки номеров (whitelist/blacklist)
-	`CREATE TABLE IF NOT EXISTS anpr_lists (
-		id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		name        TEXT NOT NULL,
-		type        TEXT NOT NULL,
-		description TEXT,
-		created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_lists_name ON anpr_lists(name);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_lists_type ON anpr_lists(type);`,
+func newMigrationProvider(database *gorm.DB) (*goose.Provider, error) {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	// Блокировка через pg_advisory_lock: несколько реплик, стартующих одновременно, не применяют миграции дважды
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("create migration locker: %w", err)
+	}
+	return goose.NewProvider(goose.DialectPostgres, sqlDB, fsys,
+		goose.WithSessionLocker(locker),
+		goose.WithGoMigrations(goose.NewGoMigration(vehicleTypeBackfillVersion,
+			&goose.GoFunc{RunTx: upVehicleTypeBackfill},
+			&goose.GoFunc{RunTx: downVehicleTypeBackfill},
+		)),
+	)
+}
 
-	// Таблица list_items - связи номеров со списками
-	`CREATE TABLE IF NOT EXISTS anpr_list_items (
-		list_id     UUID REFERENCES anpr_lists(id) ON DELETE CASCADE,
-		plate_id    UUID REFERENCES anpr_plates(id) ON DELETE CASCADE,
-		note        TEXT,
-		created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
-		PRIMARY KEY (list_id, plate_id)
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_list_items_plate_id ON anpr_list_items(plate_id);`,
+// MigrateUp применяет все ещё не применённые миграции
+func MigrateUp(ctx context.Context, database *gorm.DB, log zerolog.Logger) error {
+	provider, err := newMigrationProvider(database)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+	results, err := provider.Up(ctx)
+	for _, result := range results {
+		log.Info().
+			Int64("version", result.Source.Version).
+			Str("migration", migrationName(result.Source)).
+			Dur("duration", result.Duration).
+			Msg("migration applied")
+	}
+	if err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	return nil
+}
 
-	// Создание дефолтных списков
-	`DO $$
-	DECLARE
-		whitelist_id UUID;
-		blacklist_id UUID;
-	BEGIN
-		-- Создаем default_whitelist если его нет
-		IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_whitelist') THEN
-			INSERT INTO anpr_lists (id, name, type, description) 
-			VALUES (uuid_generate_v4(), 'default_whitelist', 'WHITELIST', 'Default whitelist - автоматически добавляются номера из vehicles')
-			RETURNING id INTO whitelist_id;
-		END IF;
-		
-		-- Создаем default_blacklist если его нет
-		IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_blacklist') THEN
-			INSERT INTO anpr_lists (id, name, type, description) 
-			VALUES (uuid_generate_v4(), 'default_blacklist', 'BLACKLIST', 'Default blacklist')
-			RETURNING id INTO blacklist_id;
-		END IF;
-	END
-	$$;`,
+// MigrateDown откатывает последнюю применённую миграцию
+func MigrateDown(ctx context.Context, database *gorm.DB, log zerolog.Logger) error {
+	provider, err := newMigrationProvider(database)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+	result, err := provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("roll back migration: %w", err)
+	}
+	log.Info().
+		Int64("version", result.Source.Version).
+		Str("migration", migrationName(result.Source)).
+		Dur("duration", result.Duration).
+		Msg("migration rolled back")
+	return nil
+}
 
-	// Функция для нормализации номера (аналогична Go функции)
-	// Используется в триггерах для автоматической синхронизации
-	`CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
-	RETURNS TEXT AS $$
-	BEGIN
-		-- Удаляем все пробелы, дефисы и приводим к верхнему регистру
-		RETURN UPPER(REGEXP_REPLACE(plate_text, '[^A-Z0-9]', '', 'g'));
-	END;
-	$$ LANGUAGE plpgsql IMMUTABLE;`,
+// MigrationStatus возвращает список миграций с отметкой, какие из них применены
+func MigrationStatus(ctx context.Context, database *gorm.DB) ([]MigrationState, error) {
+	provider, err := newMigrationProvider(database)
+	if err != nil {
+		return nil, fmt.Errorf("create migration provider: %w", err)
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get migration status: %w", err)
+	}
 
-	// Функция для автоматического добавления номера в whitelist при создании vehicle
-	// Вызывается извне (через API или триггер в основной БД, если нужно)
-	`CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
-	RETURNS UUID AS $$
-	DECLARE
-		normalized_plate TEXT;
-		plate_uuid UUID;
-		whitelist_uuid UUID;
-	BEGIN
-		-- Нормализуем номер
-		normalized_plate := normalize_plate_number(vehicle_plate_number);
-		
-		IF normalized_plate = '' THEN
-			RETURN NULL;
-		END IF;
-		
-		-- Получаем или создаем plate
-		SELECT id INTO plate_uuid
-		FROM anpr_plates
-		WHERE normalized = normalized_plate;
-		
-		IF plate_uuid IS NULL THEN
-			INSERT INTO anpr_plates (number, normalized)
-			VALUES (vehicle_plate_number, normalized_plate)
-			RETURNING id INTO plate_uuid;
-		END IF;
-		
-		-- Получаем ID whitelist
-		SELECT id INTO whitelist_uuid
-		FROM anpr_lists
-		WHERE name = 'default_whitelist' AND type = 'WHITELIST'
-		LIMIT 1;
-		
-		IF whitelist_uuid IS NULL THEN
-			-- Создаем whitelist если его нет
-			INSERT INTO anpr_lists (name, type, description)
-			VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
-			RETURNING id INTO whitelist_uuid;
-		END IF;
-		
-		-- Добавляем номер в whitelist (если еще не добавлен)
-		INSERT INTO anpr_list_items (list_id, plate_id, note)
-		VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles')
-		ON CONFLICT (list_id, plate_id) DO NOTHING;
-		
-		RETURN plate_uuid;
-	END;
-	$$ LANGUAGE plpgsql;`,
+	states := make([]MigrationState, 0, len(statuses))
+	for _, status := range statuses {
+		state := MigrationState{
+			Version: status.Source.Version,
+			Name:    migrationName(status.Source),
+			Applied: status.State == goose.StateApplied,
+		}
+		if state.Applied {
+			appliedAt := status.AppliedAt
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
 
-	// Индекс для быстрого поиска по normalized_plate в anpr_events
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);`,
+// SchemaVersion — версия схемы БД: наибольшая применённая миграция и последняя миграция этой сборки.
+// Current меньше Latest — миграции ещё не применены, больше — БД обновлена более новой сборкой.
+type SchemaVersion struct {
+	Current int64 `json:"current"`
+	Latest  int64 `json:"latest"`
+}
 
-	// Таблица anpr_event_photos - фотографии событий
-	`CREATE TABLE IF NOT EXISTS anpr_event_photos (
-		id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		event_id        UUID NOT NULL REFERENCES anpr_events(id) ON DELETE CASCADE,
-		photo_url       TEXT NOT NULL,
-		display_order   INT DEFAULT 0,
-		created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_event_photos_event_id ON anpr_event_photos(event_id);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_event_photos_display_order ON anpr_event_photos(event_id, display_order);`,
+// GetSchemaVersion возвращает версию схемы БД; не ждёт блокировки миграций, идущих на другой реплике
+func GetSchemaVersion(ctx context.Context, database *gorm.DB) (SchemaVersion, error) {
+	provider, err := newMigrationProvider(database)
+	if err != nil {
+		return SchemaVersion{}, fmt.Errorf("create migration provider: %w", err)
+	}
+	current, latest, err := provider.GetVersions(ctx)
+	if err != nil {
+		return SchemaVersion{}, fmt.Errorf("get schema version: %w", err)
+	}
+	return SchemaVersion{Current: current, Latest: latest}, nil
+}
 
-	// Таблица anpr_events_rejected — события, отклонённые из-за отсутствия номера в vehicles (whitelist)
-	`CREATE TABLE IF NOT EXISTS anpr_events_rejected (
-		id               UUID PRIMARY KEY,
-		plate_id         UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
-		camera_id        TEXT NOT NULL,
-		raw_plate        TEXT NOT NULL,
-		normalized_plate TEXT NOT NULL,
-		event_time       TIMESTAMPTZ NOT NULL,
-		raw_payload      JSONB,
-		photo_urls       JSONB,
-		reject_reason    TEXT NOT NULL DEFAULT 'vehicle_not_in_whitelist',
-		created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_normalized_plate ON anpr_events_rejected(normalized_plate);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_event_time ON anpr_events_rejected(event_time);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_created_at ON anpr_events_rejected(created_at);`,
+func migrationName(source *goose.Source) string {
+	if source.Type == goose.TypeGo {
+		return "vehicle_type_backfill (go)"
+	}
+	return strings.TrimSuffix(filepath.Base(source.Path), filepath.Ext(source.Path))
 }
 
-func runMigrations(db *gorm.DB) error {
-	for i, stmt := range migrationStatements {
-		if err := db.Exec(stmt).Error; err != nil {
-			return fmt.Errorf("migration %d failed: %w", i+1, err)
-		}
+func upVehicleTypeBackfill(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, vehicleTypeBackfillSQL())
+	return err
+}
+
+// downVehicleTypeBackfill возвращает исходные значения камеры в vehicle_type
+func downVehicleTypeBackfill(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `UPDATE anpr_events
+	SET vehicle_type = vehicle_type_raw, vehicle_type_raw = NULL
+	WHERE vehicle_type_raw IS NOT NULL`)
+	return err
+}
+
+// vehicleTypeBackfillSQL строит UPDATE, приводящий vehicle_type старых событий к каноническим
+// значениям по тем же правилам, что и anpr.NormalizeVehicleType
+func vehicleTypeBackfillSQL() string {
+	aliases := anpr.VehicleTypeAliases()
+	keys := make([]string, 0, len(aliases))
+	for k := range aliases {
+		keys = append(keys, k)
 	}
-	return nil
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, fmt.Sprintf("('%s', '%s')", k, aliases[k]))
+	}
+
+	return `UPDATE anpr_events e
+	SET vehicle_type_raw = e.vehicle_type,
+		vehicle_type = COALESCE(
+			(SELECT m.canonical FROM (VALUES ` + strings.Join(values, ", ") + `) AS m(alias, canonical)
+			 WHERE m.alias = lower(regexp_replace(trim(e.vehicle_type), '[_ /-]', '', 'g'))),
+			CASE
+				WHEN upper(trim(e.vehicle_type)) ~ '^K[12][0-9]$' THEN 'bus'
+				WHEN upper(trim(e.vehicle_type)) ~ '^K3[12]$' THEN 'van'
+				WHEN upper(trim(e.vehicle_type)) ~ '^K[0-9][0-9]$' THEN 'car'
+				WHEN upper(trim(e.vehicle_type)) ~ '^[HQZGB][0-9][0-9]$' THEN 'truck'
+				WHEN upper(trim(e.vehicle_type)) ~ '^M[0-9][0-9]$' THEN 'motorcycle'
+				WHEN upper(trim(e.vehicle_type)) ~ '^X[0-9][0-9]$' THEN 'other'
+				ELSE 'unknown'
+			END)
+	WHERE e.vehicle_type IS NOT NULL AND e.vehicle_type <> '' AND e.vehicle_type_raw IS NULL;`
 }
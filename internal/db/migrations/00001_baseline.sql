@@ -0,0 +1,408 @@
+-- Базовая схема: все изменения, накопленные до перехода на версионные миграции.
+-- Выражения идемпотентны, поэтому на уже развёрнутой базе миграция проходит без изменений.
+
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Таблица plates - хранит все уникальные номера с нормализацией
+-- Связь с vehicles через normalized (логическая связь через vehicles.plate_number)
+CREATE TABLE IF NOT EXISTS anpr_plates (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	number          TEXT NOT NULL,
+	normalized      TEXT NOT NULL,
+	country         TEXT,
+	region          TEXT,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_plates_normalized ON anpr_plates(normalized);
+CREATE INDEX IF NOT EXISTS idx_anpr_plates_number ON anpr_plates(number);
+
+-- Таблица anpr_events - события распознавания номеров
+-- camera_id может быть UUID (если камера из основной БД) или TEXT (внешний ID камеры)
+CREATE TABLE IF NOT EXISTS anpr_events (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	plate_id        UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+	camera_id       TEXT NOT NULL,
+	camera_uuid     UUID,
+	polygon_id      UUID,
+	camera_model    TEXT,
+	direction       TEXT,
+	lane            INT,
+	raw_plate       TEXT NOT NULL,
+	normalized_plate TEXT NOT NULL,
+	confidence      NUMERIC(5,2),
+	vehicle_color   TEXT,
+	vehicle_type    TEXT,
+	vehicle_brand   TEXT,
+	vehicle_model   TEXT,
+	vehicle_country TEXT,
+	vehicle_plate_color TEXT,
+	vehicle_speed   NUMERIC(7,2),
+	snapshot_url    TEXT,
+	event_time      TIMESTAMPTZ NOT NULL,
+	raw_payload     JSONB,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);
+-- Добавляем столбец camera_uuid, если его нет (для существующих таблиц)
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'camera_uuid') THEN
+		ALTER TABLE anpr_events ADD COLUMN camera_uuid UUID;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;
+-- Добавляем столбец polygon_id, если его нет (для существующих таблиц)
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'polygon_id') THEN
+		ALTER TABLE anpr_events ADD COLUMN polygon_id UUID;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;
+-- Добавляем столбец contractor_id, если его нет (для привязки к подрядчику)
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'contractor_id') THEN
+		ALTER TABLE anpr_events ADD COLUMN contractor_id UUID;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+CREATE INDEX IF NOT EXISTS idx_anpr_events_contractor_id ON anpr_events(contractor_id) WHERE contractor_id IS NOT NULL;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_brand TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_model TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_country TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_plate_color TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_speed NUMERIC(7,2);
+-- Поля для данных о снеге (snow_event_time и snow_camera_id убраны - используем event_time/created_at и camera_id)
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_percentage NUMERIC(5,2);
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_confidence NUMERIC(5,2);
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_m3 NUMERIC(10,2);
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS matched_snow BOOLEAN DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;
+-- Удаляем snow_direction_ai, если он существует (больше не используется)
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'snow_direction_ai') THEN
+		ALTER TABLE anpr_events DROP COLUMN snow_direction_ai;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+-- Удаляем дублирующие поля, если они существуют
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'snow_event_time') THEN
+		ALTER TABLE anpr_events DROP COLUMN snow_event_time;
+	END IF;
+	IF EXISTS (SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'anpr_events' AND column_name = 'snow_camera_id') THEN
+		ALTER TABLE anpr_events DROP COLUMN snow_camera_id;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+-- Удаляем индекс для snow_event_time, если он существует
+DROP INDEX IF EXISTS idx_anpr_events_snow_event_time;
+
+-- Таблица lists - списки номеров (whitelist/blacklist)
+CREATE TABLE IF NOT EXISTS anpr_lists (
+	id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	name        TEXT NOT NULL,
+	type        TEXT NOT NULL,
+	description TEXT,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_lists_name ON anpr_lists(name);
+CREATE INDEX IF NOT EXISTS idx_anpr_lists_type ON anpr_lists(type);
+
+-- Таблица list_items - связи номеров со списками
+CREATE TABLE IF NOT EXISTS anpr_list_items (
+	list_id     UUID REFERENCES anpr_lists(id) ON DELETE CASCADE,
+	plate_id    UUID REFERENCES anpr_plates(id) ON DELETE CASCADE,
+	note        TEXT,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (list_id, plate_id)
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_list_items_plate_id ON anpr_list_items(plate_id);
+
+-- Создание дефолтных списков
+-- +goose StatementBegin
+DO $$
+DECLARE
+	whitelist_id UUID;
+	blacklist_id UUID;
+BEGIN
+	-- Создаем default_whitelist если его нет
+	IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_whitelist') THEN
+		INSERT INTO anpr_lists (id, name, type, description) 
+		VALUES (uuid_generate_v4(), 'default_whitelist', 'WHITELIST', 'Default whitelist - автоматически добавляются номера из vehicles')
+		RETURNING id INTO whitelist_id;
+	END IF;
+	
+	-- Создаем default_blacklist если его нет
+	IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_blacklist') THEN
+		INSERT INTO anpr_lists (id, name, type, description) 
+		VALUES (uuid_generate_v4(), 'default_blacklist', 'BLACKLIST', 'Default blacklist')
+		RETURNING id INTO blacklist_id;
+	END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- Функция для нормализации номера (аналогична Go функции)
+-- Используется в триггерах для автоматической синхронизации
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
+RETURNS TEXT AS $$
+BEGIN
+	-- Удаляем все пробелы, дефисы и приводим к верхнему регистру
+	RETURN UPPER(REGEXP_REPLACE(plate_text, '[^A-Z0-9]', '', 'g'));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- Функция для автоматического добавления номера в whitelist при создании vehicle
+-- Вызывается извне (через API или триггер в основной БД, если нужно)
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
+RETURNS UUID AS $$
+DECLARE
+	normalized_plate TEXT;
+	plate_uuid UUID;
+	whitelist_uuid UUID;
+BEGIN
+	-- Нормализуем номер
+	normalized_plate := normalize_plate_number(vehicle_plate_number);
+	
+	IF normalized_plate = '' THEN
+		RETURN NULL;
+	END IF;
+	
+	-- Получаем или создаем plate
+	SELECT id INTO plate_uuid
+	FROM anpr_plates
+	WHERE normalized = normalized_plate;
+	
+	IF plate_uuid IS NULL THEN
+		INSERT INTO anpr_plates (number, normalized)
+		VALUES (vehicle_plate_number, normalized_plate)
+		RETURNING id INTO plate_uuid;
+	END IF;
+	
+	-- Получаем ID whitelist
+	SELECT id INTO whitelist_uuid
+	FROM anpr_lists
+	WHERE name = 'default_whitelist' AND type = 'WHITELIST'
+	LIMIT 1;
+	
+	IF whitelist_uuid IS NULL THEN
+		-- Создаем whitelist если его нет
+		INSERT INTO anpr_lists (name, type, description)
+		VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
+		RETURNING id INTO whitelist_uuid;
+	END IF;
+	
+	-- Добавляем номер в whitelist (если еще не добавлен)
+	INSERT INTO anpr_list_items (list_id, plate_id, note)
+	VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles')
+	ON CONFLICT (list_id, plate_id) DO NOTHING;
+	
+	RETURN plate_uuid;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Индекс для быстрого поиска по normalized_plate в anpr_events
+CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);
+
+-- Таблица anpr_event_photos - фотографии событий
+CREATE TABLE IF NOT EXISTS anpr_event_photos (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id        UUID NOT NULL REFERENCES anpr_events(id) ON DELETE CASCADE,
+	photo_url       TEXT NOT NULL,
+	display_order   INT DEFAULT 0,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_event_photos_event_id ON anpr_event_photos(event_id);
+CREATE INDEX IF NOT EXISTS idx_anpr_event_photos_display_order ON anpr_event_photos(event_id, display_order);
+
+-- Таблица anpr_events_rejected — события, отклонённые из-за отсутствия номера в vehicles (whitelist)
+CREATE TABLE IF NOT EXISTS anpr_events_rejected (
+	id               UUID PRIMARY KEY,
+	plate_id         UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+	camera_id        TEXT NOT NULL,
+	raw_plate        TEXT NOT NULL,
+	normalized_plate TEXT NOT NULL,
+	event_time       TIMESTAMPTZ NOT NULL,
+	raw_payload      JSONB,
+	photo_urls       JSONB,
+	reject_reason    TEXT NOT NULL DEFAULT 'vehicle_not_in_whitelist',
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_normalized_plate ON anpr_events_rejected(normalized_plate);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_event_time ON anpr_events_rejected(event_time);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_created_at ON anpr_events_rejected(created_at);
+
+-- Таблица anpr_cameras — реестр камер с индивидуальными настройками (camera_id совпадает с anpr_events.camera_id)
+CREATE TABLE IF NOT EXISTS anpr_cameras (
+	id          TEXT PRIMARY KEY,
+	name        TEXT,
+	timezone    TEXT,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- Пометка событий, время которых расходится с временем сервера больше допустимого
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS event_time_skewed BOOLEAN NOT NULL DEFAULT FALSE;
+-- Наблюдаемое расхождение часов камеры (event_time - время приёма), сглаженное по последним событиям
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS clock_skew_seconds DOUBLE PRECISION;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS clock_skew_samples INT NOT NULL DEFAULT 0;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS clock_skew_updated_at TIMESTAMPTZ;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS clock_auto_correct BOOLEAN NOT NULL DEFAULT FALSE;
+-- Поправка, применённая к event_time при автокоррекции часов камеры
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS clock_correction_seconds DOUBLE PRECISION;
+-- Расписание работы камеры (например, только ночная смена) и пометка событий вне расписания
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS armed_schedule TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS out_of_schedule BOOLEAN NOT NULL DEFAULT FALSE;
+-- Время приёма события отдельно от event_time (импорт и догрузка старых событий)
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ;
+UPDATE anpr_events SET received_at = created_at WHERE received_at IS NULL;
+ALTER TABLE anpr_events ALTER COLUMN received_at SET DEFAULT now();
+ALTER TABLE anpr_events ALTER COLUMN received_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_received_at ON anpr_events(received_at);
+-- HTTP-адрес камеры для ISAPI (снимок по запросу); может содержать учётные данные
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS http_host TEXT;
+-- Синхронизация default_whitelist в бортовой список камеры (шлагбаум работает и без сервиса)
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS whitelist_sync BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS whitelist_synced_at TIMESTAMPTZ;
+-- История изменений членства номеров в списках (для таймлайна номера)
+CREATE TABLE IF NOT EXISTS anpr_list_item_history (
+	id          BIGSERIAL PRIMARY KEY,
+	list_id     UUID NOT NULL,
+	plate_id    UUID NOT NULL,
+	action      TEXT NOT NULL,
+	note        TEXT,
+	changed_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_list_item_history_plate ON anpr_list_item_history(plate_id, changed_at);
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_list_item_history_log()
+RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO anpr_list_item_history (list_id, plate_id, action, note)
+		VALUES (NEW.list_id, NEW.plate_id, 'added', NEW.note);
+		RETURN NEW;
+	END IF;
+	INSERT INTO anpr_list_item_history (list_id, plate_id, action, note)
+	VALUES (OLD.list_id, OLD.plate_id, 'removed', OLD.note);
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP TRIGGER IF EXISTS trg_anpr_list_item_history ON anpr_list_items;
+CREATE TRIGGER trg_anpr_list_item_history
+	AFTER INSERT OR DELETE ON anpr_list_items
+	FOR EACH ROW EXECUTE FUNCTION anpr_list_item_history_log();
+-- Существующие записи списков попадают в историю как добавленные в момент создания
+INSERT INTO anpr_list_item_history (list_id, plate_id, action, note, changed_at)
+SELECT li.list_id, li.plate_id, 'added', li.note, li.created_at
+FROM anpr_list_items li
+WHERE NOT EXISTS (
+	SELECT 1 FROM anpr_list_item_history h
+	WHERE h.list_id = li.list_id AND h.plate_id = li.plate_id
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_rejected_plate_time ON anpr_events_rejected(plate_id, event_time);
+-- Последнее событие камеры для проверки её активности (/health/full)
+CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_received_at ON anpr_events(camera_id, received_at DESC);
+-- Защита от повторной записи фото при ретраях: схлопываем существующие дубли (оставляем самую раннюю запись)
+-- и добавляем уникальный индекс. Выполняется один раз — пока индекса нет.
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'uq_anpr_event_photos_event_order_url') THEN
+		UPDATE anpr_event_photos SET display_order = 0 WHERE display_order IS NULL;
+		DELETE FROM anpr_event_photos p
+		USING anpr_event_photos d
+		WHERE p.event_id = d.event_id
+			AND p.display_order = d.display_order
+			AND p.photo_url = d.photo_url
+			AND (p.created_at, p.id) > (d.created_at, d.id);
+		ALTER TABLE anpr_event_photos ALTER COLUMN display_order SET NOT NULL;
+		CREATE UNIQUE INDEX uq_anpr_event_photos_event_order_url ON anpr_event_photos(event_id, display_order, photo_url);
+	END IF;
+END $$;
+-- +goose StatementEnd
+-- Решение о доступе по событию (движок правил)
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS access_decision TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS decision_reason TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS decision_detail TEXT;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_decision_time ON anpr_events(plate_id, access_decision, event_time);
+CREATE TABLE IF NOT EXISTS anpr_contractor_access_rules (
+	contractor_id       UUID PRIMARY KEY,
+	schedule            TEXT,
+	max_trips_per_night INTEGER,
+	created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- Канонический тип транспорта: исходное значение камеры переносится в vehicle_type_raw
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_type_raw TEXT;
+-- Приведение vehicle_type старых событий к каноническим значениям — Go-миграция 2 (vehicle_type_backfill)
+CREATE INDEX IF NOT EXISTS idx_anpr_events_vehicle_type_time ON anpr_events(vehicle_type, event_time);
+-- Номера, не прошедшие проверку формата, сохраняются в anpr_events_rejected без записи в anpr_plates
+ALTER TABLE anpr_events_rejected ALTER COLUMN plate_id DROP NOT NULL;
+-- Версии данных для опроса клиентами: счётчик увеличивается при любом изменении событий или списков
+CREATE TABLE IF NOT EXISTS anpr_data_versions (
+	scope      TEXT PRIMARY KEY,
+	version    BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+INSERT INTO anpr_data_versions (scope) VALUES ('events'), ('lists') ON CONFLICT (scope) DO NOTHING;
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_bump_data_version()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE anpr_data_versions SET version = version + 1, updated_at = now() WHERE scope = TG_ARGV[0];
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP TRIGGER IF EXISTS trg_anpr_events_data_version ON anpr_events;
+CREATE TRIGGER trg_anpr_events_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_events
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('events');
+DROP TRIGGER IF EXISTS trg_anpr_lists_data_version ON anpr_lists;
+CREATE TRIGGER trg_anpr_lists_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_lists
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('lists');
+DROP TRIGGER IF EXISTS trg_anpr_list_items_data_version ON anpr_list_items;
+CREATE TRIGGER trg_anpr_list_items_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_list_items
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('lists');
+
+-- +goose Down
+-- Базовая схема необратима: она описывает базы, развёрнутые до версионных миграций, и откат удалил бы
+-- все их данные. Чтобы пересоздать схему, базу удаляют вручную.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'baseline migration is irreversible';
+END $$;
+-- +goose StatementEnd
@@ -0,0 +1,98 @@
+-- Секционирование anpr_events по event_time (недели, границы в UTC). Очистка старых событий
+-- удаляет секции целиком вместо DELETE, а запросы с фильтром по event_time читают только нужные секции.
+-- Ключ секционирования входит в первичный ключ, поэтому внешний ключ anpr_event_photos.event_id
+-- снимается: фото удаляются вместе с событиями в DeleteOldEvents / DeleteAllEvents.
+-- Секции называются anpr_events_YYYYMMDD_YYYYMMDD (начало и конец диапазона); новые создаёт сервис
+-- (RunEventPartitionMaintenance), события вне существующих секций попадают в anpr_events_default.
+
+-- +goose Up
+DROP TRIGGER IF EXISTS trg_anpr_events_data_version ON anpr_events;
+ALTER TABLE anpr_events RENAME TO anpr_events_legacy;
+
+CREATE TABLE anpr_events (LIKE anpr_events_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (event_time);
+CREATE TABLE anpr_events_default PARTITION OF anpr_events DEFAULT;
+
+-- Недельные секции от самого старого события (не раньше чем за год) до четырёх недель вперёд;
+-- более старые события попадают в секцию по умолчанию
+-- +goose StatementBegin
+DO $$
+DECLARE
+	current_week TIMESTAMP := date_trunc('week', now() AT TIME ZONE 'UTC');
+	week_start   TIMESTAMP;
+BEGIN
+	SELECT date_trunc('week', MIN(event_time) AT TIME ZONE 'UTC') INTO week_start FROM anpr_events_legacy;
+	IF week_start IS NULL OR week_start > current_week THEN
+		week_start := current_week;
+	END IF;
+	week_start := GREATEST(week_start, current_week - INTERVAL '52 weeks');
+
+	WHILE week_start <= current_week + INTERVAL '4 weeks' LOOP
+		EXECUTE format('CREATE TABLE %I PARTITION OF anpr_events FOR VALUES FROM (%L) TO (%L)',
+			'anpr_events_' || to_char(week_start, 'YYYYMMDD') || '_' || to_char(week_start + INTERVAL '1 week', 'YYYYMMDD'),
+			week_start AT TIME ZONE 'UTC',
+			(week_start + INTERVAL '1 week') AT TIME ZONE 'UTC');
+		week_start := week_start + INTERVAL '1 week';
+	END LOOP;
+END
+$$;
+-- +goose StatementEnd
+
+INSERT INTO anpr_events SELECT * FROM anpr_events_legacy;
+DROP TABLE anpr_events_legacy CASCADE;
+
+-- Поиск по id без event_time (карточка события) идёт по первичному ключу каждой секции
+ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_pkey PRIMARY KEY (id, event_time);
+ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_plate_id_fkey
+	FOREIGN KEY (plate_id) REFERENCES anpr_plates(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_anpr_events_plate_id ON anpr_events(plate_id);
+CREATE INDEX idx_anpr_events_event_time ON anpr_events(event_time);
+CREATE INDEX idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);
+CREATE INDEX idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);
+CREATE INDEX idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;
+CREATE INDEX idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;
+CREATE INDEX idx_anpr_events_contractor_id ON anpr_events(contractor_id) WHERE contractor_id IS NOT NULL;
+CREATE INDEX idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;
+CREATE INDEX idx_anpr_events_received_at ON anpr_events(received_at);
+CREATE INDEX idx_anpr_events_camera_received_at ON anpr_events(camera_id, received_at DESC);
+CREATE INDEX idx_anpr_events_plate_decision_time ON anpr_events(plate_id, access_decision, event_time);
+CREATE INDEX idx_anpr_events_vehicle_type_time ON anpr_events(vehicle_type, event_time);
+-- Последнее событие номера: секции просматриваются от новой к старой до первого найденного
+CREATE INDEX idx_anpr_events_plate_time ON anpr_events(plate_id, event_time DESC);
+
+CREATE TRIGGER trg_anpr_events_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_events
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('events');
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_anpr_events_data_version ON anpr_events;
+ALTER TABLE anpr_events RENAME TO anpr_events_partitioned;
+
+CREATE TABLE anpr_events (LIKE anpr_events_partitioned INCLUDING DEFAULTS);
+INSERT INTO anpr_events SELECT * FROM anpr_events_partitioned;
+DROP TABLE anpr_events_partitioned CASCADE;
+
+ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_pkey PRIMARY KEY (id);
+ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_plate_id_fkey
+	FOREIGN KEY (plate_id) REFERENCES anpr_plates(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_anpr_events_plate_id ON anpr_events(plate_id);
+CREATE INDEX idx_anpr_events_event_time ON anpr_events(event_time);
+CREATE INDEX idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);
+CREATE INDEX idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);
+CREATE INDEX idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;
+CREATE INDEX idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;
+CREATE INDEX idx_anpr_events_contractor_id ON anpr_events(contractor_id) WHERE contractor_id IS NOT NULL;
+CREATE INDEX idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;
+CREATE INDEX idx_anpr_events_received_at ON anpr_events(received_at);
+CREATE INDEX idx_anpr_events_camera_received_at ON anpr_events(camera_id, received_at DESC);
+CREATE INDEX idx_anpr_events_plate_decision_time ON anpr_events(plate_id, access_decision, event_time);
+CREATE INDEX idx_anpr_events_vehicle_type_time ON anpr_events(vehicle_type, event_time);
+
+DELETE FROM anpr_event_photos p WHERE NOT EXISTS (SELECT 1 FROM anpr_events e WHERE e.id = p.event_id);
+ALTER TABLE anpr_event_photos ADD CONSTRAINT anpr_event_photos_event_id_fkey
+	FOREIGN KEY (event_id) REFERENCES anpr_events(id) ON DELETE CASCADE;
+
+CREATE TRIGGER trg_anpr_events_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_events
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('events');
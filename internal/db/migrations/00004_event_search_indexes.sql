@@ -0,0 +1,15 @@
+-- Индексы под сортировку списка событий (время DESC, id DESC): первая страница и страницы с OFFSET
+-- читаются по индексу без сортировки всей выборки, id делает порядок стабильным при одинаковом времени.
+-- Индекс (normalized_plate, event_time DESC) заменяется на такой же с id.
+
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time_id ON anpr_events(event_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_received_at_id ON anpr_events(received_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time_id ON anpr_events(normalized_plate, event_time DESC, id DESC);
+DROP INDEX IF EXISTS idx_anpr_events_normalized_plate_time;
+
+-- +goose Down
+CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);
+DROP INDEX IF EXISTS idx_anpr_events_normalized_plate_time_id;
+DROP INDEX IF EXISTS idx_anpr_events_received_at_id;
+DROP INDEX IF EXISTS idx_anpr_events_event_time_id;
@@ -0,0 +1,44 @@
+-- Вебхуки: подписки внешних систем на события и журнал доставок. Доставка работает как outbox:
+-- при новом событии создаются строки pending, фоновый процесс отправляет их с повторами.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_webhook_subscriptions (
+	id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	name         TEXT NOT NULL,
+	url          TEXT NOT NULL,
+	secret       TEXT NOT NULL,
+	-- Фильтры: пустой список или NULL — без ограничения по полю
+	camera_ids   JSONB NOT NULL DEFAULT '[]',
+	list_types   JSONB NOT NULL DEFAULT '[]',
+	matched_snow BOOLEAN,
+	active       BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS anpr_webhook_deliveries (
+	id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	subscription_id  UUID NOT NULL REFERENCES anpr_webhook_subscriptions(id) ON DELETE CASCADE,
+	event_id         UUID NOT NULL,
+	topic            TEXT NOT NULL,
+	payload          JSONB NOT NULL,
+	status           TEXT NOT NULL DEFAULT 'pending',
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_status_code INTEGER,
+	last_error       TEXT,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	delivered_at     TIMESTAMPTZ
+);
+
+-- Одно событие доставляется подписчику один раз, даже если его получили несколько реплик
+CREATE UNIQUE INDEX IF NOT EXISTS idx_anpr_webhook_deliveries_subscription_event
+	ON anpr_webhook_deliveries(subscription_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_anpr_webhook_deliveries_due
+	ON anpr_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_anpr_webhook_deliveries_log
+	ON anpr_webhook_deliveries(subscription_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_webhook_deliveries;
+DROP TABLE IF EXISTS anpr_webhook_subscriptions;
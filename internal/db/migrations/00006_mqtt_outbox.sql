@@ -0,0 +1,24 @@
+-- Outbox сообщений MQTT (публикация распознаваний в SCADA полигона). Работает так же, как доставки
+-- вебхуков: строка pending на событие, фоновый relay публикует её с повторами.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_mqtt_outbox (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id        UUID NOT NULL,
+	topic           TEXT NOT NULL,
+	payload         JSONB NOT NULL,
+	status          TEXT NOT NULL DEFAULT 'pending',
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_error      TEXT,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	delivered_at    TIMESTAMPTZ
+);
+
+-- Событие публикуется один раз, даже если его получили несколько реплик
+CREATE UNIQUE INDEX IF NOT EXISTS idx_anpr_mqtt_outbox_event ON anpr_mqtt_outbox(event_id);
+CREATE INDEX IF NOT EXISTS idx_anpr_mqtt_outbox_due
+	ON anpr_mqtt_outbox(next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_mqtt_outbox;
@@ -0,0 +1,25 @@
+-- Outbox уведомлений в Telegram (чёрный список, перегруз, молчащие камеры). dedup_key + chat_id
+-- не дают отправить одно уведомление дважды, если его сформировали несколько реплик.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_telegram_outbox (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	dedup_key       TEXT NOT NULL,
+	chat_id         TEXT NOT NULL,
+	kind            TEXT NOT NULL,
+	text            TEXT NOT NULL,
+	photo_url       TEXT,
+	status          TEXT NOT NULL DEFAULT 'pending',
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_error      TEXT,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	delivered_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_anpr_telegram_outbox_dedup ON anpr_telegram_outbox(dedup_key, chat_id);
+CREATE INDEX IF NOT EXISTS idx_anpr_telegram_outbox_due
+	ON anpr_telegram_outbox(next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_telegram_outbox;
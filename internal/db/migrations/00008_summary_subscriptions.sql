@@ -0,0 +1,17 @@
+-- Подписки CONTRACTOR_ADMIN на ночную сводку по подрядчику (доставка в чат Telegram).
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_summary_subscriptions (
+	user_id          UUID PRIMARY KEY,
+	contractor_id    UUID NOT NULL,
+	telegram_chat_id TEXT NOT NULL,
+	enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_anpr_summary_subscriptions_contractor
+	ON anpr_summary_subscriptions(contractor_id) WHERE enabled;
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_summary_subscriptions;
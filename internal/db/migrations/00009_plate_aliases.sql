@@ -0,0 +1,15 @@
+-- Псевдонимы номеров: ошибочные варианты распознавания (097CP02 → O97CP02), которые при приёме
+-- события заменяются основным номером.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_plate_aliases (
+	alias      TEXT PRIMARY KEY,
+	plate_id   UUID NOT NULL REFERENCES anpr_plates(id) ON DELETE CASCADE,
+	note       TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_anpr_plate_aliases_plate_id ON anpr_plate_aliases(plate_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_plate_aliases;
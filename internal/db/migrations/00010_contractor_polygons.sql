@@ -0,0 +1,18 @@
+-- Закрепление подрядчиков за полигонами вывоза и пометка событий на чужом полигоне.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_contractor_polygons (
+	contractor_id UUID NOT NULL,
+	polygon_id    UUID NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (contractor_id, polygon_id)
+);
+
+-- Машина подрядчика приехала на полигон, за которым подрядчик не закреплён
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS wrong_destination BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_wrong_destination ON anpr_events(event_time) WHERE wrong_destination;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_anpr_events_wrong_destination;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS wrong_destination;
+DROP TABLE IF EXISTS anpr_contractor_polygons;
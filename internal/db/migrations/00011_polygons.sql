@@ -0,0 +1,37 @@
+-- Реестр полигонов (мест складирования снега) сервиса и привязка к ним камер. Полигоны из общей
+-- таблицы polygons переносятся с теми же id, чтобы уже сохранённые polygon_id событий остались верны.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_polygons (
+	id              UUID PRIMARY KEY,
+	name            TEXT NOT NULL,
+	organization_id UUID,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_anpr_polygons_name ON anpr_polygons (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_anpr_polygons_organization_id ON anpr_polygons(organization_id) WHERE organization_id IS NOT NULL;
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+	IF to_regclass('polygons') IS NOT NULL THEN
+		INSERT INTO anpr_polygons (id, name)
+		SELECT id, name FROM polygons
+		ON CONFLICT DO NOTHING;
+	END IF;
+END $$;
+-- +goose StatementEnd
+
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS polygon_id UUID REFERENCES anpr_polygons(id) ON DELETE SET NULL;
+
+DELETE FROM anpr_contractor_polygons WHERE polygon_id NOT IN (SELECT id FROM anpr_polygons);
+ALTER TABLE anpr_contractor_polygons
+	ADD CONSTRAINT fk_anpr_contractor_polygons_polygon
+	FOREIGN KEY (polygon_id) REFERENCES anpr_polygons(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE anpr_contractor_polygons DROP CONSTRAINT IF EXISTS fk_anpr_contractor_polygons_polygon;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS polygon_id;
+DROP TABLE IF EXISTS anpr_polygons;
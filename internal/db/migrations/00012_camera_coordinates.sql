@@ -0,0 +1,14 @@
+-- Координаты камер (WGS 84) для карты городских служб.
+
+-- +goose Up
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE anpr_cameras ADD CONSTRAINT chk_anpr_cameras_coordinates CHECK (
+	(latitude IS NULL AND longitude IS NULL)
+	OR (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+-- +goose Down
+ALTER TABLE anpr_cameras DROP CONSTRAINT IF EXISTS chk_anpr_cameras_coordinates;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS longitude;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS latitude;
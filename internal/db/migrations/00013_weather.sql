@@ -0,0 +1,31 @@
+-- Почасовая погода на полигонах (температура, снегопад) и её значения в событиях — для споров
+-- по оплате вывоза: шёл ли снег в момент рейса.
+
+-- +goose Up
+ALTER TABLE anpr_polygons ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE anpr_polygons ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE anpr_polygons ADD CONSTRAINT chk_anpr_polygons_coordinates CHECK (
+	(latitude IS NULL AND longitude IS NULL)
+	OR (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+-- observed_hour — конец часа (как у Open-Meteo): snowfall_cm выпал за час до него
+CREATE TABLE IF NOT EXISTS anpr_weather_observations (
+	polygon_id    UUID NOT NULL REFERENCES anpr_polygons(id) ON DELETE CASCADE,
+	observed_hour TIMESTAMPTZ NOT NULL,
+	temperature_c DOUBLE PRECISION,
+	snowfall_cm   DOUBLE PRECISION,
+	fetched_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (polygon_id, observed_hour)
+);
+
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS weather_temperature_c DOUBLE PRECISION;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS weather_snowfall_cm DOUBLE PRECISION;
+
+-- +goose Down
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS weather_snowfall_cm;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS weather_temperature_c;
+DROP TABLE IF EXISTS anpr_weather_observations;
+ALTER TABLE anpr_polygons DROP CONSTRAINT IF EXISTS chk_anpr_polygons_coordinates;
+ALTER TABLE anpr_polygons DROP COLUMN IF EXISTS longitude;
+ALTER TABLE anpr_polygons DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,33 @@
+-- Хеши фото событий для поиска снимков, присланных повторно под другими событиями (подлог при ручном
+-- импорте). Строки не ссылаются на anpr_events: хеш считается при загрузке, до сохранения события,
+-- а событие может быть отклонено.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_photo_hashes (
+	id                     UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id               UUID NOT NULL,
+	event_time             TIMESTAMPTZ NOT NULL,
+	camera_id              TEXT NOT NULL,
+	plate                  TEXT NOT NULL,
+	photo_url              TEXT NOT NULL,
+	sha256                 TEXT NOT NULL,
+	-- dHash, 64 бита в BIGINT; NULL, если файл не удалось декодировать
+	phash                  BIGINT,
+	-- Ранее присланное фото другого события, с которым совпал снимок
+	duplicate_of_event_id  UUID,
+	duplicate_of_photo_url TEXT,
+	hash_distance          INTEGER,
+	created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_sha256 ON anpr_photo_hashes (sha256);
+-- Почти дубликаты с расстоянием до 3 совпадают хотя бы в одной из четырёх 16-битных частей хеша
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_phash_0 ON anpr_photo_hashes ((phash & 65535));
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_phash_1 ON anpr_photo_hashes (((phash >> 16) & 65535));
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_phash_2 ON anpr_photo_hashes (((phash >> 32) & 65535));
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_phash_3 ON anpr_photo_hashes (((phash >> 48) & 65535));
+CREATE INDEX IF NOT EXISTS idx_anpr_photo_hashes_duplicates ON anpr_photo_hashes (event_time DESC)
+	WHERE duplicate_of_event_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_photo_hashes;
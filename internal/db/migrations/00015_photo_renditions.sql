@@ -0,0 +1,15 @@
+-- Уменьшенные копии фото событий (миниатюра и средний размер). Ключ — URL оригинала: копии строятся
+-- при загрузке, до сохранения события, как и хеши в anpr_photo_hashes.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_photo_renditions (
+	photo_url     TEXT PRIMARY KEY,
+	thumbnail_url TEXT,
+	medium_url    TEXT,
+	width         INTEGER NOT NULL,
+	height        INTEGER NOT NULL,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_photo_renditions;
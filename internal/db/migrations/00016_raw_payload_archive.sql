@@ -0,0 +1,8 @@
+-- Исходный payload события может храниться в хранилище фото (INGEST_RAW_PAYLOAD_STORAGE=storage):
+-- тогда raw_payload пуст, а raw_payload_key — ключ объекта с ним.
+
+-- +goose Up
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS raw_payload_key TEXT;
+
+-- +goose Down
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS raw_payload_key;
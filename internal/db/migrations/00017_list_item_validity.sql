@@ -0,0 +1,15 @@
+-- Срок действия записи списка: временные записи (арендованная на одну ночь техника) действуют
+-- с valid_from до valid_until. NULL — без ограничения с этой стороны.
+
+-- +goose Up
+ALTER TABLE anpr_list_items ADD COLUMN IF NOT EXISTS valid_from TIMESTAMPTZ;
+ALTER TABLE anpr_list_items ADD COLUMN IF NOT EXISTS valid_until TIMESTAMPTZ;
+ALTER TABLE anpr_list_items ADD CONSTRAINT anpr_list_items_validity_check
+	CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until > valid_from);
+CREATE INDEX IF NOT EXISTS idx_anpr_list_items_valid_until ON anpr_list_items(valid_until) WHERE valid_until IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_anpr_list_items_valid_until;
+ALTER TABLE anpr_list_items DROP CONSTRAINT IF EXISTS anpr_list_items_validity_check;
+ALTER TABLE anpr_list_items DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE anpr_list_items DROP COLUMN IF EXISTS valid_from;
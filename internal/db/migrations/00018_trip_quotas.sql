@@ -0,0 +1,23 @@
+-- Квота оплачиваемых рейсов за ночь: договор ограничивает число рейсов одной машины. Рейсы сверх
+-- квоты сохраняются с пометкой over_quota и не попадают в отчёты для оплаты.
+
+-- +goose Up
+-- Квота подрядчика на каждую его машину; NULL — квота по умолчанию (ACCESS_PAID_TRIPS_PER_NIGHT)
+ALTER TABLE anpr_contractor_access_rules ADD COLUMN IF NOT EXISTS paid_trips_per_night INTEGER;
+
+-- Квота отдельного номера; имеет приоритет над квотой подрядчика
+CREATE TABLE IF NOT EXISTS anpr_plate_trip_quotas (
+	normalized_plate     TEXT PRIMARY KEY,
+	paid_trips_per_night INTEGER NOT NULL CHECK (paid_trips_per_night >= 0),
+	created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS over_quota BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_over_quota ON anpr_events(event_time) WHERE over_quota;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_anpr_events_over_quota;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS over_quota;
+DROP TABLE IF EXISTS anpr_plate_trip_quotas;
+ALTER TABLE anpr_contractor_access_rules DROP COLUMN IF EXISTS paid_trips_per_night;
@@ -0,0 +1,17 @@
+-- Ведомости оплаты рейсов за месяц. Ведомость открытого месяца пересчитывается при каждой выгрузке,
+-- после выставления счёта месяц блокируется, и выгрузка отдаёт сохранённые строки без пересчёта.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_billing_periods (
+	period       TEXT PRIMARY KEY, -- месяц в формате YYYY-MM
+	period_from  TIMESTAMPTZ NOT NULL,
+	period_to    TIMESTAMPTZ NOT NULL,
+	lines        JSONB NOT NULL,
+	checksum     TEXT NOT NULL, -- sha256 строк ведомости: не меняется, пока не меняются данные
+	generated_at TIMESTAMPTZ NOT NULL,
+	locked_at    TIMESTAMPTZ,
+	locked_by    UUID
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_billing_periods;
@@ -0,0 +1,10 @@
+-- Мягкое удаление событий: удалённое администратором или по сроку хранения событие остаётся в БД
+-- до физической очистки (EVENTS_PURGE_GRACE), чтобы его можно было восстановить при споре.
+
+-- +goose Up
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_deleted_at ON anpr_events(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_anpr_events_deleted_at;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,17 @@
+-- Комментарии операторов к событиям («машина была полупустой, объём скорректирован вручную»).
+-- anpr_events секционирована по event_time, поэтому внешнего ключа на событие нет: комментарии
+-- удаляются вместе с событием при физической очистке.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_event_comments (
+	id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	event_id    UUID NOT NULL,
+	author_id   UUID NOT NULL,
+	author_role TEXT NOT NULL,
+	body        TEXT NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_event_comments_event ON anpr_event_comments(event_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_event_comments;
@@ -0,0 +1,11 @@
+-- Источник события: камера, импорт, ручной ввод или симулятор. Позволяет исключить загруженные
+-- задним числом и тестовые события из оперативных отчётов. Старые события считаются событиями камер.
+
+-- +goose Up
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'camera';
+ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_source_check
+	CHECK (source IN ('camera', 'import', 'manual', 'simulator'));
+
+-- +goose Down
+ALTER TABLE anpr_events DROP CONSTRAINT IF EXISTS anpr_events_source_check;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS source;
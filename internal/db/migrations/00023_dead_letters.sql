@@ -0,0 +1,23 @@
+-- Непринятые уведомления камер: тело запроса, заголовки и ошибка разбора. После исправления разбора
+-- уведомление можно обработать повторно; записи старше DEAD_LETTERS_RETENTION удаляются.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_dead_letters (
+	id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	endpoint          TEXT NOT NULL,
+	camera_id         TEXT NOT NULL DEFAULT '',
+	remote_addr       TEXT NOT NULL DEFAULT '',
+	content_type      TEXT NOT NULL DEFAULT '',
+	headers           JSONB,
+	payload           BYTEA NOT NULL,
+	payload_size      BIGINT NOT NULL,
+	error             TEXT NOT NULL,
+	received_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	replayed_at       TIMESTAMPTZ,
+	replayed_event_id UUID,
+	replay_error      TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_dead_letters_received ON anpr_dead_letters(received_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_dead_letters;
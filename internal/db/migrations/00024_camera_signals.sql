@@ -0,0 +1,10 @@
+-- Сигналы состояния камеры: heartbeat и videoloss, которые камеры шлют на адрес приёма событий.
+-- last_heartbeat_at — последний сигнал (время приёма), video_loss_since — с какого момента нет видеосигнала.
+
+-- +goose Up
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS last_heartbeat_at TIMESTAMPTZ;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS video_loss_since TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS video_loss_since;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS last_heartbeat_at;
@@ -0,0 +1,18 @@
+-- История запусков периодических задач планировщика (очистка, обслуживание секций, выгрузка белого
+-- списка и т. п.): по расписанию и вручную через API. Записи старше JOB_HISTORY_RETENTION удаляются.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_job_runs (
+	id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	job          TEXT NOT NULL,
+	trigger      TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	error        TEXT,
+	started_at   TIMESTAMPTZ NOT NULL,
+	finished_at  TIMESTAMPTZ,
+	triggered_by UUID
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_job_runs_job_started ON anpr_job_runs(job, started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_job_runs;
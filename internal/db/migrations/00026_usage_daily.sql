@@ -0,0 +1,18 @@
+-- Учёт использования по организациям за сутки: принятые события и объём их фото (по организации
+-- полигона камеры) и обращения к API (по организации пользователя). Строки пополняются фоновым
+-- сбросом счётчиков реплик; события полигонов без организации учитываются под нулевым UUID.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_usage_daily (
+	day             DATE NOT NULL,
+	organization_id UUID NOT NULL,
+	events_ingested BIGINT NOT NULL DEFAULT 0,
+	storage_bytes   BIGINT NOT NULL DEFAULT 0,
+	api_calls       BIGINT NOT NULL DEFAULT 0,
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (day, organization_id)
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_usage_daily_organization ON anpr_usage_daily(organization_id, day);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_usage_daily;
@@ -0,0 +1,16 @@
+-- Направление проезда, когда камера его не присылает. Для камеры задаётся направление по умолчанию
+-- (default_direction) и режим вывода (direction_inference): по номеру полосы (lane_directions,
+-- например "1:entry,2:exit") или чередованием въезд/выезд. direction_source события показывает,
+-- прислано направление камерой или выведено сервисом; у старых событий источник неизвестен (NULL).
+
+-- +goose Up
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS default_direction TEXT;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS direction_inference TEXT;
+ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS lane_directions TEXT;
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS direction_source TEXT;
+
+-- +goose Down
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS direction_source;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS lane_directions;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS direction_inference;
+ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS default_direction;
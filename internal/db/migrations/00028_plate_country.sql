@@ -0,0 +1,17 @@
+-- Страна и регион номера по его формату (см. utils.ParsePlate). Новые номера получают страну при
+-- создании; здесь она проставляется уже сохранённым номерам, у которых её нет. Нормализованные номера
+-- не переписываются: российские номера, сохранённые кириллицей, останутся без страны и будут заведены
+-- заново в латинице при следующем проезде (старую запись можно слить с новой через POST /api/v1/plates/:id/merge).
+
+-- +goose Up
+UPDATE anpr_plates SET country = 'KZ', region = RIGHT(normalized, 2)
+WHERE country IS NULL AND normalized ~ '^[0-9]{3}[A-Z]{2,3}[0-9]{2}$';
+
+UPDATE anpr_plates SET country = 'RU', region = SUBSTRING(normalized FROM 7)
+WHERE country IS NULL AND normalized ~ '^[ABEKMHOPCTYX][0-9]{3}[ABEKMHOPCTYX]{2}[0-9]{2,3}$';
+
+UPDATE anpr_plates SET country = 'KG', region = LEFT(normalized, 2)
+WHERE country IS NULL AND normalized ~ '^[0-9]{5}[A-Z]{3}$';
+
+-- +goose Down
+-- Проставленная страна не мешает предыдущим версиям: откат ничего не меняет
@@ -0,0 +1,21 @@
+-- Отслеживание номеров: пользователь получает уведомление о каждом проезде номера, подходящего под
+-- шаблон (нормализованный номер, * — любые символы, ? — один символ). Уведомление уходит в канал
+-- channel по адресу target (для telegram — чат) до expires_at.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_plate_watches (
+	id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id          UUID NOT NULL,
+	pattern          TEXT NOT NULL,
+	channel          TEXT NOT NULL,
+	target           TEXT NOT NULL,
+	note             TEXT,
+	expires_at       TIMESTAMPTZ NOT NULL,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_notified_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_plate_watches_user ON anpr_plate_watches(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_anpr_plate_watches_expires ON anpr_plate_watches(expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_plate_watches;
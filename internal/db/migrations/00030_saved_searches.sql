@@ -0,0 +1,27 @@
+-- Сохранённые поиски событий: фильтры /api/v1/events пользователя. Поиск с расписанием (daily, weekly)
+-- выгружается в CSV за прошедшие сутки или неделю и отправляется в Telegram или на почту; next_run_at —
+-- ближайшая выгрузка (NULL — без расписания). organization_id задан у пользователей полигона: выгрузка,
+-- как и их запросы, ограничена полигонами организации.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_saved_searches (
+	id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id         UUID NOT NULL,
+	organization_id UUID,
+	name            TEXT NOT NULL,
+	filters         JSONB NOT NULL DEFAULT '{}'::jsonb,
+	schedule        TEXT,
+	send_at         TEXT,
+	channel         TEXT,
+	target          TEXT,
+	next_run_at     TIMESTAMPTZ,
+	last_run_at     TIMESTAMPTZ,
+	last_error      TEXT,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_saved_searches_user ON anpr_saved_searches(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_anpr_saved_searches_due ON anpr_saved_searches(next_run_at) WHERE next_run_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_saved_searches;
@@ -0,0 +1,32 @@
+-- Почасовые счётчики событий по камерам для дашбордов: /api/v1/stats/hourly читает их вместо anpr_events.
+-- Задача event_stats_refresh пересчитывает последние часы (STATS_REFRESH_LOOKBACK), здесь счётчики
+-- заполняются по всей истории. Удалённые события не учитываются; события без полигона — под нулевым UUID.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_event_hourly_counts (
+	bucket     TIMESTAMPTZ NOT NULL,
+	camera_id  TEXT NOT NULL,
+	polygon_id UUID NOT NULL,
+	events     BIGINT NOT NULL DEFAULT 0,
+	entries    BIGINT NOT NULL DEFAULT 0,
+	exits      BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (bucket, camera_id, polygon_id)
+);
+CREATE INDEX IF NOT EXISTS idx_anpr_event_hourly_counts_camera ON anpr_event_hourly_counts(camera_id, bucket);
+
+INSERT INTO anpr_event_hourly_counts (bucket, camera_id, polygon_id, events, entries, exits)
+SELECT
+	date_trunc('hour', event_time AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+	camera_id,
+	COALESCE(polygon_id, '00000000-0000-0000-0000-000000000000'::uuid),
+	COUNT(*),
+	COUNT(*) FILTER (WHERE direction = 'entry'),
+	COUNT(*) FILTER (WHERE direction = 'exit')
+FROM anpr_events
+WHERE deleted_at IS NULL
+GROUP BY 1, 2, 3
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_event_hourly_counts;
@@ -0,0 +1,13 @@
+-- Версия данных номеров для условных запросов к /api/v1/plates (ETag, If-None-Match): счётчик растёт
+-- при любом изменении anpr_plates, в том числе при слиянии номеров и исправлении страны.
+
+-- +goose Up
+INSERT INTO anpr_data_versions (scope) VALUES ('plates') ON CONFLICT (scope) DO NOTHING;
+DROP TRIGGER IF EXISTS trg_anpr_plates_data_version ON anpr_plates;
+CREATE TRIGGER trg_anpr_plates_data_version
+	AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON anpr_plates
+	FOR EACH STATEMENT EXECUTE FUNCTION anpr_bump_data_version('plates');
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_anpr_plates_data_version ON anpr_plates;
+DELETE FROM anpr_data_versions WHERE scope = 'plates';
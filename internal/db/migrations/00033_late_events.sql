@@ -0,0 +1,10 @@
+-- События, которые камера переотправила с опозданием (после перезагрузки): приходят позже EVENT_MAX_AGE
+-- после event_time, сохраняются с пометкой late и не учитываются в рейсах, квотах и отчётах для оплаты.
+
+-- +goose Up
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS late BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_anpr_events_late ON anpr_events(event_time) WHERE late;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_anpr_events_late;
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS late;
@@ -0,0 +1,9 @@
+-- Версия статуса рейса: въезд, пришедший не по порядку (или перенесённый слиянием номеров), сдвигает квоту
+-- оплачиваемых рейсов уже принятых въездов ночи. Пересчёт меняет over_quota и увеличивает trip_revision,
+-- чтобы потребители могли отличить новое состояние рейса от уже обработанного.
+
+-- +goose Up
+ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS trip_revision INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE anpr_events DROP COLUMN IF EXISTS trip_revision;
@@ -0,0 +1,55 @@
+-- Календарь смен: окно работы полигона (или всех полигонов) на сезон. Отчёты группируют рейсы по сменам,
+-- а не по календарным суткам: ночная смена 20:00–06:00 — один отчётный период, а не два дня.
+-- Начальная смена повторяет окно 16:00–10:00 (Asia/Qyzylorda), которое раньше было зашито в отчёты.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_shifts (
+	id           UUID PRIMARY KEY,
+	-- NULL — смена всех полигонов, у которых нет собственной смены на эту дату
+	polygon_id   UUID REFERENCES anpr_polygons(id) ON DELETE CASCADE,
+	name         TEXT NOT NULL,
+	-- Сезон: даты начала смен, valid_to включительно (NULL — бессрочно)
+	valid_from   DATE NOT NULL,
+	valid_to     DATE,
+	-- Начало и конец смены в минутах от полуночи; конец не позже начала — смена через полночь
+	start_minute SMALLINT NOT NULL CHECK (start_minute >= 0 AND start_minute < 1440),
+	end_minute   SMALLINT NOT NULL CHECK (end_minute >= 0 AND end_minute < 1440),
+	time_zone    TEXT NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anpr_shifts_polygon_id ON anpr_shifts(polygon_id, valid_from);
+
+INSERT INTO anpr_shifts (id, polygon_id, name, valid_from, start_minute, end_minute, time_zone)
+SELECT uuid_generate_v4(), NULL, 'Ночная смена', DATE '2000-01-01', 960, 600, 'Asia/Qyzylorda'
+WHERE NOT EXISTS (SELECT 1 FROM anpr_shifts);
+
+-- anpr_event_shift — смена, к которой относится момент event_time на полигоне: смена полигона приоритетнее
+-- общей, из подходящих по сезону — начавшаяся позже. shift_date — дата начала смены в её часовом поясе,
+-- in_shift — момент попадает в окно смены (а не в перерыв до следующей).
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_event_shift(p_polygon_id UUID, p_event_time TIMESTAMPTZ)
+RETURNS TABLE (shift_id UUID, shift_date DATE, in_shift BOOLEAN)
+LANGUAGE sql STABLE AS $$
+	SELECT s.id, d.shift_date,
+		(p_event_time AT TIME ZONE s.time_zone) < d.shift_date + make_interval(mins => s.start_minute + CASE
+			WHEN s.end_minute > s.start_minute THEN s.end_minute - s.start_minute
+			ELSE s.end_minute - s.start_minute + 1440
+		END)
+	FROM anpr_shifts s
+	CROSS JOIN LATERAL (
+		SELECT ((p_event_time AT TIME ZONE s.time_zone) - make_interval(mins => s.start_minute))::date AS shift_date
+	) d
+	WHERE (s.polygon_id = p_polygon_id OR s.polygon_id IS NULL)
+		AND d.shift_date >= s.valid_from
+		AND (s.valid_to IS NULL OR d.shift_date <= s.valid_to)
+	ORDER BY s.polygon_id IS NULL, s.valid_from DESC
+	LIMIT 1
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS anpr_event_shift(UUID, TIMESTAMPTZ);
+DROP TABLE IF EXISTS anpr_shifts;
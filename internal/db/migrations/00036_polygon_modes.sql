@@ -0,0 +1,18 @@
+-- Режим работы полигона (normal/storm) при объявленном снежном ЧП: журнал переключений, текущий режим —
+-- последняя запись полигона (нет записей — normal). Записи не меняются и не удаляются: это аудит того,
+-- кто и когда переключал режим.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_polygon_mode_changes (
+	id         UUID PRIMARY KEY,
+	polygon_id UUID NOT NULL REFERENCES anpr_polygons(id) ON DELETE CASCADE,
+	mode       TEXT NOT NULL CHECK (mode IN ('normal', 'storm')),
+	reason     TEXT,
+	changed_by UUID NOT NULL,
+	changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_anpr_polygon_mode_changes_polygon ON anpr_polygon_mode_changes(polygon_id, changed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_polygon_mode_changes;
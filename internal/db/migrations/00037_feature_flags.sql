@@ -0,0 +1,20 @@
+-- Переопределения флагов возможностей (FEATURE_FLAGS): для всех (scope=global), организации или камеры.
+-- Камера важнее организации, организация важнее global, global важнее FEATURE_FLAGS.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_feature_flag_overrides (
+	id         UUID PRIMARY KEY,
+	flag       TEXT NOT NULL,
+	scope      TEXT NOT NULL CHECK (scope IN ('global', 'organization', 'camera')),
+	-- scope_id — id организации или камеры; для global — пустая строка
+	scope_id   TEXT NOT NULL DEFAULT '',
+	enabled    BOOLEAN NOT NULL,
+	reason     TEXT,
+	updated_by UUID NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (flag, scope, scope_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS anpr_feature_flag_overrides;
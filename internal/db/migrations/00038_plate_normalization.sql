@@ -0,0 +1,153 @@
+-- normalize_plate_number приводится к правилам utils.ParsePlate: 00028 изменила нормализацию только в Go
+-- (флаги KZ, RUS, KG убираются, кириллические двойники заменяются латиницей, ноль на месте буквы
+-- российского номера — буквой O), и соединения vehicles по normalize_plate_number(plate_number), а также
+-- anpr_sync_vehicle_to_whitelist расходились с приёмом событий. Сохранённые номера переписываются по новым
+-- правилам; номер, совпавший после этого с другим (например, российский номер, сохранённый кириллицей и
+-- оставленный 00028 без страны), сливается с ним так же, как в POST /api/v1/plates/:id/merge.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
+RETURNS TEXT AS $$
+DECLARE
+	cleaned TEXT;
+	latin   TEXT;
+	m       TEXT[];
+BEGIN
+	-- Без пробелов и дефисов, в верхнем регистре
+	cleaned := UPPER(REPLACE(REPLACE(REGEXP_REPLACE(plate_text, '^\s+|\s+$', '', 'g'), ' ', ''), '-', ''));
+	-- Строчные двойники перечислены отдельно: при локали C функция UPPER не меняет кириллицу
+	latin := TRANSLATE(cleaned, 'АВЕКМНОРСТУХавекмнорстух', 'ABEKMHOPCTYXABEKMHOPCTYX');
+
+	-- Казахстан: 123ABC02, 123AB02; флаг KZ слева
+	m := REGEXP_MATCH(latin, '^(?:KZ)?([0-9]{3}[A-Z]{2,3}[0-9]{2})$');
+	IF m IS NOT NULL THEN
+		RETURN m[1];
+	END IF;
+	-- Россия: A123BC77, A123BC777; надпись RUS у региона, ноль на месте буквы — O
+	m := REGEXP_MATCH(latin, '^([ABEKMHOPCTYX0])([0-9]{3})([ABEKMHOPCTYX0]{2})([0-9]{2,3})(?:RUS)?$');
+	IF m IS NOT NULL THEN
+		RETURN REPLACE(m[1], '0', 'O') || m[2] || REPLACE(m[3], '0', 'O') || m[4];
+	END IF;
+	-- Кыргызстан: 01KG123ABC → 01123ABC
+	m := REGEXP_MATCH(latin, '^([0-9]{2})(?:KG)?([0-9]{3}[A-Z]{3})$');
+	IF m IS NOT NULL THEN
+		RETURN m[1] || m[2];
+	END IF;
+	RETURN cleaned;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- Номера, у которых меняется нормализованное значение, и запись, которая остаётся: уже существующая
+-- с новым значением, иначе самая ранняя из совпавших
+CREATE TEMP TABLE anpr_plate_renormalize ON COMMIT DROP AS
+SELECT p.id, p.created_at, normalize_plate_number(p.normalized) AS normalized
+FROM anpr_plates p
+WHERE normalize_plate_number(p.normalized) <> p.normalized;
+
+CREATE TEMP TABLE anpr_plate_renormalize_merge ON COMMIT DROP AS
+SELECT r.id AS source_id, COALESCE(existing.id, first.id) AS target_id, r.normalized
+FROM anpr_plate_renormalize r
+LEFT JOIN anpr_plates existing ON existing.normalized = r.normalized
+CROSS JOIN LATERAL (
+	SELECT f.id FROM anpr_plate_renormalize f
+	WHERE f.normalized = r.normalized
+	ORDER BY f.created_at, f.id
+	LIMIT 1
+) first;
+
+-- Слияние совпавших номеров (см. ANPRRepository.MergePlates)
+UPDATE anpr_events e SET plate_id = m.target_id
+FROM anpr_plate_renormalize_merge m
+WHERE e.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+UPDATE anpr_events_rejected e SET plate_id = m.target_id
+FROM anpr_plate_renormalize_merge m
+WHERE e.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+UPDATE anpr_list_item_history h SET plate_id = m.target_id
+FROM anpr_plate_renormalize_merge m
+WHERE h.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+INSERT INTO anpr_list_items (list_id, plate_id, note, created_at, valid_from, valid_until)
+SELECT li.list_id, m.target_id, li.note, li.created_at, li.valid_from, li.valid_until
+FROM anpr_list_items li
+JOIN anpr_plate_renormalize_merge m ON li.plate_id = m.source_id AND m.source_id <> m.target_id
+ON CONFLICT (list_id, plate_id) DO NOTHING;
+
+DELETE FROM anpr_list_items li
+USING anpr_plate_renormalize_merge m
+WHERE li.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+UPDATE anpr_plate_aliases a SET plate_id = m.target_id
+FROM anpr_plate_renormalize_merge m
+WHERE a.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+DELETE FROM anpr_plates p
+USING anpr_plate_renormalize_merge m
+WHERE p.id = m.source_id AND m.source_id <> m.target_id;
+
+-- Записи истории, которые породило удаление элементов списков слитых номеров
+DELETE FROM anpr_list_item_history h
+USING anpr_plate_renormalize_merge m
+WHERE h.plate_id = m.source_id AND m.source_id <> m.target_id;
+
+-- Оставшиеся записи получают новое значение и страну (см. 00028)
+UPDATE anpr_plates p SET normalized = m.normalized
+FROM anpr_plate_renormalize_merge m
+WHERE p.id = m.source_id AND m.source_id = m.target_id;
+
+UPDATE anpr_plates SET country = 'KZ', region = RIGHT(normalized, 2)
+WHERE country IS NULL AND normalized ~ '^[0-9]{3}[A-Z]{2,3}[0-9]{2}$';
+
+UPDATE anpr_plates SET country = 'RU', region = SUBSTRING(normalized FROM 7)
+WHERE country IS NULL AND normalized ~ '^[ABEKMHOPCTYX][0-9]{3}[ABEKMHOPCTYX]{2}[0-9]{2,3}$';
+
+UPDATE anpr_plates SET country = 'KG', region = LEFT(normalized, 2)
+WHERE country IS NULL AND normalized ~ '^[0-9]{5}[A-Z]{3}$';
+
+-- Номер в событиях
+UPDATE anpr_events SET normalized_plate = normalize_plate_number(normalized_plate)
+WHERE normalized_plate <> normalize_plate_number(normalized_plate);
+
+UPDATE anpr_events_rejected SET normalized_plate = normalize_plate_number(normalized_plate)
+WHERE normalized_plate <> normalize_plate_number(normalized_plate);
+
+-- Псевдонимы и квоты номеров хранятся по нормализованному номеру: из совпавших после перезаписи
+-- остаётся уже нормализованная запись, иначе первая по алфавиту
+DELETE FROM anpr_plate_aliases a
+USING anpr_plate_aliases b
+WHERE a.alias <> b.alias
+	AND normalize_plate_number(a.alias) = normalize_plate_number(b.alias)
+	AND normalize_plate_number(a.alias) <> a.alias
+	AND (b.alias = normalize_plate_number(b.alias) OR b.alias < a.alias);
+
+UPDATE anpr_plate_aliases SET alias = normalize_plate_number(alias)
+WHERE alias <> normalize_plate_number(alias);
+
+-- Псевдоним, совпавший с самим номером, подменял бы номер им же
+DELETE FROM anpr_plate_aliases a
+USING anpr_plates p
+WHERE p.normalized = a.alias;
+
+DELETE FROM anpr_plate_trip_quotas a
+USING anpr_plate_trip_quotas b
+WHERE a.normalized_plate <> b.normalized_plate
+	AND normalize_plate_number(a.normalized_plate) = normalize_plate_number(b.normalized_plate)
+	AND normalize_plate_number(a.normalized_plate) <> a.normalized_plate
+	AND (b.normalized_plate = normalize_plate_number(b.normalized_plate) OR b.normalized_plate < a.normalized_plate);
+
+UPDATE anpr_plate_trip_quotas SET normalized_plate = normalize_plate_number(normalized_plate)
+WHERE normalized_plate <> normalize_plate_number(normalized_plate);
+
+-- +goose Down
+-- Откат возвращает прежнюю функцию; переписанные и слитые номера не восстанавливаются
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
+RETURNS TEXT AS $$
+BEGIN
+	RETURN UPPER(REGEXP_REPLACE(plate_text, '[^A-Z0-9]', '', 'g'));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
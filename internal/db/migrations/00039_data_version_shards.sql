@@ -0,0 +1,60 @@
+-- Версии данных без общей горячей строки: каждая вставка в anpr_events увеличивала одну строку
+-- anpr_data_versions, и параллельные приёмы событий ждали блокировку друг друга. Теперь счётчик области
+-- разбит на строки по соединениям (pg_backend_pid() % 16): соединения пишут в разные строки, а версия
+-- области — сумма строк, время изменения — наибольшее из них.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS anpr_data_version_shards (
+	scope      TEXT NOT NULL,
+	shard      SMALLINT NOT NULL,
+	version    BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (scope, shard)
+);
+-- Текущие версии переносятся в нулевую строку, чтобы версии не уменьшились у клиентов
+INSERT INTO anpr_data_version_shards (scope, shard, version, updated_at)
+SELECT scope, 0, version, updated_at FROM anpr_data_versions
+ON CONFLICT (scope, shard) DO NOTHING;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_touch_data_version(p_scope TEXT)
+RETURNS VOID AS $$
+BEGIN
+	INSERT INTO anpr_data_version_shards (scope, shard, version)
+	VALUES (p_scope, pg_backend_pid() % 16, 1)
+	ON CONFLICT (scope, shard) DO UPDATE
+		SET version = anpr_data_version_shards.version + 1, updated_at = now();
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_bump_data_version()
+RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM anpr_touch_data_version(TG_ARGV[0]);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP TABLE IF EXISTS anpr_data_versions;
+
+-- +goose Down
+CREATE TABLE IF NOT EXISTS anpr_data_versions (
+	scope      TEXT PRIMARY KEY,
+	version    BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+INSERT INTO anpr_data_versions (scope, version, updated_at)
+SELECT scope, SUM(version), MAX(updated_at) FROM anpr_data_version_shards GROUP BY scope
+ON CONFLICT (scope) DO NOTHING;
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION anpr_bump_data_version()
+RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE anpr_data_versions SET version = version + 1, updated_at = now() WHERE scope = TG_ARGV[0];
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP FUNCTION IF EXISTS anpr_touch_data_version(TEXT);
+DROP TABLE IF EXISTS anpr_data_version_shards;
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	seen := map[int64]string{}
+	for _, name := range names {
+		var version int64
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, "migrations/"), "%05d_", &version); err != nil {
+			t.Errorf("%s: file name must start with a 5-digit version: %v", name, err)
+			continue
+		}
+		if version == vehicleTypeBackfillVersion {
+			t.Errorf("%s: version %d is taken by the Go migration", name, version)
+		}
+		if prev, ok := seen[version]; ok {
+			t.Errorf("%s: duplicate version %d (also %s)", name, version, prev)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(migrationFiles, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, section := range []string{"-- +goose Up", "-- +goose Down"} {
+			if !strings.Contains(string(content), section) {
+				t.Errorf("%s: missing %q section", name, section)
+			}
+		}
+		if begin, end := strings.Count(string(content), "-- +goose StatementBegin"), strings.Count(string(content), "-- +goose StatementEnd"); begin != end {
+			t.Errorf("%s: %d StatementBegin vs %d StatementEnd", name, begin, end)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package anpr
+
+// Направления проезда
+const (
+	DirectionEntry = "entry"
+	DirectionExit  = "exit"
+)
+
+// Откуда взято направление события (anpr_events.direction_source)
+const (
+	// DirectionSourceReported — направление прислала камера
+	DirectionSourceReported = "reported"
+	// DirectionSourceCameraDefault — камера не прислала направление, взято направление камеры по умолчанию
+	DirectionSourceCameraDefault = "camera_default"
+	// DirectionSourceLane — выведено по номеру полосы (lane_directions камеры)
+	DirectionSourceLane = "lane"
+	// DirectionSourceSequence — выведено чередованием: после въезда номера на полигон — выезд, и наоборот
+	DirectionSourceSequence = "sequence"
+	// DirectionSourceFallback — направление неизвестно, событие считается въездом
+	DirectionSourceFallback = "fallback"
+)
@@ -17,13 +17,18 @@ type VehicleInfo struct {
 }
 
 type EventPayload struct {
-	CameraID    string                 `json:"camera_id"`
-	CameraModel string                 `json:"camera_model,omitempty"`
-	Plate       string                 `json:"plate"`
-	Confidence  float64                `json:"confidence"`
-	Direction   string                 `json:"direction"`
-	Lane        int                    `json:"lane"`
-	EventTime   time.Time              `json:"event_time"`
+	CameraID    string    `json:"camera_id"`
+	CameraModel string    `json:"camera_model,omitempty"`
+	Plate       string    `json:"plate"`
+	Confidence  float64   `json:"confidence"`
+	Direction   string    `json:"direction"`
+	Lane        int       `json:"lane"`
+	EventTime   time.Time `json:"event_time"`
+	// ReceivedAt — когда событие было принято впервые (заполняется ретрансляторами; из запросов без
+	// EDGE_TOKEN отбрасывается, и используется время сервера)
+	ReceivedAt *time.Time `json:"received_at,omitempty"`
+	// Source — источник события (Source*); если не указан, берётся заголовок X-Event-Source, иначе camera
+	Source      string                 `json:"source,omitempty"`
 	Vehicle     VehicleInfo            `json:"vehicle"`
 	SnapshotURL string                 `json:"snapshot_url,omitempty"`
 	RawPayload  map[string]interface{} `json:"raw_payload,omitempty"`
@@ -39,6 +44,54 @@ type Event struct {
 	PlateID uuid.UUID
 	EventPayload
 	NormalizedPlate string
+	// EventTimeSkewed — время события расходится с временем сервера больше допустимого
+	EventTimeSkewed bool
+	// ClockCorrectionSeconds — поправка, вычтенная из времени камеры при автокоррекции часов
+	ClockCorrectionSeconds *float64
+	// OutOfSchedule — событие пришло вне расписания камеры (не учитывается в рейсах и оповещениях)
+	OutOfSchedule bool
+	// Late — камера прислала событие позже EVENT_MAX_AGE после его времени (переотправка после
+	// перезагрузки): сохраняется, но не участвует в решениях о доступе, квотах, рейсах и оповещениях
+	Late bool
+	// WrongDestination — машина подрядчика приехала на полигон, за которым подрядчик не закреплён
+	WrongDestination bool
+	// OverQuota — въезд сверх квоты оплачиваемых рейсов за ночь: сохраняется, но не оплачивается
+	OverQuota bool
+	// Decision — решение о доступе, принятое по правилам
+	Decision *AccessDecision
+	// VehicleTypeRaw — тип транспорта в том виде, в каком его прислала камера
+	// (Vehicle.Type содержит каноническое значение)
+	VehicleTypeRaw string
+	// DirectionSource — откуда взято Direction (DirectionSource*)
+	DirectionSource string
+	// RawPayloadKey — ключ RawPayload в хранилище фото; при заполненном ключе RawPayload в БД не пишется
+	RawPayloadKey string
+}
+
+// Решения о доступе
+const (
+	DecisionAllow = "ALLOW"
+	DecisionDeny  = "DENY"
+)
+
+// Причины решений о доступе
+const (
+	ReasonRegisteredVehicle         = "registered_vehicle"
+	ReasonBlacklisted               = "blacklisted"
+	ReasonOutsideCameraSchedule     = "outside_camera_schedule"
+	ReasonOutsideContractorSchedule = "outside_contractor_schedule"
+	ReasonMaxTripsExceeded          = "max_trips_exceeded"
+	// ReasonLateEvent — событие пришло с опозданием, правила доступа к нему не применялись
+	ReasonLateEvent = "late_event"
+	// ReasonOverQuota — въезд разрешён, но превышает квоту оплачиваемых рейсов за ночь
+	ReasonOverQuota = "over_quota"
+)
+
+// AccessDecision — решение о доступе по событию: результат, машиночитаемая причина и пояснение
+type AccessDecision struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+	Detail   string `json:"detail,omitempty"`
 }
 
 type ListHit struct {
@@ -48,12 +101,20 @@ type ListHit struct {
 }
 
 type ProcessResult struct {
-	EventID       uuid.UUID `json:"event_id"`
-	PlateID       uuid.UUID `json:"plate_id"`
-	Plate         string    `json:"plate"`
-	VehicleExists bool      `json:"vehicle_exists"`   // true если номер найден в vehicles
-	Hits          []ListHit `json:"hits,omitempty"`   // Оставляем для обратной совместимости, всегда пустой
-	PhotoURLs     []string  `json:"photos,omitempty"` // URLs загруженных фотографий
+	EventID       uuid.UUID       `json:"event_id"`
+	PlateID       uuid.UUID       `json:"plate_id"`
+	Plate         string          `json:"plate"`
+	VehicleExists bool            `json:"vehicle_exists"`   // true если номер найден в vehicles
+	Hits          []ListHit       `json:"hits,omitempty"`   // Оставляем для обратной совместимости, всегда пустой
+	PhotoURLs     []string        `json:"photos,omitempty"` // URLs загруженных фотографий
+	Decision      *AccessDecision `json:"decision,omitempty"`
+	// Lists — списки, в которых состоит номер (в v1 вместо них отдаётся пустой Hits)
+	Lists []ListHit `json:"lists,omitempty"`
+	// ContractorID — подрядчик сопоставленного транспорта
+	ContractorID *uuid.UUID `json:"contractor_id,omitempty"`
+	// TripID — рейс, который событие открывает в отчётах (идентификатор рейса — ID события); nil, если
+	// событие не считается рейсом
+	TripID *uuid.UUID `json:"trip_id,omitempty"`
 }
 
 type EventPhoto struct {
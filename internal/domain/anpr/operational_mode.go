@@ -0,0 +1,19 @@
+package anpr
+
+import "slices"
+
+// Режимы работы полигона
+const (
+	// ModeNormal — обычный режим: правила доступа без изменений
+	ModeNormal = "normal"
+	// ModeStorm — объявленное снежное ЧП: расписания подрядчиков не действуют, лимит рейсов за ночь —
+	// ACCESS_STORM_MAX_TRIPS_PER_NIGHT
+	ModeStorm = "storm"
+)
+
+var operationalModes = []string{ModeNormal, ModeStorm}
+
+// IsOperationalMode проверяет, что значение — один из известных режимов работы полигона
+func IsOperationalMode(value string) bool {
+	return slices.Contains(operationalModes, value)
+}
@@ -0,0 +1,27 @@
+package anpr
+
+import "slices"
+
+// Источники событий: откуда событие попало в сервис
+const (
+	// SourceCamera — камера прислала событие сама (значение по умолчанию)
+	SourceCamera = "camera"
+	// SourceImport — загрузка архива или CSV задним числом
+	SourceImport = "import"
+	// SourceManual — событие внесено оператором вручную
+	SourceManual = "manual"
+	// SourceSimulator — событие сгенерировано anpr-simulator
+	SourceSimulator = "simulator"
+)
+
+var eventSources = []string{SourceCamera, SourceImport, SourceManual, SourceSimulator}
+
+// IsEventSource проверяет, что значение — один из известных источников событий
+func IsEventSource(value string) bool {
+	return slices.Contains(eventSources, value)
+}
+
+// EventSources возвращает список источников событий
+func EventSources() []string {
+	return slices.Clone(eventSources)
+}
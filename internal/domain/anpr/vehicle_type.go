@@ -0,0 +1,200 @@
+package anpr
+
+import (
+	"sort"
+	"strings"
+)
+
+// Канонические типы транспорта. Камеры Hikvision присылают тип строкой ("truck", "largeBus"),
+// кодом GAT ("H11", "K33") или числовым кодом классификатора — всё приводится к этим значениям.
+const (
+	VehicleTypeCar        = "car"
+	VehicleTypeSUV        = "suv"
+	VehicleTypeVan        = "van"
+	VehicleTypeBus        = "bus"
+	VehicleTypeTruck      = "truck"
+	VehicleTypeMotorcycle = "motorcycle"
+	VehicleTypeOther      = "other"
+	VehicleTypeUnknown    = "unknown"
+)
+
+var vehicleTypes = []string{
+	VehicleTypeCar,
+	VehicleTypeSUV,
+	VehicleTypeVan,
+	VehicleTypeBus,
+	VehicleTypeTruck,
+	VehicleTypeMotorcycle,
+	VehicleTypeOther,
+	VehicleTypeUnknown,
+}
+
+// vehicleTypeAliases — строковые типы ISAPI и их варианты (ключи в нижнем регистре без "_", "-" и пробелов)
+var vehicleTypeAliases = map[string]string{
+	"car":               VehicleTypeCar,
+	"vehicle":           VehicleTypeCar,
+	"smallcar":          VehicleTypeCar,
+	"microcar":          VehicleTypeCar,
+	"sedan":             VehicleTypeCar,
+	"saloon":            VehicleTypeCar,
+	"sportsedan":        VehicleTypeCar,
+	"hatchback":         VehicleTypeCar,
+	"passengercar":      VehicleTypeCar,
+	"suv":               VehicleTypeSUV,
+	"mpv":               VehicleTypeSUV,
+	"suvmpv":            VehicleTypeSUV,
+	"van":               VehicleTypeVan,
+	"minibus":           VehicleTypeVan,
+	"smallbus":          VehicleTypeBus,
+	"bus":               VehicleTypeBus,
+	"largebus":          VehicleTypeBus,
+	"mediumbus":         VehicleTypeBus,
+	"truck":             VehicleTypeTruck,
+	"largetruck":        VehicleTypeTruck,
+	"heavytruck":        VehicleTypeTruck,
+	"mediumtruck":       VehicleTypeTruck,
+	"lighttruck":        VehicleTypeTruck,
+	"smalltruck":        VehicleTypeTruck,
+	"minitruck":         VehicleTypeTruck,
+	"pickup":            VehicleTypeTruck,
+	"pickuptruck":       VehicleTypeTruck,
+	"containertruck":    VehicleTypeTruck,
+	"dumptruck":         VehicleTypeTruck,
+	"slagcar":           VehicleTypeTruck,
+	"tumbrel":           VehicleTypeTruck,
+	"crane":             VehicleTypeTruck,
+	"oiltanktruck":      VehicleTypeTruck,
+	"tanker":            VehicleTypeTruck,
+	"concretemixer":     VehicleTypeTruck,
+	"platformtrailer":   VehicleTypeTruck,
+	"trailer":           VehicleTypeTruck,
+	"motorcycle":        VehicleTypeMotorcycle,
+	"motorbike":         VehicleTypeMotorcycle,
+	"twowheelvehicle":   VehicleTypeMotorcycle,
+	"tricycle":          VehicleTypeOther,
+	"trike":             VehicleTypeOther,
+	"threewheelvehicle": VehicleTypeOther,
+	"nonmotorvehicle":   VehicleTypeOther,
+	"buggy":             VehicleTypeOther,
+	"other":             VehicleTypeOther,
+	"unknown":           VehicleTypeUnknown,
+}
+
+// vehicleTypeCodes — числовые коды классификатора Hikvision (VTR_RESULT_*)
+var vehicleTypeCodes = map[string]string{
+	"0":  VehicleTypeOther,
+	"1":  VehicleTypeBus,
+	"2":  VehicleTypeTruck,
+	"3":  VehicleTypeCar,
+	"4":  VehicleTypeVan,
+	"5":  VehicleTypeTruck,
+	"7":  VehicleTypeTruck,
+	"8":  VehicleTypeOther,
+	"9":  VehicleTypeSUV,
+	"10": VehicleTypeBus,
+	"11": VehicleTypeCar,
+	"12": VehicleTypeOther,
+	"13": VehicleTypeCar,
+	"14": VehicleTypeCar,
+	"15": VehicleTypeTruck,
+	"16": VehicleTypeTruck,
+	"17": VehicleTypeTruck,
+	"18": VehicleTypeTruck,
+	"19": VehicleTypeTruck,
+	"20": VehicleTypeTruck,
+	"21": VehicleTypeTruck,
+	"22": VehicleTypeTruck,
+	"23": VehicleTypeCar,
+	"24": VehicleTypeCar,
+	"25": VehicleTypeCar,
+	"26": VehicleTypeBus,
+}
+
+// vehicleTypeGATClasses — первая буква кода GAT (ГОСТ GA 24.4): K — пассажирские, H — грузовые,
+// Q — тягачи, Z — спецтехника, M — мототранспорт
+var vehicleTypeGATClasses = map[byte]string{
+	'H': VehicleTypeTruck,
+	'Q': VehicleTypeTruck,
+	'Z': VehicleTypeTruck,
+	'G': VehicleTypeTruck,
+	'B': VehicleTypeTruck,
+	'M': VehicleTypeMotorcycle,
+	'X': VehicleTypeOther,
+}
+
+// NormalizeVehicleType приводит тип транспорта от камеры к каноническому значению.
+// Пустое значение даёт "", нераспознанное — VehicleTypeUnknown.
+func NormalizeVehicleType(raw string) string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return ""
+	}
+
+	if t, ok := vehicleTypeCodes[value]; ok {
+		return t
+	}
+
+	key := strings.ToLower(value)
+	key = strings.NewReplacer("_", "", "-", "", " ", "", "/", "").Replace(key)
+	if t, ok := vehicleTypeAliases[key]; ok {
+		return t
+	}
+
+	if t, ok := normalizeGATVehicleType(strings.ToUpper(value)); ok {
+		return t
+	}
+
+	return VehicleTypeUnknown
+}
+
+// normalizeGATVehicleType разбирает код GAT вида "K33" или "H11"
+func normalizeGATVehicleType(code string) (string, bool) {
+	if len(code) != 3 || code[1] < '0' || code[1] > '9' || code[2] < '0' || code[2] > '9' {
+		return "", false
+	}
+	if code[0] == 'K' {
+		// K1x, K2x — большие и средние автобусы, K3x/K4x — легковые и микроавтобусы
+		switch code[1] {
+		case '1', '2':
+			return VehicleTypeBus, true
+		case '3':
+			if code[2] == '1' || code[2] == '2' {
+				return VehicleTypeVan, true
+			}
+			return VehicleTypeCar, true
+		default:
+			return VehicleTypeCar, true
+		}
+	}
+	t, ok := vehicleTypeGATClasses[code[0]]
+	return t, ok
+}
+
+// IsVehicleType проверяет, что значение — один из канонических типов
+func IsVehicleType(value string) bool {
+	for _, t := range vehicleTypes {
+		if t == value {
+			return true
+		}
+	}
+	return false
+}
+
+// VehicleTypes возвращает список канонических типов
+func VehicleTypes() []string {
+	result := append([]string(nil), vehicleTypes...)
+	sort.Strings(result)
+	return result
+}
+
+// VehicleTypeAliases возвращает соответствие строковых типов камеры каноническим (для миграции данных)
+func VehicleTypeAliases() map[string]string {
+	result := make(map[string]string, len(vehicleTypeAliases)+len(vehicleTypeCodes))
+	for k, v := range vehicleTypeAliases {
+		result[k] = v
+	}
+	for k, v := range vehicleTypeCodes {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package anpr
+
+import "testing"
+
+func TestNormalizeVehicleType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"truck", VehicleTypeTruck},
+		{"largeBus", VehicleTypeBus},
+		{"SUVMPV", VehicleTypeSUV},
+		{"pickup_truck", VehicleTypeTruck},
+		{"vehicle", VehicleTypeCar},
+		{"2", VehicleTypeTruck},
+		{"3", VehicleTypeCar},
+		{"K33", VehicleTypeCar},
+		{"K11", VehicleTypeBus},
+		{"H21", VehicleTypeTruck},
+		{"M22", VehicleTypeMotorcycle},
+		{"X99", VehicleTypeOther},
+		{"spaceship", VehicleTypeUnknown},
+	}
+	for _, tt := range tests {
+		if got := NormalizeVehicleType(tt.raw); got != tt.want {
+			t.Errorf("NormalizeVehicleType(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package edge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dirQueueExt = ".json"
+	dirQueueTmp = ".tmp"
+)
+
+// DirQueue — очередь запросов камер файлами в каталоге: один запрос — один JSON-файл с именем по ID.
+// Переживает перезапуск и обрыв питания: файл записывается во временный, сбрасывается на
+// диск и переименовывается. Состояние запросов (без тел) держится в памяти, тела читаются при пересылке.
+type DirQueue struct {
+	dir string
+
+	mu     sync.Mutex
+	nextID int64
+	// items — запросы очереди без тел
+	items map[int64]*Request
+}
+
+// OpenDirQueue открывает (и при необходимости создаёт) каталог очереди
+func OpenDirQueue(dir string) (*DirQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create relay queue directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read relay queue directory: %w", err)
+	}
+
+	q := &DirQueue{dir: dir, nextID: 1, items: map[int64]*Request{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		// Недописанный файл: запрос не был подтверждён камере, она пришлёт его повторно
+		if strings.HasSuffix(name, dirQueueTmp) {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, dirQueueExt) {
+			continue
+		}
+		req, err := q.read(name)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = nil
+		q.items[req.ID] = req
+		if req.ID >= q.nextID {
+			q.nextID = req.ID + 1
+		}
+	}
+	return q, nil
+}
+
+// Close ничего не держит открытым: каждый запрос записывается на диск сразу
+func (q *DirQueue) Close() error {
+	return nil
+}
+
+// Add ставит запрос в очередь
+func (q *DirQueue) Add(_ context.Context, req *Request) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	req.ID = q.nextID
+	req.Status = StatusPending
+	if req.NextAttemptAt.IsZero() {
+		req.NextAttemptAt = req.ReceivedAt
+	}
+	if err := q.write(req); err != nil {
+		return fmt.Errorf("failed to spool camera request: %w", err)
+	}
+	q.nextID++
+	item := *req
+	item.Body = nil
+	q.items[item.ID] = &item
+	return nil
+}
+
+// Due возвращает до limit запросов, которые пора отправить, в порядке приёма
+func (q *DirQueue) Due(_ context.Context, now time.Time, limit int) ([]Request, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := make([]int64, 0)
+	for id, item := range q.items {
+		if item.Status == StatusPending && !item.NextAttemptAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	requests := make([]Request, 0, len(ids))
+	for _, id := range ids {
+		req, err := q.read(fileName(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spooled requests: %w", err)
+		}
+		requests = append(requests, *req)
+	}
+	return requests, nil
+}
+
+// MarkDone отмечает запрос отправленным (StatusSent) или отвергнутым (StatusRejected)
+func (q *DirQueue) MarkDone(_ context.Context, id int64, status string, upstreamStatus int, errMsg *string, at time.Time) error {
+	err := q.update(id, func(req *Request) {
+		req.Status = status
+		req.UpstreamStatus = upstreamStatus
+		req.LastError = errMsg
+		req.SentAt = &at
+		req.Attempts++
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark spooled request %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkRetry откладывает запрос до nextAttemptAt
+func (q *DirQueue) MarkRetry(_ context.Context, id int64, upstreamStatus int, errMsg string, nextAttemptAt time.Time) error {
+	err := q.update(id, func(req *Request) {
+		req.UpstreamStatus = upstreamStatus
+		req.LastError = &errMsg
+		req.NextAttemptAt = nextAttemptAt
+		req.Attempts++
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule spooled request %d: %w", id, err)
+	}
+	return nil
+}
+
+// Purge удаляет отправленные запросы, отправленные раньше before. Отвергнутые остаются для разбора.
+func (q *DirQueue) Purge(_ context.Context, before time.Time) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var purged int64
+	for id, item := range q.items {
+		if item.Status != StatusSent || item.SentAt == nil || !item.SentAt.Before(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(q.dir, fileName(id))); err != nil && !os.IsNotExist(err) {
+			return purged, fmt.Errorf("failed to purge spooled requests: %w", err)
+		}
+		delete(q.items, id)
+		purged++
+	}
+	return purged, nil
+}
+
+// Stats возвращает число ожидающих и отвергнутых запросов
+func (q *DirQueue) Stats(_ context.Context) (SpoolStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var (
+		stats  SpoolStats
+		oldest *Request
+	)
+	for _, item := range q.items {
+		switch item.Status {
+		case StatusPending:
+			stats.Pending++
+			if oldest == nil || item.ID < oldest.ID {
+				oldest = item
+			}
+		case StatusRejected:
+			stats.Rejected++
+		}
+	}
+	if oldest != nil {
+		at := oldest.ReceivedAt
+		stats.OldestPendingAt = &at
+	}
+	return stats, nil
+}
+
+// update перечитывает запрос с диска, меняет его и записывает обратно
+func (q *DirQueue) update(id int64, apply func(req *Request)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.items[id]; !ok {
+		return fmt.Errorf("request %d is not in the queue", id)
+	}
+	req, err := q.read(fileName(id))
+	if err != nil {
+		return err
+	}
+	apply(req)
+	if err := q.write(req); err != nil {
+		return err
+	}
+	req.Body = nil
+	q.items[id] = req
+	return nil
+}
+
+func (q *DirQueue) read(name string) (*Request, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("read queued request %s: %w", name, err)
+	}
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("decode queued request %s: %w", name, err)
+	}
+	if id, err := strconv.ParseInt(strings.TrimSuffix(name, dirQueueExt), 10, 64); err == nil {
+		req.ID = id
+	}
+	return &req, nil
+}
+
+// write записывает запрос атомарно: во временный файл, fsync, переименование
+func (q *DirQueue) write(req *Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(q.dir, fileName(req.ID))
+	tmp := path + dirQueueTmp
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fileName — имя файла запроса; ведущие нули сохраняют порядок приёма при сортировке по имени
+func fileName(id int64) string {
+	return fmt.Sprintf("%020d%s", id, dirQueueExt)
+}
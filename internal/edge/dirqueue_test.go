@@ -0,0 +1,67 @@
+package edge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirQueue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "relay")
+
+	queue, err := OpenDirQueue(dir)
+	if err != nil {
+		t.Fatalf("OpenDirQueue: %v", err)
+	}
+	for _, body := range []string{`{"plate":"A"}`, `{"plate":"B"}`, `{"plate":"C"}`} {
+		req := &Request{Path: "/api/v1/anpr/events", Headers: `{"X-Event-Source":"manual"}`, Body: []byte(body), ReceivedAt: now}
+		if err := queue.Add(ctx, req); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if err := queue.MarkDone(ctx, 1, StatusSent, 201, nil, now); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if err := queue.MarkRetry(ctx, 2, 503, "upstream responded with status 503", now.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkRetry: %v", err)
+	}
+	// Оборванная запись не должна попасть в очередь после перезапуска
+	if err := os.WriteFile(filepath.Join(dir, fileName(9)+dirQueueTmp), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Очередь переживает перезапуск: состояние и тела читаются с диска
+	queue, err = OpenDirQueue(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	due, err := queue.Due(ctx, now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != 3 || string(due[0].Body) != `{"plate":"C"}` || due[0].HeaderMap()["X-Event-Source"] != "manual" {
+		t.Fatalf("Due(now) = %+v, %v; want request 3", due, err)
+	}
+	due, err = queue.Due(ctx, now.Add(time.Minute), 10)
+	if err != nil || len(due) != 2 || due[0].ID != 2 || due[0].Attempts != 1 || due[0].UpstreamStatus != 503 {
+		t.Fatalf("Due(now+1m) = %+v, %v; want requests 2 and 3", due, err)
+	}
+
+	req := &Request{Path: "/api/v1/anpr/events", Body: []byte(`{}`), ReceivedAt: now}
+	if err := queue.Add(ctx, req); err != nil || req.ID != 4 {
+		t.Fatalf("Add after reopen: id = %d, err = %v; want 4", req.ID, err)
+	}
+	stats, err := queue.Stats(ctx)
+	if err != nil || stats.Pending != 3 || stats.OldestPendingAt == nil {
+		t.Fatalf("Stats() = %+v, %v; want 3 pending", stats, err)
+	}
+
+	purged, err := queue.Purge(ctx, now.Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Fatalf("Purge() = %d, %v; want 1", purged, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fileName(1))); !os.IsNotExist(err) {
+		t.Fatalf("sent request file was not removed: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package edge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/lifecycle"
+)
+
+// Заголовки, которые шлюз добавляет к пересылаемому запросу
+const (
+	// ReceivedAtHeader — когда шлюз принял запрос камеры (RFC 3339); центральный сервис берёт его
+	// временем события, если камера время не прислала
+	ReceivedAtHeader = "X-Edge-Received-At"
+	// DeviceHeader — имя шлюза (EDGE_DEVICE_ID)
+	DeviceHeader = "X-Edge-Device"
+	// TokenHeader — общий секрет шлюзов (EDGE_TOKEN): без него центральный сервис не верит ReceivedAtHeader
+	TokenHeader = "X-Edge-Token"
+)
+
+// errUpstreamUnavailable — центральный сервис недоступен; пересылка откладывается до следующего тика
+var errUpstreamUnavailable = errors.New("upstream is unavailable")
+
+// ForwarderStats — состояние пересылки для /health/full шлюза
+type ForwarderStats struct {
+	Sent       int64      `json:"sent"`
+	Rejected   int64      `json:"rejected"`
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
+	// LastSuccessAt — последняя успешная пересылка (связь с центральным сервисом была)
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// Forwarder пересылает запросы из очереди центральному сервису
+type Forwarder struct {
+	queue  Queue
+	cfg    config.EdgeConfig
+	client *http.Client
+	clock  clock.Clock
+	log    zerolog.Logger
+
+	sent     atomic.Int64
+	rejected atomic.Int64
+
+	mu            sync.Mutex
+	lastSyncAt    time.Time
+	lastSuccessAt time.Time
+	lastError     string
+}
+
+// NewForwarder создаёт пересылку очереди queue в EDGE_UPSTREAM_URL
+func NewForwarder(queue Queue, cfg config.EdgeConfig, clk clock.Clock, log zerolog.Logger) *Forwarder {
+	return &Forwarder{
+		queue:  queue,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.UpstreamTimeout},
+		clock:  clk,
+		log:    log.With().Str("component", "edge_forwarder").Logger(),
+	}
+}
+
+// Run пересылает очередь раз в EDGE_SYNC_INTERVAL и удаляет отправленные запросы старше EDGE_RETENTION.
+// Блокируется до отмены ctx.
+func (f *Forwarder) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.SyncInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := f.Sync(ctx); err != nil && ctx.Err() == nil {
+			f.log.Warn().Err(err).Msg("edge sync stopped")
+		}
+		if f.cfg.Retention > 0 {
+			if purged, err := f.queue.Purge(ctx, f.clock.Now().Add(-f.cfg.Retention)); err != nil {
+				f.log.Warn().Err(err).Msg("failed to purge edge spool")
+			} else if purged > 0 {
+				f.log.Info().Int64("purged", purged).Msg("edge spool purged")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync пересылает все запросы, которые пора отправить, пачками по EDGE_SYNC_BATCH_SIZE. Если центральный
+// сервис недоступен, пересылка прерывается до следующего вызова. Возвращает число отправленных запросов.
+func (f *Forwarder) Sync(ctx context.Context) (int, error) {
+	forwarded := 0
+	err := f.sync(ctx, &forwarded)
+
+	f.mu.Lock()
+	f.lastSyncAt = f.clock.Now()
+	if err != nil {
+		f.lastError = err.Error()
+	} else {
+		f.lastError = ""
+	}
+	f.mu.Unlock()
+	return forwarded, err
+}
+
+func (f *Forwarder) sync(ctx context.Context, forwarded *int) error {
+	for ctx.Err() == nil {
+		due, err := f.queue.Due(ctx, f.clock.Now(), f.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range due {
+			// Начатая пересылка доводится до отметки в очереди, иначе запрос уйдёт повторно
+			reqCtx, cancel := lifecycle.Detach(ctx)
+			err := f.forward(reqCtx, &due[i])
+			cancel()
+			if err != nil {
+				return err
+			}
+			*forwarded++
+		}
+		if len(due) < f.cfg.BatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// forward отправляет один запрос и отмечает результат в очереди. Ошибка — только когда центральный
+// сервис недоступен или не удалось записать результат.
+func (f *Forwarder) forward(ctx context.Context, req *Request) error {
+	code, sendErr := f.send(ctx, req)
+	now := f.clock.Now()
+	switch outcome(code, sendErr) {
+	case StatusSent:
+		f.sent.Add(1)
+		f.mu.Lock()
+		f.lastSuccessAt = now
+		f.mu.Unlock()
+		return f.queue.MarkDone(ctx, req.ID, StatusSent, code, nil, now)
+	case StatusRejected:
+		f.rejected.Add(1)
+		msg := fmt.Sprintf("upstream rejected request with status %d", code)
+		f.log.Warn().Int64("request_id", req.ID).Str("path", req.Path).Int("status", code).Msg("camera request rejected by upstream")
+		return f.queue.MarkDone(ctx, req.ID, StatusRejected, code, &msg, now)
+	}
+
+	msg := fmt.Sprintf("upstream responded with status %d", code)
+	if sendErr != nil {
+		msg = sendErr.Error()
+	}
+	next := now.Add(backoff(req.Attempts+1, f.cfg.BackoffBase, f.cfg.BackoffMax))
+	if err := f.queue.MarkRetry(ctx, req.ID, code, msg, next); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errUpstreamUnavailable, msg)
+}
+
+func (f *Forwarder) send(ctx context.Context, req *Request) (int, error) {
+	target := f.cfg.UpstreamURL + req.Path
+	if req.RawQuery != "" {
+		target += "?" + req.RawQuery
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range req.HeaderMap() {
+		httpReq.Header.Set(name, value)
+	}
+	if req.ContentType != "" {
+		httpReq.Header.Set("Content-Type", req.ContentType)
+	}
+	httpReq.Header.Set(ReceivedAtHeader, req.ReceivedAt.UTC().Format(time.RFC3339Nano))
+	if f.cfg.DeviceID != "" {
+		httpReq.Header.Set(DeviceHeader, f.cfg.DeviceID)
+	}
+	if f.cfg.Token != "" {
+		httpReq.Header.Set(TokenHeader, f.cfg.Token)
+	}
+
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// outcome решает судьбу запроса по ответу центрального сервиса: StatusSent, StatusRejected или
+// StatusPending (повторить позже)
+func outcome(code int, err error) string {
+	switch {
+	case err != nil:
+		return StatusPending
+	case code >= 200 && code < 300:
+		return StatusSent
+	// Повтор уже сохранённого события и номер вне белого списка (событие сохранено с отказом) —
+	// центральный сервис запрос принял
+	case code == http.StatusConflict || code == http.StatusForbidden:
+		return StatusSent
+	case code == http.StatusRequestTimeout || code == http.StatusTooManyRequests:
+		return StatusPending
+	case code >= 400 && code < 500:
+		return StatusRejected
+	default:
+		return StatusPending
+	}
+}
+
+// backoff — задержка перед попыткой attempt: base, 2·base, 4·base, … но не больше max
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
+
+// Stats возвращает счётчики пересылки
+func (f *Forwarder) Stats() ForwarderStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := ForwarderStats{Sent: f.sent.Load(), Rejected: f.rejected.Load(), LastError: f.lastError}
+	if !f.lastSyncAt.IsZero() {
+		at := f.lastSyncAt
+		stats.LastSyncAt = &at
+	}
+	if !f.lastSuccessAt.IsZero() {
+		at := f.lastSuccessAt
+		stats.LastSuccessAt = &at
+	}
+	return stats
+}
@@ -0,0 +1,124 @@
+package edge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		code int
+		err  error
+		want string
+	}{
+		{code: http.StatusCreated, want: StatusSent},
+		{code: http.StatusAccepted, want: StatusSent},
+		{code: http.StatusConflict, want: StatusSent},
+		{code: http.StatusForbidden, want: StatusSent},
+		{code: http.StatusBadRequest, want: StatusRejected},
+		{code: http.StatusRequestEntityTooLarge, want: StatusRejected},
+		{code: http.StatusTooManyRequests, want: StatusPending},
+		{code: http.StatusServiceUnavailable, want: StatusPending},
+		{err: errors.New("connection refused"), want: StatusPending},
+	}
+	for _, tt := range tests {
+		if got := outcome(tt.code, tt.err); got != tt.want {
+			t.Errorf("outcome(%d, %v) = %s, want %s", tt.code, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestForwarderSync(t *testing.T) {
+	now := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(now)
+
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   []string
+		status   = map[string]int{"/api/v1/anpr/events": http.StatusCreated, "/api/v1/anpr/hikvision": http.StatusBadRequest}
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+		code := status[r.URL.Path]
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	defer upstream.Close()
+
+	spool, err := OpenSpool(filepath.Join(t.TempDir(), "edge", "spool.db"))
+	if err != nil {
+		t.Fatalf("OpenSpool: %v", err)
+	}
+	defer spool.Close()
+
+	ctx := context.Background()
+	receivedAt := now.Add(-2 * time.Hour)
+	for _, req := range []*Request{
+		{Path: "/api/v1/anpr/events", ContentType: "application/json", Headers: `{"X-Event-Source":"manual"}`, Body: []byte(`{"plate":"123ABC02"}`), ReceivedAt: receivedAt},
+		{Path: "/api/v1/anpr/hikvision", RawQuery: "camera_id=gate-1", ContentType: "application/xml", Body: []byte("<broken"), ReceivedAt: receivedAt},
+	} {
+		if err := spool.Add(ctx, req); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	cfg := config.EdgeConfig{UpstreamURL: upstream.URL, DeviceID: "gate-1", Token: "edge-secret", BatchSize: 1, BackoffBase: time.Minute, BackoffMax: time.Hour, UpstreamTimeout: time.Second}
+	forwarder := NewForwarder(spool, cfg, clk, zerolog.Nop())
+	n, err := forwarder.Sync(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("Sync() = %d, %v; want 2 forwarded", n, err)
+	}
+	if len(received) != 2 || bodies[0] != `{"plate":"123ABC02"}` || received[1].URL.RawQuery != "camera_id=gate-1" {
+		t.Fatalf("unexpected upstream requests: %v %v", received, bodies)
+	}
+	first := received[0]
+	if first.Header.Get(ReceivedAtHeader) != "2025-01-15T20:00:00Z" || first.Header.Get(DeviceHeader) != "gate-1" || first.Header.Get(TokenHeader) != "edge-secret" ||
+		first.Header.Get("X-Event-Source") != "manual" || first.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected forwarded headers: %v", first.Header)
+	}
+	stats, err := spool.Stats(ctx)
+	if err != nil || stats.Pending != 0 || stats.Rejected != 1 {
+		t.Fatalf("Stats() = %+v, %v; want 0 pending, 1 rejected", stats, err)
+	}
+
+	// Центральный сервис недоступен: запрос откладывается, пересылка останавливается
+	mu.Lock()
+	status["/api/v1/anpr/events"] = http.StatusServiceUnavailable
+	mu.Unlock()
+	if err := spool.Add(ctx, &Request{Path: "/api/v1/anpr/events", Body: []byte(`{}`), ReceivedAt: now}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := forwarder.Sync(ctx); !errors.Is(err, errUpstreamUnavailable) {
+		t.Fatalf("Sync() error = %v, want errUpstreamUnavailable", err)
+	}
+	due, err := spool.Due(ctx, now.Add(59*time.Second), 10)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("Due() before backoff = %v, %v; want none", due, err)
+	}
+	due, err = spool.Due(ctx, now.Add(time.Minute), 10)
+	if err != nil || len(due) != 1 || due[0].Attempts != 1 || due[0].UpstreamStatus != http.StatusServiceUnavailable {
+		t.Fatalf("Due() after backoff = %+v, %v", due, err)
+	}
+
+	// Отправленные запросы удаляются по сроку хранения, отвергнутые остаются
+	purged, err := spool.Purge(ctx, now.Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Fatalf("Purge() = %d, %v; want 1", purged, err)
+	}
+}
@@ -0,0 +1,28 @@
+package edge
+
+import (
+	"context"
+	"time"
+)
+
+// Queue — надёжная очередь запросов камер шлюза. Spool хранит её в SQLite (STORAGE_PROFILE=edge),
+// DirQueue — файлами в каталоге (RUN_MODE=relay).
+type Queue interface {
+	// Add ставит запрос в очередь и проставляет ему ID
+	Add(ctx context.Context, req *Request) error
+	// Due возвращает до limit запросов, которые пора отправить, в порядке приёма
+	Due(ctx context.Context, now time.Time, limit int) ([]Request, error)
+	// MarkDone отмечает запрос отправленным (StatusSent) или отвергнутым (StatusRejected)
+	MarkDone(ctx context.Context, id int64, status string, upstreamStatus int, errMsg *string, at time.Time) error
+	// MarkRetry откладывает запрос до nextAttemptAt
+	MarkRetry(ctx context.Context, id int64, upstreamStatus int, errMsg string, nextAttemptAt time.Time) error
+	// Purge удаляет отправленные запросы, отправленные раньше before
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	Stats(ctx context.Context) (SpoolStats, error)
+	Close() error
+}
+
+var (
+	_ Queue = (*Spool)(nil)
+	_ Queue = (*DirQueue)(nil)
+)
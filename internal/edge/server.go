@@ -0,0 +1,114 @@
+package edge
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/logctx"
+)
+
+// ForwardedHeaders — заголовки запроса камеры, которые пересылаются центральному сервису
+var ForwardedHeaders = []string{"User-Agent", "X-Event-Source", middleware.RequestIDHeader}
+
+// ingestPaths — маршруты приёма центрального сервиса, которые принимает шлюз
+var ingestPaths = []string{
+	"/api/v1/anpr/events",
+	"/api/v1/anpr/hikvision",
+	"/api/v2/anpr/events",
+	"/api/v2/anpr/hikvision",
+}
+
+// NewRouter создаёт HTTP-сервер шлюза: маршруты приёма центрального сервиса ставят запрос в очередь и
+// сразу отвечают 202, /health/full показывает очередь и пересылку. profile — режим шлюза для /health/full
+// (edge или relay).
+func NewRouter(queue Queue, forwarder *Forwarder, profile string, maxBodyBytes int64, env string, clk clock.Clock, log zerolog.Logger) *gin.Engine {
+	if env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID(log))
+
+	for _, path := range ingestPaths {
+		router.POST(path, middleware.MaxBodySize(maxBodyBytes), spoolRequest(queue, clk, log))
+	}
+	// Камера Hikvision проверяет доступность адреса GET-запросом
+	router.GET("/api/v1/anpr/hikvision", ok)
+	router.GET("/api/v2/anpr/hikvision", ok)
+
+	router.GET("/health/live", ok)
+	router.GET("/health/full", func(c *gin.Context) {
+		stats, err := queue.Stats(c.Request.Context())
+		if err != nil {
+			logctx.From(c.Request.Context(), &log).Error().Err(err).Msg("edge spool is unavailable")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "spool": "unavailable"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status":   "ok",
+			"profile":  profile,
+			"spool":    stats,
+			"upstream": forwarder.Stats(),
+		})
+	})
+	return router
+}
+
+func ok(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// spoolRequest сохраняет запрос камеры в очередь без разбора: его разберёт центральный сервис
+func spoolRequest(queue Queue, clk clock.Clock, log zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			logctx.From(ctx, &log).Error().Err(err).Msg("failed to read camera request body")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+
+		headers := map[string]string{}
+		for _, name := range ForwardedHeaders {
+			if value := c.GetHeader(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encoded, err := json.Marshal(headers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+
+		req := &Request{
+			Path:        c.Request.URL.Path,
+			RawQuery:    c.Request.URL.RawQuery,
+			ContentType: c.GetHeader("Content-Type"),
+			Headers:     string(encoded),
+			Body:        body,
+			ReceivedAt:  clk.Now(),
+		}
+		if err := queue.Add(ctx, req); err != nil {
+			logctx.From(ctx, &log).Error().Err(err).Msg("failed to spool camera request")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+		logctx.From(ctx, &log).Info().
+			Int64("request_id", req.ID).
+			Str("path", req.Path).
+			Int("payload_size", len(body)).
+			Msg("camera request spooled")
+		c.JSON(http.StatusAccepted, gin.H{"data": gin.H{
+			"status":      "queued",
+			"received_at": req.ReceivedAt.Format(time.RFC3339),
+		}})
+	}
+}
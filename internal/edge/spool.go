@@ -0,0 +1,202 @@
+// Package edge — шлюз полигона без PostgreSQL (STORAGE_PROFILE=edge или RUN_MODE=relay). Запросы камер
+// принимаются как есть и складываются в очередь (Spool в SQLite или DirQueue в каталоге), а Forwarder
+// пересылает их центральному сервису на тот же маршрут, когда появляется связь. Разбор, белый список и
+// сохранение событий остаются на центральном сервисе.
+package edge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	_ "modernc.org/sqlite"
+)
+
+// Состояния запроса в очереди
+const (
+	StatusPending = "pending"
+	// StatusSent — центральный сервис принял запрос (в том числе как повтор или отказ по белому списку)
+	StatusSent = "sent"
+	// StatusRejected — центральный сервис отверг запрос как неразборный; повторять бессмысленно
+	StatusRejected = "rejected"
+)
+
+// Request — запрос камеры в очереди шлюза
+type Request struct {
+	ID int64 `gorm:"primaryKey;autoIncrement"`
+	// Path — маршрут приёма, на который запрос пересылается (/api/v1/anpr/hikvision)
+	Path        string `gorm:"not null"`
+	RawQuery    string
+	ContentType string
+	// Headers — пересылаемые заголовки запроса в JSON
+	Headers       string
+	Body          []byte    `gorm:"not null"`
+	ReceivedAt    time.Time `gorm:"not null"`
+	Status        string    `gorm:"not null;index:idx_edge_requests_due,priority:1"`
+	NextAttemptAt time.Time `gorm:"not null;index:idx_edge_requests_due,priority:2"`
+	Attempts      int       `gorm:"not null"`
+	LastError     *string
+	// UpstreamStatus — код ответа центрального сервиса на последнюю попытку
+	UpstreamStatus int
+	SentAt         *time.Time `gorm:"index"`
+}
+
+func (Request) TableName() string {
+	return "edge_requests"
+}
+
+// HeaderMap возвращает сохранённые заголовки запроса
+func (r *Request) HeaderMap() map[string]string {
+	headers := map[string]string{}
+	if r.Headers != "" {
+		_ = json.Unmarshal([]byte(r.Headers), &headers)
+	}
+	return headers
+}
+
+// SpoolStats — состояние очереди для /health/full шлюза
+type SpoolStats struct {
+	Pending  int64 `json:"pending"`
+	Rejected int64 `json:"rejected"`
+	// OldestPendingAt — когда принят самый старый неотправленный запрос
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+}
+
+// Spool — очередь запросов камер в SQLite
+type Spool struct {
+	db *gorm.DB
+}
+
+// OpenSpool открывает (и при необходимости создаёт) файл очереди
+func OpenSpool(path string) (*Spool, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create edge spool directory: %w", err)
+		}
+	}
+	// Драйвер modernc.org/sqlite — на чистом Go: образ собирается с CGO_ENABLED=0, а go-sqlite3 без cgo не открывается.
+	// WAL: приём камер не ждёт, пока пересылка читает очередь
+	dsn := path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	database, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite", DSN: dsn}), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open edge spool: %w", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	// SQLite допускает одного писателя
+	sqlDB.SetMaxOpenConns(1)
+	if err := database.AutoMigrate(&Request{}); err != nil {
+		return nil, fmt.Errorf("migrate edge spool: %w", err)
+	}
+	return &Spool{db: database}, nil
+}
+
+// Close закрывает файл очереди
+func (s *Spool) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Add ставит запрос в очередь
+func (s *Spool) Add(ctx context.Context, req *Request) error {
+	req.Status = StatusPending
+	if req.NextAttemptAt.IsZero() {
+		req.NextAttemptAt = req.ReceivedAt
+	}
+	if err := s.db.WithContext(ctx).Create(req).Error; err != nil {
+		return fmt.Errorf("failed to spool camera request: %w", err)
+	}
+	return nil
+}
+
+// Due возвращает до limit запросов, которые пора отправить, в порядке приёма
+func (s *Spool) Due(ctx context.Context, now time.Time, limit int) ([]Request, error) {
+	var requests []Request
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&requests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spooled requests: %w", err)
+	}
+	return requests, nil
+}
+
+// MarkDone отмечает запрос отправленным (StatusSent) или отвергнутым (StatusRejected)
+func (s *Spool) MarkDone(ctx context.Context, id int64, status string, upstreamStatus int, errMsg *string, at time.Time) error {
+	err := s.db.WithContext(ctx).Model(&Request{}).Where("id = ?", id).Updates(map[string]any{
+		"status":          status,
+		"upstream_status": upstreamStatus,
+		"last_error":      errMsg,
+		"sent_at":         at,
+		"attempts":        gorm.Expr("attempts + 1"),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark spooled request %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkRetry откладывает запрос до nextAttemptAt
+func (s *Spool) MarkRetry(ctx context.Context, id int64, upstreamStatus int, errMsg string, nextAttemptAt time.Time) error {
+	err := s.db.WithContext(ctx).Model(&Request{}).Where("id = ?", id).Updates(map[string]any{
+		"upstream_status": upstreamStatus,
+		"last_error":      errMsg,
+		"next_attempt_at": nextAttemptAt,
+		"attempts":        gorm.Expr("attempts + 1"),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to reschedule spooled request %d: %w", id, err)
+	}
+	return nil
+}
+
+// Purge удаляет отправленные запросы, отправленные раньше before. Отвергнутые остаются для разбора.
+func (s *Spool) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("status = ? AND sent_at < ?", StatusSent, before).Delete(&Request{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge spooled requests: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Stats возвращает число ожидающих и отвергнутых запросов
+func (s *Spool) Stats(ctx context.Context) (SpoolStats, error) {
+	var row struct {
+		Pending  int64
+		Rejected int64
+	}
+	err := s.db.WithContext(ctx).Model(&Request{}).
+		Select("COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS rejected", StatusPending, StatusRejected).
+		Scan(&row).Error
+	if err != nil {
+		return SpoolStats{}, fmt.Errorf("failed to count spooled requests: %w", err)
+	}
+	stats := SpoolStats{Pending: row.Pending, Rejected: row.Rejected}
+	if row.Pending > 0 {
+		var oldest Request
+		err := s.db.WithContext(ctx).Where("status = ?", StatusPending).Order("id").Limit(1).Find(&oldest).Error
+		if err != nil {
+			return SpoolStats{}, fmt.Errorf("failed to load oldest spooled request: %w", err)
+		}
+		if oldest.ID != 0 {
+			stats.OldestPendingAt = &oldest.ReceivedAt
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,47 @@
+// Package eventbus — внутренняя шина событий сервиса. ANPRService публикует в неё факты
+// (новое событие, изменение камеры и т.п.), а каналы доставки (WebSocket, вебхуки, аналитика)
+// подписываются на топики единообразно, независимо от выбранного бэкенда.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+)
+
+// Топики шины
+const (
+	TopicEventCreated = "anpr.event.created"
+	TopicTripChanged  = "anpr.trip.changed"
+	TopicDBQuota      = "anpr.db.quota"
+)
+
+// Handler обрабатывает сообщение топика; payload — JSON, переданный в Publish
+type Handler func(ctx context.Context, topic string, payload []byte) error
+
+// Bus — шина событий
+type Bus interface {
+	// Publish сериализует payload в JSON и отправляет его подписчикам топика
+	Publish(ctx context.Context, topic string, payload interface{}) error
+	// Subscribe регистрирует обработчик топика; возвращённая функция отменяет подписку
+	Subscribe(topic string, handler Handler) (func(), error)
+	Close() error
+}
+
+// New создаёт шину выбранного бэкенда
+func New(cfg config.EventBusConfig, log zerolog.Logger) (Bus, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", config.EventBusInProcess:
+		return NewInProcess(log), nil
+	case config.EventBusNATS:
+		return NewNATS(cfg.NATSURL, log)
+	case config.EventBusKafka:
+		return NewKafka(cfg.KafkaBrokers, cfg.KafkaGroupID, log)
+	default:
+		return nil, fmt.Errorf("unknown event bus backend %q", cfg.Backend)
+	}
+}
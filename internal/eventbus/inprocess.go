@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// InProcess — шина в памяти процесса. Обработчики вызываются асинхронно,
+// чтобы медленный подписчик не задерживал приём событий от камер.
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers map[string]map[int]Handler
+	nextID   int
+	wg       sync.WaitGroup
+	log      zerolog.Logger
+}
+
+func NewInProcess(log zerolog.Logger) *InProcess {
+	return &InProcess{
+		handlers: make(map[string]map[int]Handler),
+		log:      log,
+	}
+}
+
+func (b *InProcess) Publish(ctx context.Context, topic string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal event bus payload: %w", err)
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[topic]))
+	for _, handler := range b.handlers[topic] {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	// Обработчики не должны зависеть от отмены контекста HTTP-запроса, который опубликовал событие
+	handlerCtx := context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go func(handler Handler) {
+			defer b.wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					b.log.Error().Interface("panic", r).Str("topic", topic).Msg("event bus handler panicked")
+				}
+			}()
+			if err := handler(handlerCtx, topic, data); err != nil {
+				b.log.Warn().Err(err).Str("topic", topic).Msg("event bus handler failed")
+			}
+		}(handler)
+	}
+	return nil
+}
+
+func (b *InProcess) Subscribe(topic string, handler Handler) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.handlers[topic] == nil {
+		b.handlers[topic] = make(map[int]Handler)
+	}
+	id := b.nextID
+	b.nextID++
+	b.handlers[topic][id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers[topic], id)
+	}, nil
+}
+
+// Close дожидается завершения уже запущенных обработчиков
+func (b *InProcess) Close() error {
+	b.wg.Wait()
+	return nil
+}
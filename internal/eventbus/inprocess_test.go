@@ -0,0 +1,50 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestInProcessPublishSubscribe(t *testing.T) {
+	bus := NewInProcess(zerolog.Nop())
+
+	received := make(chan map[string]string, 1)
+	unsubscribe, err := bus.Subscribe(TopicEventCreated, func(_ context.Context, topic string, payload []byte) error {
+		var msg map[string]string
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if err := bus.Publish(context.Background(), TopicEventCreated, map[string]string{"plate": "123ABC02"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg["plate"] != "123ABC02" {
+			t.Fatalf("unexpected payload %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+
+	unsubscribe()
+	if err := bus.Publish(context.Background(), TopicEventCreated, map[string]string{"plate": "X"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(received) != 0 {
+		t.Fatal("handler called after unsubscribe")
+	}
+}
@@ -0,0 +1,178 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBatchTimeout — сколько writer ждёт, набирая пачку. По умолчанию kafka-go ждёт до секунды; при потоке
+// событий с камер пачки всё равно собираются из одновременных вызовов, а события доходят до подписчиков быстрее.
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+const (
+	// kafkaPendingLimit — сколько сообщений ждёт отправки; при переполнении Publish отвергает новые
+	kafkaPendingLimit = 1024
+	// kafkaFlushTimeout — сколько Close ждёт отправки накопленных сообщений
+	kafkaFlushTimeout = 5 * time.Second
+)
+
+// ErrKafkaQueueFull — очередь отправки переполнена: брокер недоступен дольше, чем её хватает
+var ErrKafkaQueueFull = errors.New("kafka publish queue is full")
+
+// Kafka — шина поверх Kafka (топик шины = топик Kafka)
+type Kafka struct {
+	brokers []string
+	groupID string
+	writer  *kafka.Writer
+	// pending — сообщения для фоновой отправки: даже в режиме Async kafka-go запрашивает у брокера
+	// разделы топика в WriteMessages, поэтому Publish не вызывает writer сам
+	pending chan kafka.Message
+	stop    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	log     zerolog.Logger
+}
+
+func NewKafka(brokers []string, groupID string, log zerolog.Logger) (*Kafka, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers are required")
+	}
+	if groupID == "" {
+		groupID = "anpr-service"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Kafka{
+		ctx:     ctx,
+		cancel:  cancel,
+		brokers: brokers,
+		groupID: groupID,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			BatchTimeout:           kafkaBatchTimeout,
+			AllowAutoTopicCreation: true,
+			// Сообщения отправляются в фоне, ошибки доставки только логируются: как и NATS, шина
+			// не гарантирует доставку
+			Async:      true,
+			Completion: kafkaCompletion(log),
+		},
+		pending: make(chan kafka.Message, kafkaPendingLimit),
+		stop:    make(chan struct{}),
+		log:     log,
+	}
+	b.wg.Add(1)
+	go b.runWriter()
+	return b, nil
+}
+
+// kafkaCompletion логирует пачки, которые writer не смог доставить
+func kafkaCompletion(log zerolog.Logger) func([]kafka.Message, error) {
+	return func(messages []kafka.Message, err error) {
+		if err == nil || len(messages) == 0 {
+			return
+		}
+		log.Error().Err(err).Str("topic", messages[0].Topic).Int("messages", len(messages)).Msg("kafka publish failed")
+	}
+}
+
+// Publish ставит сообщение в очередь фоновой отправки и не ждёт брокер
+func (b *Kafka) Publish(ctx context.Context, topic string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal event bus payload: %w", err)
+	}
+	select {
+	case b.pending <- kafka.Message{Topic: topic, Value: data}:
+		return nil
+	default:
+		return fmt.Errorf("kafka publish: %w", ErrKafkaQueueFull)
+	}
+}
+
+// runWriter передаёт сообщения из очереди writer до Close, затем отправляет оставшиеся
+func (b *Kafka) runWriter() {
+	defer b.wg.Done()
+	for {
+		select {
+		case msg := <-b.pending:
+			b.write(b.ctx, msg)
+		case <-b.stop:
+			ctx, cancel := context.WithTimeout(context.Background(), kafkaFlushTimeout)
+			defer cancel()
+			for {
+				select {
+				case msg := <-b.pending:
+					b.write(ctx, msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write передаёт сообщение writer вместе со всеми, что уже накопились в очереди
+func (b *Kafka) write(ctx context.Context, first kafka.Message) {
+	msgs := []kafka.Message{first}
+	for len(msgs) < kafkaPendingLimit {
+		select {
+		case msg := <-b.pending:
+			msgs = append(msgs, msg)
+			continue
+		default:
+		}
+		break
+	}
+	if err := b.writer.WriteMessages(ctx, msgs...); err != nil {
+		b.log.Error().Err(err).Int("messages", len(msgs)).Msg("kafka publish failed")
+	}
+}
+
+func (b *Kafka) Subscribe(topic string, handler Handler) (func(), error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: b.brokers,
+		GroupID: b.groupID,
+		Topic:   topic,
+	})
+	ctx, cancel := context.WithCancel(b.ctx)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() {
+			if err := reader.Close(); err != nil {
+				b.log.Warn().Err(err).Str("topic", topic).Msg("failed to close kafka reader")
+			}
+		}()
+		for {
+			msg, err := reader.ReadMessage(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					b.log.Error().Err(err).Str("topic", topic).Msg("kafka read failed, stopping subscription")
+				}
+				return
+			}
+			if err := handler(ctx, msg.Topic, msg.Value); err != nil {
+				b.log.Warn().Err(err).Str("topic", msg.Topic).Msg("event bus handler failed")
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// Close останавливает все подписки, отправляет накопленные сообщения и закрывает writer
+func (b *Kafka) Close() error {
+	close(b.stop)
+	b.cancel()
+	b.wg.Wait()
+	return b.writer.Close()
+}
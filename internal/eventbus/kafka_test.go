@@ -0,0 +1,40 @@
+package eventbus
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Publish на пути приёма события не должен ждать брокер, даже если тот недоступен
+func TestKafkaPublishDoesNotBlockWhenBrokerIsDown(t *testing.T) {
+	// Свободный порт, на котором никто не слушает
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	bus, err := NewKafka([]string{addr}, "", zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.writer.MaxAttempts = 1
+	defer bus.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := bus.Publish(ctx, TopicEventCreated, map[string]int{"n": i}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Publish() took %s with the broker down, want no wait", elapsed)
+	}
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog"
+)
+
+// NATS — шина поверх NATS core (топик = subject)
+type NATS struct {
+	conn *nats.Conn
+	log  zerolog.Logger
+}
+
+func NewNATS(url string, log zerolog.Logger) (*NATS, error) {
+	if url == "" {
+		url = nats.DefaultURL
+	}
+	conn, err := nats.Connect(url, nats.Name("anpr-service"))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	return &NATS{conn: conn, log: log}, nil
+}
+
+func (b *NATS) Publish(_ context.Context, topic string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal event bus payload: %w", err)
+	}
+	if err := b.conn.Publish(topic, data); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+func (b *NATS) Subscribe(topic string, handler Handler) (func(), error) {
+	sub, err := b.conn.Subscribe(topic, func(msg *nats.Msg) {
+		if err := handler(context.Background(), msg.Subject, msg.Data); err != nil {
+			b.log.Warn().Err(err).Str("topic", msg.Subject).Msg("event bus handler failed")
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats subscribe: %w", err)
+	}
+	return func() {
+		if err := sub.Unsubscribe(); err != nil {
+			b.log.Warn().Err(err).Str("topic", topic).Msg("failed to unsubscribe from nats")
+		}
+	}, nil
+}
+
+func (b *NATS) Close() error {
+	return b.conn.Drain()
+}
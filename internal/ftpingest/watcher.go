@@ -0,0 +1,261 @@
+// Package ftpingest принимает выгрузку камер по FTP: камера в режиме FTP-загрузки кладёт XML уведомления
+// и снимки на FTP-сервер, а Watcher забирает их из общего с сервером каталога и передаёт в приём событий.
+package ftpingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/lifecycle"
+)
+
+// FailedDir — подкаталог для отвергнутых выгрузок и снимков без уведомления
+const FailedDir = "failed"
+
+// maxFileSize — наибольший размер файла выгрузки; файлы больше уходят в FailedDir
+const maxFileSize = 10 << 20
+
+// ErrInvalidUpload — выгрузка не может быть принята и при повторе; файлы переносятся в FailedDir.
+// Остальные ошибки Sink считаются временными: файлы остаются в каталоге до следующего просмотра.
+var ErrInvalidUpload = errors.New("invalid ftp upload")
+
+// Photo — снимок из выгрузки
+type Photo struct {
+	Name string
+	Data []byte
+}
+
+// Upload — уведомление камеры и снимки с тем же именем (до расширения)
+type Upload struct {
+	// CameraID — первый подкаталог относительно корня (камера настраивается выгружать в свой каталог);
+	// пусто для файлов в корне
+	CameraID string
+	// Name — путь XML уведомления относительно корня
+	Name         string
+	Notification []byte
+	Photos       []Photo
+}
+
+// Sink передаёт выгрузку в приём событий
+type Sink func(ctx context.Context, upload Upload) error
+
+// Options — настройки просмотра каталога
+type Options struct {
+	Dir          string
+	PollInterval time.Duration
+	Settle       time.Duration
+	OrphanAge    time.Duration
+}
+
+// Watcher периодически просматривает каталог выгрузки. Уведомление принимается, когда оно и его снимки
+// не менялись Settle; после приёма файлы удаляются.
+type Watcher struct {
+	opts Options
+	sink Sink
+	log  zerolog.Logger
+	now  func() time.Time
+}
+
+// NewWatcher создаёт Watcher для каталога opts.Dir
+func NewWatcher(opts Options, sink Sink, log zerolog.Logger) *Watcher {
+	return &Watcher{opts: opts, sink: sink, log: log.With().Str("component", "ftp_ingest").Logger(), now: time.Now}
+}
+
+// Run просматривает каталог раз в PollInterval. Блокируется до отмены ctx.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.opts.Dir == "" || w.opts.PollInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Scan(ctx); err != nil && ctx.Err() == nil {
+			w.log.Warn().Err(err).Str("dir", w.opts.Dir).Msg("failed to scan ftp upload directory")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type uploadFile struct {
+	rel     string
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Scan принимает все готовые выгрузки каталога
+func (w *Watcher) Scan(ctx context.Context) error {
+	notifications, images, err := w.list()
+	if err != nil {
+		return err
+	}
+	now := w.now()
+	settled := func(f uploadFile) bool { return now.Sub(f.modTime) >= w.opts.Settle }
+
+	claimed := map[string]bool{}
+	for _, xmlFile := range notifications {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		stem := strings.ToLower(strings.TrimSuffix(xmlFile.rel, filepath.Ext(xmlFile.rel)))
+		var photos []uploadFile
+		ready := settled(xmlFile)
+		for _, image := range images {
+			if belongsTo(image.rel, stem) {
+				photos = append(photos, image)
+				claimed[image.rel] = true
+				ready = ready && settled(image)
+			}
+		}
+		if ready {
+			w.accept(ctx, xmlFile, photos)
+		}
+	}
+
+	// Снимки, для которых уведомление так и не пришло, не должны копиться в каталоге
+	for _, image := range images {
+		if !claimed[image.rel] && now.Sub(image.modTime) >= w.opts.OrphanAge {
+			w.log.Warn().Str("file", image.rel).Msg("ftp upload photo has no notification")
+			w.fail(image)
+		}
+	}
+	return nil
+}
+
+// belongsTo сообщает, что снимок назван по уведомлению: <stem>.jpg, <stem>_plate.jpg, <stem>-1.jpg
+func belongsTo(imageRel, stem string) bool {
+	name := strings.ToLower(imageRel)
+	if len(name) <= len(stem) || !strings.HasPrefix(name, stem) {
+		return false
+	}
+	switch name[len(stem)] {
+	case '.', '_', '-':
+		return true
+	}
+	return false
+}
+
+// accept передаёт выгрузку в sink. Начатый приём не прерывается остановкой: иначе событие могло бы
+// сохраниться, а файлы остаться в каталоге и быть приняты повторно.
+func (w *Watcher) accept(ctx context.Context, xmlFile uploadFile, photos []uploadFile) {
+	files := append([]uploadFile{xmlFile}, photos...)
+	upload, err := w.read(xmlFile, photos)
+	if err == nil {
+		sinkCtx, cancel := lifecycle.Detach(ctx)
+		err = w.sink(sinkCtx, *upload)
+		cancel()
+	}
+	switch {
+	case err == nil:
+		for _, f := range files {
+			if err := os.Remove(f.path); err != nil {
+				w.log.Warn().Err(err).Str("file", f.rel).Msg("failed to remove accepted ftp upload")
+			}
+		}
+		w.log.Info().Str("file", xmlFile.rel).Int("photos", len(photos)).Msg("ftp upload accepted")
+	case errors.Is(err, ErrInvalidUpload):
+		w.log.Warn().Err(err).Str("file", xmlFile.rel).Msg("ftp upload rejected")
+		for _, f := range files {
+			w.fail(f)
+		}
+	default:
+		w.log.Warn().Err(err).Str("file", xmlFile.rel).Msg("failed to ingest ftp upload, will retry")
+	}
+}
+
+func (w *Watcher) read(xmlFile uploadFile, photos []uploadFile) (*Upload, error) {
+	upload := &Upload{Name: xmlFile.rel}
+	if dir, _, ok := strings.Cut(filepath.ToSlash(xmlFile.rel), "/"); ok {
+		upload.CameraID = dir
+	}
+	var err error
+	if upload.Notification, err = readFile(xmlFile); err != nil {
+		return nil, err
+	}
+	for _, photo := range photos {
+		data, err := readFile(photo)
+		if err != nil {
+			return nil, err
+		}
+		upload.Photos = append(upload.Photos, Photo{Name: filepath.Base(photo.rel), Data: data})
+	}
+	return upload, nil
+}
+
+func readFile(f uploadFile) ([]byte, error) {
+	if f.size > maxFileSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidUpload, f.rel, maxFileSize)
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, maxFileSize))
+}
+
+// fail переносит файл в FailedDir с сохранением относительного пути
+func (w *Watcher) fail(f uploadFile) {
+	target := filepath.Join(w.opts.Dir, FailedDir, f.rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		w.log.Warn().Err(err).Str("file", f.rel).Msg("failed to create ftp failed directory")
+		return
+	}
+	if err := os.Rename(f.path, target); err != nil {
+		w.log.Warn().Err(err).Str("file", f.rel).Msg("failed to move ftp upload to failed directory")
+	}
+}
+
+// list возвращает XML уведомления и снимки каталога по имени. Скрытые файлы (временные файлы
+// FTP-сервера) и FailedDir пропускаются.
+func (w *Watcher) list() (notifications, images []uploadFile, err error) {
+	err = filepath.WalkDir(w.opts.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(w.opts.Dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel != "." && (rel == FailedDir || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Файл мог быть удалён между чтением каталога и stat
+			return nil
+		}
+		f := uploadFile{rel: rel, path: path, size: info.Size(), modTime: info.ModTime()}
+		switch strings.ToLower(filepath.Ext(d.Name())) {
+		case ".xml", ".json":
+			notifications = append(notifications, f)
+		case ".jpg", ".jpeg", ".png":
+			images = append(images, f)
+		}
+		return nil
+	})
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].rel < notifications[j].rel })
+	sort.Slice(images, func(i, j int) bool { return images[i].rel < images[j].rel })
+	return notifications, images, err
+}
@@ -0,0 +1,107 @@
+package ftpingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestWatcherScan(t *testing.T) {
+	now := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	old := now.Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		files     map[string]time.Time
+		sinkErr   error
+		wantCalls int
+		wantPhoto int
+		wantLeft  []string
+		wantGone  []string
+	}{
+		{
+			name:      "accepted upload is removed",
+			files:     map[string]time.Time{"cam-1/a.xml": old, "cam-1/a_plate.jpg": old, "cam-1/ab.jpg": old},
+			wantCalls: 1,
+			wantPhoto: 1,
+			wantLeft:  []string{"cam-1/ab.jpg"},
+			wantGone:  []string{"cam-1/a.xml", "cam-1/a_plate.jpg"},
+		},
+		{
+			name:     "photo still uploading",
+			files:    map[string]time.Time{"cam-1/a.xml": old, "cam-1/a.jpg": now},
+			wantLeft: []string{"cam-1/a.xml", "cam-1/a.jpg"},
+		},
+		{
+			name:      "temporary failure keeps files",
+			files:     map[string]time.Time{"a.xml": old},
+			sinkErr:   errors.New("db unavailable"),
+			wantCalls: 1,
+			wantLeft:  []string{"a.xml"},
+		},
+		{
+			name:      "invalid upload goes to failed",
+			files:     map[string]time.Time{"a.xml": old, "a.jpg": old},
+			sinkErr:   ErrInvalidUpload,
+			wantCalls: 1,
+			wantLeft:  []string{"failed/a.xml", "failed/a.jpg"},
+			wantGone:  []string{"a.xml", "a.jpg"},
+		},
+		{
+			name:     "orphan photo goes to failed",
+			files:    map[string]time.Time{"cam-1/b.jpg": now.Add(-2 * time.Hour)},
+			wantLeft: []string{"failed/cam-1/b.jpg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, modTime := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("<EventNotificationAlert/>"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var uploads []Upload
+			sink := func(_ context.Context, upload Upload) error {
+				uploads = append(uploads, upload)
+				return tt.sinkErr
+			}
+			w := NewWatcher(Options{Dir: dir, PollInterval: time.Second, Settle: 5 * time.Second, OrphanAge: time.Hour}, sink, zerolog.Nop())
+			w.now = func() time.Time { return now }
+
+			if err := w.Scan(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if len(uploads) != tt.wantCalls {
+				t.Fatalf("sink called %d times, want %d", len(uploads), tt.wantCalls)
+			}
+			if tt.wantPhoto > 0 && (len(uploads[0].Photos) != tt.wantPhoto || uploads[0].CameraID != "cam-1") {
+				t.Errorf("upload = %+v, want %d photos from cam-1", uploads[0], tt.wantPhoto)
+			}
+			for _, name := range tt.wantLeft {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			}
+			for _, name := range tt.wantGone {
+				if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+					t.Errorf("%s still exists", name)
+				}
+			}
+		})
+	}
+}
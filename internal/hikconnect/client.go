@@ -0,0 +1,226 @@
+// Package hikconnect принимает события камер через облако Hik-Connect: камера за NAT оператора
+// регистрируется в облаке сама (исходящим соединением), а Subscriber забирает её тревоги из очереди сообщений
+// Hik-Connect OpenAPI и передаёт их в приём событий. Проброс портов к камере и к сервису не нужен.
+package hikconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Методы Hik-Connect OpenAPI
+const (
+	tokenPath     = "/api/hccgw/platform/v1/token/get"
+	subscribePath = "/api/hccgw/combine/v1/mq/subscribe"
+	messagesPath  = "/api/hccgw/combine/v1/mq/messages"
+	completePath  = "/api/hccgw/combine/v1/mq/messages/complete"
+)
+
+// maxResponseSize — наибольший ответ API; maxPictureSize — наибольший снимок события
+const (
+	maxResponseSize = 16 << 20
+	maxPictureSize  = 10 << 20
+)
+
+// tokenRefreshMargin — за сколько до истечения токен запрашивается заново
+const tokenRefreshMargin = time.Minute
+
+// APIError — ответ Hik-Connect с кодом ошибки
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("hik-connect error %s: %s", e.Code, e.Message)
+}
+
+// Picture — снимок события; URL подписан облаком и скачивается без токена
+type Picture struct {
+	URL string `json:"url"`
+}
+
+// RawMessage — сообщение очереди: тревога устройства в формате ISAPI JSON (EventNotificationAlert) и снимки
+type RawMessage struct {
+	GUID         string          `json:"guid"`
+	DeviceSerial string          `json:"deviceSerial"`
+	Data         json.RawMessage `json:"data"`
+	Pictures     []Picture       `json:"pictures"`
+}
+
+// Batch — пачка сообщений. Пока пачка не подтверждена Complete, облако выдаёт её сообщения повторно.
+type Batch struct {
+	ID        string       `json:"batchId"`
+	Remaining int          `json:"remainingNumber"`
+	Messages  []RawMessage `json:"events"`
+}
+
+// Client — клиент Hik-Connect OpenAPI с токеном по ключам приложения (appKey/secretKey)
+type Client struct {
+	baseURL   string
+	appKey    string
+	secretKey string
+	http      *http.Client
+	now       func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	// areaURL — адрес региона учётной записи из ответа на запрос токена; запросы идут на него
+	areaURL string
+}
+
+// NewClient создаёт клиент. domain — домен Hik-Connect (HIK_CONNECT_DOMAIN), без схемы — https.
+func NewClient(domain, appKey, secretKey string, timeout time.Duration) *Client {
+	baseURL := strings.TrimRight(strings.TrimSpace(domain), "/")
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return &Client{
+		baseURL:   baseURL,
+		appKey:    appKey,
+		secretKey: secretKey,
+		http:      &http.Client{Timeout: timeout},
+		now:       time.Now,
+	}
+}
+
+// Subscribe подписывает приложение на тревоги устройств учётной записи. Повторная подписка безопасна.
+func (c *Client) Subscribe(ctx context.Context) error {
+	return c.call(ctx, subscribePath, map[string]int{"subscribeType": 1}, nil)
+}
+
+// Messages забирает до limit сообщений очереди
+func (c *Client) Messages(ctx context.Context, limit int) (*Batch, error) {
+	var batch Batch
+	if err := c.call(ctx, messagesPath, map[string]int{"maxNumberPerTime": limit}, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Complete подтверждает пачку: её сообщения больше не выдаются
+func (c *Client) Complete(ctx context.Context, batchID string) error {
+	return c.call(ctx, completePath, map[string]string{"batchId": batchID}, nil)
+}
+
+// Picture скачивает снимок события
+func (c *Client) Picture(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("hik-connect picture request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hik-connect picture: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPictureSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("hik-connect picture: %w", err)
+	}
+	if len(data) > maxPictureSize {
+		return nil, fmt.Errorf("hik-connect picture exceeds %d bytes", maxPictureSize)
+	}
+	return data, nil
+}
+
+// call выполняет метод API с токеном. При ошибке API токен сбрасывается: истёкший или отозванный
+// токен запрашивается заново при следующем вызове.
+func (c *Client) call(ctx context.Context, path string, body, result any) error {
+	token, baseURL, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.post(ctx, baseURL+path, token, body, result)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *Client) accessToken(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.token != "" && now.Before(c.expiresAt.Add(-tokenRefreshMargin)) {
+		return c.token, c.areaURL, nil
+	}
+
+	var data struct {
+		AccessToken string `json:"accessToken"`
+		// ExpireTime — срок действия токена, Unix-время в секундах
+		ExpireTime int64  `json:"expireTime"`
+		AreaDomain string `json:"areaDomain"`
+	}
+	credentials := map[string]string{"appKey": c.appKey, "secretKey": c.secretKey}
+	if err := c.post(ctx, c.baseURL+tokenPath, "", credentials, &data); err != nil {
+		return "", "", fmt.Errorf("failed to get hik-connect token: %w", err)
+	}
+	if data.AccessToken == "" {
+		return "", "", errors.New("failed to get hik-connect token: empty token")
+	}
+	c.token = data.AccessToken
+	c.expiresAt = time.Unix(data.ExpireTime, 0)
+	c.areaURL = c.baseURL
+	if area := strings.TrimRight(data.AreaDomain, "/"); area != "" {
+		c.areaURL = area
+	}
+	return c.token, c.areaURL, nil
+}
+
+func (c *Client) post(ctx context.Context, url, token string, body, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Token", token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("hik-connect request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		ErrorCode string          `json:"errorCode"`
+		Message   string          `json:"message"`
+		Data      json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&envelope); err != nil {
+		return fmt.Errorf("hik-connect: unexpected response with status %d", resp.StatusCode)
+	}
+	if envelope.ErrorCode != "0" {
+		return &APIError{Code: envelope.ErrorCode, Message: envelope.Message}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hik-connect: status %d", resp.StatusCode)
+	}
+	if result == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, result); err != nil {
+		return fmt.Errorf("hik-connect: invalid %s response: %w", url, err)
+	}
+	return nil
+}
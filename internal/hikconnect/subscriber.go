@@ -0,0 +1,197 @@
+package hikconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/lifecycle"
+)
+
+// ErrInvalidMessage — сообщение не может быть принято и при повторе; оно пропускается.
+// Остальные ошибки Sink считаются временными: пачка не подтверждается, и облако выдаёт её повторно,
+// пока сообщение не исчерпает Options.MaxAttempts.
+var ErrInvalidMessage = errors.New("invalid hik-connect message")
+
+// Photo — снимок события
+type Photo struct {
+	Name string
+	Data []byte
+}
+
+// Message — тревога устройства с загруженными снимками
+type Message struct {
+	ID string
+	// DeviceSerial — серийный номер устройства в Hik-Connect; камера тревоги без channelID/deviceID
+	DeviceSerial string
+	// Notification — уведомление в формате ISAPI JSON
+	Notification []byte
+	Photos       []Photo
+}
+
+// Sink передаёт сообщение в приём событий
+type Sink func(ctx context.Context, msg Message) error
+
+// DeadLetterSink сохраняет сообщение, которое sink так и не принял за Options.MaxAttempts попыток
+type DeadLetterSink func(ctx context.Context, msg Message, cause error) error
+
+// Options — настройки подписки
+type Options struct {
+	Domain       string
+	AppKey       string
+	SecretKey    string
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	// MaxAttempts — сколько раз подряд sink может не принять сообщение, прежде чем оно уйдёт в dead letters
+	MaxAttempts int
+}
+
+// Subscriber забирает тревоги из очереди сообщений Hik-Connect
+type Subscriber struct {
+	opts       Options
+	client     *Client
+	sink       Sink
+	deadLetter DeadLetterSink
+	// failures — неудачные попытки приёма по GUID сообщения; пишет только Poll
+	failures map[string]int
+	log      zerolog.Logger
+}
+
+// NewSubscriber создаёт Subscriber для приложения opts.AppKey
+func NewSubscriber(opts Options, sink Sink, deadLetter DeadLetterSink, log zerolog.Logger) *Subscriber {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	return &Subscriber{
+		opts:       opts,
+		client:     NewClient(opts.Domain, opts.AppKey, opts.SecretKey, opts.Timeout),
+		sink:       sink,
+		deadLetter: deadLetter,
+		failures:   map[string]int{},
+		log:        log.With().Str("component", "hik_connect").Logger(),
+	}
+}
+
+// Run подписывается на тревоги и разбирает очередь раз в PollInterval, пока в ней что-то есть.
+// Блокируется до отмены ctx.
+func (s *Subscriber) Run(ctx context.Context) {
+	if s.opts.AppKey == "" || s.opts.PollInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	subscribed := false
+	for {
+		if !subscribed {
+			if err := s.client.Subscribe(ctx); err != nil {
+				if ctx.Err() == nil {
+					s.log.Warn().Err(err).Msg("failed to subscribe to hik-connect messages")
+				}
+			} else {
+				subscribed = true
+				s.log.Info().Msg("subscribed to hik-connect messages")
+			}
+		}
+		for subscribed {
+			remaining, err := s.Poll(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.log.Warn().Err(err).Msg("failed to poll hik-connect messages")
+				}
+				break
+			}
+			if remaining == 0 {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll принимает одну пачку сообщений и возвращает, сколько ещё осталось в очереди. Пачка подтверждается,
+// только если все её сообщения приняты, отвергнуты как ErrInvalidMessage или ушли в dead letters.
+// Пустая пачка возвращает 0 при любом Remaining облака: Run дождётся следующего тика, а не опрашивает
+// очередь в цикле.
+func (s *Subscriber) Poll(ctx context.Context) (int, error) {
+	batch, err := s.client.Messages(ctx, s.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(batch.Messages) == 0 {
+		return 0, nil
+	}
+	for _, raw := range batch.Messages {
+		if err := s.accept(ctx, raw); err != nil {
+			return 0, fmt.Errorf("message %s: %w", raw.GUID, err)
+		}
+	}
+	if err := s.client.Complete(ctx, batch.ID); err != nil {
+		return 0, fmt.Errorf("failed to complete hik-connect batch: %w", err)
+	}
+	return batch.Remaining, nil
+}
+
+// accept передаёт сообщение в sink. Начатый приём не прерывается остановкой: пачка уже выдана, и
+// прерванное сообщение пришло бы повторно вместе с сохранёнными.
+func (s *Subscriber) accept(ctx context.Context, raw RawMessage) error {
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		s.log.Warn().Str("guid", raw.GUID).Str("device_serial", raw.DeviceSerial).Msg("hik-connect message has no alarm data, skipping")
+		return nil
+	}
+	msg := Message{ID: raw.GUID, DeviceSerial: raw.DeviceSerial, Notification: raw.Data}
+	for i, picture := range raw.Pictures {
+		data, err := s.client.Picture(ctx, picture.URL)
+		if err != nil {
+			// Ссылка на снимок могла истечь — событие важнее снимка
+			s.log.Warn().Err(err).Str("guid", raw.GUID).Int("picture", i).Msg("failed to download hik-connect picture")
+			continue
+		}
+		msg.Photos = append(msg.Photos, Photo{Name: fmt.Sprintf("%s-photo-%d.jpg", raw.GUID, i), Data: data})
+	}
+
+	sinkCtx, cancel := lifecycle.Detach(ctx)
+	defer cancel()
+	err := s.sink(sinkCtx, msg)
+	if errors.Is(err, ErrInvalidMessage) {
+		delete(s.failures, raw.GUID)
+		s.log.Warn().Err(err).Str("guid", raw.GUID).Str("device_serial", raw.DeviceSerial).Msg("hik-connect message rejected")
+		return nil
+	}
+	if err != nil {
+		return s.fail(sinkCtx, msg, err)
+	}
+	delete(s.failures, raw.GUID)
+	s.log.Info().Str("guid", raw.GUID).Str("device_serial", raw.DeviceSerial).Int("photos", len(msg.Photos)).Msg("hik-connect message accepted")
+	return nil
+}
+
+// fail учитывает неудачную попытку приёма. После MaxAttempts попыток сообщение уходит в dead letters, чтобы
+// одна постоянная ошибка (БД, хранилище фото) не держала всю очередь приложения.
+func (s *Subscriber) fail(ctx context.Context, msg Message, cause error) error {
+	s.failures[msg.ID]++
+	attempts := s.failures[msg.ID]
+	if attempts < s.opts.MaxAttempts || s.deadLetter == nil {
+		return cause
+	}
+	if err := s.deadLetter(ctx, msg, cause); err != nil {
+		return fmt.Errorf("%w (dead letter: %v)", cause, err)
+	}
+	delete(s.failures, msg.ID)
+	s.log.Error().Err(cause).Str("guid", msg.ID).Str("device_serial", msg.DeviceSerial).Int("attempts", attempts).Msg("hik-connect message moved to dead letters")
+	return nil
+}
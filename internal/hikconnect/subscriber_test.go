@@ -0,0 +1,201 @@
+package hikconnect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// fakeCloud — очередь сообщений Hik-Connect с одной пачкой
+type fakeCloud struct {
+	t         *testing.T
+	srv       *httptest.Server
+	batch     Batch
+	tokens    int
+	completed []string
+}
+
+func newFakeCloud(t *testing.T) *fakeCloud {
+	f := &fakeCloud{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["appKey"] != "app" || body["secretKey"] != "secret" {
+			f.reply(w, "OPEN000001", nil)
+			return
+		}
+		f.tokens++
+		f.reply(w, "0", map[string]any{"accessToken": "token-1", "expireTime": time.Now().Add(time.Hour).Unix()})
+	})
+	mux.HandleFunc(messagesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Token") != "token-1" {
+			f.reply(w, "OPEN000007", nil)
+			return
+		}
+		f.reply(w, "0", f.batch)
+	})
+	mux.HandleFunc(completePath, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.completed = append(f.completed, body["batchId"])
+		f.reply(w, "0", nil)
+	})
+	mux.HandleFunc("/pictures/1.jpg", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("jpeg"))
+	})
+	mux.HandleFunc("/pictures/expired.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeCloud) reply(w http.ResponseWriter, code string, data any) {
+	if err := json.NewEncoder(w).Encode(map[string]any{"errorCode": code, "message": "", "data": data}); err != nil {
+		f.t.Error(err)
+	}
+}
+
+func TestSubscriberPoll(t *testing.T) {
+	alarm := json.RawMessage(`{"eventType":"ANPR","ANPR":{"licensePlate":"123ABC02"}}`)
+
+	tests := []struct {
+		name          string
+		sinkErr       error
+		wantErr       bool
+		wantCompleted bool
+	}{
+		{name: "accepted batch is completed", wantCompleted: true},
+		{name: "invalid message is skipped", sinkErr: ErrInvalidMessage, wantCompleted: true},
+		{name: "temporary failure leaves batch in queue", sinkErr: errors.New("db unavailable"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cloud := newFakeCloud(t)
+			cloud.batch = Batch{ID: "batch-1", Remaining: 3, Messages: []RawMessage{
+				{GUID: "m1", DeviceSerial: "F12345678", Data: alarm, Pictures: []Picture{
+					{URL: cloud.srv.URL + "/pictures/1.jpg"},
+					{URL: cloud.srv.URL + "/pictures/expired.jpg"},
+				}},
+				{GUID: "m2", DeviceSerial: "F12345678"},
+			}}
+
+			var got []Message
+			sink := func(_ context.Context, msg Message) error {
+				got = append(got, msg)
+				return tt.sinkErr
+			}
+			sub := NewSubscriber(Options{Domain: cloud.srv.URL, AppKey: "app", SecretKey: "secret", Timeout: time.Second}, sink, nil, zerolog.Nop())
+
+			remaining, err := sub.Poll(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Poll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && remaining != 3 {
+				t.Errorf("remaining = %d, want 3", remaining)
+			}
+			// Сообщение без тревоги в sink не попадает
+			if len(got) != 1 || got[0].DeviceSerial != "F12345678" || string(got[0].Notification) != string(alarm) {
+				t.Fatalf("sink got %+v", got)
+			}
+			if len(got[0].Photos) != 1 || string(got[0].Photos[0].Data) != "jpeg" {
+				t.Errorf("photos = %+v, want the downloadable picture only", got[0].Photos)
+			}
+			if completed := len(cloud.completed) == 1 && cloud.completed[0] == "batch-1"; completed != tt.wantCompleted {
+				t.Errorf("completed = %v, want %v", cloud.completed, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestSubscriberPollEmptyBatch(t *testing.T) {
+	cloud := newFakeCloud(t)
+	// Облако может сообщать остаток и при пустой пачке — Poll не должен звать на повторный опрос
+	cloud.batch = Batch{ID: "batch-1", Remaining: 7}
+	sub := NewSubscriber(Options{Domain: cloud.srv.URL, AppKey: "app", SecretKey: "secret", Timeout: time.Second}, nil, nil, zerolog.Nop())
+
+	remaining, err := sub.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("remaining = %d, want 0", remaining)
+	}
+	if len(cloud.completed) != 0 {
+		t.Errorf("completed = %v, want none", cloud.completed)
+	}
+}
+
+func TestSubscriberPollDeadLettersAfterMaxAttempts(t *testing.T) {
+	cloud := newFakeCloud(t)
+	cloud.batch = Batch{ID: "batch-1", Remaining: 1, Messages: []RawMessage{
+		{GUID: "m1", DeviceSerial: "F12345678", Data: json.RawMessage(`{"eventType":"ANPR"}`)},
+	}}
+	sinkErr := errors.New("db unavailable")
+	sink := func(context.Context, Message) error { return sinkErr }
+	var deadLetters []string
+	deadLetter := func(_ context.Context, msg Message, cause error) error {
+		if !errors.Is(cause, sinkErr) {
+			t.Errorf("dead letter cause = %v, want %v", cause, sinkErr)
+		}
+		deadLetters = append(deadLetters, msg.ID)
+		return nil
+	}
+	sub := NewSubscriber(Options{Domain: cloud.srv.URL, AppKey: "app", SecretKey: "secret", Timeout: time.Second, MaxAttempts: 3}, sink, deadLetter, zerolog.Nop())
+
+	for attempt := 1; attempt < 3; attempt++ {
+		if _, err := sub.Poll(context.Background()); !errors.Is(err, sinkErr) {
+			t.Fatalf("attempt %d: error = %v, want %v", attempt, err, sinkErr)
+		}
+	}
+	if len(deadLetters) != 0 || len(cloud.completed) != 0 {
+		t.Fatalf("before max attempts: dead letters %v, completed %v", deadLetters, cloud.completed)
+	}
+	if _, err := sub.Poll(context.Background()); err != nil {
+		t.Fatalf("last attempt: error = %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0] != "m1" {
+		t.Errorf("dead letters = %v, want [m1]", deadLetters)
+	}
+	if len(cloud.completed) != 1 || cloud.completed[0] != "batch-1" {
+		t.Errorf("completed = %v, want [batch-1]", cloud.completed)
+	}
+}
+
+func TestClientRefreshesRejectedToken(t *testing.T) {
+	cloud := newFakeCloud(t)
+	client := NewClient(cloud.srv.URL, "app", "secret", time.Second)
+
+	if _, err := client.Messages(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+	client.mu.Lock()
+	client.token = "revoked"
+	client.mu.Unlock()
+
+	var apiErr *APIError
+	if _, err := client.Messages(context.Background(), 10); !errors.As(err, &apiErr) || apiErr.Code != "OPEN000007" {
+		t.Fatalf("error = %v, want rejected token", err)
+	}
+	if _, err := client.Messages(context.Background(), 10); err != nil {
+		t.Fatalf("error after token refresh = %v", err)
+	}
+	if cloud.tokens != 2 {
+		t.Errorf("token requested %d times, want 2", cloud.tokens)
+	}
+}
+
+func TestClientInvalidCredentials(t *testing.T) {
+	cloud := newFakeCloud(t)
+	if err := NewClient(cloud.srv.URL, "app", "wrong", time.Second).Subscribe(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,130 @@
+package hikvision
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// allowListPageSize — размер страницы при чтении списка номеров камеры
+const allowListPageSize = 100
+
+// ListedPlate — номер из бортового списка камеры
+type ListedPlate struct {
+	ID       string
+	Plate    string
+	ListType string
+}
+
+type lpSearchCond struct {
+	XMLName              xml.Name `xml:"LPSearchCond"`
+	SearchID             string   `xml:"searchID"`
+	MaxResult            int      `xml:"maxResult"`
+	SearchResultPosition int      `xml:"searchResultPosition"`
+}
+
+type lpSearchResult struct {
+	XMLName      xml.Name `xml:"LPListAuditSearchResult"`
+	NumOfMatches int      `xml:"numOfMatches"`
+	TotalMatches int      `xml:"totalMatches"`
+	Plates       []struct {
+		ID           string `xml:"id"`
+		LicensePlate string `xml:"LicensePlate"`
+		ListType     string `xml:"listType"`
+	} `xml:"LicensePlateInfoList>LicensePlateInfo"`
+}
+
+// ListAllowListPlates возвращает все номера бортового списка камеры (белые и чёрные)
+func (c *Client) ListAllowListPlates(ctx context.Context, channel string) ([]ListedPlate, error) {
+	searchID := uuid.New().String()
+	var result []ListedPlate
+	for position := 0; ; {
+		body, err := xml.Marshal(lpSearchCond{
+			SearchID:             searchID,
+			MaxResult:            allowListPageSize,
+			SearchResultPosition: position,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encode plate list search: %w", err)
+		}
+		data, _, err := c.do(ctx, http.MethodPost, c.trafficPath(channel, "searchLPListAudit"), body, "application/xml")
+		if err != nil {
+			return nil, fmt.Errorf("search camera plate list: %w", err)
+		}
+
+		var page lpSearchResult
+		if err := xml.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("decode camera plate list: %w", err)
+		}
+		for _, p := range page.Plates {
+			result = append(result, ListedPlate{ID: p.ID, Plate: p.LicensePlate, ListType: p.ListType})
+		}
+
+		position += len(page.Plates)
+		if len(page.Plates) == 0 || position >= page.TotalMatches {
+			break
+		}
+	}
+	return result, nil
+}
+
+// AddAllowListPlates добавляет номера в белый список камеры (бессрочно)
+func (c *Client) AddAllowListPlates(ctx context.Context, channel string, plates []string) error {
+	if len(plates) == 0 {
+		return nil
+	}
+	type plateInfo struct {
+		LicensePlate       string `json:"LicensePlate"`
+		ListType           string `json:"listType"`
+		CreateTime         string `json:"createTime"`
+		EffectiveStartDate string `json:"effectiveStartDate"`
+		EffectiveTime      string `json:"effectiveTime"`
+	}
+	now := time.Now()
+	list := make([]plateInfo, 0, len(plates))
+	for _, plate := range plates {
+		list = append(list, plateInfo{
+			LicensePlate:       plate,
+			ListType:           "whiteList",
+			CreateTime:         now.Format("2006-01-02T15:04:05"),
+			EffectiveStartDate: now.Format("2006-01-02"),
+			EffectiveTime:      "2037-12-31",
+		})
+	}
+	body, err := json.Marshal(map[string]interface{}{"LicensePlateInfoList": list})
+	if err != nil {
+		return fmt.Errorf("encode plate list: %w", err)
+	}
+	if _, _, err := c.do(ctx, http.MethodPut, c.trafficPath(channel, "licensePlateAuditData/record")+"?format=json", body, "application/json"); err != nil {
+		return fmt.Errorf("add plates to camera list: %w", err)
+	}
+	return nil
+}
+
+// DeleteAllowListPlates удаляет записи бортового списка камеры по их id
+func (c *Client) DeleteAllowListPlates(ctx context.Context, channel string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"id": ids})
+	if err != nil {
+		return fmt.Errorf("encode plate ids: %w", err)
+	}
+	if _, _, err := c.do(ctx, http.MethodPut, c.trafficPath(channel, "DelLicensePlateAuditData")+"?format=json", body, "application/json"); err != nil {
+		return fmt.Errorf("delete plates from camera list: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) trafficPath(channel, resource string) string {
+	if channel == "" {
+		channel = "1"
+	}
+	return "/ISAPI/Traffic/channels/" + url.PathEscape(channel) + "/" + resource
+}
@@ -0,0 +1,56 @@
+package hikvision
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseDigestChallenge(t *testing.T) {
+	params := parseDigestChallenge(`Digest qop="auth", realm="IP Camera(C1234)", nonce="abc,123", stale="FALSE", algorithm=MD5`)
+
+	want := map[string]string{
+		"qop":       "auth",
+		"realm":     "IP Camera(C1234)",
+		"nonce":     "abc,123",
+		"stale":     "FALSE",
+		"algorithm": "MD5",
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Errorf("param %q = %q, want %q", key, params[key], value)
+		}
+	}
+}
+
+func TestSnapshotWithDigestAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ISAPI/Streaming/channels/101/picture" {
+			http.NotFound(w, r)
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Digest ") || !strings.Contains(auth, `username="admin"`) {
+			w.Header().Set("WWW-Authenticate", `Digest qop="auth", realm="cam", nonce="n1"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte{0xff, 0xd8, 0xff, 0xe0})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(strings.Replace(server.URL, "http://", "http://admin:secret@", 1), "", "")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	data, contentType, err := client.Snapshot(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if contentType != "image/jpeg" || len(data) != 4 {
+		t.Fatalf("unexpected snapshot: %s, %d bytes", contentType, len(data))
+	}
+}
@@ -0,0 +1,267 @@
+package hikvision
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+// EventTypeVideoLoss — eventType уведомления о пропаже видеосигнала
+const EventTypeVideoLoss = "videoloss"
+
+// Event — уведомление камеры EventNotificationAlert (ISAPI) о распознанном номере или о состоянии камеры
+type Event struct {
+	XMLName          xml.Name `xml:"EventNotificationAlert"`
+	EventType        string   `xml:"eventType" json:"event_type"`
+	EventDescription string   `xml:"eventDescription" json:"event_description"`
+	EventState       string   `xml:"eventState" json:"event_state"`
+	DateTime         string   `xml:"dateTime" json:"date_time"`
+	ChannelID        string   `xml:"channelID" json:"channel_id"`
+	DeviceID         string   `xml:"deviceID" json:"device_id"`
+	DeviceName       string   `xml:"deviceName" json:"device_name"`
+	IPAddress        string   `xml:"ipAddress" json:"ip_address"`
+	PortNo           string   `xml:"portNo" json:"port_no"`
+	ProtocolType     string   `xml:"protocolType" json:"protocol_type"`
+	ANPR             struct {
+		LicensePlate    string  `xml:"licensePlate" json:"license_plate"`
+		ConfidenceLevel float64 `xml:"confidenceLevel" json:"confidence_level"`
+		VehicleType     string  `xml:"vehicleType" json:"vehicle_type"`
+		VehicleColor    string  `xml:"vehicleColor" json:"vehicle_color"`
+		Color           string  `xml:"color" json:"color"`
+		PlateColor      string  `xml:"plateColor" json:"plate_color"`
+		Country         string  `xml:"country" json:"country"`
+		Brand           string  `xml:"brand" json:"brand"`
+		Direction       string  `xml:"direction" json:"direction"`
+		LaneNo          string  `xml:"laneNo" json:"lane_no"`
+		Speed           string  `xml:"speed" json:"speed"`
+	} `xml:"ANPR" json:"anpr"`
+	VehicleInfo struct {
+		Type             string `xml:"vehicleType" json:"vehicle_type"`
+		Color            string `xml:"color" json:"color"`
+		VehicleColor     string `xml:"vehicleColor" json:"vehicle_color"`
+		Brand            string `xml:"brand" json:"brand"`
+		VehicleLogoRecog string `xml:"vehicleLogoRecog" json:"vehicle_logo_recog"`
+		Model            string `xml:"vehicleModel" json:"vehicle_model"`
+		VehileModel      string `xml:"vehileModel" json:"vehile_model"`
+		PlateColor       string `xml:"plateColor" json:"plate_color"`
+		Country          string `xml:"country" json:"country"`
+		Speed            string `xml:"speed" json:"speed"`
+	} `xml:"vehicleInfo" json:"vehicle_info"`
+	VehicleGATInfo struct {
+		VehicleTypeByGAT string `xml:"vehicleTypeByGAT" json:"vehicle_type_by_gat"`
+		ColorByGAT       string `xml:"colorByGAT" json:"color_by_gat"`
+		PlateTypeByGAT   string `xml:"palteTypeByGAT" json:"plate_type_by_gat"`
+		PlateColorByGAT  string `xml:"plateColorByGAT" json:"plate_color_by_gat"`
+	} `xml:"VehicleGATInfo" json:"vehicle_gat_info"`
+	PicInfo struct {
+		StoragePath string   `xml:"ftpPath" json:"ftp_path"`
+		FilePath    string   `xml:"filePath" json:"file_path"`
+		FilePaths   []string `xml:"filePathList>filePath" json:"file_path_list"`
+	} `xml:"picInfo" json:"pic_info"`
+}
+
+// ParseEvent разбирает XML уведомления камеры
+func ParseEvent(data []byte) (*Event, error) {
+	event := &Event{}
+	if err := xml.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("failed to parse hikvision event: %w", err)
+	}
+	return event, nil
+}
+
+// CameraID возвращает идентификатор камеры из уведомления (channelID или deviceID)
+func (e *Event) CameraID() string {
+	return firstNonEmpty(e.ChannelID, e.DeviceID)
+}
+
+// IsSignal сообщает, что уведомление не о распознанном номере, а о состоянии камеры: heartbeat,
+// videoloss и другие события без номера, которые камеры шлют на тот же адрес
+func (e *Event) IsSignal() bool {
+	if strings.TrimSpace(e.ANPR.LicensePlate) != "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(e.EventType)) {
+	case "", "anpr", "vehicledetection":
+		return false
+	}
+	return true
+}
+
+// VideoLoss возвращает состояние видеосигнала из уведомления videoloss: true — сигнал пропал (eventState
+// active), false — сигнал есть. Многие камеры шлют videoloss inactive каждые несколько секунд вместо
+// heartbeat. Для остальных событий возвращает nil.
+func (e *Event) VideoLoss() *bool {
+	if !strings.EqualFold(strings.TrimSpace(e.EventType), EventTypeVideoLoss) {
+		return nil
+	}
+	lost := strings.EqualFold(strings.TrimSpace(e.EventState), "active")
+	return &lost
+}
+
+// ToEventPayload преобразует уведомление в EventPayload.
+// loc — часовой пояс камеры для dateTime без смещения (nil — UTC).
+func (e *Event) ToEventPayload(rawXML []byte, loc *time.Location) anpr.EventPayload {
+	eventTime := parseTime(e.DateTime, loc)
+	lane := parseLane(e.ANPR.LaneNo)
+
+	// Цвет: ПРИОРИТЕТ - текстовые значения из vehicleInfo, НЕ используем GAT коды если есть текст
+	// GAT коды (H, C и т.д.) - это числовые коды, не читаемые названия
+	vehicleColor := firstNonEmpty(
+		e.VehicleInfo.Color,        // "blue", "white" - текстовое значение (ПРИОРИТЕТ)
+		e.VehicleInfo.VehicleColor, // альтернативное поле в vehicleInfo
+		e.ANPR.VehicleColor,        // из ANPR секции (если есть)
+		e.ANPR.Color,               // альтернативное поле в ANPR
+	)
+	// НЕ используем GAT коды - они нечитаемые (H, C и т.д.)
+	// Если текстового значения нет, оставляем пустым
+
+	// Тип: сначала из ANPR, потом из GAT, потом из vehicleInfo
+	vehicleType := firstNonEmpty(
+		e.ANPR.VehicleType,
+		e.VehicleGATInfo.VehicleTypeByGAT,
+		e.VehicleInfo.Type,
+	)
+	vehiclePlateColor := firstNonEmpty(
+		e.ANPR.PlateColor,
+		e.VehicleGATInfo.PlateColorByGAT,
+		e.VehicleInfo.PlateColor,
+	)
+	vehicleCountry := firstNonEmpty(e.ANPR.Country, e.VehicleInfo.Country)
+
+	// Бренд: сначала текстовое значение, потом ID из vehicleLogoRecog
+	vehicleBrand := firstNonEmpty(e.VehicleInfo.Brand, e.ANPR.Brand)
+	// Если текстового значения нет, но есть ID логотипа, сохраняем ID
+	if vehicleBrand == "" && e.VehicleInfo.VehicleLogoRecog != "" && e.VehicleInfo.VehicleLogoRecog != "0" {
+		vehicleBrand = "brand_id:" + e.VehicleInfo.VehicleLogoRecog
+	}
+
+	// Модель: сначала текстовое значение, потом ID из vehileModel
+	vehicleModel := firstNonEmpty(e.VehicleInfo.Model, e.VehicleInfo.VehileModel)
+	// Если текстового значения нет, но есть ID модели, сохраняем ID (игнорируем "0")
+	if vehicleModel == "" || vehicleModel == "0" {
+		// Если есть другой ID модели, используем его
+		if e.VehicleInfo.VehileModel != "" && e.VehicleInfo.VehileModel != "0" {
+			vehicleModel = "model_id:" + e.VehicleInfo.VehileModel
+		} else {
+			vehicleModel = ""
+		}
+	}
+	speedPtr := parseOptionalFloat(firstNonEmpty(e.VehicleInfo.Speed, e.ANPR.Speed))
+
+	cameraModel := firstNonEmpty(e.DeviceName, e.DeviceID)
+	snapshotURL := firstNonEmpty(e.PicInfo.StoragePath, e.PicInfo.FilePath)
+	if snapshotURL == "" && len(e.PicInfo.FilePaths) > 0 {
+		snapshotURL = e.PicInfo.FilePaths[0]
+	}
+
+	rawPayload := map[string]interface{}{
+		"event_type":        e.EventType,
+		"event_description": e.EventDescription,
+		"device_id":         e.DeviceID,
+		"device_name":       e.DeviceName,
+		"channel_id":        e.ChannelID,
+		"ip_address":        e.IPAddress,
+		"port_no":           e.PortNo,
+		"protocol_type":     e.ProtocolType,
+		"anpr":              e.ANPR,
+		"vehicle_info":      e.VehicleInfo,
+		"vehicle_gat_info":  e.VehicleGATInfo,
+	}
+	if len(rawXML) > 0 {
+		rawPayload["xml"] = string(rawXML)
+	}
+
+	return anpr.EventPayload{
+		CameraID:    e.CameraID(),
+		CameraModel: cameraModel,
+		Plate:       strings.TrimSpace(e.ANPR.LicensePlate),
+		Confidence:  e.ANPR.ConfidenceLevel,
+		Direction:   e.ANPR.Direction,
+		Lane:        lane,
+		EventTime:   eventTime,
+		Vehicle: anpr.VehicleInfo{
+			Color:      vehicleColor,
+			Type:       vehicleType,
+			Brand:      vehicleBrand,
+			Model:      vehicleModel,
+			Country:    vehicleCountry,
+			PlateColor: vehiclePlateColor,
+			Speed:      speedPtr,
+		},
+		SnapshotURL: snapshotURL,
+		RawPayload:  rawPayload,
+	}
+}
+
+// parseTime разбирает dateTime из уведомления камеры и возвращает время в UTC.
+// Значения со смещением (+06:00, +0600, Z) разбираются как есть, без смещения — в поясе камеры loc.
+func parseTime(value string, loc *time.Location) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	withOffset := []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05.999999999Z0700",
+		"2006-01-02T15:04:05Z0700",
+		"2006-01-02 15:04:05Z07:00",
+	}
+	for _, layout := range withOffset {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts.UTC()
+		}
+	}
+
+	local := []string{
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+	}
+	for _, layout := range local {
+		if ts, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return ts.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+func parseLane(value string) int {
+	if value == "" {
+		return 0
+	}
+	lane, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return lane
+}
+
+func parseOptionalFloat(value string) *float64 {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return &f
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package hikvision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ParseJSONEvent разбирает JSON-уведомление камеры. Поля называются так же, как в XML
+// (eventType, ANPR.licensePlate, vehicleInfo.color), но числа приходят числами, а не строками.
+// Уведомление может быть обёрнуто в объект EventNotificationAlert.
+func ParseJSONEvent(data []byte) (*Event, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root map[string]interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse hikvision json event: %w", err)
+	}
+	if wrapped := jsonObject(root, "EventNotificationAlert"); wrapped != nil {
+		root = wrapped
+	}
+	if jsonString(root, "eventType") == "" && jsonObject(root, "ANPR") == nil {
+		return nil, errors.New("failed to parse hikvision json event: no eventType or ANPR")
+	}
+
+	e := &Event{
+		EventType:        jsonString(root, "eventType"),
+		EventDescription: jsonString(root, "eventDescription"),
+		EventState:       jsonString(root, "eventState"),
+		DateTime:         jsonString(root, "dateTime"),
+		ChannelID:        jsonString(root, "channelID"),
+		DeviceID:         jsonString(root, "deviceID"),
+		DeviceName:       jsonString(root, "deviceName"),
+		IPAddress:        jsonString(root, "ipAddress"),
+		PortNo:           jsonString(root, "portNo"),
+		ProtocolType:     jsonString(root, "protocolType"),
+	}
+
+	anprInfo := jsonObject(root, "ANPR")
+	e.ANPR.LicensePlate = jsonString(anprInfo, "licensePlate")
+	e.ANPR.ConfidenceLevel, _ = strconv.ParseFloat(jsonString(anprInfo, "confidenceLevel"), 64)
+	e.ANPR.VehicleType = jsonString(anprInfo, "vehicleType")
+	e.ANPR.VehicleColor = jsonString(anprInfo, "vehicleColor")
+	e.ANPR.Color = jsonString(anprInfo, "color")
+	e.ANPR.PlateColor = jsonString(anprInfo, "plateColor")
+	e.ANPR.Country = jsonString(anprInfo, "country")
+	e.ANPR.Brand = jsonString(anprInfo, "brand")
+	e.ANPR.Direction = jsonString(anprInfo, "direction")
+	e.ANPR.LaneNo = jsonString(anprInfo, "laneNo")
+	e.ANPR.Speed = jsonString(anprInfo, "speed")
+
+	vehicle := jsonObject(root, "vehicleInfo")
+	e.VehicleInfo.Type = jsonString(vehicle, "vehicleType")
+	e.VehicleInfo.Color = jsonString(vehicle, "color")
+	e.VehicleInfo.VehicleColor = jsonString(vehicle, "vehicleColor")
+	e.VehicleInfo.Brand = jsonString(vehicle, "brand")
+	e.VehicleInfo.VehicleLogoRecog = jsonString(vehicle, "vehicleLogoRecog")
+	e.VehicleInfo.Model = jsonString(vehicle, "vehicleModel")
+	e.VehicleInfo.VehileModel = jsonString(vehicle, "vehileModel")
+	e.VehicleInfo.PlateColor = jsonString(vehicle, "plateColor")
+	e.VehicleInfo.Country = jsonString(vehicle, "country")
+	e.VehicleInfo.Speed = jsonString(vehicle, "speed")
+
+	gat := jsonObject(root, "VehicleGATInfo")
+	e.VehicleGATInfo.VehicleTypeByGAT = jsonString(gat, "vehicleTypeByGAT")
+	e.VehicleGATInfo.ColorByGAT = jsonString(gat, "colorByGAT")
+	e.VehicleGATInfo.PlateTypeByGAT = jsonString(gat, "palteTypeByGAT")
+	e.VehicleGATInfo.PlateColorByGAT = jsonString(gat, "plateColorByGAT")
+
+	pic := jsonObject(root, "picInfo")
+	e.PicInfo.StoragePath = jsonString(pic, "ftpPath")
+	e.PicInfo.FilePath = jsonString(pic, "filePath")
+	if list, ok := pic["filePathList"].([]interface{}); ok {
+		for _, item := range list {
+			if value, ok := item.(string); ok && value != "" {
+				e.PicInfo.FilePaths = append(e.PicInfo.FilePaths, value)
+			}
+		}
+	}
+	return e, nil
+}
+
+func jsonObject(m map[string]interface{}, key string) map[string]interface{} {
+	value, _ := m[key].(map[string]interface{})
+	return value
+}
+
+// jsonString возвращает строку или число поля как строку (пусто, если поля нет)
+func jsonString(m map[string]interface{}, key string) string {
+	switch value := m[key].(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package hikvision
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+
+	tests := []struct {
+		name  string
+		value string
+		loc   *time.Location
+		want  time.Time
+	}{
+		{
+			name:  "rfc3339 with offset",
+			value: "2025-01-15T10:00:00+06:00",
+			loc:   almaty,
+			want:  time.Date(2025, 1, 15, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "compact offset",
+			value: "2025-01-15T10:00:00+0600",
+			loc:   almaty,
+			want:  time.Date(2025, 1, 15, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "local time without offset uses camera zone",
+			value: "2025-01-15T10:00:00",
+			loc:   almaty,
+			want:  time.Date(2025, 1, 15, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "space separated local time",
+			value: "2025-01-15 10:00:00",
+			loc:   almaty,
+			want:  time.Date(2025, 1, 15, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "nil location falls back to utc",
+			value: "2025-01-15T10:00:00",
+			loc:   nil,
+			want:  time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "garbage returns zero",
+			value: "yesterday",
+			loc:   almaty,
+			want:  time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTime(tt.value, tt.loc)
+			if !got.Equal(tt.want) {
+				t.Fatalf("parseTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventSignal(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		body          string
+		wantSignal    bool
+		wantVideoLoss *bool
+	}{
+		{name: "anpr", format: FormatXML, body: `<EventNotificationAlert><eventType>ANPR</eventType><ANPR><licensePlate>123ABC02</licensePlate></ANPR></EventNotificationAlert>`},
+		{name: "heartbeat", format: FormatXML, body: `<EventNotificationAlert><eventType>heartBeat</eventType><eventState>active</eventState></EventNotificationAlert>`, wantSignal: true},
+		{name: "video lost", format: FormatXML, body: `<EventNotificationAlert><eventType>videoloss</eventType><eventState>active</eventState></EventNotificationAlert>`, wantSignal: true, wantVideoLoss: boolPtr(true)},
+		{name: "videoloss inactive heartbeat", format: FormatJSON, body: `{"eventType":"videoloss","eventState":"inactive","channelID":1}`, wantSignal: true, wantVideoLoss: boolPtr(false)},
+		{name: "other event with plate", format: FormatJSON, body: `{"eventType":"vehicleBlackList","ANPR":{"licensePlate":"123ABC02"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := (&Notification{Format: tt.format, Body: []byte(tt.body)}).Event()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := event.IsSignal(); got != tt.wantSignal {
+				t.Errorf("IsSignal() = %v, want %v", got, tt.wantSignal)
+			}
+			got := event.VideoLoss()
+			if (got == nil) != (tt.wantVideoLoss == nil) || (got != nil && *got != *tt.wantVideoLoss) {
+				t.Errorf("VideoLoss() = %v, want %v", got, tt.wantVideoLoss)
+			}
+		})
+	}
+}
+
+func TestResponseStatusOK(t *testing.T) {
+	contentType, body := ResponseStatusOK(FormatXML, "/api/v1/anpr/hikvision")
+	if !strings.HasPrefix(contentType, "application/xml") ||
+		!strings.Contains(string(body), "<statusCode>1</statusCode>") ||
+		!strings.Contains(string(body), "<requestURL>/api/v1/anpr/hikvision</requestURL>") {
+		t.Errorf("xml ack = %s %s", contentType, body)
+	}
+	contentType, body = ResponseStatusOK(FormatJSON, "/api/v1/anpr/hikvision")
+	if contentType != "application/json" || !strings.Contains(string(body), `"statusString":"OK"`) {
+		t.Errorf("json ack = %s %s", contentType, body)
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
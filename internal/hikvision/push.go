@@ -0,0 +1,207 @@
+package hikvision
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"path"
+	"strings"
+)
+
+// pushMaxMemory — сколько multipart-уведомления держится в памяти, остальное уходит во временные файлы
+const pushMaxMemory = 10 << 20
+
+// Форматы тела уведомления
+const (
+	FormatXML  = "xml"
+	FormatJSON = "json"
+)
+
+var (
+	// ErrInvalidMultipart — тело уведомления не разбирается как multipart
+	ErrInvalidMultipart = errors.New("invalid multipart payload")
+	// ErrXMLNotFound — в запросе нет ни XML, ни JSON уведомления
+	ErrXMLNotFound = errors.New("xml payload not found")
+)
+
+// Notification — тело уведомления камеры, найденное в запросе
+type Notification struct {
+	// Format — FormatXML (EventNotificationAlert) или FormatJSON
+	Format string
+	Body   []byte
+	// Part — имя части multipart, в которой нашлось уведомление (пусто для запроса без multipart)
+	Part string
+}
+
+// Event разбирает уведомление в зависимости от формата
+func (n *Notification) Event() (*Event, error) {
+	if n.Format == FormatJSON {
+		return ParseJSONEvent(n.Body)
+	}
+	return ParseEvent(n.Body)
+}
+
+// ResponseStatusOK возвращает тип содержимого и тело подтверждения ISAPI ResponseStatus в формате
+// уведомления. Камера ждёт его в ответ на push и без него повторяет отправку или считает сервер недоступным.
+func ResponseStatusOK(format, requestURL string) (string, []byte) {
+	if format == FormatJSON {
+		body, _ := json.Marshal(map[string]interface{}{
+			"requestURL":    requestURL,
+			"statusCode":    1,
+			"statusString":  "OK",
+			"subStatusCode": "ok",
+		})
+		return "application/json", body
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<ResponseStatus version="2.0" xmlns="http://www.isapi.org/ver20/XMLSchema">` + "\n")
+	buf.WriteString("<requestURL>")
+	_ = xml.EscapeText(&buf, []byte(requestURL))
+	buf.WriteString("</requestURL>\n<statusCode>1</statusCode>\n<statusString>OK</statusString>\n<subStatusCode>ok</subStatusCode>\n</ResponseStatus>\n")
+	return "application/xml; charset=UTF-8", buf.Bytes()
+}
+
+// ExtractNotification находит уведомление в теле запроса камеры. contentType — заголовок Content-Type
+// вместе с boundary. Поддерживаются:
+//   - multipart с XML в части-файле или в поле (старые прошивки присылают anpr.xml, новые — anpr.xml рядом
+//     с JSON-частями); части из preferredParts проверяются первыми;
+//   - multipart только с JSON-уведомлением;
+//   - XML (application/xml, text/xml) и JSON (application/json) без multipart.
+//
+// Если Content-Type не указан или неизвестен, формат определяется по первому символу тела.
+func ExtractNotification(contentType string, body []byte, preferredParts []string) (*Notification, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if params["boundary"] == "" {
+			return nil, fmt.Errorf("%w: no boundary in content type %q", ErrInvalidMultipart, contentType)
+		}
+		return extractMultipart(body, params["boundary"], preferredParts)
+	}
+
+	format := formatOf(mediaType, "")
+	if format == "" {
+		format = sniffFormat(body)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("%w: content type %q", ErrInvalidMultipart, contentType)
+	}
+	return &Notification{Format: format, Body: body}, nil
+}
+
+func extractMultipart(body []byte, boundary string, preferredParts []string) (*Notification, error) {
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(pushMaxMemory)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMultipart, err)
+	}
+	defer form.RemoveAll()
+
+	// Части, названные в настройках, имеют приоритет: в них уведомление лежит наверняка
+	for _, name := range preferredParts {
+		for key, files := range form.File {
+			if !strings.EqualFold(key, name) || len(files) == 0 {
+				continue
+			}
+			data, err := readPart(files[0])
+			if err != nil {
+				return nil, err
+			}
+			if format := formatOf(partContentType(files[0]), files[0].Filename+" "+key); format != "" {
+				return &Notification{Format: format, Body: data, Part: key}, nil
+			}
+			if format := sniffFormat(data); format != "" {
+				return &Notification{Format: format, Body: data, Part: key}, nil
+			}
+		}
+		for key, values := range form.Value {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				if format := sniffFormat([]byte(values[0])); format != "" {
+					return &Notification{Format: format, Body: []byte(values[0]), Part: key}, nil
+				}
+			}
+		}
+	}
+
+	// XML — основной формат уведомлений, поэтому ищется раньше JSON
+	for _, want := range []string{FormatXML, FormatJSON} {
+		for key, files := range form.File {
+			for _, fh := range files {
+				if formatOf(partContentType(fh), fh.Filename) != want {
+					continue
+				}
+				data, err := readPart(fh)
+				if err != nil {
+					return nil, err
+				}
+				return &Notification{Format: want, Body: data, Part: key}, nil
+			}
+		}
+		for key, values := range form.Value {
+			if len(values) == 0 {
+				continue
+			}
+			if strings.Contains(strings.ToLower(key), want) || sniffFormat([]byte(values[0])) == want {
+				return &Notification{Format: want, Body: []byte(values[0]), Part: key}, nil
+			}
+		}
+	}
+
+	return nil, ErrXMLNotFound
+}
+
+func readPart(fh *multipart.FileHeader) ([]byte, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func partContentType(fh *multipart.FileHeader) string {
+	mediaType, _, _ := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+	return mediaType
+}
+
+// formatOf определяет формат по типу содержимого, а если он ничего не говорит — по расширению имени
+func formatOf(mediaType, name string) string {
+	mediaType = strings.ToLower(mediaType)
+	switch {
+	case strings.Contains(mediaType, "xml"):
+		return FormatXML
+	case strings.Contains(mediaType, "json"):
+		return FormatJSON
+	}
+	for _, field := range strings.Fields(strings.ToLower(name)) {
+		switch path.Ext(field) {
+		case ".xml":
+			return FormatXML
+		case ".json":
+			return FormatJSON
+		}
+	}
+	return ""
+}
+
+// sniffFormat определяет формат по первому значащему символу тела
+func sniffFormat(data []byte) string {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) == 0 {
+		return ""
+	}
+	switch data[0] {
+	case '<':
+		return FormatXML
+	case '{':
+		return FormatJSON
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package hikvision
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+)
+
+const (
+	testXMLNotification  = `<EventNotificationAlert><eventType>ANPR</eventType></EventNotificationAlert>`
+	testJSONNotification = `{"eventType":"ANPR","channelID":1,"ANPR":{"licensePlate":"123ABC02","confidenceLevel":91}}`
+)
+
+func TestExtractNotification(t *testing.T) {
+	multipartBody := func(write func(w *multipart.Writer)) (string, []byte) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		write(w)
+		w.Close()
+		return w.FormDataContentType(), buf.Bytes()
+	}
+	jsonPart := func(w *multipart.Writer, name, body string) {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+name+`"`)
+		header.Set("Content-Type", "application/json")
+		part, _ := w.CreatePart(header)
+		part.Write([]byte(body))
+	}
+
+	tests := []struct {
+		name       string
+		write      func(w *multipart.Writer)
+		ctype      string
+		body       string
+		wantFormat string
+		wantBody   string
+		wantErr    error
+	}{
+		{
+			name: "xml file part",
+			write: func(w *multipart.Writer) {
+				part, _ := w.CreateFormFile("anpr.xml", "anpr.xml")
+				part.Write([]byte(testXMLNotification))
+				picture, _ := w.CreateFormFile("licensePlatePicture.jpg", "licensePlatePicture.jpg")
+				picture.Write([]byte{0xff, 0xd8})
+			},
+			wantFormat: FormatXML,
+			wantBody:   testXMLNotification,
+		},
+		{
+			name:       "xml form value",
+			write:      func(w *multipart.Writer) { w.WriteField("MoveDetection.xml", testXMLNotification) },
+			wantFormat: FormatXML,
+			wantBody:   testXMLNotification,
+		},
+		{
+			name: "anpr.xml part with json siblings",
+			write: func(w *multipart.Writer) {
+				jsonPart(w, "deviceInfo", `{"deviceName":"gate-1"}`)
+				w.WriteField("anpr.xml", testXMLNotification)
+				jsonPart(w, "vehicleAttributes", `{"color":"white"}`)
+			},
+			wantFormat: FormatXML,
+			wantBody:   testXMLNotification,
+		},
+		{
+			name:       "json-only multipart",
+			write:      func(w *multipart.Writer) { jsonPart(w, "event", testJSONNotification) },
+			wantFormat: FormatJSON,
+			wantBody:   testJSONNotification,
+		},
+		{
+			name:       "application/xml body",
+			ctype:      "application/xml; charset=utf-8",
+			body:       testXMLNotification,
+			wantFormat: FormatXML,
+			wantBody:   testXMLNotification,
+		},
+		{
+			name:       "application/json body",
+			ctype:      "application/json",
+			body:       testJSONNotification,
+			wantFormat: FormatJSON,
+			wantBody:   testJSONNotification,
+		},
+		{
+			name:       "no content type sniffs body",
+			ctype:      "-",
+			body:       "\n" + testXMLNotification,
+			wantFormat: FormatXML,
+			wantBody:   "\n" + testXMLNotification,
+		},
+		{
+			name:    "no notification part",
+			write:   func(w *multipart.Writer) { w.WriteField("plate", "123ABC02") },
+			wantErr: ErrXMLNotFound,
+		},
+		{
+			name:    "unknown body",
+			ctype:   "text/plain",
+			body:    "plate=123ABC02",
+			wantErr: ErrInvalidMultipart,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctype, body := tt.ctype, []byte(tt.body)
+			if tt.write != nil {
+				ctype, body = multipartBody(tt.write)
+			}
+			if ctype == "-" {
+				ctype = ""
+			}
+
+			got, err := ExtractNotification(ctype, body, []string{"anpr.xml"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ExtractNotification() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractNotification() error = %v", err)
+			}
+			if got.Format != tt.wantFormat || string(got.Body) != tt.wantBody {
+				t.Fatalf("ExtractNotification() = %s %q, want %s %q", got.Format, got.Body, tt.wantFormat, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseJSONEvent(t *testing.T) {
+	event, err := ParseJSONEvent([]byte(`{"EventNotificationAlert": {
+		"eventType": "ANPR", "channelID": 2, "dateTime": "2025-01-15T10:00:00+06:00",
+		"ANPR": {"licensePlate": "123ABC02", "confidenceLevel": 91.5, "laneNo": 1, "direction": "forward"},
+		"vehicleInfo": {"color": "white", "vehicleType": "truck", "speed": 12}
+	}}`))
+	if err != nil {
+		t.Fatalf("ParseJSONEvent() error = %v", err)
+	}
+	payload := event.ToEventPayload(nil, nil)
+	if payload.CameraID != "2" || payload.Plate != "123ABC02" || payload.Confidence != 91.5 || payload.Lane != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.Vehicle.Color != "white" || payload.Vehicle.Speed == nil || *payload.Vehicle.Speed != 12 {
+		t.Errorf("vehicle = %+v", payload.Vehicle)
+	}
+
+	if _, err := ParseJSONEvent([]byte(`{"deviceName":"gate-1"}`)); err == nil {
+		t.Error("ParseJSONEvent() without eventType and ANPR must fail")
+	}
+}
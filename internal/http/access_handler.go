@@ -0,0 +1,201 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+// accessRuleRequest — тело изменения правила доступа подрядчика; nil — не менять
+type accessRuleRequest struct {
+	Schedule          *string `json:"schedule"`
+	MaxTripsPerNight  *int    `json:"max_trips_per_night"`
+	PaidTripsPerNight *int    `json:"paid_trips_per_night"`
+}
+
+// plateTripQuotaRequest — квота оплачиваемых рейсов номера за ночь
+type plateTripQuotaRequest struct {
+	PaidTripsPerNight *int `json:"paid_trips_per_night" binding:"required"`
+}
+
+// contractorPolygonsRequest — полный список полигонов подрядчика; пустой снимает закрепление
+type contractorPolygonsRequest struct {
+	PolygonIDs []uuid.UUID `json:"polygon_ids"`
+}
+
+func (h *Handler) listContractorAccessRules(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	rules, err := h.anprService.ListContractorAccessRules(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(rules))
+}
+
+func (h *Handler) updateContractorAccessRule(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	contractorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid contractor id"))
+		return
+	}
+
+	var req accessRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	rule, err := h.anprService.UpdateContractorAccessRule(c.Request.Context(), contractorID, service.UpdateContractorAccessRuleInput{
+		Schedule:          req.Schedule,
+		MaxTripsPerNight:  req.MaxTripsPerNight,
+		PaidTripsPerNight: req.PaidTripsPerNight,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(rule))
+}
+
+func (h *Handler) listPlateTripQuotas(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	quotas, err := h.anprService.ListPlateTripQuotas(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(quotas))
+}
+
+func (h *Handler) setPlateTripQuota(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	var req plateTripQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	quota, err := h.anprService.SetPlateTripQuota(c.Request.Context(), c.Param("plate"), *req.PaidTripsPerNight)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(quota))
+}
+
+func (h *Handler) deletePlateTripQuota(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	if err := h.anprService.DeletePlateTripQuota(c.Request.Context(), c.Param("plate")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+func (h *Handler) listContractorPolygons(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	assignments, err := h.anprService.ListContractorPolygons(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(assignments))
+}
+
+func (h *Handler) setContractorPolygons(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	contractorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid contractor id"))
+		return
+	}
+
+	var req contractorPolygonsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	assignment, err := h.anprService.SetContractorPolygons(c.Request.Context(), contractorID, req.PolygonIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(assignment))
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/service"
+	"anpr-service/internal/storage"
+)
+
+// adminSummaryResponse — сводка состояния сервиса
+type adminSummaryResponse struct {
+	Maintenance middleware.MaintenanceStatus `json:"maintenance"`
+	DBQuota     service.DBQuotaStatus        `json:"db_quota"`
+	Storage     storage.FailoverStatus       `json:"storage"`
+}
+
+// getAdminSummary — сводка состояния сервиса для администратора:
+// режим обслуживания, заполнение квоты БД и состояние хранилища фото
+func (h *Handler) getAdminSummary(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(adminSummaryResponse{
+		Maintenance: h.maintenance.Status(),
+		DBQuota:     h.anprService.DBQuotaStatus(),
+		Storage:     h.photoStore.Status(),
+	}))
+}
+
+// getIngestSLO — соблюдение SLO задержки приёма событий камер за последние сутки по камерам
+func (h *Handler) getIngestSLO(c *gin.Context) {
+	report, err := h.anprService.IngestSLO(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// getMetrics — метрики сервиса в текстовом формате Prometheus
+func (h *Handler) getMetrics(c *gin.Context) {
+	c.Header("Content-Type", metrics.ContentType)
+	c.Status(http.StatusOK)
+	if err := h.anprService.WriteMetrics(c.Writer); err != nil {
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to write metrics")
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listPhotoDuplicates — фото событий, уже присланные с другими событиями (подлог при ручном импорте)
+func (h *Handler) listPhotoDuplicates(c *gin.Context) {
+	// Совпадения охватывают всех подрядчиков, поэтому доступны тем же ролям, что и списки
+	if !h.canViewLists(c) {
+		return
+	}
+
+	var from, to *time.Time
+	if value := strings.TrimSpace(c.Query("from")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid from time format, use RFC3339"))
+			return
+		}
+		from = &t
+	}
+	if value := strings.TrimSpace(c.Query("to")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid to time format, use RFC3339"))
+			return
+		}
+		to = &t
+	}
+
+	var cameraID *string
+	if value := strings.TrimSpace(c.Query("camera_id")); value != "" {
+		cameraID = &value
+	}
+	exactOnly := false
+	if raw := strings.TrimSpace(c.Query("exact")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid exact"))
+			return
+		}
+		exactOnly = parsed
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	duplicates, err := h.anprService.ListPhotoDuplicates(c.Request.Context(), from, to, cameraID, exactOnly, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(duplicates))
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/utils"
+)
+
+// apiVersionKey — ключ контекста gin с версией API маршрута (см. withAPIVersion)
+const apiVersionKey = "api_version"
+
+// withAPIVersion помечает маршрут версией API. Обработчики общие для всех версий, а версия
+// выбирает только форму ответа.
+func withAPIVersion(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// apiVersion возвращает версию API маршрута; маршруты без пометки — v1
+func apiVersion(c *gin.Context) int {
+	if version, ok := c.Get(apiVersionKey); ok {
+		if v, ok := version.(int); ok {
+			return v
+		}
+	}
+	return 1
+}
+
+// Статусы обработки события в ответе v2
+const (
+	eventStatusProcessed = "processed"
+	eventStatusQueued    = "queued"
+)
+
+// eventResultV2 — ответ v2 на событие камеры
+type eventResultV2 struct {
+	EventID uuid.UUID `json:"event_id"`
+	// Status — processed (событие сохранено) или queued (INGEST_MODE=async, сохранение в очереди)
+	Status   string               `json:"status"`
+	Plate    eventPlateV2         `json:"plate"`
+	Decision *anpr.AccessDecision `json:"decision,omitempty"`
+	Vehicle  eventVehicleV2       `json:"vehicle"`
+	Lists    []anpr.ListHit       `json:"lists"`
+	Photos   []string             `json:"photos"`
+	// TripID — рейс, который событие открывает в отчётах; null, если событие не считается рейсом
+	TripID *uuid.UUID `json:"trip_id"`
+}
+
+// eventPlateV2 — распознанный номер; ID нет, пока событие в очереди
+type eventPlateV2 struct {
+	ID         *uuid.UUID `json:"id,omitempty"`
+	Normalized string     `json:"normalized"`
+}
+
+// eventVehicleV2 — сопоставление номера с транспортом подрядчика
+type eventVehicleV2 struct {
+	Matched      bool       `json:"matched"`
+	ContractorID *uuid.UUID `json:"contractor_id,omitempty"`
+}
+
+func newEventResultV2(result *anpr.ProcessResult) eventResultV2 {
+	plateID := result.PlateID
+	return eventResultV2{
+		EventID:  result.EventID,
+		Status:   eventStatusProcessed,
+		Plate:    eventPlateV2{ID: &plateID, Normalized: result.Plate},
+		Decision: result.Decision,
+		Vehicle:  eventVehicleV2{Matched: result.VehicleExists, ContractorID: result.ContractorID},
+		Lists:    nonNilSlice(result.Lists),
+		Photos:   nonNilSlice(result.PhotoURLs),
+		TripID:   result.TripID,
+	}
+}
+
+// respondEventCreated отвечает на сохранённое событие в формате версии маршрута. processed
+// добавляется в ответ v1 только эндпоинтом Hikvision.
+func respondEventCreated(c *gin.Context, result *anpr.ProcessResult, hikvision bool) {
+	if apiVersion(c) >= 2 {
+		c.JSON(http.StatusCreated, successResponse(newEventResultV2(result)))
+		return
+	}
+	var processed *bool
+	if hikvision {
+		value := true
+		processed = &value
+	}
+	c.JSON(http.StatusCreated, newEventCreatedResponse(result, processed))
+}
+
+// respondEventAccepted отвечает на событие, поставленное в очередь сохранения. Решение и
+// сопоставление ещё неизвестны, поэтому в v2 они пустые.
+func respondEventAccepted(c *gin.Context, eventID uuid.UUID, payload anpr.EventPayload, photoURLs []string) {
+	if apiVersion(c) >= 2 {
+		c.JSON(http.StatusAccepted, successResponse(eventResultV2{
+			EventID: eventID,
+			Status:  eventStatusQueued,
+			Plate:   eventPlateV2{Normalized: utils.NormalizePlate(payload.Plate)},
+			Lists:   []anpr.ListHit{},
+			Photos:  nonNilSlice(photoURLs),
+		}))
+		return
+	}
+	c.JSON(http.StatusAccepted, eventAcceptedResponse{
+		Status:    "accepted",
+		EventID:   eventID,
+		Plate:     payload.Plate,
+		Photos:    photoURLs,
+		Processed: false,
+	})
+}
+
+// nonNilSlice заменяет nil на пустой срез, чтобы в JSON был [] вместо null
+func nonNilSlice[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
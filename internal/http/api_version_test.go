@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestRespondEventCreatedByVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eventID := uuid.New()
+	contractorID := uuid.New()
+	result := &anpr.ProcessResult{
+		EventID:       eventID,
+		PlateID:       uuid.New(),
+		Plate:         "123ABC02",
+		VehicleExists: true,
+		PhotoURLs:     []string{"https://photos/1.jpg"},
+		Lists:         []anpr.ListHit{{ListName: "Подрядчики", ListType: "WHITELIST"}},
+		ContractorID:  &contractorID,
+		TripID:        &eventID,
+	}
+
+	tests := []struct {
+		name      string
+		version   int
+		hikvision bool
+		want      []string
+		wantNot   []string
+	}{
+		{name: "v1 keeps legacy shape", version: 1, want: []string{"status", "event_id", "plate_id", "hits", "vehicle_exists"}, wantNot: []string{"data", "processed"}},
+		{name: "v1 hikvision reports processed", version: 1, hikvision: true, want: []string{"processed"}},
+		{name: "v2 wraps result in data", version: 2, want: []string{"data"}, wantNot: []string{"hits", "status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			withAPIVersion(tt.version)(c)
+			respondEventCreated(c, result, tt.hikvision)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			for _, key := range tt.want {
+				if _, ok := body[key]; !ok {
+					t.Errorf("response has no %q: %s", key, w.Body.String())
+				}
+			}
+			for _, key := range tt.wantNot {
+				if _, ok := body[key]; ok {
+					t.Errorf("response has unexpected %q: %s", key, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNewEventResultV2(t *testing.T) {
+	eventID := uuid.New()
+	got := newEventResultV2(&anpr.ProcessResult{EventID: eventID, PlateID: uuid.New(), Plate: "123ABC02"})
+
+	if got.Status != eventStatusProcessed {
+		t.Errorf("Status = %q, want %q", got.Status, eventStatusProcessed)
+	}
+	if got.Lists == nil || got.Photos == nil {
+		t.Errorf("Lists and Photos must be empty slices, got %v and %v", got.Lists, got.Photos)
+	}
+	if got.TripID != nil {
+		t.Errorf("TripID = %v, want nil", got.TripID)
+	}
+	if got.Plate.ID == nil || got.Plate.Normalized != "123ABC02" {
+		t.Errorf("Plate = %+v", got.Plate)
+	}
+}
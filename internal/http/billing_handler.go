@@ -0,0 +1,63 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+// exportBillingStatement выгружает подписанную ведомость оплаты рейсов за месяц
+// GET /api/v1/billing/periods/:period/export
+func (h *Handler) exportBillingStatement(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	// Ведомость охватывает всех подрядчиков, поэтому доступна только Акимату и КГУ
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	data, filename, signature, err := h.anprService.ExportBillingStatement(c.Request.Context(), c.Param("period"), format)
+	if err != nil {
+		if errors.Is(err, service.ErrBillingNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, errorResponse(err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if strings.HasSuffix(filename, "."+service.BillingFormatJSON) {
+		contentType = "application/json"
+	}
+	c.Header(service.BillingHeaderSignature, signature)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// lockBillingPeriod блокирует ведомость месяца после выставления счёта
+// POST /api/v1/billing/periods/:period/lock
+func (h *Handler) lockBillingPeriod(c *gin.Context) {
+	statement, err := h.anprService.LockBillingPeriod(c.Request.Context(), c.Param("period"))
+	if err != nil {
+		if errors.Is(err, service.ErrBillingPeriodLocked) {
+			c.JSON(http.StatusConflict, errorResponse(err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(statement))
+}
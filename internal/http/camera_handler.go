@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+	"anpr-service/internal/storage"
+)
+
+// cameraUpdateRequest — тело изменения камеры в реестре; nil — не менять
+type cameraUpdateRequest struct {
+	Name             *string  `json:"name"`
+	TimeZone         *string  `json:"timezone"`
+	ClockAutoCorrect *bool    `json:"clock_auto_correct"`
+	ArmedSchedule    *string  `json:"armed_schedule"`
+	HTTPHost         *string  `json:"http_host"`
+	WhitelistSync    *bool    `json:"whitelist_sync"`
+	PolygonID        *string  `json:"polygon_id"`
+	Latitude         *float64 `json:"latitude"`
+	Longitude        *float64 `json:"longitude"`
+	// Направление, если камера его не присылает: entry/exit, режим вывода lane/alternate, полосы "1:entry,2:exit"
+	DefaultDirection   *string `json:"default_direction"`
+	DirectionInference *string `json:"direction_inference"`
+	LaneDirections     *string `json:"lane_directions"`
+}
+
+// cameraSnapshotResponse — сохранённый снимок камеры
+type cameraSnapshotResponse struct {
+	CameraID   string    `json:"camera_id"`
+	URL        string    `json:"url"`
+	CapturedAt time.Time `json:"captured_at"`
+	Size       int       `json:"size"`
+}
+
+func (h *Handler) listCameras(c *gin.Context) {
+	cameras, err := h.anprService.ListCameras(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(cameras))
+}
+
+// getCameraMap отдаёт камеры с координатами в формате GeoJSON (без обёртки data), чтобы карту
+// можно было подключить к эндпоинту напрямую
+func (h *Handler) getCameraMap(c *gin.Context) {
+	cameras, err := h.anprService.CameraMap(c.Request.Context(), h.anprService.Now())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	body, err := json.Marshal(cameras)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/geo+json", body)
+}
+
+func (h *Handler) updateCamera(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() && !principal.IsTechnicalOperator() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	var req cameraUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	camera, err := h.anprService.UpdateCamera(c.Request.Context(), c.Param("id"), service.UpdateCameraInput{
+		Name:               req.Name,
+		TimeZone:           req.TimeZone,
+		ClockAutoCorrect:   req.ClockAutoCorrect,
+		ArmedSchedule:      req.ArmedSchedule,
+		HTTPHost:           req.HTTPHost,
+		WhitelistSync:      req.WhitelistSync,
+		PolygonID:          req.PolygonID,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
+		DefaultDirection:   req.DefaultDirection,
+		DirectionInference: req.DirectionInference,
+		LaneDirections:     req.LaneDirections,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(camera))
+}
+
+// captureCameraSnapshot снимает текущий кадр камеры через ISAPI и сохраняет его в R2,
+// чтобы оператор мог проверить направление камеры без VPN до её сети
+func (h *Handler) captureCameraSnapshot(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() && !principal.IsTechnicalOperator() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	cameraID := strings.TrimSpace(c.Param("id"))
+	host, err := h.anprService.CameraHTTPHost(c.Request.Context(), cameraID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	camera := h.anprService.Config().Camera
+	client, err := hikvision.NewClient(host, camera.Username, camera.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	capturedAt := time.Now()
+	image, contentType, err := client.Snapshot(c.Request.Context(), c.DefaultQuery("channel", "101"))
+	if err != nil {
+		h.logger(c.Request.Context()).Warn().Err(err).Str("camera_id", cameraID).Msg("failed to capture camera snapshot")
+		c.JSON(http.StatusBadGateway, errorResponse("failed to capture snapshot from camera"))
+		return
+	}
+
+	ext := ".jpg"
+	if strings.Contains(contentType, "png") {
+		ext = ".png"
+	}
+	capturedKZ := capturedAt.In(time.FixedZone("KZ", 5*60*60))
+	key := fmt.Sprintf("camera_snapshots/%s/%s/%s%s",
+		sanitizePathSegment(cameraID, "unknown_camera"),
+		capturedKZ.Format("2006-01-02"),
+		capturedKZ.Format("15-04-05"),
+		ext,
+	)
+
+	url, err := h.photoStore.Upload(c.Request.Context(), key, bytes.NewReader(image), int64(len(image)), contentType)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, errorResponse("photo storage is not configured"))
+			return
+		}
+		h.logger(c.Request.Context()).Error().Err(err).Str("camera_id", cameraID).Msg("failed to upload camera snapshot")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return
+	}
+
+	h.logger(c.Request.Context()).Info().Str("camera_id", cameraID).Str("url", url).Msg("camera snapshot captured")
+
+	c.JSON(http.StatusOK, successResponse(cameraSnapshotResponse{
+		CameraID:   cameraID,
+		URL:        url,
+		CapturedAt: capturedAt,
+		Size:       len(image),
+	}))
+}
+
+// syncCameraWhitelist немедленно выгружает default_whitelist в бортовой список камеры
+func (h *Handler) syncCameraWhitelist(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() && !principal.IsKgu() && !principal.IsTechnicalOperator() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	result, err := h.anprService.SyncCameraWhitelist(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrCameraUnavailable) {
+			h.logger(c.Request.Context()).Warn().Err(err).Str("camera_id", c.Param("id")).Msg("failed to sync camera whitelist")
+			c.JSON(http.StatusBadGateway, errorResponse(err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
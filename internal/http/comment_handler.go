@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// eventCommentRequest — комментарий оператора к событию; автор берётся из токена
+type eventCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (h *Handler) listEventComments(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid event id"))
+		return
+	}
+
+	comments, err := h.anprService.ListEventComments(c.Request.Context(), eventID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(comments))
+}
+
+func (h *Handler) addEventComment(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid event id"))
+		return
+	}
+
+	var req eventCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	comment, err := h.anprService.AddEventComment(c.Request.Context(), eventID, req.Body)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(comment))
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/repository"
+)
+
+// notModified поддерживает условные запросы (If-None-Match, If-Modified-Since) по версиям данных scopes:
+// выставляет ETag, Last-Modified и X-Data-Version (если область одна) и, если у клиента актуальная версия,
+// отвечает 304 без тела. Возвращает true, если ответ уже отправлен и выборку данных делать не нужно.
+// Версия читается до выборки данных (см. setDataVersion). Ошибка чтения версии не ломает ответ —
+// заголовки не выставляются, и клиент получает полный ответ.
+func (h *Handler) notModified(c *gin.Context, scopes ...string) bool {
+	versions, err := h.anprService.DataVersions(c.Request.Context(), scopes...)
+	if err != nil {
+		h.logger(c.Request.Context()).Warn().Err(err).Strs("scopes", scopes).Msg("failed to get data versions")
+		return false
+	}
+	return writeConditional(c, versions)
+}
+
+// writeConditional выставляет заголовки версии данных и отвечает 304, если версия клиента совпадает с текущей
+func writeConditional(c *gin.Context, versions []repository.DataVersion) bool {
+	if len(versions) == 0 {
+		return false
+	}
+	etag := dataVersionETag(versions)
+	var lastModified time.Time
+	for _, v := range versions {
+		if v.UpdatedAt.After(lastModified) {
+			lastModified = v.UpdatedAt
+		}
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	// Ответ зависит от пользователя и должен перепроверяться при каждом запросе
+	c.Header("Cache-Control", "private, no-cache")
+	if len(versions) == 1 {
+		c.Header(DataVersionHeader, strconv.FormatInt(versions[0].Version, 10))
+	}
+
+	if requestModified(c.Request, etag, lastModified) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// dataVersionETag — слабый ETag из версий областей: W/"lists.42" или W/"plates.7-events.1530"
+func dataVersionETag(versions []repository.DataVersion) string {
+	parts := make([]string, 0, len(versions))
+	for _, v := range versions {
+		parts = append(parts, fmt.Sprintf("%s.%d", v.Scope, v.Version))
+	}
+	return `W/"` + strings.Join(parts, "-") + `"`
+}
+
+// requestModified сообщает, нужно ли отдавать клиенту полный ответ. If-None-Match сравнивается по слабому
+// правилу и важнее If-Modified-Since (RFC 9110, 13.2.2); If-Modified-Since точен до секунды, поэтому
+// клиентам лучше опираться на ETag.
+func requestModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return true
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return false
+			}
+		}
+		return true
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return true
+		}
+		return lastModified.Truncate(time.Second).After(since)
+	}
+	return true
+}
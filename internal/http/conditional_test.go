@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/repository"
+)
+
+func TestWriteConditional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	changed := time.Date(2025, 1, 15, 10, 30, 15, 500_000_000, time.UTC)
+	versions := []repository.DataVersion{
+		{Scope: repository.DataVersionScopePlates, Version: 7, UpdatedAt: changed.Add(-time.Hour)},
+		{Scope: repository.DataVersionScopeEvents, Version: 1530, UpdatedAt: changed},
+	}
+	const etag = `W/"plates.7-events.1530"`
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    int
+	}{
+		{name: "unconditional", method: http.MethodGet, want: http.StatusOK},
+		{name: "etag matches", method: http.MethodGet, headers: map[string]string{"If-None-Match": etag}, want: http.StatusNotModified},
+		{name: "strong etag matches weakly", method: http.MethodHead, headers: map[string]string{"If-None-Match": `"lists.1", "plates.7-events.1530"`}, want: http.StatusNotModified},
+		{name: "any etag", method: http.MethodGet, headers: map[string]string{"If-None-Match": "*"}, want: http.StatusNotModified},
+		{name: "stale etag", method: http.MethodGet, headers: map[string]string{"If-None-Match": `W/"plates.7-events.1529"`}, want: http.StatusOK},
+		{name: "etag wins over date", method: http.MethodGet, headers: map[string]string{"If-None-Match": `W/"plates.6-events.1530"`, "If-Modified-Since": "Wed, 15 Jan 2025 10:30:15 GMT"}, want: http.StatusOK},
+		{name: "not modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "Wed, 15 Jan 2025 10:30:15 GMT"}, want: http.StatusNotModified},
+		{name: "modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "Wed, 15 Jan 2025 10:30:14 GMT"}, want: http.StatusOK},
+		{name: "bad date", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "yesterday"}, want: http.StatusOK},
+		{name: "not a read", method: http.MethodPost, headers: map[string]string{"If-None-Match": etag}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tt.method, "/api/v1/plates?plate=123ABC02", nil)
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+
+			if !writeConditional(c, versions) {
+				c.Status(http.StatusOK)
+			}
+			c.Writer.WriteHeaderNow()
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != etag {
+				t.Fatalf("ETag = %q, want %q", got, etag)
+			}
+			if got := w.Header().Get("Last-Modified"); got != "Wed, 15 Jan 2025 10:30:15 GMT" {
+				t.Fatalf("Last-Modified = %q", got)
+			}
+			if w.Header().Get(DataVersionHeader) != "" {
+				t.Fatalf("%s is set for a response built from several scopes", DataVersionHeader)
+			}
+		})
+	}
+}
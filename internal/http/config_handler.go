@@ -0,0 +1,18 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getAdminConfig возвращает действующую конфигурацию сервиса со скрытыми секретами
+// GET /api/v1/admin/config
+func (h *Handler) getAdminConfig(c *gin.Context) {
+	info, err := h.anprService.AdminConfig(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(info))
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// listDeadLetters возвращает непринятые уведомления камер
+// GET /api/v1/admin/dead-letters?pending=true&limit=100&offset=0
+func (h *Handler) listDeadLetters(c *gin.Context) {
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+	pending := false
+	if raw := c.Query("pending"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid pending, use true or false"))
+			return
+		}
+		pending = parsed
+	}
+
+	letters, err := h.anprService.ListDeadLetters(c.Request.Context(), pending, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(letters))
+}
+
+// getDeadLetterPayload отдаёт тело непринятого уведомления как оно пришло
+func (h *Handler) getDeadLetterPayload(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid dead letter id"))
+		return
+	}
+
+	payload, contentType, err := h.anprService.GetDeadLetterPayload(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, payload)
+}
+
+// replayDeadLetter повторно обрабатывает непринятое уведомление
+func (h *Handler) replayDeadLetter(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid dead letter id"))
+		return
+	}
+
+	result, err := h.anprService.ReplayDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(result))
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/edge"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/service"
+)
+
+func TestApplyEdgeReceivedAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	forwarded := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		edgeToken string
+		headers   map[string]string
+		body      *time.Time
+		want      *time.Time
+	}{
+		{
+			name:      "edge forwarder with token",
+			edgeToken: "edge-secret",
+			headers:   map[string]string{edge.ReceivedAtHeader: "2025-01-15T20:00:00Z", edge.TokenHeader: "edge-secret"},
+			want:      &forwarded,
+		},
+		{
+			name:      "wrong token",
+			edgeToken: "edge-secret",
+			headers:   map[string]string{edge.ReceivedAtHeader: "2025-01-15T20:00:00Z", edge.TokenHeader: "guess"},
+		},
+		{
+			name:      "no token header",
+			edgeToken: "edge-secret",
+			headers:   map[string]string{edge.ReceivedAtHeader: "2025-01-15T20:00:00Z"},
+		},
+		{
+			// Без EDGE_TOKEN заголовку не верит никто
+			name:    "token not configured",
+			headers: map[string]string{edge.ReceivedAtHeader: "2025-01-15T20:00:00Z", edge.TokenHeader: ""},
+		},
+		{
+			// received_at в теле открытого маршрута время приёма не задаёт
+			name: "client received_at",
+			body: &forwarded,
+		},
+		{
+			name:      "relayed received_at from edge forwarder",
+			edgeToken: "edge-secret",
+			headers:   map[string]string{edge.TokenHeader: "edge-secret"},
+			body:      &forwarded,
+			want:      &forwarded,
+		},
+		{
+			name:      "time in the future",
+			edgeToken: "edge-secret",
+			headers:   map[string]string{edge.ReceivedAtHeader: "2025-01-15T23:00:00Z", edge.TokenHeader: "edge-secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Edge: config.EdgeConfig{Token: tt.edgeToken}}
+			h := &Handler{anprService: service.NewANPRService(nil, nil, cfg, zerolog.Nop(), clock.NewManual(now), idgen.Random())}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/anpr/events", nil)
+			for name, value := range tt.headers {
+				c.Request.Header.Set(name, value)
+			}
+
+			payload := anpr.EventPayload{ReceivedAt: tt.body}
+			h.applyEdgeReceivedAt(c, &payload)
+			if (payload.ReceivedAt == nil) != (tt.want == nil) || (tt.want != nil && !payload.ReceivedAt.Equal(*tt.want)) {
+				t.Errorf("received_at = %v, want %v", payload.ReceivedAt, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// getTripEvidence выгружает ZIP-пакет доказательств рейса для разбора споров
+// GET /api/v1/trips/:id/evidence
+func (h *Handler) getTripEvidence(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid trip id"))
+		return
+	}
+
+	evidence, err := h.anprService.GetTripEvidence(c.Request.Context(), tripID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("X-Evidence-Cached", strconv.FormatBool(evidence.Cached))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"trip-%s-evidence.zip\"", tripID))
+	c.Data(http.StatusOK, "application/zip", evidence.Body)
+}
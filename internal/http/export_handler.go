@@ -0,0 +1,113 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) exportAnonymizedDataset(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	// Обезличенный набор охватывает всех подрядчиков, поэтому доступен только Акимату и КГУ
+	if !principal.IsAkimat() && !principal.IsKgu() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	filters, ok := parseExportFilters(c, principal)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	data, filename, err := h.anprService.ExportAnonymizedDataset(c.Request.Context(), filters, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrExportNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, errorResponse(err.Error()))
+		case errors.Is(err, service.ErrTooManyRows):
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		default:
+			h.handleError(c, err)
+		}
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if strings.HasSuffix(filename, "."+service.AnonymizedFormatJSON) {
+		contentType = "application/json"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// eventStreamFlushRows — через сколько строк потоковая выгрузка отправляется клиенту
+const eventStreamFlushRows = 500
+
+// streamEvents отдаёт события за период построчно в NDJSON. Ответ пишется по мере чтения из БД, поэтому
+// ошибка после первой строки уже не меняет статус: последней строкой уходит {"error": "..."}.
+// GET /api/v1/events/stream.ndjson
+func (h *Handler) streamEvents(c *gin.Context) {
+	query := service.EventStreamQuery{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+		EventFilters: service.EventFilters{
+			Plate:       c.Query("plate"),
+			Direction:   c.Query("direction"),
+			VehicleType: c.Query("vehicle_type"),
+			Source:      c.Query("source"),
+			PolygonID:   c.Query("polygon_id"),
+			TimeField:   c.Query("time_field"),
+		},
+	}
+
+	buf := bufio.NewWriter(c.Writer)
+	enc := json.NewEncoder(buf)
+	rows := 0
+	start := func() {
+		c.Header("Content-Type", "application/x-ndjson")
+		// Прокси не должен копить поток целиком
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+	}
+	err := h.anprService.StreamEvents(c.Request.Context(), query, func(event service.DataLakeEvent) error {
+		if rows == 0 {
+			start()
+		}
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+		rows++
+		if rows%eventStreamFlushRows == 0 {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && rows == 0:
+		h.handleError(c, err)
+		return
+	case err != nil:
+		h.logger(c.Request.Context()).Error().Err(err).Int("rows", rows).Msg("event stream interrupted")
+		_ = enc.Encode(errorResponse("event stream interrupted"))
+	case rows == 0:
+		start()
+	}
+	_ = buf.Flush()
+	c.Writer.Flush()
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+// featureFlagOverrideRequest — переопределение флага для всех (global), организации или камеры
+type featureFlagOverrideRequest struct {
+	Scope   string `json:"scope" binding:"required"`
+	ScopeID string `json:"scope_id"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Reason  string `json:"reason"`
+}
+
+func (h *Handler) listFeatureFlags(c *gin.Context) {
+	flags, err := h.anprService.ListFeatureFlags(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(flags))
+}
+
+func (h *Handler) setFeatureFlagOverride(c *gin.Context) {
+	var req featureFlagOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	flag, err := h.anprService.SetFeatureFlagOverride(c.Request.Context(), c.Param("flag"), service.FeatureFlagOverrideInput{
+		Scope:   req.Scope,
+		ScopeID: req.ScopeID,
+		Enabled: req.Enabled,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(flag))
+}
+
+// DELETE /api/v1/admin/feature-flags/:flag?scope=camera&scope_id=gate-1
+func (h *Handler) deleteFeatureFlagOverride(c *gin.Context) {
+	flag, err := h.anprService.DeleteFeatureFlagOverride(c.Request.Context(), c.Param("flag"), c.Query("scope"), c.Query("scope_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(flag))
+}
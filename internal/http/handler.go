@@ -1,13 +1,11 @@
 package http
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"encoding/xml"
+	"crypto/subtle"
 	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -20,7 +18,13 @@ import (
 
 	"anpr-service/internal/config"
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/edge"
 	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/imaging"
+	"anpr-service/internal/leader"
+	"anpr-service/internal/logctx"
+	"anpr-service/internal/model"
+	"anpr-service/internal/photohash"
 	"anpr-service/internal/repository"
 	"anpr-service/internal/service"
 	"anpr-service/internal/storage"
@@ -31,47 +35,172 @@ type Handler struct {
 	anprService *service.ANPRService
 	config      *config.Config
 	log         zerolog.Logger
-	r2Client    *storage.R2Client
+	photoStore  *storage.FailoverStore
+	maintenance *middleware.MaintenanceMode
+	// ipLimiter / cameraLimiter ограничивают поток событий от неисправной или неверно настроенной камеры
+	ipLimiter     *middleware.RateLimiter
+	cameraLimiter *middleware.RateLimiter
+	// ingestQueue — очередь асинхронного сохранения событий (nil при INGEST_MODE=sync)
+	ingestQueue *service.IngestQueue
+	// leader — выбор реплики для периодических задач (nil — выключен)
+	leader *leader.Elector
 }
 
 func NewHandler(
 	anprService *service.ANPRService,
 	cfg *config.Config,
 	log zerolog.Logger,
-	r2Client *storage.R2Client,
+	photoStore *storage.FailoverStore,
+	ingestQueue *service.IngestQueue,
+	elector *leader.Elector,
 ) *Handler {
 	return &Handler{
-		anprService: anprService,
-		config:      cfg,
-		log:         log,
-		r2Client:    r2Client,
+		anprService:   anprService,
+		config:        cfg,
+		log:           log,
+		photoStore:    photoStore,
+		maintenance:   middleware.NewMaintenanceMode(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfter),
+		ipLimiter:     middleware.NewRateLimiter(cfg.Ingest.RateLimitIPPerMinute, cfg.Ingest.RateLimitIPBurst),
+		cameraLimiter: middleware.NewRateLimiter(cfg.Ingest.RateLimitCameraPerMinute, cfg.Ingest.RateLimitCameraBurst),
+		ingestQueue:   ingestQueue,
+		leader:        elector,
 	}
 }
 
+// logger возвращает логгер запроса с request_id (см. middleware.RequestID)
+func (h *Handler) logger(ctx context.Context) *zerolog.Logger {
+	return logctx.From(ctx, &h.log)
+}
+
 func (h *Handler) Register(r *gin.Engine, authMiddleware gin.HandlerFunc) {
 	// Public endpoints
 	public := r.Group("/api/v1")
+	ingestLimits := []gin.HandlerFunc{
+		middleware.RateLimitByIP(h.ipLimiter),
+		middleware.MaxBodySize(h.config.Ingest.MaxBodyBytes),
+	}
 	{
-		public.POST("/anpr/events", h.createANPREvent)
-		public.POST("/anpr/hikvision", h.createHikvisionEvent)
+		public.POST("/anpr/events", append(ingestLimits, h.ingestEvent(genericRoute))...)
+		public.POST("/anpr/hikvision", append(ingestLimits, h.ingestEvent(hikvisionRoute))...)
 		public.GET("/anpr/hikvision", h.checkHikvisionEndpoint) // Для проверки доступности камерой
 		public.GET("/camera/status", h.checkCameraStatus)
+		public.GET("/photos/*key", h.getStoredPhoto)
+		public.GET("/openapi.json", h.getOpenAPISpec)
+		if h.config.Environment != "production" {
+			public.GET("/docs", h.getSwaggerUI)
+		}
+	}
+
+	// v2 отличается от v1 только ответом на событие камеры (см. eventResultV2). Маршруты v1
+	// заморожены: новые поля ответа добавляются только в v2.
+	v2 := r.Group("/api/v2")
+	v2.Use(withAPIVersion(2))
+	{
+		v2.POST("/anpr/events", append(ingestLimits, h.ingestEvent(genericRoute))...)
+		v2.POST("/anpr/hikvision", append(ingestLimits, h.ingestEvent(hikvisionRoute))...)
+		v2.GET("/anpr/hikvision", h.checkHikvisionEndpoint)
 	}
 
 	// Protected endpoints
 	protected := r.Group("/api/v1")
-	protected.Use(authMiddleware)
+	protected.Use(authMiddleware, h.meterAPICall)
 	{
 		protected.GET("/plates", h.listPlates)
+		protected.GET("/plates/unmatched", h.listUnmatchedPlates)
+		protected.GET("/plates/trip-quotas", h.listPlateTripQuotas)
+		protected.PUT("/plates/trip-quotas/:plate", h.setPlateTripQuota)
+		protected.DELETE("/plates/trip-quotas/:plate", h.deletePlateTripQuota)
+		protected.POST("/plates/unmatched/:id/review", h.requireAdmin, h.reviewUnmatchedPlate)
+		protected.GET("/plates/:id/timeline", h.getPlateTimeline)
+		protected.POST("/plates/:id/merge", h.requireAdmin, h.mergePlates)
+		protected.GET("/plates/:id/aliases", h.requireAdmin, h.listPlateAliases)
+		protected.POST("/plates/:id/aliases", h.requireAdmin, h.createPlateAlias)
+		protected.DELETE("/plates/:id/aliases/:alias", h.requireAdmin, h.deletePlateAlias)
 		protected.GET("/events", h.listEvents)
+		protected.HEAD("/events", h.headDataVersion(repository.DataVersionScopeEvents))
+		protected.GET("/events/stream.ndjson", h.streamEvents)
 		protected.GET("/events/:id", h.getEvent)
+		protected.GET("/events/:id/comments", h.listEventComments)
+		protected.POST("/events/:id/comments", h.addEventComment)
+		protected.GET("/trips/:id/evidence", h.getTripEvidence)
 		protected.POST("/anpr/sync-vehicle", h.syncVehicleToWhitelist)
+		protected.DELETE("/anpr/sync-vehicle", h.removeVehicleFromWhitelist)
 		protected.DELETE("/anpr/events/old", h.deleteOldEvents)
 		protected.DELETE("/anpr/events/all", h.deleteAllEvents)
 		protected.GET("/reports", h.getReports)
 		protected.GET("/reports/hourly-activity", h.getReportsHourlyActivity)
 		protected.GET("/reports/comparison", h.getReportsComparison)
+		protected.GET("/reports/shifts", h.getShiftReport)
+		protected.GET("/reports/speed", h.getSpeedReport)
+		protected.GET("/reports/lanes", h.getLaneUsage)
+		protected.GET("/reports/confidence", h.getConfidenceReport)
 		protected.GET("/reports/excel", h.exportReportsExcel)
+		protected.GET("/stats/hourly", h.getHourlyStats)
+		protected.GET("/reports/anonymized", h.exportAnonymizedDataset)
+		protected.GET("/anomalies/photo-duplicates", h.listPhotoDuplicates)
+		protected.GET("/billing/periods/:period/export", h.exportBillingStatement)
+		protected.POST("/billing/periods/:period/lock", h.lockBillingPeriod)
+		protected.GET("/lists", h.listLists)
+		protected.HEAD("/lists", h.headDataVersion(repository.DataVersionScopeLists))
+		protected.GET("/lists/:id/items", h.listListEntries)
+		protected.POST("/lists/:id/items", h.addListEntry)
+		protected.PUT("/lists/:id/items/:plate_id", h.setListEntryValidity)
+		protected.DELETE("/lists/:id/items/:plate_id", h.removeListEntry)
+		protected.GET("/lists/:id/export", h.exportListFile)
+		protected.POST("/lists/:id/import", h.importListFile)
+		protected.GET("/polygons", h.listPolygons)
+		protected.POST("/polygons", h.requireAdmin, h.createPolygon)
+		protected.PUT("/polygons/:id", h.requireAdmin, h.updatePolygon)
+		protected.DELETE("/polygons/:id", h.requireAdmin, h.deletePolygon)
+		protected.GET("/polygons/modes", h.listPolygonModes)
+		protected.PUT("/polygons/:id/mode", h.setPolygonMode)
+		protected.GET("/polygons/:id/mode/history", h.listPolygonModeHistory)
+		protected.GET("/shifts", h.listShifts)
+		protected.POST("/shifts", h.requireAdmin, h.createShift)
+		protected.PUT("/shifts/:id", h.requireAdmin, h.updateShift)
+		protected.DELETE("/shifts/:id", h.requireAdmin, h.deleteShift)
+		protected.GET("/cameras", h.listCameras)
+		protected.GET("/cameras/geojson", h.getCameraMap)
+		protected.PUT("/cameras/:id", h.updateCamera)
+		protected.POST("/cameras/:id/snapshot", h.captureCameraSnapshot)
+		protected.POST("/cameras/:id/whitelist/sync", h.syncCameraWhitelist)
+		protected.GET("/contractors/access-rules", h.listContractorAccessRules)
+		protected.PUT("/contractors/:id/access-rules", h.updateContractorAccessRule)
+		protected.GET("/contractors/polygons", h.listContractorPolygons)
+		protected.PUT("/contractors/:id/polygons", h.setContractorPolygons)
+		protected.GET("/admin/summary", h.requireAdmin, h.getAdminSummary)
+		protected.GET("/admin/maintenance", h.getMaintenance)
+		protected.PUT("/admin/maintenance", h.requireAdmin, h.setMaintenance)
+		protected.POST("/admin/events/:id/restore", h.requireAdmin, h.restoreEvent)
+		protected.POST("/admin/events/reprocess", h.requireAdmin, h.reprocessEvents)
+		protected.GET("/admin/config", h.requireAdmin, h.getAdminConfig)
+		protected.GET("/admin/jobs", h.requireAdmin, h.listJobs)
+		protected.GET("/admin/jobs/:name/runs", h.requireAdmin, h.listJobRuns)
+		protected.POST("/admin/jobs/:name/run", h.requireAdmin, h.triggerJob)
+		protected.GET("/admin/usage", h.requireAdmin, h.getUsage)
+		protected.GET("/admin/slo", h.requireAdmin, h.getIngestSLO)
+		protected.GET("/admin/feature-flags", h.requireAdmin, h.listFeatureFlags)
+		protected.PUT("/admin/feature-flags/:flag", h.requireAdmin, h.setFeatureFlagOverride)
+		protected.DELETE("/admin/feature-flags/:flag", h.requireAdmin, h.deleteFeatureFlagOverride)
+		protected.GET("/admin/dead-letters", h.requireAdmin, h.listDeadLetters)
+		protected.GET("/admin/dead-letters/:id/payload", h.requireAdmin, h.getDeadLetterPayload)
+		protected.POST("/admin/dead-letters/:id/replay", h.requireAdmin, h.replayDeadLetter)
+		protected.GET("/notifications/nightly-summary", h.getSummarySubscription)
+		protected.PUT("/notifications/nightly-summary", h.updateSummarySubscription)
+		protected.GET("/watches", h.listPlateWatches)
+		protected.POST("/watches", h.createPlateWatch)
+		protected.DELETE("/watches/:id", h.deletePlateWatch)
+		protected.GET("/saved-searches", h.listSavedSearches)
+		protected.POST("/saved-searches", h.createSavedSearch)
+		protected.PUT("/saved-searches/:id", h.updateSavedSearch)
+		protected.DELETE("/saved-searches/:id", h.deleteSavedSearch)
+		protected.GET("/saved-searches/:id/export", h.exportSavedSearch)
+		protected.GET("/webhooks", h.requireAdmin, h.listWebhooks)
+		protected.POST("/webhooks", h.requireAdmin, h.createWebhook)
+		protected.PUT("/webhooks/:id", h.requireAdmin, h.updateWebhook)
+		protected.DELETE("/webhooks/:id", h.requireAdmin, h.deleteWebhook)
+		protected.GET("/webhooks/:id/deliveries", h.requireAdmin, h.listWebhookDeliveries)
+		protected.POST("/webhooks/:id/deliveries/:delivery_id/retry", h.requireAdmin, h.retryWebhookDelivery)
 	}
 
 	// Internal endpoints (для межсервисного взаимодействия)
@@ -79,335 +208,84 @@ func (h *Handler) Register(r *gin.Engine, authMiddleware gin.HandlerFunc) {
 	internal.Use(middleware.InternalToken(h.config.Auth.InternalToken))
 	{
 		internal.GET("/anpr/events", h.getInternalEvents)
+		internal.GET("/maintenance", h.getMaintenance)
+		internal.PUT("/maintenance", h.setMaintenance)
+		internal.GET("/metrics", h.getMetrics)
 	}
 }
 
-func (h *Handler) createANPREvent(c *gin.Context) {
-	// Parse multipart form (max 50MB for photos)
-	if err := c.Request.ParseMultipartForm(50 << 20); err != nil {
-		// If not multipart, try JSON (backward compatibility)
-		var payload anpr.EventPayload
-		if err := c.ShouldBindJSON(&payload); err != nil {
-			c.JSON(http.StatusBadRequest, errorResponse("failed to parse request: "+err.Error()))
-			return
-		}
-
-		if payload.EventTime.IsZero() {
-			payload.EventTime = time.Now()
-		}
-
-		// Generate event ID upfront
-		eventID := uuid.New()
-
-		h.log.Info().
-			Str("plate", payload.Plate).
-			Str("camera_id", payload.CameraID).
-			Msg("processing ANPR event (JSON)")
-
-		result, err := h.anprService.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model, eventID, nil)
-		if err != nil {
-			if errors.Is(err, service.ErrInvalidInput) {
-				h.log.Warn().
-					Err(err).
-					Str("plate", payload.Plate).
-					Str("camera_id", payload.CameraID).
-					Msg("invalid input for ANPR event")
-				c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-				return
-			}
-			if errors.Is(err, service.ErrDuplicateEvent) {
-				h.log.Warn().
-					Err(err).
-					Str("plate", payload.Plate).
-					Str("camera_id", payload.CameraID).
-					Msg("duplicate event within 5 minutes, skipping save")
-				c.JSON(http.StatusConflict, errorResponse(err.Error()))
-				return
-			}
-			if errors.Is(err, service.ErrVehicleNotWhitelisted) {
-				h.log.Warn().
-					Err(err).
-					Str("plate", payload.Plate).
-					Str("camera_id", payload.CameraID).
-					Msg("vehicle not in whitelist (vehicles table)")
-				c.JSON(http.StatusForbidden, errorResponse(err.Error()))
-				return
-			}
-			h.log.Error().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("failed to process ANPR event")
-			c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
-			return
-		}
-
-		h.log.Info().
-			Str("event_id", result.EventID.String()).
-			Str("plate_id", result.PlateID.String()).
-			Str("plate", result.Plate).
-			Int("hits_count", len(result.Hits)).
-			Msg("successfully processed and saved ANPR event")
-
-		c.JSON(http.StatusCreated, gin.H{
-			"status":         "ok",
-			"event_id":       result.EventID,
-			"plate_id":       result.PlateID,
-			"plate":          result.Plate,
-			"vehicle_exists": result.VehicleExists,
-			"hits":           result.Hits,
-			"photos":         result.PhotoURLs,
-		})
-		return
-	}
-
-	// Handle multipart form data
-	eventJSON := c.PostForm("event")
-	if eventJSON == "" {
-		c.JSON(http.StatusBadRequest, errorResponse("event field is required"))
-		return
-	}
-
-	// Сначала парсим в map, чтобы сохранить все дополнительные поля
-	var eventMap map[string]interface{}
-	if err := json.Unmarshal([]byte(eventJSON), &eventMap); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse("invalid event JSON: "+err.Error()))
-		return
-	}
-
-	// Извлекаем известные поля для EventPayload
-	var payload anpr.EventPayload
-	payloadBytes, _ := json.Marshal(eventMap)
-	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse("invalid event JSON: "+err.Error()))
-		return
-	}
-
-	// ИЗВЛЕКАЕМ СНЕГОВЫЕ ПОЛЯ НАПРЯМУЮ ИЗ eventMap (Go не парсит строку времени в *time.Time автоматически)
-	// Делаем это ДО того, как поля будут исключены из RawPayload
-	if payload.SnowVolumePercentage == nil {
-		// Пробуем разные типы: float64, int, float32
-		var snowVolumePct float64
-		var ok bool
-		if snowVolumePct, ok = eventMap["snow_volume_percentage"].(float64); !ok {
-			if pctInt, okInt := eventMap["snow_volume_percentage"].(int); okInt {
-				snowVolumePct = float64(pctInt)
-				ok = true
-			} else if pctFloat32, okFloat32 := eventMap["snow_volume_percentage"].(float32); okFloat32 {
-				snowVolumePct = float64(pctFloat32)
-				ok = true
-			}
-		}
-		if ok {
-			payload.SnowVolumePercentage = &snowVolumePct
-			h.log.Info().Float64("snow_volume_percentage", snowVolumePct).Msg("extracted snow_volume_percentage from eventMap")
-		} else {
-			// Значение по умолчанию: 0.0 если снег не обнаружен
-			defaultVolume := 0.0
-			payload.SnowVolumePercentage = &defaultVolume
-			h.log.Warn().Interface("snow_volume_percentage_type", eventMap["snow_volume_percentage"]).Msg("snow_volume_percentage not found or wrong type, using default 0.0")
-		}
-	}
-	if payload.SnowVolumeConfidence == nil {
-		// Пробуем разные типы: float64, int, float32
-		var snowVolumeConf float64
-		var ok bool
-		if snowVolumeConf, ok = eventMap["snow_volume_confidence"].(float64); !ok {
-			if confInt, okInt := eventMap["snow_volume_confidence"].(int); okInt {
-				snowVolumeConf = float64(confInt)
-				ok = true
-			} else if confFloat32, okFloat32 := eventMap["snow_volume_confidence"].(float32); okFloat32 {
-				snowVolumeConf = float64(confFloat32)
-				ok = true
-			}
-		}
-		if ok {
-			payload.SnowVolumeConfidence = &snowVolumeConf
-			h.log.Info().Float64("snow_volume_confidence", snowVolumeConf).Msg("extracted snow_volume_confidence from eventMap")
-		} else {
-			// Значение по умолчанию: 0.0 если снег не обнаружен
-			defaultConfidence := 0.0
-			payload.SnowVolumeConfidence = &defaultConfidence
-			h.log.Warn().Interface("snow_volume_confidence_type", eventMap["snow_volume_confidence"]).Msg("snow_volume_confidence not found or wrong type, using default 0.0")
-		}
-	}
-	if !payload.MatchedSnow {
-		if matchedSnow, ok := eventMap["matched_snow"].(bool); ok {
-			payload.MatchedSnow = matchedSnow
-			h.log.Info().Bool("matched_snow", matchedSnow).Msg("extracted matched_snow from eventMap")
-		} else {
-			// Значение по умолчанию: false если поле не пришло
-			payload.MatchedSnow = false
-		}
-	}
-
-	// Сохраняем все дополнительные поля в RawPayload
-	// (поля, которых нет в структуре EventPayload)
-	if payload.RawPayload == nil {
-		payload.RawPayload = make(map[string]interface{})
-	}
-
-	// Известные поля EventPayload, которые не нужно дублировать в RawPayload
-	knownFields := map[string]bool{
-		"camera_id": true, "camera_model": true, "plate": true, "confidence": true,
-		"direction": true, "lane": true, "event_time": true, "vehicle": true,
-		"snapshot_url": true, "raw_payload": true,
-		"snow_volume_percentage": true,
-		"snow_volume_confidence": true, "snow_volume_m3": true, "matched_snow": true,
-	}
-
-	// Добавляем неизвестные поля в RawPayload
-	for key, value := range eventMap {
-		if !knownFields[key] && value != nil {
-			payload.RawPayload[key] = value
-		}
-	}
-
-	if payload.EventTime.IsZero() {
-		payload.EventTime = time.Now()
-	}
-
-	// Generate event ID upfront so we can organize photos by event
-	eventID := uuid.New()
-
-	// Get photos from form
-	form, err := c.MultipartForm()
-	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse("failed to parse multipart form"))
-		return
-	}
+// eventCreatedResponse — ответ камере на сохранённое событие. processed передаётся только
+// эндпоинтом Hikvision.
+type eventCreatedResponse struct {
+	Status        string         `json:"status"`
+	EventID       uuid.UUID      `json:"event_id"`
+	PlateID       uuid.UUID      `json:"plate_id"`
+	Plate         string         `json:"plate"`
+	VehicleExists bool           `json:"vehicle_exists"`
+	Hits          []anpr.ListHit `json:"hits"`
+	Photos        []string       `json:"photos"`
+	Processed     *bool          `json:"processed,omitempty"`
+}
 
-	photoFiles := form.File["photos"]
-	var photoURLs []string
-
-	// Upload photos organized by date, camera_id, time and plate
-	if h.r2Client != nil && len(photoFiles) > 0 {
-		for i, fileHeader := range photoFiles {
-			url, err := h.uploadEventPhoto(c.Request.Context(), fileHeader, eventID, payload.EventTime, payload.CameraID, payload.Plate, i)
-			if err != nil {
-				h.log.Warn().
-					Err(err).
-					Str("filename", fileHeader.Filename).
-					Str("event_id", eventID.String()).
-					Msg("failed to upload photo")
-				continue
-			}
-			photoURLs = append(photoURLs, url)
-		}
-	} else if len(photoFiles) > 0 && h.r2Client == nil {
-		h.log.Warn().
-			Int("photos_count", len(photoFiles)).
-			Msg("photos provided but R2 storage not configured, skipping photo upload")
+func newEventCreatedResponse(result *anpr.ProcessResult, processed *bool) eventCreatedResponse {
+	return eventCreatedResponse{
+		Status:        "ok",
+		EventID:       result.EventID,
+		PlateID:       result.PlateID,
+		Plate:         result.Plate,
+		VehicleExists: result.VehicleExists,
+		Hits:          result.Hits,
+		Photos:        result.PhotoURLs,
+		Processed:     processed,
 	}
+}
 
-	h.log.Info().
-		Str("plate", payload.Plate).
-		Str("camera_id", payload.CameraID).
-		Int("photos_count", len(photoURLs)).
-		Msg("processing ANPR event with photos")
-
-	result, err := h.anprService.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model, eventID, photoURLs)
-	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("invalid input for ANPR event")
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-			return
-		}
-		if errors.Is(err, service.ErrDuplicateEvent) {
-			h.log.Warn().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("duplicate event within 5 minutes, skipping save")
-			c.JSON(http.StatusConflict, errorResponse(err.Error()))
-			return
-		}
-		if errors.Is(err, service.ErrVehicleNotWhitelisted) {
-			h.log.Warn().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("vehicle not in whitelist (vehicles table)")
-			c.JSON(http.StatusForbidden, errorResponse(err.Error()))
-			return
-		}
-		h.log.Error().
-			Err(err).
-			Str("plate", payload.Plate).
-			Str("camera_id", payload.CameraID).
-			Msg("failed to process ANPR event")
-		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
-		return
-	}
+// eventAcceptedResponse — ответ камере на событие, поставленное в очередь сохранения (INGEST_MODE=async)
+type eventAcceptedResponse struct {
+	Status    string    `json:"status"`
+	EventID   uuid.UUID `json:"event_id"`
+	Plate     string    `json:"plate"`
+	Photos    []string  `json:"photos"`
+	Processed bool      `json:"processed"`
+}
 
-	h.log.Info().
-		Str("event_id", result.EventID.String()).
-		Str("plate_id", result.PlateID.String()).
-		Str("plate", result.Plate).
-		Int("hits_count", len(result.Hits)).
-		Int("photos_count", len(photoURLs)).
-		Msg("successfully processed and saved ANPR event")
-
-	c.JSON(http.StatusCreated, gin.H{
-		"status":         "ok",
-		"event_id":       result.EventID,
-		"plate_id":       result.PlateID,
-		"plate":          result.Plate,
-		"vehicle_exists": result.VehicleExists,
-		"hits":           result.Hits,
-		"photos":         result.PhotoURLs,
-	})
+// uploadedPhoto — загруженное фото события: ссылка на оригинал, хеши исходного файла и копии
+type uploadedPhoto struct {
+	URL string
+	// Keys — ключи всех загруженных объектов (оригинал и копии), чтобы удалить их, если событие не принято
+	Keys []string
+	Hash photohash.Hash
+	// Bytes — сколько байт загружено в хранилище вместе с копиями
+	Bytes int64
+	// Rendition — копии и размер оригинала; nil, если фото загружено без обработки
+	Rendition *service.PhotoRenditionInput
 }
 
-func (h *Handler) uploadEventPhoto(
+// maxPhotoSize — наибольший размер снимка события
+const maxPhotoSize = 10 << 20 // 10MB
+
+// storeEventPhoto загружает снимок события в хранилище фото вместе с уменьшенными копиями
+func (h *Handler) storeEventPhoto(
 	ctx context.Context,
-	fileHeader *multipart.FileHeader,
+	data []byte,
+	contentType string,
+	filename string,
 	eventID uuid.UUID,
 	eventTime time.Time,
 	cameraID string,
 	plateNumber string,
 	index int,
-) (string, error) {
-	const maxPhotoSize = 10 << 20 // 10MB
-	if fileHeader.Size > maxPhotoSize {
-		return "", errors.New("photo too large, max 10MB")
-	}
-
-	if fileHeader.Size <= 0 {
-		return "", errors.New("photo is empty")
+) (*uploadedPhoto, error) {
+	if len(data) > maxPhotoSize {
+		return nil, errors.New("photo too large, max 10MB")
 	}
-
-	// Open file once - we'll use it for both content type detection and upload
-	file, err := fileHeader.Open()
-	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+	if len(data) == 0 {
+		return nil, errors.New("photo is empty")
 	}
-	defer file.Close()
 
 	// Validate content type
-	contentType := fileHeader.Header.Get("Content-Type")
 	if contentType == "" {
-		// Try to detect from file
-		buf := make([]byte, 512)
-		if n, _ := file.Read(buf); n > 0 {
-			contentType = http.DetectContentType(buf[:n])
-		}
-		// Reset file position to beginning for upload
-		if seeker, ok := file.(io.Seeker); ok {
-			seeker.Seek(0, io.SeekStart)
-		} else {
-			// If file doesn't support seeking, we need to reopen it
-			file.Close()
-			file, err = fileHeader.Open()
-			if err != nil {
-				return "", fmt.Errorf("failed to reopen file: %w", err)
-			}
-			defer file.Close()
-		}
+		contentType = http.DetectContentType(data)
 	}
 
 	if contentType == "" {
@@ -415,11 +293,11 @@ func (h *Handler) uploadEventPhoto(
 	}
 
 	if !strings.HasPrefix(contentType, "image/") {
-		return "", errors.New("file must be an image")
+		return nil, errors.New("file must be an image")
 	}
 
 	// Determine file extension
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	ext := strings.ToLower(filepath.Ext(filename))
 	if ext == "" {
 		// Default based on content type
 		if strings.Contains(contentType, "jpeg") || strings.Contains(contentType, "jpg") {
@@ -443,16 +321,68 @@ func (h *Handler) uploadEventPhoto(
 
 	// Organize photos by date, camera, time and plate:
 	// anpr_events/{YYYY-MM-DD}/{camera_id}/{HH-MM-SS}-{plate}/{event_id}-photo-{index}{ext}
-	key := fmt.Sprintf("anpr_events/%s/%s/%s-%s/%s-photo-%d%s",
-		dateStr, cameraPath, timeStr, platePath, eventID.String(), index, ext)
+	base := fmt.Sprintf("anpr_events/%s/%s/%s-%s/%s-photo-%d",
+		dateStr, cameraPath, timeStr, platePath, eventID.String(), index)
+	photo := &uploadedPhoto{Hash: photohash.Compute(data)}
+
+	// Оригинал без EXIF (перекодированный в JPEG) и копии рядом с ним: {base}.jpg, {base}-thumbnail.jpg,
+	// {base}-medium.jpg. Файл, который не удалось декодировать, загружается как есть.
+	body, size := data, int64(len(data))
+	var processed *imaging.Result
+	var err error
+	if h.config.Ingest.PhotoProcessing {
+		processed, err = imaging.Process(data)
+		if err != nil {
+			h.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to process photo, uploading original")
+		} else {
+			body, size, ext, contentType = processed.Original, int64(len(processed.Original)), ".jpg", "image/jpeg"
+		}
+	}
 
-	// Upload to R2
-	url, err := h.r2Client.Upload(ctx, key, file, fileHeader.Size, contentType)
+	photo.URL, err = h.photoStore.Upload(ctx, base+ext, bytes.NewReader(body), size, contentType)
 	if err != nil {
-		return "", fmt.Errorf("r2 upload failed: %w", err)
+		return nil, fmt.Errorf("photo upload failed: %w", err)
+	}
+	photo.Keys = append(photo.Keys, base+ext)
+	photo.Bytes = size
+	if processed == nil {
+		return photo, nil
+	}
+
+	photo.Rendition = &service.PhotoRenditionInput{Width: processed.Width, Height: processed.Height, URLs: map[string]string{}}
+	for name, rendition := range processed.Renditions {
+		url, err := h.photoStore.Upload(ctx, base+"-"+name+".jpg", bytes.NewReader(rendition), int64(len(rendition)), "image/jpeg")
+		if err != nil {
+			h.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Str("rendition", name).Msg("failed to upload photo rendition")
+			continue
+		}
+		photo.Rendition.URLs[name] = url
+		photo.Keys = append(photo.Keys, base+"-"+name+".jpg")
+		photo.Bytes += int64(len(rendition))
+	}
+	return photo, nil
+}
+
+// registerEventPhoto сохраняет копии и хеш загруженного снимка; ошибки только логируются
+func (h *Handler) registerEventPhoto(ctx context.Context, photo *uploadedPhoto, eventID uuid.UUID, payload anpr.EventPayload) {
+	if photo.Rendition != nil {
+		if err := h.anprService.SavePhotoRendition(ctx, photo.URL, *photo.Rendition); err != nil {
+			h.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to save photo renditions")
+		}
 	}
 
-	return url, nil
+	// Повторно присланный снимок не мешает приёму события: совпадение сохраняется для разбора
+	if err := h.anprService.RegisterEventPhoto(ctx, service.UploadedPhoto{
+		EventID:   eventID,
+		EventTime: payload.EventTime,
+		CameraID:  payload.CameraID,
+		Plate:     payload.Plate,
+		PhotoURL:  photo.URL,
+		Hash:      photo.Hash,
+		Bytes:     photo.Bytes,
+	}); err != nil {
+		h.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to register photo hash")
+	}
 }
 
 func sanitizePathSegment(value, fallback string) string {
@@ -510,6 +440,10 @@ func (h *Handler) listPlates(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse("plate parameter is required"))
 		return
 	}
+	// В ответе и номера, и время их последнего события
+	if h.notModified(c, repository.DataVersionScopePlates, repository.DataVersionScopeEvents) {
+		return
+	}
 
 	plates, err := h.anprService.FindPlates(c.Request.Context(), plateQuery)
 	if err != nil {
@@ -517,7 +451,7 @@ func (h *Handler) listPlates(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to find plates")
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to find plates")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -526,6 +460,12 @@ func (h *Handler) listPlates(c *gin.Context) {
 }
 
 func (h *Handler) listEvents(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
 	var plateQuery *string
 	if plate := strings.TrimSpace(c.Query("plate")); plate != "" {
 		plateQuery = &plate
@@ -544,6 +484,32 @@ func (h *Handler) listEvents(c *gin.Context) {
 		direction = &d
 	}
 
+	var vehicleType *string
+	if vt := strings.TrimSpace(c.Query("vehicle_type")); vt != "" {
+		vehicleType = &vt
+	}
+
+	var source *string
+	if s := strings.TrimSpace(c.Query("source")); s != "" {
+		source = &s
+	}
+
+	var polygonID *string
+	if p := strings.TrimSpace(c.Query("polygon_id")); p != "" {
+		polygonID = &p
+	}
+
+	// Пользователи полигона видят только события полигонов своей организации
+	var polygonOrgID *uuid.UUID
+	if principal.IsLandfill() {
+		polygonOrgID = &principal.OrgID
+	}
+
+	// time_field=received_at фильтрует по времени приёма (отличает импорт от событий в реальном времени)
+	timeField := c.Query("time_field")
+
+	h.setDataVersion(c, repository.DataVersionScopeEvents)
+
 	limit := 10
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := parseInt(l); err == nil && parsed > 0 {
@@ -558,13 +524,13 @@ func (h *Handler) listEvents(c *gin.Context) {
 		}
 	}
 
-	events, err := h.anprService.FindEvents(c.Request.Context(), plateQuery, from, to, direction, limit, offset)
+	events, err := h.anprService.FindEvents(c.Request.Context(), plateQuery, from, to, timeField, direction, vehicleType, source, polygonID, polygonOrgID, limit, offset)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to find events")
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to find events")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -582,11 +548,11 @@ func (h *Handler) getEvent(c *gin.Context) {
 
 	event, err := h.anprService.GetEventByID(c.Request.Context(), eventID)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
+		if errors.Is(err, service.ErrEventNotFound) {
 			c.JSON(http.StatusNotFound, errorResponse("event not found"))
 			return
 		}
-		h.log.Error().Err(err).Str("event_id", eventID.String()).Msg("failed to get event")
+		h.logger(c.Request.Context()).Error().Err(err).Str("event_id", eventID.String()).Msg("failed to get event")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -594,144 +560,94 @@ func (h *Handler) getEvent(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(event))
 }
 
-func (h *Handler) handleError(c *gin.Context, err error) {
-	switch {
-	case errors.Is(err, service.ErrInvalidInput):
-		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-	case errors.Is(err, service.ErrNotFound):
-		c.JSON(http.StatusNotFound, errorResponse(err.Error()))
-	default:
-		h.log.Error().Err(err).Msg("handler error")
-		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+// allowCamera проверяет лимит событий камеры; при превышении отвечает 429 с Retry-After
+func (h *Handler) allowCamera(c *gin.Context, cameraID string) bool {
+	ok, wait := h.cameraLimiter.Allow(cameraID)
+	if !ok {
+		h.logger(c.Request.Context()).Warn().
+			Str("camera_id", cameraID).
+			Dur("retry_after", wait).
+			Msg("camera event rate limit exceeded")
+		middleware.AbortRateLimited(c, wait)
 	}
+	return ok
 }
 
-func (h *Handler) createHikvisionEvent(c *gin.Context) {
-	h.log.Info().
-		Str("method", c.Request.Method).
-		Str("path", c.Request.URL.Path).
-		Str("remote_addr", c.ClientIP()).
-		Str("user_agent", c.Request.UserAgent()).
-		Str("content_type", c.Request.Header.Get("Content-Type")).
-		Msg("received Hikvision event request")
+// eventSourceHeader — источник события для запросов, в теле которых поля source нет (XML Hikvision)
+const eventSourceHeader = "X-Event-Source"
 
-	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		h.log.Error().Err(err).Msg("failed to parse multipart request")
-		c.JSON(http.StatusBadRequest, errorResponse("invalid multipart payload"))
-		return
+// applyEventSourceHeader подставляет источник из заголовка X-Event-Source, если в теле события его нет.
+// Значение проверяет сервис при обработке события.
+func applyEventSourceHeader(c *gin.Context, payload *anpr.EventPayload) {
+	if payload.Source == "" {
+		payload.Source = strings.TrimSpace(c.GetHeader(eventSourceHeader))
 	}
+}
 
-	xmlPayload, err := extractXMLPayload(c.Request.MultipartForm)
-	if err != nil {
-		h.log.Error().Err(err).Msg("failed to extract xml payload")
-		c.JSON(http.StatusBadRequest, errorResponse("xml payload not found"))
+// applyEdgeReceivedAt берёт время приёма из заголовка шлюза полигона (edge.ReceivedAtHeader): запрос мог
+// пролежать в очереди шлюза, пока не было связи, и время сервера для него неверно. Маршруты приёма открыты,
+// поэтому время приёма — и заголовок, и received_at в теле — принимается только от шлюза с EDGE_TOKEN
+// (см. fromEdge), иначе им можно сдвинуть время события; остальным событиям его ставит сервис.
+func (h *Handler) applyEdgeReceivedAt(c *gin.Context, payload *anpr.EventPayload) {
+	if !h.fromEdge(c) {
+		payload.ReceivedAt = nil
 		return
 	}
-
-	h.log.Debug().
-		Int("xml_size", len(xmlPayload)).
-		Str("xml_preview", string(xmlPayload[:min(200, len(xmlPayload))])).
-		Msg("extracted XML payload")
-
-	hikEvent := &hikvisionEvent{}
-	if err := xml.Unmarshal(xmlPayload, hikEvent); err != nil {
-		h.log.Error().
-			Err(err).
-			Str("xml_content", string(xmlPayload)).
-			Msg("failed to parse hikvision xml")
-		c.JSON(http.StatusBadRequest, errorResponse("invalid xml payload"))
+	value := c.GetHeader(edge.ReceivedAtHeader)
+	if value == "" || payload.ReceivedAt != nil {
 		return
 	}
+	receivedAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || receivedAt.After(h.anprService.Now()) {
+		return
+	}
+	payload.ReceivedAt = &receivedAt
+}
 
-	h.log.Info().
-		Str("event_type", hikEvent.EventType).
-		Str("license_plate", hikEvent.ANPR.LicensePlate).
-		Str("device_id", hikEvent.DeviceID).
-		Str("channel_id", hikEvent.ChannelID).
-		Str("date_time", hikEvent.DateTime).
-		Str("vehicle_info_color", hikEvent.VehicleInfo.Color).
-		Str("vehicle_info_brand", hikEvent.VehicleInfo.Brand).
-		Str("vehicle_info_logo_recog", hikEvent.VehicleInfo.VehicleLogoRecog).
-		Str("vehicle_info_model", hikEvent.VehicleInfo.Model).
-		Str("vehicle_info_vehile_model", hikEvent.VehicleInfo.VehileModel).
-		Str("gat_color", hikEvent.VehicleGATInfo.ColorByGAT).
-		Msg("parsed Hikvision event")
-
-	payload := hikEvent.ToEventPayload(xmlPayload)
+// fromEdge сообщает, что запрос переслан шлюзом полигона: заголовок edge.TokenHeader совпадает с EDGE_TOKEN
+func (h *Handler) fromEdge(c *gin.Context) bool {
+	token := h.anprService.Config().Edge.Token
+	return token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(edge.TokenHeader)), []byte(token)) == 1
+}
 
-	if payload.CameraID == "" {
-		cameraID := c.Query("camera_id")
-		if cameraID == "" {
-			cameraID = h.config.Camera.HTTPHost
-		}
-		payload.CameraID = cameraID
-	}
-	if payload.CameraModel == "" {
-		payload.CameraModel = h.config.Camera.Model
+// enqueueEvent в режиме INGEST_MODE=async ставит событие в очередь сохранения и отвечает камере 202.
+// false — очередь выключена (или флаг async_ingest выключен для камеры) либо переполнена, событие нужно
+// сохранить синхронно.
+func (h *Handler) enqueueEvent(c *gin.Context, payload anpr.EventPayload, eventID uuid.UUID, photoURLs []string) bool {
+	if h.ingestQueue == nil || !h.anprService.FeatureEnabled(c.Request.Context(), config.FeatureAsyncIngest, payload.CameraID) {
+		return false
 	}
-	if payload.EventTime.IsZero() {
-		payload.EventTime = time.Now()
-	}
-	if payload.RawPayload == nil {
-		payload.RawPayload = map[string]interface{}{
-			"xml": string(xmlPayload),
-		}
+	if !h.ingestQueue.Enqueue(c.Request.Context(), payload, h.anprService.Config().Camera.Model, eventID, photoURLs) {
+		h.logger(c.Request.Context()).Warn().
+			Str("event_id", eventID.String()).
+			Str("camera_id", payload.CameraID).
+			Msg("ingest queue is full, processing event synchronously")
+		return false
 	}
 
-	// Generate event ID upfront
-	eventID := uuid.New()
+	respondEventAccepted(c, eventID, payload, photoURLs)
+	return true
+}
 
-	result, err := h.anprService.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model, eventID, nil)
-	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("invalid input for Hikvision event")
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-			return
-		}
-		if errors.Is(err, service.ErrVehicleNotWhitelisted) {
-			h.log.Warn().
-				Err(err).
-				Str("plate", payload.Plate).
-				Str("camera_id", payload.CameraID).
-				Msg("vehicle not in whitelist (vehicles table)")
-			c.JSON(http.StatusForbidden, errorResponse(err.Error()))
-			return
-		}
-		h.log.Error().
-			Err(err).
-			Str("plate", payload.Plate).
-			Str("camera_id", payload.CameraID).
-			Msg("failed to process hikvision event")
+func (h *Handler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(err.Error()))
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, errorResponse(err.Error()))
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, errorResponse("request deadline exceeded"))
+	default:
+		h.logger(c.Request.Context()).Error().Err(err).Msg("handler error")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
-		return
 	}
-
-	h.log.Info().
-		Str("event_id", result.EventID.String()).
-		Str("plate_id", result.PlateID.String()).
-		Str("plate", result.Plate).
-		Int("hits_count", len(result.Hits)).
-		Msg("successfully processed and saved Hikvision event")
-
-	c.JSON(http.StatusCreated, gin.H{
-		"status":         "ok",
-		"event_id":       result.EventID,
-		"plate_id":       result.PlateID,
-		"plate":          result.Plate,
-		"vehicle_exists": result.VehicleExists,
-		"hits":           result.Hits,
-		"photos":         result.PhotoURLs,
-		"processed":      true,
-	})
 }
 
 // checkHikvisionEndpoint обрабатывает GET запросы от камеры для проверки доступности эндпоинта
 func (h *Handler) checkHikvisionEndpoint(c *gin.Context) {
-	h.log.Info().
+	h.logger(c.Request.Context()).Info().
 		Str("method", c.Request.Method).
 		Str("path", c.Request.URL.Path).
 		Str("remote_addr", c.ClientIP()).
@@ -745,253 +661,66 @@ func (h *Handler) checkHikvisionEndpoint(c *gin.Context) {
 	})
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func extractXMLPayload(form *multipart.Form) ([]byte, error) {
-	if form == nil {
-		return nil, errors.New("empty form")
-	}
-
-	for _, files := range form.File {
-		for _, fh := range files {
-			if isXMLFile(fh) {
-				file, err := fh.Open()
-				if err != nil {
-					return nil, err
-				}
-				defer file.Close()
-				return io.ReadAll(file)
-			}
-		}
-	}
-
-	for key, values := range form.Value {
-		if strings.Contains(strings.ToLower(key), "xml") && len(values) > 0 {
-			return []byte(values[0]), nil
-		}
+func successResponse(data interface{}) gin.H {
+	return gin.H{
+		"data": data,
 	}
-
-	return nil, errors.New("xml file not found")
 }
 
-func isXMLFile(fh *multipart.FileHeader) bool {
-	filename := strings.ToLower(fh.Filename)
-	if strings.HasSuffix(filename, ".xml") {
-		return true
-	}
-	contentType := strings.ToLower(fh.Header.Get("Content-Type"))
-	return strings.Contains(contentType, "xml")
+// syncVehicleRequest — номер транспорта, добавляемый в белый список или удаляемый из него
+type syncVehicleRequest struct {
+	PlateNumber string `json:"plate_number" binding:"required"`
 }
 
-type hikvisionEvent struct {
-	XMLName          xml.Name `xml:"EventNotificationAlert"`
-	EventType        string   `xml:"eventType" json:"event_type"`
-	EventDescription string   `xml:"eventDescription" json:"event_description"`
-	DateTime         string   `xml:"dateTime" json:"date_time"`
-	ChannelID        string   `xml:"channelID" json:"channel_id"`
-	DeviceID         string   `xml:"deviceID" json:"device_id"`
-	DeviceName       string   `xml:"deviceName" json:"device_name"`
-	IPAddress        string   `xml:"ipAddress" json:"ip_address"`
-	PortNo           string   `xml:"portNo" json:"port_no"`
-	ProtocolType     string   `xml:"protocolType" json:"protocol_type"`
-	ANPR             struct {
-		LicensePlate    string  `xml:"licensePlate" json:"license_plate"`
-		ConfidenceLevel float64 `xml:"confidenceLevel" json:"confidence_level"`
-		VehicleType     string  `xml:"vehicleType" json:"vehicle_type"`
-		VehicleColor    string  `xml:"vehicleColor" json:"vehicle_color"`
-		Color           string  `xml:"color" json:"color"`
-		PlateColor      string  `xml:"plateColor" json:"plate_color"`
-		Country         string  `xml:"country" json:"country"`
-		Brand           string  `xml:"brand" json:"brand"`
-		Direction       string  `xml:"direction" json:"direction"`
-		LaneNo          string  `xml:"laneNo" json:"lane_no"`
-		Speed           string  `xml:"speed" json:"speed"`
-	} `xml:"ANPR" json:"anpr"`
-	VehicleInfo struct {
-		Type             string `xml:"vehicleType" json:"vehicle_type"`
-		Color            string `xml:"color" json:"color"`
-		VehicleColor     string `xml:"vehicleColor" json:"vehicle_color"`
-		Brand            string `xml:"brand" json:"brand"`
-		VehicleLogoRecog string `xml:"vehicleLogoRecog" json:"vehicle_logo_recog"`
-		Model            string `xml:"vehicleModel" json:"vehicle_model"`
-		VehileModel      string `xml:"vehileModel" json:"vehile_model"`
-		PlateColor       string `xml:"plateColor" json:"plate_color"`
-		Country          string `xml:"country" json:"country"`
-		Speed            string `xml:"speed" json:"speed"`
-	} `xml:"vehicleInfo" json:"vehicle_info"`
-	VehicleGATInfo struct {
-		VehicleTypeByGAT string `xml:"vehicleTypeByGAT" json:"vehicle_type_by_gat"`
-		ColorByGAT       string `xml:"colorByGAT" json:"color_by_gat"`
-		PlateTypeByGAT   string `xml:"palteTypeByGAT" json:"plate_type_by_gat"`
-		PlateColorByGAT  string `xml:"plateColorByGAT" json:"plate_color_by_gat"`
-	} `xml:"VehicleGATInfo" json:"vehicle_gat_info"`
-	PicInfo struct {
-		StoragePath string   `xml:"ftpPath" json:"ftp_path"`
-		FilePath    string   `xml:"filePath" json:"file_path"`
-		FilePaths   []string `xml:"filePathList>filePath" json:"file_path_list"`
-	} `xml:"picInfo" json:"pic_info"`
+type syncVehicleResponse struct {
+	Status      string `json:"status"`
+	PlateID     string `json:"plate_id"`
+	PlateNumber string `json:"plate_number"`
+	Message     string `json:"message"`
 }
 
-func (e *hikvisionEvent) ToEventPayload(rawXML []byte) anpr.EventPayload {
-	eventTime := parseHikvisionTime(e.DateTime)
-	lane := parseLane(e.ANPR.LaneNo)
-
-	// Цвет: ПРИОРИТЕТ - текстовые значения из vehicleInfo, НЕ используем GAT коды если есть текст
-	// GAT коды (H, C и т.д.) - это числовые коды, не читаемые названия
-	vehicleColor := firstNonEmpty(
-		e.VehicleInfo.Color,        // "blue", "white" - текстовое значение (ПРИОРИТЕТ)
-		e.VehicleInfo.VehicleColor, // альтернативное поле в vehicleInfo
-		e.ANPR.VehicleColor,        // из ANPR секции (если есть)
-		e.ANPR.Color,               // альтернативное поле в ANPR
-	)
-	// НЕ используем GAT коды - они нечитаемые (H, C и т.д.)
-	// Если текстового значения нет, оставляем пустым
-
-	// Тип: сначала из ANPR, потом из GAT, потом из vehicleInfo
-	vehicleType := firstNonEmpty(
-		e.ANPR.VehicleType,
-		e.VehicleGATInfo.VehicleTypeByGAT,
-		e.VehicleInfo.Type,
-	)
-	vehiclePlateColor := firstNonEmpty(
-		e.ANPR.PlateColor,
-		e.VehicleGATInfo.PlateColorByGAT,
-		e.VehicleInfo.PlateColor,
-	)
-	vehicleCountry := firstNonEmpty(e.ANPR.Country, e.VehicleInfo.Country)
-
-	// Бренд: сначала текстовое значение, потом ID из vehicleLogoRecog
-	vehicleBrand := firstNonEmpty(e.VehicleInfo.Brand, e.ANPR.Brand)
-	// Если текстового значения нет, но есть ID логотипа, сохраняем ID
-	if vehicleBrand == "" && e.VehicleInfo.VehicleLogoRecog != "" && e.VehicleInfo.VehicleLogoRecog != "0" {
-		vehicleBrand = "brand_id:" + e.VehicleInfo.VehicleLogoRecog
-	}
-
-	// Модель: сначала текстовое значение, потом ID из vehileModel
-	vehicleModel := firstNonEmpty(e.VehicleInfo.Model, e.VehicleInfo.VehileModel)
-	// Если текстового значения нет, но есть ID модели, сохраняем ID (игнорируем "0")
-	if vehicleModel == "" || vehicleModel == "0" {
-		// Если есть другой ID модели, используем его
-		if e.VehicleInfo.VehileModel != "" && e.VehicleInfo.VehileModel != "0" {
-			vehicleModel = "model_id:" + e.VehicleInfo.VehileModel
-		} else {
-			vehicleModel = ""
-		}
-	}
-	speedPtr := parseOptionalFloat(firstNonEmpty(e.VehicleInfo.Speed, e.ANPR.Speed))
-
-	cameraModel := firstNonEmpty(e.DeviceName, e.DeviceID)
-	snapshotURL := firstNonEmpty(e.PicInfo.StoragePath, e.PicInfo.FilePath)
-	if snapshotURL == "" && len(e.PicInfo.FilePaths) > 0 {
-		snapshotURL = e.PicInfo.FilePaths[0]
-	}
-
-	rawPayload := map[string]interface{}{
-		"event_type":        e.EventType,
-		"event_description": e.EventDescription,
-		"device_id":         e.DeviceID,
-		"device_name":       e.DeviceName,
-		"channel_id":        e.ChannelID,
-		"ip_address":        e.IPAddress,
-		"port_no":           e.PortNo,
-		"protocol_type":     e.ProtocolType,
-		"anpr":              e.ANPR,
-		"vehicle_info":      e.VehicleInfo,
-		"vehicle_gat_info":  e.VehicleGATInfo,
-	}
-	if len(rawXML) > 0 {
-		rawPayload["xml"] = string(rawXML)
-	}
-
-	return anpr.EventPayload{
-		CameraID:    firstNonEmpty(e.ChannelID, e.DeviceID),
-		CameraModel: cameraModel,
-		Plate:       strings.TrimSpace(e.ANPR.LicensePlate),
-		Confidence:  e.ANPR.ConfidenceLevel,
-		Direction:   e.ANPR.Direction,
-		Lane:        lane,
-		EventTime:   eventTime,
-		Vehicle: anpr.VehicleInfo{
-			Color:      vehicleColor,
-			Type:       vehicleType,
-			Brand:      vehicleBrand,
-			Model:      vehicleModel,
-			Country:    vehicleCountry,
-			PlateColor: vehiclePlateColor,
-			Speed:      speedPtr,
-		},
-		SnapshotURL: snapshotURL,
-		RawPayload:  rawPayload,
-	}
+// removeVehicleResponse — результат удаления номера из белого списка
+type removeVehicleResponse struct {
+	PlateNumber string `json:"plate_number"`
+	// Removed — false, если номера в белом списке не было
+	Removed bool `json:"removed"`
 }
 
-func parseHikvisionTime(value string) time.Time {
-	if value == "" {
-		return time.Time{}
-	}
-
-	layouts := []string{
-		time.RFC3339Nano,
-		time.RFC3339,
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02 15:04:05",
-	}
-
-	for _, layout := range layouts {
-		if ts, err := time.Parse(layout, value); err == nil {
-			return ts
-		}
+// removeVehicleFromWhitelist убирает номер деактивированного транспорта из белого списка
+// DELETE /api/v1/anpr/sync-vehicle
+func (h *Handler) removeVehicleFromWhitelist(c *gin.Context) {
+	var req syncVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
 	}
 
-	return time.Time{}
-}
-
-func parseLane(value string) int {
-	if value == "" {
-		return 0
-	}
-	lane, err := strconv.Atoi(value)
+	removed, err := h.anprService.RemoveVehicleFromWhitelist(c.Request.Context(), req.PlateNumber)
 	if err != nil {
-		return 0
+		h.handleError(c, err)
+		return
 	}
-	return lane
+	c.JSON(http.StatusOK, successResponse(removeVehicleResponse{PlateNumber: req.PlateNumber, Removed: removed}))
 }
 
-func parseOptionalFloat(value string) *float64 {
-	if strings.TrimSpace(value) == "" {
-		return nil
-	}
-
-	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
-		return &f
-	}
-	return nil
+// deleteOldEventsRequest — удаление событий старше days дней
+type deleteOldEventsRequest struct {
+	Days int `json:"days" binding:"required,min=1"`
 }
 
-func firstNonEmpty(values ...string) string {
-	for _, v := range values {
-		if strings.TrimSpace(v) != "" {
-			return strings.TrimSpace(v)
-		}
-	}
-	return ""
+// deleteAllEventsRequest — удаление всех событий, требует confirm=true
+type deleteAllEventsRequest struct {
+	Confirm bool `json:"confirm" binding:"required"`
 }
 
-func successResponse(data interface{}) gin.H {
-	return gin.H{
-		"data": data,
-	}
+type deleteEventsResponse struct {
+	Status       string `json:"status"`
+	DeletedCount int64  `json:"deleted_count"`
+	Message      string `json:"message"`
 }
 
 func (h *Handler) syncVehicleToWhitelist(c *gin.Context) {
-	var req struct {
-		PlateNumber string `json:"plate_number" binding:"required"`
-	}
+	var req syncVehicleRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
@@ -1000,28 +729,30 @@ func (h *Handler) syncVehicleToWhitelist(c *gin.Context) {
 
 	plateID, err := h.anprService.SyncVehicleToWhitelist(c.Request.Context(), req.PlateNumber)
 	if err != nil {
-		h.log.Error().Err(err).Str("plate_number", req.PlateNumber).Msg("failed to sync vehicle to whitelist")
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c.Request.Context()).Error().Err(err).Str("plate_number", req.PlateNumber).Msg("failed to sync vehicle to whitelist")
 		c.JSON(http.StatusInternalServerError, errorResponse("failed to sync vehicle to whitelist"))
 		return
 	}
 
-	h.log.Info().
+	h.logger(c.Request.Context()).Info().
 		Str("plate_number", req.PlateNumber).
 		Str("plate_id", plateID.String()).
 		Msg("vehicle synced to whitelist")
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":       "ok",
-		"plate_id":     plateID.String(),
-		"plate_number": req.PlateNumber,
-		"message":      "vehicle added to whitelist",
+	c.JSON(http.StatusOK, syncVehicleResponse{
+		Status:      "ok",
+		PlateID:     plateID.String(),
+		PlateNumber: req.PlateNumber,
+		Message:     "vehicle added to whitelist",
 	})
 }
 
 func (h *Handler) deleteOldEvents(c *gin.Context) {
-	var req struct {
-		Days int `json:"days" binding:"required,min=1"`
-	}
+	var req deleteOldEventsRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse("days parameter is required and must be >= 1"))
@@ -1030,42 +761,44 @@ func (h *Handler) deleteOldEvents(c *gin.Context) {
 
 	deletedCount, err := h.anprService.DeleteOldEvents(c.Request.Context(), req.Days)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		if errors.Is(err, service.ErrInvalidInput) || errors.Is(err, service.ErrForbidden) {
+			h.handleError(c, err)
 			return
 		}
-		h.log.Error().Err(err).Int("days", req.Days).Msg("failed to delete old events")
+		h.logger(c.Request.Context()).Error().Err(err).Int("days", req.Days).Msg("failed to delete old events")
 		c.JSON(http.StatusInternalServerError, errorResponse("failed to delete old events"))
 		return
 	}
 
-	h.log.Info().
+	h.logger(c.Request.Context()).Info().
 		Int("days", req.Days).
 		Int64("deleted_count", deletedCount).
 		Msg("deleted old events")
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"deleted_count": deletedCount,
-		"message":       fmt.Sprintf("deleted %d events older than %d days", deletedCount, req.Days),
+	c.JSON(http.StatusOK, deleteEventsResponse{
+		Status:       "ok",
+		DeletedCount: deletedCount,
+		Message:      fmt.Sprintf("deleted %d events older than %d days", deletedCount, req.Days),
 	})
 }
 
 func (h *Handler) deleteAllEvents(c *gin.Context) {
-	var req struct {
-		Confirm bool `json:"confirm" binding:"required"`
-	}
+	var req deleteAllEventsRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil || !req.Confirm {
 		c.JSON(http.StatusBadRequest, errorResponse("confirmation required: set confirm=true"))
 		return
 	}
 
-	h.log.Warn().Str("user_ip", c.ClientIP()).Msg("DELETE ALL EVENTS requested")
+	h.logger(c.Request.Context()).Warn().Str("user_ip", c.ClientIP()).Msg("DELETE ALL EVENTS requested")
 
 	deletedCount, err := h.anprService.DeleteAllEvents(c.Request.Context())
 	if err != nil {
-		h.log.Error().
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c.Request.Context()).Error().
 			Err(err).
 			Str("error_details", err.Error()).
 			Msg("failed to delete all events")
@@ -1076,18 +809,71 @@ func (h *Handler) deleteAllEvents(c *gin.Context) {
 		return
 	}
 
-	h.log.Warn().
+	h.logger(c.Request.Context()).Warn().
 		Int64("deleted_count", deletedCount).
 		Str("user_ip", c.ClientIP()).
 		Msg("successfully deleted ALL events")
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":        "ok",
-		"deleted_count": deletedCount,
-		"message":       fmt.Sprintf("deleted all %d events", deletedCount),
+	c.JSON(http.StatusOK, deleteEventsResponse{
+		Status:       "ok",
+		DeletedCount: deletedCount,
+		Message:      fmt.Sprintf("deleted all %d events", deletedCount),
 	})
 }
 
+// restoreEvent восстанавливает мягко удалённое событие, пока оно не очищено физически
+func (h *Handler) restoreEvent(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid event id"))
+		return
+	}
+
+	if err := h.anprService.RestoreEvent(c.Request.Context(), eventID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"restored": true}))
+}
+
+// reprocessEvents заново разбирает XML камер событий периода и обновляет производные колонки
+// POST /api/v1/admin/events/reprocess?from=2025-01-01T00:00:00Z&to=2025-01-15T00:00:00Z&dry_run=true
+func (h *Handler) reprocessEvents(c *gin.Context) {
+	fromRaw := strings.TrimSpace(c.Query("from"))
+	toRaw := strings.TrimSpace(c.Query("to"))
+	if fromRaw == "" || toRaw == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("from and to are required (RFC3339)"))
+		return
+	}
+	from, err := time.Parse(time.RFC3339, fromRaw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid from time format, use RFC3339"))
+		return
+	}
+	to, err := time.Parse(time.RFC3339, toRaw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid to time format, use RFC3339"))
+		return
+	}
+	dryRun := false
+	if raw := strings.TrimSpace(c.Query("dry_run")); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid dry_run, use true or false"))
+			return
+		}
+	}
+
+	result, err := h.anprService.ReprocessEvents(c.Request.Context(), from, to, dryRun)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
+
 func errorResponse(message string) gin.H {
 	return gin.H{
 		"error": message,
@@ -1148,10 +934,12 @@ func (h *Handler) getInternalEvents(c *gin.Context) {
 		direction = &dir
 	}
 
+	h.setDataVersion(c, repository.DataVersionScopeEvents)
+
 	events, err := h.anprService.GetEventsByPlateAndTime(c.Request.Context(), normalizedPlate, startTime, endTime, direction)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().
+			h.logger(c.Request.Context()).Warn().
 				Err(err).
 				Str("plate", normalizedPlate).
 				Str("start_time", startTimeStr).
@@ -1160,7 +948,7 @@ func (h *Handler) getInternalEvents(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().
+		h.logger(c.Request.Context()).Error().
 			Err(err).
 			Str("plate", normalizedPlate).
 			Str("start_time", startTimeStr).
@@ -1170,7 +958,7 @@ func (h *Handler) getInternalEvents(c *gin.Context) {
 		return
 	}
 
-	h.log.Info().
+	h.logger(c.Request.Context()).Info().
 		Str("plate", normalizedPlate).
 		Time("start_time", startTime).
 		Time("end_time", endTime).
@@ -1187,7 +975,7 @@ func parseInt(s string) (int, error) {
 func (h *Handler) checkCameraStatus(c *gin.Context) {
 	httpHost := h.config.Camera.HTTPHost
 	rtspURL := h.config.Camera.RTSPURL
-	cameraModel := h.config.Camera.Model
+	cameraModel := h.anprService.Config().Camera.Model
 
 	status := gin.H{
 		"camera_model": cameraModel,
@@ -1216,7 +1004,7 @@ func (h *Handler) checkCameraStatus(c *gin.Context) {
 	// RTSP URL проверяем только на наличие (для проверки подключения нужен специальный клиент)
 	status["rtsp_configured"] = rtspURL != ""
 
-	h.log.Info().
+	h.logger(c.Request.Context()).Info().
 		Str("http_host", httpHost).
 		Bool("http_accessible", status["http_accessible"].(bool)).
 		Msg("camera status checked")
@@ -1291,6 +1079,46 @@ func (h *Handler) getReports(c *gin.Context) {
 		filters.PlateNumber = &plateNumber
 	}
 
+	// Фильтр по типу транспорта
+	if vt := strings.TrimSpace(c.Query("vehicle_type")); vt != "" {
+		vehicleType, err := service.ParseVehicleTypeFilter(vt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		filters.VehicleType = &vehicleType
+	}
+
+	// Фильтр по источнику: source=camera исключает импорт и симулятор
+	if raw := strings.TrimSpace(c.Query("source")); raw != "" {
+		sources, err := service.ParseEventSourceFilter(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		filters.Sources = sources
+	}
+
+	// События, пришедшие позже EVENT_MAX_AGE, по умолчанию не учитываются
+	if raw := strings.TrimSpace(c.Query("include_late")); raw != "" {
+		includeLate, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid include_late"))
+			return
+		}
+		filters.IncludeLate = includeLate
+	}
+
+	// Только рейсы на полигон, за которым подрядчик не закреплён
+	if raw := strings.TrimSpace(c.Query("wrong_destination")); raw != "" {
+		onlyWrong, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid wrong_destination"))
+			return
+		}
+		filters.OnlyWrongDestination = onlyWrong
+	}
+
 	// Фильтр по периоду
 	var fromTime, toTime time.Time
 	if fromStr := strings.TrimSpace(c.Query("from")); fromStr != "" {
@@ -1340,16 +1168,8 @@ func (h *Handler) getReports(c *gin.Context) {
 		}
 	}
 
-	// Права доступа: подрядчики видят только свои события
-	if principal.IsContractor() {
-		// Подрядчик видит только события своих машин
-		filters.ContractorID = &principal.OrgID
-		filters.OnlyAssigned = true
-	} else {
-		// Админы/КГУ видят все события, включая непривязанные
-		// Если не указан фильтр по подрядчику, показываем все
-		filters.OnlyAssigned = false
-	}
+	// Права доступа: подрядчики видят только свои события, пользователи полигона — свои полигоны
+	scopeReportFilters(principal, &filters)
 
 	// Пагинация
 	limit := 100
@@ -1375,11 +1195,11 @@ func (h *Handler) getReports(c *gin.Context) {
 	result, err := h.anprService.GetReports(c.Request.Context(), filters)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().Err(err).Msg("invalid input for reports query")
+			h.logger(c.Request.Context()).Warn().Err(err).Msg("invalid input for reports query")
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to get reports")
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to get reports")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -1483,14 +1303,37 @@ func (h *Handler) getReportsComparison(c *gin.Context) {
 	if plateNumber := strings.TrimSpace(c.Query("plate")); plateNumber != "" {
 		baseFilters.PlateNumber = &plateNumber
 	}
+	if vt := strings.TrimSpace(c.Query("vehicle_type")); vt != "" {
+		vehicleType, err := service.ParseVehicleTypeFilter(vt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		baseFilters.VehicleType = &vehicleType
+	}
 
-	if principal.IsContractor() {
-		baseFilters.ContractorID = &principal.OrgID
-		baseFilters.OnlyAssigned = true
-	} else {
-		baseFilters.OnlyAssigned = false
+	// Фильтр по источнику: source=camera исключает импорт и симулятор
+	if raw := strings.TrimSpace(c.Query("source")); raw != "" {
+		sources, err := service.ParseEventSourceFilter(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		baseFilters.Sources = sources
 	}
 
+	// События, пришедшие позже EVENT_MAX_AGE, по умолчанию не учитываются
+	if raw := strings.TrimSpace(c.Query("include_late")); raw != "" {
+		includeLate, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid include_late"))
+			return
+		}
+		baseFilters.IncludeLate = includeLate
+	}
+
+	scopeReportFilters(principal, &baseFilters)
+
 	result, err := h.anprService.GetReportsComparison(c.Request.Context(), service.ReportComparisonInput{
 		Mode:         mode,
 		CurrentFrom:  currentFrom,
@@ -1504,7 +1347,7 @@ func (h *Handler) getReportsComparison(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to get reports comparison")
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to get reports comparison")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -1548,6 +1391,34 @@ func (h *Handler) getReportsHourlyActivity(c *gin.Context) {
 	if plateNumber := strings.TrimSpace(c.Query("plate")); plateNumber != "" {
 		filters.PlateNumber = &plateNumber
 	}
+	if vt := strings.TrimSpace(c.Query("vehicle_type")); vt != "" {
+		vehicleType, err := service.ParseVehicleTypeFilter(vt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		filters.VehicleType = &vehicleType
+	}
+
+	// Фильтр по источнику: source=camera исключает импорт и симулятор
+	if raw := strings.TrimSpace(c.Query("source")); raw != "" {
+		sources, err := service.ParseEventSourceFilter(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		filters.Sources = sources
+	}
+
+	// События, пришедшие позже EVENT_MAX_AGE, по умолчанию не учитываются
+	if raw := strings.TrimSpace(c.Query("include_late")); raw != "" {
+		includeLate, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid include_late"))
+			return
+		}
+		filters.IncludeLate = includeLate
+	}
 
 	var fromTime, toTime time.Time
 	if fromStr := strings.TrimSpace(c.Query("from")); fromStr != "" {
@@ -1584,13 +1455,8 @@ func (h *Handler) getReportsHourlyActivity(c *gin.Context) {
 		return
 	}
 
-	if principal.IsContractor() {
-		filters.ContractorID = &principal.OrgID
-		filters.OnlyAssigned = true
-	} else {
-		filters.OnlyAssigned = false
-	}
-	filters.UseOperationalWindow = true
+	scopeReportFilters(principal, &filters)
+	filters.OnlyInShift = true
 
 	result, err := h.anprService.GetHourlyActivity(c.Request.Context(), filters)
 	if err != nil {
@@ -1598,7 +1464,7 @@ func (h *Handler) getReportsHourlyActivity(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to get hourly activity")
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to get hourly activity")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -1614,6 +1480,53 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		return
 	}
 
+	filters, ok := parseExportFilters(c, principal)
+	if !ok {
+		return
+	}
+
+	// Генерируем Excel файл
+	excelData, filename, err := h.anprService.ExportReportsExcel(c.Request.Context(), filters)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.logger(c.Request.Context()).Warn().Err(err).Msg("invalid input for excel export")
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		if errors.Is(err, service.ErrTooManyRows) {
+			h.logger(c.Request.Context()).Warn().Err(err).Msg("too many rows for excel export")
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to export reports to excel")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return
+	}
+
+	// Устанавливаем заголовки для скачивания файла
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", excelData)
+}
+
+// parseExportFilters разбирает фильтры выгрузок (Excel, обезличенный набор) из query параметров.
+// При ошибке пишет ответ 400 и возвращает false.
+// scopeReportFilters ограничивает отчёт правами пользователя: подрядчик видит только события своих
+// машин, пользователь полигона (LANDFILL_*) — только полигоны своей организации, админы и КГУ — всё
+func scopeReportFilters(principal model.Principal, filters *repository.ReportFilters) {
+	switch {
+	case principal.IsContractor():
+		filters.ContractorID = &principal.OrgID
+		filters.OnlyAssigned = true
+	case principal.IsLandfill():
+		filters.PolygonOrgID = &principal.OrgID
+		filters.OnlyAssigned = false
+	default:
+		filters.OnlyAssigned = false
+	}
+}
+
+func parseExportFilters(c *gin.Context, principal model.Principal) (repository.ReportFilters, bool) {
 	// Парсим фильтры из query параметров (аналогично getReports)
 	filters := repository.ReportFilters{}
 
@@ -1622,7 +1535,7 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		contractorID, err := uuid.Parse(contractorIDStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, errorResponse("invalid contractor_id"))
-			return
+			return filters, false
 		}
 		filters.ContractorID = &contractorID
 	}
@@ -1632,7 +1545,7 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		polygonID, err := uuid.Parse(polygonIDStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, errorResponse("invalid polygon_id"))
-			return
+			return filters, false
 		}
 		filters.PolygonID = &polygonID
 	}
@@ -1642,7 +1555,7 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		vehicleID, err := uuid.Parse(vehicleIDStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, errorResponse("invalid vehicle_id"))
-			return
+			return filters, false
 		}
 		filters.VehicleID = &vehicleID
 	}
@@ -1652,13 +1565,53 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		filters.PlateNumber = &plateNumber
 	}
 
+	// Фильтр по типу транспорта
+	if vt := strings.TrimSpace(c.Query("vehicle_type")); vt != "" {
+		vehicleType, err := service.ParseVehicleTypeFilter(vt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return filters, false
+		}
+		filters.VehicleType = &vehicleType
+	}
+
+	// Фильтр по источнику: source=camera исключает импорт и симулятор
+	if raw := strings.TrimSpace(c.Query("source")); raw != "" {
+		sources, err := service.ParseEventSourceFilter(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return filters, false
+		}
+		filters.Sources = sources
+	}
+
+	// События, пришедшие позже EVENT_MAX_AGE, по умолчанию не учитываются
+	if raw := strings.TrimSpace(c.Query("include_late")); raw != "" {
+		includeLate, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid include_late"))
+			return filters, false
+		}
+		filters.IncludeLate = includeLate
+	}
+
+	// Только рейсы на полигон, за которым подрядчик не закреплён
+	if raw := strings.TrimSpace(c.Query("wrong_destination")); raw != "" {
+		onlyWrong, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid wrong_destination"))
+			return filters, false
+		}
+		filters.OnlyWrongDestination = onlyWrong
+	}
+
 	// Фильтр по периоду
 	var fromTime, toTime time.Time
 	if fromStr := strings.TrimSpace(c.Query("from")); fromStr != "" {
 		t, err := time.Parse(time.RFC3339, fromStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, errorResponse("invalid from time format, use RFC3339"))
-			return
+			return filters, false
 		}
 		fromTime = t
 		filters.From = fromTime
@@ -1668,7 +1621,7 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		t, err := time.Parse(time.RFC3339, toStr)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, errorResponse("invalid to time format, use RFC3339"))
-			return
+			return filters, false
 		}
 		toTime = t
 		filters.To = toTime
@@ -1697,7 +1650,7 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 	if !filters.From.IsZero() && !filters.To.IsZero() {
 		if filters.To.Before(filters.From) {
 			c.JSON(http.StatusBadRequest, errorResponse("to time must be after from time"))
-			return
+			return filters, false
 		}
 	}
 
@@ -1706,45 +1659,16 @@ func (h *Handler) exportReportsExcel(c *gin.Context) {
 		daysDiff := filters.To.Sub(filters.From).Hours() / 24
 		if daysDiff > 90 {
 			c.JSON(http.StatusBadRequest, errorResponse("date range cannot exceed 90 days"))
-			return
+			return filters, false
 		}
 	}
 
-	// Права доступа: подрядчики видят только свои события
-	if principal.IsContractor() {
-		// Подрядчик видит только события своих машин
-		filters.ContractorID = &principal.OrgID
-		filters.OnlyAssigned = true
-	} else {
-		// Админы/КГУ видят все события, включая непривязанные
-		// Если не указан фильтр по подрядчику, показываем все
-		filters.OnlyAssigned = false
-	}
+	// Права доступа: подрядчики видят только свои события, пользователи полигона — свои полигоны
+	scopeReportFilters(principal, &filters)
 
-	// Для Excel limit/offset из query НЕ используем - используем внутреннюю пагинацию
+	// Для выгрузок limit/offset из query НЕ используем - используем внутреннюю пагинацию
 	// Но проверяем максимальное количество строк (100k)
 	filters.MaxRows = 100000
 
-	// Генерируем Excel файл
-	excelData, filename, err := h.anprService.ExportReportsExcel(c.Request.Context(), filters)
-	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().Err(err).Msg("invalid input for excel export")
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-			return
-		}
-		if errors.Is(err, service.ErrTooManyRows) {
-			h.log.Warn().Err(err).Msg("too many rows for excel export")
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-			return
-		}
-		h.log.Error().Err(err).Msg("failed to export reports to excel")
-		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
-		return
-	}
-
-	// Устанавливаем заголовки для скачивания файла
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", excelData)
+	return filters, true
 }
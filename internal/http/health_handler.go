@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/db"
+	"anpr-service/internal/service"
+	"anpr-service/internal/storage"
+)
+
+// fullHealth — расширенная проверка: БД, доступность хранилища фото и активность камер.
+// status=degraded (HTTP 200), если хранилище фото недоступно, загрузки идут в резервное хранилище или какая-то камера молчит во время смены;
+// status=unhealthy (HTTP 503), если недоступна БД.
+// leader показывает, выполняет ли эта реплика периодические задачи.
+func (h *Handler) fullHealth(database *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := db.HealthCheck(ctx, database); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "database": "unavailable"})
+			return
+		}
+
+		status := "ok"
+
+		storageStatus := "ok"
+		if h.photoStore.FailoverActive() {
+			storageStatus = "failover"
+			status = "degraded"
+		} else if err := h.photoStore.Ping(ctx); err != nil {
+			if errors.Is(err, storage.ErrNotConfigured) {
+				storageStatus = "not_configured"
+			} else {
+				h.logger(c.Request.Context()).Warn().Err(err).Msg("photo storage is unreachable")
+				storageStatus = "unavailable"
+				status = "degraded"
+			}
+		}
+
+		cameras, err := h.anprService.CameraLiveness(ctx, h.anprService.Now())
+		if err != nil {
+			h.logger(c.Request.Context()).Error().Err(err).Msg("failed to check camera liveness")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unhealthy", "database": "ok", "storage": storageStatus})
+			return
+		}
+		for _, camera := range cameras {
+			if camera.Status == service.CameraStatusSilent || camera.Status == service.CameraStatusVideoLoss {
+				status = "degraded"
+				break
+			}
+		}
+
+		response := gin.H{
+			"status":           status,
+			"database":         "ok",
+			"storage":          storageStatus,
+			"storage_failover": h.photoStore.Status(),
+			"cameras":          cameras,
+			"leader":           h.leader.Stats(),
+			"ingest_panics":    h.anprService.IngestPanicStats(),
+		}
+		if h.ingestQueue != nil {
+			response["ingest_queue"] = h.ingestQueue.Stats()
+		}
+		if pool, err := db.Pool(database); err == nil {
+			response["database_pool"] = pool
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/service"
+)
+
+// ingestRoute — маршрут приёма событий одного формата камер
+type ingestRoute struct {
+	adapter string
+	// deadLetters — сохранять непринятые запросы для повтора: камера такие уведомления не переотправляет
+	deadLetters bool
+	// processed — поле processed в ответе v1 (исторически есть только у Hikvision)
+	processed bool
+}
+
+var (
+	genericRoute   = ingestRoute{adapter: ingest.AdapterGeneric}
+	hikvisionRoute = ingestRoute{adapter: ingest.AdapterHikvision, deadLetters: true, processed: true}
+)
+
+// ingestEvent принимает событие камеры: адаптер маршрута разбирает запрос, снимки загружаются в хранилище,
+// событие проходит ProcessIncomingEvent (или очередь при INGEST_MODE=async)
+func (h *Handler) ingestEvent(route ingestRoute) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		h.logger(ctx).Info().
+			Str("adapter", route.adapter).
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("remote_addr", c.ClientIP()).
+			Str("user_agent", c.Request.UserAgent()).
+			Str("content_type", c.GetHeader("Content-Type")).
+			Msg("received ingest request")
+
+		adapter, err := h.anprService.IngestAdapter(route.adapter)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		// Тело читается целиком, чтобы непринятое уведомление можно было положить в dead letters
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			h.logger(ctx).Error().Err(err).Msg("failed to read ingest request body")
+			c.JSON(http.StatusBadRequest, errorResponse("failed to read request body"))
+			return
+		}
+
+		result, err := adapter.Parse(ctx, &ingest.Request{
+			ContentType: c.GetHeader("Content-Type"),
+			Header:      c.Request.Header,
+			Query:       c.Request.URL.Query(),
+			Body:        body,
+		})
+		if err != nil {
+			h.logger(ctx).Error().Err(err).Str("adapter", route.adapter).Msg("failed to parse ingest request")
+			// Запрос, уронивший разбор, сохраняется для повтора на любом маршруте
+			if route.deadLetters || service.IsIngestPanic(err) {
+				h.saveDeadLetter(c, route.adapter, body, err)
+			}
+			var payloadErr *ingest.PayloadError
+			if errors.As(err, &payloadErr) {
+				response := errorResponse(payloadErr.Message)
+				if len(payloadErr.Fields) > 0 {
+					response["details"] = payloadErr.Fields
+				}
+				c.JSON(http.StatusBadRequest, response)
+				return
+			}
+			h.handleError(c, err)
+			return
+		}
+		if result.Signal != nil {
+			h.ackCameraSignal(c, result.Format, *result.Signal)
+			return
+		}
+
+		payload := *result.Payload
+		h.applyEdgeReceivedAt(c, &payload)
+		if payload.EventTime.IsZero() {
+			payload.EventTime = h.anprService.Now()
+			if payload.ReceivedAt != nil {
+				payload.EventTime = *payload.ReceivedAt
+			}
+		}
+		applyEventSourceHeader(c, &payload)
+
+		// Лимит проверяется до загрузки фото, чтобы поток событий не расходовал R2
+		if !h.allowCamera(c, payload.CameraID) {
+			return
+		}
+
+		// Идентификатор выдаётся заранее: по нему раскладываются фото события
+		eventID := h.anprService.NewEventID()
+		photoURLs := h.storeEventPhotos(ctx, result.Photos, eventID, payload)
+
+		h.logger(ctx).Info().
+			Str("plate", payload.Plate).
+			Str("camera_id", payload.CameraID).
+			Int("photos_count", len(photoURLs)).
+			Msg("processing ANPR event")
+
+		if h.enqueueEvent(c, payload, eventID, photoURLs) {
+			return
+		}
+
+		processed, err := h.anprService.ProcessIncomingEvent(ctx, payload, h.anprService.Config().Camera.Model, eventID, photoURLs)
+		if err != nil {
+			if (errors.Is(err, service.ErrInvalidInput) && route.deadLetters) || service.IsIngestPanic(err) {
+				h.saveDeadLetter(c, route.adapter, body, err)
+			}
+			h.respondIngestError(c, payload, err)
+			return
+		}
+
+		h.logger(ctx).Info().
+			Str("event_id", processed.EventID.String()).
+			Str("plate_id", processed.PlateID.String()).
+			Str("plate", processed.Plate).
+			Int("hits_count", len(processed.Hits)).
+			Int("photos_count", len(photoURLs)).
+			Msg("successfully processed and saved ANPR event")
+
+		respondEventCreated(c, processed, route.processed)
+	}
+}
+
+// respondIngestError отвечает камере на событие, которое не удалось сохранить
+func (h *Handler) respondIngestError(c *gin.Context, payload anpr.EventPayload, err error) {
+	log := h.logger(c.Request.Context())
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		log.Warn().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Msg("invalid input for ANPR event")
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+	case errors.Is(err, service.ErrDuplicateEvent):
+		log.Warn().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Msg("duplicate event within 5 minutes, skipping save")
+		c.JSON(http.StatusConflict, errorResponse(err.Error()))
+	case errors.Is(err, service.ErrVehicleNotWhitelisted):
+		log.Warn().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Msg("vehicle not in whitelist (vehicles table)")
+		c.JSON(http.StatusForbidden, errorResponse(err.Error()))
+	case service.IsIngestPanic(err):
+		// Подробности паники (стек) уже в логе recoverIngest
+		c.JSON(http.StatusInternalServerError, errorResponse("failed to process event, request saved for replay"))
+	default:
+		log.Error().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Msg("failed to process ANPR event")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+	}
+}
+
+// storeEventPhotos загружает снимки события в хранилище фото и регистрирует их; снимок, который не удалось загрузить,
+// пропускается. Возвращает ссылки на загруженные снимки.
+func (h *Handler) storeEventPhotos(ctx context.Context, photos []ingest.Photo, eventID uuid.UUID, payload anpr.EventPayload) []string {
+	uploaded := h.uploadEventPhotos(ctx, photos, eventID, payload)
+	h.registerEventPhotos(ctx, uploaded, eventID, payload)
+	return photoURLs(uploaded)
+}
+
+// uploadEventPhotos загружает снимки события в хранилище фото, не регистрируя их; снимок, который не удалось
+// загрузить, пропускается
+func (h *Handler) uploadEventPhotos(ctx context.Context, photos []ingest.Photo, eventID uuid.UUID, payload anpr.EventPayload) []*uploadedPhoto {
+	if len(photos) == 0 {
+		return nil
+	}
+	if h.photoStore == nil {
+		h.logger(ctx).Warn().
+			Int("photos_count", len(photos)).
+			Msg("photos provided but photo storage not configured, skipping photo upload")
+		return nil
+	}
+
+	var uploaded []*uploadedPhoto
+	for i, file := range photos {
+		photo, err := h.storeEventPhoto(ctx, file.Data, file.ContentType, file.Name, eventID, payload.EventTime, payload.CameraID, payload.Plate, i)
+		if err != nil {
+			h.logger(ctx).Warn().
+				Err(err).
+				Str("filename", file.Name).
+				Str("event_id", eventID.String()).
+				Msg("failed to upload photo")
+			continue
+		}
+		uploaded = append(uploaded, photo)
+	}
+	return uploaded
+}
+
+// registerEventPhotos сохраняет копии и хеши снимков сохранённого события
+func (h *Handler) registerEventPhotos(ctx context.Context, uploaded []*uploadedPhoto, eventID uuid.UUID, payload anpr.EventPayload) {
+	for _, photo := range uploaded {
+		h.registerEventPhoto(ctx, photo, eventID, payload)
+	}
+}
+
+// discardEventPhotos удаляет из хранилища снимки события, которое так и не сохранилось; ошибки только
+// логируются
+func (h *Handler) discardEventPhotos(ctx context.Context, uploaded []*uploadedPhoto, eventID uuid.UUID) {
+	for _, photo := range uploaded {
+		for _, key := range photo.Keys {
+			if err := h.photoStore.Delete(ctx, key); err != nil {
+				h.logger(ctx).Warn().Err(err).Str("key", key).Str("event_id", eventID.String()).Msg("failed to delete photo of rejected event")
+			}
+		}
+	}
+}
+
+func photoURLs(uploaded []*uploadedPhoto) []string {
+	var urls []string
+	for _, photo := range uploaded {
+		urls = append(urls, photo.URL)
+	}
+	return urls
+}
+
+// ackCameraSignal фиксирует heartbeat/videoloss камеры и подтверждает его ответом ISAPI ResponseStatus.
+// Сигналы не расходуют лимит событий камеры. Ошибка записи сигнала только логируется: камера не должна
+// повторять heartbeat из-за сбоя БД.
+func (h *Handler) ackCameraSignal(c *gin.Context, format string, signal ingest.Signal) {
+	if err := h.anprService.RecordCameraSignal(c.Request.Context(), signal); err != nil {
+		h.logger(c.Request.Context()).Error().
+			Err(err).
+			Str("camera_id", signal.CameraID).
+			Str("event_type", signal.EventType).
+			Msg("failed to record camera signal")
+	}
+	contentType, body := hikvision.ResponseStatusOK(format, c.Request.URL.Path)
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// saveDeadLetter сохраняет непринятое уведомление; ошибка сохранения только логируется,
+// ответ камере не меняется
+func (h *Handler) saveDeadLetter(c *gin.Context, adapter string, body []byte, cause error) {
+	headers := map[string]string{}
+	for _, name := range service.DeadLetterHeaders {
+		if value := c.GetHeader(name); value != "" {
+			headers[name] = value
+		}
+	}
+	err := h.anprService.SaveDeadLetter(c.Request.Context(), service.DeadLetterInput{
+		Endpoint:    adapter,
+		CameraID:    c.Query("camera_id"),
+		RemoteAddr:  c.ClientIP(),
+		ContentType: c.GetHeader("Content-Type"),
+		Headers:     headers,
+		Body:        body,
+		Err:         cause,
+	})
+	if err != nil {
+		h.logger(c.Request.Context()).Error().Err(err).Msg("failed to save dead letter")
+	}
+}
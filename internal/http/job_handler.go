@@ -0,0 +1,60 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+// listJobs возвращает периодические задачи с расписанием и последним запуском
+// GET /api/v1/admin/jobs
+func (h *Handler) listJobs(c *gin.Context) {
+	jobs, err := h.anprService.ListJobs(c.Request.Context())
+	if err != nil {
+		h.handleJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(jobs))
+}
+
+// listJobRuns возвращает историю запусков задачи
+// GET /api/v1/admin/jobs/:name/runs?limit=50
+func (h *Handler) listJobRuns(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	runs, err := h.anprService.ListJobRuns(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		h.handleJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(runs))
+}
+
+// triggerJob запускает задачу вручную; ответ приходит сразу, итог — в истории запусков
+// POST /api/v1/admin/jobs/:name/run
+func (h *Handler) triggerJob(c *gin.Context) {
+	run, err := h.anprService.TriggerJob(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleJobError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, successResponse(run))
+}
+
+func (h *Handler) handleJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobRunning):
+		c.JSON(http.StatusConflict, errorResponse(err.Error()))
+	case errors.Is(err, service.ErrSchedulerNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, errorResponse(err.Error()))
+	default:
+		h.handleError(c, err)
+	}
+}
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/service"
+)
+
+// DataVersionHeader — заголовок с версией данных ответа: клиент сравнивает её с сохранённой
+// (или делает HEAD-запрос) и запрашивает полный ответ только при изменении
+const DataVersionHeader = "X-Data-Version"
+
+func (h *Handler) listLists(c *gin.Context) {
+	if !h.canViewLists(c) {
+		return
+	}
+	if h.notModified(c, repository.DataVersionScopeLists) {
+		return
+	}
+
+	lists, err := h.anprService.ListLists(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(lists))
+}
+
+func (h *Handler) listListEntries(c *gin.Context) {
+	if !h.canViewLists(c) {
+		return
+	}
+
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+		return
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	if h.notModified(c, repository.DataVersionScopeLists) {
+		return
+	}
+
+	entries, err := h.anprService.GetListEntries(c.Request.Context(), listID, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(entries))
+}
+
+// listEntryRequest — номер, добавляемый в список; valid_from и valid_until задают временную запись
+type listEntryRequest struct {
+	Plate      string     `json:"plate" binding:"required"`
+	Note       *string    `json:"note"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+// listEntryValidityRequest — новый срок действия записи; пустое поле снимает ограничение
+type listEntryValidityRequest struct {
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
+
+// addListEntry добавляет номер в список (или меняет срок действия, если он уже там)
+// POST /api/v1/lists/:id/items
+func (h *Handler) addListEntry(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+		return
+	}
+	var req listEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	entry, err := h.anprService.AddListEntry(c.Request.Context(), listID, service.ListEntryInput{
+		Plate:      req.Plate,
+		Note:       req.Note,
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(entry))
+}
+
+// setListEntryValidity меняет срок действия записи списка
+// PUT /api/v1/lists/:id/items/:plate_id
+func (h *Handler) setListEntryValidity(c *gin.Context) {
+	listID, plateID, ok := parseListEntryParams(c)
+	if !ok {
+		return
+	}
+	var req listEntryValidityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	entry, err := h.anprService.SetListEntryValidity(c.Request.Context(), listID, plateID, req.ValidFrom, req.ValidUntil)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(entry))
+}
+
+// removeListEntry убирает номер из списка
+// DELETE /api/v1/lists/:id/items/:plate_id
+func (h *Handler) removeListEntry(c *gin.Context) {
+	listID, plateID, ok := parseListEntryParams(c)
+	if !ok {
+		return
+	}
+	if err := h.anprService.RemoveListEntry(c.Request.Context(), listID, plateID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+// listFileMaxBytes — наибольший размер файла импорта списка
+const listFileMaxBytes = 5 << 20
+
+// exportListFile выгружает номера списка файлом для обмена с ведомствами
+// GET /api/v1/lists/:id/export?format=xlsx
+func (h *Handler) exportListFile(c *gin.Context) {
+	if !h.canViewLists(c) {
+		return
+	}
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	data, filename, err := h.anprService.ExportListFile(c.Request.Context(), listID, format)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if strings.HasSuffix(filename, "."+service.ListFileFormatXLSX) {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// importListFile загружает номера из CSV или XLSX (multipart, поле file) и возвращает отчёт проверки строк
+// POST /api/v1/lists/:id/import?dry_run=true
+func (h *Handler) importListFile(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+		return
+	}
+	dryRun := false
+	if raw := strings.TrimSpace(c.Query("dry_run")); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid dry_run, use true or false"))
+			return
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("file is required (multipart field file)"))
+		return
+	}
+	if header.Size > listFileMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, errorResponse(fmt.Sprintf("file must be at most %d MB", listFileMaxBytes>>20)))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("failed to read request body"))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, listFileMaxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("failed to read request body"))
+		return
+	}
+
+	report, err := h.anprService.ImportListFile(c.Request.Context(), listID, header.Filename, data, dryRun)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+func parseListEntryParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	plateID, err := uuid.Parse(c.Param("plate_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return listID, plateID, true
+}
+
+// canViewLists — состав списков охватывает все номера, поэтому подрядчикам и водителям недоступен
+func (h *Handler) canViewLists(c *gin.Context) bool {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return false
+	}
+	if principal.IsContractor() || principal.IsDriver() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return false
+	}
+	return true
+}
+
+// setDataVersion выставляет X-Data-Version. Версия читается до выборки данных, поэтому изменение,
+// пришедшее во время запроса, даст клиенту новую версию при следующем опросе.
+// Ошибка чтения версии не ломает ответ — заголовок просто не выставляется.
+func (h *Handler) setDataVersion(c *gin.Context, scope string) {
+	version, err := h.anprService.DataVersion(c.Request.Context(), scope)
+	if err != nil {
+		h.logger(c.Request.Context()).Warn().Err(err).Str("scope", scope).Msg("failed to get data version")
+		return
+	}
+	c.Header(DataVersionHeader, strconv.FormatInt(version, 10))
+}
+
+// headDataVersion отвечает на HEAD только заголовками версии данных (X-Data-Version, ETag, Last-Modified),
+// без выборки данных; на условный запрос с актуальной версией — 304
+func (h *Handler) headDataVersion(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := h.anprService.DataVersions(c.Request.Context(), scope)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+		if writeConditional(c, versions) {
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/model"
+)
+
+// requireAdmin пропускает только администраторов Акимата
+func (h *Handler) requireAdmin(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if principal.Role != model.UserRoleAkimatAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+	c.Next()
+}
+
+func (h *Handler) getMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(h.maintenance.Status()))
+}
+
+// maintenanceRequest — включение или выключение режима только для чтения
+type maintenanceRequest struct {
+	Enabled           *bool  `json:"enabled" binding:"required"`
+	Reason            string `json:"reason"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+func (h *Handler) setMaintenance(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if req.RetryAfterSeconds < 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("retry_after_seconds must be positive"))
+		return
+	}
+
+	status := h.maintenance.Set(*req.Enabled, req.Reason, time.Duration(req.RetryAfterSeconds)*time.Second)
+
+	h.logger(c.Request.Context()).Warn().
+		Bool("enabled", status.Enabled).
+		Str("reason", status.Reason).
+		Int("retry_after_seconds", status.RetryAfterSeconds).
+		Msg("maintenance mode changed")
+
+	c.JSON(http.StatusOK, successResponse(status))
+}
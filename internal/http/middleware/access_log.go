@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/logctx"
+)
+
+// AccessLogOptions — какие запросы попадают в access-лог
+type AccessLogOptions struct {
+	// SampleRate — доля успешных быстрых запросов, которые логируются (0 — не логировать, 1 — все)
+	SampleRate float64
+	// AlwaysPaths — пути, запросы к которым логируются всегда (например, приём событий Hikvision)
+	AlwaysPaths []string
+	// SlowThreshold — запросы дольше порога логируются всегда (0 — без порога)
+	SlowThreshold time.Duration
+}
+
+// AccessLog пишет структурированную строку лога на каждый отобранный запрос. Ошибки (4xx/5xx),
+// медленные запросы и AlwaysPaths логируются всегда, остальные — с вероятностью SampleRate.
+// Логгер берётся из контекста запроса, поэтому строка содержит request_id и trace_id.
+func AccessLog(log zerolog.Logger, opts AccessLogOptions) gin.HandlerFunc {
+	return accessLog(log, opts, rand.Float64)
+}
+
+func accessLog(log zerolog.Logger, opts AccessLogOptions, sample func() float64) gin.HandlerFunc {
+	always := make(map[string]struct{}, len(opts.AlwaysPaths))
+	for _, path := range opts.AlwaysPaths {
+		always[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		rawQuery := c.Request.URL.RawQuery
+
+		c.Next()
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+
+		_, alwaysPath := always[path]
+		slow := opts.SlowThreshold > 0 && latency >= opts.SlowThreshold
+		if status < http.StatusBadRequest && !alwaysPath && !slow && sample() >= opts.SampleRate {
+			return
+		}
+
+		logger := logctx.From(c.Request.Context(), &log)
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = logger.Error()
+		case status >= http.StatusBadRequest || slow:
+			event = logger.Warn()
+		default:
+			event = logger.Info()
+		}
+
+		event = event.
+			Str("method", c.Request.Method).
+			Str("path", path).
+			Int("status", status).
+			Dur("latency", latency).
+			Str("client_ip", c.ClientIP()).
+			Str("user_agent", c.Request.UserAgent()).
+			Int64("request_size", c.Request.ContentLength).
+			Int("response_size", c.Writer.Size())
+		if rawQuery != "" {
+			event = event.Str("query", rawQuery)
+		}
+		if route := c.FullPath(); route != "" {
+			event = event.Str("route", route)
+		}
+		if principal, ok := MustPrincipal(c); ok {
+			event = event.
+				Str("user_id", principal.UserID.String()).
+				Str("org_id", principal.OrgID.String()).
+				Str("role", string(principal.Role))
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+		event.Msg("http request")
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func TestAccessLogSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		sample float64
+		logged bool
+	}{
+		{name: "success not sampled", path: "/events", status: http.StatusOK, sample: 0.9, logged: false},
+		{name: "success sampled", path: "/events", status: http.StatusOK, sample: 0.05, logged: true},
+		{name: "client error always", path: "/events", status: http.StatusBadRequest, sample: 0.9, logged: true},
+		{name: "server error always", path: "/events", status: http.StatusInternalServerError, sample: 0.9, logged: true},
+		{name: "always path", path: "/hikvision", status: http.StatusOK, sample: 0.9, logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := AccessLogOptions{SampleRate: 0.1, AlwaysPaths: []string{"/hikvision"}}
+			router := gin.New()
+			router.Use(accessLog(zerolog.New(&buf), opts, func() float64 { return tt.sample }))
+			router.GET(tt.path, func(c *gin.Context) {
+				c.Status(tt.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path+"?plate=123ABC02", nil)
+			req.Header.Set("User-Agent", "camera/1.0")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			line := buf.String()
+			if (line != "") != tt.logged {
+				t.Fatalf("logged = %v, want %v (%q)", line != "", tt.logged, line)
+			}
+			if tt.logged {
+				for _, field := range []string{`"path":"` + tt.path + `"`, `"user_agent":"camera/1.0"`, `"query":"plate=123ABC02"`, `"latency"`} {
+					if !strings.Contains(line, field) {
+						t.Errorf("log line %q has no %s", line, field)
+					}
+				}
+			}
+		})
+	}
+}
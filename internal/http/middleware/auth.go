@@ -46,6 +46,7 @@ func Auth(parser *auth.Parser) gin.HandlerFunc {
 
 		c.Set(claimsContextKey, claims)
 		c.Set(principalContextKey, principal)
+		c.Request = c.Request.WithContext(model.WithPrincipal(c.Request.Context(), principal))
 		c.Next()
 	}
 }
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestDeadlineHeader = "X-Request-Deadline"
+	grpcTimeoutHeader     = "Grpc-Timeout"
+)
+
+// RequestDeadline ограничивает обработку запроса сроком, переданным шлюзом: абсолютным
+// X-Request-Deadline (RFC3339 или unix-время в миллисекундах) или относительным grpc-timeout ("500m", "2S").
+// Срок попадает в контекст запроса и действует на всю цепочку (БД, R2, камеры), поэтому после таймаута
+// шлюза сервис не продолжает работу впустую. Если срок уже истёк, запрос сразу получает 504.
+func RequestDeadline() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deadline, ok, err := parseRequestDeadline(c.Request.Header, time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+		if !time.Now().Before(deadline) {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request deadline exceeded"})
+			return
+		}
+
+		ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// parseRequestDeadline возвращает более ранний из сроков, заданных заголовками
+func parseRequestDeadline(header http.Header, now time.Time) (time.Time, bool, error) {
+	var deadline time.Time
+	found := false
+
+	if value := strings.TrimSpace(header.Get(requestDeadlineHeader)); value != "" {
+		t, err := parseAbsoluteDeadline(value)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		deadline, found = t, true
+	}
+
+	if value := strings.TrimSpace(header.Get(grpcTimeoutHeader)); value != "" {
+		timeout, err := parseGRPCTimeout(value)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if t := now.Add(timeout); !found || t.Before(deadline) {
+			deadline, found = t, true
+		}
+	}
+
+	return deadline, found, nil
+}
+
+func parseAbsoluteDeadline(value string) (time.Time, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s header, use RFC3339 or unix milliseconds", requestDeadlineHeader)
+	}
+	return t, nil
+}
+
+// parseGRPCTimeout разбирает значение в формате gRPC: до 8 цифр и единица H, M, S, m, u или n
+func parseGRPCTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("invalid %s header", grpcTimeoutHeader)
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid %s header", grpcTimeoutHeader)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("invalid %s header unit", grpcTimeoutHeader)
+	}
+	return time.Duration(amount) * unit, nil
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRequestDeadline(t *testing.T) {
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Time
+		found   bool
+		wantErr bool
+	}{
+		{name: "no headers"},
+		{
+			name:    "rfc3339 deadline",
+			headers: map[string]string{"X-Request-Deadline": "2025-01-21T12:00:05Z"},
+			want:    now.Add(5 * time.Second),
+			found:   true,
+		},
+		{
+			name:    "unix millis deadline",
+			headers: map[string]string{"X-Request-Deadline": "1737460802000"},
+			want:    now.Add(2 * time.Second),
+			found:   true,
+		},
+		{
+			name:    "grpc timeout",
+			headers: map[string]string{"Grpc-Timeout": "1500m"},
+			want:    now.Add(1500 * time.Millisecond),
+			found:   true,
+		},
+		{
+			name: "earliest wins",
+			headers: map[string]string{
+				"X-Request-Deadline": "2025-01-21T12:00:05Z",
+				"Grpc-Timeout":       "1S",
+			},
+			want:  now.Add(time.Second),
+			found: true,
+		},
+		{name: "invalid unit", headers: map[string]string{"Grpc-Timeout": "10x"}, wantErr: true},
+		{name: "invalid deadline", headers: map[string]string{"X-Request-Deadline": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+			got, found, err := parseRequestDeadline(header, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if found != tt.found || !got.Equal(tt.want) {
+				t.Fatalf("got (%v, %v), want (%v, %v)", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
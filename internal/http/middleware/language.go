@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/i18n"
+)
+
+// Language выбирает язык ответа по Accept-Language (ru, kk/kz, en) и кладёт его в контекст запроса
+// (см. i18n.FromContext) — на нём формируются отчёты. Поле error JSON-ответов с ошибкой переводится
+// на выбранный язык, в том числе ошибки других middleware, поэтому Language стоит в цепочке раньше них.
+// Без заголовка ответы не меняются: ошибки остаются на английском, отчёты — на языке по умолчанию.
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		lang, ok := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		if !ok {
+			c.Next()
+			return
+		}
+		c.Header("Content-Language", string(lang))
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+		if lang == i18n.EN {
+			c.Next()
+			return
+		}
+
+		writer := &localizedErrorWriter{ResponseWriter: c.Writer, lang: lang}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.flushError()
+	}
+}
+
+// localizedErrorWriter придерживает тело JSON-ответа с ошибкой, чтобы перевести его после обработчика.
+// Остальные ответы пишутся напрямую.
+type localizedErrorWriter struct {
+	gin.ResponseWriter
+	lang i18n.Lang
+	body bytes.Buffer
+}
+
+func (w *localizedErrorWriter) Write(data []byte) (int, error) {
+	if w.holdsError() {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localizedErrorWriter) WriteString(s string) (int, error) {
+	if w.holdsError() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *localizedErrorWriter) holdsError() bool {
+	return w.body.Len() > 0 || (!w.ResponseWriter.Written() && w.Status() >= http.StatusBadRequest &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"))
+}
+
+// flushError переводит поле error придержанного ответа и отправляет его. Тело, которое не удалось
+// разобрать, отправляется как есть.
+func (w *localizedErrorWriter) flushError() {
+	if w.body.Len() == 0 {
+		return
+	}
+	data := w.body.Bytes()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err == nil {
+		var message string
+		if raw, ok := fields["error"]; ok && json.Unmarshal(raw, &message) == nil {
+			if translated, err := json.Marshal(i18n.Error(w.lang, message)); err == nil {
+				fields["error"] = translated
+				if localized, err := json.Marshal(fields); err == nil {
+					data = localized
+				}
+			}
+		}
+	}
+	_, _ = w.ResponseWriter.Write(data)
+}
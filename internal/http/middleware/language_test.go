@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/i18n"
+)
+
+func TestLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		header   string
+		path     string
+		wantLang string
+		wantBody string
+	}{
+		{name: "no header", path: "/fail", wantBody: `{"error":"invalid input: plate is required"}`},
+		{name: "russian error", header: "ru-RU,ru;q=0.9", path: "/fail", wantLang: "ru", wantBody: `{"error":"некорректные данные: обязательное поле plate"}`},
+		{name: "aborted by middleware", header: "kk", path: "/limited", wantLang: "kk", wantBody: `{"error":"сұраулар шегінен асты"}`},
+		{name: "english", header: "en-US", path: "/fail", wantLang: "en", wantBody: `{"error":"invalid input: plate is required"}`},
+		{name: "success untouched", header: "kz", path: "/ok", wantLang: "kk", wantBody: `{"data":"kk"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Language())
+			router.GET("/fail", func(c *gin.Context) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: plate is required"})
+			})
+			router.GET("/limited", func(c *gin.Context) {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			})
+			router.GET("/ok", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"data": i18n.FromContext(c.Request.Context())})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Fatalf("Content-Language = %q, want %q", got, tt.wantLang)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Fatalf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
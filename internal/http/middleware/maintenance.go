@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceMode — флаг режима обслуживания (только чтение) на время миграций схемы или переноса бакета R2
+type MaintenanceMode struct {
+	mu         sync.RWMutex
+	enabled    bool
+	reason     string
+	retryAfter time.Duration
+	since      *time.Time
+}
+
+// MaintenanceStatus — текущее состояние режима обслуживания
+type MaintenanceStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Reason            string     `json:"reason,omitempty"`
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+	Since             *time.Time `json:"since,omitempty"`
+}
+
+func NewMaintenanceMode(enabled bool, retryAfter time.Duration) *MaintenanceMode {
+	m := &MaintenanceMode{retryAfter: retryAfter}
+	if enabled {
+		now := time.Now()
+		m.enabled = true
+		m.since = &now
+	}
+	return m
+}
+
+// Set включает или выключает режим; retryAfter <= 0 оставляет прежнее значение
+func (m *MaintenanceMode) Set(enabled bool, reason string, retryAfter time.Duration) MaintenanceStatus {
+	m.mu.Lock()
+	if enabled && !m.enabled {
+		now := time.Now()
+		m.since = &now
+	}
+	if !enabled {
+		m.since = nil
+		reason = ""
+	}
+	m.enabled = enabled
+	m.reason = reason
+	if retryAfter > 0 {
+		m.retryAfter = retryAfter
+	}
+	m.mu.Unlock()
+	return m.Status()
+}
+
+func (m *MaintenanceMode) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return MaintenanceStatus{
+		Enabled:           m.enabled,
+		Reason:            m.reason,
+		RetryAfterSeconds: int(m.retryAfter.Seconds()),
+		Since:             m.since,
+	}
+}
+
+// Maintenance в режиме обслуживания отвечает 503 с Retry-After на все изменяющие запросы.
+// Чтение (GET/HEAD/OPTIONS) и пути из exempt (переключатель режима) продолжают работать.
+func Maintenance(m *MaintenanceMode, exempt ...string) gin.HandlerFunc {
+	exemptPaths := make(map[string]struct{}, len(exempt))
+	for _, path := range exempt {
+		exemptPaths[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if _, ok := exemptPaths[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		status := m.Status()
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(status.RetryAfterSeconds))
+		message := "service is in maintenance mode"
+		if status.Reason != "" {
+			message += ": " + status.Reason
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mode := NewMaintenanceMode(true, 2*time.Minute)
+
+	router := gin.New()
+	router.Use(Maintenance(mode, "/admin/maintenance"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/events", ok)
+	router.POST("/events", ok)
+	router.PUT("/admin/maintenance", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/events", http.StatusOK},
+		{http.MethodPost, "/events", http.StatusServiceUnavailable},
+		{http.MethodPut, "/admin/maintenance", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+		if w.Code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "120" {
+			t.Errorf("Retry-After = %q, want 120", w.Header().Get("Retry-After"))
+		}
+	}
+
+	mode.Set(false, "", 0)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/events", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("POST after disabling maintenance = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(60, 2) // 1 запрос в секунду, запас 2
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("cam-1"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+	ok, wait := l.Allow("cam-1")
+	if ok {
+		t.Fatal("request over burst was allowed")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Fatalf("wait = %s, want (0, 1s]", wait)
+	}
+	if ok, _ := l.Allow("cam-2"); !ok {
+		t.Fatal("other key must have its own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("cam-1"); !ok {
+		t.Fatal("bucket was not refilled after 1s")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := NewRateLimiter(0, 10)
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("ip"); !ok {
+			t.Fatal("disabled limiter must allow everything")
+		}
+	}
+}
+
+func TestRateLimitByIPRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimitByIP(NewRateLimiter(1, 1)))
+	router.POST("/events", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	codes := make([]int, 0, 2)
+	var retryAfter string
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", nil))
+		codes = append(codes, rec.Code)
+		retryAfter = rec.Header().Get("Retry-After")
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Fatalf("codes = %v, want [200 429]", codes)
+	}
+	if retryAfter == "" || retryAfter == "0" {
+		t.Fatalf("Retry-After = %q, want positive seconds", retryAfter)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MaxBodySize(8))
+	router.POST("/events", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader("0123456789")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("code = %d, want 413", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader("small")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"anpr-service/internal/logctx"
+)
+
+// RequestIDHeader — заголовок сквозного идентификатора запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength — более длинные входящие идентификаторы заменяются сгенерированными
+const maxRequestIDLength = 128
+
+// RequestID принимает X-Request-ID от клиента (камеры, шлюза) или генерирует новый, возвращает его
+// в ответе и кладёт в контекст запроса логгер с полем request_id
+func RequestID(log zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(RequestIDHeader))
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Set("request_id", id)
+
+		logContext := log.With().Str("request_id", id)
+		// Связываем строки лога с trace, а спан запроса — с X-Request-ID
+		if span := trace.SpanFromContext(c.Request.Context()); span.SpanContext().IsValid() {
+			logContext = logContext.Str("trace_id", span.SpanContext().TraceID().String())
+			span.SetAttributes(attribute.String("http.request_id", id))
+		}
+		logger := logContext.Logger()
+		ctx := logctx.WithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx))
+
+		c.Next()
+	}
+}
+
+// validRequestID допускает только печатные ASCII-символы, чтобы идентификатор нельзя было
+// использовать для подделки строк лога
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
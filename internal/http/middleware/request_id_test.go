@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/logctx"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "propagates incoming id", incoming: "cam-001-42", keep: true},
+		{name: "generates when missing"},
+		{name: "replaces id with spaces", incoming: "bad id\nforged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+			router := gin.New()
+			router.Use(RequestID(zerolog.Nop()))
+			router.GET("/", func(c *gin.Context) {
+				ctxID = logctx.RequestID(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(RequestIDHeader)
+			if got == "" {
+				t.Fatalf("response has no %s", RequestIDHeader)
+			}
+			if got != ctxID {
+				t.Fatalf("response id %q differs from context id %q", got, ctxID)
+			}
+			if tt.keep && got != tt.incoming {
+				t.Fatalf("id = %q, want incoming %q", got, tt.incoming)
+			}
+			if !tt.keep && got == tt.incoming {
+				t.Fatalf("invalid incoming id %q was kept", tt.incoming)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package http
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/openapi"
+	"anpr-service/internal/service"
+)
+
+//go:generate go run ../../cmd/openapi-gen -out openapi.json
+
+// openAPISpec — спецификация, сгенерированная по APIRoutes (go generate ./internal/http)
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Теги разделов спецификации
+const (
+	tagIngest        = "ingest"
+	tagEvents        = "events"
+	tagPlates        = "plates"
+	tagReports       = "reports"
+	tagLists         = "lists"
+	tagCameras       = "cameras"
+	tagPolygons      = "polygons"
+	tagContractors   = "contractors"
+	tagNotifications = "notifications"
+	tagWebhooks      = "webhooks"
+	tagAdmin         = "admin"
+	tagHealth        = "health"
+	tagInternal      = "internal"
+)
+
+// Тела, которые обработчики не разбирают в структуры: описаны только для спецификации
+type (
+	// eventMultipartForm — событие с фото: JSON события в поле event и файлы в photos
+	eventMultipartForm struct {
+		Event  string         `json:"event" binding:"required"`
+		Photos []openapi.File `json:"photos"`
+	}
+	// hikvisionMultipartForm — уведомление камеры Hikvision: XML EventNotificationAlert и снимки.
+	// Имена частей камера выбирает сама, XML определяется по расширению или Content-Type.
+	hikvisionMultipartForm struct {
+		AnprXML openapi.File   `json:"anpr.xml"`
+		Images  []openapi.File `json:"licensePlatePicture.jpg"`
+	}
+	// listImportForm — файл импорта списка: CSV (разделитель «,» или «;», UTF-8) или XLSX
+	listImportForm struct {
+		File openapi.File `json:"file" binding:"required"`
+	}
+	statusResponse struct {
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+	}
+	deletedResponse struct {
+		Deleted bool `json:"deleted"`
+	}
+	queuedResponse struct {
+		Queued bool `json:"queued"`
+	}
+	restoredResponse struct {
+		Restored bool `json:"restored"`
+	}
+)
+
+// Повторяющиеся параметры строки запроса
+var (
+	paramLimit        = openapi.Param{Name: "limit", Type: "integer"}
+	paramOffset       = openapi.Param{Name: "offset", Type: "integer"}
+	paramFrom         = openapi.Param{Name: "from", Format: "date-time", Description: "Начало периода (RFC3339)"}
+	paramTo           = openapi.Param{Name: "to", Format: "date-time", Description: "Конец периода (RFC3339)"}
+	paramPlate        = openapi.Param{Name: "plate", Description: "Номер или его часть"}
+	paramContractorID = openapi.Param{Name: "contractor_id", Format: "uuid"}
+	paramPolygonID    = openapi.Param{Name: "polygon_id", Format: "uuid"}
+	paramVehicleID    = openapi.Param{Name: "vehicle_id", Format: "uuid"}
+	paramVehicleType  = openapi.Param{Name: "vehicle_type"}
+	paramSource       = openapi.Param{Name: "source", Description: "Источники через запятую: camera, import, manual, simulator"}
+	paramIncludeLate  = openapi.Param{Name: "include_late", Type: "boolean", Description: "Учитывать события, пришедшие позже EVENT_MAX_AGE"}
+	reportParams      = []openapi.Param{paramFrom, paramTo, paramContractorID, paramPolygonID, paramVehicleID, paramVehicleType, paramSource, paramPlate, paramIncludeLate}
+)
+
+// APIRoutes описывает маршруты сервиса для спецификации OpenAPI. Маршрут, добавленный в Register или
+// NewRouter, добавляется и сюда (это проверяет тест), после чего спецификация перегенерируется:
+// go generate ./internal/http
+func APIRoutes() []openapi.Route {
+	reportFilters := append([]openapi.Param{}, reportParams...)
+	exportFilters := append(append([]openapi.Param{}, reportParams...), openapi.Param{Name: "wrong_destination", Type: "boolean"})
+	trafficFilters := []openapi.Param{
+		{Name: "from", Format: "date-time"},
+		{Name: "to", Format: "date-time"},
+		{Name: "camera_id"},
+		paramPolygonID,
+	}
+
+	return []openapi.Route{
+		// Приём событий
+		{Method: http.MethodPost, Path: "/api/v1/anpr/events", Tag: tagIngest, Summary: "Событие распознавания номера (JSON)",
+			Request: anpr.EventPayload{}, Response: eventCreatedResponse{}, RawResponse: true, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/api/v1/anpr/hikvision", Tag: tagIngest, Summary: "Уведомление камеры Hikvision (multipart, XML или JSON); heartbeat и videoloss — 200 ResponseStatus",
+			Request: hikvisionMultipartForm{}, RequestContentType: "multipart/form-data",
+			Response: eventCreatedResponse{}, RawResponse: true, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/anpr/hikvision", Tag: tagIngest, Summary: "Проверка доступности эндпоинта камерой",
+			Response: statusResponse{}, RawResponse: true},
+		{Method: http.MethodPost, Path: "/api/v2/anpr/events", Tag: tagIngest, Summary: "Событие распознавания номера (JSON), ответ v2",
+			Request: anpr.EventPayload{}, Response: eventResultV2{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/api/v2/anpr/hikvision", Tag: tagIngest, Summary: "Уведомление камеры Hikvision, ответ v2",
+			Request: hikvisionMultipartForm{}, RequestContentType: "multipart/form-data",
+			Response: eventResultV2{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/anpr/hikvision", Tag: tagIngest, Summary: "Проверка доступности эндпоинта камерой",
+			Response: statusResponse{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/api/v1/camera/status", Tag: tagCameras, Summary: "Доступность камеры из конфигурации",
+			Response: map[string]any{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/api/v1/photos/*key", Tag: tagEvents, Summary: "Фото из локального хранилища (STORAGE_BACKEND=local)",
+			ResponseContentType: "image/jpeg"},
+		{Method: http.MethodGet, Path: "/api/v1/openapi.json", Tag: tagAdmin, Summary: "Эта спецификация",
+			Response: map[string]any{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/api/v1/docs", Tag: tagAdmin, Summary: "Swagger UI (кроме APP_ENV=production)",
+			ResponseContentType: "text/html"},
+
+		// Номера
+		{Method: http.MethodGet, Path: "/api/v1/plates", Tag: tagPlates, Summary: "Поиск номеров (ETag, If-None-Match)", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramPlate}, Response: []service.PlateInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/plates/unmatched", Tag: tagPlates, Summary: "Номера без сопоставленного транспорта", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramFrom, paramTo, paramLimit, paramOffset}, Response: []service.UnmatchedPlateInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/plates/unmatched/:id/review", Tag: tagPlates, Summary: "Решение по несопоставленному номеру", Auth: openapi.AuthBearer,
+			Request: unmatchedReviewRequest{}, Response: service.UnmatchedReviewResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/plates/trip-quotas", Tag: tagPlates, Summary: "Квоты оплачиваемых рейсов номеров", Auth: openapi.AuthBearer,
+			Response: []service.PlateTripQuotaInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/plates/trip-quotas/:plate", Tag: tagPlates, Summary: "Квота оплачиваемых рейсов номера", Auth: openapi.AuthBearer,
+			Request: plateTripQuotaRequest{}, Response: service.PlateTripQuotaInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/plates/trip-quotas/:plate", Tag: tagPlates, Summary: "Снятие квоты рейсов номера", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/plates/:id/timeline", Tag: tagPlates, Summary: "Хронология номера", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramFrom, paramTo}, Response: service.PlateTimeline{}},
+		{Method: http.MethodPost, Path: "/api/v1/plates/:id/merge", Tag: tagPlates, Summary: "Объединение номеров", Auth: openapi.AuthBearer,
+			Request: mergePlatesRequest{}, Response: service.PlateMergeInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/plates/:id/aliases", Tag: tagPlates, Summary: "Псевдонимы номера", Auth: openapi.AuthBearer,
+			Response: []service.PlateAliasInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/plates/:id/aliases", Tag: tagPlates, Summary: "Добавление псевдонима", Auth: openapi.AuthBearer,
+			Request: plateAliasRequest{}, Response: service.PlateAliasInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/api/v1/plates/:id/aliases/:alias", Tag: tagPlates, Summary: "Удаление псевдонима", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+
+		// События
+		{Method: http.MethodGet, Path: "/api/v1/events", Tag: tagEvents, Summary: "Поиск событий", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramPlate, paramFrom, paramTo,
+				{Name: "time_field", Description: "event_time (по умолчанию) или received_at"},
+				{Name: "direction"}, paramVehicleType, paramSource, paramPolygonID, paramLimit, paramOffset},
+			Response: []service.EventInfo{}},
+		{Method: http.MethodHead, Path: "/api/v1/events", Tag: tagEvents, Summary: "Версия данных событий (X-Data-Version)", Auth: openapi.AuthBearer},
+		{Method: http.MethodGet, Path: "/api/v1/events/stream.ndjson", Tag: tagEvents, Summary: "События за период потоком (NDJSON, строка на событие)", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramFrom, paramTo, paramPlate,
+				{Name: "time_field", Description: "event_time (по умолчанию) или received_at"},
+				{Name: "direction"}, paramVehicleType, paramSource, paramPolygonID},
+			Response: service.DataLakeEvent{}, RawResponse: true, ResponseContentType: "application/x-ndjson"},
+		{Method: http.MethodGet, Path: "/api/v1/events/:id", Tag: tagEvents, Summary: "Событие с фото, комментариями и исходными данными камеры", Auth: openapi.AuthBearer,
+			Response: service.EventInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/events/:id/comments", Tag: tagEvents, Summary: "Комментарии к событию", Auth: openapi.AuthBearer,
+			Response: []service.EventCommentInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/events/:id/comments", Tag: tagEvents, Summary: "Комментарий оператора к событию", Auth: openapi.AuthBearer,
+			Request: eventCommentRequest{}, Response: service.EventCommentInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/trips/:id/evidence", Tag: tagEvents, Summary: "ZIP-пакет доказательств рейса: события, фото и уведомления камеры обоих проездов", Auth: openapi.AuthBearer,
+			ResponseContentType: "application/zip"},
+		{Method: http.MethodPost, Path: "/api/v1/anpr/sync-vehicle", Tag: tagLists, Summary: "Добавление номера в белый список", Auth: openapi.AuthBearer,
+			Request: syncVehicleRequest{}, Response: syncVehicleResponse{}, RawResponse: true},
+		{Method: http.MethodDelete, Path: "/api/v1/anpr/sync-vehicle", Tag: tagLists, Summary: "Удаление номера деактивированного транспорта из белого списка", Auth: openapi.AuthBearer,
+			Request: syncVehicleRequest{}, Response: removeVehicleResponse{}},
+		{Method: http.MethodDelete, Path: "/api/v1/anpr/events/old", Tag: tagEvents, Summary: "Удаление старых событий", Auth: openapi.AuthBearer,
+			Request: deleteOldEventsRequest{}, Response: deleteEventsResponse{}, RawResponse: true},
+		{Method: http.MethodDelete, Path: "/api/v1/anpr/events/all", Tag: tagEvents, Summary: "Удаление всех событий", Auth: openapi.AuthBearer,
+			Request: deleteAllEventsRequest{}, Response: deleteEventsResponse{}, RawResponse: true},
+
+		// Отчёты
+		{Method: http.MethodGet, Path: "/api/v1/reports", Tag: tagReports, Summary: "Отчёт по вывозу снега", Auth: openapi.AuthBearer,
+			Query:    append(exportFilters, paramLimit, paramOffset),
+			Response: service.ReportResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/hourly-activity", Tag: tagReports, Summary: "Активность по часам", Auth: openapi.AuthBearer,
+			Query: reportFilters, Response: service.HourlyActivityResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/comparison", Tag: tagReports, Summary: "Сравнение с предыдущим периодом", Auth: openapi.AuthBearer,
+			Query: append(reportFilters,
+				openapi.Param{Name: "mode"},
+				openapi.Param{Name: "previous_from", Format: "date-time"},
+				openapi.Param{Name: "previous_to", Format: "date-time"}),
+			Response: service.ReportComparisonResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/shifts", Tag: tagReports, Summary: "Рейсы по сменам календаря смен", Auth: openapi.AuthBearer,
+			Query: exportFilters, Response: service.ShiftReport{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/speed", Tag: tagReports, Summary: "Средняя скорость и перцентили по камерам", Auth: openapi.AuthBearer,
+			Query: trafficFilters, Response: service.SpeedReport{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/lanes", Tag: tagReports, Summary: "Загрузка полос камер по часам или дням", Auth: openapi.AuthBearer,
+			Query: append(trafficFilters, openapi.Param{Name: "interval", Description: "hour (по умолчанию) или day"}), Response: service.LaneUsageReport{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/confidence", Tag: tagReports, Summary: "Уверенность распознавания камер против ручных исправлений номеров", Auth: openapi.AuthBearer,
+			Query:    append(trafficFilters, openapi.Param{Name: "max_error_rate", Type: "number", Description: "допустимая доля исправлений выше предлагаемого порога (по умолчанию 0.02)"}),
+			Response: service.ConfidenceReport{}},
+		{Method: http.MethodGet, Path: "/api/v1/stats/hourly", Tag: tagReports, Summary: "Число событий камер по часам (почасовые счётчики)", Auth: openapi.AuthBearer,
+			Query: trafficFilters, Response: service.HourlyStats{}},
+		{Method: http.MethodGet, Path: "/api/v1/reports/excel", Tag: tagReports, Summary: "Отчёт в Excel", Auth: openapi.AuthBearer,
+			Query: exportFilters, ResponseContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{Method: http.MethodGet, Path: "/api/v1/reports/anonymized", Tag: tagReports, Summary: "Обезличенный набор данных (CSV или JSON)", Auth: openapi.AuthBearer,
+			Query: append(exportFilters, openapi.Param{Name: "format", Description: "csv (по умолчанию) или json"}), ResponseContentType: "text/csv"},
+		{Method: http.MethodGet, Path: "/api/v1/billing/periods/:period/export", Tag: tagReports, Summary: "Подписанная ведомость оплаты рейсов за месяц (CSV или JSON)", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{{Name: "format", Description: "csv (по умолчанию) или json"}}, ResponseContentType: "text/csv"},
+		{Method: http.MethodPost, Path: "/api/v1/billing/periods/:period/lock", Tag: tagReports, Summary: "Блокировка ведомости после выставления счёта", Auth: openapi.AuthBearer,
+			Response: service.BillingStatement{}},
+		{Method: http.MethodGet, Path: "/api/v1/anomalies/photo-duplicates", Tag: tagEvents, Summary: "Повторно присланные фото", Auth: openapi.AuthBearer,
+			Query:    []openapi.Param{paramFrom, paramTo, {Name: "camera_id"}, {Name: "exact", Type: "boolean"}, paramLimit, paramOffset},
+			Response: []service.PhotoDuplicateInfo{}},
+
+		// Списки
+		{Method: http.MethodGet, Path: "/api/v1/lists", Tag: tagLists, Summary: "Списки номеров (ETag, If-None-Match)", Auth: openapi.AuthBearer,
+			Response: []service.ListInfo{}},
+		{Method: http.MethodHead, Path: "/api/v1/lists", Tag: tagLists, Summary: "Версия данных списков (X-Data-Version, ETag)", Auth: openapi.AuthBearer},
+		{Method: http.MethodGet, Path: "/api/v1/lists/:id/items", Tag: tagLists, Summary: "Номера списка (ETag, If-None-Match)", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramLimit, paramOffset}, Response: []service.ListEntryInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/lists/:id/items", Tag: tagLists, Summary: "Добавление номера в список (в том числе временное)", Auth: openapi.AuthBearer,
+			Request: listEntryRequest{}, Response: service.ListEntryInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/v1/lists/:id/items/:plate_id", Tag: tagLists, Summary: "Срок действия записи списка", Auth: openapi.AuthBearer,
+			Request: listEntryValidityRequest{}, Response: service.ListEntryInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/lists/:id/items/:plate_id", Tag: tagLists, Summary: "Удаление номера из списка", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/lists/:id/export", Tag: tagLists, Summary: "Выгрузка списка файлом (CSV или XLSX)", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{{Name: "format", Description: "csv (по умолчанию) или xlsx"}}, ResponseContentType: "text/csv"},
+		{Method: http.MethodPost, Path: "/api/v1/lists/:id/import", Tag: tagLists, Summary: "Загрузка номеров в список из CSV или XLSX с отчётом проверки строк", Auth: openapi.AuthBearer,
+			Query:   []openapi.Param{{Name: "dry_run", Type: "boolean", Description: "Только проверить файл, не меняя список"}},
+			Request: listImportForm{}, RequestContentType: "multipart/form-data", Response: service.ListImportReport{}},
+
+		// Полигоны
+		{Method: http.MethodGet, Path: "/api/v1/polygons", Tag: tagPolygons, Summary: "Полигоны", Auth: openapi.AuthBearer,
+			Response: []service.PolygonInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/polygons", Tag: tagPolygons, Summary: "Создание полигона", Auth: openapi.AuthBearer,
+			Request: polygonRequest{}, Response: service.PolygonInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/v1/polygons/:id", Tag: tagPolygons, Summary: "Изменение полигона", Auth: openapi.AuthBearer,
+			Request: polygonRequest{}, Response: service.PolygonInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/polygons/:id", Tag: tagPolygons, Summary: "Удаление полигона", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/polygons/modes", Tag: tagPolygons, Summary: "Текущие режимы работы полигонов (normal/storm)", Auth: openapi.AuthBearer,
+			Response: []service.PolygonModeInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/polygons/:id/mode", Tag: tagPolygons, Summary: "Переключение режима работы полигона", Auth: openapi.AuthBearer,
+			Request: polygonModeRequest{}, Response: service.PolygonModeInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/polygons/:id/mode/history", Tag: tagPolygons, Summary: "Журнал переключений режима полигона", Auth: openapi.AuthBearer,
+			Response: []service.PolygonModeInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/shifts", Tag: tagPolygons, Summary: "Календарь смен", Auth: openapi.AuthBearer,
+			Response: []service.ShiftInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/shifts", Tag: tagPolygons, Summary: "Создание смены", Auth: openapi.AuthBearer,
+			Request: shiftRequest{}, Response: service.ShiftInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/v1/shifts/:id", Tag: tagPolygons, Summary: "Изменение смены", Auth: openapi.AuthBearer,
+			Request: shiftRequest{}, Response: service.ShiftInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/shifts/:id", Tag: tagPolygons, Summary: "Удаление смены", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+
+		// Камеры
+		{Method: http.MethodGet, Path: "/api/v1/cameras", Tag: tagCameras, Summary: "Реестр камер", Auth: openapi.AuthBearer,
+			Response: []service.CameraInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/cameras/geojson", Tag: tagCameras, Summary: "Камеры на карте (GeoJSON)", Auth: openapi.AuthBearer,
+			Response: service.CameraFeatureCollection{}, RawResponse: true, ResponseContentType: "application/geo+json"},
+		{Method: http.MethodPut, Path: "/api/v1/cameras/:id", Tag: tagCameras, Summary: "Изменение камеры", Auth: openapi.AuthBearer,
+			Request: cameraUpdateRequest{}, Response: service.CameraInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/cameras/:id/snapshot", Tag: tagCameras, Summary: "Снимок с камеры", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{{Name: "channel", Type: "integer"}}, Response: cameraSnapshotResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/cameras/:id/whitelist/sync", Tag: tagCameras, Summary: "Выгрузка белого списка в камеру", Auth: openapi.AuthBearer,
+			Response: service.WhitelistSyncResult{}},
+
+		// Подрядчики
+		{Method: http.MethodGet, Path: "/api/v1/contractors/access-rules", Tag: tagContractors, Summary: "Правила доступа подрядчиков", Auth: openapi.AuthBearer,
+			Response: []service.ContractorAccessRuleInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/contractors/:id/access-rules", Tag: tagContractors, Summary: "Изменение правила доступа", Auth: openapi.AuthBearer,
+			Request: accessRuleRequest{}, Response: service.ContractorAccessRuleInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/contractors/polygons", Tag: tagContractors, Summary: "Закрепление подрядчиков за полигонами", Auth: openapi.AuthBearer,
+			Response: []service.ContractorPolygonsInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/contractors/:id/polygons", Tag: tagContractors, Summary: "Закрепление подрядчика за полигонами", Auth: openapi.AuthBearer,
+			Request: contractorPolygonsRequest{}, Response: service.ContractorPolygonsInfo{}},
+
+		// Администрирование
+		{Method: http.MethodGet, Path: "/api/v1/admin/summary", Tag: tagAdmin, Summary: "Сводка состояния сервиса", Auth: openapi.AuthBearer,
+			Response: adminSummaryResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/maintenance", Tag: tagAdmin, Summary: "Режим обслуживания", Auth: openapi.AuthBearer,
+			Response: middleware.MaintenanceStatus{}},
+		{Method: http.MethodPut, Path: "/api/v1/admin/maintenance", Tag: tagAdmin, Summary: "Переключение режима обслуживания", Auth: openapi.AuthBearer,
+			Request: maintenanceRequest{}, Response: middleware.MaintenanceStatus{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/events/:id/restore", Tag: tagAdmin, Summary: "Восстановление удалённого события", Auth: openapi.AuthBearer,
+			Response: restoredResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/events/reprocess", Tag: tagAdmin, Summary: "Повторный разбор XML камер событий периода", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{
+				{Name: "from", Format: "date-time", Description: "Начало периода (RFC3339)", Required: true},
+				{Name: "to", Format: "date-time", Description: "Конец периода (RFC3339), не включается", Required: true},
+				{Name: "dry_run", Type: "boolean", Description: "Только показать изменения, не сохраняя их"},
+			},
+			Response: service.ReprocessResult{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/config", Tag: tagAdmin, Summary: "Действующая конфигурация (секреты скрыты)", Auth: openapi.AuthBearer,
+			Response: service.ConfigInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/jobs", Tag: tagAdmin, Summary: "Периодические задачи и их последний запуск", Auth: openapi.AuthBearer,
+			Response: []service.JobInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/jobs/:name/runs", Tag: tagAdmin, Summary: "История запусков задачи", Auth: openapi.AuthBearer,
+			Query:    []openapi.Param{paramLimit},
+			Response: []service.JobRunInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/jobs/:name/run", Tag: tagAdmin, Summary: "Запуск задачи вручную", Auth: openapi.AuthBearer,
+			Status: http.StatusAccepted, Response: service.JobRunInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/usage", Tag: tagAdmin, Summary: "Использование сервиса по организациям и дням", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{
+				{Name: "from", Format: "date", Description: "Первый день (YYYY-MM-DD), по умолчанию — 30 дней до to"},
+				{Name: "to", Format: "date", Description: "Последний день включительно (YYYY-MM-DD), по умолчанию — сегодня"},
+				{Name: "organization_id", Format: "uuid", Description: "Только одна организация"},
+			},
+			Response: []service.UsageInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/slo", Tag: tagAdmin, Summary: "Соблюдение SLO задержки приёма событий камер за сутки", Auth: openapi.AuthBearer,
+			Response: service.IngestSLOReport{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/feature-flags", Tag: tagAdmin, Summary: "Флаги возможностей и их переопределения", Auth: openapi.AuthBearer,
+			Response: []service.FeatureFlagInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/admin/feature-flags/:flag", Tag: tagAdmin, Summary: "Переопределение флага для всех, организации или камеры", Auth: openapi.AuthBearer,
+			Request: featureFlagOverrideRequest{}, Response: service.FeatureFlagInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/feature-flags/:flag", Tag: tagAdmin, Summary: "Снятие переопределения флага", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{
+				{Name: "scope", Description: "global, organization или camera", Required: true},
+				{Name: "scope_id", Description: "id организации или камеры, для global — пусто"},
+			},
+			Response: service.FeatureFlagInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/dead-letters", Tag: tagAdmin, Summary: "Непринятые уведомления камер", Auth: openapi.AuthBearer,
+			Query:    []openapi.Param{{Name: "pending", Type: "boolean", Description: "Только не обработанные повторно"}, paramLimit, paramOffset},
+			Response: []service.DeadLetterInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/dead-letters/:id/payload", Tag: tagAdmin, Summary: "Тело непринятого уведомления", Auth: openapi.AuthBearer,
+			ResponseContentType: "application/octet-stream"},
+		{Method: http.MethodPost, Path: "/api/v1/admin/dead-letters/:id/replay", Tag: tagAdmin, Summary: "Повторная обработка непринятого уведомления", Auth: openapi.AuthBearer,
+			Response: service.DeadLetterReplayResult{}},
+
+		// Уведомления
+		{Method: http.MethodGet, Path: "/api/v1/notifications/nightly-summary", Tag: tagNotifications, Summary: "Подписка на ночную сводку", Auth: openapi.AuthBearer,
+			Response: service.SummarySubscriptionInfo{}},
+		{Method: http.MethodPut, Path: "/api/v1/notifications/nightly-summary", Tag: tagNotifications, Summary: "Изменение подписки на ночную сводку", Auth: openapi.AuthBearer,
+			Request: summarySubscriptionRequest{}, Response: service.SummarySubscriptionInfo{}},
+		{Method: http.MethodGet, Path: "/api/v1/watches", Tag: tagNotifications, Summary: "Отслеживаемые номера пользователя", Auth: openapi.AuthBearer,
+			Response: []service.PlateWatchInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/watches", Tag: tagNotifications, Summary: "Отслеживание номера", Auth: openapi.AuthBearer,
+			Request: plateWatchRequest{}, Response: service.PlateWatchInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/api/v1/watches/:id", Tag: tagNotifications, Summary: "Удаление отслеживания", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/saved-searches", Tag: tagNotifications, Summary: "Сохранённые поиски событий пользователя", Auth: openapi.AuthBearer,
+			Response: []service.SavedSearchInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/saved-searches", Tag: tagNotifications, Summary: "Сохранение поиска и расписания выгрузки", Auth: openapi.AuthBearer,
+			Request: savedSearchRequest{}, Response: service.SavedSearchInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/v1/saved-searches/:id", Tag: tagNotifications, Summary: "Изменение сохранённого поиска", Auth: openapi.AuthBearer,
+			Request: savedSearchRequest{}, Response: service.SavedSearchInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/saved-searches/:id", Tag: tagNotifications, Summary: "Удаление сохранённого поиска", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/saved-searches/:id/export", Tag: tagNotifications, Summary: "Выгрузка сохранённого поиска в CSV", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{paramFrom, paramTo}, ResponseContentType: "text/csv"},
+
+		// Вебхуки
+		{Method: http.MethodGet, Path: "/api/v1/webhooks", Tag: tagWebhooks, Summary: "Подписки на вебхуки", Auth: openapi.AuthBearer,
+			Response: []service.WebhookSubscriptionInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks", Tag: tagWebhooks, Summary: "Создание подписки", Auth: openapi.AuthBearer,
+			Request: webhookRequest{}, Response: service.WebhookSubscriptionInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/v1/webhooks/:id", Tag: tagWebhooks, Summary: "Изменение подписки", Auth: openapi.AuthBearer,
+			Request: webhookRequest{}, Response: service.WebhookSubscriptionInfo{}},
+		{Method: http.MethodDelete, Path: "/api/v1/webhooks/:id", Tag: tagWebhooks, Summary: "Удаление подписки", Auth: openapi.AuthBearer,
+			Response: deletedResponse{}},
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Tag: tagWebhooks, Summary: "Доставки подписки", Auth: openapi.AuthBearer,
+			Query: []openapi.Param{{Name: "status"}, paramLimit, paramOffset}, Response: []service.WebhookDeliveryInfo{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks/:id/deliveries/:delivery_id/retry", Tag: tagWebhooks, Summary: "Повтор доставки", Auth: openapi.AuthBearer,
+			Response: queuedResponse{}},
+
+		// Межсервисные вызовы
+		{Method: http.MethodGet, Path: "/internal/anpr/events", Tag: tagInternal, Summary: "События номера за период", Auth: openapi.AuthInternal,
+			Query: []openapi.Param{{Name: "plate", Required: true}, {Name: "start_time", Format: "date-time", Required: true},
+				{Name: "end_time", Format: "date-time", Required: true}, {Name: "direction"}},
+			Response: []service.EventInfo{}},
+		{Method: http.MethodGet, Path: "/internal/maintenance", Tag: tagInternal, Summary: "Режим обслуживания", Auth: openapi.AuthInternal,
+			Response: middleware.MaintenanceStatus{}},
+		{Method: http.MethodPut, Path: "/internal/maintenance", Tag: tagInternal, Summary: "Переключение режима обслуживания", Auth: openapi.AuthInternal,
+			Request: maintenanceRequest{}, Response: middleware.MaintenanceStatus{}},
+		{Method: http.MethodGet, Path: "/internal/metrics", Tag: tagInternal, Summary: "Метрики в формате Prometheus", Auth: openapi.AuthInternal,
+			ResponseContentType: "text/plain"},
+
+		// Проверки здоровья
+		{Method: http.MethodGet, Path: "/health/live", Tag: tagHealth, Summary: "Процесс жив", Response: statusResponse{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/health/ready", Tag: tagHealth, Summary: "Готовность (доступна БД)", Response: statusResponse{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/health/full", Tag: tagHealth, Summary: "БД, хранилище фото и камеры", Response: map[string]any{}, RawResponse: true},
+		{Method: http.MethodGet, Path: "/version", Tag: tagHealth, Summary: "Сборка, версия схемы БД и включённые возможности", Response: versionResponse{}, RawResponse: true},
+	}
+}
+
+// OpenAPISpec строит спецификацию по APIRoutes в том виде, в каком она хранится в openapi.json
+func OpenAPISpec() ([]byte, error) {
+	doc := openapi.Build(openapi.Info{
+		Title:       "ANPR Service API",
+		Description: "Приём событий распознавания номеров, отчёты по вывозу снега и справочники полигонов.",
+		Version:     "v1",
+	}, APIRoutes())
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *Handler) getOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// swaggerUIPage — Swagger UI с CDN (статику сервис не раздаёт); подключается только вне production
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>ANPR Service API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+func (h *Handler) getSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/config"
+)
+
+func TestOpenAPISpecUpToDate(t *testing.T) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		t.Fatalf("OpenAPISpec() error = %v", err)
+	}
+	if !bytes.Equal(spec, openAPISpec) {
+		t.Fatal("internal/http/openapi.json is out of date: run go generate ./internal/http")
+	}
+}
+
+// TestAPIRoutesMatchRouter — спецификация описывает ровно те маршруты, что зарегистрированы в роутере
+func TestAPIRoutesMatchRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &Handler{config: &config.Config{}}
+	router := NewRouter(handler, func(c *gin.Context) { c.Next() }, "development", nil)
+
+	documented := map[string]bool{}
+	for _, route := range APIRoutes() {
+		documented[route.Method+" "+route.Path] = true
+	}
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		if route.Method == http.MethodOptions || strings.HasPrefix(route.Path, "/debug/") {
+			continue
+		}
+		registered[route.Method+" "+route.Path] = true
+		if !documented[route.Method+" "+route.Path] {
+			t.Errorf("route %s %s is not described in APIRoutes", route.Method, route.Path)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf("APIRoutes describes %s, which is not registered", route)
+		}
+	}
+}
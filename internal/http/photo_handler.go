@@ -0,0 +1,39 @@
+package http
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getStoredPhoto отдаёт фото из локального хранилища (STORAGE_BACKEND=local). Ссылки на фото публичные,
+// как и ссылки на объекты бакета, поэтому авторизация не требуется.
+func (h *Handler) getStoredPhoto(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !h.photoStore.ServesObjects() || key == "" {
+		c.JSON(http.StatusNotFound, errorResponse("photo not found"))
+		return
+	}
+
+	body, size, contentType, err := h.photoStore.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.JSON(http.StatusNotFound, errorResponse("photo not found"))
+			return
+		}
+		h.logger(c.Request.Context()).Error().Err(err).Str("key", key).Msg("failed to open stored photo")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return
+	}
+	defer body.Close()
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// Ключ фото включает идентификатор события, содержимое по ключу не меняется
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.DataFromReader(http.StatusOK, size, contentType, body, nil)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// mergePlatesRequest — номер, события которого переносятся на номер из пути
+type mergePlatesRequest struct {
+	SourcePlateID string  `json:"source_plate_id" binding:"required"`
+	Note          *string `json:"note"`
+}
+
+// plateAliasRequest — псевдоним номера (вариант распознавания)
+type plateAliasRequest struct {
+	Alias string  `json:"alias" binding:"required"`
+	Note  *string `json:"note"`
+}
+
+func (h *Handler) mergePlates(c *gin.Context) {
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	var req mergePlatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	sourceID, err := uuid.Parse(req.SourcePlateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid source_plate_id"))
+		return
+	}
+
+	result, err := h.anprService.MergePlates(c.Request.Context(), plateID, sourceID, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
+
+func (h *Handler) listPlateAliases(c *gin.Context) {
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	aliases, err := h.anprService.ListPlateAliases(c.Request.Context(), plateID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(aliases))
+}
+
+func (h *Handler) createPlateAlias(c *gin.Context) {
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	var req plateAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	alias, err := h.anprService.AddPlateAlias(c.Request.Context(), plateID, req.Alias, req.Note)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(alias))
+}
+
+func (h *Handler) deletePlateAlias(c *gin.Context) {
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	if err := h.anprService.DeletePlateAlias(c.Request.Context(), plateID, c.Param("alias")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+// polygonRequest — тело создания/изменения полигона. Пустой organization_id отвязывает организацию.
+type polygonRequest struct {
+	Name           *string  `json:"name"`
+	OrganizationID *string  `json:"organization_id"`
+	Latitude       *float64 `json:"latitude"`
+	Longitude      *float64 `json:"longitude"`
+}
+
+func (r polygonRequest) input() service.PolygonInput {
+	return service.PolygonInput{
+		Name:           r.Name,
+		OrganizationID: r.OrganizationID,
+		Latitude:       r.Latitude,
+		Longitude:      r.Longitude,
+	}
+}
+
+func (h *Handler) listPolygons(c *gin.Context) {
+	polygons, err := h.anprService.ListPolygons(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(polygons))
+}
+
+func (h *Handler) createPolygon(c *gin.Context) {
+	var req polygonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	polygon, err := h.anprService.CreatePolygon(c.Request.Context(), req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(polygon))
+}
+
+func (h *Handler) updatePolygon(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid polygon id"))
+		return
+	}
+	var req polygonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	polygon, err := h.anprService.UpdatePolygon(c.Request.Context(), id, req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(polygon))
+}
+
+func (h *Handler) deletePolygon(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid polygon id"))
+		return
+	}
+	if err := h.anprService.DeletePolygon(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+// polygonModeRequest — переключение режима полигона (normal/storm) с основанием
+type polygonModeRequest struct {
+	Mode   string `json:"mode" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+func (h *Handler) listPolygonModes(c *gin.Context) {
+	modes, err := h.anprService.ListPolygonModes(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(modes))
+}
+
+func (h *Handler) setPolygonMode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid polygon id"))
+		return
+	}
+	var req polygonModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	mode, err := h.anprService.SetPolygonMode(c.Request.Context(), id, service.PolygonModeInput{Mode: req.Mode, Reason: req.Reason})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(mode))
+}
+
+func (h *Handler) listPolygonModeHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid polygon id"))
+		return
+	}
+	history, err := h.anprService.ListPolygonModeHistory(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(history))
+}
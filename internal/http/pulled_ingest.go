@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"anpr-service/internal/ftpingest"
+	"anpr-service/internal/hikconnect"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/service"
+)
+
+// pulledNotification — уведомление Hikvision, которое сервис забрал сам (FTP, Hik-Connect), а не получил по HTTP
+type pulledNotification struct {
+	// Source и Name — откуда уведомление (для логов и dead letters)
+	Source string
+	Name   string
+	// CameraID — камера уведомления без channelID/deviceID
+	CameraID     string
+	Notification []byte
+	Photos       []ingest.Photo
+}
+
+// IngestFTPUpload принимает выгрузку камеры по FTP (ftpingest.Sink). Камерой без channelID/deviceID
+// в уведомлении считается каталог выгрузки.
+func (h *Handler) IngestFTPUpload(ctx context.Context, upload ftpingest.Upload) error {
+	photos := make([]ingest.Photo, 0, len(upload.Photos))
+	for _, file := range upload.Photos {
+		photos = append(photos, ingest.Photo{Name: file.Name, Data: file.Data})
+	}
+	return h.ingestPulledNotification(ctx, pulledNotification{
+		Source:       "ftp",
+		Name:         upload.Name,
+		CameraID:     upload.CameraID,
+		Notification: upload.Notification,
+		Photos:       photos,
+	}, ftpingest.ErrInvalidUpload)
+}
+
+// IngestHikConnectMessage принимает тревогу камеры из очереди Hik-Connect (hikconnect.Sink). Камерой без
+// channelID/deviceID в уведомлении считается серийный номер устройства.
+func (h *Handler) IngestHikConnectMessage(ctx context.Context, msg hikconnect.Message) error {
+	photos := make([]ingest.Photo, 0, len(msg.Photos))
+	for _, photo := range msg.Photos {
+		photos = append(photos, ingest.Photo{Name: photo.Name, Data: photo.Data})
+	}
+	return h.ingestPulledNotification(ctx, pulledNotification{
+		Source:       "hik_connect",
+		Name:         msg.ID,
+		CameraID:     msg.DeviceSerial,
+		Notification: msg.Notification,
+		Photos:       photos,
+	}, hikconnect.ErrInvalidMessage)
+}
+
+// DeadLetterHikConnectMessage сохраняет в dead letters тревогу, которую приём так и не принял
+// (hikconnect.DeadLetterSink); её можно переиграть через /admin/dead-letters.
+func (h *Handler) DeadLetterHikConnectMessage(ctx context.Context, msg hikconnect.Message, cause error) error {
+	return h.anprService.SaveDeadLetter(ctx, service.DeadLetterInput{
+		Endpoint: ingest.AdapterHikvision,
+		CameraID: msg.DeviceSerial,
+		Body:     msg.Notification,
+		Err:      cause,
+	})
+}
+
+// ingestPulledNotification принимает уведомление так же, как уведомление Hikvision по HTTP, тем же адаптером:
+// снимки загружаются в хранилище фото, событие проходит ProcessIncomingEvent; снимки несохранённого события удаляются. Ошибки, с которыми уведомление
+// не примется и при повторе, оборачивают invalid; остальные источник повторяет позже.
+func (h *Handler) ingestPulledNotification(ctx context.Context, n pulledNotification, invalid error) error {
+	adapter, err := h.anprService.IngestAdapter(ingest.AdapterHikvision)
+	if err != nil {
+		return err
+	}
+	parsed, err := adapter.Parse(ctx, &ingest.Request{
+		Query: url.Values{"camera_id": []string{n.CameraID}},
+		Body:  n.Notification,
+	})
+	if err != nil {
+		if service.IsIngestPanic(err) {
+			return h.pulledPanic(ctx, n, err, invalid)
+		}
+		var payloadErr *ingest.PayloadError
+		if errors.As(err, &payloadErr) {
+			return fmt.Errorf("%w: %v", invalid, err)
+		}
+		return err
+	}
+	if parsed.Signal != nil {
+		return h.anprService.RecordCameraSignal(ctx, *parsed.Signal)
+	}
+	payload := *parsed.Payload
+
+	// Снимки регистрируются, только если событие сохранено; иначе они удаляются, чтобы повторы уведомления
+	// под новыми eventID не оставляли в хранилище ничьих файлов
+	eventID := h.anprService.NewEventID()
+	uploaded := h.uploadEventPhotos(ctx, n.Photos, eventID, payload)
+
+	result, err := h.anprService.ProcessIncomingEvent(ctx, payload, h.anprService.Config().Camera.Model, eventID, photoURLs(uploaded))
+	if err == nil || errors.Is(err, service.ErrVehicleNotWhitelisted) {
+		h.registerEventPhotos(ctx, uploaded, eventID, payload)
+	} else {
+		h.discardEventPhotos(ctx, uploaded, eventID)
+	}
+	switch {
+	case errors.Is(err, service.ErrVehicleNotWhitelisted):
+		// Событие сохранено с отказом — уведомление принято
+		h.logger(ctx).Warn().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Msg("vehicle not in whitelist (vehicles table)")
+		return nil
+	case errors.Is(err, service.ErrDuplicateEvent):
+		// Повтор уже сохранённого события (источник выдал уведомление ещё раз после сбоя) — принято
+		h.logger(ctx).Warn().Err(err).Str("plate", payload.Plate).Str("camera_id", payload.CameraID).Str("source", n.Source).Msg("duplicate event within 5 minutes, skipping save")
+		return nil
+	case errors.Is(err, service.ErrInvalidInput):
+		return fmt.Errorf("%w: %v", invalid, err)
+	case service.IsIngestPanic(err):
+		return h.pulledPanic(ctx, n, err, invalid)
+	case err != nil:
+		return err
+	}
+
+	h.logger(ctx).Info().
+		Str("event_id", result.EventID.String()).
+		Str("plate", result.Plate).
+		Str("source", n.Source).
+		Str("name", n.Name).
+		Msg("successfully processed and saved pulled notification")
+	return nil
+}
+
+// pulledPanic сохраняет уведомление, уронившее приём, в dead letters и отвергает его: иначе источник
+// выдавал бы его с той же паникой снова
+func (h *Handler) pulledPanic(ctx context.Context, n pulledNotification, cause, invalid error) error {
+	err := h.anprService.SaveDeadLetter(ctx, service.DeadLetterInput{
+		Endpoint: ingest.AdapterHikvision,
+		CameraID: n.CameraID,
+		Body:     n.Notification,
+		Err:      cause,
+	})
+	if err != nil {
+		h.logger(ctx).Error().Err(err).Str("source", n.Source).Str("name", n.Name).Msg("failed to save dead letter")
+	}
+	return fmt.Errorf("%w: %v", invalid, cause)
+}
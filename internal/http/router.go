@@ -2,15 +2,16 @@ package http
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 
 	"anpr-service/internal/db"
+	"anpr-service/internal/http/middleware"
 )
 
 func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, database *gorm.DB) *gin.Engine {
@@ -20,45 +21,42 @@ func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, dat
 
 	router := gin.New()
 	router.Use(gin.Recovery())
-	
-	// Логирование всех входящих запросов
-	router.Use(func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
-
-		c.Next()
-
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-
-		if raw != "" {
-			path = path + "?" + raw
-		}
 
-		// Логируем только важные запросы или ошибки
-		if statusCode >= 400 || path == "/api/v1/anpr/hikvision" {
-			fmt.Printf("[GIN] %v | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
-		}
-	})
-	
+	// Спан OpenTelemetry на каждый запрос (входящий traceparent продолжает trace вызывающей стороны);
+	// пробы здоровья не трассируются, чтобы не засорять коллектор
+	router.Use(otelgin.Middleware(handler.config.Tracing.ServiceName,
+		otelgin.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/health/live" && r.URL.Path != "/health/ready"
+		}),
+	))
+
+	// X-Request-ID: принимается от клиента или генерируется, попадает в ответ и во все логи запроса
+	router.Use(middleware.RequestID(handler.log))
+
+	// Access-лог: ошибки, медленные запросы и приём событий Hikvision — всегда, остальное — выборочно
+	router.Use(middleware.AccessLog(handler.log, middleware.AccessLogOptions{
+		SampleRate:    handler.config.AccessLog.SampleRate,
+		AlwaysPaths:   handler.config.AccessLog.AlwaysPaths,
+		SlowThreshold: handler.config.AccessLog.SlowThreshold,
+	}))
+
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins: true,
-		AllowMethods:    []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowMethods:    []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:    []string{"*"},
-		ExposeHeaders:   []string{"Content-Type", "Content-Disposition"},
+		ExposeHeaders:   []string{"Content-Type", "Content-Disposition", "Content-Language", "Retry-After", middleware.RequestIDHeader, DataVersionHeader},
 		MaxAge:          12 * time.Hour,
 	}))
 
+	// Язык ответа по Accept-Language: раньше остальных middleware, чтобы переводились и их ошибки
+	router.Use(middleware.Language())
+
+	// Срок обработки, переданный шлюзом (X-Request-Deadline / grpc-timeout), действует на всю цепочку
+	router.Use(middleware.RequestDeadline())
+
+	// Режим обслуживания: изменяющие запросы получают 503, переключатель режима остаётся доступным
+	router.Use(middleware.Maintenance(handler.maintenance, "/api/v1/admin/maintenance", "/internal/maintenance"))
+
 	router.GET("/health/live", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
@@ -74,8 +72,11 @@ func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, dat
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	router.GET("/health/full", handler.fullHealth(database))
+
+	router.GET("/version", handler.version(database))
+
 	handler.Register(router, authMiddleware)
 
 	return router
 }
-
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+// savedSearchRequest — сохраняемый поиск; владелец берётся из токена. Без schedule поиск не выгружается
+// по расписанию, channel и target тогда не нужны.
+type savedSearchRequest struct {
+	Name     string               `json:"name" binding:"required"`
+	Filters  service.EventFilters `json:"filters"`
+	Schedule string               `json:"schedule"`
+	SendAt   string               `json:"send_at"`
+	Channel  string               `json:"channel"`
+	Target   string               `json:"target"`
+}
+
+func (req savedSearchRequest) input() service.SavedSearchInput {
+	return service.SavedSearchInput{
+		Name:     req.Name,
+		Filters:  req.Filters,
+		Schedule: req.Schedule,
+		SendAt:   req.SendAt,
+		Channel:  req.Channel,
+		Target:   req.Target,
+	}
+}
+
+func (h *Handler) listSavedSearches(c *gin.Context) {
+	searches, err := h.anprService.ListSavedSearches(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(searches))
+}
+
+func (h *Handler) createSavedSearch(c *gin.Context) {
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	search, err := h.anprService.CreateSavedSearch(c.Request.Context(), req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(search))
+}
+
+func (h *Handler) updateSavedSearch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid saved search id"))
+		return
+	}
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	search, err := h.anprService.UpdateSavedSearch(c.Request.Context(), id, req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(search))
+}
+
+func (h *Handler) deleteSavedSearch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid saved search id"))
+		return
+	}
+	if err := h.anprService.DeleteSavedSearch(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+func (h *Handler) exportSavedSearch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid saved search id"))
+		return
+	}
+	from := strings.TrimSpace(c.Query("from"))
+	to := strings.TrimSpace(c.Query("to"))
+	data, filename, err := h.anprService.ExportSavedSearch(c.Request.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyRows) {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
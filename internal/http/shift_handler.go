@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+// shiftRequest — тело создания/изменения смены. Пустой polygon_id — смена всех полигонов,
+// пустой valid_to — бессрочная смена.
+type shiftRequest struct {
+	PolygonID *string `json:"polygon_id"`
+	Name      *string `json:"name"`
+	ValidFrom *string `json:"valid_from"`
+	ValidTo   *string `json:"valid_to"`
+	Start     *string `json:"start"`
+	End       *string `json:"end"`
+	TimeZone  *string `json:"time_zone"`
+}
+
+func (r shiftRequest) input() service.ShiftInput {
+	return service.ShiftInput{
+		PolygonID: r.PolygonID,
+		Name:      r.Name,
+		ValidFrom: r.ValidFrom,
+		ValidTo:   r.ValidTo,
+		Start:     r.Start,
+		End:       r.End,
+		TimeZone:  r.TimeZone,
+	}
+}
+
+func (h *Handler) listShifts(c *gin.Context) {
+	shifts, err := h.anprService.ListShifts(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(shifts))
+}
+
+func (h *Handler) createShift(c *gin.Context) {
+	var req shiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	shift, err := h.anprService.CreateShift(c.Request.Context(), req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(shift))
+}
+
+func (h *Handler) updateShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid shift id"))
+		return
+	}
+	var req shiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	shift, err := h.anprService.UpdateShift(c.Request.Context(), id, req.input())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(shift))
+}
+
+func (h *Handler) deleteShift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid shift id"))
+		return
+	}
+	if err := h.anprService.DeleteShift(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+// getShiftReport — рейсы периода по сменам календаря смен (фильтры — как у выгрузки в Excel)
+func (h *Handler) getShiftReport(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+
+	filters, ok := parseExportFilters(c, principal)
+	if !ok {
+		return
+	}
+
+	report, err := h.anprService.GetShiftReport(c.Request.Context(), filters)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) getSummarySubscription(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsContractor() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	sub, err := h.anprService.GetSummarySubscription(c.Request.Context(), principal.UserID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(sub))
+}
+
+// summarySubscriptionRequest — изменение подписки на ночную сводку; nil — не менять
+type summarySubscriptionRequest struct {
+	Enabled        *bool   `json:"enabled"`
+	TelegramChatID *string `json:"telegram_chat_id"`
+}
+
+func (h *Handler) updateSummarySubscription(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsContractor() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	var req summarySubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	sub, err := h.anprService.SetSummarySubscription(c.Request.Context(), principal.UserID, principal.OrgID, service.SummarySubscriptionInput{
+		Enabled:        req.Enabled,
+		TelegramChatID: req.TelegramChatID,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(sub))
+}
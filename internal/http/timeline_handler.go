@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+)
+
+func (h *Handler) getPlateTimeline(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	// Таймлайн охватывает все события номера, поэтому подрядчикам и водителям недоступен
+	if principal.IsContractor() || principal.IsDriver() {
+		c.JSON(http.StatusForbidden, errorResponse("insufficient permissions"))
+		return
+	}
+
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	var from, to *time.Time
+	if value := strings.TrimSpace(c.Query("from")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid from time format, use RFC3339"))
+			return
+		}
+		from = &t
+	}
+	if value := strings.TrimSpace(c.Query("to")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid to time format, use RFC3339"))
+			return
+		}
+		to = &t
+	}
+
+	timeline, err := h.anprService.GetPlateTimeline(c.Request.Context(), plateID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(timeline))
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+// trafficQuery собирает параметры аналитики скорости и полос из query-строки
+func trafficQuery(c *gin.Context) service.TrafficQuery {
+	return service.TrafficQuery{
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		CameraID:  c.Query("camera_id"),
+		PolygonID: c.Query("polygon_id"),
+		Interval:  c.Query("interval"),
+	}
+}
+
+// getSpeedReport возвращает среднюю скорость и перцентили по камерам
+// GET /api/v1/reports/speed
+func (h *Handler) getSpeedReport(c *gin.Context) {
+	report, err := h.anprService.SpeedReport(c.Request.Context(), trafficQuery(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// getLaneUsage возвращает загрузку полос камер по часам или дням
+// GET /api/v1/reports/lanes
+func (h *Handler) getLaneUsage(c *gin.Context) {
+	report, err := h.anprService.LaneUsage(c.Request.Context(), trafficQuery(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// getConfidenceReport сравнивает уверенность распознавания камер с ручными исправлениями номеров
+// GET /api/v1/reports/confidence
+func (h *Handler) getConfidenceReport(c *gin.Context) {
+	report, err := h.anprService.ConfidenceCalibration(c.Request.Context(), trafficQuery(c), c.Query("max_error_rate"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// getHourlyStats возвращает число событий камер по часам из почасовых счётчиков
+// GET /api/v1/stats/hourly
+func (h *Handler) getHourlyStats(c *gin.Context) {
+	stats, err := h.anprService.HourlyStats(c.Request.Context(), trafficQuery(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(stats))
+}
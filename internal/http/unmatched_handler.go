@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) listUnmatchedPlates(c *gin.Context) {
+	// Разбор охватывает все номера, поэтому доступен тем же ролям, что и списки
+	if !h.canViewLists(c) {
+		return
+	}
+
+	var from, to *time.Time
+	if value := strings.TrimSpace(c.Query("from")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid from time format, use RFC3339"))
+			return
+		}
+		from = &t
+	}
+	if value := strings.TrimSpace(c.Query("to")); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid to time format, use RFC3339"))
+			return
+		}
+		to = &t
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	plates, err := h.anprService.ListUnmatchedPlates(c.Request.Context(), from, to, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(plates))
+}
+
+// unmatchedReviewRequest — решение по номеру без сопоставленного транспорта
+type unmatchedReviewRequest struct {
+	Action string  `json:"action" binding:"required"`
+	ListID *string `json:"list_id"`
+	Note   *string `json:"note"`
+}
+
+func (h *Handler) reviewUnmatchedPlate(c *gin.Context) {
+	plateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid plate id"))
+		return
+	}
+
+	var req unmatchedReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	input := service.UnmatchedReviewInput{Action: req.Action, Note: req.Note}
+	if req.ListID != nil && *req.ListID != "" {
+		listID, err := uuid.Parse(*req.ListID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("invalid list id"))
+			return
+		}
+		input.ListID = &listID
+	}
+
+	result, err := h.anprService.ReviewUnmatchedPlate(c.Request.Context(), plateID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
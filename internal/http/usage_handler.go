@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+)
+
+// meterAPICall учитывает обращение к API в использовании организации пользователя
+func (h *Handler) meterAPICall(c *gin.Context) {
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		h.anprService.RecordAPICall(principal.OrgID)
+	}
+	c.Next()
+}
+
+// getUsage возвращает использование сервиса по организациям и дням
+// GET /api/v1/admin/usage?from=2025-01-01&to=2025-01-31&organization_id=
+func (h *Handler) getUsage(c *gin.Context) {
+	usage, err := h.anprService.UsageReport(c.Request.Context(), c.Query("from"), c.Query("to"), c.Query("organization_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(usage))
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/buildinfo"
+	"anpr-service/internal/db"
+)
+
+// versionResponse — сведения о сборке, версии схемы БД и включённых возможностях.
+// Migration и FeatureFlags отсутствуют, если БД недоступна.
+type versionResponse struct {
+	buildinfo.Info
+	Migration    *db.SchemaVersion          `json:"migration,omitempty"`
+	Features     map[string]bool            `json:"features"`
+	FeatureFlags map[string]versionFlagInfo `json:"feature_flags,omitempty"`
+}
+
+// versionFlagInfo — флаг возможности: значение для всех и переопределения организаций и камер
+type versionFlagInfo struct {
+	Enabled   bool                  `json:"enabled"`
+	Overrides []versionFlagOverride `json:"overrides,omitempty"`
+}
+
+type versionFlagOverride struct {
+	Scope   string `json:"scope"`
+	ScopeID string `json:"scope_id,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// version — GET /version: что за сборка запущена и с какими возможностями (для обращений в поддержку)
+func (h *Handler) version(database *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		response := versionResponse{
+			Info:     buildinfo.Get(),
+			Features: h.anprService.Config().Features(),
+		}
+		if schema, err := db.GetSchemaVersion(ctx, database); err != nil {
+			h.logger(c.Request.Context()).Warn().Err(err).Msg("failed to get schema version")
+		} else {
+			response.Migration = &schema
+		}
+		if flags, err := h.anprService.ListFeatureFlags(ctx); err != nil {
+			h.logger(c.Request.Context()).Warn().Err(err).Msg("failed to list feature flags")
+		} else {
+			response.FeatureFlags = make(map[string]versionFlagInfo, len(flags))
+			for _, flag := range flags {
+				info := versionFlagInfo{Enabled: flag.Enabled}
+				for _, override := range flag.Overrides {
+					info.Overrides = append(info.Overrides, versionFlagOverride{Scope: override.Scope, ScopeID: override.ScopeID, Enabled: override.Enabled})
+				}
+				response.FeatureFlags[flag.Name] = info
+			}
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
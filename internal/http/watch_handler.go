@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+// plateWatchRequest — новое отслеживание номера; владелец берётся из токена
+type plateWatchRequest struct {
+	Pattern   string     `json:"pattern" binding:"required"`
+	Channel   string     `json:"channel"`
+	Target    string     `json:"target" binding:"required"`
+	Note      *string    `json:"note"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *Handler) listPlateWatches(c *gin.Context) {
+	watches, err := h.anprService.ListPlateWatches(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(watches))
+}
+
+func (h *Handler) createPlateWatch(c *gin.Context) {
+	var req plateWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	watch, err := h.anprService.CreatePlateWatch(c.Request.Context(), service.PlateWatchInput{
+		Pattern:   req.Pattern,
+		Channel:   req.Channel,
+		Target:    req.Target,
+		Note:      req.Note,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(watch))
+}
+
+func (h *Handler) deletePlateWatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid watch id"))
+		return
+	}
+	if err := h.anprService.DeletePlateWatch(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+// webhookRequest — тело создания/изменения подписки. matched_snow: null снимает фильтр.
+type webhookRequest struct {
+	Name        *string         `json:"name"`
+	URL         *string         `json:"url"`
+	Secret      *string         `json:"secret"`
+	CameraIDs   *[]string       `json:"camera_ids"`
+	ListTypes   *[]string       `json:"list_types"`
+	MatchedSnow json.RawMessage `json:"matched_snow"`
+	Active      *bool           `json:"active"`
+}
+
+func (r webhookRequest) input() (service.WebhookInput, error) {
+	input := service.WebhookInput{
+		Name:      r.Name,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		CameraIDs: r.CameraIDs,
+		ListTypes: r.ListTypes,
+		Active:    r.Active,
+	}
+	switch {
+	case len(r.MatchedSnow) == 0:
+	case bytes.Equal(r.MatchedSnow, []byte("null")):
+		input.ClearMatchedSnow = true
+	default:
+		var matched bool
+		if err := json.Unmarshal(r.MatchedSnow, &matched); err != nil {
+			return input, err
+		}
+		input.MatchedSnow = &matched
+	}
+	return input, nil
+}
+
+func (h *Handler) listWebhooks(c *gin.Context) {
+	subs, err := h.anprService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(subs))
+}
+
+func (h *Handler) createWebhook(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	input, err := req.input()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("matched_snow must be true, false or null"))
+		return
+	}
+
+	sub, err := h.anprService.CreateWebhook(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(sub))
+}
+
+func (h *Handler) updateWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid webhook id"))
+		return
+	}
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	input, err := req.input()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("matched_snow must be true, false or null"))
+		return
+	}
+
+	sub, err := h.anprService.UpdateWebhook(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(sub))
+}
+
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid webhook id"))
+		return
+	}
+	if err := h.anprService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": true}))
+}
+
+func (h *Handler) listWebhookDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid webhook id"))
+		return
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	deliveries, err := h.anprService.ListWebhookDeliveries(c.Request.Context(), id, c.Query("status"), limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(deliveries))
+}
+
+func (h *Handler) retryWebhookDelivery(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid webhook id"))
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid delivery id"))
+		return
+	}
+	if err := h.anprService.RetryWebhookDelivery(c.Request.Context(), id, deliveryID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"queued": true}))
+}
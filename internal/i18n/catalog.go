@@ -0,0 +1,106 @@
+package i18n
+
+import "strings"
+
+// messages — тексты отчётов и уведомлений по ключу; форматы с разным порядком слов используют
+// явные номера аргументов (%[2]d)
+var messages = map[string]map[Lang]string{
+	// Заголовки и итоги Excel-отчёта
+	"report.contractor":  {RU: "ТОО", KK: "ЖШС", EN: "Contractor"},
+	"report.vehicle":     {RU: "Машина", KK: "Көлік", EN: "Vehicle"},
+	"report.plate":       {RU: "Госномер", KK: "Мемлекеттік нөмір", EN: "Plate number"},
+	"report.event_time":  {RU: "Время события", KK: "Оқиға уақыты", EN: "Event time"},
+	"report.percentage":  {RU: "Процент", KK: "Пайыз", EN: "Fill, %"},
+	"report.volume":      {RU: "Объем", KK: "Көлем", EN: "Volume"},
+	"report.unassigned":  {RU: "Не назначено", KK: "Тағайындалмаған", EN: "Unassigned"},
+	"report.group":       {RU: "ТОО: %s", KK: "ЖШС: %s", EN: "Contractor: %s"},
+	"report.group_total": {RU: "Итого %s: %d рейсов, %.2f м³", KK: "%s бойынша барлығы: %d рейс, %.2f м³", EN: "Total %s: %d trips, %.2f m³"},
+	"report.total":       {RU: "ВСЕГО: %d рейсов, %.2f м³", KK: "БАРЛЫҒЫ: %d рейс, %.2f м³", EN: "TOTAL: %d trips, %.2f m³"},
+	"report.volume_m3":   {RU: "%.2f м³", KK: "%.2f м³", EN: "%.2f m³"},
+
+	// Уведомления Telegram
+	"notify.blacklist":       {RU: "Номер из чёрного списка: %s\nСписок: %s\n%s", KK: "Қара тізімдегі нөмір: %s\nТізім: %s\n%s", EN: "Blacklisted plate: %s\nList: %s\n%s"},
+	"notify.overload":        {RU: "Перегруз кузова: %s, заполнение %.0f%%", KK: "Шанақ асыра жүктелген: %s, толуы %.0f%%", EN: "Body overload: %s, filled %.0f%%"},
+	"notify.overload_volume": {RU: " (%.1f м³)", KK: " (%.1f м³)", EN: " (%.1f m³)"},
+	"notify.video_loss":      {RU: "Камера сообщает о пропаже видеосигнала: %s\nС %s (%s назад)", KK: "Камера бейне сигналы жоғалғанын хабарлайды: %s\n%s бастап (%s бұрын)", EN: "Camera reports video loss: %s\nSince %s (%s ago)"},
+	"notify.camera_never":    {RU: "Камера не присылает события: %s\nСобытий от камеры ещё не было", KK: "Камера оқиғаларды жібермейді: %s\nКамерадан әлі оқиға болған жоқ", EN: "Camera is not sending events: %s\nNo events from this camera yet"},
+	"notify.camera_silent":   {RU: "Камера не присылает события: %s\nПоследнее событие: %s (%s назад)", KK: "Камера оқиғаларды жібермейді: %s\nСоңғы оқиға: %s (%s бұрын)", EN: "Camera is not sending events: %s\nLast event: %s (%s ago)"},
+	"notify.event_camera":    {RU: "Камера: %s", KK: "Камера: %s", EN: "Camera: %s"},
+	"notify.event_time":      {RU: "Время: %s", KK: "Уақыты: %s", EN: "Time: %s"},
+	"notify.photo":           {RU: "Фото: %s", KK: "Фото: %s", EN: "Photo: %s"},
+	"notify.watch":           {RU: "Отслеживаемый номер: %s (шаблон %s)\n%s", KK: "Бақыланатын нөмір: %s (үлгі %s)\n%s", EN: "Watched plate: %s (pattern %s)\n%s"},
+	"notify.watch_note":      {RU: "Примечание: %s", KK: "Ескертпе: %s", EN: "Note: %s"},
+
+	// Ночная сводка подрядчику
+	"summary.title":     {RU: "Сводка за ночь %s – %s", KK: "%s – %s түнгі жиынтық", EN: "Night summary %s – %s"},
+	"summary.trips":     {RU: "Рейсов: %d", KK: "Рейстер: %d", EN: "Trips: %d"},
+	"summary.volume":    {RU: "Вывезено снега: %.1f м³", KK: "Шығарылған қар: %.1f м³", EN: "Snow removed: %.1f m³"},
+	"summary.unmatched": {RU: "Без сопоставленного снега: %s", KK: "Қары сәйкестендірілмеген: %s", EN: "Without matched snow: %s"},
+
+	// Выгрузки сохранённых поисков
+	"saved_search.caption": {RU: "%[1]s: событий %[2]d за %[3]s — %[4]s", KK: "%[1]s: %[3]s — %[4]s аралығында %[2]d оқиға", EN: "%[1]s: %[2]d events for %[3]s — %[4]s"},
+	"saved_search.body":    {RU: "Выгрузка сохранённого поиска во вложении.", KK: "Сақталған іздеу нәтижесі тіркемеде.", EN: "The saved search export is attached."},
+}
+
+// errorTexts — переводы сообщений об ошибках API по английскому тексту
+var errorTexts = map[string]map[Lang]string{
+	// Общие ошибки сервиса (sentinel-ошибки service и middleware)
+	"invalid input":                    {RU: "некорректные данные", KK: "деректер қате"},
+	"not found":                        {RU: "не найдено", KK: "табылмады"},
+	"insufficient permissions":         {RU: "недостаточно прав", KK: "құқық жеткіліксіз"},
+	"unauthorized":                     {RU: "требуется авторизация", KK: "авторизация қажет"},
+	"internal error":                   {RU: "внутренняя ошибка", KK: "ішкі қате"},
+	"request deadline exceeded":        {RU: "истёк срок обработки запроса", KK: "сұрауды өңдеу мерзімі өтті"},
+	"rate limit exceeded":              {RU: "превышен лимит запросов", KK: "сұраулар шегінен асты"},
+	"request body too large":           {RU: "слишком большое тело запроса", KK: "сұрау денесі тым үлкен"},
+	"authorization header missing":     {RU: "нет заголовка Authorization", KK: "Authorization тақырыбы жоқ"},
+	"invalid authorization header":     {RU: "некорректный заголовок Authorization", KK: "Authorization тақырыбы қате"},
+	"invalid token":                    {RU: "недействительный токен", KK: "токен жарамсыз"},
+	"service is in maintenance mode":   {RU: "сервис на обслуживании", KK: "сервис қызмет көрсетуде"},
+	"too many rows for export":         {RU: "слишком много строк для выгрузки", KK: "жүктеуге арналған жолдар тым көп"},
+	"vehicle not whitelisted":          {RU: "машины нет в белом списке", KK: "көлік ақ тізімде жоқ"},
+	"camera unavailable":               {RU: "камера недоступна", KK: "камера қолжетімсіз"},
+	"job is already running":           {RU: "задача уже выполняется", KK: "тапсырма орындалып жатыр"},
+	"billing period is already locked": {RU: "ведомость за период уже заблокирована", KK: "кезең ведомосы бұғатталған"},
+
+	// Параметры запросов
+	"from and to are required (RFC3339)":                {RU: "нужны from и to (RFC3339)", KK: "from және to қажет (RFC3339)"},
+	"invalid from time format, use RFC3339":             {RU: "некорректный формат from, используйте RFC3339", KK: "from пішімі қате, RFC3339 қолданыңыз"},
+	"invalid to time format, use RFC3339":               {RU: "некорректный формат to, используйте RFC3339", KK: "to пішімі қате, RFC3339 қолданыңыз"},
+	"to time must be after from time":                   {RU: "to должно быть позже from", KK: "to уақыты from уақытынан кейін болуы керек"},
+	"date range cannot exceed 90 days":                  {RU: "период не может превышать 90 дней", KK: "кезең 90 күннен аспауы керек"},
+	"direction must be 'entry' or 'exit'":               {RU: "direction должно быть 'entry' или 'exit'", KK: "direction 'entry' немесе 'exit' болуы керек"},
+	"end_time must be after start_time":                 {RU: "end_time должно быть позже start_time", KK: "end_time уақыты start_time уақытынан кейін болуы керек"},
+	"confirmation required: set confirm=true":           {RU: "нужно подтверждение: передайте confirm=true", KK: "растау қажет: confirm=true жіберіңіз"},
+	"plate parameter is required":                       {RU: "нужен параметр plate", KK: "plate параметрі қажет"},
+	"invalid plate format":                              {RU: "некорректный формат номера", KK: "нөмір пішімі қате"},
+	"event not found":                                   {RU: "событие не найдено", KK: "оқиға табылмады"},
+	"photo not found":                                   {RU: "фото не найдено", KK: "фото табылмады"},
+	"photo storage is not configured":                   {RU: "хранилище фото не настроено", KK: "фото қоймасы бапталмаған"},
+	"failed to read request body":                       {RU: "не удалось прочитать тело запроса", KK: "сұрау денесін оқу мүмкін болмады"},
+	"failed to capture snapshot from camera":            {RU: "не удалось получить снимок с камеры", KK: "камерадан сурет алу мүмкін болмады"},
+	"invalid event payload":                             {RU: "событие не прошло проверку", KK: "оқиға тексеруден өтпеді"},
+	"invalid event JSON":                                {RU: "JSON события не прошёл проверку", KK: "оқиға JSON тексеруден өтпеді"},
+	"failed to process event, request saved for replay": {RU: "не удалось обработать событие, запрос сохранён для повтора", KK: "оқиғаны өңдеу мүмкін болмады, сұрау қайталау үшін сақталды"},
+}
+
+// errorPattern — перевод типового сообщения с именем поля или объекта (subject)
+type errorPattern struct {
+	prefix, suffix string
+	texts          map[Lang]string
+}
+
+func (p errorPattern) match(part string) (string, bool) {
+	if !strings.HasPrefix(part, p.prefix) || !strings.HasSuffix(part, p.suffix) || len(part) <= len(p.prefix)+len(p.suffix) {
+		return "", false
+	}
+	return part[len(p.prefix) : len(part)-len(p.suffix)], true
+}
+
+// errorPatterns — типовые сообщения: "invalid plate id", "target is required", "webhook not found"
+var errorPatterns = []errorPattern{
+	{prefix: "invalid ", texts: map[Lang]string{RU: "некорректное значение %s", KK: "%s мәні қате"}},
+	{suffix: " is required", texts: map[Lang]string{RU: "обязательное поле %s", KK: "%s міндетті"}},
+	{suffix: " not found", texts: map[Lang]string{RU: "не найдено: %s", KK: "табылмады: %s"}},
+	{suffix: " is not configured", texts: map[Lang]string{RU: "не настроено: %s", KK: "бапталмаған: %s"}},
+}
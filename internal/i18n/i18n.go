@@ -0,0 +1,146 @@
+// Package i18n — языки ответов API, отчётов и уведомлений (русский, казахский, английский)
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang — язык (код ISO 639-1)
+type Lang string
+
+const (
+	RU Lang = "ru"
+	KK Lang = "kk"
+	EN Lang = "en"
+)
+
+// Default — язык отчётов и уведомлений, если другой не выбран
+const Default = RU
+
+// Supported — поддерживаемые языки
+var Supported = []Lang{RU, KK, EN}
+
+// Parse разбирает код языка: регистр и регион не важны ("ru-RU", "kk-KZ"), "kz" — частое
+// обозначение казахского по коду страны
+func Parse(value string) (Lang, bool) {
+	tag := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	switch tag {
+	case "ru":
+		return RU, true
+	case "kk", "kz":
+		return KK, true
+	case "en":
+		return EN, true
+	}
+	return "", false
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учётом весов q. false — заголовка нет
+// или ни один из языков не поддерживается.
+func Negotiate(acceptLanguage string) (Lang, bool) {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || !strings.EqualFold(name, "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang, true
+}
+
+type contextKey struct{}
+
+// WithLanguage сохраняет язык запроса в контексте
+func WithLanguage(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext возвращает язык запроса или Default, если клиент его не выбрал
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// Text форматирует сообщение key (см. messages) на языке lang. Неизвестный язык заменяется на
+// Default, неизвестный ключ возвращается как есть — так пропуск в каталоге виден сразу.
+func Text(lang Lang, key string, args ...any) string {
+	texts, ok := messages[key]
+	if !ok {
+		return key
+	}
+	format, ok := texts[lang]
+	if !ok {
+		format = texts[Default]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error переводит текст ошибки API. Ошибки сервиса собираются из частей через ": "
+// ("invalid input: plate is required"), поэтому каждая часть переводится отдельно; части без
+// перевода (имена полей, значения, редкие сообщения) остаются на английском.
+func Error(lang Lang, message string) string {
+	if lang == EN || message == "" {
+		return message
+	}
+	if texts, ok := errorTexts[message]; ok && texts[lang] != "" {
+		return texts[lang]
+	}
+	parts := strings.Split(message, ": ")
+	for i, part := range parts {
+		parts[i] = translateErrorPart(lang, part)
+	}
+	return strings.Join(parts, ": ")
+}
+
+func translateErrorPart(lang Lang, part string) string {
+	if texts, ok := errorTexts[part]; ok {
+		if text, ok := texts[lang]; ok {
+			return text
+		}
+		return part
+	}
+	for _, pattern := range errorPatterns {
+		if subject, ok := pattern.match(part); ok {
+			if format, ok := pattern.texts[lang]; ok {
+				return fmt.Sprintf(format, subject)
+			}
+		}
+	}
+	return part
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "de-DE, fr;q=0.8", ok: false},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: RU, ok: true},
+		{header: "en;q=0.5, kk-KZ", want: KK, ok: true},
+		{header: "kz", want: KK, ok: true},
+		{header: "de, en-GB;q=0.7, ru;q=0.3", want: EN, ok: true},
+		{header: "ru;q=0, en", want: EN, ok: true},
+		{header: "ru;q=abc", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := Negotiate(tt.header)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Negotiate(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    Lang
+		message string
+		want    string
+	}{
+		{name: "english is the source", lang: EN, message: "invalid input: plate is required", want: "invalid input: plate is required"},
+		{name: "sentinel with pattern", lang: RU, message: "invalid input: plate is required", want: "некорректные данные: обязательное поле plate"},
+		{name: "exact message", lang: KK, message: "invalid from time format, use RFC3339", want: "from пішімі қате, RFC3339 қолданыңыз"},
+		{name: "message with colon", lang: RU, message: "confirmation required: set confirm=true", want: "нужно подтверждение: передайте confirm=true"},
+		{name: "invalid id", lang: RU, message: "invalid webhook id", want: "некорректное значение webhook id"},
+		{name: "free text stays", lang: RU, message: "not found: plate 123ABC02 has no events", want: "не найдено: plate 123ABC02 has no events"},
+		{name: "maintenance reason", lang: KK, message: "service is in maintenance mode: migration", want: "сервис қызмет көрсетуде: migration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Error(tt.lang, tt.message); got != tt.want {
+				t.Fatalf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := Text(KK, "saved_search.caption", "Ночь", 3, "14.01.2025 08:00", "15.01.2025 08:00"); got != "Ночь: 14.01.2025 08:00 — 15.01.2025 08:00 аралығында 3 оқиға" {
+		t.Fatalf("Text() = %q", got)
+	}
+	if got := Text(Lang("de"), "report.plate"); got != "Госномер" {
+		t.Fatalf("Text() for an unknown language = %q, want the default", got)
+	}
+	if got := FromContext(WithLanguage(context.Background(), EN)); got != EN {
+		t.Fatalf("FromContext() = %q", got)
+	}
+	if got := FromContext(context.Background()); got != Default {
+		t.Fatalf("FromContext() without language = %q", got)
+	}
+}
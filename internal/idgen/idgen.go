@@ -0,0 +1,49 @@
+// Package idgen — генерация идентификаторов. Сервис и репозиторий получают Generator через конструктор,
+// чтобы в тестах идентификаторы событий и номеров были предсказуемыми.
+package idgen
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Generator выдаёт новые идентификаторы
+type Generator interface {
+	NewID() uuid.UUID
+}
+
+type randomGenerator struct{}
+
+func (randomGenerator) NewID() uuid.UUID {
+	return uuid.New()
+}
+
+// Random возвращает генератор случайных UUID v4
+func Random() Generator {
+	return randomGenerator{}
+}
+
+// Sequence выдаёт UUID с возрастающим номером в младших байтах:
+// 00000000-0000-0000-0000-000000000001, ...-000000000002 и т.д. (для тестов)
+type Sequence struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewSequence создаёт генератор, который начинает с 1
+func NewSequence() *Sequence {
+	return &Sequence{}
+}
+
+func (s *Sequence) NewID() uuid.UUID {
+	s.mu.Lock()
+	s.next++
+	n := s.next
+	s.mu.Unlock()
+
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], n)
+	return id
+}
@@ -0,0 +1,17 @@
+package idgen
+
+import "testing"
+
+func TestSequence(t *testing.T) {
+	seq := NewSequence()
+	want := []string{
+		"00000000-0000-0000-0000-000000000001",
+		"00000000-0000-0000-0000-000000000002",
+		"00000000-0000-0000-0000-000000000003",
+	}
+	for _, w := range want {
+		if got := seq.NewID().String(); got != w {
+			t.Fatalf("NewID() = %s, want %s", got, w)
+		}
+	}
+}
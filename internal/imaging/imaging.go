@@ -0,0 +1,103 @@
+// Package imaging готовит фото событий к хранению: убирает метаданные EXIF (координаты, серийный номер
+// устройства) перекодированием и строит уменьшенные копии для списков и карточек.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Имена копий фото
+const (
+	RenditionThumbnail = "thumbnail"
+	RenditionMedium    = "medium"
+)
+
+// Rendition — размер уменьшенной копии: наибольшая сторона в пикселях
+type Rendition struct {
+	Name    string
+	MaxSide int
+}
+
+// Renditions — копии, которые строятся для каждого фото
+var Renditions = []Rendition{
+	{Name: RenditionThumbnail, MaxSide: 320},
+	{Name: RenditionMedium, MaxSide: 1280},
+}
+
+const (
+	originalQuality  = 90
+	renditionQuality = 80
+)
+
+// Result — перекодированное фото без EXIF и его уменьшенные копии (все в JPEG)
+type Result struct {
+	Original   []byte
+	Width      int
+	Height     int
+	Renditions map[string][]byte
+}
+
+// Process декодирует фото, поворачивает его по EXIF-ориентации и перекодирует в JPEG без метаданных.
+// Копия строится, только если фото больше её размера, иначе используется оригинал.
+func Process(data []byte) (*Result, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	img = applyOrientation(img, exifOrientation(data))
+
+	original, err := encodeJPEG(img, originalQuality)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	result := &Result{
+		Original:   original,
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Renditions: make(map[string][]byte, len(Renditions)),
+	}
+	for _, r := range Renditions {
+		if max(bounds.Dx(), bounds.Dy()) <= r.MaxSide {
+			result.Renditions[r.Name] = original
+			continue
+		}
+		encoded, err := encodeJPEG(resize(img, r.MaxSide), renditionQuality)
+		if err != nil {
+			return nil, err
+		}
+		result.Renditions[r.Name] = encoded
+	}
+	return result, nil
+}
+
+// resize уменьшает изображение так, чтобы большая сторона стала maxSide, с сохранением пропорций
+func resize(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
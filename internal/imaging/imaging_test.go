@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// jpegWithOrientation кодирует изображение width×height и вставляет после SOI блок EXIF с тегом Orientation
+func jpegWithOrientation(t *testing.T, width, height, orientation int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	if orientation == 0 {
+		return encoded
+	}
+
+	// TIFF (little endian): заголовок, IFD0 с одной записью Orientation
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, encoded[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		wantWidth   int
+		wantHeight  int
+		wantThumb   image.Point
+		thumbIsSame bool
+	}{
+		{
+			name:       "landscape",
+			data:       jpegWithOrientation(t, 1600, 1200, 0),
+			wantWidth:  1600,
+			wantHeight: 1200,
+			wantThumb:  image.Pt(320, 240),
+		},
+		{
+			name:       "rotated by exif",
+			data:       jpegWithOrientation(t, 1600, 1200, 6),
+			wantWidth:  1200,
+			wantHeight: 1600,
+			wantThumb:  image.Pt(240, 320),
+		},
+		{
+			name:        "smaller than thumbnail",
+			data:        jpegWithOrientation(t, 200, 100, 1),
+			wantWidth:   200,
+			wantHeight:  100,
+			wantThumb:   image.Pt(200, 100),
+			thumbIsSame: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Width != tt.wantWidth || result.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", result.Width, result.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if bytes.Contains(result.Original, []byte("Exif")) {
+				t.Error("original still contains EXIF")
+			}
+
+			thumb, err := jpeg.DecodeConfig(bytes.NewReader(result.Renditions[RenditionThumbnail]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := image.Pt(thumb.Width, thumb.Height); got != tt.wantThumb {
+				t.Errorf("thumbnail = %v, want %v", got, tt.wantThumb)
+			}
+			if same := bytes.Equal(result.Renditions[RenditionThumbnail], result.Original); same != tt.thumbIsSame {
+				t.Errorf("thumbnail is original = %v, want %v", same, tt.thumbIsSame)
+			}
+			if _, ok := result.Renditions[RenditionMedium]; !ok {
+				t.Error("medium rendition is missing")
+			}
+		})
+	}
+}
+
+func TestProcessNotAnImage(t *testing.T) {
+	if _, err := Process([]byte("not an image")); err == nil {
+		t.Error("expected an error for non-image data")
+	}
+}
@@ -0,0 +1,174 @@
+// Package ingest описывает адаптеры форматов камер: каждый адаптер разбирает запрос своей камеры
+// (JSON, уведомление Hikvision и т.д.) в anpr.EventPayload, не завися от HTTP-обработчика.
+// Приём по HTTP, FTP и повтор непринятых уведомлений находят адаптер в Registry по имени.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+// Имена встроенных адаптеров. Имя адаптера сохраняется в непринятых уведомлениях (dead letters),
+// поэтому его нельзя менять.
+const (
+	AdapterGeneric   = "generic"
+	AdapterHikvision = "hikvision"
+)
+
+// ErrUnknownAdapter — адаптер с таким именем не зарегистрирован
+var ErrUnknownAdapter = errors.New("unknown ingest adapter")
+
+// Request — запрос камеры: тело целиком, заголовки и параметры адреса
+type Request struct {
+	ContentType string
+	Header      http.Header
+	Query       url.Values
+	Body        []byte
+}
+
+// Photo — снимок, присланный вместе с событием
+type Photo struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Signal — уведомление камеры о её состоянии (heartbeat, videoloss и другие события без номера)
+type Signal struct {
+	CameraID  string
+	EventType string
+	// VideoLoss — состояние видеосигнала для videoloss, nil для остальных сигналов
+	VideoLoss *bool
+}
+
+// Result — разобранный запрос: событие со снимками или сигнал состояния камеры
+type Result struct {
+	Payload *anpr.EventPayload
+	Photos  []Photo
+	Signal  *Signal
+	// Format — формат уведомления (xml, json), в котором камера ждёт подтверждение сигнала
+	Format string
+}
+
+// FieldError — недопустимое значение поля события; Field — путь к полю (vehicle.speed)
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// PayloadError — запрос не разбирается адаптером. Message и Fields отдаются камере, Err — причина для логов.
+type PayloadError struct {
+	Message string
+	// Fields — ошибки по полям, если событие разобрано, но не прошло проверку
+	Fields []FieldError
+	Err    error
+}
+
+func (e *PayloadError) Error() string {
+	message := e.Message
+	if len(e.Fields) > 0 {
+		details := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			details[i] = field.Field + " " + field.Message
+		}
+		message += ": " + strings.Join(details, "; ")
+	}
+	if e.Err == nil {
+		return message
+	}
+	return message + ": " + e.Err.Error()
+}
+
+func (e *PayloadError) Unwrap() error {
+	return e.Err
+}
+
+// Этапы приёма, на которых перехватывается паника (PanicError.Stage)
+const (
+	StageParse   = "parse"
+	StageProcess = "process"
+)
+
+// PanicError — паника при разборе или обработке уведомления, перехваченная приёмом. Уведомление
+// сохраняется в dead letters и может быть обработано повторно после исправления.
+type PanicError struct {
+	Stage string
+	// Adapter — имя адаптера (для этапа разбора)
+	Adapter string
+	Value   any
+	Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Adapter != "" {
+		return fmt.Sprintf("panic during %s (%s): %v", e.Stage, e.Adapter, e.Value)
+	}
+	return fmt.Sprintf("panic during %s: %v", e.Stage, e.Value)
+}
+
+// Adapter разбирает запросы камер одного формата
+type Adapter interface {
+	// Name — имя адаптера в Registry
+	Name() string
+	// Parse разбирает запрос; ошибка разбора возвращается как *PayloadError
+	Parse(ctx context.Context, req *Request) (*Result, error)
+}
+
+// Registry — зарегистрированные адаптеры по имени
+type Registry struct {
+	mu       sync.RWMutex
+	adapters map[string]Adapter
+}
+
+// NewRegistry создаёт реестр с адаптерами; повтор имени — ошибка программы
+func NewRegistry(adapters ...Adapter) *Registry {
+	r := &Registry{adapters: map[string]Adapter{}}
+	for _, adapter := range adapters {
+		if err := r.Register(adapter); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register добавляет адаптер
+func (r *Registry) Register(adapter Adapter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.adapters[adapter.Name()]; ok {
+		return fmt.Errorf("ingest adapter %q is already registered", adapter.Name())
+	}
+	r.adapters[adapter.Name()] = adapter
+	return nil
+}
+
+// Get возвращает адаптер по имени
+func (r *Registry) Get(name string) (Adapter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	adapter, ok := r.adapters[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAdapter, name)
+	}
+	return adapter, nil
+}
+
+// Names возвращает имена адаптеров по алфавиту
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.adapters))
+	for name := range r.adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,30 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubAdapter string
+
+func (a stubAdapter) Name() string { return string(a) }
+
+func (a stubAdapter) Parse(context.Context, *Request) (*Result, error) { return &Result{}, nil }
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(stubAdapter("b"), stubAdapter("a"))
+
+	if err := r.Register(stubAdapter("a")); err == nil {
+		t.Error("duplicate adapter registered")
+	}
+	if _, err := r.Get("c"); !errors.Is(err, ErrUnknownAdapter) {
+		t.Errorf("Get(c) err = %v, want ErrUnknownAdapter", err)
+	}
+	if adapter, err := r.Get("b"); err != nil || adapter.Name() != "b" {
+		t.Errorf("Get(b) = %v, %v", adapter, err)
+	}
+	if names := r.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("Names() = %v, want [a b]", names)
+	}
+}
@@ -0,0 +1,151 @@
+// Package generic — адаптер собственного формата сервиса: EventPayload в JSON или multipart
+// (поле event с JSON события и файлы photos). Так присылают события симулятор и интеграции.
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/logctx"
+)
+
+// multipartMaxMemory — сколько multipart-запроса держится в памяти, остальное уходит во временные файлы
+const multipartMaxMemory = 50 << 20
+
+// maxPhotoSize — снимки больше не читаются целиком; приём события отвергает их по размеру
+const maxPhotoSize = 10 << 20
+
+// knownFields — поля EventPayload, которые не нужно дублировать в RawPayload
+var knownFields = map[string]bool{
+	"camera_id": true, "camera_model": true, "plate": true, "confidence": true,
+	"direction": true, "lane": true, "event_time": true, "vehicle": true,
+	"snapshot_url": true, "raw_payload": true, "source": true,
+	"snow_volume_percentage": true,
+	"snow_volume_confidence": true, "snow_volume_m3": true, "matched_snow": true,
+}
+
+// Adapter разбирает события в формате EventPayload
+type Adapter struct {
+	// Strict — проверять событие по eventSchema (INGEST_PAYLOAD_VALIDATION=strict); nil — без проверки
+	Strict func() bool
+	Log    zerolog.Logger
+}
+
+// Name возвращает ingest.AdapterGeneric
+func (a *Adapter) Name() string {
+	return ingest.AdapterGeneric
+}
+
+// Parse разбирает JSON-тело или multipart с полем event и снимками photos. Снимок, который не удалось
+// прочитать, пропускается: событие важнее фото.
+func (a *Adapter) Parse(ctx context.Context, req *ingest.Request) (*ingest.Result, error) {
+	mediaType, params, _ := mime.ParseMediaType(req.ContentType)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if err := a.validate("invalid event payload", req.Body); err != nil {
+			return nil, err
+		}
+		var payload anpr.EventPayload
+		if err := json.Unmarshal(req.Body, &payload); err != nil {
+			return nil, &ingest.PayloadError{Message: "failed to parse request: " + err.Error(), Err: err}
+		}
+		return &ingest.Result{Payload: &payload}, nil
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"]).ReadForm(multipartMaxMemory)
+	if err != nil {
+		return nil, &ingest.PayloadError{Message: "failed to parse multipart form", Err: err}
+	}
+	defer form.RemoveAll()
+
+	var eventJSON string
+	if values := form.Value["event"]; len(values) > 0 {
+		eventJSON = values[0]
+	}
+	if eventJSON == "" {
+		return nil, &ingest.PayloadError{Message: "event field is required"}
+	}
+	if err := a.validate("invalid event JSON", []byte(eventJSON)); err != nil {
+		return nil, err
+	}
+	payload, err := parseEventJSON([]byte(eventJSON))
+	if err != nil {
+		return nil, &ingest.PayloadError{Message: "invalid event JSON: " + err.Error(), Err: err}
+	}
+
+	result := &ingest.Result{Payload: payload}
+	for _, fh := range form.File["photos"] {
+		photo, err := readPhoto(fh)
+		if err != nil {
+			logctx.From(ctx, &a.Log).Warn().Err(err).Str("filename", fh.Filename).Msg("failed to read photo")
+			continue
+		}
+		result.Photos = append(result.Photos, photo)
+	}
+	return result, nil
+}
+
+// validate проверяет событие по eventSchema в строгом режиме
+func (a *Adapter) validate(message string, data []byte) error {
+	if a.Strict == nil || !a.Strict() {
+		return nil
+	}
+	if fields := validateEvent(data); len(fields) > 0 {
+		return &ingest.PayloadError{Message: message, Fields: fields}
+	}
+	return nil
+}
+
+// parseEventJSON разбирает событие из multipart. Поля, которых нет в EventPayload, сохраняются в RawPayload,
+// отсутствующие поля снега заполняются нулями: их ждут отчёты по объёму снега.
+func parseEventJSON(data []byte) (*anpr.EventPayload, error) {
+	var eventMap map[string]interface{}
+	if err := json.Unmarshal(data, &eventMap); err != nil {
+		return nil, err
+	}
+	var payload anpr.EventPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.SnowVolumePercentage == nil {
+		value := 0.0
+		payload.SnowVolumePercentage = &value
+	}
+	if payload.SnowVolumeConfidence == nil {
+		value := 0.0
+		payload.SnowVolumeConfidence = &value
+	}
+
+	if payload.RawPayload == nil {
+		payload.RawPayload = make(map[string]interface{})
+	}
+	for key, value := range eventMap {
+		if !knownFields[key] && value != nil {
+			payload.RawPayload[key] = value
+		}
+	}
+	return &payload, nil
+}
+
+func readPhoto(fh *multipart.FileHeader) (ingest.Photo, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return ingest.Photo{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxPhotoSize+1))
+	if err != nil {
+		return ingest.Photo{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	return ingest.Photo{Name: fh.Filename, ContentType: fh.Header.Get("Content-Type"), Data: data}, nil
+}
@@ -0,0 +1,177 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/ingest"
+)
+
+func TestAdapterParse(t *testing.T) {
+	formType, form := multipartBody(t, `{"camera_id":"cam-1","plate":"123ABC02","vehicle_color":"white"}`, 2)
+	noEventType, noEvent := multipartBody(t, "", 1)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantErr     string
+		wantPlate   string
+		wantPhotos  int
+		wantRaw     string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        []byte(`{"camera_id":"cam-1","plate":"123ABC02"}`),
+			wantPlate:   "123ABC02",
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        []byte(`{`),
+			wantErr:     "failed to parse request: unexpected end of JSON input",
+		},
+		{
+			name:        "multipart with photos",
+			contentType: formType,
+			body:        form,
+			wantPlate:   "123ABC02",
+			wantPhotos:  2,
+			wantRaw:     "vehicle_color",
+		},
+		{
+			name:        "multipart without event",
+			contentType: noEventType,
+			body:        noEvent,
+			wantErr:     "event field is required",
+		},
+	}
+
+	adapter := &Adapter{Log: zerolog.Nop()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := adapter.Parse(context.Background(), &ingest.Request{ContentType: tt.contentType, Body: tt.body})
+			if tt.wantErr != "" {
+				var payloadErr *ingest.PayloadError
+				if !errors.As(err, &payloadErr) || payloadErr.Message != tt.wantErr {
+					t.Fatalf("err = %v, want payload error %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Payload.Plate != tt.wantPlate || len(result.Photos) != tt.wantPhotos {
+				t.Fatalf("result = %+v, want %s with %d photos", result, tt.wantPlate, tt.wantPhotos)
+			}
+			if tt.wantRaw != "" {
+				if _, ok := result.Payload.RawPayload[tt.wantRaw]; !ok {
+					t.Errorf("raw_payload = %v, want %s", result.Payload.RawPayload, tt.wantRaw)
+				}
+			}
+		})
+	}
+}
+
+func TestAdapterParseStrict(t *testing.T) {
+	garbageType, garbage := multipartBody(t, `{"camera_id":"cam-1","plate":"123ABC02","snow_volume_percentage":300}`, 0)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		legacy      bool
+		wantErr     string
+		wantFields  []ingest.FieldError
+	}{
+		{
+			name: "valid",
+			body: `{"camera_id":"cam-1","plate":"123ABC02","confidence":91.5,"direction":"Exit","lane":1,` +
+				`"event_time":"2025-01-15T22:00:00+05:00","vehicle":{"speed":12},"snow_volume_confidence":0.9,"extra":1}`,
+		},
+		{
+			name: "garbage",
+			body: `{"camera_id":"","confidence":-1,"direction":"sideways","lane":1.5,"event_time":"15.01.2025 22:00",` +
+				`"vehicle":{"speed":"fast"},"snow_volume_percentage":300,"matched_snow":"yes"}`,
+			wantErr: "invalid event payload",
+			wantFields: []ingest.FieldError{
+				{Field: "camera_id", Message: "is required"},
+				{Field: "plate", Message: "is required"},
+				{Field: "confidence", Message: "must be between 0 and 100"},
+				{Field: "direction", Message: "must be one of entry, exit, unknown"},
+				{Field: "lane", Message: "must be a non-negative integer"},
+				{Field: "event_time", Message: "must be an RFC3339 time"},
+				{Field: "vehicle.speed", Message: "must be a number"},
+				{Field: "snow_volume_percentage", Message: "must be between 0 and 100"},
+				{Field: "matched_snow", Message: "must be a boolean"},
+			},
+		},
+		{
+			name:        "multipart",
+			contentType: garbageType,
+			body:        string(garbage),
+			wantErr:     "invalid event JSON",
+			wantFields:  []ingest.FieldError{{Field: "snow_volume_percentage", Message: "must be between 0 and 100"}},
+		},
+		{
+			name:   "legacy accepts garbage",
+			body:   `{"camera_id":"cam-1","plate":"123ABC02","confidence":-1,"snow_volume_percentage":300}`,
+			legacy: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &Adapter{Strict: func() bool { return !tt.legacy }, Log: zerolog.Nop()}
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			result, err := adapter.Parse(context.Background(), &ingest.Request{ContentType: contentType, Body: []byte(tt.body)})
+			if tt.wantErr == "" {
+				if err != nil || result.Payload == nil {
+					t.Fatalf("Parse() = %+v, %v", result, err)
+				}
+				return
+			}
+			var payloadErr *ingest.PayloadError
+			if !errors.As(err, &payloadErr) || payloadErr.Message != tt.wantErr {
+				t.Fatalf("err = %v, want payload error %q", err, tt.wantErr)
+			}
+			if !slices.Equal(payloadErr.Fields, tt.wantFields) {
+				t.Fatalf("fields = %+v, want %+v", payloadErr.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func multipartBody(t *testing.T, event string, photos int) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if event != "" {
+		if err := w.WriteField("event", event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < photos; i++ {
+		part, err := w.CreateFormFile("photos", "plate.jpg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte("jpeg")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return w.FormDataContentType(), buf.Bytes()
+}
@@ -0,0 +1,170 @@
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"anpr-service/internal/ingest"
+)
+
+// fieldRule — требование к полю события. check возвращает описание ошибки или пустую строку; null
+// равносилен отсутствию поля.
+type fieldRule struct {
+	name     string
+	required bool
+	check    func(value any) string
+	// fields — правила вложенного объекта
+	fields []fieldRule
+}
+
+// eventSchema — схема EventPayload в строгом режиме. Поля, которых нет в схеме, не проверяются:
+// интеграции присылают свои поля, они сохраняются в RawPayload.
+var eventSchema = []fieldRule{
+	{name: "camera_id", required: true, check: nonEmptyString},
+	{name: "plate", required: true, check: nonEmptyString},
+	{name: "camera_model", check: isString},
+	// Hikvision и симулятор присылают уверенность в процентах, другие интеграции — долей единицы
+	{name: "confidence", check: numberBetween(0, 100)},
+	{name: "direction", check: oneOf("entry", "exit", "unknown")},
+	{name: "lane", check: nonNegativeInteger},
+	{name: "event_time", check: rfc3339Time},
+	{name: "received_at", check: rfc3339Time},
+	{name: "source", check: isString},
+	{name: "vehicle", check: isObject, fields: []fieldRule{
+		{name: "color", check: isString},
+		{name: "type", check: isString},
+		{name: "brand", check: isString},
+		{name: "model", check: isString},
+		{name: "country", check: isString},
+		{name: "plate_color", check: isString},
+		{name: "speed", check: numberBetween(0, math.Inf(1))},
+	}},
+	{name: "snapshot_url", check: isString},
+	{name: "raw_payload", check: isObject},
+	{name: "snow_volume_percentage", check: numberBetween(0, 100)},
+	{name: "snow_volume_confidence", check: numberBetween(0, 1)},
+	{name: "snow_volume_m3", check: numberBetween(0, math.Inf(1))},
+	{name: "matched_snow", check: isBool},
+}
+
+// validateEvent проверяет JSON события по eventSchema. Тело, которое не является JSON-объектом, не
+// проверяется: его отвергает разбор в EventPayload.
+func validateEvent(data []byte) []ingest.FieldError {
+	var event map[string]any
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil
+	}
+	return validateFields("", event, eventSchema)
+}
+
+func validateFields(prefix string, object map[string]any, rules []fieldRule) []ingest.FieldError {
+	var problems []ingest.FieldError
+	for _, rule := range rules {
+		path := prefix + rule.name
+		value := object[rule.name]
+		if value == nil {
+			if rule.required {
+				problems = append(problems, ingest.FieldError{Field: path, Message: "is required"})
+			}
+			continue
+		}
+		if message := rule.check(value); message != "" {
+			problems = append(problems, ingest.FieldError{Field: path, Message: message})
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok && len(rule.fields) > 0 {
+			problems = append(problems, validateFields(path+".", nested, rule.fields)...)
+		}
+	}
+	return problems
+}
+
+func isString(value any) string {
+	if _, ok := value.(string); !ok {
+		return "must be a string"
+	}
+	return ""
+}
+
+func nonEmptyString(value any) string {
+	s, ok := value.(string)
+	if !ok {
+		return "must be a string"
+	}
+	if strings.TrimSpace(s) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+func isBool(value any) string {
+	if _, ok := value.(bool); !ok {
+		return "must be a boolean"
+	}
+	return ""
+}
+
+func isObject(value any) string {
+	if _, ok := value.(map[string]any); !ok {
+		return "must be an object"
+	}
+	return ""
+}
+
+func numberBetween(minValue, maxValue float64) func(any) string {
+	return func(value any) string {
+		number, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		if number < minValue || number > maxValue {
+			if math.IsInf(maxValue, 1) {
+				return fmt.Sprintf("must not be less than %g", minValue)
+			}
+			return fmt.Sprintf("must be between %g and %g", minValue, maxValue)
+		}
+		return ""
+	}
+}
+
+func nonNegativeInteger(value any) string {
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || number < 0 || number > math.MaxInt32 {
+		return "must be a non-negative integer"
+	}
+	return ""
+}
+
+// oneOf — строка из перечисления без учёта регистра; пустая строка допустима (направление не определено)
+func oneOf(allowed ...string) func(any) string {
+	return func(value any) string {
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		s = strings.ToLower(s)
+		if s == "" {
+			return ""
+		}
+		for _, candidate := range allowed {
+			if s == candidate {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(allowed, ", ")
+	}
+}
+
+func rfc3339Time(value any) string {
+	s, ok := value.(string)
+	if !ok {
+		return "must be an RFC3339 time"
+	}
+	if _, err := time.Parse(time.RFC3339, s); err != nil {
+		return "must be an RFC3339 time"
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+// Package hikvisionpush — адаптер уведомлений, которые камеры Hikvision присылают по HTTP (ISAPI
+// EventNotificationAlert в multipart, XML или JSON) или выгружают по FTP
+package hikvisionpush
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/logctx"
+)
+
+// RawJSONKey — ключ raw_payload с исходным JSON-уведомлением (XML хранится под ключом xml)
+const RawJSONKey = "hikvision_json"
+
+// Locator возвращает часовой пояс камеры: камеры часто шлют dateTime в локальном времени без смещения
+type Locator interface {
+	CameraLocation(ctx context.Context, cameraID string) *time.Location
+}
+
+// Adapter разбирает уведомления Hikvision
+type Adapter struct {
+	// Parts — имена частей multipart, в которых уведомление ищется в первую очередь
+	Parts []string
+	// DefaultCameraID — камера уведомления без channelID/deviceID и без camera_id в адресе
+	DefaultCameraID string
+	Locator         Locator
+	Log             zerolog.Logger
+}
+
+// Name возвращает ingest.AdapterHikvision
+func (a *Adapter) Name() string {
+	return ingest.AdapterHikvision
+}
+
+// Parse находит уведомление в запросе и разбирает его. Камерой уведомления без channelID/deviceID
+// считается camera_id из адреса запроса, затем DefaultCameraID.
+func (a *Adapter) Parse(ctx context.Context, req *ingest.Request) (*ingest.Result, error) {
+	notification, err := hikvision.ExtractNotification(req.ContentType, req.Body, a.Parts)
+	if err != nil {
+		if errors.Is(err, hikvision.ErrXMLNotFound) {
+			return nil, &ingest.PayloadError{Message: "xml payload not found", Err: err}
+		}
+		return nil, &ingest.PayloadError{Message: "invalid multipart payload", Err: err}
+	}
+
+	logctx.From(ctx, &a.Log).Debug().
+		Str("format", notification.Format).
+		Str("part", notification.Part).
+		Int("payload_size", len(notification.Body)).
+		Str("payload_preview", string(notification.Body[:min(200, len(notification.Body))])).
+		Msg("extracted notification payload")
+
+	return a.parseNotification(ctx, notification, req.Query.Get("camera_id"))
+}
+
+// parseNotification разбирает найденное в запросе уведомление
+func (a *Adapter) parseNotification(ctx context.Context, notification *hikvision.Notification, fallbackCameraID string) (*ingest.Result, error) {
+	hikEvent, err := notification.Event()
+	if err != nil {
+		return nil, &ingest.PayloadError{Message: "invalid " + notification.Format + " payload", Err: err}
+	}
+
+	cameraID := hikEvent.CameraID()
+	if cameraID == "" {
+		cameraID = fallbackCameraID
+		if cameraID == "" {
+			cameraID = a.DefaultCameraID
+		}
+	}
+
+	log := logctx.From(ctx, &a.Log)
+	if hikEvent.IsSignal() {
+		log.Debug().
+			Str("format", notification.Format).
+			Str("event_type", hikEvent.EventType).
+			Str("event_state", hikEvent.EventState).
+			Str("camera_id", cameraID).
+			Msg("parsed Hikvision camera signal")
+		return &ingest.Result{Format: notification.Format, Signal: &ingest.Signal{
+			CameraID:  cameraID,
+			EventType: hikEvent.EventType,
+			VideoLoss: hikEvent.VideoLoss(),
+		}}, nil
+	}
+
+	log.Info().
+		Str("format", notification.Format).
+		Str("event_type", hikEvent.EventType).
+		Str("license_plate", hikEvent.ANPR.LicensePlate).
+		Str("device_id", hikEvent.DeviceID).
+		Str("channel_id", hikEvent.ChannelID).
+		Str("date_time", hikEvent.DateTime).
+		Str("vehicle_info_color", hikEvent.VehicleInfo.Color).
+		Str("vehicle_info_brand", hikEvent.VehicleInfo.Brand).
+		Str("vehicle_info_logo_recog", hikEvent.VehicleInfo.VehicleLogoRecog).
+		Str("vehicle_info_model", hikEvent.VehicleInfo.Model).
+		Str("vehicle_info_vehile_model", hikEvent.VehicleInfo.VehileModel).
+		Str("gat_color", hikEvent.VehicleGATInfo.ColorByGAT).
+		Msg("parsed Hikvision event")
+
+	var rawXML []byte
+	if notification.Format == hikvision.FormatXML {
+		rawXML = notification.Body
+	}
+	var loc *time.Location
+	if a.Locator != nil {
+		loc = a.Locator.CameraLocation(ctx, cameraID)
+	}
+	payload := hikEvent.ToEventPayload(rawXML, loc)
+	if notification.Format == hikvision.FormatJSON {
+		payload.RawPayload[RawJSONKey] = string(notification.Body)
+	}
+	payload.CameraID = cameraID
+	return &ingest.Result{Format: notification.Format, Payload: &payload}, nil
+}
@@ -0,0 +1,75 @@
+package hikvisionpush
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/ingest"
+)
+
+func TestAdapterParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      url.Values
+		body       string
+		wantErr    string
+		wantCamera string
+		wantPlate  string
+		wantSignal bool
+	}{
+		{
+			name:       "xml event with default camera",
+			body:       `<EventNotificationAlert><eventType>ANPR</eventType><ANPR><licensePlate>123ABC02</licensePlate></ANPR></EventNotificationAlert>`,
+			wantCamera: "cam-default",
+			wantPlate:  "123ABC02",
+		},
+		{
+			name:       "camera from query",
+			query:      url.Values{"camera_id": {"cam-query"}},
+			body:       `<EventNotificationAlert><eventType>ANPR</eventType><ANPR><licensePlate>123ABC02</licensePlate></ANPR></EventNotificationAlert>`,
+			wantCamera: "cam-query",
+			wantPlate:  "123ABC02",
+		},
+		{
+			name:       "heartbeat is a signal",
+			body:       `<EventNotificationAlert><eventType>heartBeat</eventType><eventState>active</eventState></EventNotificationAlert>`,
+			wantCamera: "cam-default",
+			wantSignal: true,
+		},
+		{
+			name:    "not a notification",
+			body:    "hello",
+			wantErr: "invalid multipart payload",
+		},
+	}
+
+	adapter := &Adapter{DefaultCameraID: "cam-default", Log: zerolog.Nop()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := adapter.Parse(context.Background(), &ingest.Request{Query: tt.query, Body: []byte(tt.body)})
+			if tt.wantErr != "" {
+				var payloadErr *ingest.PayloadError
+				if !errors.As(err, &payloadErr) || payloadErr.Message != tt.wantErr {
+					t.Fatalf("err = %v, want payload error %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantSignal {
+				if result.Signal == nil || result.Signal.CameraID != tt.wantCamera {
+					t.Fatalf("signal = %+v, want camera %s", result.Signal, tt.wantCamera)
+				}
+				return
+			}
+			if result.Payload == nil || result.Payload.CameraID != tt.wantCamera || result.Payload.Plate != tt.wantPlate {
+				t.Fatalf("payload = %+v, want %s on %s", result.Payload, tt.wantPlate, tt.wantCamera)
+			}
+		})
+	}
+}
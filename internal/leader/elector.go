@@ -0,0 +1,261 @@
+// Package leader выбирает среди реплик сервиса одну, на которой выполняются периодические задачи
+// (очистка, обслуживание секций, синхронизация белого списка, опросы внешних сервисов).
+//
+// Лидер — реплика, удерживающая advisory-блокировку Postgres pg_try_advisory_lock(LEADER_LOCK_KEY).
+// Блокировка живёт, пока открыто соединение, поэтому лидер держит для неё отдельное соединение
+// из пула и периодически проверяет его. Если соединение потеряно (перезапуск БД, сетевой сбой),
+// Postgres снимает блокировку сам, и лидером становится другая реплика.
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Options — настройки выбора лидера
+type Options struct {
+	// LockKey — ключ advisory-блокировки; у всех реплик одного сервиса должен совпадать
+	LockKey int64
+	// RetryInterval — как часто реплика, не ставшая лидером, пробует взять блокировку
+	RetryInterval time.Duration
+	// CheckInterval — как часто лидер проверяет соединение, удерживающее блокировку
+	CheckInterval time.Duration
+}
+
+// Stats — состояние выбора лидера для /health/full
+type Stats struct {
+	Enabled bool `json:"enabled"`
+	Leader  bool `json:"leader"`
+	// Since — с какого момента реплика лидер (или перестала им быть)
+	Since *time.Time `json:"since,omitempty"`
+	// Acquired — сколько раз реплика становилась лидером с момента запуска
+	Acquired int64 `json:"acquired"`
+	// Jobs — задачи, выполняемые только на лидере
+	Jobs      []string `json:"jobs"`
+	LastError string   `json:"last_error,omitempty"`
+}
+
+// Elector следит за advisory-блокировкой и запускает задачи, пока реплика остаётся лидером.
+// Nil-Elector означает, что выбор лидера выключен: реплика всегда лидер.
+type Elector struct {
+	db   *sql.DB
+	opts Options
+	log  zerolog.Logger
+	now  func() time.Time
+
+	mu       sync.Mutex
+	leader   bool
+	since    time.Time
+	acquired int64
+	lastErr  string
+	jobs     []string
+	// changed закрывается и заменяется при каждой смене лидерства
+	changed chan struct{}
+}
+
+// New создаёт Elector; выбор начинается с вызова Run
+func New(db *sql.DB, opts Options, log zerolog.Logger) *Elector {
+	return &Elector{
+		db:      db,
+		opts:    opts,
+		log:     log.With().Str("component", "leader").Logger(),
+		now:     time.Now,
+		changed: make(chan struct{}),
+	}
+}
+
+// IsLeader сообщает, является ли реплика лидером
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Stats возвращает состояние выбора лидера
+func (e *Elector) Stats() Stats {
+	if e == nil {
+		return Stats{Leader: true, Jobs: []string{}}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := Stats{
+		Enabled:   true,
+		Leader:    e.leader,
+		Acquired:  e.acquired,
+		Jobs:      append([]string{}, e.jobs...),
+		LastError: e.lastErr,
+	}
+	if !e.since.IsZero() {
+		since := e.since
+		stats.Since = &since
+	}
+	return stats
+}
+
+// Run пробует стать лидером и удерживает лидерство до отмены ctx; при отмене блокировка снимается
+func (e *Elector) Run(ctx context.Context) {
+	for {
+		conn, err := e.acquire(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				e.log.Warn().Err(err).Msg("failed to acquire leader lock")
+				e.setError(err)
+			}
+		case conn != nil:
+			e.hold(ctx, conn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.opts.RetryInterval):
+		}
+	}
+}
+
+// acquire берёт отдельное соединение и пробует взять на нём блокировку. nil, nil — лидер другая реплика.
+func (e *Elector) acquire(ctx context.Context) (*sql.Conn, error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.opts.LockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("pg_try_advisory_lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		e.setError(nil)
+		return nil, nil
+	}
+	return conn, nil
+}
+
+// hold удерживает лидерство, пока соединение с блокировкой отвечает и ctx не отменён
+func (e *Elector) hold(ctx context.Context, conn *sql.Conn) {
+	e.setLeader(true)
+	e.log.Info().Int64("lock_key", e.opts.LockKey).Msg("became leader")
+
+	ticker := time.NewTicker(e.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.release(conn)
+			return
+		case <-ticker.C:
+		}
+		if err := conn.PingContext(ctx); err != nil {
+			if ctx.Err() != nil {
+				e.release(conn)
+				return
+			}
+			// Соединение потеряно — вместе с ним Postgres снял блокировку
+			e.log.Warn().Err(err).Msg("leader lock connection lost, stepping down")
+			e.setError(err)
+			e.setLeader(false)
+			conn.Close()
+			return
+		}
+	}
+}
+
+// release отдаёт лидерство при остановке: задачи останавливаются, блокировка снимается явно,
+// чтобы другая реплика не ждала закрытия соединения
+func (e *Elector) release(conn *sql.Conn) {
+	e.setLeader(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", e.opts.LockKey); err != nil {
+		e.log.Warn().Err(err).Msg("failed to release leader lock")
+	}
+	conn.Close()
+	e.log.Info().Msg("leader lock released")
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader == leader {
+		return
+	}
+	e.leader = leader
+	e.since = e.now()
+	if leader {
+		e.acquired++
+		e.lastErr = ""
+	}
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (e *Elector) setError(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		e.lastErr = ""
+		return
+	}
+	e.lastErr = err.Error()
+}
+
+// state возвращает текущее лидерство и канал, который закроется при его смене
+func (e *Elector) state() (bool, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader, e.changed
+}
+
+// RunWhileLeader выполняет job, пока реплика лидер: при получении лидерства job запускается,
+// при потере — её контекст отменяется, и RunWhileLeader ждёт завершения job до следующего запуска.
+// На nil-Elector job просто выполняется. Возвращается после отмены ctx и завершения job.
+func (e *Elector) RunWhileLeader(ctx context.Context, name string, job func(context.Context)) {
+	if e == nil {
+		job(ctx)
+		return
+	}
+	e.mu.Lock()
+	e.jobs = append(e.jobs, name)
+	e.mu.Unlock()
+
+	for {
+		leader, changed := e.state()
+		if !leader {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			job(jobCtx)
+		}()
+		e.log.Debug().Str("job", name).Msg("leader job started")
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		}
+		cancel()
+		<-done
+		if ctx.Err() != nil {
+			return
+		}
+		e.log.Debug().Str("job", name).Msg("leader job stopped")
+	}
+}
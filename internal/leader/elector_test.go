@@ -0,0 +1,84 @@
+package leader
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRunWhileLeader(t *testing.T) {
+	e := New(nil, Options{}, zerolog.Nop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, starts atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.RunWhileLeader(ctx, "purge", func(jobCtx context.Context) {
+			starts.Add(1)
+			running.Store(1)
+			<-jobCtx.Done()
+			running.Store(0)
+		})
+	}()
+
+	waitFor := func(name string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor("job registration", func() bool { return len(e.Stats().Jobs) == 1 })
+	time.Sleep(10 * time.Millisecond)
+	if running.Load() != 0 {
+		t.Fatal("job must not run before leadership is acquired")
+	}
+
+	e.setLeader(true)
+	waitFor("job start", func() bool { return running.Load() == 1 })
+
+	e.setLeader(false)
+	waitFor("job stop", func() bool { return running.Load() == 0 })
+
+	e.setLeader(true)
+	waitFor("job restart", func() bool { return starts.Load() == 2 && running.Load() == 1 })
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWhileLeader did not return after cancel")
+	}
+	if running.Load() != 0 {
+		t.Fatal("job must be stopped after cancel")
+	}
+
+	stats := e.Stats()
+	if !stats.Enabled || !stats.Leader || stats.Acquired != 2 || stats.Since == nil {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestNilElector(t *testing.T) {
+	var e *Elector
+	if !e.IsLeader() {
+		t.Fatal("disabled election must treat the replica as leader")
+	}
+	if stats := e.Stats(); stats.Enabled || !stats.Leader {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	ran := false
+	e.RunWhileLeader(context.Background(), "job", func(context.Context) { ran = true })
+	if !ran {
+		t.Fatal("job must run when election is disabled")
+	}
+}
@@ -0,0 +1,115 @@
+// Package lifecycle управляет фоновыми воркерами сервиса при остановке. Воркер получает контекст,
+// отменяемый в начале остановки: по нему он перестаёт брать новую работу. Начатая работа (пачка
+// outbox, запуск задачи, файл FTP-выгрузки) выполняется в контексте Detach и прерывается только
+// по истечении срока остановки, поэтому записи в БД не обрываются на середине.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type hardKey struct{}
+
+// Group — набор фоновых воркеров с общей остановкой
+type Group struct {
+	log zerolog.Logger
+
+	stop       context.Context
+	cancelStop context.CancelFunc
+	hard       context.Context
+	cancelHard context.CancelFunc
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// New создаёт группу воркеров
+func New(log zerolog.Logger) *Group {
+	hard, cancelHard := context.WithCancel(context.Background())
+	stop, cancelStop := context.WithCancel(context.WithValue(context.Background(), hardKey{}, hard))
+	return &Group{
+		log:        log.With().Str("component", "lifecycle").Logger(),
+		stop:       stop,
+		cancelStop: cancelStop,
+		hard:       hard,
+		cancelHard: cancelHard,
+		running:    map[string]int{},
+	}
+}
+
+// Context возвращает контекст воркеров: он отменяется в начале остановки
+func (g *Group) Context() context.Context {
+	return g.stop
+}
+
+// Go запускает воркер. fn должна вернуться после отмены ctx, закончив начатую работу.
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.mu.Lock()
+	g.running[name]++
+	g.mu.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			g.running[name]--
+			if g.running[name] == 0 {
+				delete(g.running, name)
+			}
+			g.mu.Unlock()
+			g.wg.Done()
+		}()
+		fn(g.stop)
+	}()
+}
+
+// Shutdown отменяет контекст воркеров и ждёт их завершения до истечения ctx. Если срок истёк,
+// начатая работа прерывается (отменяются контексты Detach), а ошибка перечисляет незавершённые воркеры.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.cancelStop()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancelHard()
+		g.log.Info().Msg("background workers stopped")
+		return nil
+	case <-ctx.Done():
+	}
+	g.cancelHard()
+
+	g.mu.Lock()
+	names := make([]string, 0, len(g.running))
+	for name := range g.running {
+		names = append(names, name)
+	}
+	g.mu.Unlock()
+	sort.Strings(names)
+	return fmt.Errorf("workers did not stop in time: %s", strings.Join(names, ", "))
+}
+
+// Detach возвращает контекст для начатой работы воркера: с теми же значениями (логгер, трассировка), но
+// не отменяемый вместе с ctx. Он отменяется, только когда истекает срок остановки группы, в которой
+// запущен воркер. Вне группы Detach ведёт себя как context.WithCancel(ctx).
+func Detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	hard, ok := ctx.Value(hardKey{}).(context.Context)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	detached, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(hard, cancel)
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,74 @@
+package lifecycle
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestShutdownDrainsWorkers(t *testing.T) {
+	g := New(zerolog.Nop())
+	saved := make(chan struct{})
+	g.Go("relay", func(ctx context.Context) {
+		<-ctx.Done()
+		// Начатая работа продолжается после отмены контекста воркера
+		work, cancel := Detach(ctx)
+		defer cancel()
+		select {
+		case <-work.Done():
+			t.Error("detached context canceled before shutdown deadline")
+		case <-time.After(20 * time.Millisecond):
+			close(saved)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-saved:
+	default:
+		t.Fatal("worker did not finish its work")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	g := New(zerolog.Nop())
+	aborted := make(chan struct{})
+	g.Go("stuck", func(ctx context.Context) {
+		work, cancel := Detach(ctx)
+		defer cancel()
+		<-work.Done()
+		close(aborted)
+	})
+	g.Go("fast", func(ctx context.Context) { <-ctx.Done() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := g.Shutdown(ctx)
+	if err == nil || !strings.Contains(err.Error(), "stuck") || strings.Contains(err.Error(), "fast") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("detached work was not canceled after deadline")
+	}
+}
+
+func TestDetachOutsideGroup(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	work, cancel := Detach(parent)
+	defer cancel()
+	cancelParent()
+	select {
+	case <-work.Done():
+	case <-time.After(time.Second):
+		t.Fatal("detached context outside group should follow parent")
+	}
+}
@@ -0,0 +1,36 @@
+// Package logctx — логгер запроса в context.Context. Middleware кладёт в контекст логгер с request_id,
+// а handler, service и repository (через логгер GORM) берут его оттуда, поэтому все строки лога
+// одного запроса связаны общим идентификатором.
+package logctx
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из контекста (пусто — запрос без идентификатора)
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// From возвращает логгер запроса из ctx, а если его нет — fallback
+func From(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if ctx != nil {
+		if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+			return l
+		}
+	}
+	return fallback
+}
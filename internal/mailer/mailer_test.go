@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	csv := []byte(strings.Repeat("event_id,plate\n", 20))
+	data, err := buildMessage("anpr@example.com", Message{
+		To:          []string{"inspector@example.com"},
+		Subject:     "Выгрузка событий",
+		Body:        "Событий: 20",
+		Attachments: []Attachment{{Filename: "events.csv", ContentType: "text/csv", Data: csv}},
+	}, time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Выгрузка событий" {
+		t.Errorf("subject = %q (%v)", subject, err)
+	}
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []*multipart.Part
+	var bodies [][]byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := io.ReadAll(part)
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		parts = append(parts, part)
+		bodies = append(bodies, decoded)
+	}
+	if len(parts) != 2 || string(bodies[0]) != "Событий: 20" {
+		t.Fatalf("got %d parts, body %q", len(parts), bodies)
+	}
+	if parts[1].FileName() != "events.csv" || !bytes.Equal(bodies[1], csv) {
+		t.Errorf("attachment %q = %q", parts[1].FileName(), bodies[1])
+	}
+}
@@ -0,0 +1,107 @@
+// Package metrics пишет метрики в текстовом формате Prometheus (text/plain; version=0.0.4). Метрик у сервиса
+// немного, поэтому клиентская библиотека Prometheus не нужна: значения собираются при каждом опросе.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType — Content-Type ответа с метриками
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Типы метрик (строка # TYPE)
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+	TypeSummary = "summary"
+)
+
+// Label — метка значения метрики
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Writer пишет метрики; первая ошибка записи сохраняется и возвращается из Flush
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Header пишет описание метрики (# HELP и # TYPE); вызывается один раз перед её значениями
+func (w *Writer) Header(name, help, metricType string) {
+	w.write("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.write("# TYPE " + name + " " + metricType + "\n")
+}
+
+// Sample пишет значение метрики name с метками labels
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(label.Name)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabel(label.Value))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatValue(value))
+	b.WriteByte('\n')
+	w.write(b.String())
+}
+
+// Flush дописывает буфер и возвращает первую ошибку записи
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) write(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.w.WriteString(s)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Header("anpr_ingest_latency_seconds", "Latency from event_time\nto commit", TypeSummary)
+	w.Sample("anpr_ingest_latency_seconds", 1.5, Label{"camera_id", `gate "1"\north`}, Label{"quantile", "0.5"})
+	w.Sample("anpr_ingest_latency_seconds_count", 42)
+	w.Sample("anpr_ingest_latency_seconds", math.NaN(), Label{"camera_id", "cam-2"}, Label{"quantile", "0.99"})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP anpr_ingest_latency_seconds Latency from event_time\nto commit
+# TYPE anpr_ingest_latency_seconds summary
+anpr_ingest_latency_seconds{camera_id="gate \"1\"\\north",quantile="0.5"} 1.5
+anpr_ingest_latency_seconds_count 42
+anpr_ingest_latency_seconds{camera_id="cam-2",quantile="0.99"} NaN
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import "context"
+
+type principalKey struct{}
+
+// WithPrincipal сохраняет пользователя запроса в контексте, чтобы сервисы могли проверить его права
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает пользователя запроса; false — контекст не из авторизованного запроса
+// (фоновая задача, межсервисный вызов)
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,106 @@
+// Package mqtt публикует сообщения в MQTT-брокер (SCADA полигона).
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+)
+
+// Publisher — подключение к брокеру с автоматическим переподключением
+type Publisher struct {
+	client  paho.Client
+	qos     byte
+	retain  bool
+	timeout time.Duration
+	log     zerolog.Logger
+}
+
+// NewPublisher подключается к брокеру. Недоступность брокера при старте не ошибка: клиент
+// переподключается в фоне, а сообщения ждут в outbox.
+func NewPublisher(cfg config.MQTTConfig, log zerolog.Logger) (*Publisher, error) {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		host, _ := os.Hostname()
+		clientID = "anpr-service-" + host
+	}
+
+	p := &Publisher{
+		qos:     byte(cfg.QoS),
+		retain:  cfg.Retain,
+		timeout: cfg.PublishTimeout,
+		log:     log.With().Str("component", "mqtt").Logger(),
+	}
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.BrokerURL).
+		SetClientID(clientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(5 * time.Second).
+		SetOnConnectHandler(func(paho.Client) {
+			p.log.Info().Str("broker", cfg.BrokerURL).Msg("connected to mqtt broker")
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			p.log.Warn().Err(err).Msg("mqtt connection lost")
+		})
+	p.client = paho.NewClient(opts)
+
+	token := p.client.Connect()
+	if token.WaitTimeout(cfg.PublishTimeout) && token.Error() != nil {
+		return nil, fmt.Errorf("connect to mqtt broker: %w", token.Error())
+	}
+	return p, nil
+}
+
+// Publish публикует сообщение и ждёт подтверждения брокера (для QoS 0 — только отправки)
+func (p *Publisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	if !p.client.IsConnectionOpen() {
+		return errors.New("mqtt broker is not connected")
+	}
+	token := p.client.Publish(topic, p.qos, p.retain, payload)
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("mqtt publish: %w", err)
+		}
+		return nil
+	case <-timer.C:
+		return errors.New("mqtt publish timed out")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close отключается от брокера, дав до секунды на отправку буферизованных сообщений
+func (p *Publisher) Close() {
+	p.client.Disconnect(1000)
+}
+
+// Topic возвращает топик камеры: <prefix>/<camera_id>. Символы, недопустимые в имени топика
+// при публикации (/, +, #), в идентификаторе камеры заменяются на "_".
+func Topic(prefix, cameraID string) string {
+	cameraID = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#':
+			return '_'
+		}
+		return r
+	}, cameraID)
+	if cameraID == "" {
+		cameraID = "unknown"
+	}
+	return prefix + "/" + cameraID
+}
@@ -0,0 +1,249 @@
+// Package openapi строит спецификацию OpenAPI 3 по описанию маршрутов и Go-типам их запросов и ответов.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version — версия формата спецификации
+const Version = "3.0.3"
+
+// Document — спецификация OpenAPI
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem — операции пути по HTTP-методу (в нижнем регистре)
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Схемы авторизации маршрутов
+const (
+	AuthNone     = ""
+	AuthBearer   = "bearerAuth"    // JWT пользователя
+	AuthInternal = "internalToken" // X-Internal-Token межсервисных вызовов
+)
+
+// Route — описание маршрута для спецификации
+type Route struct {
+	Method  string
+	Path    string // в формате gin: /api/v1/events/:id, /api/v1/photos/*key
+	Tag     string
+	Summary string
+	Auth    string
+	Query   []Param
+	// Request — значение типа тела запроса (nil — без тела); RequestContentType по умолчанию application/json
+	Request            any
+	RequestContentType string
+	// Response — значение типа поля data успешного ответа {"data": ...}; при RawResponse ответ не обёрнут.
+	// nil — ответ без схемы (файл, изображение). ResponseContentType по умолчанию application/json.
+	Response            any
+	RawResponse         bool
+	ResponseContentType string
+	Status              int // код успешного ответа, по умолчанию 200
+}
+
+// Param — параметр строки запроса
+type Param struct {
+	Name        string
+	Type        string // string, integer, number, boolean; по умолчанию string
+	Format      string // date-time, uuid, ...
+	Description string
+	Required    bool
+}
+
+// Build строит спецификацию маршрутов routes
+func Build(info Info, routes []Route) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				AuthBearer:   {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				AuthInternal: {Type: "apiKey", In: "header", Name: "X-Internal-Token"},
+			},
+		},
+	}
+	g.schemas["Error"] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		path, params := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = g.operation(route, params)
+		if route.Tag != "" && !tags[route.Tag] {
+			tags[route.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: route.Tag})
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
+
+func (g *generator) operation(route Route, pathParams []string) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route.Method, route.Path),
+		Responses:   map[string]Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Auth != AuthNone {
+		op.Security = []map[string][]string{{route.Auth: {}}}
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, param := range route.Query {
+		typ := param.Type
+		if typ == "" {
+			typ = "string"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: typ, Format: param.Format},
+		})
+	}
+
+	if route.Request != nil {
+		contentType := route.RequestContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentType: {Schema: g.schemaOf(route.Request)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	contentType := route.ResponseContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	switch {
+	case route.Response == nil && route.ResponseContentType != "":
+		success.Content = map[string]MediaType{contentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case route.Response != nil && route.RawResponse:
+		success.Content = map[string]MediaType{contentType: {Schema: g.schemaOf(route.Response)}}
+	case route.Response != nil:
+		success.Content = map[string]MediaType{contentType: {Schema: &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"data": g.schemaOf(route.Response)},
+			Required:   []string{"data"},
+		}}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+	op.Responses["default"] = Response{
+		Description: "Ошибка",
+		Content:     map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}}},
+	}
+	return op
+}
+
+// convertPath переводит путь gin в путь OpenAPI и возвращает имена параметров пути
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID — идентификатор операции из метода и пути: GET /api/v1/events/:id → getApiV1EventsId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testItem struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name" binding:"required"`
+	Note      *string         `json:"note,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Extra     json.RawMessage `json:"extra,omitempty"`
+	Tags      []string        `json:"tags"`
+	Parent    *testItem       `json:"parent,omitempty"`
+	Hidden    string          `json:"-"`
+	internal  string
+	testEmbedded
+}
+
+type testEmbedded struct {
+	Version int64 `json:"version"`
+}
+
+func TestSchema(t *testing.T) {
+	g := newGenerator()
+	ref := g.schemaOf(testItem{})
+	if ref.Ref != "#/components/schemas/TestItem" {
+		t.Fatalf("ref = %q, want component reference", ref.Ref)
+	}
+
+	s := g.schemas["TestItem"]
+	want := map[string]Schema{
+		"id":         {Type: "string", Format: "uuid"},
+		"name":       {Type: "string"},
+		"note":       {Type: "string", Nullable: true},
+		"created_at": {Type: "string", Format: "date-time"},
+		"extra":      {},
+		"version":    {Type: "integer", Format: "int64"},
+	}
+	for name, schema := range want {
+		got, ok := s.Properties[name]
+		if !ok {
+			t.Errorf("property %q is missing", name)
+			continue
+		}
+		if !reflect.DeepEqual(*got, schema) {
+			t.Errorf("property %q = %+v, want %+v", name, *got, schema)
+		}
+	}
+	if tags := s.Properties["tags"]; tags == nil || tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("tags = %+v, want array of strings", tags)
+	}
+	if parent := s.Properties["parent"]; parent == nil || parent.Ref != "#/components/schemas/TestItem" {
+		t.Errorf("parent = %+v, want reference to itself", parent)
+	}
+	for _, name := range []string{"Hidden", "-", "internal", "testEmbedded"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("property %q must not be documented", name)
+		}
+	}
+	if !reflect.DeepEqual(s.Required, []string{"name"}) {
+		t.Errorf("required = %v, want [name]", s.Required)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build(Info{Title: "test", Version: "v1"}, []Route{
+		{Method: http.MethodGet, Path: "/api/v1/items/:id", Tag: "items", Auth: AuthBearer, Response: testItem{}},
+		{Method: http.MethodPost, Path: "/api/v1/items", Tag: "items", Request: testItem{}, Response: testItem{}, RawResponse: true, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/files/*key", ResponseContentType: "image/jpeg"},
+	})
+
+	get := doc.Paths["/api/v1/items/{id}"]["get"]
+	if get == nil {
+		t.Fatal("GET /api/v1/items/{id} is missing")
+	}
+	if get.OperationID != "getApiV1ItemsId" {
+		t.Errorf("operationId = %q", get.OperationID)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" || !get.Parameters[0].Required {
+		t.Errorf("parameters = %+v, want required path parameter id", get.Parameters)
+	}
+	if len(get.Security) != 1 {
+		t.Errorf("security = %v, want bearer auth", get.Security)
+	}
+	data := get.Responses["200"].Content["application/json"].Schema
+	if data == nil || data.Properties["data"] == nil || data.Properties["data"].Ref != "#/components/schemas/TestItem" {
+		t.Errorf("GET response = %+v, want {data: TestItem}", data)
+	}
+
+	post := doc.Paths["/api/v1/items"]["post"]
+	if post == nil || post.RequestBody == nil || post.Security != nil {
+		t.Fatalf("POST /api/v1/items = %+v, want public operation with request body", post)
+	}
+	if created := post.Responses["201"].Content["application/json"].Schema; created == nil || created.Ref != "#/components/schemas/TestItem" {
+		t.Errorf("POST response = %+v, want unwrapped TestItem", created)
+	}
+
+	file := doc.Paths["/api/v1/files/{key}"]["get"]
+	if file == nil || file.Responses["200"].Content["image/jpeg"].Schema.Format != "binary" {
+		t.Errorf("GET /api/v1/files/{key} = %+v, want binary response", file)
+	}
+}
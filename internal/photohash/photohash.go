@@ -0,0 +1,94 @@
+// Package photohash считает хеши снимков для поиска повторно присланных фото: SHA-256 для точных
+// копий и перцептивный dHash для пережатых или слегка изменённых.
+package photohash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+)
+
+// Размер сетки dHash: 9×8 ячеек дают 8×8 = 64 сравнения соседних ячеек
+const (
+	gridWidth  = 9
+	gridHeight = 8
+)
+
+// Hash — хеши снимка. Perceptual nil, если изображение не удалось декодировать.
+type Hash struct {
+	SHA256     string
+	Perceptual *uint64
+}
+
+// Compute считает хеши содержимого файла
+func Compute(data []byte) Hash {
+	sum := sha256.Sum256(data)
+	hash := Hash{SHA256: hex.EncodeToString(sum[:])}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == nil {
+		if dhash, ok := differenceHash(img); ok {
+			hash.Perceptual = &dhash
+		}
+	}
+	return hash
+}
+
+// Distance — расстояние Хэмминга между перцептивными хешами (0 — одинаковые картинки)
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// differenceHash уменьшает изображение до 9×8 ячеек яркости и ставит бит, если ячейка ярче
+// соседней справа. Хеш не меняется при пережатии и изменении размера снимка.
+func differenceHash(img image.Image) (uint64, bool) {
+	bounds := img.Bounds()
+	if bounds.Dx() < gridWidth || bounds.Dy() < gridHeight {
+		return 0, false
+	}
+
+	var grid [gridHeight][gridWidth]float64
+	for gy := 0; gy < gridHeight; gy++ {
+		y0 := bounds.Min.Y + gy*bounds.Dy()/gridHeight
+		y1 := bounds.Min.Y + (gy+1)*bounds.Dy()/gridHeight
+		for gx := 0; gx < gridWidth; gx++ {
+			x0 := bounds.Min.X + gx*bounds.Dx()/gridWidth
+			x1 := bounds.Min.X + (gx+1)*bounds.Dx()/gridWidth
+			grid[gy][gx] = averageLuma(img, x0, y0, x1, y1)
+		}
+	}
+
+	var hash uint64
+	for gy := 0; gy < gridHeight; gy++ {
+		for gx := 0; gx < gridWidth-1; gx++ {
+			hash <<= 1
+			if grid[gy][gx] > grid[gy][gx+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash, true
+}
+
+// averageLuma — средняя яркость прямоугольника [x0, x1)×[y0, y1). Для больших снимков берётся
+// не больше 16×16 точек на ячейку: этого хватает для усреднения и не замедляет приём.
+func averageLuma(img image.Image, x0, y0, x1, y1 int) float64 {
+	stepX := max(1, (x1-x0)/16)
+	stepY := max(1, (y1-y0)/16)
+
+	var sum float64
+	var n int
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			n++
+		}
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,90 @@
+package photohash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage — снимок с горизонтальным градиентом и тёмным «кузовом» в позиции offset
+func testImage(width, height, offset int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x * 255 / width)
+			if x >= offset && x < offset+width/4 && y > height/3 {
+				v = 20
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompute(t *testing.T) {
+	original := encodePNG(t, testImage(640, 480, 100))
+
+	tests := []struct {
+		name        string
+		data        []byte
+		sameSHA     bool
+		maxDistance int
+		minDistance int
+	}{
+		{name: "same file", data: original, sameSHA: true, maxDistance: 0},
+		{name: "recompressed jpeg", data: encodeJPEG(t, testImage(640, 480, 100), 60), maxDistance: 2},
+		{name: "resized", data: encodePNG(t, testImage(320, 240, 50)), maxDistance: 2},
+		{name: "different scene", data: encodePNG(t, testImage(640, 480, 420)), minDistance: 4, maxDistance: 64},
+	}
+
+	base := Compute(original)
+	if base.Perceptual == nil {
+		t.Fatal("perceptual hash is missing for a decodable image")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.data)
+			if (got.SHA256 == base.SHA256) != tt.sameSHA {
+				t.Errorf("sha256 equal = %v, want %v", got.SHA256 == base.SHA256, tt.sameSHA)
+			}
+			if got.Perceptual == nil {
+				t.Fatal("perceptual hash is missing")
+			}
+			d := Distance(*base.Perceptual, *got.Perceptual)
+			if d < tt.minDistance || d > tt.maxDistance {
+				t.Errorf("distance = %d, want %d..%d", d, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestComputeNotAnImage(t *testing.T) {
+	got := Compute([]byte("not an image"))
+	if got.SHA256 == "" {
+		t.Error("sha256 is empty")
+	}
+	if got.Perceptual != nil {
+		t.Errorf("perceptual = %x, want nil", *got.Perceptual)
+	}
+}
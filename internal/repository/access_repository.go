@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContractorAccessRule — правила доступа машин подрядчика
+type ContractorAccessRule struct {
+	ContractorID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Schedule         *string   // окна, в которые машинам подрядчика разрешён въезд, например "20:00-06:00"
+	MaxTripsPerNight *int      // лимит въездов одной машины за ночь; nil — используется ACCESS_MAX_TRIPS_PER_NIGHT
+	// PaidTripsPerNight — квота оплачиваемых рейсов одной машины за ночь; nil — ACCESS_PAID_TRIPS_PER_NIGHT
+	PaidTripsPerNight *int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (ContractorAccessRule) TableName() string {
+	return "anpr_contractor_access_rules"
+}
+
+// GetContractorAccessRule получает правила подрядчика; возвращает nil, если правила не заданы
+func (r *ANPRRepository) GetContractorAccessRule(ctx context.Context, contractorID uuid.UUID) (*ContractorAccessRule, error) {
+	var rule ContractorAccessRule
+	err := r.db.WithContext(ctx).Where("contractor_id = ?", contractorID).First(&rule).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contractor access rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListContractorAccessRules возвращает правила всех подрядчиков
+func (r *ANPRRepository) ListContractorAccessRules(ctx context.Context) ([]ContractorAccessRule, error) {
+	var rules []ContractorAccessRule
+	err := r.db.WithContext(ctx).Order("contractor_id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// UpsertContractorAccessRule создает или обновляет правила подрядчика
+func (r *ANPRRepository) UpsertContractorAccessRule(ctx context.Context, rule *ContractorAccessRule) error {
+	now := r.clock.Now()
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	rule.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "contractor_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"schedule", "max_trips_per_night", "paid_trips_per_night", "updated_at"}),
+		}).
+		Create(rule).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert contractor access rule: %w", err)
+	}
+	return nil
+}
+
+// CountAllowedEntries считает въезды номера с решением ALLOW в интервале [from, to); события, пришедшие
+// с опозданием (late), в квоту не входят
+func (r *ANPRRepository) CountAllowedEntries(ctx context.Context, plateID uuid.UUID, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&ANPREvent{}).
+		Where("plate_id = ?", plateID).
+		Where("access_decision = ?", "ALLOW").
+		Where("direction = ?", "entry").
+		Where("late = FALSE").
+		Where("event_time >= ? AND event_time < ?", from, to).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count allowed entries: %w", err)
+	}
+	return count, nil
+}
+
+// PlateTripQuota — квота оплачиваемых рейсов за ночь отдельного номера (приоритетнее квоты подрядчика)
+type PlateTripQuota struct {
+	NormalizedPlate   string `gorm:"primaryKey"`
+	PaidTripsPerNight int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (PlateTripQuota) TableName() string {
+	return "anpr_plate_trip_quotas"
+}
+
+// GetPlateTripQuota получает квоту номера; возвращает nil, если квота не задана
+func (r *ANPRRepository) GetPlateTripQuota(ctx context.Context, normalized string) (*PlateTripQuota, error) {
+	var quota PlateTripQuota
+	err := r.db.WithContext(ctx).Where("normalized_plate = ?", normalized).First(&quota).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate trip quota: %w", err)
+	}
+	return &quota, nil
+}
+
+// ListPlateTripQuotas возвращает квоты всех номеров
+func (r *ANPRRepository) ListPlateTripQuotas(ctx context.Context) ([]PlateTripQuota, error) {
+	var quotas []PlateTripQuota
+	err := r.db.WithContext(ctx).Order("normalized_plate ASC").Find(&quotas).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plate trip quotas: %w", err)
+	}
+	return quotas, nil
+}
+
+// UpsertPlateTripQuota создает или обновляет квоту номера
+func (r *ANPRRepository) UpsertPlateTripQuota(ctx context.Context, quota *PlateTripQuota) error {
+	now := r.clock.Now()
+	if quota.CreatedAt.IsZero() {
+		quota.CreatedAt = now
+	}
+	quota.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "normalized_plate"}},
+			DoUpdates: clause.AssignmentColumns([]string{"paid_trips_per_night", "updated_at"}),
+		}).
+		Create(quota).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert plate trip quota: %w", err)
+	}
+	return nil
+}
+
+// DeletePlateTripQuota удаляет квоту номера; false — квоты не было
+func (r *ANPRRepository) DeletePlateTripQuota(ctx context.Context, normalized string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("normalized_plate = ?", normalized).Delete(&PlateTripQuota{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete plate trip quota: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ContractorPolygon — закрепление подрядчика за полигоном вывоза
+type ContractorPolygon struct {
+	ContractorID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PolygonID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt    time.Time
+}
+
+func (ContractorPolygon) TableName() string {
+	return "anpr_contractor_polygons"
+}
+
+// ListContractorPolygons возвращает закрепления всех подрядчиков
+func (r *ANPRRepository) ListContractorPolygons(ctx context.Context) ([]ContractorPolygon, error) {
+	var assignments []ContractorPolygon
+	err := r.db.WithContext(ctx).Order("contractor_id ASC, polygon_id ASC").Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contractor polygons: %w", err)
+	}
+	return assignments, nil
+}
+
+// GetContractorPolygonIDs возвращает полигоны, за которыми закреплён подрядчик (пусто — не закреплён)
+func (r *ANPRRepository) GetContractorPolygonIDs(ctx context.Context, contractorID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&ContractorPolygon{}).
+		Where("contractor_id = ?", contractorID).
+		Pluck("polygon_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contractor polygons: %w", err)
+	}
+	return ids, nil
+}
+
+// ReplaceContractorPolygons заменяет набор полигонов подрядчика
+func (r *ANPRRepository) ReplaceContractorPolygons(ctx context.Context, contractorID uuid.UUID, polygonIDs []uuid.UUID) error {
+	now := r.clock.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("contractor_id = ?", contractorID)
+		if len(polygonIDs) > 0 {
+			query = query.Where("polygon_id NOT IN ?", polygonIDs)
+		}
+		if err := query.Delete(&ContractorPolygon{}).Error; err != nil {
+			return err
+		}
+		if len(polygonIDs) == 0 {
+			return nil
+		}
+		rows := make([]ContractorPolygon, 0, len(polygonIDs))
+		for _, id := range polygonIDs {
+			rows = append(rows, ContractorPolygon{ContractorID: contractorID, PolygonID: id, CreatedAt: now})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace contractor polygons: %w", err)
+	}
+	return nil
+}
+
+// TripQuotaRepair — пересчёт квоты оплачиваемых рейсов номера за период
+type TripQuotaRepair struct {
+	PlateID  uuid.UUID
+	From, To time.Time
+	// TimeZone и NightStartMinutes задают границы ночей (ACCESS_NIGHT_START в поясе камеры)
+	TimeZone          string
+	NightStartMinutes int
+	// PaidTripsPerNight — квота на ночь; 0 — без квоты, оплачиваются все рейсы
+	PaidTripsPerNight int
+}
+
+// TripChange — въезд, статус оплаты которого изменил пересчёт
+type TripChange struct {
+	EventID         uuid.UUID `gorm:"column:id"`
+	PlateID         uuid.UUID `gorm:"column:plate_id"`
+	NormalizedPlate string    `gorm:"column:normalized_plate"`
+	CameraID        string    `gorm:"column:camera_id"`
+	EventTime       time.Time `gorm:"column:event_time"`
+	MatchedSnow     bool      `gorm:"column:matched_snow"`
+	SnowVolumeM3    *float64  `gorm:"column:snow_volume_m3"`
+	OverQuota       bool      `gorm:"column:over_quota"`
+	TripRevision    int       `gorm:"column:trip_revision"`
+}
+
+// RepairTripQuota заново распределяет квоту между въездами номера за [From, To): в каждой ночи первые
+// PaidTripsPerNight въездов по event_time оплачиваются, остальные — сверх квоты. Въезды отбираются так же,
+// как в CountAllowedEntries, без удалённых событий. Меняются только over_quota и trip_revision строк,
+// статус которых отличается от нужного: decision_reason и decision_detail остаются решением, принятым
+// при въезде. Повторный пересчёт ничего не меняет. Возвращает изменённые въезды.
+func (r *ANPRRepository) RepairTripQuota(ctx context.Context, repair TripQuotaRepair) ([]TripChange, error) {
+	var changes []TripChange
+	err := r.db.WithContext(ctx).Raw(`
+		WITH ranked AS (
+			SELECT id, event_time,
+				ROW_NUMBER() OVER (
+					PARTITION BY ((event_time AT TIME ZONE ?) - make_interval(mins => ?))::date
+					ORDER BY event_time, id
+				) AS n
+			FROM anpr_events
+			WHERE plate_id = ? AND access_decision = 'ALLOW' AND direction = 'entry' AND late = FALSE
+				AND event_time >= ? AND event_time < ? AND deleted_at IS NULL
+		), target AS (
+			SELECT id, event_time, (CAST(? AS integer) > 0 AND n > ?) AS over_quota FROM ranked
+		)
+		UPDATE anpr_events e SET
+			over_quota = t.over_quota,
+			trip_revision = e.trip_revision + 1
+		FROM target t
+		WHERE e.id = t.id AND e.event_time = t.event_time AND e.over_quota <> t.over_quota
+		RETURNING e.id, e.plate_id, e.normalized_plate, e.camera_id, e.event_time, e.matched_snow,
+			e.snow_volume_m3, e.over_quota, e.trip_revision`,
+		repair.TimeZone, repair.NightStartMinutes,
+		repair.PlateID, repair.From, repair.To,
+		repair.PaidTripsPerNight, repair.PaidTripsPerNight,
+	).Scan(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair trip quota: %w", err)
+	}
+	return changes, nil
+}
+
+// TripPlate — номер, у которого есть учитываемые в квоте въезды
+type TripPlate struct {
+	PlateID         uuid.UUID `gorm:"column:plate_id"`
+	NormalizedPlate string    `gorm:"column:normalized_plate"`
+}
+
+// ListTripPlates возвращает номера с въездами за [from, to), которые учитываются в квоте рейсов
+// (как в RepairTripQuota)
+func (r *ANPRRepository) ListTripPlates(ctx context.Context, from, to time.Time) ([]TripPlate, error) {
+	var plates []TripPlate
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT plate_id, normalized_plate
+		FROM anpr_events
+		WHERE plate_id IS NOT NULL AND access_decision = 'ALLOW' AND direction = 'entry' AND late = FALSE
+			AND event_time >= ? AND event_time < ? AND deleted_at IS NULL`,
+		from, to,
+	).Scan(&plates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trip plates: %w", err)
+	}
+	return plates, nil
+}
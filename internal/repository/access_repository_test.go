@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/idgen"
+)
+
+// Удалённый въезд не занимает место в квоте ночи
+func TestRepairTripQuotaSkipsDeletedEventsDatabase(t *testing.T) {
+	database := openTestDatabase(t)
+	ctx := context.Background()
+
+	repo := NewANPRRepository(database, clock.System(), idgen.Random())
+	plate := "TEST" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		database.Unscoped().Where("normalized_plate = ?", plate).Delete(&ANPREvent{})
+		database.Where("normalized = ?", plate).Delete(&Plate{})
+	})
+
+	night := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	events := make([]*anpr.Event, 3)
+	for i := range events {
+		events[i] = &anpr.Event{
+			ID:              uuid.New(),
+			NormalizedPlate: plate,
+			EventPayload: anpr.EventPayload{
+				CameraID:  "test-camera",
+				Plate:     plate,
+				Direction: "entry",
+				EventTime: night.Add(time.Duration(i) * time.Hour),
+			},
+			Decision: &anpr.AccessDecision{Decision: anpr.DecisionAllow, Reason: anpr.ReasonRegisteredVehicle},
+		}
+		if _, err := repo.SaveANPREvent(ctx, events[i], "", "", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.Where("id = ?", events[0].ID).Delete(&ANPREvent{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := repo.RepairTripQuota(ctx, TripQuotaRepair{
+		PlateID:           events[0].PlateID,
+		From:              night.Add(-time.Hour),
+		To:                night.Add(23 * time.Hour),
+		TimeZone:          "UTC",
+		NightStartMinutes: 12 * 60,
+		PaidTripsPerNight: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Оплачен второй въезд, сверх квоты — только третий
+	if len(changes) != 1 || changes[0].EventID != events[2].ID || !changes[0].OverQuota {
+		t.Fatalf("changes = %+v, want only the third entry over quota", changes)
+	}
+	// Решение при въезде пересчёт не меняет
+	third, err := repo.GetEventByID(ctx, events[2].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.DecisionReason == nil || *third.DecisionReason != anpr.ReasonRegisteredVehicle {
+		t.Errorf("decision_reason = %v, want %s", third.DecisionReason, anpr.ReasonRegisteredVehicle)
+	}
+}
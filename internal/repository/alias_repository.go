@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlateAlias — ошибочный вариант распознавания (нормализованный), который заменяется основным номером
+type PlateAlias struct {
+	Alias     string    `gorm:"primaryKey"`
+	PlateID   uuid.UUID `gorm:"type:uuid;not null"`
+	Note      *string
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (PlateAlias) TableName() string {
+	return "anpr_plate_aliases"
+}
+
+// PlateMergeResult — сколько записей перенесено на основной номер при слиянии
+type PlateMergeResult struct {
+	Events         int64
+	RejectedEvents int64
+	ListItems      int64
+	// EventsFrom, EventsTo — время первого и последнего перенесённого события (nil — событий не было)
+	EventsFrom *time.Time
+	EventsTo   *time.Time
+}
+
+// ResolvePlateAlias возвращает нормализованный основной номер для псевдонима ("" — псевдонима нет)
+func (r *ANPRRepository) ResolvePlateAlias(ctx context.Context, normalized string) (string, error) {
+	var plates []string
+	err := r.db.WithContext(ctx).
+		Table("anpr_plate_aliases a").
+		Select("p.normalized").
+		Joins("JOIN anpr_plates p ON p.id = a.plate_id").
+		Where("a.alias = ?", normalized).
+		Limit(1).
+		Pluck("p.normalized", &plates).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve plate alias: %w", err)
+	}
+	if len(plates) == 0 {
+		return "", nil
+	}
+	return plates[0], nil
+}
+
+// ListPlateAliases возвращает псевдонимы номера
+func (r *ANPRRepository) ListPlateAliases(ctx context.Context, plateID uuid.UUID) ([]PlateAlias, error) {
+	var aliases []PlateAlias
+	err := r.db.WithContext(ctx).
+		Where("plate_id = ?", plateID).
+		Order("alias ASC").
+		Find(&aliases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plate aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// CreatePlateAlias сохраняет псевдоним. Возвращает false, если такой псевдоним уже есть.
+func (r *ANPRRepository) CreatePlateAlias(ctx context.Context, alias *PlateAlias) (bool, error) {
+	if alias.CreatedAt.IsZero() {
+		alias.CreatedAt = r.clock.Now()
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(alias)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create plate alias: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeletePlateAlias удаляет псевдоним номера. Возвращает false, если его не было.
+func (r *ANPRRepository) DeletePlateAlias(ctx context.Context, plateID uuid.UUID, alias string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("plate_id = ? AND alias = ?", plateID, alias).
+		Delete(&PlateAlias{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete plate alias: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MergePlates переносит события, отклонённые проезды, членство в списках и псевдонимы номера sourceID
+// на номер targetID, удаляет sourceID и делает его нормализованный номер псевдонимом targetID.
+// Всё выполняется в одной транзакции. ErrPlateNotFound — одного из номеров нет.
+func (r *ANPRRepository) MergePlates(ctx context.Context, targetID, sourceID uuid.UUID, note *string) (*PlateMergeResult, error) {
+	result := &PlateMergeResult{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var plates []Plate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{targetID, sourceID}).
+			Find(&plates).Error; err != nil {
+			return err
+		}
+		var target, source *Plate
+		for i := range plates {
+			switch plates[i].ID {
+			case targetID:
+				target = &plates[i]
+			case sourceID:
+				source = &plates[i]
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("%w: %s", ErrPlateNotFound, targetID)
+		}
+		if source == nil {
+			return fmt.Errorf("source %w: %s", ErrPlateNotFound, sourceID)
+		}
+
+		var moved struct {
+			From *time.Time `gorm:"column:first_event"`
+			To   *time.Time `gorm:"column:last_event"`
+		}
+		if err := tx.Raw("SELECT MIN(event_time) AS first_event, MAX(event_time) AS last_event FROM anpr_events WHERE plate_id = ?", sourceID).
+			Scan(&moved).Error; err != nil {
+			return err
+		}
+		result.EventsFrom, result.EventsTo = moved.From, moved.To
+
+		res := tx.Exec("UPDATE anpr_events SET plate_id = ?, normalized_plate = ? WHERE plate_id = ?", targetID, target.Normalized, sourceID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Events = res.RowsAffected
+
+		res = tx.Exec("UPDATE anpr_events_rejected SET plate_id = ?, normalized_plate = ? WHERE plate_id = ?", targetID, target.Normalized, sourceID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.RejectedEvents = res.RowsAffected
+
+		// История переносится до перестановки записей, чтобы таймлайн основного номера включал прошлое
+		// членство сливаемого; записи, которые породит перестановка для sourceID, удаляются вместе с ним
+		if err := tx.Exec("UPDATE anpr_list_item_history SET plate_id = ? WHERE plate_id = ?", targetID, sourceID).Error; err != nil {
+			return err
+		}
+		res = tx.Exec(`
+			INSERT INTO anpr_list_items (list_id, plate_id, note, created_at, valid_from, valid_until)
+			SELECT list_id, ?, note, created_at, valid_from, valid_until FROM anpr_list_items WHERE plate_id = ?
+			ON CONFLICT (list_id, plate_id) DO NOTHING`, targetID, sourceID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.ListItems = res.RowsAffected
+		if err := tx.Exec("DELETE FROM anpr_list_items WHERE plate_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("UPDATE anpr_plate_aliases SET plate_id = ? WHERE plate_id = ?", targetID, sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			INSERT INTO anpr_plate_aliases (alias, plate_id, note, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (alias) DO UPDATE SET plate_id = EXCLUDED.plate_id`,
+			source.Normalized, targetID, note, r.clock.Now()).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM anpr_plates WHERE id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM anpr_list_item_history WHERE plate_id = ?", sourceID).Error
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge plates: %w", err)
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -10,14 +11,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"anpr-service/internal/clock"
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/idgen"
 )
 
 type ANPRRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	clock clock.Clock
+	ids   idgen.Generator
 }
 
 var cameraAliasToPolygonName = map[string]string{
@@ -28,8 +35,8 @@ var cameraAliasToPolygonName = map[string]string{
 	"yakor":       "Якорь",
 }
 
-func NewANPRRepository(db *gorm.DB) *ANPRRepository {
-	return &ANPRRepository{db: db}
+func NewANPRRepository(db *gorm.DB, clk clock.Clock, ids idgen.Generator) *ANPRRepository {
+	return &ANPRRepository{db: db, clock: clk, ids: ids}
 }
 
 var photoIndexPattern = regexp.MustCompile(`-photo-(\d+)(?:\.[^/?#]+)?(?:\?.*)?(?:#.*)?$`)
@@ -63,6 +70,12 @@ type Plate struct {
 	CreatedAt  time.Time
 }
 
+// Колонки времени события, по которым можно фильтровать список событий
+const (
+	EventTimeFieldEventTime  = "event_time"
+	EventTimeFieldReceivedAt = "received_at"
+)
+
 type ANPREvent struct {
 	ID                uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	PlateID           *uuid.UUID `gorm:"type:uuid"`
@@ -72,12 +85,14 @@ type ANPREvent struct {
 	ContractorID      *uuid.UUID `gorm:"type:uuid"` // ID организации (подрядчика), к которой принадлежит машина
 	CameraModel       *string
 	Direction         *string
+	DirectionSource   *string // откуда взято направление (anpr.DirectionSource*); NULL у событий до его учёта
 	Lane              *int
 	RawPlate          string `gorm:"not null"`
 	NormalizedPlate   string `gorm:"not null"`
 	Confidence        *float64
 	VehicleColor      *string
-	VehicleType       *string
+	VehicleType       *string // канонический тип (anpr.VehicleType*)
+	VehicleTypeRaw    *string // тип, присланный камерой
 	VehicleBrand      *string
 	VehicleModel      *string
 	VehicleCountry    *string
@@ -85,13 +100,30 @@ type ANPREvent struct {
 	VehicleSpeed      *float64
 	SnapshotURL       *string
 	EventTime         time.Time      `gorm:"not null"`
+	ReceivedAt        time.Time      `gorm:"not null"`                // время приёма события сервисом (для импорта отличается от event_time)
+	Source            string         `gorm:"not null;default:camera"` // источник события (anpr.Source*)
 	RawPayload        datatypes.JSON `gorm:"type:jsonb"`
+	RawPayloadKey     *string        // ключ payload в хранилище фото, если он вынесен из raw_payload
 	// Поля для данных о снеге
-	SnowVolumePercentage *float64
-	SnowVolumeConfidence *float64
-	SnowVolumeM3         *float64
-	MatchedSnow          bool `gorm:"default:false"`
-	CreatedAt            time.Time
+	SnowVolumePercentage   *float64
+	SnowVolumeConfidence   *float64
+	SnowVolumeM3           *float64
+	MatchedSnow            bool     `gorm:"default:false"`
+	EventTimeSkewed        bool     `gorm:"default:false"` // event_time расходится с временем сервера больше допустимого
+	ClockCorrectionSeconds *float64 // поправка, вычтенная из времени камеры при автокоррекции
+	OutOfSchedule          bool     `gorm:"default:false"` // событие вне расписания камеры, не учитывается в рейсах
+	Late                   bool     `gorm:"default:false"` // камера прислала событие с опозданием, не учитывается в рейсах и квотах
+	WrongDestination       bool     `gorm:"default:false"` // машина подрядчика на полигоне, за которым он не закреплён
+	OverQuota              bool     `gorm:"default:false"` // въезд сверх квоты оплачиваемых рейсов, не учитывается в отчётах
+	TripRevision           int      `gorm:"default:0"`     // сколько раз пересчёт рейсов менял over_quota
+	WeatherTemperatureC    *float64 // температура на полигоне в час события (см. anpr_weather_observations)
+	WeatherSnowfallCm      *float64 // снегопад на полигоне за час события, см
+	AccessDecision         *string  // ALLOW / DENY
+	DecisionReason         *string
+	DecisionDetail         *string
+	CreatedAt              time.Time
+	// DeletedAt — время мягкого удаления; такие события не видны в выборках до восстановления или очистки
+	DeletedAt gorm.DeletedAt
 }
 
 type List struct {
@@ -107,6 +139,9 @@ type ListItem struct {
 	PlateID   uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Note      *string
 	CreatedAt time.Time
+	// ValidFrom, ValidUntil — срок действия записи; nil — без ограничения
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
 }
 
 type VehicleData struct {
@@ -135,36 +170,86 @@ type EventPhoto struct {
 	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	EventID      uuid.UUID `gorm:"type:uuid;not null"`
 	PhotoURL     string    `gorm:"not null"`
-	DisplayOrder int       `gorm:"default:0"`
+	DisplayOrder int       `gorm:"not null;default:0"`
 	CreatedAt    time.Time
+	// Копии из anpr_photo_renditions (только чтение; nil — фото загружено без обработки)
+	ThumbnailURL *string `gorm:"->"`
+	MediumURL    *string `gorm:"->"`
+}
+
+// GetOrCreatePlate возвращает ID номера, создавая его при первом появлении. country и region — страна и
+// регион по формату номера (пустые, если формат не распознан); у номеров, созданных без страны, они
+// заполняются при следующем появлении.
+func (r *ANPRRepository) GetOrCreatePlate(ctx context.Context, normalized, original, country, region string) (uuid.UUID, error) {
+	return r.getOrCreatePlate(r.db.WithContext(ctx), false, normalized, original, country, region)
 }
 
-func (r *ANPRRepository) GetOrCreatePlate(ctx context.Context, normalized, original string) (uuid.UUID, error) {
+// getOrCreatePlate — GetOrCreatePlate в соединении или транзакции db. Номер вставляется через
+// ON CONFLICT (normalized) DO NOTHING: если его одновременно создал другой запрос, вставка ничего не делает
+// и ID перечитывается, а не падает на уникальном индексе. lock берёт на строку номера FOR KEY SHARE до конца
+// транзакции, чтобы слияние номеров не удалило её раньше, чем на неё сошлётся событие.
+func (r *ANPRRepository) getOrCreatePlate(db *gorm.DB, lock bool, normalized, original, country, region string) (uuid.UUID, error) {
+	find := func(plate *Plate) error {
+		query := db.Where("normalized = ?", normalized)
+		if lock {
+			query = query.Clauses(clause.Locking{Strength: "KEY SHARE"})
+		}
+		return query.First(plate).Error
+	}
+
 	var plate Plate
-	err := r.db.WithContext(ctx).Where("normalized = ?", normalized).First(&plate).Error
+	err := find(&plate)
 	if err == nil {
+		if plate.Country == nil && country != "" {
+			updates := map[string]interface{}{"country": country}
+			if region != "" {
+				updates["region"] = region
+			}
+			err = db.Model(&Plate{}).
+				Where("id = ? AND country IS NULL", plate.ID).
+				Updates(updates).Error
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("failed to set plate country: %w", err)
+			}
+		}
 		return plate.ID, nil
 	}
-	if err != gorm.ErrRecordNotFound {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return uuid.Nil, err
 	}
 
 	plate = Plate{
-		ID:         uuid.New(),
+		ID:         r.ids.NewID(),
 		Number:     original,
 		Normalized: normalized,
-		CreatedAt:  time.Now(),
+		CreatedAt:  r.clock.Now(),
+	}
+	if country != "" {
+		plate.Country = &country
+	}
+	if region != "" {
+		plate.Region = &region
+	}
+	result := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "normalized"}}, DoNothing: true}).Create(&plate)
+	if result.Error != nil {
+		return uuid.Nil, fmt.Errorf("failed to create plate: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return plate.ID, nil
 	}
-	if err := r.db.WithContext(ctx).Create(&plate).Error; err != nil {
-		return uuid.Nil, fmt.Errorf("failed to create plate: %w", err)
+	// Номер создал параллельный запрос
+	var existing Plate
+	if err := find(&existing); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get concurrently created plate: %w", err)
 	}
-	return plate.ID, nil
+	return existing.ID, nil
 }
 
-// RejectedEvent — событие, отклонённое из-за отсутствия номера в vehicles (сохраняется в anpr_events_rejected)
+// RejectedEvent — отклонённое событие (номер не найден в vehicles или не прошёл проверку формата),
+// сохраняется в anpr_events_rejected. У номеров с неверным форматом plate_id не заполняется.
 type RejectedEvent struct {
 	ID              uuid.UUID      `gorm:"type:uuid;primaryKey"`
-	PlateID         uuid.UUID      `gorm:"type:uuid;not null"`
+	PlateID         *uuid.UUID     `gorm:"type:uuid"`
 	CameraID        string         `gorm:"not null"`
 	RawPlate        string         `gorm:"not null"`
 	NormalizedPlate string         `gorm:"not null"`
@@ -179,10 +264,15 @@ func (RejectedEvent) TableName() string {
 	return "anpr_events_rejected"
 }
 
-const rejectReasonVehicleNotWhitelist = "vehicle_not_in_whitelist"
+// Причины отклонения событий в anpr_events_rejected
+const (
+	RejectReasonVehicleNotWhitelist = "vehicle_not_in_whitelist"
+	RejectReasonInvalidPlate        = "invalid_plate"
+)
 
-// CreateRejectedEvent сохраняет отклонённое событие (номер не найден в vehicles) в anpr_events_rejected
-func (r *ANPRRepository) CreateRejectedEvent(ctx context.Context, eventID, plateID uuid.UUID, normalizedPlate, rawPlate, cameraID string, eventTime time.Time, payload *anpr.EventPayload, photoURLs []string) error {
+// CreateRejectedEvent сохраняет отклонённое событие в anpr_events_rejected.
+// plateID равен nil, если запись в anpr_plates не создавалась (номер не прошёл проверку формата).
+func (r *ANPRRepository) CreateRejectedEvent(ctx context.Context, eventID uuid.UUID, plateID *uuid.UUID, reason string, normalizedPlate, rawPlate, cameraID string, eventTime time.Time, payload *anpr.EventPayload, photoURLs []string) error {
 	rawPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload for rejected event: %w", err)
@@ -200,14 +290,76 @@ func (r *ANPRRepository) CreateRejectedEvent(ctx context.Context, eventID, plate
 		EventTime:       eventTime,
 		RawPayload:      datatypes.JSON(rawPayload),
 		PhotoURLs:       datatypes.JSON(photoURLsJSON),
-		RejectReason:    rejectReasonVehicleNotWhitelist,
-		CreatedAt:       time.Now(),
+		RejectReason:    reason,
+		CreatedAt:       r.clock.Now(),
 	}
 	return r.db.WithContext(ctx).Create(&rec).Error
 }
 
-func (r *ANPRRepository) CreateANPREvent(
-	ctx context.Context,
+// Повторы транзакции сохранения события: после конфликта сериализации или взаимоблокировки (например,
+// с параллельным слиянием номеров) она выполняется заново, с паузой attempt × ingestTxRetryBackoff
+const (
+	ingestTxAttempts     = 3
+	ingestTxRetryBackoff = 20 * time.Millisecond
+)
+
+// SaveANPREvent сохраняет принятое событие одной транзакцией: номер (создаётся со страной и регионом
+// country/region, если его ещё нет; см. GetOrCreatePlate), событие и его фото — и возвращает ID номера,
+// он же записывается в event.PlateID. Транзакция повторяется при конфликте сериализации и взаимоблокировке;
+// ошибка сохранения фото отменяет всё сохранение.
+func (r *ANPRRepository) SaveANPREvent(ctx context.Context, event *anpr.Event, country, region string, contractorID, polygonID *uuid.UUID, photoURLs []string) (uuid.UUID, error) {
+	err := r.retryTx(ctx, ingestTxAttempts, func(tx *gorm.DB) error {
+		plateID, err := r.getOrCreatePlate(tx, true, event.NormalizedPlate, event.Plate, country, region)
+		if err != nil {
+			return fmt.Errorf("failed to get or create plate: %w", err)
+		}
+		event.PlateID = plateID
+		if err := r.createANPREvent(tx, event, contractorID, polygonID); err != nil {
+			return err
+		}
+		if err := r.createEventPhotos(tx, event.ID, photoURLs); err != nil {
+			return fmt.Errorf("failed to create event photos: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return event.PlateID, nil
+}
+
+// retryTx выполняет fn в транзакции и повторяет её, пока ошибка — конфликт сериализации (40001) или
+// взаимоблокировка (40P01), но не больше attempts раз
+func (r *ANPRRepository) retryTx(ctx context.Context, attempts int, fn func(tx *gorm.DB) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.db.WithContext(ctx).Transaction(fn)
+		if err == nil || attempt >= attempts || !isRetryableTxError(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * ingestTxRetryBackoff):
+		}
+	}
+}
+
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgerrcodeSerializationFailure || pgErr.Code == pgerrcodeDeadlockDetected
+}
+
+// Коды ошибок PostgreSQL, после которых транзакцию можно повторить
+const (
+	pgerrcodeSerializationFailure = "40001"
+	pgerrcodeDeadlockDetected     = "40P01"
+)
+
+func (r *ANPRRepository) createANPREvent(
+	tx *gorm.DB,
 	event *anpr.Event,
 	contractorID *uuid.UUID,
 	polygonID *uuid.UUID,
@@ -220,8 +372,9 @@ func (r *ANPRRepository) CreateANPREvent(
 		RawPlate:        event.Plate,
 		NormalizedPlate: event.NormalizedPlate,
 		EventTime:       event.EventTime,
+		Source:          event.Source,
 		ContractorID:    contractorID, // Сохраняем ID подрядчика напрямую в событии
-		CreatedAt:       time.Now(),
+		CreatedAt:       r.clock.Now(),
 	}
 
 	if event.CameraModel != "" {
@@ -230,6 +383,9 @@ func (r *ANPRRepository) CreateANPREvent(
 	if event.Direction != "" {
 		dbEvent.Direction = &event.Direction
 	}
+	if event.DirectionSource != "" {
+		dbEvent.DirectionSource = &event.DirectionSource
+	}
 	if event.Lane != 0 {
 		dbEvent.Lane = &event.Lane
 	}
@@ -242,6 +398,9 @@ func (r *ANPRRepository) CreateANPREvent(
 	if event.Vehicle.Type != "" {
 		dbEvent.VehicleType = &event.Vehicle.Type
 	}
+	if event.VehicleTypeRaw != "" {
+		dbEvent.VehicleTypeRaw = &event.VehicleTypeRaw
+	}
 	if event.Vehicle.Brand != "" {
 		dbEvent.VehicleBrand = &event.Vehicle.Brand
 	}
@@ -267,6 +426,9 @@ func (r *ANPRRepository) CreateANPREvent(
 		}
 		dbEvent.RawPayload = datatypes.JSON(raw)
 	}
+	if event.RawPayloadKey != "" {
+		dbEvent.RawPayloadKey = &event.RawPayloadKey
+	}
 
 	// Сохраняем данные о снеге, если они есть
 	if event.SnowVolumePercentage != nil {
@@ -279,8 +441,26 @@ func (r *ANPRRepository) CreateANPREvent(
 		dbEvent.SnowVolumeM3 = event.SnowVolumeM3
 	}
 	dbEvent.MatchedSnow = event.MatchedSnow
+	dbEvent.EventTimeSkewed = event.EventTimeSkewed
+	dbEvent.ClockCorrectionSeconds = event.ClockCorrectionSeconds
+	dbEvent.OutOfSchedule = event.OutOfSchedule
+	dbEvent.Late = event.Late
+	dbEvent.WrongDestination = event.WrongDestination
+	dbEvent.OverQuota = event.OverQuota
+	if event.Decision != nil {
+		dbEvent.AccessDecision = &event.Decision.Decision
+		dbEvent.DecisionReason = &event.Decision.Reason
+		if event.Decision.Detail != "" {
+			dbEvent.DecisionDetail = &event.Decision.Detail
+		}
+	}
+	if event.ReceivedAt != nil {
+		dbEvent.ReceivedAt = *event.ReceivedAt
+	} else {
+		dbEvent.ReceivedAt = r.clock.Now()
+	}
 
-	if err := r.db.WithContext(ctx).Create(&dbEvent).Error; err != nil {
+	if err := tx.Create(&dbEvent).Error; err != nil {
 		return fmt.Errorf("failed to create ANPR event in database: %w", err)
 	}
 
@@ -288,6 +468,8 @@ func (r *ANPRRepository) CreateANPREvent(
 	return nil
 }
 
+// ResolvePolygonIDByCameraID возвращает полигон незарегистрированной камеры по её старому
+// псевдониму (shahovskoye → «Шаховское»). Камеры реестра привязываются к полигону через polygon_id.
 func (r *ANPRRepository) ResolvePolygonIDByCameraID(ctx context.Context, cameraID string) (*uuid.UUID, error) {
 	alias := strings.ToLower(strings.TrimSpace(cameraID))
 	if alias == "" {
@@ -299,36 +481,27 @@ func (r *ANPRRepository) ResolvePolygonIDByCameraID(ctx context.Context, cameraI
 		return nil, nil
 	}
 
-	var polygon struct {
-		ID uuid.UUID `gorm:"column:id"`
-	}
-
-	err := r.db.WithContext(ctx).
-		Table("polygons").
-		Select("id").
-		Where("LOWER(name) = LOWER(?)", polygonName).
-		Limit(1).
-		First(&polygon).Error
-
-	if err == gorm.ErrRecordNotFound {
+	polygon, err := r.GetPolygonByName(ctx, polygonName)
+	if errors.Is(err, ErrPolygonNotFound) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("resolve polygon for camera_id %q: %w", cameraID, err)
 	}
-
-	polygonID := polygon.ID
-	return &polygonID, nil
+	return &polygon.ID, nil
 }
 
+// FindListsForPlate возвращает списки, в которых номер состоит сейчас (с учётом срока действия записей)
 func (r *ANPRRepository) FindListsForPlate(ctx context.Context, plateID uuid.UUID) ([]anpr.ListHit, error) {
 	var hits []anpr.ListHit
 
+	now := r.clock.Now()
 	err := r.db.WithContext(ctx).
 		Table("anpr_list_items").
 		Select("anpr_lists.id as list_id, anpr_lists.name as list_name, anpr_lists.type as list_type").
 		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
 		Where("anpr_list_items.plate_id = ?", plateID).
+		Where(listItemActive, now, now).
 		Scan(&hits).Error
 
 	if err != nil {
@@ -338,6 +511,66 @@ func (r *ANPRRepository) FindListsForPlate(ctx context.Context, plateID uuid.UUI
 	return hits, nil
 }
 
+// listItemActive — условие «запись списка действует в момент ?» (параметры: момент дважды)
+const listItemActive = "(anpr_list_items.valid_from IS NULL OR anpr_list_items.valid_from <= ?) AND " +
+	"(anpr_list_items.valid_until IS NULL OR anpr_list_items.valid_until > ?)"
+
+// ListMembership — членство номера в списке со сроком действия записи
+type ListMembership struct {
+	anpr.ListHit
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+// ActiveAt сообщает, действует ли запись в момент t
+func (m ListMembership) ActiveAt(t time.Time) bool {
+	return (m.ValidFrom == nil || !t.Before(*m.ValidFrom)) && (m.ValidUntil == nil || t.Before(*m.ValidUntil))
+}
+
+// LoadListMembership возвращает членство всех номеров в списках одним запросом (для кэша в сервисе).
+// Записи возвращаются со сроком действия, включая ещё не начавшиеся и истёкшие, но не удалённые.
+func (r *ANPRRepository) LoadListMembership(ctx context.Context) (map[uuid.UUID][]ListMembership, error) {
+	var rows []struct {
+		PlateID uuid.UUID
+		ListMembership
+	}
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_items").
+		Select("anpr_list_items.plate_id, anpr_lists.id as list_id, anpr_lists.name as list_name, anpr_lists.type as list_type, " +
+			"anpr_list_items.valid_from, anpr_list_items.valid_until").
+		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load list membership: %w", err)
+	}
+
+	membership := make(map[uuid.UUID][]ListMembership, len(rows))
+	for _, row := range rows {
+		membership[row.PlateID] = append(membership[row.PlateID], row.ListMembership)
+	}
+	return membership, nil
+}
+
+// ListPlatesInList возвращает нормализованные номера списка (например, default_whitelist), записи
+// которого действуют сейчас
+func (r *ANPRRepository) ListPlatesInList(ctx context.Context, listName string) ([]string, error) {
+	var plates []string
+	now := r.clock.Now()
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_items").
+		Select("anpr_plates.normalized").
+		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
+		Joins("JOIN anpr_plates ON anpr_list_items.plate_id = anpr_plates.id").
+		Where("anpr_lists.name = ?", listName).
+		Where(listItemActive, now, now).
+		Order("anpr_plates.normalized ASC").
+		Pluck("anpr_plates.normalized", &plates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plates in %s: %w", listName, err)
+	}
+	return plates, nil
+}
+
 func (r *ANPRRepository) FindPlatesByNormalized(ctx context.Context, normalized string) ([]Plate, error) {
 	var plates []Plate
 	err := r.db.WithContext(ctx).
@@ -346,37 +579,35 @@ func (r *ANPRRepository) FindPlatesByNormalized(ctx context.Context, normalized
 	return plates, err
 }
 
-func (r *ANPRRepository) FindEvents(ctx context.Context, normalizedPlate *string, from, to *time.Time, direction *string, limit, offset int) ([]ANPREvent, error) {
-	query := r.db.WithContext(ctx).Model(&ANPREvent{})
+// FindEvents находит события по параметрам поиска (см. EventSearch)
+func (r *ANPRRepository) FindEvents(ctx context.Context, search EventSearch) ([]ANPREvent, error) {
+	var events []ANPREvent
+	err := search.apply(r.db.WithContext(ctx).Model(&ANPREvent{})).Find(&events).Error
+	return events, err
+}
 
-	if normalizedPlate != nil {
-		query = query.Where("normalized_plate = ?", *normalizedPlate)
-	}
-	if from != nil {
-		query = query.Where("event_time >= ?", *from)
-	}
-	if to != nil {
-		query = query.Where("event_time <= ?", *to)
-	}
-	if direction != nil && *direction != "" {
-		query = query.Where("direction = ?", *direction)
+// StreamEvents передаёт fn события по фильтрам search (Limit и Offset не учитываются) по возрастанию времени.
+// Строки читаются из курсора по одной по мере обработки, а не загружаются в память: медленный потребитель
+// притормаживает чтение. Ошибка fn прерывает выборку и возвращается как есть.
+func (r *ANPRRepository) StreamEvents(ctx context.Context, search EventSearch, fn func(*ANPREvent) error) error {
+	rows, err := search.applyStream(r.db.WithContext(ctx).Model(&ANPREvent{})).Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream events: %w", err)
 	}
-
-	query = query.Order("event_time DESC")
-
-	if limit > 0 {
-		query = query.Limit(limit)
-		if limit > 100 {
-			query = query.Limit(100)
+	defer rows.Close()
+	for rows.Next() {
+		var event ANPREvent
+		if err := r.db.ScanRows(rows, &event); err != nil {
+			return fmt.Errorf("failed to scan streamed event: %w", err)
+		}
+		if err := fn(&event); err != nil {
+			return err
 		}
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to stream events: %w", err)
 	}
-
-	var events []ANPREvent
-	err := query.Find(&events).Error
-	return events, err
+	return nil
 }
 
 // FindEventsByPlateAndTime находит события по номеру, времени и направлению (для внутреннего использования)
@@ -397,21 +628,32 @@ func (r *ANPRRepository) FindEventsByPlateAndTime(ctx context.Context, normalize
 	return events, err
 }
 
-func (r *ANPRRepository) GetLastEventTimeForPlate(ctx context.Context, plateID uuid.UUID) (*time.Time, error) {
-	var event ANPREvent
-	err := r.db.WithContext(ctx).
-		Where("plate_id = ?", plateID).
-		Order("event_time DESC").
-		First(&event).Error
+// GetLastEventTimes возвращает время последнего события для каждого из номеров одним запросом.
+// Номера без событий в результат не попадают.
+func (r *ANPRRepository) GetLastEventTimes(ctx context.Context, plateIDs []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	result := make(map[uuid.UUID]time.Time, len(plateIDs))
+	if len(plateIDs) == 0 {
+		return result, nil
+	}
 
-	if err == gorm.ErrRecordNotFound {
-		return nil, nil
+	var rows []struct {
+		PlateID       uuid.UUID
+		LastEventTime time.Time
 	}
+	err := r.db.WithContext(ctx).
+		Model(&ANPREvent{}).
+		Select("plate_id, MAX(event_time) AS last_event_time").
+		Where("plate_id IN ?", plateIDs).
+		Group("plate_id").
+		Scan(&rows).Error
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get last event times: %w", err)
 	}
 
-	return &event.EventTime, nil
+	for _, row := range rows {
+		result[row.PlateID] = row.LastEventTime
+	}
+	return result, nil
 }
 
 // SyncVehicleToWhitelist синхронизирует номер из vehicles в whitelist
@@ -427,6 +669,40 @@ func (r *ANPRRepository) SyncVehicleToWhitelist(ctx context.Context, plateNumber
 	return plateID, nil
 }
 
+// vehicleWhitelistNote — пометка записей default_whitelist, добавленных anpr_sync_vehicle_to_whitelist
+const vehicleWhitelistNote = "Автоматически добавлен из vehicles"
+
+// RemoveVehicleFromWhitelist убирает номер из default_whitelist. Возвращает false, если его там не было.
+func (r *ANPRRepository) RemoveVehicleFromWhitelist(ctx context.Context, normalizedPlate string) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM anpr_list_items li
+		USING anpr_lists l, anpr_plates p
+		WHERE li.list_id = l.id AND li.plate_id = p.id
+			AND l.name = 'default_whitelist' AND p.normalized = ?`,
+		normalizedPlate)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove vehicle from whitelist: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListVehicleWhitelistPlates возвращает номера default_whitelist, добавленные синхронизацией с vehicles
+// (записи, добавленные вручную, не входят)
+func (r *ANPRRepository) ListVehicleWhitelistPlates(ctx context.Context) ([]string, error) {
+	var plates []string
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_items").
+		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
+		Joins("JOIN anpr_plates ON anpr_list_items.plate_id = anpr_plates.id").
+		Where("anpr_lists.name = ? AND anpr_list_items.note = ?", "default_whitelist", vehicleWhitelistNote).
+		Order("anpr_plates.normalized ASC").
+		Pluck("anpr_plates.normalized", &plates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicle whitelist plates: %w", err)
+	}
+	return plates, nil
+}
+
 // GetVehicleByPlate получает данные о транспорте по нормализованному номеру
 // Возвращает nil, если vehicle не найден или неактивен
 func (r *ANPRRepository) GetVehicleByPlate(ctx context.Context, normalizedPlate string) (*VehicleData, error) {
@@ -551,33 +827,73 @@ func (r *ANPRRepository) ExistsRecentEvent(ctx context.Context, normalizedPlate,
 	return count > 0, nil
 }
 
-// DeleteOldEvents удаляет события старше указанного количества дней
-func (r *ANPRRepository) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
-	cutoffTime := time.Now().AddDate(0, 0, -days)
+// SoftDeleteAllEvents помечает удалёнными все события; физически они удаляются PurgeDeletedEvents
+func (r *ANPRRepository) SoftDeleteAllEvents(ctx context.Context, deletedAt time.Time) (int64, error) {
 	result := r.db.WithContext(ctx).
-		Where("created_at < ?", cutoffTime).
-		Delete(&ANPREvent{})
-
+		Model(&ANPREvent{}).
+		Where("deleted_at IS NULL").
+		Update("deleted_at", deletedAt)
 	if result.Error != nil {
-		return 0, result.Error
+		return 0, fmt.Errorf("failed to soft delete events: %w", result.Error)
 	}
-
 	return result.RowsAffected, nil
 }
 
-// DeleteAllEvents удаляет все события из базы данных
-func (r *ANPRRepository) DeleteAllEvents(ctx context.Context) (int64, error) {
-	// Используем прямой SQL запрос для удаления всех событий
-	// Фотографии удалятся автоматически благодаря ON DELETE CASCADE в таблице anpr_event_photos
-	result := r.db.WithContext(ctx).Exec("DELETE FROM anpr_events")
+// SoftDeleteEventsBefore помечает удалёнными события с event_time раньше before
+func (r *ANPRRepository) SoftDeleteEventsBefore(ctx context.Context, before, deletedAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&ANPREvent{}).
+		Where("event_time < ?", before).
+		Update("deleted_at", deletedAt)
 	if result.Error != nil {
-		return 0, fmt.Errorf("failed to delete events from database: %w", result.Error)
+		return 0, fmt.Errorf("failed to soft delete old events: %w", result.Error)
 	}
 	return result.RowsAffected, nil
 }
 
-// CreateEventPhotos сохраняет фотографии события
-func (r *ANPRRepository) CreateEventPhotos(ctx context.Context, eventID uuid.UUID, photoURLs []string) error {
+// RestoreEvent снимает пометку удаления с события; false — события нет, оно не удалено или уже очищено
+func (r *ANPRRepository) RestoreEvent(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&ANPREvent{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to restore event: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PurgeDeletedEvents физически удаляет события, помеченные удалёнными раньше deletedBefore, вместе с фото
+// и комментариями
+func (r *ANPRRepository) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	// anpr_events секционирована, внешнего ключа с каскадом у фото и комментариев нет — удаляем их явно
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM anpr_event_photos
+			WHERE event_id IN (SELECT id FROM anpr_events WHERE deleted_at < ?)`, deletedBefore).Error; err != nil {
+			return fmt.Errorf("delete photos of deleted events: %w", err)
+		}
+		if err := tx.Exec(`DELETE FROM anpr_event_comments
+			WHERE event_id IN (SELECT id FROM anpr_events WHERE deleted_at < ?)`, deletedBefore).Error; err != nil {
+			return fmt.Errorf("delete comments of deleted events: %w", err)
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&ANPREvent{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted events: %w", err)
+	}
+	return purged, nil
+}
+
+// createEventPhotos сохраняет фото события. Повторная запись того же фото (ретрай загрузки)
+// игнорируется благодаря уникальному индексу (event_id, display_order, photo_url).
+func (r *ANPRRepository) createEventPhotos(tx *gorm.DB, eventID uuid.UUID, photoURLs []string) error {
 	if len(photoURLs) == 0 {
 		return nil
 	}
@@ -589,11 +905,16 @@ func (r *ANPRRepository) CreateEventPhotos(ctx context.Context, eventID uuid.UUI
 			EventID:      eventID,
 			PhotoURL:     url,
 			DisplayOrder: displayOrder,
-			CreatedAt:    time.Now(),
+			CreatedAt:    r.clock.Now(),
 		})
 	}
 
-	return r.db.WithContext(ctx).Create(&photos).Error
+	return tx.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}, {Name: "display_order"}, {Name: "photo_url"}},
+			DoNothing: true,
+		}).
+		Create(&photos).Error
 }
 
 func displayOrderFromPhotoURL(photoURL string, fallback int) int {
@@ -611,15 +932,15 @@ func displayOrderFromPhotoURL(photoURL string, fallback int) int {
 	return parsed
 }
 
-// GetEventByID получает событие по ID
+// GetEventByID получает событие по ID; ErrEventNotFound — события нет (или оно удалено)
 func (r *ANPRRepository) GetEventByID(ctx context.Context, eventID uuid.UUID) (*ANPREvent, error) {
 	var event ANPREvent
 	err := r.db.WithContext(ctx).Where("id = ?", eventID).First(&event).Error
-	if err == gorm.ErrRecordNotFound {
-		return nil, nil // Событие не найдено
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventID)
 	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get event: %w", err)
 	}
 	return &event, nil
 }
@@ -628,8 +949,10 @@ func (r *ANPRRepository) GetEventByID(ctx context.Context, eventID uuid.UUID) (*
 func (r *ANPRRepository) GetEventPhotos(ctx context.Context, eventID uuid.UUID) ([]EventPhoto, error) {
 	var photos []EventPhoto
 	err := r.db.WithContext(ctx).
-		Where("event_id = ?", eventID).
-		Order("display_order ASC").
+		Select("anpr_event_photos.*, pr.thumbnail_url, pr.medium_url").
+		Joins("LEFT JOIN anpr_photo_renditions pr ON pr.photo_url = anpr_event_photos.photo_url").
+		Where("anpr_event_photos.event_id = ?", eventID).
+		Order("anpr_event_photos.display_order ASC").
 		Find(&photos).Error
 	return photos, err
 }
@@ -727,49 +1050,71 @@ const (
 		`
 )
 
-// GetReportEvents получает события для отчетов с фильтрацией
-func (r *ANPRRepository) GetReportEvents(ctx context.Context, filters ReportFilters) ([]ReportEvent, error) {
-	query := r.db.WithContext(ctx).
-		Table("anpr_events AS e").
-		Select(reportPhotoSelectSQL).
-		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
-		Joins("LEFT JOIN organizations o ON o.id = COALESCE(e.contractor_id, v.contractor_id)").
-		Where("e.snow_volume_m3 IS NOT NULL AND e.snow_volume_m3 > 0") // Только события с объемом
+// applyReportFilters ограничивает запрос отчёта оплачиваемыми рейсами и применяет фильтры.
+// Запрос должен выбирать из anpr_events AS e с LEFT JOIN vehicles v.
+func applyReportFilters(query *gorm.DB, filters ReportFilters) *gorm.DB {
+	query = query.
+		Where("e.snow_volume_m3 IS NOT NULL AND e.snow_volume_m3 > 0"). // Только события с объемом
+		Where("e.out_of_schedule = FALSE").                             // События вне расписания камеры не считаются рейсами
+		Where("e.over_quota = FALSE").                                  // Рейсы сверх квоты не оплачиваются
+		Where("e.deleted_at IS NULL")                                   // Мягко удалённые события не учитываются
+
+	// Переотправленные с опозданием — только по явному запросу
+	if !filters.IncludeLate {
+		query = query.Where("e.late = FALSE")
+	}
 
-	// Фильтр по подрядчику (если указан)
 	// Используем поле contractor_id из anpr_events (если есть), иначе через JOIN с vehicles
 	if filters.ContractorID != nil {
 		query = query.Where("(e.contractor_id = ? OR v.contractor_id = ?)", *filters.ContractorID, *filters.ContractorID)
 	}
-
-	// Фильтр по полигону
 	if filters.PolygonID != nil {
 		query = query.Where("e.polygon_id = ?", *filters.PolygonID)
 	}
-
-	// Фильтр по периоду
+	if filters.PolygonOrgID != nil {
+		query = query.Where("e.polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *filters.PolygonOrgID)
+	}
 	if !filters.From.IsZero() {
 		query = query.Where("e.event_time >= ?", filters.From)
 	}
 	if !filters.To.IsZero() {
 		query = query.Where("e.event_time <= ?", filters.To)
 	}
-
-	// Фильтр по номеру (поиск)
 	if filters.PlateNumber != nil && *filters.PlateNumber != "" {
 		normalized := fmt.Sprintf("%%%s%%", *filters.PlateNumber)
-		query = query.Where("e.normalized_plate LIKE ? OR e.raw_plate LIKE ?", normalized, normalized)
+		query = query.Where("(e.normalized_plate LIKE ? OR e.raw_plate LIKE ?)", normalized, normalized)
 	}
-
-	// Фильтр по vehicle_id
 	if filters.VehicleID != nil {
 		query = query.Where("v.id = ?", *filters.VehicleID)
 	}
-
+	if filters.VehicleType != nil {
+		query = query.Where("e.vehicle_type = ?", *filters.VehicleType)
+	}
+	if len(filters.Sources) > 0 {
+		query = query.Where("e.source IN ?", filters.Sources)
+	}
 	// Для подрядчиков показываем только привязанные события
 	if filters.OnlyAssigned {
 		query = query.Where("(e.contractor_id IS NOT NULL OR v.contractor_id IS NOT NULL)")
 	}
+	if filters.OnlyWrongDestination {
+		query = query.Where("e.wrong_destination = TRUE")
+	}
+	if filters.OnlyInShift {
+		query = query.Where(inShiftSQL)
+	}
+	return query
+}
+
+// GetReportEvents получает события для отчетов с фильтрацией
+func (r *ANPRRepository) GetReportEvents(ctx context.Context, filters ReportFilters) ([]ReportEvent, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(reportPhotoSelectSQL).
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
+		Joins("LEFT JOIN organizations o ON o.id = COALESCE(e.contractor_id, v.contractor_id)")
+
+	query = applyReportFilters(query, filters)
 
 	query = query.Order("e.event_time DESC")
 
@@ -791,38 +1136,12 @@ func (r *ANPRRepository) GetReportStats(ctx context.Context, filters ReportFilte
 		Table("anpr_events AS e").
 		Select(`
 			COALESCE(SUM(e.snow_volume_m3), 0) AS total_volume,
-			COUNT(*) AS trip_count
+			COUNT(*) AS trip_count,
+			COUNT(*) FILTER (WHERE e.wrong_destination) AS wrong_destination_count
 		`).
-		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
-		Where("e.snow_volume_m3 IS NOT NULL AND e.snow_volume_m3 > 0")
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true")
 
-	// Применяем те же фильтры, что и в GetReportEvents
-	// Используем поле contractor_id из anpr_events (если есть), иначе через JOIN с vehicles
-	if filters.ContractorID != nil {
-		query = query.Where("(e.contractor_id = ? OR v.contractor_id = ?)", *filters.ContractorID, *filters.ContractorID)
-	}
-	if filters.PolygonID != nil {
-		query = query.Where("e.polygon_id = ?", *filters.PolygonID)
-	}
-	if !filters.From.IsZero() {
-		query = query.Where("e.event_time >= ?", filters.From)
-	}
-	if !filters.To.IsZero() {
-		query = query.Where("e.event_time <= ?", filters.To)
-	}
-	if filters.PlateNumber != nil && *filters.PlateNumber != "" {
-		normalized := fmt.Sprintf("%%%s%%", *filters.PlateNumber)
-		query = query.Where("e.normalized_plate LIKE ? OR e.raw_plate LIKE ?", normalized, normalized)
-	}
-	if filters.VehicleID != nil {
-		query = query.Where("v.id = ?", *filters.VehicleID)
-	}
-	if filters.OnlyAssigned {
-		query = query.Where("(e.contractor_id IS NOT NULL OR v.contractor_id IS NOT NULL)")
-	}
-	if filters.UseOperationalWindow {
-		query = query.Where("((e.event_time AT TIME ZONE 'Asia/Qyzylorda')::time >= TIME '16:00:00' OR (e.event_time AT TIME ZONE 'Asia/Qyzylorda')::time < TIME '10:00:00')")
-	}
+	query = applyReportFilters(query, filters)
 
 	var stats ReportStats
 	err := query.Scan(&stats).Error
@@ -835,10 +1154,15 @@ type ReportFilters struct {
 	PolygonID            *uuid.UUID
 	VehicleID            *uuid.UUID
 	PlateNumber          *string
+	VehicleType          *string // канонический тип транспорта
 	From                 time.Time
 	To                   time.Time
-	OnlyAssigned         bool // Только привязанные события (для подрядчиков)
-	UseOperationalWindow bool // Учитывать только рабочее окно 16:00-10:00 (Asia/Qyzylorda)
+	PolygonOrgID         *uuid.UUID // Только события полигонов организации (для LANDFILL_*)
+	OnlyAssigned         bool       // Только привязанные события (для подрядчиков)
+	OnlyWrongDestination bool       // Только события на полигоне, за которым подрядчик не закреплён
+	OnlyInShift          bool       // Только события в окне смены полигона по календарю смен (anpr_shifts)
+	Sources              []string   // Только события из этих источников (anpr.Source*); пусто — все
+	IncludeLate          bool       // Учитывать и события, пришедшие позже EVENT_MAX_AGE (late)
 	Limit                int
 	Offset               int
 	MaxRows              int // Максимальное количество строк для экспорта
@@ -846,8 +1170,9 @@ type ReportFilters struct {
 
 // ReportStats содержит статистику для отчетов
 type ReportStats struct {
-	TotalVolume float64 `gorm:"column:total_volume"`
-	TripCount   int64   `gorm:"column:trip_count"`
+	TotalVolume           float64 `gorm:"column:total_volume"`
+	TripCount             int64   `gorm:"column:trip_count"`
+	WrongDestinationCount int64   `gorm:"column:wrong_destination_count"` // рейсы на чужой полигон
 }
 
 // HourlyActivityStat содержит агрегированные показатели по часам суток (0..23)
@@ -867,34 +1192,9 @@ func (r *ANPRRepository) GetHourlyActivityStats(ctx context.Context, filters Rep
 			COALESCE(SUM(e.snow_volume_m3), 0) AS total_volume,
 			COUNT(*) AS trip_count
 		`).
-		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
-		Where("e.snow_volume_m3 IS NOT NULL AND e.snow_volume_m3 > 0")
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true")
 
-	if filters.ContractorID != nil {
-		query = query.Where("(e.contractor_id = ? OR v.contractor_id = ?)", *filters.ContractorID, *filters.ContractorID)
-	}
-	if filters.PolygonID != nil {
-		query = query.Where("e.polygon_id = ?", *filters.PolygonID)
-	}
-	if !filters.From.IsZero() {
-		query = query.Where("e.event_time >= ?", filters.From)
-	}
-	if !filters.To.IsZero() {
-		query = query.Where("e.event_time <= ?", filters.To)
-	}
-	if filters.PlateNumber != nil && *filters.PlateNumber != "" {
-		normalized := fmt.Sprintf("%%%s%%", *filters.PlateNumber)
-		query = query.Where("e.normalized_plate LIKE ? OR e.raw_plate LIKE ?", normalized, normalized)
-	}
-	if filters.VehicleID != nil {
-		query = query.Where("v.id = ?", *filters.VehicleID)
-	}
-	if filters.OnlyAssigned {
-		query = query.Where("(e.contractor_id IS NOT NULL OR v.contractor_id IS NOT NULL)")
-	}
-	if filters.UseOperationalWindow {
-		query = query.Where("((e.event_time AT TIME ZONE 'Asia/Qyzylorda')::time >= TIME '16:00:00' OR (e.event_time AT TIME ZONE 'Asia/Qyzylorda')::time < TIME '10:00:00')")
-	}
+	query = applyReportFilters(query, filters)
 
 	query = query.
 		Group("hour_of_day").
@@ -905,6 +1205,36 @@ func (r *ANPRRepository) GetHourlyActivityStats(ctx context.Context, filters Rep
 	return rows, err
 }
 
+// VehicleTypeStat содержит показатели рейсов по каноническому типу транспорта
+type VehicleTypeStat struct {
+	VehicleType string  `gorm:"column:vehicle_type"`
+	TotalVolume float64 `gorm:"column:total_volume"`
+	TripCount   int64   `gorm:"column:trip_count"`
+}
+
+// GetVehicleTypeStats возвращает объём и число рейсов в разрезе типа транспорта.
+// События без типа (до нормализации или без классификации камеры) попадают в "unknown".
+func (r *ANPRRepository) GetVehicleTypeStats(ctx context.Context, filters ReportFilters) ([]VehicleTypeStat, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			COALESCE(NULLIF(e.vehicle_type, ''), 'unknown') AS vehicle_type,
+			COALESCE(SUM(e.snow_volume_m3), 0) AS total_volume,
+			COUNT(*) AS trip_count
+		`).
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true")
+
+	query = applyReportFilters(query, filters)
+
+	query = query.
+		Group("1").
+		Order("trip_count DESC, vehicle_type ASC")
+
+	var rows []VehicleTypeStat
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
 // reportPhotoSelectExcelSQL — те же правила выбора plate/body по camera_id, что и в отчётах (см. комментарий выше).
 const reportPhotoSelectExcelSQL = `
 			e.*,
@@ -988,7 +1318,8 @@ func (r *ANPRRepository) GetReportEventsForExcel(ctx context.Context, filters Re
 	query := r.db.WithContext(ctx).
 		Table("anpr_events AS e").
 		Select(reportPhotoSelectExcelSQL).
-		Joins("LEFT JOIN organizations o ON o.id = e.contractor_id")
+		Joins("LEFT JOIN organizations o ON o.id = e.contractor_id").
+		Where("e.deleted_at IS NULL")
 	// Для Excel выгрузки показываем все события, не только с snow_volume_m3 > 0
 
 	// Фильтр по подрядчику (если указан)
@@ -1000,6 +1331,9 @@ func (r *ANPRRepository) GetReportEventsForExcel(ctx context.Context, filters Re
 	if filters.PolygonID != nil {
 		query = query.Where("e.polygon_id = ?", *filters.PolygonID)
 	}
+	if filters.PolygonOrgID != nil {
+		query = query.Where("e.polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *filters.PolygonOrgID)
+	}
 
 	// Фильтр по периоду
 	if !filters.From.IsZero() {
@@ -1020,6 +1354,14 @@ func (r *ANPRRepository) GetReportEventsForExcel(ctx context.Context, filters Re
 	//     query = query.Where("v.id = ?", *filters.VehicleID)
 	// }
 
+	// Фильтр по типу транспорта
+	if filters.VehicleType != nil {
+		query = query.Where("e.vehicle_type = ?", *filters.VehicleType)
+	}
+	if len(filters.Sources) > 0 {
+		query = query.Where("e.source IN ?", filters.Sources)
+	}
+
 	// Для подрядчиков показываем только привязанные события
 	if filters.OnlyAssigned {
 		query = query.Where("e.contractor_id IS NOT NULL")
@@ -1045,7 +1387,8 @@ func (r *ANPRRepository) GetReportEventsForExcel(ctx context.Context, filters Re
 // Работает без таблиц vehicles и organizations (использует только данные из anpr_events)
 func (r *ANPRRepository) CountReportEventsForExcel(ctx context.Context, filters ReportFilters) (int64, error) {
 	query := r.db.WithContext(ctx).
-		Table("anpr_events AS e")
+		Table("anpr_events AS e").
+		Where("e.deleted_at IS NULL")
 		// Для Excel выгрузки показываем все события, не только с snow_volume_m3 > 0
 
 	// Применяем те же фильтры, что и в GetReportEventsForExcel
@@ -1055,6 +1398,9 @@ func (r *ANPRRepository) CountReportEventsForExcel(ctx context.Context, filters
 	if filters.PolygonID != nil {
 		query = query.Where("e.polygon_id = ?", *filters.PolygonID)
 	}
+	if filters.PolygonOrgID != nil {
+		query = query.Where("e.polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *filters.PolygonOrgID)
+	}
 	if !filters.From.IsZero() {
 		query = query.Where("e.event_time >= ?", filters.From)
 	}
@@ -1069,6 +1415,12 @@ func (r *ANPRRepository) CountReportEventsForExcel(ctx context.Context, filters
 	// if filters.VehicleID != nil {
 	//     query = query.Where("v.id = ?", *filters.VehicleID)
 	// }
+	if filters.VehicleType != nil {
+		query = query.Where("e.vehicle_type = ?", *filters.VehicleType)
+	}
+	if len(filters.Sources) > 0 {
+		query = query.Where("e.source IN ?", filters.Sources)
+	}
 	if filters.OnlyAssigned {
 		query = query.Where("e.contractor_id IS NOT NULL")
 	}
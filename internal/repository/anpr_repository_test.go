@@ -1,6 +1,27 @@
 package repository
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/db"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/utils"
+)
 
 func TestDisplayOrderFromPhotoURL(t *testing.T) {
 	tests := []struct {
@@ -38,3 +59,160 @@ func TestDisplayOrderFromPhotoURL(t *testing.T) {
 		})
 	}
 }
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: fmt.Errorf("failed to create ANPR event in database: %w", &pgconn.PgError{Code: "40P01"}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "not a postgres error", err: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableTxError(tt.err); got != tt.want {
+				t.Errorf("isRetryableTxError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyReportFiltersLate(t *testing.T) {
+	dryRun, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		includeLate bool
+		wantLate    bool
+	}{
+		{name: "late events excluded by default", includeLate: false, wantLate: true},
+		{name: "include_late keeps late events", includeLate: true, wantLate: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := dryRun.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var stats ReportStats
+				query := tx.Table("anpr_events AS e").Select("COUNT(*) AS trip_count")
+				return applyReportFilters(query, ReportFilters{IncludeLate: tt.includeLate}).Scan(&stats)
+			})
+			if got := strings.Contains(sql, "e.late = FALSE"); got != tt.wantLate {
+				t.Errorf("late condition in %q = %v, want %v", sql, got, tt.wantLate)
+			}
+			if !strings.Contains(sql, "e.over_quota = FALSE") {
+				t.Errorf("query %q lost the over_quota condition", sql)
+			}
+		})
+	}
+}
+
+// openTestDatabase открывает тестовую базу ANPR_TEST_DATABASE_DSN с применёнными миграциями; без неё тест пропускается
+func openTestDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseDSNEnv)
+	}
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.MigrateUp(context.Background(), database, zerolog.Nop()); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// normalize_plate_number используется в соединениях с vehicles и должна совпадать с utils.NormalizePlate
+func TestNormalizePlateNumberMatchesGoDatabase(t *testing.T) {
+	database := openTestDatabase(t)
+	inputs := []string{
+		"123 ABC 02", "123abc02", " 123-AbC-02 ", "123 AB 02", "KZ 123 ABC 02", "123 АВС 02",
+		"А 123 ВС 77", "a123bc777", "А123ВС 77 RUS", "0123ОО77", "01 KG 123 ABC", "01-123-ABC",
+		"а 123 вс 77", "AB.12", "  ",
+	}
+	for _, input := range inputs {
+		var got string
+		if err := database.Raw("SELECT normalize_plate_number(?)", input).Scan(&got).Error; err != nil {
+			t.Fatal(err)
+		}
+		if want := utils.NormalizePlate(input); got != want {
+			t.Errorf("normalize_plate_number(%q) = %q, utils.NormalizePlate = %q", input, got, want)
+		}
+	}
+}
+
+func TestSaveANPREventConcurrentNewPlateDatabase(t *testing.T) {
+	database := openTestDatabase(t)
+	ctx := context.Background()
+
+	repo := NewANPRRepository(database, clock.System(), idgen.Random())
+	plate := "TEST" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		database.Unscoped().Where("normalized_plate = ?", plate).Delete(&ANPREvent{})
+		database.Where("normalized = ?", plate).Delete(&Plate{})
+	})
+
+	// Первые проезды нового номера одновременно с нескольких камер: все события сохраняются на один номер
+	const pushes = 8
+	events := make([]*anpr.Event, pushes)
+	errs := make([]error, pushes)
+	var wg sync.WaitGroup
+	for i := range events {
+		events[i] = &anpr.Event{
+			ID:              uuid.New(),
+			NormalizedPlate: plate,
+			EventPayload: anpr.EventPayload{
+				CameraID:  fmt.Sprintf("test-camera-%d", i),
+				Plate:     plate,
+				EventTime: time.Now().UTC(),
+			},
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = repo.SaveANPREvent(ctx, events[i], utils.PlateCountryKZ, "02", nil, nil, []string{fmt.Sprintf("https://example.com/%s-photo-0.jpg", events[i].ID)})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("push %d: SaveANPREvent() error = %v", i, err)
+		}
+		if events[i].PlateID != events[0].PlateID {
+			t.Fatalf("push %d saved on plate %s, want %s", i, events[i].PlateID, events[0].PlateID)
+		}
+	}
+	// Страна номера, созданного в транзакции события, не теряется
+	var saved Plate
+	if err := database.Where("id = ?", events[0].PlateID).First(&saved).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Country == nil || *saved.Country != utils.PlateCountryKZ {
+		t.Errorf("plate country = %v, want %s", saved.Country, utils.PlateCountryKZ)
+	}
+	var photos int64
+	if err := database.Model(&EventPhoto{}).Where("event_id IN ?", eventIDs(events)).Count(&photos).Error; err != nil {
+		t.Fatal(err)
+	}
+	if photos != pushes {
+		t.Errorf("saved %d photos, want %d", photos, pushes)
+	}
+}
+
+func eventIDs(events []*anpr.Event) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BillingPeriod — сохранённая ведомость оплаты рейсов за месяц
+type BillingPeriod struct {
+	Period      string `gorm:"primaryKey"` // YYYY-MM
+	PeriodFrom  time.Time
+	PeriodTo    time.Time
+	Lines       datatypes.JSON `gorm:"type:jsonb"` // []BillingLine
+	Checksum    string
+	GeneratedAt time.Time
+	LockedAt    *time.Time // nil — месяц открыт и пересчитывается при выгрузке
+	LockedBy    *uuid.UUID `gorm:"type:uuid"`
+}
+
+func (BillingPeriod) TableName() string {
+	return "anpr_billing_periods"
+}
+
+// BillingLine — оплачиваемые рейсы одной машины подрядчика за период
+type BillingLine struct {
+	ContractorID   uuid.UUID `gorm:"column:contractor_id" json:"contractor_id"`
+	ContractorName string    `gorm:"column:contractor_name" json:"contractor_name"`
+	VehicleID      uuid.UUID `gorm:"column:vehicle_id" json:"vehicle_id"`
+	PlateNumber    string    `gorm:"column:plate_number" json:"plate_number"`
+	BodyVolumeM3   float64   `gorm:"column:body_volume_m3" json:"body_volume_m3"`
+	Trips          int64     `gorm:"column:trips" json:"trips"`
+}
+
+// GetBillingLines считает оплачиваемые рейсы в [from, to) по машинам подрядчиков. Рейс оплачивается
+// на тех же условиях, что учитывается в отчётах (есть объём, не вне расписания камеры, не сверх квоты),
+// если номер сопоставлен с активной машиной подрядчика и въезд не запрещён правилами.
+func (r *ANPRRepository) GetBillingLines(ctx context.Context, from, to time.Time) ([]BillingLine, error) {
+	var lines []BillingLine
+	err := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			COALESCE(e.contractor_id, v.contractor_id) AS contractor_id,
+			COALESCE(MAX(o.name), '') AS contractor_name,
+			v.id AS vehicle_id,
+			v.plate_number AS plate_number,
+			COALESCE(v.body_volume_m3, 0) AS body_volume_m3,
+			COUNT(*) AS trips
+		`).
+		Joins("JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
+		Joins("LEFT JOIN organizations o ON o.id = COALESCE(e.contractor_id, v.contractor_id)").
+		Where("e.event_time >= ? AND e.event_time < ?", from, to).
+		Where("e.snow_volume_m3 IS NOT NULL AND e.snow_volume_m3 > 0").
+		Where("e.out_of_schedule = FALSE AND e.late = FALSE").
+		Where("e.over_quota = FALSE").
+		Where("e.deleted_at IS NULL").
+		Where("e.access_decision IS DISTINCT FROM 'DENY'").
+		Where("COALESCE(e.contractor_id, v.contractor_id) IS NOT NULL").
+		Group("COALESCE(e.contractor_id, v.contractor_id), v.id, v.plate_number, v.body_volume_m3").
+		Order("contractor_id ASC, plate_number ASC, vehicle_id ASC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing lines: %w", err)
+	}
+	return lines, nil
+}
+
+// GetBillingPeriod получает ведомость месяца; ErrBillingPeriodNotFound — она ещё не формировалась
+func (r *ANPRRepository) GetBillingPeriod(ctx context.Context, period string) (*BillingPeriod, error) {
+	var billing BillingPeriod
+	err := r.db.WithContext(ctx).Where("period = ?", period).First(&billing).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrBillingPeriodNotFound, period)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing period: %w", err)
+	}
+	return &billing, nil
+}
+
+// SaveBillingPeriod сохраняет ведомость открытого месяца. Ведомость заблокированного месяца не
+// перезаписывается, даже если блокировка случилась параллельно с пересчётом.
+func (r *ANPRRepository) SaveBillingPeriod(ctx context.Context, billing *BillingPeriod) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "period"}},
+			DoUpdates: clause.AssignmentColumns([]string{"period_from", "period_to", "lines", "checksum", "generated_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "anpr_billing_periods.locked_at IS NULL"}}},
+		}).
+		Omit("locked_at", "locked_by").
+		Create(billing).Error
+	if err != nil {
+		return fmt.Errorf("failed to save billing period: %w", err)
+	}
+	return nil
+}
+
+// LockBillingPeriod блокирует ведомость месяца; false — ведомости нет или она уже заблокирована
+func (r *ANPRRepository) LockBillingPeriod(ctx context.Context, period string, lockedBy uuid.UUID, lockedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&BillingPeriod{}).
+		Where("period = ? AND locked_at IS NULL", period).
+		Updates(map[string]interface{}{"locked_at": lockedAt, "locked_by": lockedBy})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to lock billing period: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
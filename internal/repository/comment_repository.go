@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventComment — комментарий оператора к событию
+type EventComment struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EventID    uuid.UUID `gorm:"type:uuid;not null"`
+	AuthorID   uuid.UUID `gorm:"type:uuid;not null"`
+	AuthorRole string    `gorm:"not null"`
+	Body       string    `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (EventComment) TableName() string {
+	return "anpr_event_comments"
+}
+
+// CreateEventComment сохраняет комментарий к событию
+func (r *ANPRRepository) CreateEventComment(ctx context.Context, comment *EventComment) error {
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = r.clock.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(comment).Error; err != nil {
+		return fmt.Errorf("failed to create event comment: %w", err)
+	}
+	return nil
+}
+
+// ListEventComments возвращает комментарии к событию в порядке добавления
+func (r *ANPRRepository) ListEventComments(ctx context.Context, eventID uuid.UUID) ([]EventComment, error) {
+	var comments []EventComment
+	err := r.db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event comments: %w", err)
+	}
+	return comments, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DeadLetter — непринятое уведомление камеры
+type DeadLetter struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Endpoint        string    `gorm:"not null"` // маршрут приёма (hikvision)
+	CameraID        string    // camera_id из строки запроса
+	RemoteAddr      string
+	ContentType     string
+	Headers         datatypes.JSON `gorm:"type:jsonb"`
+	Payload         []byte         `gorm:"not null"` // тело запроса как есть
+	PayloadSize     int64          `gorm:"not null"`
+	Error           string         `gorm:"not null"`
+	ReceivedAt      time.Time      `gorm:"not null"`
+	ReplayedAt      *time.Time     // время успешной повторной обработки
+	ReplayedEventID *uuid.UUID     `gorm:"type:uuid"`
+	ReplayError     *string        // ошибка последней повторной обработки
+}
+
+func (DeadLetter) TableName() string {
+	return "anpr_dead_letters"
+}
+
+// CreateDeadLetter сохраняет непринятое уведомление
+func (r *ANPRRepository) CreateDeadLetter(ctx context.Context, letter *DeadLetter) error {
+	if letter.ReceivedAt.IsZero() {
+		letter.ReceivedAt = r.clock.Now()
+	}
+	letter.PayloadSize = int64(len(letter.Payload))
+	if err := r.db.WithContext(ctx).Create(letter).Error; err != nil {
+		return fmt.Errorf("failed to create dead letter: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters возвращает уведомления без тела, новые первыми; pendingOnly — только не обработанные повторно
+func (r *ANPRRepository) ListDeadLetters(ctx context.Context, pendingOnly bool, limit, offset int) ([]DeadLetter, error) {
+	query := r.db.WithContext(ctx).Omit("payload")
+	if pendingOnly {
+		query = query.Where("replayed_at IS NULL")
+	}
+	var letters []DeadLetter
+	err := query.
+		Order("received_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&letters).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// GetDeadLetter получает уведомление вместе с телом; ErrDeadLetterNotFound — его нет
+func (r *ANPRRepository) GetDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetter, error) {
+	var letter DeadLetter
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&letter).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return &letter, nil
+}
+
+// MarkDeadLetterReplayed записывает результат повторной обработки: eventID при успехе, replayErr при ошибке
+func (r *ANPRRepository) MarkDeadLetterReplayed(ctx context.Context, id uuid.UUID, replayedAt time.Time, eventID *uuid.UUID, replayErr *string) error {
+	updates := map[string]interface{}{"replay_error": replayErr}
+	if eventID != nil {
+		updates["replayed_at"] = replayedAt
+		updates["replayed_event_id"] = eventID
+	}
+	err := r.db.WithContext(ctx).
+		Model(&DeadLetter{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter replayed: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeadLetters удаляет уведомления, полученные раньше before
+func (r *ANPRRepository) PurgeDeadLetters(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("received_at < ?", before).Delete(&DeadLetter{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound — запись не найдена. Ошибки «не найдено» конкретных сущностей оборачивают её, поэтому
+// errors.Is(err, ErrNotFound) верно для любой из них. Их возвращают поиски сущности по ID или имени.
+// Поиски необязательных сведений о номере (машина, водитель и подрядчик по номеру, правило доступа
+// подрядчика, квота рейсов, режим полигона) возвращают nil без ошибки: их отсутствие — обычный ответ.
+var ErrNotFound = errors.New("not found")
+
+var (
+	ErrPlateNotFound               = fmt.Errorf("plate %w", ErrNotFound)
+	ErrEventNotFound               = fmt.Errorf("event %w", ErrNotFound)
+	ErrListNotFound                = fmt.Errorf("list %w", ErrNotFound)
+	ErrCameraNotFound              = fmt.Errorf("camera %w", ErrNotFound)
+	ErrPolygonNotFound             = fmt.Errorf("polygon %w", ErrNotFound)
+	ErrShiftNotFound               = fmt.Errorf("shift %w", ErrNotFound)
+	ErrSavedSearchNotFound         = fmt.Errorf("saved search %w", ErrNotFound)
+	ErrWebhookSubscriptionNotFound = fmt.Errorf("webhook subscription %w", ErrNotFound)
+	ErrDeadLetterNotFound          = fmt.Errorf("dead letter %w", ErrNotFound)
+	ErrBillingPeriodNotFound       = fmt.Errorf("billing period %w", ErrNotFound)
+	ErrSummarySubscriptionNotFound = fmt.Errorf("summary subscription %w", ErrNotFound)
+)
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ограничения постраничного поиска событий: большой OFFSET заставляет PostgreSQL прочитать и отбросить
+// все предыдущие строки, поэтому глубже MaxEventSearchOffset листать нельзя — нужно сузить период
+const (
+	DefaultEventSearchLimit = 50
+	MaxEventSearchLimit     = 100
+	MaxEventSearchOffset    = 10000
+)
+
+// EventSearch — параметры поиска событий. Пустые поля не фильтруют.
+type EventSearch struct {
+	NormalizedPlate *string
+	From            *time.Time
+	To              *time.Time
+	// TimeField — колонка для фильтра по времени и сортировки (EventTimeFieldEventTime или EventTimeFieldReceivedAt)
+	TimeField   string
+	Direction   *string
+	VehicleType *string
+	// Sources — только события из этих источников (anpr.Source*)
+	Sources   []string
+	PolygonID *uuid.UUID
+	// PolygonOrgID — только события полигонов организации (пользователи LANDFILL_*)
+	PolygonOrgID *uuid.UUID
+	Limit        int
+	Offset       int
+}
+
+// normalize приводит параметры к допустимым: неизвестная колонка времени заменяется на event_time,
+// limit и offset ограничиваются сверху
+func (s EventSearch) normalize() EventSearch {
+	if s.TimeField != EventTimeFieldReceivedAt {
+		s.TimeField = EventTimeFieldEventTime
+	}
+	if s.Limit <= 0 {
+		s.Limit = DefaultEventSearchLimit
+	}
+	if s.Limit > MaxEventSearchLimit {
+		s.Limit = MaxEventSearchLimit
+	}
+	if s.Offset < 0 {
+		s.Offset = 0
+	}
+	if s.Offset > MaxEventSearchOffset {
+		s.Offset = MaxEventSearchOffset
+	}
+	return s
+}
+
+// apply добавляет к запросу фильтры, сортировку и пагинацию. Сортировка (time DESC, id DESC)
+// совпадает с индексами idx_anpr_events_*_time_id: страницы стабильны при одинаковом времени
+// и читаются по индексу без сортировки всей выборки.
+func (s EventSearch) apply(query *gorm.DB) *gorm.DB {
+	s = s.normalize()
+	query = s.applyFilters(query)
+	query = query.Order(s.TimeField + " DESC").Order("id DESC").Limit(s.Limit)
+	if s.Offset > 0 {
+		query = query.Offset(s.Offset)
+	}
+	return query
+}
+
+// applyStream добавляет к запросу фильтры и сортировку по возрастанию (time ASC, id ASC) без пагинации —
+// выборка для потоковой выгрузки
+func (s EventSearch) applyStream(query *gorm.DB) *gorm.DB {
+	s = s.normalize()
+	return s.applyFilters(query).Order(s.TimeField + " ASC").Order("id ASC")
+}
+
+// applyFilters добавляет к запросу только фильтры; TimeField должен быть нормализован
+func (s EventSearch) applyFilters(query *gorm.DB) *gorm.DB {
+	if s.NormalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *s.NormalizedPlate)
+	}
+	if s.From != nil {
+		query = query.Where(s.TimeField+" >= ?", *s.From)
+	}
+	if s.To != nil {
+		query = query.Where(s.TimeField+" <= ?", *s.To)
+	}
+	if s.Direction != nil && *s.Direction != "" {
+		query = query.Where("direction = ?", *s.Direction)
+	}
+	if s.VehicleType != nil {
+		query = query.Where("vehicle_type = ?", *s.VehicleType)
+	}
+	if len(s.Sources) > 0 {
+		query = query.Where("source IN ?", s.Sources)
+	}
+	if s.PolygonID != nil {
+		query = query.Where("polygon_id = ?", *s.PolygonID)
+	}
+	if s.PolygonOrgID != nil {
+		query = query.Where("polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *s.PolygonOrgID)
+	}
+	return query
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/idgen"
+)
+
+// testDatabaseDSNEnv — DSN тестовой базы PostgreSQL; без него тесты с базой пропускаются
+const testDatabaseDSNEnv = "ANPR_TEST_DATABASE_DSN"
+
+func eventSearchSQL(t *testing.T, search EventSearch) string {
+	t.Helper()
+	dryRun, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dryRun.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var events []ANPREvent
+		return search.apply(tx.Model(&ANPREvent{})).Find(&events)
+	})
+}
+
+func TestEventSearchApply(t *testing.T) {
+	plate := "A123BC"
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	direction := "entry"
+	empty := ""
+	polygonID := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	orgID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+	tests := []struct {
+		name   string
+		search EventSearch
+		want   string
+	}{
+		{
+			name:   "defaults",
+			search: EventSearch{},
+			want:   `SELECT * FROM "anpr_events" WHERE "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 50`,
+		},
+		{
+			name:   "limit above max is capped once",
+			search: EventSearch{Limit: 500},
+			want:   `SELECT * FROM "anpr_events" WHERE "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 100`,
+		},
+		{
+			name:   "offset is capped",
+			search: EventSearch{Limit: 10, Offset: 1_000_000},
+			want:   `SELECT * FROM "anpr_events" WHERE "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10 OFFSET 10000`,
+		},
+		{
+			name:   "negative offset is ignored",
+			search: EventSearch{Limit: 10, Offset: -5},
+			want:   `SELECT * FROM "anpr_events" WHERE "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10`,
+		},
+		{
+			name:   "unknown time field falls back to event_time",
+			search: EventSearch{TimeField: "created_at; DROP TABLE anpr_events", From: &from, Limit: 10},
+			want:   `SELECT * FROM "anpr_events" WHERE event_time >= '2025-01-01 00:00:00' AND "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10`,
+		},
+		{
+			name: "filters by received_at",
+			search: EventSearch{
+				NormalizedPlate: &plate,
+				From:            &from,
+				To:              &from,
+				TimeField:       EventTimeFieldReceivedAt,
+				Direction:       &direction,
+				Limit:           20,
+				Offset:          40,
+			},
+			want: `SELECT * FROM "anpr_events" WHERE normalized_plate = 'A123BC' AND received_at >= '2025-01-01 00:00:00' AND received_at <= '2025-01-01 00:00:00' AND direction = 'entry' AND "anpr_events"."deleted_at" IS NULL ORDER BY received_at DESC,id DESC LIMIT 20 OFFSET 40`,
+		},
+		{
+			name:   "filters by polygon and landfill organization",
+			search: EventSearch{PolygonID: &polygonID, PolygonOrgID: &orgID, Limit: 10},
+			want:   `SELECT * FROM "anpr_events" WHERE polygon_id = '7c9e6679-7425-40de-944b-e07fc1f90ae7' AND polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = '550e8400-e29b-41d4-a716-446655440000') AND "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10`,
+		},
+		{
+			name:   "filters by source",
+			search: EventSearch{Sources: []string{"camera", "manual"}, Limit: 10},
+			want:   `SELECT * FROM "anpr_events" WHERE source IN ('camera','manual') AND "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10`,
+		},
+		{
+			name:   "empty direction does not filter",
+			search: EventSearch{Direction: &empty, Limit: 10},
+			want:   `SELECT * FROM "anpr_events" WHERE "anpr_events"."deleted_at" IS NULL ORDER BY event_time DESC,id DESC LIMIT 10`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventSearchSQL(t, tt.search); got != tt.want {
+				t.Fatalf("SQL =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventSearchApplyStream(t *testing.T) {
+	dryRun, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	search := EventSearch{From: &from, TimeField: EventTimeFieldReceivedAt, Limit: 10, Offset: 20}
+	got := dryRun.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var events []ANPREvent
+		return search.applyStream(tx.Model(&ANPREvent{})).Find(&events)
+	})
+	want := `SELECT * FROM "anpr_events" WHERE received_at >= '2025-01-01 00:00:00' AND "anpr_events"."deleted_at" IS NULL ORDER BY received_at ASC,id ASC`
+	if got != want {
+		t.Fatalf("SQL =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFindEventsDatabase(t *testing.T) {
+	database := openTestDatabase(t)
+	ctx := context.Background()
+
+	repo := NewANPRRepository(database, clock.System(), idgen.Random())
+	plate := "TEST" + uuid.NewString()[:8]
+	base := time.Now().UTC().Truncate(time.Second)
+	t.Cleanup(func() {
+		database.Unscoped().Where("normalized_plate = ?", plate).Delete(&ANPREvent{})
+	})
+
+	// Два события с одинаковым временем проверяют стабильность порядка по id
+	times := []time.Time{base, base, base.Add(-time.Minute), base.Add(-2 * time.Minute), base.Add(-3 * time.Minute)}
+	var ids []uuid.UUID
+	for _, eventTime := range times {
+		event := ANPREvent{
+			ID:              uuid.New(),
+			CameraID:        "test-camera",
+			RawPlate:        plate,
+			NormalizedPlate: plate,
+			EventTime:       eventTime,
+			ReceivedAt:      eventTime,
+		}
+		if err := database.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, event.ID)
+	}
+
+	var seen []uuid.UUID
+	for offset := 0; offset < len(times); offset += 2 {
+		page, err := repo.FindEvents(ctx, EventSearch{NormalizedPlate: &plate, Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, event := range page {
+			seen = append(seen, event.ID)
+		}
+	}
+	if len(seen) != len(ids) {
+		t.Fatalf("paged through %d events, want %d", len(seen), len(ids))
+	}
+	unique := map[uuid.UUID]bool{}
+	for _, id := range seen {
+		if unique[id] {
+			t.Fatalf("event %s returned on more than one page", id)
+		}
+		unique[id] = true
+	}
+
+	all, err := repo.FindEvents(ctx, EventSearch{NormalizedPlate: &plate, Limit: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].EventTime.After(all[i-1].EventTime) {
+			t.Fatalf("events are not sorted by event_time DESC at %d", i)
+		}
+	}
+
+	var streamed []ANPREvent
+	err = repo.StreamEvents(ctx, EventSearch{NormalizedPlate: &plate}, func(event *ANPREvent) error {
+		streamed = append(streamed, *event)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streamed) != len(ids) {
+		t.Fatalf("streamed %d events, want %d", len(streamed), len(ids))
+	}
+	for i := 1; i < len(streamed); i++ {
+		if streamed[i].EventTime.Before(streamed[i-1].EventTime) {
+			t.Fatalf("streamed events are not sorted by event_time ASC at %d", i)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Области переопределения флага возможностей
+const (
+	FeatureFlagScopeGlobal       = "global"
+	FeatureFlagScopeOrganization = "organization"
+	FeatureFlagScopeCamera       = "camera"
+)
+
+// FeatureFlagOverride — переопределение флага возможностей для всех, организации или камеры
+type FeatureFlagOverride struct {
+	ID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Flag string
+	// Scope — FeatureFlagScope*; ScopeID — id организации или камеры, для global — пусто
+	Scope   string
+	ScopeID string
+	Enabled bool
+	Reason  *string
+	// UpdatedBy — пользователь, задавший переопределение последним
+	UpdatedBy uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (FeatureFlagOverride) TableName() string {
+	return "anpr_feature_flag_overrides"
+}
+
+// ListFeatureFlagOverrides возвращает все переопределения флагов
+func (r *ANPRRepository) ListFeatureFlagOverrides(ctx context.Context) ([]FeatureFlagOverride, error) {
+	var overrides []FeatureFlagOverride
+	err := r.db.WithContext(ctx).Order("flag ASC, scope ASC, scope_id ASC").Find(&overrides).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertFeatureFlagOverride создаёт переопределение флага или меняет существующее в той же области
+func (r *ANPRRepository) UpsertFeatureFlagOverride(ctx context.Context, override *FeatureFlagOverride) error {
+	if override.ID == uuid.Nil {
+		override.ID = r.ids.NewID()
+	}
+	now := r.clock.Now()
+	if override.CreatedAt.IsZero() {
+		override.CreatedAt = now
+	}
+	override.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "flag"}, {Name: "scope"}, {Name: "scope_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "reason", "updated_by", "updated_at"}),
+		}).
+		Create(override).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert feature flag override: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeatureFlagOverride удаляет переопределение флага; false — его не было
+func (r *ANPRRepository) DeleteFeatureFlagOverride(ctx context.Context, flag, scope, scopeID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("flag = ? AND scope = ? AND scope_id = ?", flag, scope, scopeID).
+		Delete(&FeatureFlagOverride{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete feature flag override: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
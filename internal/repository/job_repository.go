@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRun — запуск периодической задачи планировщика
+type JobRun struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Job         string    `gorm:"not null"`
+	Trigger     string    `gorm:"not null"` // schedule или manual
+	Status      string    `gorm:"not null"` // running, succeeded, failed, timeout
+	Error       *string
+	StartedAt   time.Time `gorm:"not null"`
+	FinishedAt  *time.Time
+	TriggeredBy *uuid.UUID `gorm:"type:uuid"`
+}
+
+func (JobRun) TableName() string {
+	return "anpr_job_runs"
+}
+
+// CreateJobRun записывает начало запуска
+func (r *ANPRRepository) CreateJobRun(ctx context.Context, run *JobRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = r.ids.NewID()
+	}
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+	return nil
+}
+
+// FinishJobRun записывает итог запуска
+func (r *ANPRRepository) FinishJobRun(ctx context.Context, id uuid.UUID, status string, errMsg *string, finishedAt time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&JobRun{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"error":       errMsg,
+			"finished_at": finishedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+	return nil
+}
+
+// ListJobRuns возвращает последние запуски задачи, новые первыми
+func (r *ANPRRepository) ListJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error) {
+	var runs []JobRun
+	err := r.db.WithContext(ctx).
+		Where("job = ?", job).
+		Order("started_at DESC, id DESC").
+		Limit(limit).
+		Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	return runs, nil
+}
+
+// ListLatestJobRuns возвращает последний запуск каждой задачи
+func (r *ANPRRepository) ListLatestJobRuns(ctx context.Context) ([]JobRun, error) {
+	var runs []JobRun
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (job) *
+		FROM anpr_job_runs
+		ORDER BY job, started_at DESC, id DESC`).
+		Scan(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list latest job runs: %w", err)
+	}
+	return runs, nil
+}
+
+// PurgeJobRuns удаляет запуски, начатые раньше before
+func (r *ANPRRepository) PurgeJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("started_at < ?", before).Delete(&JobRun{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge job runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
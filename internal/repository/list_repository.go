@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Области версий данных (anpr_data_version_shards)
+const (
+	DataVersionScopeEvents = "events"
+	DataVersionScopeLists  = "lists"
+	DataVersionScopePlates = "plates"
+)
+
+// DataVersion — версия данных области и время её последнего изменения
+type DataVersion struct {
+	Scope     string    `gorm:"column:scope"`
+	Version   int64     `gorm:"column:version"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// ListSummary — список номеров с количеством записей
+type ListSummary struct {
+	ID          uuid.UUID `gorm:"column:id"`
+	Name        string    `gorm:"column:name"`
+	Type        string    `gorm:"column:type"`
+	Description *string   `gorm:"column:description"`
+	ItemCount   int64     `gorm:"column:item_count"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// ListEntry — номер в списке
+type ListEntry struct {
+	PlateID    uuid.UUID  `gorm:"column:plate_id"`
+	Normalized string     `gorm:"column:normalized"`
+	Number     string     `gorm:"column:number"`
+	Note       *string    `gorm:"column:note"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	ValidFrom  *time.Time `gorm:"column:valid_from"`
+	ValidUntil *time.Time `gorm:"column:valid_until"`
+}
+
+// ListLists возвращает все списки номеров с количеством записей
+func (r *ANPRRepository) ListLists(ctx context.Context) ([]ListSummary, error) {
+	var lists []ListSummary
+	err := r.db.WithContext(ctx).
+		Table("anpr_lists l").
+		Select("l.id, l.name, l.type, l.description, l.created_at, COUNT(li.plate_id) AS item_count").
+		Joins("LEFT JOIN anpr_list_items li ON li.list_id = l.id").
+		Group("l.id").
+		Order("l.name ASC").
+		Scan(&lists).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lists: %w", err)
+	}
+	return lists, nil
+}
+
+// GetList получает список по id; ErrListNotFound — списка нет
+func (r *ANPRRepository) GetList(ctx context.Context, listID uuid.UUID) (*List, error) {
+	var list List
+	err := r.db.WithContext(ctx).Where("id = ?", listID).First(&list).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrListNotFound, listID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	return &list, nil
+}
+
+// listEntryColumns — поля ListEntry из anpr_list_items li и anpr_plates p
+const listEntryColumns = "li.plate_id, p.normalized, p.number, li.note, li.created_at, li.valid_from, li.valid_until"
+
+// GetListEntries возвращает номера списка (новые записи первыми)
+func (r *ANPRRepository) GetListEntries(ctx context.Context, listID uuid.UUID, limit, offset int) ([]ListEntry, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_list_items li").
+		Select(listEntryColumns).
+		Joins("JOIN anpr_plates p ON p.id = li.plate_id").
+		Where("li.list_id = ?", listID).
+		Order("li.created_at DESC, p.normalized ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var entries []ListEntry
+	if err := query.Scan(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get list entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetListEntry возвращает запись списка. Возвращает nil, если номера в списке нет
+func (r *ANPRRepository) GetListEntry(ctx context.Context, listID, plateID uuid.UUID) (*ListEntry, error) {
+	var entries []ListEntry
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_items li").
+		Select(listEntryColumns).
+		Joins("JOIN anpr_plates p ON p.id = li.plate_id").
+		Where("li.list_id = ? AND li.plate_id = ?", listID, plateID).
+		Limit(1).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list entry: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+// UpsertListItem добавляет номер в список или, если он уже там, меняет срок действия записи
+// (и примечание, если оно передано)
+func (r *ANPRRepository) UpsertListItem(ctx context.Context, item *ListItem) error {
+	err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO anpr_list_items (list_id, plate_id, note, created_at, valid_from, valid_until)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (list_id, plate_id) DO UPDATE SET
+			note = COALESCE(EXCLUDED.note, anpr_list_items.note),
+			valid_from = EXCLUDED.valid_from,
+			valid_until = EXCLUDED.valid_until`,
+		item.ListID, item.PlateID, item.Note, r.clock.Now(), item.ValidFrom, item.ValidUntil).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert list item: %w", err)
+	}
+	return nil
+}
+
+// SetListItemValidity меняет срок действия записи списка. Возвращает false, если номера в списке нет
+func (r *ANPRRepository) SetListItemValidity(ctx context.Context, listID, plateID uuid.UUID, validFrom, validUntil *time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Table("anpr_list_items").
+		Where("list_id = ? AND plate_id = ?", listID, plateID).
+		Updates(map[string]interface{}{"valid_from": validFrom, "valid_until": validUntil})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to set list item validity: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RemoveListItem убирает номер из списка. Возвращает false, если его там не было
+func (r *ANPRRepository) RemoveListItem(ctx context.Context, listID, plateID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM anpr_list_items WHERE list_id = ? AND plate_id = ?", listID, plateID)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove list item: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteExpiredListItems удаляет записи списков, срок действия которых закончился до before
+func (r *ANPRRepository) DeleteExpiredListItems(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM anpr_list_items WHERE valid_until IS NOT NULL AND valid_until <= ?", before)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired list items: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetDataVersion возвращает текущую версию данных области (растёт при каждом изменении): сумму счётчиков
+// по соединениям, которые её меняли
+func (r *ANPRRepository) GetDataVersion(ctx context.Context, scope string) (int64, error) {
+	var version int64
+	err := r.db.WithContext(ctx).
+		Raw("SELECT COALESCE(SUM(version), 0) FROM anpr_data_version_shards WHERE scope = ?", scope).
+		Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get data version: %w", err)
+	}
+	return version, nil
+}
+
+// GetDataVersions возвращает версии областей scopes в порядке scopes; области без версии пропускаются
+func (r *ANPRRepository) GetDataVersions(ctx context.Context, scopes []string) ([]DataVersion, error) {
+	var rows []DataVersion
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT scope, SUM(version) AS version, MAX(updated_at) AS updated_at
+			FROM anpr_data_version_shards WHERE scope IN ? GROUP BY scope`, scopes).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data versions: %w", err)
+	}
+	byScope := make(map[string]DataVersion, len(rows))
+	for _, row := range rows {
+		byScope[row.Scope] = row
+	}
+	versions := make([]DataVersion, 0, len(rows))
+	for _, scope := range scopes {
+		if v, ok := byScope[scope]; ok {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,2566 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: anpr-service/internal/repository (interfaces: ANPRStore)
+//
+// Generated by this command:
+//
+//	mockgen -destination=mocks/store_mock.go -package=mocks . ANPRStore
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	anpr "anpr-service/internal/domain/anpr"
+	repository "anpr-service/internal/repository"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockANPRStore is a mock of ANPRStore interface.
+type MockANPRStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockANPRStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockANPRStoreMockRecorder is the mock recorder for MockANPRStore.
+type MockANPRStoreMockRecorder struct {
+	mock *MockANPRStore
+}
+
+// NewMockANPRStore creates a new mock instance.
+func NewMockANPRStore(ctrl *gomock.Controller) *MockANPRStore {
+	mock := &MockANPRStore{ctrl: ctrl}
+	mock.recorder = &MockANPRStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockANPRStore) EXPECT() *MockANPRStoreMockRecorder {
+	return m.recorder
+}
+
+// AddPlateToList mocks base method.
+func (m *MockANPRStore) AddPlateToList(ctx context.Context, listID, plateID uuid.UUID, note *string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlateToList", ctx, listID, plateID, note)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPlateToList indicates an expected call of AddPlateToList.
+func (mr *MockANPRStoreMockRecorder) AddPlateToList(ctx, listID, plateID, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlateToList", reflect.TypeOf((*MockANPRStore)(nil).AddPlateToList), ctx, listID, plateID, note)
+}
+
+// AddUsage mocks base method.
+func (m *MockANPRStore) AddUsage(ctx context.Context, rows []repository.UsageDaily) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUsage", ctx, rows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUsage indicates an expected call of AddUsage.
+func (mr *MockANPRStoreMockRecorder) AddUsage(ctx, rows any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUsage", reflect.TypeOf((*MockANPRStore)(nil).AddUsage), ctx, rows)
+}
+
+// ApplyWeatherToEvents mocks base method.
+func (m *MockANPRStore) ApplyWeatherToEvents(ctx context.Context, from, until time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyWeatherToEvents", ctx, from, until)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyWeatherToEvents indicates an expected call of ApplyWeatherToEvents.
+func (mr *MockANPRStoreMockRecorder) ApplyWeatherToEvents(ctx, from, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyWeatherToEvents", reflect.TypeOf((*MockANPRStore)(nil).ApplyWeatherToEvents), ctx, from, until)
+}
+
+// ClaimMQTTMessages mocks base method.
+func (m *MockANPRStore) ClaimMQTTMessages(ctx context.Context, limit int, lease time.Duration) ([]repository.MQTTMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMQTTMessages", ctx, limit, lease)
+	ret0, _ := ret[0].([]repository.MQTTMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimMQTTMessages indicates an expected call of ClaimMQTTMessages.
+func (mr *MockANPRStoreMockRecorder) ClaimMQTTMessages(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMQTTMessages", reflect.TypeOf((*MockANPRStore)(nil).ClaimMQTTMessages), ctx, limit, lease)
+}
+
+// ClaimTelegramNotifications mocks base method.
+func (m *MockANPRStore) ClaimTelegramNotifications(ctx context.Context, limit int, lease time.Duration) ([]repository.TelegramNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimTelegramNotifications", ctx, limit, lease)
+	ret0, _ := ret[0].([]repository.TelegramNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimTelegramNotifications indicates an expected call of ClaimTelegramNotifications.
+func (mr *MockANPRStoreMockRecorder) ClaimTelegramNotifications(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimTelegramNotifications", reflect.TypeOf((*MockANPRStore)(nil).ClaimTelegramNotifications), ctx, limit, lease)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockANPRStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockANPRStoreMockRecorder) ClaimWebhookDeliveries(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockANPRStore)(nil).ClaimWebhookDeliveries), ctx, limit, lease)
+}
+
+// CountAllowedEntries mocks base method.
+func (m *MockANPRStore) CountAllowedEntries(ctx context.Context, plateID uuid.UUID, from, to time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAllowedEntries", ctx, plateID, from, to)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAllowedEntries indicates an expected call of CountAllowedEntries.
+func (mr *MockANPRStoreMockRecorder) CountAllowedEntries(ctx, plateID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAllowedEntries", reflect.TypeOf((*MockANPRStore)(nil).CountAllowedEntries), ctx, plateID, from, to)
+}
+
+// CountPlateWatches mocks base method.
+func (m *MockANPRStore) CountPlateWatches(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPlateWatches", ctx, userID, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPlateWatches indicates an expected call of CountPlateWatches.
+func (mr *MockANPRStoreMockRecorder) CountPlateWatches(ctx, userID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPlateWatches", reflect.TypeOf((*MockANPRStore)(nil).CountPlateWatches), ctx, userID, now)
+}
+
+// CountReportEventsForExcel mocks base method.
+func (m *MockANPRStore) CountReportEventsForExcel(ctx context.Context, filters repository.ReportFilters) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReportEventsForExcel", ctx, filters)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReportEventsForExcel indicates an expected call of CountReportEventsForExcel.
+func (mr *MockANPRStoreMockRecorder) CountReportEventsForExcel(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReportEventsForExcel", reflect.TypeOf((*MockANPRStore)(nil).CountReportEventsForExcel), ctx, filters)
+}
+
+// CountSavedSearches mocks base method.
+func (m *MockANPRStore) CountSavedSearches(ctx context.Context, userID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSavedSearches", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSavedSearches indicates an expected call of CountSavedSearches.
+func (mr *MockANPRStoreMockRecorder) CountSavedSearches(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSavedSearches", reflect.TypeOf((*MockANPRStore)(nil).CountSavedSearches), ctx, userID)
+}
+
+// CreateDeadLetter mocks base method.
+func (m *MockANPRStore) CreateDeadLetter(ctx context.Context, letter *repository.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeadLetter", ctx, letter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeadLetter indicates an expected call of CreateDeadLetter.
+func (mr *MockANPRStoreMockRecorder) CreateDeadLetter(ctx, letter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeadLetter", reflect.TypeOf((*MockANPRStore)(nil).CreateDeadLetter), ctx, letter)
+}
+
+// CreateEventComment mocks base method.
+func (m *MockANPRStore) CreateEventComment(ctx context.Context, comment *repository.EventComment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEventComment", ctx, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEventComment indicates an expected call of CreateEventComment.
+func (mr *MockANPRStoreMockRecorder) CreateEventComment(ctx, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEventComment", reflect.TypeOf((*MockANPRStore)(nil).CreateEventComment), ctx, comment)
+}
+
+// CreateJobRun mocks base method.
+func (m *MockANPRStore) CreateJobRun(ctx context.Context, run *repository.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJobRun indicates an expected call of CreateJobRun.
+func (mr *MockANPRStoreMockRecorder) CreateJobRun(ctx, run any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobRun", reflect.TypeOf((*MockANPRStore)(nil).CreateJobRun), ctx, run)
+}
+
+// CreatePhotoHash mocks base method.
+func (m *MockANPRStore) CreatePhotoHash(ctx context.Context, hash *repository.PhotoHash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePhotoHash", ctx, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePhotoHash indicates an expected call of CreatePhotoHash.
+func (mr *MockANPRStoreMockRecorder) CreatePhotoHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePhotoHash", reflect.TypeOf((*MockANPRStore)(nil).CreatePhotoHash), ctx, hash)
+}
+
+// CreatePlateAlias mocks base method.
+func (m *MockANPRStore) CreatePlateAlias(ctx context.Context, alias *repository.PlateAlias) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlateAlias", ctx, alias)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePlateAlias indicates an expected call of CreatePlateAlias.
+func (mr *MockANPRStoreMockRecorder) CreatePlateAlias(ctx, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlateAlias", reflect.TypeOf((*MockANPRStore)(nil).CreatePlateAlias), ctx, alias)
+}
+
+// CreatePlateWatch mocks base method.
+func (m *MockANPRStore) CreatePlateWatch(ctx context.Context, watch *repository.PlateWatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlateWatch", ctx, watch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePlateWatch indicates an expected call of CreatePlateWatch.
+func (mr *MockANPRStoreMockRecorder) CreatePlateWatch(ctx, watch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlateWatch", reflect.TypeOf((*MockANPRStore)(nil).CreatePlateWatch), ctx, watch)
+}
+
+// CreatePolygon mocks base method.
+func (m *MockANPRStore) CreatePolygon(ctx context.Context, polygon *repository.Polygon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolygon", ctx, polygon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePolygon indicates an expected call of CreatePolygon.
+func (mr *MockANPRStoreMockRecorder) CreatePolygon(ctx, polygon any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolygon", reflect.TypeOf((*MockANPRStore)(nil).CreatePolygon), ctx, polygon)
+}
+
+// CreatePolygonModeChange mocks base method.
+func (m *MockANPRStore) CreatePolygonModeChange(ctx context.Context, change *repository.PolygonModeChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePolygonModeChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePolygonModeChange indicates an expected call of CreatePolygonModeChange.
+func (mr *MockANPRStoreMockRecorder) CreatePolygonModeChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePolygonModeChange", reflect.TypeOf((*MockANPRStore)(nil).CreatePolygonModeChange), ctx, change)
+}
+
+// CreateRejectedEvent mocks base method.
+func (m *MockANPRStore) CreateRejectedEvent(ctx context.Context, eventID uuid.UUID, plateID *uuid.UUID, reason, normalizedPlate, rawPlate, cameraID string, eventTime time.Time, payload *anpr.EventPayload, photoURLs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRejectedEvent", ctx, eventID, plateID, reason, normalizedPlate, rawPlate, cameraID, eventTime, payload, photoURLs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRejectedEvent indicates an expected call of CreateRejectedEvent.
+func (mr *MockANPRStoreMockRecorder) CreateRejectedEvent(ctx, eventID, plateID, reason, normalizedPlate, rawPlate, cameraID, eventTime, payload, photoURLs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRejectedEvent", reflect.TypeOf((*MockANPRStore)(nil).CreateRejectedEvent), ctx, eventID, plateID, reason, normalizedPlate, rawPlate, cameraID, eventTime, payload, photoURLs)
+}
+
+// CreateSavedSearch mocks base method.
+func (m *MockANPRStore) CreateSavedSearch(ctx context.Context, search *repository.SavedSearch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSavedSearch indicates an expected call of CreateSavedSearch.
+func (mr *MockANPRStoreMockRecorder) CreateSavedSearch(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavedSearch", reflect.TypeOf((*MockANPRStore)(nil).CreateSavedSearch), ctx, search)
+}
+
+// CreateShift mocks base method.
+func (m *MockANPRStore) CreateShift(ctx context.Context, shift *repository.Shift) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShift", ctx, shift)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateShift indicates an expected call of CreateShift.
+func (mr *MockANPRStoreMockRecorder) CreateShift(ctx, shift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShift", reflect.TypeOf((*MockANPRStore)(nil).CreateShift), ctx, shift)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockANPRStore) CreateWebhookSubscription(ctx context.Context, sub *repository.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockANPRStoreMockRecorder) CreateWebhookSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockANPRStore)(nil).CreateWebhookSubscription), ctx, sub)
+}
+
+// DeleteExpiredListItems mocks base method.
+func (m *MockANPRStore) DeleteExpiredListItems(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredListItems", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredListItems indicates an expected call of DeleteExpiredListItems.
+func (mr *MockANPRStoreMockRecorder) DeleteExpiredListItems(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredListItems", reflect.TypeOf((*MockANPRStore)(nil).DeleteExpiredListItems), ctx, before)
+}
+
+// DeleteFeatureFlagOverride mocks base method.
+func (m *MockANPRStore) DeleteFeatureFlagOverride(ctx context.Context, flag, scope, scopeID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeatureFlagOverride", ctx, flag, scope, scopeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeatureFlagOverride indicates an expected call of DeleteFeatureFlagOverride.
+func (mr *MockANPRStoreMockRecorder) DeleteFeatureFlagOverride(ctx, flag, scope, scopeID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeatureFlagOverride", reflect.TypeOf((*MockANPRStore)(nil).DeleteFeatureFlagOverride), ctx, flag, scope, scopeID)
+}
+
+// DeleteOldEvents mocks base method.
+func (m *MockANPRStore) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldEvents", ctx, days)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOldEvents indicates an expected call of DeleteOldEvents.
+func (mr *MockANPRStoreMockRecorder) DeleteOldEvents(ctx, days any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldEvents", reflect.TypeOf((*MockANPRStore)(nil).DeleteOldEvents), ctx, days)
+}
+
+// DeletePlateAlias mocks base method.
+func (m *MockANPRStore) DeletePlateAlias(ctx context.Context, plateID uuid.UUID, alias string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlateAlias", ctx, plateID, alias)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePlateAlias indicates an expected call of DeletePlateAlias.
+func (mr *MockANPRStoreMockRecorder) DeletePlateAlias(ctx, plateID, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlateAlias", reflect.TypeOf((*MockANPRStore)(nil).DeletePlateAlias), ctx, plateID, alias)
+}
+
+// DeletePlateTripQuota mocks base method.
+func (m *MockANPRStore) DeletePlateTripQuota(ctx context.Context, normalized string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlateTripQuota", ctx, normalized)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePlateTripQuota indicates an expected call of DeletePlateTripQuota.
+func (mr *MockANPRStoreMockRecorder) DeletePlateTripQuota(ctx, normalized any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlateTripQuota", reflect.TypeOf((*MockANPRStore)(nil).DeletePlateTripQuota), ctx, normalized)
+}
+
+// DeletePlateWatch mocks base method.
+func (m *MockANPRStore) DeletePlateWatch(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlateWatch", ctx, id, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePlateWatch indicates an expected call of DeletePlateWatch.
+func (mr *MockANPRStoreMockRecorder) DeletePlateWatch(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlateWatch", reflect.TypeOf((*MockANPRStore)(nil).DeletePlateWatch), ctx, id, userID)
+}
+
+// DeletePolygon mocks base method.
+func (m *MockANPRStore) DeletePolygon(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePolygon", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePolygon indicates an expected call of DeletePolygon.
+func (mr *MockANPRStoreMockRecorder) DeletePolygon(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePolygon", reflect.TypeOf((*MockANPRStore)(nil).DeletePolygon), ctx, id)
+}
+
+// DeleteSavedSearch mocks base method.
+func (m *MockANPRStore) DeleteSavedSearch(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedSearch", ctx, id, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSavedSearch indicates an expected call of DeleteSavedSearch.
+func (mr *MockANPRStoreMockRecorder) DeleteSavedSearch(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedSearch", reflect.TypeOf((*MockANPRStore)(nil).DeleteSavedSearch), ctx, id, userID)
+}
+
+// DeleteShift mocks base method.
+func (m *MockANPRStore) DeleteShift(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShift", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteShift indicates an expected call of DeleteShift.
+func (mr *MockANPRStoreMockRecorder) DeleteShift(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShift", reflect.TypeOf((*MockANPRStore)(nil).DeleteShift), ctx, id)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockANPRStore) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockANPRStoreMockRecorder) DeleteWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockANPRStore)(nil).DeleteWebhookSubscription), ctx, id)
+}
+
+// EnqueueMQTTMessage mocks base method.
+func (m *MockANPRStore) EnqueueMQTTMessage(ctx context.Context, msg *repository.MQTTMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueMQTTMessage", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueMQTTMessage indicates an expected call of EnqueueMQTTMessage.
+func (mr *MockANPRStoreMockRecorder) EnqueueMQTTMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueMQTTMessage", reflect.TypeOf((*MockANPRStore)(nil).EnqueueMQTTMessage), ctx, msg)
+}
+
+// EnqueueTelegramNotifications mocks base method.
+func (m *MockANPRStore) EnqueueTelegramNotifications(ctx context.Context, notifications []repository.TelegramNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueTelegramNotifications", ctx, notifications)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueTelegramNotifications indicates an expected call of EnqueueTelegramNotifications.
+func (mr *MockANPRStoreMockRecorder) EnqueueTelegramNotifications(ctx, notifications any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTelegramNotifications", reflect.TypeOf((*MockANPRStore)(nil).EnqueueTelegramNotifications), ctx, notifications)
+}
+
+// EnqueueWebhookDeliveries mocks base method.
+func (m *MockANPRStore) EnqueueWebhookDeliveries(ctx context.Context, deliveries []repository.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueWebhookDeliveries indicates an expected call of EnqueueWebhookDeliveries.
+func (mr *MockANPRStoreMockRecorder) EnqueueWebhookDeliveries(ctx, deliveries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookDeliveries", reflect.TypeOf((*MockANPRStore)(nil).EnqueueWebhookDeliveries), ctx, deliveries)
+}
+
+// EnsureEventPartitions mocks base method.
+func (m *MockANPRStore) EnsureEventPartitions(ctx context.Context, from, to time.Time, daily bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEventPartitions", ctx, from, to, daily)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEventPartitions indicates an expected call of EnsureEventPartitions.
+func (mr *MockANPRStoreMockRecorder) EnsureEventPartitions(ctx, from, to, daily any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEventPartitions", reflect.TypeOf((*MockANPRStore)(nil).EnsureEventPartitions), ctx, from, to, daily)
+}
+
+// ExistsRecentEvent mocks base method.
+func (m *MockANPRStore) ExistsRecentEvent(ctx context.Context, normalizedPlate, cameraID string, eventTime time.Time, window time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsRecentEvent", ctx, normalizedPlate, cameraID, eventTime, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsRecentEvent indicates an expected call of ExistsRecentEvent.
+func (mr *MockANPRStoreMockRecorder) ExistsRecentEvent(ctx, normalizedPlate, cameraID, eventTime, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsRecentEvent", reflect.TypeOf((*MockANPRStore)(nil).ExistsRecentEvent), ctx, normalizedPlate, cameraID, eventTime, window)
+}
+
+// FindEvents mocks base method.
+func (m *MockANPRStore) FindEvents(ctx context.Context, search repository.EventSearch) ([]repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEvents", ctx, search)
+	ret0, _ := ret[0].([]repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEvents indicates an expected call of FindEvents.
+func (mr *MockANPRStoreMockRecorder) FindEvents(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEvents", reflect.TypeOf((*MockANPRStore)(nil).FindEvents), ctx, search)
+}
+
+// FindEventsByPlateAndTime mocks base method.
+func (m *MockANPRStore) FindEventsByPlateAndTime(ctx context.Context, normalizedPlate string, from, to time.Time, direction *string) ([]repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEventsByPlateAndTime", ctx, normalizedPlate, from, to, direction)
+	ret0, _ := ret[0].([]repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEventsByPlateAndTime indicates an expected call of FindEventsByPlateAndTime.
+func (mr *MockANPRStoreMockRecorder) FindEventsByPlateAndTime(ctx, normalizedPlate, from, to, direction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEventsByPlateAndTime", reflect.TypeOf((*MockANPRStore)(nil).FindEventsByPlateAndTime), ctx, normalizedPlate, from, to, direction)
+}
+
+// FindListsForPlate mocks base method.
+func (m *MockANPRStore) FindListsForPlate(ctx context.Context, plateID uuid.UUID) ([]anpr.ListHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindListsForPlate", ctx, plateID)
+	ret0, _ := ret[0].([]anpr.ListHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindListsForPlate indicates an expected call of FindListsForPlate.
+func (mr *MockANPRStoreMockRecorder) FindListsForPlate(ctx, plateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindListsForPlate", reflect.TypeOf((*MockANPRStore)(nil).FindListsForPlate), ctx, plateID)
+}
+
+// FindPhotoHashCandidates mocks base method.
+func (m *MockANPRStore) FindPhotoHashCandidates(ctx context.Context, sha256 string, phash *int64, excludeEventID uuid.UUID) ([]repository.PhotoHash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPhotoHashCandidates", ctx, sha256, phash, excludeEventID)
+	ret0, _ := ret[0].([]repository.PhotoHash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPhotoHashCandidates indicates an expected call of FindPhotoHashCandidates.
+func (mr *MockANPRStoreMockRecorder) FindPhotoHashCandidates(ctx, sha256, phash, excludeEventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPhotoHashCandidates", reflect.TypeOf((*MockANPRStore)(nil).FindPhotoHashCandidates), ctx, sha256, phash, excludeEventID)
+}
+
+// FindPlateEvents mocks base method.
+func (m *MockANPRStore) FindPlateEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPlateEvents", ctx, plateID, from, to, limit)
+	ret0, _ := ret[0].([]repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPlateEvents indicates an expected call of FindPlateEvents.
+func (mr *MockANPRStoreMockRecorder) FindPlateEvents(ctx, plateID, from, to, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPlateEvents", reflect.TypeOf((*MockANPRStore)(nil).FindPlateEvents), ctx, plateID, from, to, limit)
+}
+
+// FindPlateRejectedEvents mocks base method.
+func (m *MockANPRStore) FindPlateRejectedEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]repository.RejectedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPlateRejectedEvents", ctx, plateID, from, to, limit)
+	ret0, _ := ret[0].([]repository.RejectedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPlateRejectedEvents indicates an expected call of FindPlateRejectedEvents.
+func (mr *MockANPRStoreMockRecorder) FindPlateRejectedEvents(ctx, plateID, from, to, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPlateRejectedEvents", reflect.TypeOf((*MockANPRStore)(nil).FindPlateRejectedEvents), ctx, plateID, from, to, limit)
+}
+
+// FindPlatesByNormalized mocks base method.
+func (m *MockANPRStore) FindPlatesByNormalized(ctx context.Context, normalized string) ([]repository.Plate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPlatesByNormalized", ctx, normalized)
+	ret0, _ := ret[0].([]repository.Plate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPlatesByNormalized indicates an expected call of FindPlatesByNormalized.
+func (mr *MockANPRStoreMockRecorder) FindPlatesByNormalized(ctx, normalized any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPlatesByNormalized", reflect.TypeOf((*MockANPRStore)(nil).FindPlatesByNormalized), ctx, normalized)
+}
+
+// FindPolygonIDs mocks base method.
+func (m *MockANPRStore) FindPolygonIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPolygonIDs", ctx, ids)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPolygonIDs indicates an expected call of FindPolygonIDs.
+func (mr *MockANPRStoreMockRecorder) FindPolygonIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPolygonIDs", reflect.TypeOf((*MockANPRStore)(nil).FindPolygonIDs), ctx, ids)
+}
+
+// FinishJobRun mocks base method.
+func (m *MockANPRStore) FinishJobRun(ctx context.Context, id uuid.UUID, status string, errMsg *string, finishedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJobRun", ctx, id, status, errMsg, finishedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJobRun indicates an expected call of FinishJobRun.
+func (mr *MockANPRStoreMockRecorder) FinishJobRun(ctx, id, status, errMsg, finishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJobRun", reflect.TypeOf((*MockANPRStore)(nil).FinishJobRun), ctx, id, status, errMsg, finishedAt)
+}
+
+// FinishSavedSearchRun mocks base method.
+func (m *MockANPRStore) FinishSavedSearchRun(ctx context.Context, id uuid.UUID, scheduledAt, ranAt, nextRunAt time.Time, runErr *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishSavedSearchRun", ctx, id, scheduledAt, ranAt, nextRunAt, runErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishSavedSearchRun indicates an expected call of FinishSavedSearchRun.
+func (mr *MockANPRStoreMockRecorder) FinishSavedSearchRun(ctx, id, scheduledAt, ranAt, nextRunAt, runErr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishSavedSearchRun", reflect.TypeOf((*MockANPRStore)(nil).FinishSavedSearchRun), ctx, id, scheduledAt, ranAt, nextRunAt, runErr)
+}
+
+// GetBillingLines mocks base method.
+func (m *MockANPRStore) GetBillingLines(ctx context.Context, from, to time.Time) ([]repository.BillingLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBillingLines", ctx, from, to)
+	ret0, _ := ret[0].([]repository.BillingLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBillingLines indicates an expected call of GetBillingLines.
+func (mr *MockANPRStoreMockRecorder) GetBillingLines(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillingLines", reflect.TypeOf((*MockANPRStore)(nil).GetBillingLines), ctx, from, to)
+}
+
+// GetBillingPeriod mocks base method.
+func (m *MockANPRStore) GetBillingPeriod(ctx context.Context, period string) (*repository.BillingPeriod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBillingPeriod", ctx, period)
+	ret0, _ := ret[0].(*repository.BillingPeriod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBillingPeriod indicates an expected call of GetBillingPeriod.
+func (mr *MockANPRStoreMockRecorder) GetBillingPeriod(ctx, period any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillingPeriod", reflect.TypeOf((*MockANPRStore)(nil).GetBillingPeriod), ctx, period)
+}
+
+// GetCamera mocks base method.
+func (m *MockANPRStore) GetCamera(ctx context.Context, cameraID string) (*repository.Camera, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCamera", ctx, cameraID)
+	ret0, _ := ret[0].(*repository.Camera)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCamera indicates an expected call of GetCamera.
+func (mr *MockANPRStoreMockRecorder) GetCamera(ctx, cameraID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCamera", reflect.TypeOf((*MockANPRStore)(nil).GetCamera), ctx, cameraID)
+}
+
+// GetCameraSpeedStats mocks base method.
+func (m *MockANPRStore) GetCameraSpeedStats(ctx context.Context, filters repository.TrafficFilters) ([]repository.CameraSpeedStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCameraSpeedStats", ctx, filters)
+	ret0, _ := ret[0].([]repository.CameraSpeedStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCameraSpeedStats indicates an expected call of GetCameraSpeedStats.
+func (mr *MockANPRStoreMockRecorder) GetCameraSpeedStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCameraSpeedStats", reflect.TypeOf((*MockANPRStore)(nil).GetCameraSpeedStats), ctx, filters)
+}
+
+// GetConfidenceStats mocks base method.
+func (m *MockANPRStore) GetConfidenceStats(ctx context.Context, filters repository.TrafficFilters) ([]repository.ConfidenceStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfidenceStats", ctx, filters)
+	ret0, _ := ret[0].([]repository.ConfidenceStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfidenceStats indicates an expected call of GetConfidenceStats.
+func (mr *MockANPRStoreMockRecorder) GetConfidenceStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfidenceStats", reflect.TypeOf((*MockANPRStore)(nil).GetConfidenceStats), ctx, filters)
+}
+
+// GetContractorAccessRule mocks base method.
+func (m *MockANPRStore) GetContractorAccessRule(ctx context.Context, contractorID uuid.UUID) (*repository.ContractorAccessRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractorAccessRule", ctx, contractorID)
+	ret0, _ := ret[0].(*repository.ContractorAccessRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractorAccessRule indicates an expected call of GetContractorAccessRule.
+func (mr *MockANPRStoreMockRecorder) GetContractorAccessRule(ctx, contractorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractorAccessRule", reflect.TypeOf((*MockANPRStore)(nil).GetContractorAccessRule), ctx, contractorID)
+}
+
+// GetContractorByVehiclePlate mocks base method.
+func (m *MockANPRStore) GetContractorByVehiclePlate(ctx context.Context, normalizedPlate string) (*repository.ContractorData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractorByVehiclePlate", ctx, normalizedPlate)
+	ret0, _ := ret[0].(*repository.ContractorData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractorByVehiclePlate indicates an expected call of GetContractorByVehiclePlate.
+func (mr *MockANPRStoreMockRecorder) GetContractorByVehiclePlate(ctx, normalizedPlate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractorByVehiclePlate", reflect.TypeOf((*MockANPRStore)(nil).GetContractorByVehiclePlate), ctx, normalizedPlate)
+}
+
+// GetContractorPolygonIDs mocks base method.
+func (m *MockANPRStore) GetContractorPolygonIDs(ctx context.Context, contractorID uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractorPolygonIDs", ctx, contractorID)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractorPolygonIDs indicates an expected call of GetContractorPolygonIDs.
+func (mr *MockANPRStoreMockRecorder) GetContractorPolygonIDs(ctx, contractorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractorPolygonIDs", reflect.TypeOf((*MockANPRStore)(nil).GetContractorPolygonIDs), ctx, contractorID)
+}
+
+// GetContractorUnmatchedPlates mocks base method.
+func (m *MockANPRStore) GetContractorUnmatchedPlates(ctx context.Context, contractorID uuid.UUID, from, to time.Time, limit int) ([]repository.PlateCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractorUnmatchedPlates", ctx, contractorID, from, to, limit)
+	ret0, _ := ret[0].([]repository.PlateCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractorUnmatchedPlates indicates an expected call of GetContractorUnmatchedPlates.
+func (mr *MockANPRStoreMockRecorder) GetContractorUnmatchedPlates(ctx, contractorID, from, to, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractorUnmatchedPlates", reflect.TypeOf((*MockANPRStore)(nil).GetContractorUnmatchedPlates), ctx, contractorID, from, to, limit)
+}
+
+// GetDataVersion mocks base method.
+func (m *MockANPRStore) GetDataVersion(ctx context.Context, scope string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataVersion", ctx, scope)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataVersion indicates an expected call of GetDataVersion.
+func (mr *MockANPRStoreMockRecorder) GetDataVersion(ctx, scope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataVersion", reflect.TypeOf((*MockANPRStore)(nil).GetDataVersion), ctx, scope)
+}
+
+// GetDataVersions mocks base method.
+func (m *MockANPRStore) GetDataVersions(ctx context.Context, scopes []string) ([]repository.DataVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataVersions", ctx, scopes)
+	ret0, _ := ret[0].([]repository.DataVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataVersions indicates an expected call of GetDataVersions.
+func (mr *MockANPRStoreMockRecorder) GetDataVersions(ctx, scopes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataVersions", reflect.TypeOf((*MockANPRStore)(nil).GetDataVersions), ctx, scopes)
+}
+
+// GetDatabaseSize mocks base method.
+func (m *MockANPRStore) GetDatabaseSize(ctx context.Context) (*repository.DatabaseSize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDatabaseSize", ctx)
+	ret0, _ := ret[0].(*repository.DatabaseSize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDatabaseSize indicates an expected call of GetDatabaseSize.
+func (mr *MockANPRStoreMockRecorder) GetDatabaseSize(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDatabaseSize", reflect.TypeOf((*MockANPRStore)(nil).GetDatabaseSize), ctx)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockANPRStore) GetDeadLetter(ctx context.Context, id uuid.UUID) (*repository.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(*repository.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockANPRStoreMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockANPRStore)(nil).GetDeadLetter), ctx, id)
+}
+
+// GetDriverByVehiclePlate mocks base method.
+func (m *MockANPRStore) GetDriverByVehiclePlate(ctx context.Context, normalizedPlate string) (*repository.DriverData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverByVehiclePlate", ctx, normalizedPlate)
+	ret0, _ := ret[0].(*repository.DriverData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverByVehiclePlate indicates an expected call of GetDriverByVehiclePlate.
+func (mr *MockANPRStoreMockRecorder) GetDriverByVehiclePlate(ctx, normalizedPlate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverByVehiclePlate", reflect.TypeOf((*MockANPRStore)(nil).GetDriverByVehiclePlate), ctx, normalizedPlate)
+}
+
+// GetEventByID mocks base method.
+func (m *MockANPRStore) GetEventByID(ctx context.Context, eventID uuid.UUID) (*repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventByID", ctx, eventID)
+	ret0, _ := ret[0].(*repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventByID indicates an expected call of GetEventByID.
+func (mr *MockANPRStoreMockRecorder) GetEventByID(ctx, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventByID", reflect.TypeOf((*MockANPRStore)(nil).GetEventByID), ctx, eventID)
+}
+
+// GetEventPhotos mocks base method.
+func (m *MockANPRStore) GetEventPhotos(ctx context.Context, eventID uuid.UUID) ([]repository.EventPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventPhotos", ctx, eventID)
+	ret0, _ := ret[0].([]repository.EventPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventPhotos indicates an expected call of GetEventPhotos.
+func (mr *MockANPRStoreMockRecorder) GetEventPhotos(ctx, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventPhotos", reflect.TypeOf((*MockANPRStore)(nil).GetEventPhotos), ctx, eventID)
+}
+
+// GetHourlyActivityStats mocks base method.
+func (m *MockANPRStore) GetHourlyActivityStats(ctx context.Context, filters repository.ReportFilters) ([]repository.HourlyActivityStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyActivityStats", ctx, filters)
+	ret0, _ := ret[0].([]repository.HourlyActivityStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyActivityStats indicates an expected call of GetHourlyActivityStats.
+func (mr *MockANPRStoreMockRecorder) GetHourlyActivityStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyActivityStats", reflect.TypeOf((*MockANPRStore)(nil).GetHourlyActivityStats), ctx, filters)
+}
+
+// GetHourlyEventCounts mocks base method.
+func (m *MockANPRStore) GetHourlyEventCounts(ctx context.Context, filters repository.TrafficFilters) ([]repository.HourlyEventCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyEventCounts", ctx, filters)
+	ret0, _ := ret[0].([]repository.HourlyEventCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyEventCounts indicates an expected call of GetHourlyEventCounts.
+func (mr *MockANPRStoreMockRecorder) GetHourlyEventCounts(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyEventCounts", reflect.TypeOf((*MockANPRStore)(nil).GetHourlyEventCounts), ctx, filters)
+}
+
+// GetHourlyEventCountsRefreshedAt mocks base method.
+func (m *MockANPRStore) GetHourlyEventCountsRefreshedAt(ctx context.Context) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyEventCountsRefreshedAt", ctx)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyEventCountsRefreshedAt indicates an expected call of GetHourlyEventCountsRefreshedAt.
+func (mr *MockANPRStoreMockRecorder) GetHourlyEventCountsRefreshedAt(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyEventCountsRefreshedAt", reflect.TypeOf((*MockANPRStore)(nil).GetHourlyEventCountsRefreshedAt), ctx)
+}
+
+// GetIngestLatencyStats mocks base method.
+func (m *MockANPRStore) GetIngestLatencyStats(ctx context.Context, since time.Time, target time.Duration) ([]repository.IngestLatencyStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngestLatencyStats", ctx, since, target)
+	ret0, _ := ret[0].([]repository.IngestLatencyStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIngestLatencyStats indicates an expected call of GetIngestLatencyStats.
+func (mr *MockANPRStoreMockRecorder) GetIngestLatencyStats(ctx, since, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngestLatencyStats", reflect.TypeOf((*MockANPRStore)(nil).GetIngestLatencyStats), ctx, since, target)
+}
+
+// GetLaneUsageStats mocks base method.
+func (m *MockANPRStore) GetLaneUsageStats(ctx context.Context, filters repository.TrafficFilters, interval, timezone string) ([]repository.LaneUsageStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaneUsageStats", ctx, filters, interval, timezone)
+	ret0, _ := ret[0].([]repository.LaneUsageStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLaneUsageStats indicates an expected call of GetLaneUsageStats.
+func (mr *MockANPRStoreMockRecorder) GetLaneUsageStats(ctx, filters, interval, timezone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaneUsageStats", reflect.TypeOf((*MockANPRStore)(nil).GetLaneUsageStats), ctx, filters, interval, timezone)
+}
+
+// GetLastEventTimes mocks base method.
+func (m *MockANPRStore) GetLastEventTimes(ctx context.Context, plateIDs []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastEventTimes", ctx, plateIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastEventTimes indicates an expected call of GetLastEventTimes.
+func (mr *MockANPRStoreMockRecorder) GetLastEventTimes(ctx, plateIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEventTimes", reflect.TypeOf((*MockANPRStore)(nil).GetLastEventTimes), ctx, plateIDs)
+}
+
+// GetList mocks base method.
+func (m *MockANPRStore) GetList(ctx context.Context, listID uuid.UUID) (*repository.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx, listID)
+	ret0, _ := ret[0].(*repository.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockANPRStoreMockRecorder) GetList(ctx, listID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockANPRStore)(nil).GetList), ctx, listID)
+}
+
+// GetListByName mocks base method.
+func (m *MockANPRStore) GetListByName(ctx context.Context, name string) (*repository.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListByName", ctx, name)
+	ret0, _ := ret[0].(*repository.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListByName indicates an expected call of GetListByName.
+func (mr *MockANPRStoreMockRecorder) GetListByName(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByName", reflect.TypeOf((*MockANPRStore)(nil).GetListByName), ctx, name)
+}
+
+// GetListEntries mocks base method.
+func (m *MockANPRStore) GetListEntries(ctx context.Context, listID uuid.UUID, limit, offset int) ([]repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListEntries", ctx, listID, limit, offset)
+	ret0, _ := ret[0].([]repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListEntries indicates an expected call of GetListEntries.
+func (mr *MockANPRStoreMockRecorder) GetListEntries(ctx, listID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListEntries", reflect.TypeOf((*MockANPRStore)(nil).GetListEntries), ctx, listID, limit, offset)
+}
+
+// GetListEntry mocks base method.
+func (m *MockANPRStore) GetListEntry(ctx context.Context, listID, plateID uuid.UUID) (*repository.ListEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListEntry", ctx, listID, plateID)
+	ret0, _ := ret[0].(*repository.ListEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListEntry indicates an expected call of GetListEntry.
+func (mr *MockANPRStoreMockRecorder) GetListEntry(ctx, listID, plateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListEntry", reflect.TypeOf((*MockANPRStore)(nil).GetListEntry), ctx, listID, plateID)
+}
+
+// GetOldestEventTime mocks base method.
+func (m *MockANPRStore) GetOldestEventTime(ctx context.Context) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOldestEventTime", ctx)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOldestEventTime indicates an expected call of GetOldestEventTime.
+func (mr *MockANPRStoreMockRecorder) GetOldestEventTime(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOldestEventTime", reflect.TypeOf((*MockANPRStore)(nil).GetOldestEventTime), ctx)
+}
+
+// GetOrCreatePlate mocks base method.
+func (m *MockANPRStore) GetOrCreatePlate(ctx context.Context, normalized, original, country, region string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrCreatePlate", ctx, normalized, original, country, region)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrCreatePlate indicates an expected call of GetOrCreatePlate.
+func (mr *MockANPRStoreMockRecorder) GetOrCreatePlate(ctx, normalized, original, country, region any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreatePlate", reflect.TypeOf((*MockANPRStore)(nil).GetOrCreatePlate), ctx, normalized, original, country, region)
+}
+
+// GetPlateByID mocks base method.
+func (m *MockANPRStore) GetPlateByID(ctx context.Context, plateID uuid.UUID) (*repository.Plate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlateByID", ctx, plateID)
+	ret0, _ := ret[0].(*repository.Plate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlateByID indicates an expected call of GetPlateByID.
+func (mr *MockANPRStoreMockRecorder) GetPlateByID(ctx, plateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlateByID", reflect.TypeOf((*MockANPRStore)(nil).GetPlateByID), ctx, plateID)
+}
+
+// GetPlateListHistory mocks base method.
+func (m *MockANPRStore) GetPlateListHistory(ctx context.Context, plateID uuid.UUID, from, to time.Time) ([]repository.ListHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlateListHistory", ctx, plateID, from, to)
+	ret0, _ := ret[0].([]repository.ListHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlateListHistory indicates an expected call of GetPlateListHistory.
+func (mr *MockANPRStoreMockRecorder) GetPlateListHistory(ctx, plateID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlateListHistory", reflect.TypeOf((*MockANPRStore)(nil).GetPlateListHistory), ctx, plateID, from, to)
+}
+
+// GetPlateTripQuota mocks base method.
+func (m *MockANPRStore) GetPlateTripQuota(ctx context.Context, normalized string) (*repository.PlateTripQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlateTripQuota", ctx, normalized)
+	ret0, _ := ret[0].(*repository.PlateTripQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlateTripQuota indicates an expected call of GetPlateTripQuota.
+func (mr *MockANPRStoreMockRecorder) GetPlateTripQuota(ctx, normalized any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlateTripQuota", reflect.TypeOf((*MockANPRStore)(nil).GetPlateTripQuota), ctx, normalized)
+}
+
+// GetPolygon mocks base method.
+func (m *MockANPRStore) GetPolygon(ctx context.Context, id uuid.UUID) (*repository.Polygon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolygon", ctx, id)
+	ret0, _ := ret[0].(*repository.Polygon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolygon indicates an expected call of GetPolygon.
+func (mr *MockANPRStoreMockRecorder) GetPolygon(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolygon", reflect.TypeOf((*MockANPRStore)(nil).GetPolygon), ctx, id)
+}
+
+// GetPolygonByName mocks base method.
+func (m *MockANPRStore) GetPolygonByName(ctx context.Context, name string) (*repository.Polygon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolygonByName", ctx, name)
+	ret0, _ := ret[0].(*repository.Polygon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolygonByName indicates an expected call of GetPolygonByName.
+func (mr *MockANPRStoreMockRecorder) GetPolygonByName(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolygonByName", reflect.TypeOf((*MockANPRStore)(nil).GetPolygonByName), ctx, name)
+}
+
+// GetPolygonMode mocks base method.
+func (m *MockANPRStore) GetPolygonMode(ctx context.Context, polygonID uuid.UUID, at time.Time) (*repository.PolygonModeChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolygonMode", ctx, polygonID, at)
+	ret0, _ := ret[0].(*repository.PolygonModeChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolygonMode indicates an expected call of GetPolygonMode.
+func (mr *MockANPRStoreMockRecorder) GetPolygonMode(ctx, polygonID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolygonMode", reflect.TypeOf((*MockANPRStore)(nil).GetPolygonMode), ctx, polygonID, at)
+}
+
+// GetRejectedPhotoSamples mocks base method.
+func (m *MockANPRStore) GetRejectedPhotoSamples(ctx context.Context, plateIDs []uuid.UUID, from, to time.Time, perPlate int) ([]repository.RejectedPhotos, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRejectedPhotoSamples", ctx, plateIDs, from, to, perPlate)
+	ret0, _ := ret[0].([]repository.RejectedPhotos)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRejectedPhotoSamples indicates an expected call of GetRejectedPhotoSamples.
+func (mr *MockANPRStoreMockRecorder) GetRejectedPhotoSamples(ctx, plateIDs, from, to, perPlate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRejectedPhotoSamples", reflect.TypeOf((*MockANPRStore)(nil).GetRejectedPhotoSamples), ctx, plateIDs, from, to, perPlate)
+}
+
+// GetReportEvents mocks base method.
+func (m *MockANPRStore) GetReportEvents(ctx context.Context, filters repository.ReportFilters) ([]repository.ReportEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportEvents", ctx, filters)
+	ret0, _ := ret[0].([]repository.ReportEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportEvents indicates an expected call of GetReportEvents.
+func (mr *MockANPRStoreMockRecorder) GetReportEvents(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportEvents", reflect.TypeOf((*MockANPRStore)(nil).GetReportEvents), ctx, filters)
+}
+
+// GetReportEventsForExcel mocks base method.
+func (m *MockANPRStore) GetReportEventsForExcel(ctx context.Context, filters repository.ReportFilters, pageSize, offset int) ([]repository.ReportEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportEventsForExcel", ctx, filters, pageSize, offset)
+	ret0, _ := ret[0].([]repository.ReportEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportEventsForExcel indicates an expected call of GetReportEventsForExcel.
+func (mr *MockANPRStoreMockRecorder) GetReportEventsForExcel(ctx, filters, pageSize, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportEventsForExcel", reflect.TypeOf((*MockANPRStore)(nil).GetReportEventsForExcel), ctx, filters, pageSize, offset)
+}
+
+// GetReportStats mocks base method.
+func (m *MockANPRStore) GetReportStats(ctx context.Context, filters repository.ReportFilters) (*repository.ReportStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportStats", ctx, filters)
+	ret0, _ := ret[0].(*repository.ReportStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportStats indicates an expected call of GetReportStats.
+func (mr *MockANPRStoreMockRecorder) GetReportStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportStats", reflect.TypeOf((*MockANPRStore)(nil).GetReportStats), ctx, filters)
+}
+
+// GetSavedSearch mocks base method.
+func (m *MockANPRStore) GetSavedSearch(ctx context.Context, id uuid.UUID) (*repository.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedSearch", ctx, id)
+	ret0, _ := ret[0].(*repository.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedSearch indicates an expected call of GetSavedSearch.
+func (mr *MockANPRStoreMockRecorder) GetSavedSearch(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedSearch", reflect.TypeOf((*MockANPRStore)(nil).GetSavedSearch), ctx, id)
+}
+
+// GetShift mocks base method.
+func (m *MockANPRStore) GetShift(ctx context.Context, id uuid.UUID) (*repository.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShift", ctx, id)
+	ret0, _ := ret[0].(*repository.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShift indicates an expected call of GetShift.
+func (mr *MockANPRStoreMockRecorder) GetShift(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShift", reflect.TypeOf((*MockANPRStore)(nil).GetShift), ctx, id)
+}
+
+// GetShiftStats mocks base method.
+func (m *MockANPRStore) GetShiftStats(ctx context.Context, filters repository.ReportFilters) ([]repository.ShiftStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShiftStats", ctx, filters)
+	ret0, _ := ret[0].([]repository.ShiftStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShiftStats indicates an expected call of GetShiftStats.
+func (mr *MockANPRStoreMockRecorder) GetShiftStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShiftStats", reflect.TypeOf((*MockANPRStore)(nil).GetShiftStats), ctx, filters)
+}
+
+// GetSummarySubscription mocks base method.
+func (m *MockANPRStore) GetSummarySubscription(ctx context.Context, userID uuid.UUID) (*repository.SummarySubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummarySubscription", ctx, userID)
+	ret0, _ := ret[0].(*repository.SummarySubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummarySubscription indicates an expected call of GetSummarySubscription.
+func (mr *MockANPRStoreMockRecorder) GetSummarySubscription(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummarySubscription", reflect.TypeOf((*MockANPRStore)(nil).GetSummarySubscription), ctx, userID)
+}
+
+// GetVehicleByPlate mocks base method.
+func (m *MockANPRStore) GetVehicleByPlate(ctx context.Context, normalizedPlate string) (*repository.VehicleData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVehicleByPlate", ctx, normalizedPlate)
+	ret0, _ := ret[0].(*repository.VehicleData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVehicleByPlate indicates an expected call of GetVehicleByPlate.
+func (mr *MockANPRStoreMockRecorder) GetVehicleByPlate(ctx, normalizedPlate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicleByPlate", reflect.TypeOf((*MockANPRStore)(nil).GetVehicleByPlate), ctx, normalizedPlate)
+}
+
+// GetVehicleTypeStats mocks base method.
+func (m *MockANPRStore) GetVehicleTypeStats(ctx context.Context, filters repository.ReportFilters) ([]repository.VehicleTypeStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVehicleTypeStats", ctx, filters)
+	ret0, _ := ret[0].([]repository.VehicleTypeStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVehicleTypeStats indicates an expected call of GetVehicleTypeStats.
+func (mr *MockANPRStoreMockRecorder) GetVehicleTypeStats(ctx, filters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicleTypeStats", reflect.TypeOf((*MockANPRStore)(nil).GetVehicleTypeStats), ctx, filters)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockANPRStore) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (*repository.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(*repository.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockANPRStoreMockRecorder) GetWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockANPRStore)(nil).GetWebhookSubscription), ctx, id)
+}
+
+// ListActivePlateWatches mocks base method.
+func (m *MockANPRStore) ListActivePlateWatches(ctx context.Context, now time.Time) ([]repository.PlateWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActivePlateWatches", ctx, now)
+	ret0, _ := ret[0].([]repository.PlateWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActivePlateWatches indicates an expected call of ListActivePlateWatches.
+func (mr *MockANPRStoreMockRecorder) ListActivePlateWatches(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActivePlateWatches", reflect.TypeOf((*MockANPRStore)(nil).ListActivePlateWatches), ctx, now)
+}
+
+// ListCameras mocks base method.
+func (m *MockANPRStore) ListCameras(ctx context.Context) ([]repository.Camera, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCameras", ctx)
+	ret0, _ := ret[0].([]repository.Camera)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCameras indicates an expected call of ListCameras.
+func (mr *MockANPRStoreMockRecorder) ListCameras(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCameras", reflect.TypeOf((*MockANPRStore)(nil).ListCameras), ctx)
+}
+
+// ListCamerasWithLastEvent mocks base method.
+func (m *MockANPRStore) ListCamerasWithLastEvent(ctx context.Context) ([]repository.CameraLastEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCamerasWithLastEvent", ctx)
+	ret0, _ := ret[0].([]repository.CameraLastEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCamerasWithLastEvent indicates an expected call of ListCamerasWithLastEvent.
+func (mr *MockANPRStoreMockRecorder) ListCamerasWithLastEvent(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCamerasWithLastEvent", reflect.TypeOf((*MockANPRStore)(nil).ListCamerasWithLastEvent), ctx)
+}
+
+// ListContractorAccessRules mocks base method.
+func (m *MockANPRStore) ListContractorAccessRules(ctx context.Context) ([]repository.ContractorAccessRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContractorAccessRules", ctx)
+	ret0, _ := ret[0].([]repository.ContractorAccessRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContractorAccessRules indicates an expected call of ListContractorAccessRules.
+func (mr *MockANPRStoreMockRecorder) ListContractorAccessRules(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContractorAccessRules", reflect.TypeOf((*MockANPRStore)(nil).ListContractorAccessRules), ctx)
+}
+
+// ListContractorPolygons mocks base method.
+func (m *MockANPRStore) ListContractorPolygons(ctx context.Context) ([]repository.ContractorPolygon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContractorPolygons", ctx)
+	ret0, _ := ret[0].([]repository.ContractorPolygon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContractorPolygons indicates an expected call of ListContractorPolygons.
+func (mr *MockANPRStoreMockRecorder) ListContractorPolygons(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContractorPolygons", reflect.TypeOf((*MockANPRStore)(nil).ListContractorPolygons), ctx)
+}
+
+// ListCurrentPolygonModes mocks base method.
+func (m *MockANPRStore) ListCurrentPolygonModes(ctx context.Context) ([]repository.PolygonModeChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrentPolygonModes", ctx)
+	ret0, _ := ret[0].([]repository.PolygonModeChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrentPolygonModes indicates an expected call of ListCurrentPolygonModes.
+func (mr *MockANPRStoreMockRecorder) ListCurrentPolygonModes(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrentPolygonModes", reflect.TypeOf((*MockANPRStore)(nil).ListCurrentPolygonModes), ctx)
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockANPRStore) ListDeadLetters(ctx context.Context, pendingOnly bool, limit, offset int) ([]repository.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, pendingOnly, limit, offset)
+	ret0, _ := ret[0].([]repository.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockANPRStoreMockRecorder) ListDeadLetters(ctx, pendingOnly, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockANPRStore)(nil).ListDeadLetters), ctx, pendingOnly, limit, offset)
+}
+
+// ListDueSavedSearches mocks base method.
+func (m *MockANPRStore) ListDueSavedSearches(ctx context.Context, now time.Time, limit int) ([]repository.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueSavedSearches", ctx, now, limit)
+	ret0, _ := ret[0].([]repository.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueSavedSearches indicates an expected call of ListDueSavedSearches.
+func (mr *MockANPRStoreMockRecorder) ListDueSavedSearches(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueSavedSearches", reflect.TypeOf((*MockANPRStore)(nil).ListDueSavedSearches), ctx, now, limit)
+}
+
+// ListEnabledSummarySubscriptions mocks base method.
+func (m *MockANPRStore) ListEnabledSummarySubscriptions(ctx context.Context) ([]repository.SummarySubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEnabledSummarySubscriptions", ctx)
+	ret0, _ := ret[0].([]repository.SummarySubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEnabledSummarySubscriptions indicates an expected call of ListEnabledSummarySubscriptions.
+func (mr *MockANPRStoreMockRecorder) ListEnabledSummarySubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEnabledSummarySubscriptions", reflect.TypeOf((*MockANPRStore)(nil).ListEnabledSummarySubscriptions), ctx)
+}
+
+// ListEventComments mocks base method.
+func (m *MockANPRStore) ListEventComments(ctx context.Context, eventID uuid.UUID) ([]repository.EventComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEventComments", ctx, eventID)
+	ret0, _ := ret[0].([]repository.EventComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEventComments indicates an expected call of ListEventComments.
+func (mr *MockANPRStoreMockRecorder) ListEventComments(ctx, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventComments", reflect.TypeOf((*MockANPRStore)(nil).ListEventComments), ctx, eventID)
+}
+
+// ListEventsForExport mocks base method.
+func (m *MockANPRStore) ListEventsForExport(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEventsForExport", ctx, from, to, afterTime, afterID, limit)
+	ret0, _ := ret[0].([]repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEventsForExport indicates an expected call of ListEventsForExport.
+func (mr *MockANPRStoreMockRecorder) ListEventsForExport(ctx, from, to, afterTime, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventsForExport", reflect.TypeOf((*MockANPRStore)(nil).ListEventsForExport), ctx, from, to, afterTime, afterID, limit)
+}
+
+// ListFeatureFlagOverrides mocks base method.
+func (m *MockANPRStore) ListFeatureFlagOverrides(ctx context.Context) ([]repository.FeatureFlagOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeatureFlagOverrides", ctx)
+	ret0, _ := ret[0].([]repository.FeatureFlagOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeatureFlagOverrides indicates an expected call of ListFeatureFlagOverrides.
+func (mr *MockANPRStoreMockRecorder) ListFeatureFlagOverrides(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeatureFlagOverrides", reflect.TypeOf((*MockANPRStore)(nil).ListFeatureFlagOverrides), ctx)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockANPRStore) ListJobRuns(ctx context.Context, job string, limit int) ([]repository.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", ctx, job, limit)
+	ret0, _ := ret[0].([]repository.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockANPRStoreMockRecorder) ListJobRuns(ctx, job, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockANPRStore)(nil).ListJobRuns), ctx, job, limit)
+}
+
+// ListLatestJobRuns mocks base method.
+func (m *MockANPRStore) ListLatestJobRuns(ctx context.Context) ([]repository.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLatestJobRuns", ctx)
+	ret0, _ := ret[0].([]repository.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLatestJobRuns indicates an expected call of ListLatestJobRuns.
+func (mr *MockANPRStoreMockRecorder) ListLatestJobRuns(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLatestJobRuns", reflect.TypeOf((*MockANPRStore)(nil).ListLatestJobRuns), ctx)
+}
+
+// ListLists mocks base method.
+func (m *MockANPRStore) ListLists(ctx context.Context) ([]repository.ListSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLists", ctx)
+	ret0, _ := ret[0].([]repository.ListSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLists indicates an expected call of ListLists.
+func (mr *MockANPRStoreMockRecorder) ListLists(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLists", reflect.TypeOf((*MockANPRStore)(nil).ListLists), ctx)
+}
+
+// ListPhotoDuplicates mocks base method.
+func (m *MockANPRStore) ListPhotoDuplicates(ctx context.Context, filter repository.PhotoDuplicateFilter) ([]repository.PhotoDuplicate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPhotoDuplicates", ctx, filter)
+	ret0, _ := ret[0].([]repository.PhotoDuplicate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPhotoDuplicates indicates an expected call of ListPhotoDuplicates.
+func (mr *MockANPRStoreMockRecorder) ListPhotoDuplicates(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPhotoDuplicates", reflect.TypeOf((*MockANPRStore)(nil).ListPhotoDuplicates), ctx, filter)
+}
+
+// ListPlateAliases mocks base method.
+func (m *MockANPRStore) ListPlateAliases(ctx context.Context, plateID uuid.UUID) ([]repository.PlateAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlateAliases", ctx, plateID)
+	ret0, _ := ret[0].([]repository.PlateAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlateAliases indicates an expected call of ListPlateAliases.
+func (mr *MockANPRStoreMockRecorder) ListPlateAliases(ctx, plateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlateAliases", reflect.TypeOf((*MockANPRStore)(nil).ListPlateAliases), ctx, plateID)
+}
+
+// ListPlateTripQuotas mocks base method.
+func (m *MockANPRStore) ListPlateTripQuotas(ctx context.Context) ([]repository.PlateTripQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlateTripQuotas", ctx)
+	ret0, _ := ret[0].([]repository.PlateTripQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlateTripQuotas indicates an expected call of ListPlateTripQuotas.
+func (mr *MockANPRStoreMockRecorder) ListPlateTripQuotas(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlateTripQuotas", reflect.TypeOf((*MockANPRStore)(nil).ListPlateTripQuotas), ctx)
+}
+
+// ListPlateWatches mocks base method.
+func (m *MockANPRStore) ListPlateWatches(ctx context.Context, userID uuid.UUID) ([]repository.PlateWatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlateWatches", ctx, userID)
+	ret0, _ := ret[0].([]repository.PlateWatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlateWatches indicates an expected call of ListPlateWatches.
+func (mr *MockANPRStoreMockRecorder) ListPlateWatches(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlateWatches", reflect.TypeOf((*MockANPRStore)(nil).ListPlateWatches), ctx, userID)
+}
+
+// ListPlatesInList mocks base method.
+func (m *MockANPRStore) ListPlatesInList(ctx context.Context, listName string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlatesInList", ctx, listName)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlatesInList indicates an expected call of ListPlatesInList.
+func (mr *MockANPRStoreMockRecorder) ListPlatesInList(ctx, listName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlatesInList", reflect.TypeOf((*MockANPRStore)(nil).ListPlatesInList), ctx, listName)
+}
+
+// ListPolygonModeChanges mocks base method.
+func (m *MockANPRStore) ListPolygonModeChanges(ctx context.Context, polygonID *uuid.UUID, until time.Time) ([]repository.PolygonModeChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPolygonModeChanges", ctx, polygonID, until)
+	ret0, _ := ret[0].([]repository.PolygonModeChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPolygonModeChanges indicates an expected call of ListPolygonModeChanges.
+func (mr *MockANPRStoreMockRecorder) ListPolygonModeChanges(ctx, polygonID, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolygonModeChanges", reflect.TypeOf((*MockANPRStore)(nil).ListPolygonModeChanges), ctx, polygonID, until)
+}
+
+// ListPolygons mocks base method.
+func (m *MockANPRStore) ListPolygons(ctx context.Context) ([]repository.Polygon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPolygons", ctx)
+	ret0, _ := ret[0].([]repository.Polygon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPolygons indicates an expected call of ListPolygons.
+func (mr *MockANPRStoreMockRecorder) ListPolygons(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolygons", reflect.TypeOf((*MockANPRStore)(nil).ListPolygons), ctx)
+}
+
+// ListRawPayloadEvents mocks base method.
+func (m *MockANPRStore) ListRawPayloadEvents(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]repository.ANPREvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRawPayloadEvents", ctx, from, to, afterTime, afterID, limit)
+	ret0, _ := ret[0].([]repository.ANPREvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRawPayloadEvents indicates an expected call of ListRawPayloadEvents.
+func (mr *MockANPRStoreMockRecorder) ListRawPayloadEvents(ctx, from, to, afterTime, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRawPayloadEvents", reflect.TypeOf((*MockANPRStore)(nil).ListRawPayloadEvents), ctx, from, to, afterTime, afterID, limit)
+}
+
+// ListSavedSearches mocks base method.
+func (m *MockANPRStore) ListSavedSearches(ctx context.Context, userID uuid.UUID) ([]repository.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavedSearches", ctx, userID)
+	ret0, _ := ret[0].([]repository.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavedSearches indicates an expected call of ListSavedSearches.
+func (mr *MockANPRStoreMockRecorder) ListSavedSearches(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavedSearches", reflect.TypeOf((*MockANPRStore)(nil).ListSavedSearches), ctx, userID)
+}
+
+// ListShifts mocks base method.
+func (m *MockANPRStore) ListShifts(ctx context.Context) ([]repository.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShifts", ctx)
+	ret0, _ := ret[0].([]repository.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShifts indicates an expected call of ListShifts.
+func (mr *MockANPRStoreMockRecorder) ListShifts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShifts", reflect.TypeOf((*MockANPRStore)(nil).ListShifts), ctx)
+}
+
+// ListTripPlates mocks base method.
+func (m *MockANPRStore) ListTripPlates(ctx context.Context, from, to time.Time) ([]repository.TripPlate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTripPlates", ctx, from, to)
+	ret0, _ := ret[0].([]repository.TripPlate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTripPlates indicates an expected call of ListTripPlates.
+func (mr *MockANPRStoreMockRecorder) ListTripPlates(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTripPlates", reflect.TypeOf((*MockANPRStore)(nil).ListTripPlates), ctx, from, to)
+}
+
+// ListUnmatchedPlates mocks base method.
+func (m *MockANPRStore) ListUnmatchedPlates(ctx context.Context, from, to time.Time, limit, offset int) ([]repository.UnmatchedPlate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnmatchedPlates", ctx, from, to, limit, offset)
+	ret0, _ := ret[0].([]repository.UnmatchedPlate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnmatchedPlates indicates an expected call of ListUnmatchedPlates.
+func (mr *MockANPRStoreMockRecorder) ListUnmatchedPlates(ctx, from, to, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnmatchedPlates", reflect.TypeOf((*MockANPRStore)(nil).ListUnmatchedPlates), ctx, from, to, limit, offset)
+}
+
+// ListUsage mocks base method.
+func (m *MockANPRStore) ListUsage(ctx context.Context, from, to time.Time, organizationID *uuid.UUID) ([]repository.UsageDaily, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsage", ctx, from, to, organizationID)
+	ret0, _ := ret[0].([]repository.UsageDaily)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsage indicates an expected call of ListUsage.
+func (mr *MockANPRStoreMockRecorder) ListUsage(ctx, from, to, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsage", reflect.TypeOf((*MockANPRStore)(nil).ListUsage), ctx, from, to, organizationID)
+}
+
+// ListVehicleWhitelistPlates mocks base method.
+func (m *MockANPRStore) ListVehicleWhitelistPlates(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVehicleWhitelistPlates", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVehicleWhitelistPlates indicates an expected call of ListVehicleWhitelistPlates.
+func (mr *MockANPRStoreMockRecorder) ListVehicleWhitelistPlates(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVehicleWhitelistPlates", reflect.TypeOf((*MockANPRStore)(nil).ListVehicleWhitelistPlates), ctx)
+}
+
+// ListWeatherLocations mocks base method.
+func (m *MockANPRStore) ListWeatherLocations(ctx context.Context) ([]repository.WeatherLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWeatherLocations", ctx)
+	ret0, _ := ret[0].([]repository.WeatherLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWeatherLocations indicates an expected call of ListWeatherLocations.
+func (mr *MockANPRStoreMockRecorder) ListWeatherLocations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWeatherLocations", reflect.TypeOf((*MockANPRStore)(nil).ListWeatherLocations), ctx)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockANPRStore) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit, offset int) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID, status, limit, offset)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockANPRStoreMockRecorder) ListWebhookDeliveries(ctx, subscriptionID, status, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockANPRStore)(nil).ListWebhookDeliveries), ctx, subscriptionID, status, limit, offset)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockANPRStore) ListWebhookSubscriptions(ctx context.Context, activeOnly bool) ([]repository.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", ctx, activeOnly)
+	ret0, _ := ret[0].([]repository.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockANPRStoreMockRecorder) ListWebhookSubscriptions(ctx, activeOnly any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockANPRStore)(nil).ListWebhookSubscriptions), ctx, activeOnly)
+}
+
+// ListWhitelistSyncCameras mocks base method.
+func (m *MockANPRStore) ListWhitelistSyncCameras(ctx context.Context) ([]repository.Camera, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWhitelistSyncCameras", ctx)
+	ret0, _ := ret[0].([]repository.Camera)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWhitelistSyncCameras indicates an expected call of ListWhitelistSyncCameras.
+func (mr *MockANPRStoreMockRecorder) ListWhitelistSyncCameras(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhitelistSyncCameras", reflect.TypeOf((*MockANPRStore)(nil).ListWhitelistSyncCameras), ctx)
+}
+
+// LoadListMembership mocks base method.
+func (m *MockANPRStore) LoadListMembership(ctx context.Context) (map[uuid.UUID][]repository.ListMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadListMembership", ctx)
+	ret0, _ := ret[0].(map[uuid.UUID][]repository.ListMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadListMembership indicates an expected call of LoadListMembership.
+func (mr *MockANPRStoreMockRecorder) LoadListMembership(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadListMembership", reflect.TypeOf((*MockANPRStore)(nil).LoadListMembership), ctx)
+}
+
+// LockBillingPeriod mocks base method.
+func (m *MockANPRStore) LockBillingPeriod(ctx context.Context, period string, lockedBy uuid.UUID, lockedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBillingPeriod", ctx, period, lockedBy, lockedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockBillingPeriod indicates an expected call of LockBillingPeriod.
+func (mr *MockANPRStoreMockRecorder) LockBillingPeriod(ctx, period, lockedBy, lockedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBillingPeriod", reflect.TypeOf((*MockANPRStore)(nil).LockBillingPeriod), ctx, period, lockedBy, lockedAt)
+}
+
+// MarkCameraWhitelistSynced mocks base method.
+func (m *MockANPRStore) MarkCameraWhitelistSynced(ctx context.Context, cameraID string, syncedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkCameraWhitelistSynced", ctx, cameraID, syncedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkCameraWhitelistSynced indicates an expected call of MarkCameraWhitelistSynced.
+func (mr *MockANPRStoreMockRecorder) MarkCameraWhitelistSynced(ctx, cameraID, syncedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkCameraWhitelistSynced", reflect.TypeOf((*MockANPRStore)(nil).MarkCameraWhitelistSynced), ctx, cameraID, syncedAt)
+}
+
+// MarkDeadLetterReplayed mocks base method.
+func (m *MockANPRStore) MarkDeadLetterReplayed(ctx context.Context, id uuid.UUID, replayedAt time.Time, eventID *uuid.UUID, replayErr *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeadLetterReplayed", ctx, id, replayedAt, eventID, replayErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeadLetterReplayed indicates an expected call of MarkDeadLetterReplayed.
+func (mr *MockANPRStoreMockRecorder) MarkDeadLetterReplayed(ctx, id, replayedAt, eventID, replayErr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeadLetterReplayed", reflect.TypeOf((*MockANPRStore)(nil).MarkDeadLetterReplayed), ctx, id, replayedAt, eventID, replayErr)
+}
+
+// MarkMQTTAttemptFailed mocks base method.
+func (m *MockANPRStore) MarkMQTTAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMQTTAttemptFailed", ctx, id, errMsg, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMQTTAttemptFailed indicates an expected call of MarkMQTTAttemptFailed.
+func (mr *MockANPRStoreMockRecorder) MarkMQTTAttemptFailed(ctx, id, errMsg, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMQTTAttemptFailed", reflect.TypeOf((*MockANPRStore)(nil).MarkMQTTAttemptFailed), ctx, id, errMsg, nextAttemptAt)
+}
+
+// MarkMQTTMessageSent mocks base method.
+func (m *MockANPRStore) MarkMQTTMessageSent(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMQTTMessageSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMQTTMessageSent indicates an expected call of MarkMQTTMessageSent.
+func (mr *MockANPRStoreMockRecorder) MarkMQTTMessageSent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMQTTMessageSent", reflect.TypeOf((*MockANPRStore)(nil).MarkMQTTMessageSent), ctx, id)
+}
+
+// MarkTelegramAttemptFailed mocks base method.
+func (m *MockANPRStore) MarkTelegramAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkTelegramAttemptFailed", ctx, id, errMsg, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkTelegramAttemptFailed indicates an expected call of MarkTelegramAttemptFailed.
+func (mr *MockANPRStoreMockRecorder) MarkTelegramAttemptFailed(ctx, id, errMsg, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTelegramAttemptFailed", reflect.TypeOf((*MockANPRStore)(nil).MarkTelegramAttemptFailed), ctx, id, errMsg, nextAttemptAt)
+}
+
+// MarkTelegramNotificationSent mocks base method.
+func (m *MockANPRStore) MarkTelegramNotificationSent(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkTelegramNotificationSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkTelegramNotificationSent indicates an expected call of MarkTelegramNotificationSent.
+func (mr *MockANPRStoreMockRecorder) MarkTelegramNotificationSent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTelegramNotificationSent", reflect.TypeOf((*MockANPRStore)(nil).MarkTelegramNotificationSent), ctx, id)
+}
+
+// MarkWebhookAttemptFailed mocks base method.
+func (m *MockANPRStore) MarkWebhookAttemptFailed(ctx context.Context, id uuid.UUID, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookAttemptFailed", ctx, id, statusCode, errMsg, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookAttemptFailed indicates an expected call of MarkWebhookAttemptFailed.
+func (mr *MockANPRStoreMockRecorder) MarkWebhookAttemptFailed(ctx, id, statusCode, errMsg, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookAttemptFailed", reflect.TypeOf((*MockANPRStore)(nil).MarkWebhookAttemptFailed), ctx, id, statusCode, errMsg, nextAttemptAt)
+}
+
+// MarkWebhookDelivered mocks base method.
+func (m *MockANPRStore) MarkWebhookDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDelivered", ctx, id, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDelivered indicates an expected call of MarkWebhookDelivered.
+func (mr *MockANPRStoreMockRecorder) MarkWebhookDelivered(ctx, id, statusCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDelivered", reflect.TypeOf((*MockANPRStore)(nil).MarkWebhookDelivered), ctx, id, statusCode)
+}
+
+// MergePlates mocks base method.
+func (m *MockANPRStore) MergePlates(ctx context.Context, targetID, sourceID uuid.UUID, note *string) (*repository.PlateMergeResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergePlates", ctx, targetID, sourceID, note)
+	ret0, _ := ret[0].(*repository.PlateMergeResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergePlates indicates an expected call of MergePlates.
+func (mr *MockANPRStoreMockRecorder) MergePlates(ctx, targetID, sourceID, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergePlates", reflect.TypeOf((*MockANPRStore)(nil).MergePlates), ctx, targetID, sourceID, note)
+}
+
+// PurgeDeadLetters mocks base method.
+func (m *MockANPRStore) PurgeDeadLetters(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeadLetters", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeadLetters indicates an expected call of PurgeDeadLetters.
+func (mr *MockANPRStoreMockRecorder) PurgeDeadLetters(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeadLetters", reflect.TypeOf((*MockANPRStore)(nil).PurgeDeadLetters), ctx, before)
+}
+
+// PurgeDeletedEvents mocks base method.
+func (m *MockANPRStore) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedEvents", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedEvents indicates an expected call of PurgeDeletedEvents.
+func (mr *MockANPRStoreMockRecorder) PurgeDeletedEvents(ctx, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedEvents", reflect.TypeOf((*MockANPRStore)(nil).PurgeDeletedEvents), ctx, deletedBefore)
+}
+
+// PurgeJobRuns mocks base method.
+func (m *MockANPRStore) PurgeJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeJobRuns", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeJobRuns indicates an expected call of PurgeJobRuns.
+func (mr *MockANPRStoreMockRecorder) PurgeJobRuns(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeJobRuns", reflect.TypeOf((*MockANPRStore)(nil).PurgeJobRuns), ctx, before)
+}
+
+// RecordCameraClockSkew mocks base method.
+func (m *MockANPRStore) RecordCameraClockSkew(ctx context.Context, cameraID string, skewSeconds float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCameraClockSkew", ctx, cameraID, skewSeconds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCameraClockSkew indicates an expected call of RecordCameraClockSkew.
+func (mr *MockANPRStoreMockRecorder) RecordCameraClockSkew(ctx, cameraID, skewSeconds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCameraClockSkew", reflect.TypeOf((*MockANPRStore)(nil).RecordCameraClockSkew), ctx, cameraID, skewSeconds)
+}
+
+// RecordCameraSignal mocks base method.
+func (m *MockANPRStore) RecordCameraSignal(ctx context.Context, cameraID string, receivedAt time.Time, videoLoss *bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCameraSignal", ctx, cameraID, receivedAt, videoLoss)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCameraSignal indicates an expected call of RecordCameraSignal.
+func (mr *MockANPRStoreMockRecorder) RecordCameraSignal(ctx, cameraID, receivedAt, videoLoss any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCameraSignal", reflect.TypeOf((*MockANPRStore)(nil).RecordCameraSignal), ctx, cameraID, receivedAt, videoLoss)
+}
+
+// RefreshHourlyEventCounts mocks base method.
+func (m *MockANPRStore) RefreshHourlyEventCounts(ctx context.Context, from time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshHourlyEventCounts", ctx, from)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshHourlyEventCounts indicates an expected call of RefreshHourlyEventCounts.
+func (mr *MockANPRStoreMockRecorder) RefreshHourlyEventCounts(ctx, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshHourlyEventCounts", reflect.TypeOf((*MockANPRStore)(nil).RefreshHourlyEventCounts), ctx, from)
+}
+
+// RemoveListItem mocks base method.
+func (m *MockANPRStore) RemoveListItem(ctx context.Context, listID, plateID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveListItem", ctx, listID, plateID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveListItem indicates an expected call of RemoveListItem.
+func (mr *MockANPRStoreMockRecorder) RemoveListItem(ctx, listID, plateID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveListItem", reflect.TypeOf((*MockANPRStore)(nil).RemoveListItem), ctx, listID, plateID)
+}
+
+// RemoveVehicleFromWhitelist mocks base method.
+func (m *MockANPRStore) RemoveVehicleFromWhitelist(ctx context.Context, normalizedPlate string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveVehicleFromWhitelist", ctx, normalizedPlate)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveVehicleFromWhitelist indicates an expected call of RemoveVehicleFromWhitelist.
+func (mr *MockANPRStoreMockRecorder) RemoveVehicleFromWhitelist(ctx, normalizedPlate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveVehicleFromWhitelist", reflect.TypeOf((*MockANPRStore)(nil).RemoveVehicleFromWhitelist), ctx, normalizedPlate)
+}
+
+// RepairTripQuota mocks base method.
+func (m *MockANPRStore) RepairTripQuota(ctx context.Context, repair repository.TripQuotaRepair) ([]repository.TripChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairTripQuota", ctx, repair)
+	ret0, _ := ret[0].([]repository.TripChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairTripQuota indicates an expected call of RepairTripQuota.
+func (mr *MockANPRStoreMockRecorder) RepairTripQuota(ctx, repair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairTripQuota", reflect.TypeOf((*MockANPRStore)(nil).RepairTripQuota), ctx, repair)
+}
+
+// ReplaceContractorPolygons mocks base method.
+func (m *MockANPRStore) ReplaceContractorPolygons(ctx context.Context, contractorID uuid.UUID, polygonIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceContractorPolygons", ctx, contractorID, polygonIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceContractorPolygons indicates an expected call of ReplaceContractorPolygons.
+func (mr *MockANPRStoreMockRecorder) ReplaceContractorPolygons(ctx, contractorID, polygonIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceContractorPolygons", reflect.TypeOf((*MockANPRStore)(nil).ReplaceContractorPolygons), ctx, contractorID, polygonIDs)
+}
+
+// ResolvePlateAlias mocks base method.
+func (m *MockANPRStore) ResolvePlateAlias(ctx context.Context, normalized string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePlateAlias", ctx, normalized)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePlateAlias indicates an expected call of ResolvePlateAlias.
+func (mr *MockANPRStoreMockRecorder) ResolvePlateAlias(ctx, normalized any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePlateAlias", reflect.TypeOf((*MockANPRStore)(nil).ResolvePlateAlias), ctx, normalized)
+}
+
+// ResolvePolygonIDByCameraID mocks base method.
+func (m *MockANPRStore) ResolvePolygonIDByCameraID(ctx context.Context, cameraID string) (*uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePolygonIDByCameraID", ctx, cameraID)
+	ret0, _ := ret[0].(*uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePolygonIDByCameraID indicates an expected call of ResolvePolygonIDByCameraID.
+func (mr *MockANPRStoreMockRecorder) ResolvePolygonIDByCameraID(ctx, cameraID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePolygonIDByCameraID", reflect.TypeOf((*MockANPRStore)(nil).ResolvePolygonIDByCameraID), ctx, cameraID)
+}
+
+// RestoreEvent mocks base method.
+func (m *MockANPRStore) RestoreEvent(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreEvent", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreEvent indicates an expected call of RestoreEvent.
+func (mr *MockANPRStoreMockRecorder) RestoreEvent(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreEvent", reflect.TypeOf((*MockANPRStore)(nil).RestoreEvent), ctx, id)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockANPRStore) RetryWebhookDelivery(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, subscriptionID, deliveryID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockANPRStoreMockRecorder) RetryWebhookDelivery(ctx, subscriptionID, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockANPRStore)(nil).RetryWebhookDelivery), ctx, subscriptionID, deliveryID)
+}
+
+// SaveANPREvent mocks base method.
+func (m *MockANPRStore) SaveANPREvent(ctx context.Context, event *anpr.Event, country, region string, contractorID, polygonID *uuid.UUID, photoURLs []string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveANPREvent", ctx, event, country, region, contractorID, polygonID, photoURLs)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveANPREvent indicates an expected call of SaveANPREvent.
+func (mr *MockANPRStoreMockRecorder) SaveANPREvent(ctx, event, country, region, contractorID, polygonID, photoURLs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveANPREvent", reflect.TypeOf((*MockANPRStore)(nil).SaveANPREvent), ctx, event, country, region, contractorID, polygonID, photoURLs)
+}
+
+// SaveBillingPeriod mocks base method.
+func (m *MockANPRStore) SaveBillingPeriod(ctx context.Context, billing *repository.BillingPeriod) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBillingPeriod", ctx, billing)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBillingPeriod indicates an expected call of SaveBillingPeriod.
+func (mr *MockANPRStoreMockRecorder) SaveBillingPeriod(ctx, billing any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBillingPeriod", reflect.TypeOf((*MockANPRStore)(nil).SaveBillingPeriod), ctx, billing)
+}
+
+// SetListItemValidity mocks base method.
+func (m *MockANPRStore) SetListItemValidity(ctx context.Context, listID, plateID uuid.UUID, validFrom, validUntil *time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListItemValidity", ctx, listID, plateID, validFrom, validUntil)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetListItemValidity indicates an expected call of SetListItemValidity.
+func (mr *MockANPRStoreMockRecorder) SetListItemValidity(ctx, listID, plateID, validFrom, validUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListItemValidity", reflect.TypeOf((*MockANPRStore)(nil).SetListItemValidity), ctx, listID, plateID, validFrom, validUntil)
+}
+
+// SoftDeleteAllEvents mocks base method.
+func (m *MockANPRStore) SoftDeleteAllEvents(ctx context.Context, deletedAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteAllEvents", ctx, deletedAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteAllEvents indicates an expected call of SoftDeleteAllEvents.
+func (mr *MockANPRStoreMockRecorder) SoftDeleteAllEvents(ctx, deletedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteAllEvents", reflect.TypeOf((*MockANPRStore)(nil).SoftDeleteAllEvents), ctx, deletedAt)
+}
+
+// SoftDeleteEventsBefore mocks base method.
+func (m *MockANPRStore) SoftDeleteEventsBefore(ctx context.Context, before, deletedAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteEventsBefore", ctx, before, deletedAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteEventsBefore indicates an expected call of SoftDeleteEventsBefore.
+func (mr *MockANPRStoreMockRecorder) SoftDeleteEventsBefore(ctx, before, deletedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteEventsBefore", reflect.TypeOf((*MockANPRStore)(nil).SoftDeleteEventsBefore), ctx, before, deletedAt)
+}
+
+// StreamEvents mocks base method.
+func (m *MockANPRStore) StreamEvents(ctx context.Context, search repository.EventSearch, fn func(*repository.ANPREvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamEvents", ctx, search, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamEvents indicates an expected call of StreamEvents.
+func (mr *MockANPRStoreMockRecorder) StreamEvents(ctx, search, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamEvents", reflect.TypeOf((*MockANPRStore)(nil).StreamEvents), ctx, search, fn)
+}
+
+// SyncVehicleToWhitelist mocks base method.
+func (m *MockANPRStore) SyncVehicleToWhitelist(ctx context.Context, plateNumber string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncVehicleToWhitelist", ctx, plateNumber)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncVehicleToWhitelist indicates an expected call of SyncVehicleToWhitelist.
+func (mr *MockANPRStoreMockRecorder) SyncVehicleToWhitelist(ctx, plateNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncVehicleToWhitelist", reflect.TypeOf((*MockANPRStore)(nil).SyncVehicleToWhitelist), ctx, plateNumber)
+}
+
+// TouchPlateWatches mocks base method.
+func (m *MockANPRStore) TouchPlateWatches(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchPlateWatches", ctx, ids, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchPlateWatches indicates an expected call of TouchPlateWatches.
+func (mr *MockANPRStoreMockRecorder) TouchPlateWatches(ctx, ids, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchPlateWatches", reflect.TypeOf((*MockANPRStore)(nil).TouchPlateWatches), ctx, ids, at)
+}
+
+// UpdateEventDerivedFields mocks base method.
+func (m *MockANPRStore) UpdateEventDerivedFields(ctx context.Context, event *repository.ANPREvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEventDerivedFields", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEventDerivedFields indicates an expected call of UpdateEventDerivedFields.
+func (mr *MockANPRStoreMockRecorder) UpdateEventDerivedFields(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEventDerivedFields", reflect.TypeOf((*MockANPRStore)(nil).UpdateEventDerivedFields), ctx, event)
+}
+
+// UpdatePolygon mocks base method.
+func (m *MockANPRStore) UpdatePolygon(ctx context.Context, polygon *repository.Polygon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePolygon", ctx, polygon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePolygon indicates an expected call of UpdatePolygon.
+func (mr *MockANPRStoreMockRecorder) UpdatePolygon(ctx, polygon any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePolygon", reflect.TypeOf((*MockANPRStore)(nil).UpdatePolygon), ctx, polygon)
+}
+
+// UpdateSavedSearch mocks base method.
+func (m *MockANPRStore) UpdateSavedSearch(ctx context.Context, search *repository.SavedSearch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSavedSearch indicates an expected call of UpdateSavedSearch.
+func (mr *MockANPRStoreMockRecorder) UpdateSavedSearch(ctx, search any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSavedSearch", reflect.TypeOf((*MockANPRStore)(nil).UpdateSavedSearch), ctx, search)
+}
+
+// UpdateShift mocks base method.
+func (m *MockANPRStore) UpdateShift(ctx context.Context, shift *repository.Shift) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShift", ctx, shift)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateShift indicates an expected call of UpdateShift.
+func (mr *MockANPRStoreMockRecorder) UpdateShift(ctx, shift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShift", reflect.TypeOf((*MockANPRStore)(nil).UpdateShift), ctx, shift)
+}
+
+// UpdateWebhookSubscription mocks base method.
+func (m *MockANPRStore) UpdateWebhookSubscription(ctx context.Context, sub *repository.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookSubscription indicates an expected call of UpdateWebhookSubscription.
+func (mr *MockANPRStoreMockRecorder) UpdateWebhookSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookSubscription", reflect.TypeOf((*MockANPRStore)(nil).UpdateWebhookSubscription), ctx, sub)
+}
+
+// UpsertCamera mocks base method.
+func (m *MockANPRStore) UpsertCamera(ctx context.Context, camera *repository.Camera) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCamera", ctx, camera)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCamera indicates an expected call of UpsertCamera.
+func (mr *MockANPRStoreMockRecorder) UpsertCamera(ctx, camera any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCamera", reflect.TypeOf((*MockANPRStore)(nil).UpsertCamera), ctx, camera)
+}
+
+// UpsertContractorAccessRule mocks base method.
+func (m *MockANPRStore) UpsertContractorAccessRule(ctx context.Context, rule *repository.ContractorAccessRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertContractorAccessRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertContractorAccessRule indicates an expected call of UpsertContractorAccessRule.
+func (mr *MockANPRStoreMockRecorder) UpsertContractorAccessRule(ctx, rule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertContractorAccessRule", reflect.TypeOf((*MockANPRStore)(nil).UpsertContractorAccessRule), ctx, rule)
+}
+
+// UpsertFeatureFlagOverride mocks base method.
+func (m *MockANPRStore) UpsertFeatureFlagOverride(ctx context.Context, override *repository.FeatureFlagOverride) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertFeatureFlagOverride", ctx, override)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertFeatureFlagOverride indicates an expected call of UpsertFeatureFlagOverride.
+func (mr *MockANPRStoreMockRecorder) UpsertFeatureFlagOverride(ctx, override any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertFeatureFlagOverride", reflect.TypeOf((*MockANPRStore)(nil).UpsertFeatureFlagOverride), ctx, override)
+}
+
+// UpsertListItem mocks base method.
+func (m *MockANPRStore) UpsertListItem(ctx context.Context, item *repository.ListItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertListItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertListItem indicates an expected call of UpsertListItem.
+func (mr *MockANPRStoreMockRecorder) UpsertListItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertListItem", reflect.TypeOf((*MockANPRStore)(nil).UpsertListItem), ctx, item)
+}
+
+// UpsertPhotoRendition mocks base method.
+func (m *MockANPRStore) UpsertPhotoRendition(ctx context.Context, rendition *repository.PhotoRendition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPhotoRendition", ctx, rendition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPhotoRendition indicates an expected call of UpsertPhotoRendition.
+func (mr *MockANPRStoreMockRecorder) UpsertPhotoRendition(ctx, rendition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPhotoRendition", reflect.TypeOf((*MockANPRStore)(nil).UpsertPhotoRendition), ctx, rendition)
+}
+
+// UpsertPlateTripQuota mocks base method.
+func (m *MockANPRStore) UpsertPlateTripQuota(ctx context.Context, quota *repository.PlateTripQuota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPlateTripQuota", ctx, quota)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPlateTripQuota indicates an expected call of UpsertPlateTripQuota.
+func (mr *MockANPRStoreMockRecorder) UpsertPlateTripQuota(ctx, quota any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPlateTripQuota", reflect.TypeOf((*MockANPRStore)(nil).UpsertPlateTripQuota), ctx, quota)
+}
+
+// UpsertSummarySubscription mocks base method.
+func (m *MockANPRStore) UpsertSummarySubscription(ctx context.Context, sub *repository.SummarySubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSummarySubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSummarySubscription indicates an expected call of UpsertSummarySubscription.
+func (mr *MockANPRStoreMockRecorder) UpsertSummarySubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSummarySubscription", reflect.TypeOf((*MockANPRStore)(nil).UpsertSummarySubscription), ctx, sub)
+}
+
+// UpsertWeatherObservations mocks base method.
+func (m *MockANPRStore) UpsertWeatherObservations(ctx context.Context, observations []repository.WeatherObservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWeatherObservations", ctx, observations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWeatherObservations indicates an expected call of UpsertWeatherObservations.
+func (mr *MockANPRStoreMockRecorder) UpsertWeatherObservations(ctx, observations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWeatherObservations", reflect.TypeOf((*MockANPRStore)(nil).UpsertWeatherObservations), ctx, observations)
+}
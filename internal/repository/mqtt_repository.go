@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+)
+
+// MQTTMessage — сообщение о событии, ожидающее публикации в MQTT-брокер
+type MQTTMessage struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EventID       uuid.UUID      `gorm:"type:uuid;not null"`
+	Topic         string         `gorm:"not null"`
+	Payload       datatypes.JSON `gorm:"type:jsonb;not null"`
+	Status        string         `gorm:"not null"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+func (MQTTMessage) TableName() string {
+	return "anpr_mqtt_outbox"
+}
+
+// EnqueueMQTTMessage ставит сообщение в outbox; повтор того же события пропускается
+func (r *ANPRRepository) EnqueueMQTTMessage(ctx context.Context, msg *MQTTMessage) error {
+	now := r.clock.Now()
+	if msg.ID == uuid.Nil {
+		msg.ID = r.ids.NewID()
+	}
+	msg.Status = OutboxStatusPending
+	msg.CreatedAt = now
+	msg.NextAttemptAt = now
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "event_id"}}, DoNothing: true}).
+		Create(msg).Error
+	if err != nil {
+		return fmt.Errorf("failed to enqueue mqtt message: %w", err)
+	}
+	return nil
+}
+
+// ClaimMQTTMessages выбирает до limit сообщений, которым пора уйти (см. claimOutbox)
+func (r *ANPRRepository) ClaimMQTTMessages(ctx context.Context, limit int, lease time.Duration) ([]MQTTMessage, error) {
+	var messages []MQTTMessage
+	if err := r.claimOutbox(ctx, MQTTMessage{}.TableName(), limit, lease, &messages); err != nil {
+		return nil, fmt.Errorf("failed to claim mqtt messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkMQTTMessageSent отмечает успешную публикацию
+func (r *ANPRRepository) MarkMQTTMessageSent(ctx context.Context, id uuid.UUID) error {
+	if err := r.markOutboxDelivered(ctx, &MQTTMessage{}, id, nil); err != nil {
+		return fmt.Errorf("failed to mark mqtt message sent: %w", err)
+	}
+	return nil
+}
+
+// MarkMQTTAttemptFailed записывает неудачную попытку; nextAttemptAt nil — сообщение помечается failed
+func (r *ANPRRepository) MarkMQTTAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error {
+	if err := r.recordOutboxFailure(ctx, &MQTTMessage{}, id, nil, errMsg, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to record mqtt attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Статусы записи outbox (доставки вебхуков, сообщения MQTT)
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusFailed    = "failed"
+)
+
+// claimOutbox выбирает из таблицы outbox до limit записей, которым пора уйти, и откладывает их
+// следующую попытку на lease: параллельные реплики не возьмут те же строки, а записи упавшей
+// реплики вернутся в работу после истечения lease. Таблица должна иметь колонки id, status
+// и next_attempt_at.
+func (r *ANPRRepository) claimOutbox(ctx context.Context, table string, limit int, lease time.Duration, dest interface{}) error {
+	now := r.clock.Now()
+	return r.db.WithContext(ctx).Raw(fmt.Sprintf(`
+		UPDATE %[1]s SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM %[1]s
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, table), now.Add(lease), OutboxStatusPending, now, limit).
+		Scan(dest).Error
+}
+
+// markOutboxDelivered отмечает успешную отправку записи outbox; updates — дополнительные колонки
+func (r *ANPRRepository) markOutboxDelivered(ctx context.Context, model interface{}, id uuid.UUID, updates map[string]interface{}) error {
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["status"] = OutboxStatusDelivered
+	updates["attempts"] = gorm.Expr("attempts + 1")
+	updates["last_error"] = nil
+	updates["delivered_at"] = r.clock.Now()
+	return r.db.WithContext(ctx).Model(model).Where("id = ?", id).Updates(updates).Error
+}
+
+// recordOutboxFailure записывает неудачную попытку. nextAttemptAt nil — попытки исчерпаны,
+// запись помечается failed.
+func (r *ANPRRepository) recordOutboxFailure(ctx context.Context, model interface{}, id uuid.UUID, updates map[string]interface{}, errMsg string, nextAttemptAt *time.Time) error {
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["attempts"] = gorm.Expr("attempts + 1")
+	updates["last_error"] = errMsg
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = OutboxStatusFailed
+	}
+	return r.db.WithContext(ctx).Model(model).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// eventPartitionDateLayout — формат дат в имени секции anpr_events_YYYYMMDD_YYYYMMDD
+const eventPartitionDateLayout = "20060102"
+
+var eventPartitionPattern = regexp.MustCompile(`^anpr_events_(\d{8})_(\d{8})$`)
+
+// EventPartition — секция anpr_events с диапазоном event_time [From, To) в UTC
+type EventPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// parseEventPartition разбирает имя секции; секция по умолчанию и чужие таблицы не распознаются
+func parseEventPartition(name string) (EventPartition, bool) {
+	matches := eventPartitionPattern.FindStringSubmatch(name)
+	if matches == nil {
+		return EventPartition{}, false
+	}
+	from, err := time.Parse(eventPartitionDateLayout, matches[1])
+	if err != nil {
+		return EventPartition{}, false
+	}
+	to, err := time.Parse(eventPartitionDateLayout, matches[2])
+	if err != nil || !to.After(from) {
+		return EventPartition{}, false
+	}
+	return EventPartition{Name: name, From: from, To: to}, true
+}
+
+// eventPartitionFor возвращает секцию, в которую попадает момент t: сутки (daily) или неделя
+// (с понедельника, как date_trunc('week') в миграции) в UTC
+func eventPartitionFor(t time.Time, daily bool) EventPartition {
+	t = t.UTC()
+	from := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	if !daily {
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		to = from.AddDate(0, 0, 7)
+	}
+	return EventPartition{
+		Name: "anpr_events_" + from.Format(eventPartitionDateLayout) + "_" + to.Format(eventPartitionDateLayout),
+		From: from,
+		To:   to,
+	}
+}
+
+func (p EventPartition) overlaps(other EventPartition) bool {
+	return p.From.Before(other.To) && other.From.Before(p.To)
+}
+
+// ListEventPartitions возвращает секции anpr_events по возрастанию диапазона (без секции по умолчанию)
+func (r *ANPRRepository) ListEventPartitions(ctx context.Context) ([]EventPartition, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'anpr_events'::regclass`).
+		Scan(&names).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event partitions: %w", err)
+	}
+
+	partitions := make([]EventPartition, 0, len(names))
+	for _, name := range names {
+		if partition, ok := parseEventPartition(name); ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].From.Before(partitions[j].From) })
+	return partitions, nil
+}
+
+// EnsureEventPartitions создаёт недостающие секции, покрывающие [from, to]. Диапазоны, которые
+// пересекаются с уже существующими секциями (например, недельными при переходе на суточные), пропускаются.
+// Возвращает имена созданных секций.
+func (r *ANPRRepository) EnsureEventPartitions(ctx context.Context, from, to time.Time, daily bool) ([]string, error) {
+	existing, err := r.ListEventPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	var errs []error
+	for t := from; !t.After(to); {
+		partition := eventPartitionFor(t, daily)
+		t = partition.To
+
+		overlaps := false
+		for _, e := range existing {
+			if partition.overlaps(e) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		// Если в секции по умолчанию уже есть события из этого диапазона, PostgreSQL откажет в создании —
+		// ошибка возвращается, но остальные секции создаются
+		err := r.db.WithContext(ctx).Exec(fmt.Sprintf(
+			`CREATE TABLE %s PARTITION OF anpr_events FOR VALUES FROM ('%s') TO ('%s')`,
+			partition.Name, partition.From.Format(time.RFC3339), partition.To.Format(time.RFC3339),
+		)).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("create partition %s: %w", partition.Name, err))
+			continue
+		}
+		existing = append(existing, partition)
+		created = append(created, partition.Name)
+	}
+	return created, errors.Join(errs...)
+}
+
+// DeleteOldEvents удаляет события с event_time старше указанного количества дней.
+// Секции, целиком попадающие в удаляемый период, удаляются через DROP TABLE (без роста таблицы и VACUUM),
+// остаток граничной секции и секции по умолчанию удаляется обычным DELETE. Фото и комментарии удаляются
+// вместе с событиями.
+// Удаление физическое, включая мягко удалённые события: используется квотой БД, когда нужно освободить место.
+func (r *ANPRRepository) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
+	cutoff := r.clock.Now().AddDate(0, 0, -days)
+
+	partitions, err := r.ListEventPartitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			break
+		}
+		count, err := r.dropEventPartition(ctx, partition)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += count
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM anpr_event_photos
+			WHERE event_id IN (SELECT id FROM anpr_events WHERE event_time < ?)`, cutoff).Error; err != nil {
+			return fmt.Errorf("delete photos of old events: %w", err)
+		}
+		if err := tx.Exec(`DELETE FROM anpr_event_comments
+			WHERE event_id IN (SELECT id FROM anpr_events WHERE event_time < ?)`, cutoff).Error; err != nil {
+			return fmt.Errorf("delete comments of old events: %w", err)
+		}
+		result := tx.Unscoped().Where("event_time < ?", cutoff).Delete(&ANPREvent{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// dropEventPartition удаляет секцию вместе с фото и комментариями её событий и возвращает число удалённых событий
+func (r *ANPRRepository) dropEventPartition(ctx context.Context, partition EventPartition) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, partition.Name)).Scan(&count).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf(`DELETE FROM anpr_event_photos
+			WHERE event_id IN (SELECT id FROM %s)`, partition.Name)).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf(`DELETE FROM anpr_event_comments
+			WHERE event_id IN (SELECT id FROM %s)`, partition.Name)).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf(`DROP TABLE %s`, partition.Name)).Error; err != nil {
+			return err
+		}
+		// DROP TABLE не вызывает триггеры, поэтому версию данных для опроса увеличиваем явно
+		return tx.Exec(`SELECT anpr_touch_data_version(?)`, DataVersionScopeEvents).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop event partition %s: %w", partition.Name, err)
+	}
+	return count, nil
+}
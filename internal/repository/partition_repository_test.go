@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventPartitionFor(t *testing.T) {
+	// 2025-01-08 — среда; в Алматы (UTC+5) уже наступили сутки, в UTC ещё 7-е число
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+
+	tests := []struct {
+		name  string
+		t     time.Time
+		daily bool
+		want  string
+	}{
+		{name: "week starts on monday", t: time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC), want: "anpr_events_20250106_20250113"},
+		{name: "monday midnight", t: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), want: "anpr_events_20250106_20250113"},
+		{name: "sunday", t: time.Date(2025, 1, 12, 23, 59, 0, 0, time.UTC), want: "anpr_events_20250106_20250113"},
+		{name: "day", t: time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC), daily: true, want: "anpr_events_20250108_20250109"},
+		{name: "bounds are utc", t: time.Date(2025, 1, 8, 2, 0, 0, 0, almaty), daily: true, want: "anpr_events_20250107_20250108"},
+		{name: "week across year", t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), want: "anpr_events_20241230_20250106"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventPartitionFor(tt.t, tt.daily)
+			if got.Name != tt.want {
+				t.Fatalf("eventPartitionFor(%v) = %s, want %s", tt.t, got.Name, tt.want)
+			}
+			if tt.t.Before(got.From) || !tt.t.Before(got.To) {
+				t.Errorf("%v is outside [%v, %v)", tt.t, got.From, got.To)
+			}
+			parsed, ok := parseEventPartition(got.Name)
+			if !ok || !parsed.From.Equal(got.From) || !parsed.To.Equal(got.To) {
+				t.Errorf("parseEventPartition(%s) = %+v, %v", got.Name, parsed, ok)
+			}
+		})
+	}
+}
+
+func TestParseEventPartition(t *testing.T) {
+	for _, name := range []string{"anpr_events_default", "anpr_events_20250113_20250106", "anpr_events_2025010_20250113", "anpr_events"} {
+		if _, ok := parseEventPartition(name); ok {
+			t.Errorf("parseEventPartition(%q) = ok, want rejected", name)
+		}
+	}
+}
+
+func TestEventPartitionOverlaps(t *testing.T) {
+	week := eventPartitionFor(time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), false)
+	if !eventPartitionFor(time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), true).overlaps(week) {
+		t.Error("day inside the week must overlap")
+	}
+	if eventPartitionFor(time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), true).overlaps(week) {
+		t.Error("day right after the week must not overlap")
+	}
+}
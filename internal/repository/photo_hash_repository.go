@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// photoHashCandidateLimit — сколько совпавших по хешу фото рассматривается при проверке нового снимка
+const photoHashCandidateLimit = 50
+
+// PhotoHash — хеши фото события и ранее присланное фото, с которым оно совпало
+type PhotoHash struct {
+	ID                  uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EventID             uuid.UUID  `gorm:"type:uuid;not null"`
+	EventTime           time.Time  `gorm:"not null"`
+	CameraID            string     `gorm:"not null"`
+	Plate               string     `gorm:"not null"`
+	PhotoURL            string     `gorm:"not null"`
+	SHA256              string     `gorm:"column:sha256;not null"`
+	PHash               *int64     `gorm:"column:phash"`
+	DuplicateOfEventID  *uuid.UUID `gorm:"type:uuid"`
+	DuplicateOfPhotoURL *string
+	HashDistance        *int
+	CreatedAt           time.Time
+}
+
+func (PhotoHash) TableName() string {
+	return "anpr_photo_hashes"
+}
+
+// PhotoDuplicate — фото события, совпавшее с фото другого, ранее присланного события
+type PhotoDuplicate struct {
+	EventID              uuid.UUID `gorm:"column:event_id"`
+	EventTime            time.Time `gorm:"column:event_time"`
+	CameraID             string    `gorm:"column:camera_id"`
+	Plate                string    `gorm:"column:plate"`
+	PhotoURL             string    `gorm:"column:photo_url"`
+	HashDistance         int       `gorm:"column:hash_distance"`
+	Exact                bool      `gorm:"column:exact"` // побайтовая копия (совпал SHA-256)
+	DuplicateOfEventID   uuid.UUID `gorm:"column:duplicate_of_event_id"`
+	DuplicateOfPhotoURL  string    `gorm:"column:duplicate_of_photo_url"`
+	DuplicateOfEventTime time.Time `gorm:"column:duplicate_of_event_time"`
+	DuplicateOfCameraID  string    `gorm:"column:duplicate_of_camera_id"`
+	DuplicateOfPlate     string    `gorm:"column:duplicate_of_plate"`
+}
+
+// PhotoDuplicateFilter — фильтры списка совпадений фото
+type PhotoDuplicateFilter struct {
+	From     time.Time
+	To       time.Time
+	CameraID *string
+	// ExactOnly — только побайтовые копии (без почти дубликатов)
+	ExactOnly bool
+	Limit     int
+	Offset    int
+}
+
+// CreatePhotoHash сохраняет хеши фото
+func (r *ANPRRepository) CreatePhotoHash(ctx context.Context, hash *PhotoHash) error {
+	if hash.ID == uuid.Nil {
+		hash.ID = r.ids.NewID()
+	}
+	hash.CreatedAt = r.clock.Now()
+	if err := r.db.WithContext(ctx).Create(hash).Error; err != nil {
+		return fmt.Errorf("failed to save photo hash: %w", err)
+	}
+	return nil
+}
+
+// FindPhotoHashCandidates возвращает фото других событий с тем же SHA-256 или с совпадающей
+// 16-битной частью перцептивного хеша (кандидаты в почти дубликаты), самые ранние первыми
+func (r *ANPRRepository) FindPhotoHashCandidates(ctx context.Context, sha256 string, phash *int64, excludeEventID uuid.UUID) ([]PhotoHash, error) {
+	query := r.db.WithContext(ctx).Where("event_id <> ?", excludeEventID)
+	if phash != nil {
+		query = query.Where(`sha256 = ?
+			OR phash & 65535 = ? & 65535
+			OR (phash >> 16) & 65535 = (?::bigint >> 16) & 65535
+			OR (phash >> 32) & 65535 = (?::bigint >> 32) & 65535
+			OR (phash >> 48) & 65535 = (?::bigint >> 48) & 65535`,
+			sha256, *phash, *phash, *phash, *phash)
+	} else {
+		query = query.Where("sha256 = ?", sha256)
+	}
+
+	var candidates []PhotoHash
+	err := query.Order("created_at ASC").Limit(photoHashCandidateLimit).Find(&candidates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find photo hash candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// ListPhotoDuplicates возвращает совпавшие фото за период по времени события, новые первыми
+func (r *ANPRRepository) ListPhotoDuplicates(ctx context.Context, filter PhotoDuplicateFilter) ([]PhotoDuplicate, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_photo_hashes h").
+		Select(`
+			h.event_id, h.event_time, h.camera_id, h.plate, h.photo_url, h.hash_distance,
+			h.duplicate_of_event_id, h.duplicate_of_photo_url, o.sha256 = h.sha256 AS exact,
+			o.event_time AS duplicate_of_event_time, o.camera_id AS duplicate_of_camera_id, o.plate AS duplicate_of_plate
+		`).
+		Joins(`JOIN LATERAL (
+			SELECT event_time, camera_id, plate, sha256 FROM anpr_photo_hashes
+			WHERE event_id = h.duplicate_of_event_id AND photo_url = h.duplicate_of_photo_url
+			LIMIT 1
+		) o ON true`).
+		Where("h.duplicate_of_event_id IS NOT NULL").
+		Where("h.event_time >= ? AND h.event_time <= ?", filter.From, filter.To)
+	if filter.CameraID != nil {
+		query = query.Where("h.camera_id = ?", *filter.CameraID)
+	}
+	if filter.ExactOnly {
+		query = query.Where("o.sha256 = h.sha256")
+	}
+	query = query.Order("h.event_time DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var duplicates []PhotoDuplicate
+	if err := query.Scan(&duplicates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list photo duplicates: %w", err)
+	}
+	return duplicates, nil
+}
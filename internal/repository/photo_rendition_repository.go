@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// PhotoRendition — уменьшенные копии фото и размер оригинала
+type PhotoRendition struct {
+	PhotoURL     string `gorm:"primaryKey"`
+	ThumbnailURL *string
+	MediumURL    *string
+	Width        int `gorm:"not null"`
+	Height       int `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+func (PhotoRendition) TableName() string {
+	return "anpr_photo_renditions"
+}
+
+// UpsertPhotoRendition сохраняет копии фото; повторная загрузка того же фото их перезаписывает
+func (r *ANPRRepository) UpsertPhotoRendition(ctx context.Context, rendition *PhotoRendition) error {
+	rendition.CreatedAt = r.clock.Now()
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "photo_url"}},
+			DoUpdates: clause.AssignmentColumns([]string{"thumbnail_url", "medium_url", "width", "height", "created_at"}),
+		}).
+		Create(rendition).Error
+	if err != nil {
+		return fmt.Errorf("failed to save photo rendition: %w", err)
+	}
+	return nil
+}
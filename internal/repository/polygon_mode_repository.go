@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PolygonModeChange — переключение режима работы полигона (журнал аудита, записи не меняются)
+type PolygonModeChange struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PolygonID uuid.UUID `gorm:"type:uuid"`
+	Mode      string
+	Reason    *string
+	// ChangedBy — пользователь, переключивший режим
+	ChangedBy uuid.UUID `gorm:"type:uuid"`
+	ChangedAt time.Time
+}
+
+func (PolygonModeChange) TableName() string {
+	return "anpr_polygon_mode_changes"
+}
+
+// CreatePolygonModeChange записывает переключение режима полигона
+func (r *ANPRRepository) CreatePolygonModeChange(ctx context.Context, change *PolygonModeChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = r.ids.NewID()
+	}
+	if change.ChangedAt.IsZero() {
+		change.ChangedAt = r.clock.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(change).Error; err != nil {
+		return fmt.Errorf("failed to create polygon mode change: %w", err)
+	}
+	return nil
+}
+
+// GetPolygonMode возвращает последнее переключение режима полигона не позже at; nil — режим не переключался
+func (r *ANPRRepository) GetPolygonMode(ctx context.Context, polygonID uuid.UUID, at time.Time) (*PolygonModeChange, error) {
+	var change PolygonModeChange
+	err := r.db.WithContext(ctx).
+		Where("polygon_id = ? AND changed_at <= ?", polygonID, at).
+		Order("changed_at DESC").
+		First(&change).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polygon mode: %w", err)
+	}
+	return &change, nil
+}
+
+// ListCurrentPolygonModes возвращает последнее переключение режима каждого полигона, у которого оно было
+func (r *ANPRRepository) ListCurrentPolygonModes(ctx context.Context) ([]PolygonModeChange, error) {
+	var changes []PolygonModeChange
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (polygon_id) * FROM anpr_polygon_mode_changes ORDER BY polygon_id, changed_at DESC`).
+		Scan(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list polygon modes: %w", err)
+	}
+	return changes, nil
+}
+
+// ListPolygonModeChanges возвращает переключения режимов до until по возрастанию времени; polygonID nil —
+// всех полигонов. Для отчёта за период нужны и переключения до его начала: они задают режим на начало.
+func (r *ANPRRepository) ListPolygonModeChanges(ctx context.Context, polygonID *uuid.UUID, until time.Time) ([]PolygonModeChange, error) {
+	query := r.db.WithContext(ctx).Where("changed_at <= ?", until)
+	if polygonID != nil {
+		query = query.Where("polygon_id = ?", *polygonID)
+	}
+	var changes []PolygonModeChange
+	if err := query.Order("changed_at ASC, id ASC").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list polygon mode changes: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Polygon — полигон (место складирования снега), на котором стоят камеры
+type Polygon struct {
+	ID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name string
+	// OrganizationID — организация-оператор полигона; её пользователи LANDFILL_* видят только события полигона
+	OrganizationID *uuid.UUID `gorm:"type:uuid"`
+	// Latitude, Longitude — координаты полигона для погоды; заданы обе или ни одной
+	Latitude  *float64
+	Longitude *float64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Polygon) TableName() string {
+	return "anpr_polygons"
+}
+
+// ListPolygons возвращает все полигоны по имени
+func (r *ANPRRepository) ListPolygons(ctx context.Context) ([]Polygon, error) {
+	var polygons []Polygon
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&polygons).Error; err != nil {
+		return nil, fmt.Errorf("failed to list polygons: %w", err)
+	}
+	return polygons, nil
+}
+
+// GetPolygon возвращает полигон; ErrPolygonNotFound — полигона нет
+func (r *ANPRRepository) GetPolygon(ctx context.Context, id uuid.UUID) (*Polygon, error) {
+	var polygon Polygon
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&polygon).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrPolygonNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polygon: %w", err)
+	}
+	return &polygon, nil
+}
+
+// GetPolygonByName ищет полигон по имени без учёта регистра; ErrPolygonNotFound — полигона нет
+func (r *ANPRRepository) GetPolygonByName(ctx context.Context, name string) (*Polygon, error) {
+	var polygon Polygon
+	err := r.db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).First(&polygon).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrPolygonNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polygon by name: %w", err)
+	}
+	return &polygon, nil
+}
+
+// CreatePolygon добавляет полигон
+func (r *ANPRRepository) CreatePolygon(ctx context.Context, polygon *Polygon) error {
+	now := r.clock.Now()
+	if polygon.ID == uuid.Nil {
+		polygon.ID = r.ids.NewID()
+	}
+	polygon.CreatedAt = now
+	polygon.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(polygon).Error; err != nil {
+		return fmt.Errorf("failed to create polygon: %w", err)
+	}
+	return nil
+}
+
+// UpdatePolygon сохраняет имя и организацию полигона
+func (r *ANPRRepository) UpdatePolygon(ctx context.Context, polygon *Polygon) error {
+	polygon.UpdatedAt = r.clock.Now()
+	if err := r.db.WithContext(ctx).Save(polygon).Error; err != nil {
+		return fmt.Errorf("failed to update polygon: %w", err)
+	}
+	return nil
+}
+
+// DeletePolygon удаляет полигон: камеры отвязываются, закрепления подрядчиков удаляются,
+// polygon_id сохранённых событий не меняется. false — полигона не было.
+func (r *ANPRRepository) DeletePolygon(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&Polygon{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete polygon: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindPolygonIDs возвращает те из ids, что есть в реестре полигонов
+func (r *ANPRRepository) FindPolygonIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&Polygon{}).
+		Where("id IN ?", ids).
+		Pluck("id", &found).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find polygons: %w", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DatabaseSize — размер БД и таблицы событий (вместе с индексами и TOAST)
+type DatabaseSize struct {
+	DatabaseBytes int64 `gorm:"column:database_bytes"`
+	EventsBytes   int64 `gorm:"column:events_bytes"`
+}
+
+// GetDatabaseSize возвращает текущий размер БД и anpr_events (сумма по всем секциям)
+func (r *ANPRRepository) GetDatabaseSize(ctx context.Context) (*DatabaseSize, error) {
+	var size DatabaseSize
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT pg_database_size(current_database()) AS database_bytes,
+			(SELECT COALESCE(SUM(pg_total_relation_size(inhrelid)), 0)
+			 FROM pg_inherits WHERE inhparent = 'anpr_events'::regclass) AS events_bytes`).
+		Scan(&size).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+	return &size, nil
+}
+
+// GetOldestEventTime возвращает event_time самого старого события (nil — событий нет).
+// Срок хранения считается по event_time — так же, как секционирована anpr_events. Мягко удалённые
+// события учитываются: до очистки они занимают место в БД.
+func (r *ANPRRepository) GetOldestEventTime(ctx context.Context) (*time.Time, error) {
+	var oldest *time.Time
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&ANPREvent{}).
+		Select("MIN(event_time)").
+		Scan(&oldest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest event: %w", err)
+	}
+	return oldest, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventDerivedColumns — колонки события, которые вычисляются разбором payload камеры и
+// пересчитываются при повторной обработке
+var EventDerivedColumns = []string{
+	"camera_model",
+	"direction",
+	"direction_source",
+	"lane",
+	"confidence",
+	"vehicle_color",
+	"vehicle_type",
+	"vehicle_type_raw",
+	"vehicle_brand",
+	"vehicle_model",
+	"vehicle_country",
+	"vehicle_plate_color",
+	"vehicle_speed",
+	"snapshot_url",
+}
+
+// ListRawPayloadEvents возвращает до limit событий [from, to) с уведомлением камеры (XML или JSON)
+// в raw_payload или с payload, вынесенным в хранилище. События идут по (event_time, id) и начинаются
+// после (afterTime, afterID).
+func (r *ANPRRepository) ListRawPayloadEvents(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]ANPREvent, error) {
+	var events []ANPREvent
+	err := r.db.WithContext(ctx).
+		Where("event_time >= ? AND event_time < ?", from, to).
+		Where("raw_payload->>'xml' IS NOT NULL OR raw_payload->>'hikvision_json' IS NOT NULL OR raw_payload_key IS NOT NULL").
+		Where("(event_time, id) > (?, ?)", afterTime, afterID).
+		Order("event_time ASC, id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events with raw payload: %w", err)
+	}
+	return events, nil
+}
+
+// ListEventsForExport возвращает до limit неудалённых событий [from, to) по (event_time, id) после
+// (afterTime, afterID) — для постраничной выгрузки без сдвига страниц
+func (r *ANPRRepository) ListEventsForExport(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]ANPREvent, error) {
+	var events []ANPREvent
+	err := r.db.WithContext(ctx).
+		Where("event_time >= ? AND event_time < ?", from, to).
+		Where("(event_time, id) > (?, ?)", afterTime, afterID).
+		Order("event_time ASC, id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events for export: %w", err)
+	}
+	return events, nil
+}
+
+// UpdateEventDerivedFields сохраняет EventDerivedColumns события, включая обнулённые значения
+func (r *ANPRRepository) UpdateEventDerivedFields(ctx context.Context, event *ANPREvent) error {
+	err := r.db.WithContext(ctx).
+		Model(event).
+		Where("event_time = ?", event.EventTime).
+		Select(EventDerivedColumns).
+		Updates(event).Error
+	if err != nil {
+		return fmt.Errorf("failed to update event derived fields: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SavedSearch — сохранённые фильтры списка событий пользователя и расписание их выгрузки
+type SavedSearch struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null"`
+	// OrganizationID — организация пользователя полигона: выгрузка ограничена её полигонами, как и его запросы
+	OrganizationID *uuid.UUID `gorm:"type:uuid"`
+	Name           string     `gorm:"not null"`
+	// Filters — параметры запроса /api/v1/events (JSON-объект)
+	Filters datatypes.JSON `gorm:"type:jsonb;not null"`
+	// Schedule, SendAt, Channel, Target — расписание и адрес доставки; nil — без расписания
+	Schedule  *string
+	SendAt    *string
+	Channel   *string
+	Target    *string
+	NextRunAt *time.Time
+	LastRunAt *time.Time
+	LastError *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SavedSearch) TableName() string {
+	return "anpr_saved_searches"
+}
+
+// CreateSavedSearch сохраняет новый поиск
+func (r *ANPRRepository) CreateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	now := r.clock.Now()
+	if search.ID == uuid.Nil {
+		search.ID = r.ids.NewID()
+	}
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(search).Error; err != nil {
+		return fmt.Errorf("failed to create saved search: %w", err)
+	}
+	return nil
+}
+
+// UpdateSavedSearch сохраняет все поля поиска
+func (r *ANPRRepository) UpdateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	search.UpdatedAt = r.clock.Now()
+	if err := r.db.WithContext(ctx).Save(search).Error; err != nil {
+		return fmt.Errorf("failed to update saved search: %w", err)
+	}
+	return nil
+}
+
+// GetSavedSearch получает поиск; ErrSavedSearchNotFound — его нет
+func (r *ANPRRepository) GetSavedSearch(ctx context.Context, id uuid.UUID) (*SavedSearch, error) {
+	var search SavedSearch
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&search).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return &search, nil
+}
+
+// ListSavedSearches возвращает поиски пользователя в порядке создания
+func (r *ANPRRepository) ListSavedSearches(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&searches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// CountSavedSearches возвращает число поисков пользователя
+func (r *ANPRRepository) CountSavedSearches(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteSavedSearch удаляет поиск пользователя; false — у пользователя такого нет
+func (r *ANPRRepository) DeleteSavedSearch(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&SavedSearch{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListDueSavedSearches возвращает до limit поисков, выгрузке которых пора уйти, начиная с самых давних
+func (r *ANPRRepository) ListDueSavedSearches(ctx context.Context, now time.Time, limit int) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("next_run_at IS NOT NULL AND next_run_at <= ?", now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Find(&searches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// FinishSavedSearchRun записывает итог выгрузки: время, следующую выгрузку и ошибку (nil — успешно).
+// Если расписание успели изменить, следующая выгрузка не переписывается.
+func (r *ANPRRepository) FinishSavedSearchRun(ctx context.Context, id uuid.UUID, scheduledAt, ranAt, nextRunAt time.Time, runErr *string) error {
+	err := r.db.WithContext(ctx).Model(&SavedSearch{}).
+		Where("id = ? AND next_run_at = ?", id, scheduledAt).
+		Updates(map[string]interface{}{
+			"last_run_at": ranAt,
+			"next_run_at": nextRunAt,
+			"last_error":  runErr,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record saved search run: %w", err)
+	}
+	return nil
+}
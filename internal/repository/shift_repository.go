@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// inShiftSQL — событие e попадает в окно своей смены по календарю смен (функция anpr_event_shift)
+const inShiftSQL = "EXISTS (SELECT 1 FROM anpr_event_shift(e.polygon_id, e.event_time) sh WHERE sh.in_shift)"
+
+// Shift — смена календаря смен: окно работы полигона (или всех полигонов) на сезон
+type Shift struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	// PolygonID — полигон смены; nil — смена всех полигонов, у которых нет собственной
+	PolygonID *uuid.UUID `gorm:"type:uuid"`
+	Name      string
+	// ValidFrom, ValidTo — сезон: даты начала смен, ValidTo включительно (nil — бессрочно)
+	ValidFrom time.Time  `gorm:"type:date"`
+	ValidTo   *time.Time `gorm:"type:date"`
+	// StartMinute, EndMinute — начало и конец смены в минутах от полуночи в поясе TimeZone;
+	// конец не позже начала — смена через полночь
+	StartMinute int
+	EndMinute   int
+	TimeZone    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (Shift) TableName() string {
+	return "anpr_shifts"
+}
+
+// ShiftStat — рейсы смены: ShiftID и ShiftDate (дата начала смены) пустые у рейсов вне окна своей смены
+// или без смены в календаре
+type ShiftStat struct {
+	ShiftID     *uuid.UUID `gorm:"column:shift_id"`
+	ShiftDate   *time.Time `gorm:"column:shift_date"`
+	TotalVolume float64    `gorm:"column:total_volume"`
+	TripCount   int64      `gorm:"column:trip_count"`
+}
+
+// ListShifts возвращает смены календаря: общие, затем по полигонам, по началу сезона
+func (r *ANPRRepository) ListShifts(ctx context.Context) ([]Shift, error) {
+	var shifts []Shift
+	err := r.db.WithContext(ctx).Order("polygon_id NULLS FIRST, valid_from ASC").Find(&shifts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shifts: %w", err)
+	}
+	return shifts, nil
+}
+
+// GetShift возвращает смену; ErrShiftNotFound — смены нет
+func (r *ANPRRepository) GetShift(ctx context.Context, id uuid.UUID) (*Shift, error) {
+	var shift Shift
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&shift).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrShiftNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+	return &shift, nil
+}
+
+// CreateShift добавляет смену в календарь
+func (r *ANPRRepository) CreateShift(ctx context.Context, shift *Shift) error {
+	now := r.clock.Now()
+	if shift.ID == uuid.Nil {
+		shift.ID = r.ids.NewID()
+	}
+	shift.CreatedAt = now
+	shift.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(shift).Error; err != nil {
+		return fmt.Errorf("failed to create shift: %w", err)
+	}
+	return nil
+}
+
+// UpdateShift сохраняет смену
+func (r *ANPRRepository) UpdateShift(ctx context.Context, shift *Shift) error {
+	shift.UpdatedAt = r.clock.Now()
+	if err := r.db.WithContext(ctx).Save(shift).Error; err != nil {
+		return fmt.Errorf("failed to update shift: %w", err)
+	}
+	return nil
+}
+
+// DeleteShift удаляет смену; false — смены не было
+func (r *ANPRRepository) DeleteShift(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&Shift{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete shift: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetShiftStats возвращает объём и число рейсов по сменам. Рейсы отбираются так же, как в GetReportStats;
+// смена события определяется по календарю смен его полигона (anpr_event_shift).
+func (r *ANPRRepository) GetShiftStats(ctx context.Context, filters ReportFilters) ([]ShiftStat, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			CASE WHEN sh.in_shift THEN sh.shift_id END AS shift_id,
+			CASE WHEN sh.in_shift THEN sh.shift_date END AS shift_date,
+			COALESCE(SUM(e.snow_volume_m3), 0) AS total_volume,
+			COUNT(*) AS trip_count
+		`).
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
+		Joins("LEFT JOIN LATERAL anpr_event_shift(e.polygon_id, e.event_time) sh ON TRUE")
+
+	query = applyReportFilters(query, filters)
+
+	var rows []ShiftStat
+	err := query.Group("1, 2").Order("2 NULLS LAST, 1").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift stats: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HourlyEventCount — события камеры за час (UTC) из anpr_event_hourly_counts
+type HourlyEventCount struct {
+	Bucket   time.Time `gorm:"column:bucket"`
+	CameraID string    `gorm:"column:camera_id"`
+	Events   int64     `gorm:"column:events"`
+	Entries  int64     `gorm:"column:entries"`
+	Exits    int64     `gorm:"column:exits"`
+}
+
+// RefreshHourlyEventCounts пересчитывает почасовые счётчики событий начиная с часа, в который попадает
+// from: счётчики этих часов заменяются целиком, поэтому учитываются и поздние, и удалённые события.
+// Возвращает число записанных строк.
+func (r *ANPRRepository) RefreshHourlyEventCounts(ctx context.Context, from time.Time) (int64, error) {
+	from = from.UTC().Truncate(time.Hour)
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM anpr_event_hourly_counts WHERE bucket >= ?", from).Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			INSERT INTO anpr_event_hourly_counts (bucket, camera_id, polygon_id, events, entries, exits, updated_at)
+			SELECT
+				date_trunc('hour', event_time AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+				camera_id,
+				COALESCE(polygon_id, '00000000-0000-0000-0000-000000000000'::uuid),
+				COUNT(*),
+				COUNT(*) FILTER (WHERE direction = 'entry'),
+				COUNT(*) FILTER (WHERE direction = 'exit'),
+				?
+			FROM anpr_events
+			WHERE deleted_at IS NULL AND event_time >= ?
+			GROUP BY 1, 2, 3
+		`, r.clock.Now(), from)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh hourly event counts: %w", err)
+	}
+	return rows, nil
+}
+
+// GetHourlyEventCounts возвращает почасовые счётчики камер за [From, To), суммированные по полигонам
+func (r *ANPRRepository) GetHourlyEventCounts(ctx context.Context, filters TrafficFilters) ([]HourlyEventCount, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_event_hourly_counts AS c").
+		Select("c.bucket, c.camera_id, SUM(c.events) AS events, SUM(c.entries) AS entries, SUM(c.exits) AS exits").
+		Where("c.bucket >= ? AND c.bucket < ?", filters.From, filters.To)
+	if filters.CameraID != nil {
+		query = query.Where("c.camera_id = ?", *filters.CameraID)
+	}
+	if filters.PolygonID != nil {
+		query = query.Where("c.polygon_id = ?", *filters.PolygonID)
+	}
+	if filters.PolygonOrgID != nil {
+		query = query.Where("c.polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *filters.PolygonOrgID)
+	}
+
+	var rows []HourlyEventCount
+	if err := query.Group("c.bucket, c.camera_id").Order("c.bucket, c.camera_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get hourly event counts: %w", err)
+	}
+	return rows, nil
+}
+
+// GetHourlyEventCountsRefreshedAt возвращает время последнего пересчёта счётчиков; nil — ещё не считались
+func (r *ANPRRepository) GetHourlyEventCountsRefreshedAt(ctx context.Context) (*time.Time, error) {
+	var refreshedAt *time.Time
+	err := r.db.WithContext(ctx).Raw("SELECT MAX(updated_at) FROM anpr_event_hourly_counts").Scan(&refreshedAt).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly event counts refresh time: %w", err)
+	}
+	return refreshedAt, nil
+}
+
+// IngestLatencyStat — задержка записи событий камеры в БД (created_at − event_time) за период.
+// CameraID пустой — итог по всем камерам.
+type IngestLatencyStat struct {
+	CameraID     *string `gorm:"column:camera_id"`
+	Events       int64   `gorm:"column:events"`
+	WithinTarget int64   `gorm:"column:within_target"`
+	P50          float64 `gorm:"column:p50"`
+	P95          float64 `gorm:"column:p95"`
+	P99          float64 `gorm:"column:p99"`
+}
+
+// GetIngestLatencyStats возвращает перцентили задержки (в секундах) и число событий, записанных не позже
+// target после event_time, по камерам и итог. Учитываются события камер (source = camera), принятые с since.
+func (r *ANPRRepository) GetIngestLatencyStats(ctx context.Context, since time.Time, target time.Duration) ([]IngestLatencyStat, error) {
+	var rows []IngestLatencyStat
+	err := r.db.WithContext(ctx).Raw(`
+		WITH latency AS (
+			SELECT camera_id, GREATEST(EXTRACT(EPOCH FROM created_at - event_time), 0) AS seconds
+			FROM anpr_events
+			WHERE received_at >= ? AND source = 'camera' AND deleted_at IS NULL
+		)
+		SELECT camera_id,
+			COUNT(*) AS events,
+			COUNT(*) FILTER (WHERE seconds <= ?) AS within_target,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds), 0) AS p50,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY seconds), 0) AS p95,
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY seconds), 0) AS p99
+		FROM latency
+		GROUP BY GROUPING SETS ((camera_id), ())
+		ORDER BY camera_id NULLS FIRST
+	`, since, target.Seconds()).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest latency stats: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -destination=mocks/store_mock.go -package=mocks . ANPRStore
+
+// EventStore — хранение событий распознавания, их фото и отклонённых событий
+type EventStore interface {
+	SaveANPREvent(ctx context.Context, event *anpr.Event, country, region string, contractorID, polygonID *uuid.UUID, photoURLs []string) (uuid.UUID, error)
+	CreateRejectedEvent(ctx context.Context, eventID uuid.UUID, plateID *uuid.UUID, reason string, normalizedPlate, rawPlate, cameraID string, eventTime time.Time, payload *anpr.EventPayload, photoURLs []string) error
+	ExistsRecentEvent(ctx context.Context, normalizedPlate, cameraID string, eventTime time.Time, window time.Duration) (bool, error)
+	GetEventByID(ctx context.Context, eventID uuid.UUID) (*ANPREvent, error)
+	GetEventPhotos(ctx context.Context, eventID uuid.UUID) ([]EventPhoto, error)
+	CreateEventComment(ctx context.Context, comment *EventComment) error
+	ListEventComments(ctx context.Context, eventID uuid.UUID) ([]EventComment, error)
+	UpsertPhotoRendition(ctx context.Context, rendition *PhotoRendition) error
+	FindEvents(ctx context.Context, search EventSearch) ([]ANPREvent, error)
+	StreamEvents(ctx context.Context, search EventSearch, fn func(*ANPREvent) error) error
+	FindEventsByPlateAndTime(ctx context.Context, normalizedPlate string, from, to time.Time, direction *string) ([]ANPREvent, error)
+	FindPlateEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]ANPREvent, error)
+	FindPlateRejectedEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]RejectedEvent, error)
+	ListUnmatchedPlates(ctx context.Context, from, to time.Time, limit, offset int) ([]UnmatchedPlate, error)
+	GetRejectedPhotoSamples(ctx context.Context, plateIDs []uuid.UUID, from, to time.Time, perPlate int) ([]RejectedPhotos, error)
+	CountAllowedEntries(ctx context.Context, plateID uuid.UUID, from, to time.Time) (int64, error)
+	RepairTripQuota(ctx context.Context, repair TripQuotaRepair) ([]TripChange, error)
+	ListTripPlates(ctx context.Context, from, to time.Time) ([]TripPlate, error)
+	GetLastEventTimes(ctx context.Context, plateIDs []uuid.UUID) (map[uuid.UUID]time.Time, error)
+	DeleteOldEvents(ctx context.Context, days int) (int64, error)
+	SoftDeleteEventsBefore(ctx context.Context, before, deletedAt time.Time) (int64, error)
+	SoftDeleteAllEvents(ctx context.Context, deletedAt time.Time) (int64, error)
+	RestoreEvent(ctx context.Context, id uuid.UUID) (bool, error)
+	PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ListRawPayloadEvents(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]ANPREvent, error)
+	ListEventsForExport(ctx context.Context, from, to, afterTime time.Time, afterID uuid.UUID, limit int) ([]ANPREvent, error)
+	UpdateEventDerivedFields(ctx context.Context, event *ANPREvent) error
+	EnsureEventPartitions(ctx context.Context, from, to time.Time, daily bool) ([]string, error)
+	GetOldestEventTime(ctx context.Context) (*time.Time, error)
+	GetDatabaseSize(ctx context.Context) (*DatabaseSize, error)
+}
+
+// PlateStore — номера и связанные с ними данные из vehicles/drivers/organizations
+type PlateStore interface {
+	GetOrCreatePlate(ctx context.Context, normalized, original, country, region string) (uuid.UUID, error)
+	GetPlateByID(ctx context.Context, plateID uuid.UUID) (*Plate, error)
+	FindPlatesByNormalized(ctx context.Context, normalized string) ([]Plate, error)
+	SyncVehicleToWhitelist(ctx context.Context, plateNumber string) (uuid.UUID, error)
+	RemoveVehicleFromWhitelist(ctx context.Context, normalizedPlate string) (bool, error)
+	ListVehicleWhitelistPlates(ctx context.Context) ([]string, error)
+	GetVehicleByPlate(ctx context.Context, normalizedPlate string) (*VehicleData, error)
+	GetContractorByVehiclePlate(ctx context.Context, normalizedPlate string) (*ContractorData, error)
+	GetDriverByVehiclePlate(ctx context.Context, normalizedPlate string) (*DriverData, error)
+	ResolvePlateAlias(ctx context.Context, normalized string) (string, error)
+	ListPlateAliases(ctx context.Context, plateID uuid.UUID) ([]PlateAlias, error)
+	CreatePlateAlias(ctx context.Context, alias *PlateAlias) (bool, error)
+	DeletePlateAlias(ctx context.Context, plateID uuid.UUID, alias string) (bool, error)
+	MergePlates(ctx context.Context, targetID, sourceID uuid.UUID, note *string) (*PlateMergeResult, error)
+}
+
+// ListStore — списки номеров (whitelist/blacklist) и членство в них
+type ListStore interface {
+	FindListsForPlate(ctx context.Context, plateID uuid.UUID) ([]anpr.ListHit, error)
+	LoadListMembership(ctx context.Context) (map[uuid.UUID][]ListMembership, error)
+	GetList(ctx context.Context, listID uuid.UUID) (*List, error)
+	GetListByName(ctx context.Context, name string) (*List, error)
+	AddPlateToList(ctx context.Context, listID, plateID uuid.UUID, note *string) (bool, error)
+	GetListEntries(ctx context.Context, listID uuid.UUID, limit, offset int) ([]ListEntry, error)
+	GetListEntry(ctx context.Context, listID, plateID uuid.UUID) (*ListEntry, error)
+	UpsertListItem(ctx context.Context, item *ListItem) error
+	SetListItemValidity(ctx context.Context, listID, plateID uuid.UUID, validFrom, validUntil *time.Time) (bool, error)
+	RemoveListItem(ctx context.Context, listID, plateID uuid.UUID) (bool, error)
+	DeleteExpiredListItems(ctx context.Context, before time.Time) (int64, error)
+	ListLists(ctx context.Context) ([]ListSummary, error)
+	ListPlatesInList(ctx context.Context, listName string) ([]string, error)
+	GetPlateListHistory(ctx context.Context, plateID uuid.UUID, from, to time.Time) ([]ListHistoryEntry, error)
+}
+
+// CameraStore — реестр камер
+type CameraStore interface {
+	GetCamera(ctx context.Context, cameraID string) (*Camera, error)
+	ListCameras(ctx context.Context) ([]Camera, error)
+	ListCamerasWithLastEvent(ctx context.Context) ([]CameraLastEvent, error)
+	UpsertCamera(ctx context.Context, camera *Camera) error
+	ListWhitelistSyncCameras(ctx context.Context) ([]Camera, error)
+	MarkCameraWhitelistSynced(ctx context.Context, cameraID string, syncedAt time.Time) error
+	RecordCameraClockSkew(ctx context.Context, cameraID string, skewSeconds float64) error
+	RecordCameraSignal(ctx context.Context, cameraID string, receivedAt time.Time, videoLoss *bool) error
+	ResolvePolygonIDByCameraID(ctx context.Context, cameraID string) (*uuid.UUID, error)
+}
+
+// AccessRuleStore — правила доступа подрядчиков, квоты рейсов номеров и закрепление подрядчиков за полигонами
+type AccessRuleStore interface {
+	GetContractorAccessRule(ctx context.Context, contractorID uuid.UUID) (*ContractorAccessRule, error)
+	ListContractorAccessRules(ctx context.Context) ([]ContractorAccessRule, error)
+	UpsertContractorAccessRule(ctx context.Context, rule *ContractorAccessRule) error
+	GetPlateTripQuota(ctx context.Context, normalized string) (*PlateTripQuota, error)
+	ListPlateTripQuotas(ctx context.Context) ([]PlateTripQuota, error)
+	UpsertPlateTripQuota(ctx context.Context, quota *PlateTripQuota) error
+	DeletePlateTripQuota(ctx context.Context, normalized string) (bool, error)
+	ListContractorPolygons(ctx context.Context) ([]ContractorPolygon, error)
+	GetContractorPolygonIDs(ctx context.Context, contractorID uuid.UUID) ([]uuid.UUID, error)
+	ReplaceContractorPolygons(ctx context.Context, contractorID uuid.UUID, polygonIDs []uuid.UUID) error
+}
+
+// PolygonStore — реестр полигонов
+type PolygonStore interface {
+	ListPolygons(ctx context.Context) ([]Polygon, error)
+	GetPolygon(ctx context.Context, id uuid.UUID) (*Polygon, error)
+	GetPolygonByName(ctx context.Context, name string) (*Polygon, error)
+	CreatePolygon(ctx context.Context, polygon *Polygon) error
+	UpdatePolygon(ctx context.Context, polygon *Polygon) error
+	DeletePolygon(ctx context.Context, id uuid.UUID) (bool, error)
+	FindPolygonIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+}
+
+// PolygonModeStore — режимы работы полигонов (normal/storm) и журнал их переключений
+type PolygonModeStore interface {
+	CreatePolygonModeChange(ctx context.Context, change *PolygonModeChange) error
+	GetPolygonMode(ctx context.Context, polygonID uuid.UUID, at time.Time) (*PolygonModeChange, error)
+	ListCurrentPolygonModes(ctx context.Context) ([]PolygonModeChange, error)
+	ListPolygonModeChanges(ctx context.Context, polygonID *uuid.UUID, until time.Time) ([]PolygonModeChange, error)
+}
+
+// FeatureFlagStore — переопределения флагов возможностей
+type FeatureFlagStore interface {
+	ListFeatureFlagOverrides(ctx context.Context) ([]FeatureFlagOverride, error)
+	UpsertFeatureFlagOverride(ctx context.Context, override *FeatureFlagOverride) error
+	DeleteFeatureFlagOverride(ctx context.Context, flag, scope, scopeID string) (bool, error)
+}
+
+// ShiftStore — календарь смен и отчёт по сменам
+type ShiftStore interface {
+	ListShifts(ctx context.Context) ([]Shift, error)
+	GetShift(ctx context.Context, id uuid.UUID) (*Shift, error)
+	CreateShift(ctx context.Context, shift *Shift) error
+	UpdateShift(ctx context.Context, shift *Shift) error
+	DeleteShift(ctx context.Context, id uuid.UUID) (bool, error)
+	GetShiftStats(ctx context.Context, filters ReportFilters) ([]ShiftStat, error)
+}
+
+// WeatherStore — почасовая погода на полигонах
+type WeatherStore interface {
+	ListWeatherLocations(ctx context.Context) ([]WeatherLocation, error)
+	UpsertWeatherObservations(ctx context.Context, observations []WeatherObservation) error
+	ApplyWeatherToEvents(ctx context.Context, from, until time.Time) (int64, error)
+}
+
+// PhotoHashStore — хеши фото событий для поиска повторно присланных снимков
+type PhotoHashStore interface {
+	CreatePhotoHash(ctx context.Context, hash *PhotoHash) error
+	FindPhotoHashCandidates(ctx context.Context, sha256 string, phash *int64, excludeEventID uuid.UUID) ([]PhotoHash, error)
+	ListPhotoDuplicates(ctx context.Context, filter PhotoDuplicateFilter) ([]PhotoDuplicate, error)
+}
+
+// ReportStore — выборки для отчётов
+type ReportStore interface {
+	GetReportEvents(ctx context.Context, filters ReportFilters) ([]ReportEvent, error)
+	GetReportEventsForExcel(ctx context.Context, filters ReportFilters, pageSize, offset int) ([]ReportEvent, error)
+	CountReportEventsForExcel(ctx context.Context, filters ReportFilters) (int64, error)
+	GetReportStats(ctx context.Context, filters ReportFilters) (*ReportStats, error)
+	GetHourlyActivityStats(ctx context.Context, filters ReportFilters) ([]HourlyActivityStat, error)
+	GetVehicleTypeStats(ctx context.Context, filters ReportFilters) ([]VehicleTypeStat, error)
+	GetCameraSpeedStats(ctx context.Context, filters TrafficFilters) ([]CameraSpeedStat, error)
+	GetLaneUsageStats(ctx context.Context, filters TrafficFilters, interval, timezone string) ([]LaneUsageStat, error)
+	GetConfidenceStats(ctx context.Context, filters TrafficFilters) ([]ConfidenceStat, error)
+	RefreshHourlyEventCounts(ctx context.Context, from time.Time) (int64, error)
+	GetHourlyEventCounts(ctx context.Context, filters TrafficFilters) ([]HourlyEventCount, error)
+	GetHourlyEventCountsRefreshedAt(ctx context.Context) (*time.Time, error)
+	GetIngestLatencyStats(ctx context.Context, since time.Time, target time.Duration) ([]IngestLatencyStat, error)
+}
+
+// WebhookStore — подписки на вебхуки и очередь их доставок
+type WebhookStore interface {
+	CreateWebhookSubscription(ctx context.Context, sub *WebhookSubscription) error
+	UpdateWebhookSubscription(ctx context.Context, sub *WebhookSubscription) error
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error)
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (*WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, activeOnly bool) ([]WebhookSubscription, error)
+	EnqueueWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id uuid.UUID, statusCode int) error
+	MarkWebhookAttemptFailed(ctx context.Context, id uuid.UUID, statusCode *int, errMsg string, nextAttemptAt *time.Time) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit, offset int) ([]WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (bool, error)
+}
+
+// MQTTStore — outbox публикаций в MQTT
+type MQTTStore interface {
+	EnqueueMQTTMessage(ctx context.Context, msg *MQTTMessage) error
+	ClaimMQTTMessages(ctx context.Context, limit int, lease time.Duration) ([]MQTTMessage, error)
+	MarkMQTTMessageSent(ctx context.Context, id uuid.UUID) error
+	MarkMQTTAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error
+}
+
+// TelegramStore — outbox уведомлений в Telegram
+type TelegramStore interface {
+	EnqueueTelegramNotifications(ctx context.Context, notifications []TelegramNotification) error
+	ClaimTelegramNotifications(ctx context.Context, limit int, lease time.Duration) ([]TelegramNotification, error)
+	MarkTelegramNotificationSent(ctx context.Context, id uuid.UUID) error
+	MarkTelegramAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error
+}
+
+// SummaryStore — подписки на ночные сводки подрядчикам и выборки для них
+type SummaryStore interface {
+	GetSummarySubscription(ctx context.Context, userID uuid.UUID) (*SummarySubscription, error)
+	UpsertSummarySubscription(ctx context.Context, sub *SummarySubscription) error
+	ListEnabledSummarySubscriptions(ctx context.Context) ([]SummarySubscription, error)
+	GetContractorUnmatchedPlates(ctx context.Context, contractorID uuid.UUID, from, to time.Time, limit int) ([]PlateCount, error)
+}
+
+// BillingStore — ежемесячные ведомости оплаты рейсов
+type BillingStore interface {
+	GetBillingLines(ctx context.Context, from, to time.Time) ([]BillingLine, error)
+	GetBillingPeriod(ctx context.Context, period string) (*BillingPeriod, error)
+	SaveBillingPeriod(ctx context.Context, billing *BillingPeriod) error
+	LockBillingPeriod(ctx context.Context, period string, lockedBy uuid.UUID, lockedAt time.Time) (bool, error)
+}
+
+// DeadLetterStore — непринятые уведомления камер для разбора и повторной обработки
+type DeadLetterStore interface {
+	CreateDeadLetter(ctx context.Context, letter *DeadLetter) error
+	ListDeadLetters(ctx context.Context, pendingOnly bool, limit, offset int) ([]DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetter, error)
+	MarkDeadLetterReplayed(ctx context.Context, id uuid.UUID, replayedAt time.Time, eventID *uuid.UUID, replayErr *string) error
+	PurgeDeadLetters(ctx context.Context, before time.Time) (int64, error)
+}
+
+// JobStore — история запусков периодических задач
+type JobStore interface {
+	CreateJobRun(ctx context.Context, run *JobRun) error
+	FinishJobRun(ctx context.Context, id uuid.UUID, status string, errMsg *string, finishedAt time.Time) error
+	ListJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error)
+	ListLatestJobRuns(ctx context.Context) ([]JobRun, error)
+	PurgeJobRuns(ctx context.Context, before time.Time) (int64, error)
+}
+
+// UsageStore — учёт использования по организациям
+type UsageStore interface {
+	AddUsage(ctx context.Context, rows []UsageDaily) error
+	ListUsage(ctx context.Context, from, to time.Time, organizationID *uuid.UUID) ([]UsageDaily, error)
+}
+
+// WatchStore — отслеживание номеров пользователями
+type WatchStore interface {
+	CreatePlateWatch(ctx context.Context, watch *PlateWatch) error
+	ListPlateWatches(ctx context.Context, userID uuid.UUID) ([]PlateWatch, error)
+	CountPlateWatches(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error)
+	ListActivePlateWatches(ctx context.Context, now time.Time) ([]PlateWatch, error)
+	DeletePlateWatch(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	TouchPlateWatches(ctx context.Context, ids []uuid.UUID, at time.Time) error
+}
+
+// SavedSearchStore — сохранённые поиски событий и расписание их выгрузки
+type SavedSearchStore interface {
+	CreateSavedSearch(ctx context.Context, search *SavedSearch) error
+	UpdateSavedSearch(ctx context.Context, search *SavedSearch) error
+	GetSavedSearch(ctx context.Context, id uuid.UUID) (*SavedSearch, error)
+	ListSavedSearches(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error)
+	CountSavedSearches(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteSavedSearch(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	ListDueSavedSearches(ctx context.Context, now time.Time, limit int) ([]SavedSearch, error)
+	FinishSavedSearchRun(ctx context.Context, id uuid.UUID, scheduledAt, ranAt, nextRunAt time.Time, runErr *string) error
+}
+
+// ANPRStore — всё хранилище, от которого зависит ANPRService. Реализуется *ANPRRepository;
+// в тестах сервиса подменяется моком из пакета mocks (go generate ./internal/repository).
+type ANPRStore interface {
+	EventStore
+	PlateStore
+	ListStore
+	CameraStore
+	AccessRuleStore
+	PolygonStore
+	PolygonModeStore
+	ShiftStore
+	WeatherStore
+	PhotoHashStore
+	ReportStore
+	WebhookStore
+	MQTTStore
+	TelegramStore
+	SummaryStore
+	BillingStore
+	DeadLetterStore
+	JobStore
+	UsageStore
+	WatchStore
+	SavedSearchStore
+	FeatureFlagStore
+
+	GetDataVersion(ctx context.Context, scope string) (int64, error)
+	GetDataVersions(ctx context.Context, scopes []string) ([]DataVersion, error)
+}
+
+var _ ANPRStore = (*ANPRRepository)(nil)
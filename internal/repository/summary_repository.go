@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SummarySubscription — подписка пользователя подрядчика на ночную сводку
+type SummarySubscription struct {
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ContractorID   uuid.UUID `gorm:"type:uuid;not null"`
+	TelegramChatID string    `gorm:"not null"`
+	Enabled        bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (SummarySubscription) TableName() string {
+	return "anpr_summary_subscriptions"
+}
+
+// PlateCount — номер и число его событий
+type PlateCount struct {
+	Plate string `gorm:"column:plate" json:"plate"`
+	Count int64  `gorm:"column:event_count" json:"count"`
+}
+
+// GetSummarySubscription возвращает подписку пользователя; ErrSummarySubscriptionNotFound — её нет
+func (r *ANPRRepository) GetSummarySubscription(ctx context.Context, userID uuid.UUID) (*SummarySubscription, error) {
+	var sub SummarySubscription
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&sub).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrSummarySubscriptionNotFound, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summary subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// UpsertSummarySubscription создаёт или обновляет подписку пользователя
+func (r *ANPRRepository) UpsertSummarySubscription(ctx context.Context, sub *SummarySubscription) error {
+	now := r.clock.Now()
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = now
+	}
+	sub.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"contractor_id", "telegram_chat_id", "enabled", "updated_at"}),
+		}).
+		Create(sub).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert summary subscription: %w", err)
+	}
+	return nil
+}
+
+// ListEnabledSummarySubscriptions возвращает включённые подписки, сгруппированные по подрядчику
+func (r *ANPRRepository) ListEnabledSummarySubscriptions(ctx context.Context) ([]SummarySubscription, error) {
+	var subs []SummarySubscription
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("contractor_id, user_id").
+		Find(&subs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summary subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// GetContractorUnmatchedPlates возвращает номера машин подрядчика, у событий которых в интервале [from, to)
+// снег не сопоставлен с машиной (matched_snow = false), по убыванию числа таких событий
+func (r *ANPRRepository) GetContractorUnmatchedPlates(ctx context.Context, contractorID uuid.UUID, from, to time.Time, limit int) ([]PlateCount, error) {
+	var plates []PlateCount
+	err := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select("e.normalized_plate AS plate, COUNT(*) AS event_count").
+		Joins("LEFT JOIN vehicles v ON normalize_plate_number(v.plate_number) = e.normalized_plate AND v.is_active = true").
+		Where("(e.contractor_id = ? OR v.contractor_id = ?)", contractorID, contractorID).
+		Where("e.event_time >= ? AND e.event_time < ?", from, to).
+		Where("e.matched_snow = FALSE AND e.out_of_schedule = FALSE AND e.late = FALSE").
+		Where("e.deleted_at IS NULL").
+		Group("e.normalized_plate").
+		Order("event_count DESC, plate").
+		Limit(limit).
+		Scan(&plates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmatched plates: %w", err)
+	}
+	return plates, nil
+}
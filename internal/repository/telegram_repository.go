@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// TelegramNotification — уведомление в один чат Telegram, ожидающее отправки
+type TelegramNotification struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	// DedupKey — ключ инцидента (событие, простой камеры): одно уведомление на чат
+	DedupKey      string `gorm:"not null"`
+	ChatID        string `gorm:"not null"`
+	Kind          string `gorm:"not null"`
+	Text          string `gorm:"not null"`
+	PhotoURL      *string
+	Status        string `gorm:"not null"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+func (TelegramNotification) TableName() string {
+	return "anpr_telegram_outbox"
+}
+
+// EnqueueTelegramNotifications ставит уведомления в outbox; уже поставленные (тот же dedup_key и чат)
+// пропускаются
+func (r *ANPRRepository) EnqueueTelegramNotifications(ctx context.Context, notifications []TelegramNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	now := r.clock.Now()
+	for i := range notifications {
+		if notifications[i].ID == uuid.Nil {
+			notifications[i].ID = r.ids.NewID()
+		}
+		notifications[i].Status = OutboxStatusPending
+		notifications[i].CreatedAt = now
+		notifications[i].NextAttemptAt = now
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "dedup_key"}, {Name: "chat_id"}},
+			DoNothing: true,
+		}).
+		Create(&notifications).Error
+	if err != nil {
+		return fmt.Errorf("failed to enqueue telegram notifications: %w", err)
+	}
+	return nil
+}
+
+// ClaimTelegramNotifications выбирает до limit уведомлений, которым пора уйти (см. claimOutbox)
+func (r *ANPRRepository) ClaimTelegramNotifications(ctx context.Context, limit int, lease time.Duration) ([]TelegramNotification, error) {
+	var notifications []TelegramNotification
+	if err := r.claimOutbox(ctx, TelegramNotification{}.TableName(), limit, lease, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to claim telegram notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkTelegramNotificationSent отмечает успешную отправку
+func (r *ANPRRepository) MarkTelegramNotificationSent(ctx context.Context, id uuid.UUID) error {
+	if err := r.markOutboxDelivered(ctx, &TelegramNotification{}, id, nil); err != nil {
+		return fmt.Errorf("failed to mark telegram notification sent: %w", err)
+	}
+	return nil
+}
+
+// MarkTelegramAttemptFailed записывает неудачную попытку; nextAttemptAt nil — уведомление помечается failed
+func (r *ANPRRepository) MarkTelegramAttemptFailed(ctx context.Context, id uuid.UUID, errMsg string, nextAttemptAt *time.Time) error {
+	if err := r.recordOutboxFailure(ctx, &TelegramNotification{}, id, nil, errMsg, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to record telegram attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListHistoryEntry — изменение членства номера в списке
+type ListHistoryEntry struct {
+	ListID    uuid.UUID
+	ListName  *string
+	ListType  *string
+	Action    string
+	Note      *string
+	ChangedAt time.Time
+}
+
+// GetPlateByID получает номер по ID; ErrPlateNotFound — номера нет
+func (r *ANPRRepository) GetPlateByID(ctx context.Context, plateID uuid.UUID) (*Plate, error) {
+	var plate Plate
+	err := r.db.WithContext(ctx).Where("id = ?", plateID).First(&plate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrPlateNotFound, plateID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate: %w", err)
+	}
+	return &plate, nil
+}
+
+// FindPlateEvents возвращает события номера за период в хронологическом порядке
+func (r *ANPRRepository) FindPlateEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]ANPREvent, error) {
+	var events []ANPREvent
+	err := r.db.WithContext(ctx).
+		Where("plate_id = ?", plateID).
+		Where("event_time >= ? AND event_time <= ?", from, to).
+		Order("event_time ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate events: %w", err)
+	}
+	return events, nil
+}
+
+// FindPlateRejectedEvents возвращает отклонённые события номера за период
+func (r *ANPRRepository) FindPlateRejectedEvents(ctx context.Context, plateID uuid.UUID, from, to time.Time, limit int) ([]RejectedEvent, error) {
+	var events []RejectedEvent
+	err := r.db.WithContext(ctx).
+		Where("plate_id = ?", plateID).
+		Where("event_time >= ? AND event_time <= ?", from, to).
+		Order("event_time ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate rejected events: %w", err)
+	}
+	return events, nil
+}
+
+// GetPlateListHistory возвращает историю добавления/удаления номера в списки за период
+func (r *ANPRRepository) GetPlateListHistory(ctx context.Context, plateID uuid.UUID, from, to time.Time) ([]ListHistoryEntry, error) {
+	var entries []ListHistoryEntry
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_item_history h").
+		Select("h.list_id, l.name AS list_name, l.type AS list_type, h.action, h.note, h.changed_at").
+		Joins("LEFT JOIN anpr_lists l ON l.id = h.list_id").
+		Where("h.plate_id = ?", plateID).
+		Where("h.changed_at >= ? AND h.changed_at <= ?", from, to).
+		Order("h.changed_at ASC").
+		Scan(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate list history: %w", err)
+	}
+	return entries, nil
+}
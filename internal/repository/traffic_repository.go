@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrafficFilters — выборка событий для аналитики скорости и полос
+type TrafficFilters struct {
+	From      time.Time
+	To        time.Time
+	CameraID  *string
+	PolygonID *uuid.UUID
+	// PolygonOrgID — только полигоны организации (пользователи полигона видят свои камеры)
+	PolygonOrgID *uuid.UUID
+}
+
+func (f TrafficFilters) apply(query *gorm.DB) *gorm.DB {
+	query = query.Where("e.deleted_at IS NULL").
+		Where("e.event_time >= ? AND e.event_time < ?", f.From, f.To)
+	if f.CameraID != nil {
+		query = query.Where("e.camera_id = ?", *f.CameraID)
+	}
+	if f.PolygonID != nil {
+		query = query.Where("e.polygon_id = ?", *f.PolygonID)
+	}
+	if f.PolygonOrgID != nil {
+		query = query.Where("e.polygon_id IN (SELECT id FROM anpr_polygons WHERE organization_id = ?)", *f.PolygonOrgID)
+	}
+	return query
+}
+
+// CameraSpeedStat — скорость проездов камеры (км/ч) по событиям, где камера её измерила
+type CameraSpeedStat struct {
+	CameraID string  `gorm:"column:camera_id"`
+	Samples  int64   `gorm:"column:samples"`
+	AvgSpeed float64 `gorm:"column:avg_speed"`
+	P50Speed float64 `gorm:"column:p50_speed"`
+	P85Speed float64 `gorm:"column:p85_speed"`
+	P95Speed float64 `gorm:"column:p95_speed"`
+	MaxSpeed float64 `gorm:"column:max_speed"`
+}
+
+// GetCameraSpeedStats возвращает среднюю скорость, перцентили и максимум по камерам
+func (r *ANPRRepository) GetCameraSpeedStats(ctx context.Context, filters TrafficFilters) ([]CameraSpeedStat, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			e.camera_id,
+			COUNT(*) AS samples,
+			AVG(e.vehicle_speed) AS avg_speed,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY e.vehicle_speed) AS p50_speed,
+			percentile_cont(0.85) WITHIN GROUP (ORDER BY e.vehicle_speed) AS p85_speed,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY e.vehicle_speed) AS p95_speed,
+			MAX(e.vehicle_speed) AS max_speed
+		`).
+		Where("e.vehicle_speed IS NOT NULL AND e.vehicle_speed > 0")
+
+	var rows []CameraSpeedStat
+	err := filters.apply(query).Group("e.camera_id").Order("e.camera_id").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera speed stats: %w", err)
+	}
+	return rows, nil
+}
+
+// LaneUsageStat — число проездов по полосе камеры за интервал
+type LaneUsageStat struct {
+	Bucket   time.Time `gorm:"column:bucket"`
+	CameraID string    `gorm:"column:camera_id"`
+	Lane     int       `gorm:"column:lane"`
+	Events   int64     `gorm:"column:events"`
+	// AvgSpeed — средняя скорость на полосе за интервал; nil — камера скорость не измеряла
+	AvgSpeed *float64 `gorm:"column:avg_speed"`
+}
+
+// GetLaneUsageStats возвращает проезды по полосам камер, сгруппированные по интервалу interval
+// ("hour" или "day") в часовом поясе timezone. События без номера полосы не учитываются.
+func (r *ANPRRepository) GetLaneUsageStats(ctx context.Context, filters TrafficFilters, interval, timezone string) ([]LaneUsageStat, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			date_trunc(?, e.event_time AT TIME ZONE ?) AT TIME ZONE ? AS bucket,
+			e.camera_id,
+			e.lane,
+			COUNT(*) AS events,
+			AVG(e.vehicle_speed) FILTER (WHERE e.vehicle_speed > 0) AS avg_speed
+		`, interval, timezone, timezone).
+		Where("e.lane IS NOT NULL")
+
+	var rows []LaneUsageStat
+	err := filters.apply(query).Group("bucket, e.camera_id, e.lane").Order("bucket, e.camera_id, e.lane").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lane usage stats: %w", err)
+	}
+	return rows, nil
+}
+
+// ConfidenceStat — события камеры в интервале уверенности распознавания шириной 0.05 и сколько из них
+// исправлено вручную
+type ConfidenceStat struct {
+	CameraID string `gorm:"column:camera_id"`
+	// Bucket — номер интервала: уверенность [Bucket·0.05, (Bucket+1)·0.05), последний включает 1.0
+	Bucket    int   `gorm:"column:bucket"`
+	Events    int64 `gorm:"column:events"`
+	Corrected int64 `gorm:"column:corrected"`
+}
+
+// GetConfidenceStats возвращает распределение уверенности распознавания по камерам. Уверенность в
+// процентах (Hikvision присылает 0–100) приводится к 0–1. Событие считается исправленным, если его номер
+// отличается от прочитанного камерой (нормализация как в utils.NormalizePlate): оператор слил номер с
+// основным или номер заменён псевдонимом.
+func (r *ANPRRepository) GetConfidenceStats(ctx context.Context, filters TrafficFilters) ([]ConfidenceStat, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events AS e").
+		Select(`
+			e.camera_id,
+			LEAST(FLOOR((CASE WHEN e.confidence > 1 THEN e.confidence / 100 ELSE e.confidence END) * 20), 19)::int AS bucket,
+			COUNT(*) AS events,
+			COUNT(*) FILTER (WHERE UPPER(REPLACE(REPLACE(BTRIM(e.raw_plate), ' ', ''), '-', '')) <> e.normalized_plate) AS corrected
+		`).
+		Where("e.confidence IS NOT NULL AND e.confidence > 0")
+
+	var rows []ConfidenceStat
+	err := filters.apply(query).Group("e.camera_id, bucket").Order("e.camera_id, bucket").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confidence stats: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// UnmatchedPlate — номер, проезды которого отклонялись (нет в vehicles), не попавший ни в один список
+type UnmatchedPlate struct {
+	PlateID    uuid.UUID `gorm:"column:plate_id"`
+	Number     string    `gorm:"column:number"`
+	Normalized string    `gorm:"column:normalized"`
+	EventCount int64     `gorm:"column:event_count"`
+	FirstSeen  time.Time `gorm:"column:first_seen"`
+	LastSeen   time.Time `gorm:"column:last_seen"`
+	Cameras    string    `gorm:"column:cameras"` // идентификаторы камер через запятую
+}
+
+// RejectedPhotos — фото одного отклонённого проезда номера
+type RejectedPhotos struct {
+	PlateID   uuid.UUID      `gorm:"column:plate_id"`
+	PhotoURLs datatypes.JSON `gorm:"column:photo_urls"`
+}
+
+// unmatchedRejected — отклонённые из-за отсутствия в vehicles проезды номеров, которых до сих пор нет
+// ни в vehicles, ни в списках (номер, добавленный в whitelist или blacklist, считается разобранным)
+func (r *ANPRRepository) unmatchedRejected(ctx context.Context, from, to time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("anpr_events_rejected r").
+		Joins("JOIN anpr_plates p ON p.id = r.plate_id").
+		Where("r.reject_reason = ?", RejectReasonVehicleNotWhitelist).
+		Where("r.event_time >= ? AND r.event_time <= ?", from, to).
+		Where("NOT EXISTS (SELECT 1 FROM anpr_list_items li WHERE li.plate_id = r.plate_id)").
+		Where("NOT EXISTS (SELECT 1 FROM vehicles v WHERE v.is_active = true AND normalize_plate_number(v.plate_number) = p.normalized)")
+}
+
+// ListUnmatchedPlates возвращает неразобранные номера за период: самые частые первыми
+func (r *ANPRRepository) ListUnmatchedPlates(ctx context.Context, from, to time.Time, limit, offset int) ([]UnmatchedPlate, error) {
+	query := r.unmatchedRejected(ctx, from, to).
+		Select(`
+			r.plate_id, p.number, p.normalized,
+			COUNT(*) AS event_count,
+			MIN(r.event_time) AS first_seen,
+			MAX(r.event_time) AS last_seen,
+			string_agg(DISTINCT r.camera_id, ',') AS cameras
+		`).
+		Group("r.plate_id, p.number, p.normalized").
+		Order("event_count DESC, last_seen DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var plates []UnmatchedPlate
+	if err := query.Scan(&plates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list unmatched plates: %w", err)
+	}
+	return plates, nil
+}
+
+// GetRejectedPhotoSamples возвращает фото последних perPlate отклонённых проездов каждого номера за период
+func (r *ANPRRepository) GetRejectedPhotoSamples(ctx context.Context, plateIDs []uuid.UUID, from, to time.Time, perPlate int) ([]RejectedPhotos, error) {
+	if len(plateIDs) == 0 {
+		return nil, nil
+	}
+	var samples []RejectedPhotos
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT plate_id, photo_urls FROM (
+			SELECT plate_id, photo_urls,
+				ROW_NUMBER() OVER (PARTITION BY plate_id ORDER BY event_time DESC) AS rn
+			FROM anpr_events_rejected
+			WHERE plate_id IN ? AND reject_reason = ?
+				AND event_time >= ? AND event_time <= ?
+				AND jsonb_typeof(photo_urls) = 'array' AND jsonb_array_length(photo_urls) > 0
+		) s
+		WHERE rn <= ?
+		ORDER BY plate_id, rn`,
+		plateIDs, RejectReasonVehicleNotWhitelist, from, to, perPlate).
+		Scan(&samples).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rejected photo samples: %w", err)
+	}
+	return samples, nil
+}
+
+// GetListByName получает список по имени; ErrListNotFound — списка нет
+func (r *ANPRRepository) GetListByName(ctx context.Context, name string) (*List, error) {
+	var list List
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&list).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list by name: %w", err)
+	}
+	return &list, nil
+}
+
+// AddPlateToList добавляет номер в список; повторное добавление ничего не меняет.
+// Возвращает false, если номер уже был в списке.
+func (r *ANPRRepository) AddPlateToList(ctx context.Context, listID, plateID uuid.UUID, note *string) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO anpr_list_items (list_id, plate_id, note, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (list_id, plate_id) DO NOTHING`,
+		listID, plateID, note, r.clock.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to add plate to list: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
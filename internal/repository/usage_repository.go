@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageDaily — использование сервиса организацией за сутки
+type UsageDaily struct {
+	Day            time.Time `gorm:"type:date;primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EventsIngested int64
+	StorageBytes   int64
+	APICalls       int64 `gorm:"column:api_calls"`
+	UpdatedAt      time.Time
+}
+
+func (UsageDaily) TableName() string {
+	return "anpr_usage_daily"
+}
+
+// AddUsage прибавляет счётчики к строкам (day, organization_id), создавая недостающие
+func (r *ANPRRepository) AddUsage(ctx context.Context, rows []UsageDaily) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "day"}, {Name: "organization_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"events_ingested": gorm.Expr("anpr_usage_daily.events_ingested + EXCLUDED.events_ingested"),
+				"storage_bytes":   gorm.Expr("anpr_usage_daily.storage_bytes + EXCLUDED.storage_bytes"),
+				"api_calls":       gorm.Expr("anpr_usage_daily.api_calls + EXCLUDED.api_calls"),
+				"updated_at":      gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).
+		Create(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to add usage: %w", err)
+	}
+	return nil
+}
+
+// ListUsage возвращает использование за дни [from, to] (включительно), по дням и организациям.
+// organizationID ограничивает выборку одной организацией.
+func (r *ANPRRepository) ListUsage(ctx context.Context, from, to time.Time, organizationID *uuid.UUID) ([]UsageDaily, error) {
+	query := r.db.WithContext(ctx).
+		Where("day >= ? AND day <= ?", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if organizationID != nil {
+		query = query.Where("organization_id = ?", *organizationID)
+	}
+	var rows []UsageDaily
+	if err := query.Order("day, organization_id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlateWatch — отслеживание номера пользователем: уведомление о каждом проезде номера под шаблон
+type PlateWatch struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null"`
+	// Pattern — нормализованный номер, * — любые символы, ? — один символ
+	Pattern        string `gorm:"not null"`
+	Channel        string `gorm:"not null"`
+	Target         string `gorm:"not null"`
+	Note           *string
+	ExpiresAt      time.Time `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"not null"`
+	LastNotifiedAt *time.Time
+}
+
+func (PlateWatch) TableName() string {
+	return "anpr_plate_watches"
+}
+
+// CreatePlateWatch сохраняет отслеживание номера
+func (r *ANPRRepository) CreatePlateWatch(ctx context.Context, watch *PlateWatch) error {
+	if watch.ID == uuid.Nil {
+		watch.ID = r.ids.NewID()
+	}
+	if watch.CreatedAt.IsZero() {
+		watch.CreatedAt = r.clock.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(watch).Error; err != nil {
+		return fmt.Errorf("failed to create plate watch: %w", err)
+	}
+	return nil
+}
+
+// ListPlateWatches возвращает отслеживания пользователя, в том числе истёкшие, в порядке создания
+func (r *ANPRRepository) ListPlateWatches(ctx context.Context, userID uuid.UUID) ([]PlateWatch, error) {
+	var watches []PlateWatch
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&watches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plate watches: %w", err)
+	}
+	return watches, nil
+}
+
+// CountPlateWatches возвращает число действующих на момент now отслеживаний пользователя
+func (r *ANPRRepository) CountPlateWatches(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&PlateWatch{}).
+		Where("user_id = ? AND expires_at > ?", userID, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count plate watches: %w", err)
+	}
+	return count, nil
+}
+
+// ListActivePlateWatches возвращает все отслеживания, действующие на момент now
+func (r *ANPRRepository) ListActivePlateWatches(ctx context.Context, now time.Time) ([]PlateWatch, error) {
+	var watches []PlateWatch
+	if err := r.db.WithContext(ctx).Where("expires_at > ?", now).Find(&watches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list active plate watches: %w", err)
+	}
+	return watches, nil
+}
+
+// DeletePlateWatch удаляет отслеживание пользователя; false — у пользователя такого нет
+func (r *ANPRRepository) DeletePlateWatch(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&PlateWatch{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete plate watch: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchPlateWatches запоминает время последнего срабатывания отслеживаний
+func (r *ANPRRepository) TouchPlateWatches(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Model(&PlateWatch{}).
+		Where("id IN ?", ids).
+		Update("last_notified_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch plate watches: %w", err)
+	}
+	return nil
+}
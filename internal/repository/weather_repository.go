@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// WeatherObservation — погода на полигоне за час, заканчивающийся в ObservedHour
+type WeatherObservation struct {
+	PolygonID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ObservedHour time.Time `gorm:"primaryKey"`
+	TemperatureC *float64
+	SnowfallCm   *float64
+	FetchedAt    time.Time
+}
+
+func (WeatherObservation) TableName() string {
+	return "anpr_weather_observations"
+}
+
+// WeatherLocation — точка, по которой запрашивается погода полигона
+type WeatherLocation struct {
+	PolygonID uuid.UUID `gorm:"column:polygon_id"`
+	Latitude  float64   `gorm:"column:latitude"`
+	Longitude float64   `gorm:"column:longitude"`
+}
+
+// ListWeatherLocations возвращает координаты полигонов: собственные, а если их нет — среднее по
+// координатам камер полигона. Полигоны без координат пропускаются.
+func (r *ANPRRepository) ListWeatherLocations(ctx context.Context) ([]WeatherLocation, error) {
+	var locations []WeatherLocation
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT id AS polygon_id, latitude, longitude
+		FROM (
+			SELECT p.id,
+				COALESCE(p.latitude, (SELECT AVG(c.latitude) FROM anpr_cameras c WHERE c.polygon_id = p.id)) AS latitude,
+				COALESCE(p.longitude, (SELECT AVG(c.longitude) FROM anpr_cameras c WHERE c.polygon_id = p.id)) AS longitude
+			FROM anpr_polygons p
+		) located
+		WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+		ORDER BY polygon_id`).
+		Scan(&locations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list weather locations: %w", err)
+	}
+	return locations, nil
+}
+
+// UpsertWeatherObservations сохраняет погоду, перезаписывая ранее загруженные часы
+func (r *ANPRRepository) UpsertWeatherObservations(ctx context.Context, observations []WeatherObservation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+	now := r.clock.Now()
+	for i := range observations {
+		observations[i].FetchedAt = now
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "polygon_id"}, {Name: "observed_hour"}},
+			DoUpdates: clause.AssignmentColumns([]string{"temperature_c", "snowfall_cm", "fetched_at"}),
+		}).
+		CreateInBatches(observations, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert weather observations: %w", err)
+	}
+	return nil
+}
+
+// ApplyWeatherToEvents проставляет погоду событиям с event_time в [from, until), у которых её ещё нет.
+// Событию соответствует час, в котором оно произошло (observed_hour — конец этого часа).
+func (r *ANPRRepository) ApplyWeatherToEvents(ctx context.Context, from, until time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE anpr_events e
+		SET weather_temperature_c = w.temperature_c,
+			weather_snowfall_cm = w.snowfall_cm
+		FROM anpr_weather_observations w
+		WHERE e.event_time >= ? AND e.event_time < ?
+		  AND e.polygon_id = w.polygon_id
+		  AND w.observed_hour = (date_trunc('hour', e.event_time AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') + INTERVAL '1 hour'
+		  AND e.weather_temperature_c IS NULL
+		  AND e.weather_snowfall_cm IS NULL
+		  AND (w.temperature_c IS NOT NULL OR w.snowfall_cm IS NOT NULL)`,
+		from, until,
+	)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to apply weather to events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
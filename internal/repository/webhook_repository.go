@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Статусы доставки вебхука
+const (
+	WebhookStatusPending   = OutboxStatusPending
+	WebhookStatusDelivered = OutboxStatusDelivered
+	WebhookStatusFailed    = OutboxStatusFailed
+)
+
+// WebhookSubscription — подписка внешней системы на события
+type WebhookSubscription struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name   string    `gorm:"not null"`
+	URL    string    `gorm:"column:url;not null"`
+	Secret string    `gorm:"not null"` // ключ HMAC-подписи тела запроса
+	// Фильтры: пустой список — любое значение, MatchedSnow nil — любое
+	CameraIDs   datatypes.JSONSlice[string] `gorm:"column:camera_ids;type:jsonb"`
+	ListTypes   datatypes.JSONSlice[string] `gorm:"type:jsonb"`
+	MatchedSnow *bool
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (WebhookSubscription) TableName() string {
+	return "anpr_webhook_subscriptions"
+}
+
+// WebhookDelivery — доставка одного события одному подписчику (строка журнала доставок)
+type WebhookDelivery struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SubscriptionID uuid.UUID      `gorm:"type:uuid;not null"`
+	EventID        uuid.UUID      `gorm:"type:uuid;not null"`
+	Topic          string         `gorm:"not null"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null"`
+	Status         string         `gorm:"not null"`
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+func (WebhookDelivery) TableName() string {
+	return "anpr_webhook_deliveries"
+}
+
+// CreateWebhookSubscription сохраняет новую подписку
+func (r *ANPRRepository) CreateWebhookSubscription(ctx context.Context, sub *WebhookSubscription) error {
+	now := r.clock.Now()
+	if sub.ID == uuid.Nil {
+		sub.ID = r.ids.NewID()
+	}
+	sub.CreatedAt = now
+	sub.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(sub).Error; err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookSubscription сохраняет все поля подписки
+func (r *ANPRRepository) UpdateWebhookSubscription(ctx context.Context, sub *WebhookSubscription) error {
+	sub.UpdatedAt = r.clock.Now()
+	if err := r.db.WithContext(ctx).Save(sub).Error; err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhookSubscription удаляет подписку вместе с журналом доставок; false — подписки нет
+func (r *ANPRRepository) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&WebhookSubscription{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetWebhookSubscription получает подписку; ErrWebhookSubscriptionNotFound — подписки нет
+func (r *ANPRRepository) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (*WebhookSubscription, error) {
+	var sub WebhookSubscription
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&sub).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrWebhookSubscriptionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// ListWebhookSubscriptions возвращает подписки (activeOnly — только включённые)
+func (r *ANPRRepository) ListWebhookSubscriptions(ctx context.Context, activeOnly bool) ([]WebhookSubscription, error) {
+	query := r.db.WithContext(ctx).Order("created_at ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var subs []WebhookSubscription
+	if err := query.Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// EnqueueWebhookDeliveries ставит доставки в очередь. Доставка того же события тому же подписчику
+// (событие получили несколько реплик) пропускается.
+func (r *ANPRRepository) EnqueueWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	now := r.clock.Now()
+	for i := range deliveries {
+		if deliveries[i].ID == uuid.Nil {
+			deliveries[i].ID = r.ids.NewID()
+		}
+		deliveries[i].Status = WebhookStatusPending
+		deliveries[i].CreatedAt = now
+		deliveries[i].NextAttemptAt = now
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(&deliveries).Error
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries выбирает до limit доставок, которым пора уйти (см. claimOutbox)
+func (r *ANPRRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if err := r.claimOutbox(ctx, WebhookDelivery{}.TableName(), limit, lease, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// MarkWebhookDelivered отмечает успешную доставку
+func (r *ANPRRepository) MarkWebhookDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	updates := map[string]interface{}{"last_status_code": statusCode}
+	if err := r.markOutboxDelivered(ctx, &WebhookDelivery{}, id, updates); err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkWebhookAttemptFailed записывает неудачную попытку. nextAttemptAt nil — попытки исчерпаны,
+// доставка помечается failed.
+func (r *ANPRRepository) MarkWebhookAttemptFailed(ctx context.Context, id uuid.UUID, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	updates := map[string]interface{}{"last_status_code": statusCode}
+	if err := r.recordOutboxFailure(ctx, &WebhookDelivery{}, id, updates, errMsg, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries возвращает журнал доставок подписки, новые первыми (status пустой — все)
+func (r *ANPRRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit, offset int) ([]WebhookDelivery, error) {
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []WebhookDelivery
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery возвращает доставку в очередь с новым счётчиком попыток; false — доставки нет
+func (r *ANPRRepository) RetryWebhookDelivery(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("id = ? AND subscription_id = ?", deliveryID, subscriptionID).
+		Updates(map[string]interface{}{
+			"status":          WebhookStatusPending,
+			"attempts":        0,
+			"next_attempt_at": r.clock.Now(),
+			"delivered_at":    nil,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to retry webhook delivery: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNext(t *testing.T) {
+	almaty := time.FixedZone("UTC+5", 5*60*60)
+	// Среда, 15 января 2025, 22:10 UTC+5
+	from := time.Date(2025, 1, 15, 22, 10, 30, 0, almaty)
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{name: "every", spec: "@every 15m", want: from.Add(15 * time.Minute)},
+		{name: "every minute", spec: "* * * * *", want: time.Date(2025, 1, 15, 22, 11, 0, 0, almaty)},
+		{name: "daily at 03:00", spec: "0 3 * * *", want: time.Date(2025, 1, 16, 3, 0, 0, 0, almaty)},
+		{name: "step", spec: "*/20 * * * *", want: time.Date(2025, 1, 15, 22, 20, 0, 0, almaty)},
+		{name: "list and range", spec: "0 8-10,23 * * *", want: time.Date(2025, 1, 15, 23, 0, 0, 0, almaty)},
+		{name: "hourly", spec: "@hourly", want: time.Date(2025, 1, 15, 23, 0, 0, 0, almaty)},
+		{name: "weekly on sunday", spec: "@weekly", want: time.Date(2025, 1, 19, 0, 0, 0, 0, almaty)},
+		{name: "sunday as 7", spec: "30 4 * * 7", want: time.Date(2025, 1, 19, 4, 30, 0, 0, almaty)},
+		{name: "monthly", spec: "@monthly", want: time.Date(2025, 2, 1, 0, 0, 0, 0, almaty)},
+		{name: "day of month or weekday", spec: "0 0 17 * 1", want: time.Date(2025, 1, 17, 0, 0, 0, 0, almaty)},
+		{name: "leap day", spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, almaty)},
+		{name: "never", spec: "0 0 30 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec, almaty)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Fatalf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+		"@every soon",
+		"@every 100ms",
+		"@often",
+	} {
+		if _, err := Parse(spec, time.UTC); err == nil {
+			t.Errorf("Parse(%q): expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+// Package scheduler запускает периодические задачи сервиса по расписанию (cron или @every) со случайной
+// задержкой, таймаутом на запуск и историей запусков. Задачу можно запустить и вручную (Trigger).
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/lifecycle"
+)
+
+// Кто запустил задачу
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Статусы запуска
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusTimeout   = "timeout"
+)
+
+// defaultTimeout — ограничение запуска, если не задано ни у задачи, ни в Options
+const defaultTimeout = 30 * time.Minute
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job — периодическая задача
+type Job struct {
+	Name string
+	// Spec — расписание (см. Parse)
+	Spec string
+	// Timeout — ограничение одного запуска; 0 — Options.Timeout
+	Timeout time.Duration
+	// RunOnStart — первый запуск сразу при старте планировщика, не дожидаясь расписания
+	RunOnStart bool
+	Run        func(ctx context.Context) error
+}
+
+// Run — запуск задачи
+type Run struct {
+	ID         uuid.UUID
+	Job        string
+	Trigger    string
+	Status     string
+	Error      string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	// TriggeredBy — пользователь, запустивший задачу вручную
+	TriggeredBy *uuid.UUID
+}
+
+// History — журнал запусков. Ошибки записи в журнал только логируются и не мешают выполнению задачи.
+type History interface {
+	StartRun(ctx context.Context, run *Run) error
+	FinishRun(ctx context.Context, run *Run) error
+}
+
+// Options — общие настройки планировщика
+type Options struct {
+	// Location — часовой пояс cron-выражений
+	Location *time.Location
+	// Jitter — наибольшая случайная задержка запуска по расписанию, чтобы задачи не стартовали разом
+	Jitter time.Duration
+	// Timeout — ограничение одного запуска по умолчанию
+	Timeout time.Duration
+}
+
+// JobInfo — задача и её состояние на этой реплике
+type JobInfo struct {
+	Name    string
+	Spec    string
+	Timeout time.Duration
+	Running bool
+	// NextRunAt — следующий запуск по расписанию; nil — планировщик на этой реплике не запущен
+	NextRunAt *time.Time
+}
+
+// Scheduler хранит задачи и запускает их по расписанию
+type Scheduler struct {
+	opts    Options
+	history History
+	clock   clock.Clock
+	ids     idgen.Generator
+	log     zerolog.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+	// inflight — выполняющиеся запуски, по расписанию и ручные
+	inflight sync.WaitGroup
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	running  bool
+	next     time.Time
+}
+
+// New создаёт планировщик; задачи добавляются через Add до вызова Run
+func New(opts Options, history History, clk clock.Clock, ids idgen.Generator, log zerolog.Logger) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	return &Scheduler{
+		opts:    opts,
+		history: history,
+		clock:   clk,
+		ids:     ids,
+		log:     log.With().Str("component", "scheduler").Logger(),
+		jobs:    map[string]*entry{},
+	}
+}
+
+// Add добавляет задачу; имя должно быть уникальным, расписание — корректным
+func (s *Scheduler) Add(job Job) error {
+	schedule, err := Parse(job.Spec, s.opts.Location)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = s.opts.Timeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Jobs возвращает задачи по имени
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]JobInfo, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		info := JobInfo{Name: e.job.Name, Spec: e.job.Spec, Timeout: e.job.Timeout, Running: e.running}
+		if !e.next.IsZero() {
+			next := e.next
+			info.NextRunAt = &next
+		}
+		jobs = append(jobs, info)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Run запускает задачи по расписанию до отмены ctx и ждёт завершения начатых запусков
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.setNext(e, time.Time{})
+	if e.job.RunOnStart {
+		s.runScheduled(ctx, e)
+	}
+	for {
+		now := s.clock.Now()
+		next := e.schedule.Next(now)
+		if next.IsZero() {
+			s.log.Warn().Str("job", e.job.Name).Str("spec", e.job.Spec).Msg("job schedule never fires")
+			return
+		}
+		next = next.Add(s.jitter())
+		s.setNext(e, next)
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runScheduled(ctx, e)
+	}
+}
+
+// runScheduled выполняет запуск по расписанию; если задача ещё выполняется (ручной запуск), он пропускается.
+// Начатый запуск не прерывается остановкой планировщика: он доводится до конца (см. lifecycle.Detach).
+func (s *Scheduler) runScheduled(ctx context.Context, e *entry) {
+	runCtx, cancel := lifecycle.Detach(ctx)
+	defer cancel()
+	run, err := s.begin(runCtx, e, TriggerSchedule, nil)
+	if err != nil {
+		s.log.Info().Str("job", e.job.Name).Msg("job is still running, skipping scheduled run")
+		return
+	}
+	s.execute(runCtx, e, run)
+}
+
+// Wait ждёт окончания выполняющихся запусков, в том числе ручных, до истечения ctx
+func (s *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		var running []string
+		for _, job := range s.Jobs() {
+			if job.Running {
+				running = append(running, job.Name)
+			}
+		}
+		return fmt.Errorf("jobs did not finish in time: %s", strings.Join(running, ", "))
+	}
+}
+
+// Trigger запускает задачу вручную, не дожидаясь расписания, и сразу возвращает начатый запуск.
+// Запуск не прерывается отменой ctx (запрос API завершится раньше задачи) и ограничен таймаутом задачи.
+func (s *Scheduler) Trigger(ctx context.Context, name string, triggeredBy *uuid.UUID) (*Run, error) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	runCtx := context.WithoutCancel(ctx)
+	run, err := s.begin(runCtx, e, TriggerManual, triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	go s.execute(runCtx, e, run)
+	return &started, nil
+}
+
+// begin отмечает задачу выполняющейся и записывает начало запуска
+func (s *Scheduler) begin(ctx context.Context, e *entry, trigger string, triggeredBy *uuid.UUID) (*Run, error) {
+	s.mu.Lock()
+	if e.running {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, e.job.Name)
+	}
+	e.running = true
+	s.inflight.Add(1)
+	s.mu.Unlock()
+
+	run := &Run{
+		ID:          s.ids.NewID(),
+		Job:         e.job.Name,
+		Trigger:     trigger,
+		Status:      StatusRunning,
+		StartedAt:   s.clock.Now(),
+		TriggeredBy: triggeredBy,
+	}
+	if err := s.history.StartRun(ctx, run); err != nil {
+		s.log.Warn().Err(err).Str("job", run.Job).Msg("failed to record job start")
+	}
+	return run, nil
+}
+
+// execute выполняет задачу с таймаутом и записывает итог запуска
+func (s *Scheduler) execute(ctx context.Context, e *entry, run *Run) {
+	defer func() {
+		s.mu.Lock()
+		e.running = false
+		s.mu.Unlock()
+		s.inflight.Done()
+	}()
+
+	jobCtx, cancel := context.WithTimeout(ctx, e.job.Timeout)
+	err := e.job.Run(jobCtx)
+	timedOut := errors.Is(jobCtx.Err(), context.DeadlineExceeded)
+	cancel()
+
+	finished := s.clock.Now()
+	run.FinishedAt = &finished
+	switch {
+	case err == nil:
+		run.Status = StatusSucceeded
+	case timedOut:
+		run.Status = StatusTimeout
+		run.Error = fmt.Sprintf("timed out after %s: %v", e.job.Timeout, err)
+	default:
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+
+	log := s.log.Info()
+	if run.Status != StatusSucceeded {
+		log = s.log.Warn().Str("error", run.Error)
+	}
+	log.Str("job", run.Job).
+		Str("trigger", run.Trigger).
+		Str("status", run.Status).
+		Dur("duration", finished.Sub(run.StartedAt)).
+		Msg("job finished")
+
+	// Итог записывается и при остановке сервиса, когда ctx уже отменён
+	historyCtx, cancelHistory := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancelHistory()
+	if err := s.history.FinishRun(historyCtx, run); err != nil {
+		s.log.Warn().Err(err).Str("job", run.Job).Msg("failed to record job result")
+	}
+}
+
+func (s *Scheduler) setNext(e *entry, next time.Time) {
+	s.mu.Lock()
+	e.next = next
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) jitter() time.Duration {
+	if s.opts.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.opts.Jitter)
+}
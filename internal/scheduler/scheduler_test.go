@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/idgen"
+)
+
+// memoryHistory — журнал запусков в памяти
+type memoryHistory struct {
+	mu       sync.Mutex
+	started  []Run
+	finished chan Run
+}
+
+func newMemoryHistory() *memoryHistory {
+	return &memoryHistory{finished: make(chan Run, 10)}
+}
+
+func (h *memoryHistory) StartRun(_ context.Context, run *Run) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started = append(h.started, *run)
+	return nil
+}
+
+func (h *memoryHistory) FinishRun(_ context.Context, run *Run) error {
+	h.finished <- *run
+	return nil
+}
+
+func (h *memoryHistory) next(t *testing.T) Run {
+	t.Helper()
+	select {
+	case run := <-h.finished:
+		return run
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for job run")
+		return Run{}
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	history := newMemoryHistory()
+	s := New(Options{}, history, clock.System(), idgen.NewSequence(), zerolog.Nop())
+
+	release := make(chan struct{})
+	jobs := []Job{
+		{Name: "ok", Spec: "@daily", Run: func(context.Context) error { return nil }},
+		{Name: "fails", Spec: "@daily", Run: func(context.Context) error { return errors.New("boom") }},
+		{Name: "slow", Spec: "@daily", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		{Name: "blocked", Spec: "@daily", Run: func(context.Context) error {
+			<-release
+			return nil
+		}},
+	}
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			t.Fatalf("Add(%s): %v", job.Name, err)
+		}
+	}
+	if err := s.Add(Job{Name: "ok", Spec: "@daily"}); err == nil {
+		t.Fatal("expected error for duplicate job")
+	}
+	if err := s.Add(Job{Name: "bad", Spec: "every day"}); err == nil {
+		t.Fatal("expected error for invalid schedule")
+	}
+
+	tests := []struct {
+		job    string
+		status string
+		err    string
+	}{
+		{job: "ok", status: StatusSucceeded},
+		{job: "fails", status: StatusFailed, err: "boom"},
+		{job: "slow", status: StatusTimeout, err: "timed out after 10ms: context deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.job, func(t *testing.T) {
+			started, err := s.Trigger(context.Background(), tt.job, nil)
+			if err != nil {
+				t.Fatalf("Trigger: %v", err)
+			}
+			if started.Status != StatusRunning || started.Trigger != TriggerManual {
+				t.Fatalf("unexpected started run: %+v", started)
+			}
+			run := history.next(t)
+			if run.ID != started.ID || run.Status != tt.status || run.Error != tt.err || run.FinishedAt == nil {
+				t.Fatalf("unexpected finished run: %+v", run)
+			}
+		})
+	}
+
+	if _, err := s.Trigger(context.Background(), "missing", nil); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+
+	if _, err := s.Trigger(context.Background(), "blocked", nil); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	if _, err := s.Trigger(context.Background(), "blocked", nil); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected ErrJobRunning, got %v", err)
+	}
+	close(release)
+	history.next(t)
+}
+
+func TestRunOnStart(t *testing.T) {
+	history := newMemoryHistory()
+	s := New(Options{}, history, clock.System(), idgen.NewSequence(), zerolog.Nop())
+	if err := s.Add(Job{Name: "partitions", Spec: "@daily", RunOnStart: true, Run: func(context.Context) error { return nil }}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	run := history.next(t)
+	if run.Trigger != TriggerSchedule || run.Status != StatusSucceeded {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Jobs()[0].NextRunAt == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("next run was not scheduled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	if info := s.Jobs()[0]; info.NextRunAt != nil || info.Running {
+		t.Fatalf("unexpected job state after stop: %+v", info)
+	}
+}
@@ -0,0 +1,192 @@
+// Package secrets получает секреты (DSN базы, ключи JWT, учётные данные камер и хранилища фото)
+// не только из значения переменной, но и из файла или Vault.
+//
+// Значение переменной может быть:
+//   - самим секретом;
+//   - ссылкой file:/run/secrets/db_dsn — секрет читается из файла (Docker/Kubernetes secrets);
+//   - ссылкой vault:secret/data/anpr#db_dsn — поле db_dsn секрета по пути secret/data/anpr в Vault
+//     (KV v2 или KV v1), адрес и токен — VAULT_ADDR и VAULT_TOKEN.
+//
+// Если переменная не задана, секрет читается из файла, указанного в <NAME>_FILE.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Префиксы ссылок на секреты
+const (
+	PrefixFile  = "file:"
+	PrefixVault = "vault:"
+)
+
+// FileSuffix — суффикс переменной с путём к файлу секрета (DB_DSN_FILE для DB_DSN)
+const FileSuffix = "_FILE"
+
+// defaultTimeout — таймаут запроса к Vault
+const defaultTimeout = 10 * time.Second
+
+// ErrVaultNotConfigured — ссылка на Vault без VAULT_ADDR
+var ErrVaultNotConfigured = errors.New("vault is not configured (VAULT_ADDR)")
+
+// Options — настройки провайдеров секретов
+type Options struct {
+	VaultAddr  string
+	VaultToken string
+	// VaultNamespace — пространство имён Vault Enterprise (пусто — не используется)
+	VaultNamespace string
+	Timeout        time.Duration
+}
+
+// Resolver раскрывает ссылки на секреты. Секреты Vault кешируются по пути: несколько полей одного
+// секрета читаются одним запросом.
+type Resolver struct {
+	opts Options
+	http *http.Client
+
+	mu    sync.Mutex
+	vault map[string]map[string]any
+}
+
+// NewResolver создаёт Resolver
+func NewResolver(opts Options) *Resolver {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	opts.VaultAddr = strings.TrimRight(strings.TrimSpace(opts.VaultAddr), "/")
+	return &Resolver{
+		opts:  opts,
+		http:  &http.Client{Timeout: opts.Timeout},
+		vault: map[string]map[string]any{},
+	}
+}
+
+// FromEnv создаёт Resolver по переменным окружения VAULT_ADDR, VAULT_TOKEN (или VAULT_TOKEN_FILE)
+// и VAULT_NAMESPACE
+func FromEnv() (*Resolver, error) {
+	token, err := NewResolver(Options{}).Lookup(context.Background(), "VAULT_TOKEN", os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return NewResolver(Options{
+		VaultAddr:      os.Getenv("VAULT_ADDR"),
+		VaultToken:     token,
+		VaultNamespace: strings.TrimSpace(os.Getenv("VAULT_NAMESPACE")),
+	}), nil
+}
+
+// Lookup возвращает секрет переменной name: её значение (со ссылками file: и vault:) или содержимое
+// файла из <name>_FILE. getenv — источник переменных (os.Getenv или настройки viper).
+func (r *Resolver) Lookup(ctx context.Context, name string, getenv func(string) string) (string, error) {
+	value := strings.TrimSpace(getenv(name))
+	if value == "" {
+		path := strings.TrimSpace(getenv(name + FileSuffix))
+		if path == "" {
+			return "", nil
+		}
+		value = PrefixFile + path
+	}
+	secret, err := r.Resolve(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// Resolve раскрывает ссылку на секрет; значение без префикса возвращается как есть
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, PrefixFile):
+		data, err := os.ReadFile(strings.TrimPrefix(value, PrefixFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		// Файлы секретов обычно заканчиваются переводом строки
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, PrefixVault):
+		return r.resolveVault(ctx, strings.TrimPrefix(value, PrefixVault))
+	default:
+		return value, nil
+	}
+}
+
+// resolveVault читает поле секрета по ссылке <path>#<field>
+func (r *Resolver) resolveVault(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference must be vault:<path>#<field>, got %q", PrefixVault+ref)
+	}
+	data, err := r.vaultSecret(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("vault secret %s field %q: %w", path, field, err)
+		}
+		return string(encoded), nil
+	}
+}
+
+// vaultSecret читает секрет Vault по пути. У KV v2 поля лежат в data.data, у KV v1 — в data.
+func (r *Resolver) vaultSecret(ctx context.Context, path string) (map[string]any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if data, ok := r.vault[path]; ok {
+		return data, nil
+	}
+	if r.opts.VaultAddr == "" {
+		return nil, ErrVaultNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.opts.VaultAddr+"/v1/"+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", r.opts.VaultToken)
+	if r.opts.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.opts.VaultNamespace)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, path, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := payload.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+	r.vault[path] = data
+	return data, nil
+}
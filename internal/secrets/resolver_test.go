@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolverLookup(t *testing.T) {
+	var vaultRequests int
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vaultRequests++
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/anpr":
+			_, _ = w.Write([]byte(`{"data": {"data": {"db_dsn": "host=db", "port": 5432}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/anpr":
+			_, _ = w.Write([]byte(`{"data": {"jwt": "kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", env: map[string]string{"SECRET": " plain "}, want: "plain"},
+		{name: "not set", env: map[string]string{}, want: ""},
+		{name: "file reference", env: map[string]string{"SECRET": "file:" + file}, want: "from-file"},
+		{name: "file variable", env: map[string]string{"SECRET_FILE": file}, want: "from-file"},
+		{name: "value wins over file variable", env: map[string]string{"SECRET": "plain", "SECRET_FILE": file}, want: "plain"},
+		{name: "missing file", env: map[string]string{"SECRET_FILE": file + ".missing"}, wantErr: true},
+		{name: "vault kv v2", env: map[string]string{"SECRET": "vault:secret/data/anpr#db_dsn"}, want: "host=db"},
+		{name: "vault non-string field", env: map[string]string{"SECRET": "vault:secret/data/anpr#port"}, want: "5432"},
+		{name: "vault kv v1", env: map[string]string{"SECRET": "vault:kv/anpr#jwt"}, want: "kv1-secret"},
+		{name: "vault missing field", env: map[string]string{"SECRET": "vault:secret/data/anpr#nope"}, wantErr: true},
+		{name: "vault missing secret", env: map[string]string{"SECRET": "vault:secret/data/other#key"}, wantErr: true},
+		{name: "vault reference without field", env: map[string]string{"SECRET": "vault:secret/data/anpr"}, wantErr: true},
+	}
+
+	r := NewResolver(Options{VaultAddr: vault.URL + "/", VaultToken: "vault-token"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Lookup(context.Background(), "SECRET", func(name string) string { return tt.env[name] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Поля одного секрета читаются одним запросом
+	before := vaultRequests
+	if _, err := r.Resolve(context.Background(), "vault:secret/data/anpr#db_dsn"); err != nil {
+		t.Fatal(err)
+	}
+	if vaultRequests != before {
+		t.Errorf("vault secret was requested again")
+	}
+
+	if _, err := NewResolver(Options{}).Resolve(context.Background(), "vault:secret/data/anpr#db_dsn"); err == nil {
+		t.Error("vault reference resolved without VAULT_ADDR")
+	}
+}
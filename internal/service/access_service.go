@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// accessFacts — данные, по которым движок правил принимает решение о доступе
+type accessFacts struct {
+	// Late — событие пришло с опозданием (EVENT_MAX_AGE): проезд давно состоялся, правила не применяются
+	Late bool
+	// BlacklistName — имя чёрного списка, в котором состоит номер (пусто — не состоит)
+	BlacklistName string
+	// OutOfSchedule — событие вне расписания камеры
+	OutOfSchedule bool
+	// ContractorSchedule — окна въезда подрядчика (пусто — без ограничений)
+	ContractorSchedule ArmedSchedule
+	Location           *time.Location
+	EventTime          time.Time
+	// Entry — событие является въездом (лимит рейсов считается только по въездам)
+	Entry bool
+	// MaxTripsPerNight — лимит въездов за ночь (0 — без ограничения)
+	MaxTripsPerNight int
+	// PaidTripsPerNight — квота оплачиваемых въездов за ночь (0 — без квоты)
+	PaidTripsPerNight int
+	// TripsTonight — уже разрешённые въезды номера с начала ночи
+	TripsTonight int64
+	// Storm — полигон события в режиме storm: расписание подрядчика не действует
+	Storm bool
+}
+
+// decideAccess применяет правила по порядку приоритета: чёрный список, расписание камеры,
+// расписание подрядчика, лимит рейсов за ночь. Первое сработавшее правило даёт DENY.
+// Въезд сверх квоты оплачиваемых рейсов разрешается с причиной over_quota. В режиме storm
+// расписание подрядчика не проверяется, а разрешённый въезд помечается в пояснении.
+// К опоздавшему событию правила не применяются: оно записывается с причиной late_event.
+func decideAccess(f accessFacts) anpr.AccessDecision {
+	if f.Late {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionAllow,
+			Reason:   anpr.ReasonLateEvent,
+			Detail:   "event arrived too late to be checked against access rules",
+		}
+	}
+	if f.BlacklistName != "" {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionDeny,
+			Reason:   anpr.ReasonBlacklisted,
+			Detail:   fmt.Sprintf("plate is in list %s", f.BlacklistName),
+		}
+	}
+	if f.OutOfSchedule {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionDeny,
+			Reason:   anpr.ReasonOutsideCameraSchedule,
+			Detail:   "event is outside camera armed schedule",
+		}
+	}
+	if !f.Storm && len(f.ContractorSchedule) > 0 && !f.ContractorSchedule.Contains(f.EventTime, f.Location) {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionDeny,
+			Reason:   anpr.ReasonOutsideContractorSchedule,
+			Detail:   "event is outside contractor access schedule",
+		}
+	}
+	if f.Entry && f.MaxTripsPerNight > 0 && f.TripsTonight >= int64(f.MaxTripsPerNight) {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionDeny,
+			Reason:   anpr.ReasonMaxTripsExceeded,
+			Detail:   fmt.Sprintf("%d of %d trips per night already used", f.TripsTonight, f.MaxTripsPerNight),
+		}
+	}
+	if f.Entry && f.PaidTripsPerNight > 0 && f.TripsTonight >= int64(f.PaidTripsPerNight) {
+		return anpr.AccessDecision{
+			Decision: anpr.DecisionAllow,
+			Reason:   anpr.ReasonOverQuota,
+			Detail:   fmt.Sprintf("%d of %d paid trips per night already used", f.TripsTonight, f.PaidTripsPerNight),
+		}
+	}
+	decision := anpr.AccessDecision{
+		Decision: anpr.DecisionAllow,
+		Reason:   anpr.ReasonRegisteredVehicle,
+	}
+	if f.Storm {
+		decision.Detail = "polygon is in storm mode"
+	}
+	return decision
+}
+
+// evaluateAccess собирает данные для правил по событию зарегистрированной машины (решение — decideAccess).
+// Ошибки получения данных логируются, соответствующее правило в этом случае не применяется.
+// Вместе с данными возвращаются списки номера (для подписчиков шины событий).
+func (s *ANPRService) evaluateAccess(ctx context.Context, plateID uuid.UUID, normalized string, contractorID, polygonID *uuid.UUID, camera *repository.Camera, direction string, eventTime time.Time, outOfSchedule, late bool) (accessFacts, []anpr.ListHit) {
+	facts := accessFacts{
+		Late:              late,
+		OutOfSchedule:     outOfSchedule,
+		Location:          s.cameraLocation(camera),
+		EventTime:         eventTime,
+		Entry:             direction == "entry",
+		MaxTripsPerNight:  s.Config().Access.MaxTripsPerNight,
+		PaidTripsPerNight: s.Config().Access.PaidTripsPerNight,
+	}
+
+	hits, err := s.findListsForPlate(ctx, plateID)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("plate_id", plateID.String()).Msg("failed to load plate lists for access decision")
+	}
+	for _, hit := range hits {
+		if strings.EqualFold(hit.ListType, "BLACKLIST") {
+			facts.BlacklistName = hit.ListName
+			break
+		}
+	}
+	// Правила и квоты к опоздавшему событию не применяются — данные для них не нужны
+	if late {
+		return facts, hits
+	}
+
+	if contractorID != nil {
+		rule, err := s.repo.GetContractorAccessRule(ctx, *contractorID)
+		if err != nil {
+			s.logger(ctx).Warn().Err(err).Str("contractor_id", contractorID.String()).Msg("failed to load contractor access rule")
+		}
+		if rule != nil {
+			if rule.Schedule != nil {
+				schedule, err := ParseArmedSchedule(*rule.Schedule)
+				if err != nil {
+					s.logger(ctx).Warn().Err(err).Str("contractor_id", contractorID.String()).Msg("invalid contractor access schedule, ignoring")
+				}
+				facts.ContractorSchedule = schedule
+			}
+			if rule.MaxTripsPerNight != nil {
+				facts.MaxTripsPerNight = *rule.MaxTripsPerNight
+			}
+			if rule.PaidTripsPerNight != nil {
+				facts.PaidTripsPerNight = *rule.PaidTripsPerNight
+			}
+		}
+	}
+
+	// Режим storm заменяет лимит рейсов подрядчика и лимит по умолчанию; квота оплачиваемых рейсов не меняется
+	if s.polygonMode(ctx, polygonID, eventTime) == anpr.ModeStorm {
+		facts.Storm = true
+		facts.MaxTripsPerNight = s.Config().Access.StormMaxTripsPerNight
+	}
+
+	// Квота номера приоритетнее квоты подрядчика; нужна только для въездов
+	if facts.Entry {
+		quota, err := s.repo.GetPlateTripQuota(ctx, normalized)
+		if err != nil {
+			s.logger(ctx).Warn().Err(err).Str("plate", normalized).Msg("failed to load plate trip quota")
+		}
+		if quota != nil {
+			facts.PaidTripsPerNight = quota.PaidTripsPerNight
+		}
+	}
+
+	if facts.Entry && (facts.MaxTripsPerNight > 0 || facts.PaidTripsPerNight > 0) {
+		from := nightStart(eventTime, facts.Location, s.Config().Access.NightStart)
+		count, err := s.repo.CountAllowedEntries(ctx, plateID, from, eventTime)
+		if err != nil {
+			s.logger(ctx).Warn().Err(err).Str("plate_id", plateID.String()).Msg("failed to count trips for access decision")
+		}
+		facts.TripsTonight = count
+	}
+
+	return facts, hits
+}
+
+// nightStart возвращает начало «ночи», которой принадлежит момент t: последний момент clock
+// (HH:MM в поясе loc), не позже t
+func nightStart(t time.Time, loc *time.Location, clock string) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	minutes := nightStartMinutes(clock)
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// nightStartMinutes — начало ночи clock (HH:MM) в минутах от полуночи; некорректное значение — полночь
+func nightStartMinutes(clock string) int {
+	minutes, err := parseClockMinutes(clock)
+	if err != nil {
+		return 0
+	}
+	return minutes
+}
+
+// eventDecision собирает решение о доступе из колонок события (nil — событие до появления движка правил)
+func eventDecision(decision, reason, detail *string) *anpr.AccessDecision {
+	if decision == nil {
+		return nil
+	}
+	result := &anpr.AccessDecision{Decision: *decision}
+	if reason != nil {
+		result.Reason = *reason
+	}
+	if detail != nil {
+		result.Detail = *detail
+	}
+	return result
+}
+
+// ContractorAccessRuleInfo — правила доступа подрядчика для API
+type ContractorAccessRuleInfo struct {
+	ContractorID     string  `json:"contractor_id"`
+	Schedule         *string `json:"schedule,omitempty"`
+	MaxTripsPerNight *int    `json:"max_trips_per_night,omitempty"`
+	// PaidTripsPerNight — квота оплачиваемых рейсов каждой машины подрядчика за ночь
+	PaidTripsPerNight *int      `json:"paid_trips_per_night,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type UpdateContractorAccessRuleInput struct {
+	Schedule          *string
+	MaxTripsPerNight  *int
+	PaidTripsPerNight *int
+}
+
+// ListContractorAccessRules возвращает правила доступа всех подрядчиков
+func (s *ANPRService) ListContractorAccessRules(ctx context.Context) ([]ContractorAccessRuleInfo, error) {
+	rules, err := s.repo.ListContractorAccessRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contractor access rules: %w", err)
+	}
+	result := make([]ContractorAccessRuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toContractorAccessRuleInfo(rule))
+	}
+	return result, nil
+}
+
+// UpdateContractorAccessRule задаёт расписание въезда, лимит и квоту оплачиваемых рейсов за ночь для
+// подрядчика. Пустое расписание снимает ограничение по времени, отрицательные лимит и квота возвращают
+// значения по умолчанию.
+func (s *ANPRService) UpdateContractorAccessRule(ctx context.Context, contractorID uuid.UUID, input UpdateContractorAccessRuleInput) (*ContractorAccessRuleInfo, error) {
+	rule, err := s.repo.GetContractorAccessRule(ctx, contractorID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		rule = &repository.ContractorAccessRule{ContractorID: contractorID}
+	}
+
+	if input.Schedule != nil {
+		schedule := strings.TrimSpace(*input.Schedule)
+		if schedule != "" {
+			if _, err := ParseArmedSchedule(schedule); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+			}
+			rule.Schedule = &schedule
+		} else {
+			rule.Schedule = nil
+		}
+	}
+	if input.MaxTripsPerNight != nil {
+		if *input.MaxTripsPerNight < 0 {
+			rule.MaxTripsPerNight = nil
+		} else {
+			limit := *input.MaxTripsPerNight
+			rule.MaxTripsPerNight = &limit
+		}
+	}
+	if input.PaidTripsPerNight != nil {
+		if *input.PaidTripsPerNight < 0 {
+			rule.PaidTripsPerNight = nil
+		} else {
+			quota := *input.PaidTripsPerNight
+			rule.PaidTripsPerNight = &quota
+		}
+	}
+
+	if err := s.repo.UpsertContractorAccessRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().Str("contractor_id", contractorID.String()).Msg("contractor access rule updated")
+
+	info := toContractorAccessRuleInfo(*rule)
+	return &info, nil
+}
+
+func toContractorAccessRuleInfo(rule repository.ContractorAccessRule) ContractorAccessRuleInfo {
+	return ContractorAccessRuleInfo{
+		ContractorID:      rule.ContractorID.String(),
+		Schedule:          rule.Schedule,
+		MaxTripsPerNight:  rule.MaxTripsPerNight,
+		PaidTripsPerNight: rule.PaidTripsPerNight,
+		CreatedAt:         rule.CreatedAt,
+		UpdatedAt:         rule.UpdatedAt,
+	}
+}
+
+// PlateTripQuotaInfo — квота оплачиваемых рейсов номера для API
+type PlateTripQuotaInfo struct {
+	Plate             string    `json:"plate"`
+	PaidTripsPerNight int       `json:"paid_trips_per_night"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ListPlateTripQuotas возвращает квоты рейсов, заданные отдельным номерам
+func (s *ANPRService) ListPlateTripQuotas(ctx context.Context) ([]PlateTripQuotaInfo, error) {
+	quotas, err := s.repo.ListPlateTripQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PlateTripQuotaInfo, 0, len(quotas))
+	for _, quota := range quotas {
+		result = append(result, toPlateTripQuotaInfo(quota))
+	}
+	return result, nil
+}
+
+// SetPlateTripQuota задаёт номеру квоту оплачиваемых рейсов за ночь; она приоритетнее квоты подрядчика.
+// Квота 0 — рейсы номера не оплачиваются вовсе.
+func (s *ANPRService) SetPlateTripQuota(ctx context.Context, plate string, paidTripsPerNight int) (*PlateTripQuotaInfo, error) {
+	normalized := utils.NormalizePlate(plate)
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: plate is required", ErrInvalidInput)
+	}
+	if paidTripsPerNight < 0 {
+		return nil, fmt.Errorf("%w: paid_trips_per_night must not be negative", ErrInvalidInput)
+	}
+
+	quota := &repository.PlateTripQuota{NormalizedPlate: normalized, PaidTripsPerNight: paidTripsPerNight}
+	existing, err := s.repo.GetPlateTripQuota(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		quota.CreatedAt = existing.CreatedAt
+	}
+	if err := s.repo.UpsertPlateTripQuota(ctx, quota); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().Str("plate", normalized).Int("paid_trips_per_night", paidTripsPerNight).Msg("plate trip quota updated")
+
+	info := toPlateTripQuotaInfo(*quota)
+	return &info, nil
+}
+
+// DeletePlateTripQuota снимает квоту номера: снова действует квота подрядчика или по умолчанию
+func (s *ANPRService) DeletePlateTripQuota(ctx context.Context, plate string) error {
+	normalized := utils.NormalizePlate(plate)
+	if normalized == "" {
+		return fmt.Errorf("%w: plate is required", ErrInvalidInput)
+	}
+	deleted, err := s.repo.DeletePlateTripQuota(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: plate %s has no trip quota", ErrNotFound, normalized)
+	}
+	s.logger(ctx).Info().Str("plate", normalized).Msg("plate trip quota removed")
+	return nil
+}
+
+func toPlateTripQuotaInfo(quota repository.PlateTripQuota) PlateTripQuotaInfo {
+	return PlateTripQuotaInfo{
+		Plate:             quota.NormalizedPlate,
+		PaidTripsPerNight: quota.PaidTripsPerNight,
+		CreatedAt:         quota.CreatedAt,
+		UpdatedAt:         quota.UpdatedAt,
+	}
+}
+
+// ContractorPolygonsInfo — полигоны, за которыми закреплён подрядчик
+type ContractorPolygonsInfo struct {
+	ContractorID string   `json:"contractor_id"`
+	PolygonIDs   []string `json:"polygon_ids"`
+}
+
+// ListContractorPolygons возвращает закрепления подрядчиков за полигонами
+func (s *ANPRService) ListContractorPolygons(ctx context.Context) ([]ContractorPolygonsInfo, error) {
+	assignments, err := s.repo.ListContractorPolygons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := []ContractorPolygonsInfo{}
+	index := map[uuid.UUID]int{}
+	for _, a := range assignments {
+		i, ok := index[a.ContractorID]
+		if !ok {
+			i = len(result)
+			index[a.ContractorID] = i
+			result = append(result, ContractorPolygonsInfo{ContractorID: a.ContractorID.String(), PolygonIDs: []string{}})
+		}
+		result[i].PolygonIDs = append(result[i].PolygonIDs, a.PolygonID.String())
+	}
+	return result, nil
+}
+
+// SetContractorPolygons закрепляет подрядчика за полигонами. Пустой набор снимает закрепление:
+// тогда машины подрядчика могут выгружаться на любом полигоне.
+func (s *ANPRService) SetContractorPolygons(ctx context.Context, contractorID uuid.UUID, polygonIDs []uuid.UUID) (*ContractorPolygonsInfo, error) {
+	unique := make([]uuid.UUID, 0, len(polygonIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range polygonIDs {
+		if id == uuid.Nil {
+			return nil, fmt.Errorf("%w: polygon_ids must not contain empty ids", ErrInvalidInput)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) > 0 {
+		existing, err := s.repo.FindPolygonIDs(ctx, unique)
+		if err != nil {
+			return nil, err
+		}
+		known := map[uuid.UUID]bool{}
+		for _, id := range existing {
+			known[id] = true
+		}
+		for _, id := range unique {
+			if !known[id] {
+				return nil, fmt.Errorf("%w: unknown polygon %s", ErrInvalidInput, id)
+			}
+		}
+	}
+
+	if err := s.repo.ReplaceContractorPolygons(ctx, contractorID, unique); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("contractor_id", contractorID.String()).
+		Int("polygons", len(unique)).
+		Msg("contractor polygons updated")
+
+	info := &ContractorPolygonsInfo{ContractorID: contractorID.String(), PolygonIDs: make([]string, 0, len(unique))}
+	for _, id := range unique {
+		info.PolygonIDs = append(info.PolygonIDs, id.String())
+	}
+	return info, nil
+}
+
+// isWrongDestination сообщает, что подрядчик закреплён за полигонами, но polygonID среди них нет.
+// Ошибка чтения закреплений не мешает приёму события: оно сохраняется без пометки.
+func (s *ANPRService) isWrongDestination(ctx context.Context, contractorID, polygonID *uuid.UUID) bool {
+	if contractorID == nil || polygonID == nil {
+		return false
+	}
+	assigned, err := s.repo.GetContractorPolygonIDs(ctx, *contractorID)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("contractor_id", contractorID.String()).Msg("failed to load contractor polygons")
+		return false
+	}
+	return len(assigned) > 0 && !slices.Contains(assigned, *polygonID)
+}
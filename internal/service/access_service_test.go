@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestDecideAccess(t *testing.T) {
+	night, err := ParseArmedSchedule("20:00-06:00")
+	if err != nil {
+		t.Fatalf("ParseArmedSchedule: %v", err)
+	}
+	at := func(hour int) time.Time {
+		return time.Date(2025, 1, 10, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		facts  accessFacts
+		result string
+		reason string
+	}{
+		{
+			name:   "registered vehicle",
+			facts:  accessFacts{EventTime: at(22), Entry: true},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:   "late event skips rules",
+			facts:  accessFacts{Late: true, BlacklistName: "default_blacklist", Entry: true, MaxTripsPerNight: 1, TripsTonight: 5, EventTime: at(22)},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonLateEvent,
+		},
+		{
+			name:   "blacklist takes precedence",
+			facts:  accessFacts{BlacklistName: "default_blacklist", OutOfSchedule: true, EventTime: at(22)},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonBlacklisted,
+		},
+		{
+			name:   "outside camera schedule",
+			facts:  accessFacts{OutOfSchedule: true, EventTime: at(22)},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonOutsideCameraSchedule,
+		},
+		{
+			name:   "outside contractor schedule",
+			facts:  accessFacts{ContractorSchedule: night, EventTime: at(12)},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonOutsideContractorSchedule,
+		},
+		{
+			name:   "inside contractor schedule",
+			facts:  accessFacts{ContractorSchedule: night, EventTime: at(2)},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:   "storm mode lifts contractor schedule",
+			facts:  accessFacts{ContractorSchedule: night, EventTime: at(12), Storm: true},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:   "storm mode keeps camera schedule",
+			facts:  accessFacts{OutOfSchedule: true, EventTime: at(12), Storm: true},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonOutsideCameraSchedule,
+		},
+		{
+			name:   "trip limit reached",
+			facts:  accessFacts{EventTime: at(23), Entry: true, MaxTripsPerNight: 3, TripsTonight: 3},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonMaxTripsExceeded,
+		},
+		{
+			name:   "trip limit does not apply to exit",
+			facts:  accessFacts{EventTime: at(23), MaxTripsPerNight: 3, TripsTonight: 5},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:   "entry over paid quota is allowed",
+			facts:  accessFacts{EventTime: at(23), Entry: true, PaidTripsPerNight: 2, TripsTonight: 2},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonOverQuota,
+		},
+		{
+			name:   "entry within paid quota",
+			facts:  accessFacts{EventTime: at(23), Entry: true, PaidTripsPerNight: 2, TripsTonight: 1},
+			result: anpr.DecisionAllow,
+			reason: anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:   "trip limit takes precedence over quota",
+			facts:  accessFacts{EventTime: at(23), Entry: true, MaxTripsPerNight: 3, PaidTripsPerNight: 2, TripsTonight: 3},
+			result: anpr.DecisionDeny,
+			reason: anpr.ReasonMaxTripsExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decideAccess(tt.facts)
+			if got.Decision != tt.result || got.Reason != tt.reason {
+				t.Fatalf("decideAccess() = %s/%s, want %s/%s", got.Decision, got.Reason, tt.result, tt.reason)
+			}
+		})
+	}
+}
+
+func TestNightStart(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"evening", time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC), time.Date(2025, 1, 10, 18, 0, 0, 0, time.UTC)},
+		{"after midnight", time.Date(2025, 1, 11, 3, 0, 0, 0, time.UTC), time.Date(2025, 1, 10, 18, 0, 0, 0, time.UTC)},
+		{"exactly at start", time.Date(2025, 1, 10, 18, 0, 0, 0, time.UTC), time.Date(2025, 1, 10, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nightStart(tt.t, time.UTC, "18:00"); !got.Equal(tt.want) {
+				t.Fatalf("nightStart() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWrongDestination(t *testing.T) {
+	contractorID, assigned, other := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		polygonID *uuid.UUID
+		assigned  []uuid.UUID
+		err       error
+		want      bool
+	}{
+		{name: "assigned polygon", polygonID: &assigned, assigned: []uuid.UUID{assigned}},
+		{name: "other polygon", polygonID: &other, assigned: []uuid.UUID{assigned}, want: true},
+		{name: "contractor without assignments", polygonID: &other},
+		{name: "lookup failure does not flag", polygonID: &other, err: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, &config.Config{})
+			store.EXPECT().GetContractorPolygonIDs(gomock.Any(), contractorID).Return(tt.assigned, tt.err)
+			if got := svc.isWrongDestination(context.Background(), &contractorID, tt.polygonID); got != tt.want {
+				t.Fatalf("isWrongDestination() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("camera without polygon", func(t *testing.T) {
+		svc, _ := newTestService(t, &config.Config{})
+		if svc.isWrongDestination(context.Background(), &contractorID, nil) {
+			t.Fatal("event without polygon must not be flagged")
+		}
+	})
+}
+
+func TestSetContractorPolygons(t *testing.T) {
+	contractorID, polygonA, polygonB := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("deduplicates and replaces", func(t *testing.T) {
+		svc, store := newTestService(t, &config.Config{})
+		store.EXPECT().FindPolygonIDs(gomock.Any(), []uuid.UUID{polygonA, polygonB}).Return([]uuid.UUID{polygonB, polygonA}, nil)
+		store.EXPECT().ReplaceContractorPolygons(gomock.Any(), contractorID, []uuid.UUID{polygonA, polygonB}).Return(nil)
+
+		info, err := svc.SetContractorPolygons(context.Background(), contractorID, []uuid.UUID{polygonA, polygonB, polygonA})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.PolygonIDs) != 2 {
+			t.Fatalf("got %v, want 2 polygons", info.PolygonIDs)
+		}
+	})
+
+	t.Run("unknown polygon", func(t *testing.T) {
+		svc, store := newTestService(t, &config.Config{})
+		store.EXPECT().FindPolygonIDs(gomock.Any(), []uuid.UUID{polygonA, polygonB}).Return([]uuid.UUID{polygonA}, nil)
+
+		_, err := svc.SetContractorPolygons(context.Background(), contractorID, []uuid.UUID{polygonA, polygonB})
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("err = %v, want ErrInvalidInput", err)
+		}
+	})
+
+	t.Run("empty set clears assignment", func(t *testing.T) {
+		svc, store := newTestService(t, &config.Config{})
+		store.EXPECT().ReplaceContractorPolygons(gomock.Any(), contractorID, []uuid.UUID{}).Return(nil)
+
+		if _, err := svc.SetContractorPolygons(context.Background(), contractorID, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/snow/calc"
+)
+
+const (
+	AnonymizedFormatCSV  = "csv"
+	AnonymizedFormatJSON = "json"
+
+	// anonymizedExportPageSize — размер страницы при выборке событий для обезличенной выгрузки
+	anonymizedExportPageSize = 1000
+)
+
+// ErrExportNotConfigured — не задан ключ EXPORT_ANONYMIZATION_KEY
+var ErrExportNotConfigured = errors.New("anonymized export is not configured")
+
+// AnonymizedEvent — событие без персональных данных: номер заменён HMAC-хешем,
+// фото и сырые данные камеры исключены, время округлено
+type AnonymizedEvent struct {
+	PlateHash    string     `json:"plate_hash"`
+	CameraID     string     `json:"camera_id"`
+	PolygonID    *uuid.UUID `json:"polygon_id,omitempty"`
+	ContractorID *uuid.UUID `json:"contractor_id,omitempty"`
+	Direction    *string    `json:"direction,omitempty"`
+	VehicleType  *string    `json:"vehicle_type,omitempty"`
+	EventTime    time.Time  `json:"event_time"`
+	SnowVolumeM3 *float64   `json:"snow_volume_m3,omitempty"`
+	MatchedSnow  bool       `json:"matched_snow"`
+}
+
+// ExportAnonymizedDataset формирует обезличенный набор событий (CSV или JSON) для передачи исследователям.
+// Один и тот же номер в пределах ключа всегда даёт один и тот же хеш, поэтому рейсы машины
+// можно связать между собой, но восстановить номер без ключа нельзя.
+func (s *ANPRService) ExportAnonymizedDataset(ctx context.Context, filters repository.ReportFilters, format string) ([]byte, string, error) {
+	key := s.Config().Export.AnonymizationKey
+	if key == "" {
+		return nil, "", ErrExportNotConfigured
+	}
+	if format == "" {
+		format = AnonymizedFormatCSV
+	}
+	if format != AnonymizedFormatCSV && format != AnonymizedFormatJSON {
+		return nil, "", fmt.Errorf("%w: format must be %q or %q", ErrInvalidInput, AnonymizedFormatCSV, AnonymizedFormatJSON)
+	}
+
+	if filters.MaxRows > 0 {
+		count, err := s.repo.CountReportEventsForExcel(ctx, filters)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to count events: %w", err)
+		}
+		if count > int64(filters.MaxRows) {
+			return nil, "", fmt.Errorf("%w: found %d rows, maximum allowed is %d", ErrTooManyRows, count, filters.MaxRows)
+		}
+	}
+
+	rounding := s.Config().Export.AnonymizedTimeRounding
+	events := make([]AnonymizedEvent, 0)
+	for offset := 0; ; offset += anonymizedExportPageSize {
+		page, err := s.repo.GetReportEventsForExcel(ctx, filters, anonymizedExportPageSize, offset)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get events for anonymized export: %w", err)
+		}
+		for _, e := range page {
+			events = append(events, AnonymizedEvent{
+				PlateHash:    anonymizePlate(key, e.NormalizedPlate),
+				CameraID:     e.CameraID,
+				PolygonID:    e.PolygonID,
+				ContractorID: e.ContractorID,
+				Direction:    e.Direction,
+				VehicleType:  e.VehicleType,
+				EventTime:    roundEventTime(e.EventTime, rounding),
+				SnowVolumeM3: e.SnowVolumeM3,
+				MatchedSnow:  e.MatchedSnow,
+			})
+		}
+		if len(page) < anonymizedExportPageSize {
+			break
+		}
+	}
+
+	// Выгрузка упорядочена по времени, чтобы порядок строк не выдавал группировку по номерам
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].EventTime.Before(events[j].EventTime)
+	})
+
+	filename := fmt.Sprintf("anpr-anonymized_%s_%s.%s", filters.From.Format("2006-01-02"), filters.To.Format("2006-01-02"), format)
+
+	if format == AnonymizedFormatJSON {
+		data, err := json.Marshal(events)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode anonymized dataset: %w", err)
+		}
+		return data, filename, nil
+	}
+
+	data, err := encodeAnonymizedCSV(events)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode anonymized dataset: %w", err)
+	}
+	return data, filename, nil
+}
+
+func encodeAnonymizedCSV(events []AnonymizedEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"plate_hash", "camera_id", "polygon_id", "contractor_id", "direction", "vehicle_type", "event_time", "snow_volume_m3", "matched_snow"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		record := []string{
+			e.PlateHash,
+			e.CameraID,
+			uuidOrEmpty(e.PolygonID),
+			uuidOrEmpty(e.ContractorID),
+			stringOrEmpty(e.Direction),
+			stringOrEmpty(e.VehicleType),
+			e.EventTime.Format(time.RFC3339),
+			"",
+			strconv.FormatBool(e.MatchedSnow),
+		}
+		if e.SnowVolumeM3 != nil {
+			record[7] = strconv.FormatFloat(*e.SnowVolumeM3, 'f', calc.VolumeDecimals, 64)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// anonymizePlate возвращает HMAC-SHA256 нормализованного номера в hex
+func anonymizePlate(key, normalizedPlate string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(normalizedPlate))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// roundEventTime округляет время вниз до шага step (в UTC)
+func roundEventTime(t time.Time, step time.Duration) time.Time {
+	if step <= 0 {
+		return t.UTC()
+	}
+	return t.UTC().Truncate(step)
+}
+
+func uuidOrEmpty(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
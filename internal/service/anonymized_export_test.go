@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnonymizePlate(t *testing.T) {
+	first := anonymizePlate("secret", "123ABC02")
+	if first != anonymizePlate("secret", "123ABC02") {
+		t.Fatal("hash must be stable for the same key and plate")
+	}
+	if first == anonymizePlate("other", "123ABC02") {
+		t.Fatal("hash must depend on the key")
+	}
+	if first == anonymizePlate("secret", "124ABC02") {
+		t.Fatal("hash must depend on the plate")
+	}
+	if len(first) != 64 {
+		t.Fatalf("expected hex-encoded sha256, got %d chars", len(first))
+	}
+}
+
+func TestRoundEventTime(t *testing.T) {
+	at := time.Date(2025, 1, 15, 23, 47, 12, 0, time.FixedZone("UTC+5", 5*60*60))
+
+	if got, want := roundEventTime(at, time.Hour), time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("roundEventTime(hour) = %v, want %v", got, want)
+	}
+	if got, want := roundEventTime(at, 15*time.Minute), time.Date(2025, 1, 15, 18, 45, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("roundEventTime(15m) = %v, want %v", got, want)
+	}
+	if got := roundEventTime(at, 0); !got.Equal(at) {
+		t.Fatalf("roundEventTime(0) = %v, want unchanged", got)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,8 +13,17 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/xuri/excelize/v2"
 
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/eventbus"
+	"anpr-service/internal/i18n"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/logctx"
 	"anpr-service/internal/repository"
+	"anpr-service/internal/scheduler"
+	"anpr-service/internal/snow/calc"
 	"anpr-service/internal/utils"
 )
 
@@ -21,26 +31,98 @@ import (
 var kzLocation = time.FixedZone("Asia/Qyzylorda", 5*60*60)
 
 var (
-	ErrInvalidInput          = errors.New("invalid input")
-	ErrNotFound              = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNotFound — то же, что repository.ErrNotFound: ошибки «не найдено» репозитория (ErrPlateNotFound,
+	// ErrEventNotFound) проходят через сервис без перевода и дают 404
+	ErrNotFound              = repository.ErrNotFound
+	ErrPlateNotFound         = repository.ErrPlateNotFound
+	ErrEventNotFound         = repository.ErrEventNotFound
 	ErrVehicleNotWhitelisted = errors.New("vehicle not whitelisted")
 	ErrDuplicateEvent        = errors.New("duplicate recent event")
 	ErrTooManyRows           = errors.New("too many rows for export")
 )
 
 type ANPRService struct {
-	repo *repository.ANPRRepository
-	log  zerolog.Logger
+	repo repository.ANPRStore
+	bus  eventbus.Bus
+	// live — действующая конфигурация, часть настроек перезагружается без перезапуска (см. Config)
+	live  *config.Live
+	log   zerolog.Logger
+	quota quotaTracker
+	lists listMembershipCache
+	clock clock.Clock
+	ids   idgen.Generator
+	// archive — хранилище вынесенных из БД payload событий (nil — не настроено)
+	archive PayloadArchive
+	// vehicles — roles-сервис для поиска транспорта (nil — таблица vehicles общей БД)
+	vehicles VehicleDirectory
+	// adapters — адаптеры форматов камер для приёма и повтора непринятых уведомлений
+	adapters *ingest.Registry
+	// jobs — планировщик периодических задач (nil — не создан, см. NewScheduler)
+	jobs *scheduler.Scheduler
+	// panics — перехваченные паники приёма (см. IngestPanicStats)
+	panics panicCounter
+	// dataLake — хранилище ночной выгрузки событий (nil — не настроено)
+	dataLake DataLakeStore
+	// reportTelegram и reportMailer — доставка выгрузок сохранённых поисков (nil — канал не настроен)
+	reportTelegram ReportTelegram
+	reportMailer   ReportMailer
+	// usage — счётчики использования по организациям до сброса в БД (см. FlushUsage)
+	usage usageMeter
+	// latency — задержки приёма событий камер для метрик SLO (см. WriteMetrics)
+	latency latencyTracker
+	// flags — переопределения флагов возможностей (см. FeatureEnabled)
+	flags featureFlagCache
+	// dbStats — пул соединений БД для метрик (nil — не выводится, см. UseDBStats)
+	dbStats DBStatsSource
 }
 
-func NewANPRService(repo *repository.ANPRRepository, log zerolog.Logger) *ANPRService {
-	return &ANPRService{
-		repo: repo,
-		log:  log,
+func NewANPRService(repo repository.ANPRStore, bus eventbus.Bus, cfg *config.Config, log zerolog.Logger, clk clock.Clock, ids idgen.Generator) *ANPRService {
+	s := &ANPRService{
+		repo:  repo,
+		bus:   bus,
+		live:  config.NewLive(cfg),
+		log:   log,
+		clock: clk,
+		ids:   ids,
+	}
+	s.adapters = s.newIngestRegistry()
+	return s
+}
+
+// Config возвращает действующую конфигурацию. Её читают при каждом обращении, а не сохраняют:
+// перезагрузка подменяет конфигурацию целиком.
+func (s *ANPRService) Config() *config.Config {
+	return s.live.Get()
+}
+
+// Now возвращает текущее время по часам сервиса
+func (s *ANPRService) Now() time.Time {
+	return s.clock.Now()
+}
+
+// NewEventID выдаёт идентификатор для нового события
+func (s *ANPRService) NewEventID() uuid.UUID {
+	return s.ids.NewID()
+}
+
+// logger возвращает логгер запроса (с request_id), а вне запроса — логгер сервиса
+func (s *ANPRService) logger(ctx context.Context) *zerolog.Logger {
+	return logctx.From(ctx, &s.log)
+}
+
+// ProcessIncomingEvent сохраняет событие камеры. Паника при обработке возвращается как *ingest.PanicError.
+func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string, eventID uuid.UUID, photoURLs []string) (result *anpr.ProcessResult, err error) {
+	defer s.recoverIngest(ctx, ingest.StageProcess, "", &err)
+	result, err = s.processIncomingEvent(ctx, payload, defaultCameraModel, eventID, photoURLs)
+	// Событие сохранено, в том числе с отказом по белому списку
+	if err == nil || errors.Is(err, ErrVehicleNotWhitelisted) {
+		s.recordIngestUsage(payload.CameraID, 1, 0)
 	}
+	return result, err
 }
 
-func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string, eventID uuid.UUID, photoURLs []string) (*anpr.ProcessResult, error) {
+func (s *ANPRService) processIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string, eventID uuid.UUID, photoURLs []string) (*anpr.ProcessResult, error) {
 	if payload.Plate == "" {
 		return nil, fmt.Errorf("%w: plate is required", ErrInvalidInput)
 	}
@@ -51,44 +133,127 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		return nil, fmt.Errorf("%w: event_time is required", ErrInvalidInput)
 	}
 
-	normalized := utils.NormalizePlate(payload.Plate)
+	source := strings.ToLower(strings.TrimSpace(payload.Source))
+	if source == "" {
+		source = anpr.SourceCamera
+	}
+	if !anpr.IsEventSource(source) {
+		return nil, fmt.Errorf("%w: source must be one of %s", ErrInvalidInput, strings.Join(anpr.EventSources(), ", "))
+	}
+	payload.Source = source
+
+	plate := utils.ParsePlate(payload.Plate)
+	normalized := plate.Normalized
 	if normalized == "" {
 		return nil, fmt.Errorf("%w: plate cannot be empty after normalization", ErrInvalidInput)
 	}
+	// Страна по формату номера точнее страны, которую сообщает камера
+	country := plate.Country
+	if country == "" {
+		country = strings.ToUpper(strings.TrimSpace(payload.Vehicle.Country))
+	}
 
-	// Дедупликация: если тот же номер с этой камеры уже был в окне ±5 минут — считаем дублем
-	recent, err := s.repo.ExistsRecentEvent(ctx, normalized, payload.CameraID, payload.EventTime, 5*time.Minute)
+	// Мусорные распознавания не должны создавать записи в anpr_plates
+	if violation := plateViolation(normalized, s.plateRule(country)); violation != "" {
+		if s.Config().Plate.Policy == config.PlatePolicyFlag {
+			if err := s.repo.CreateRejectedEvent(ctx, eventID, nil, repository.RejectReasonInvalidPlate, normalized, payload.Plate, payload.CameraID, payload.EventTime, &payload, photoURLs); err != nil {
+				s.logger(ctx).Error().Err(err).Str("plate", normalized).Msg("failed to save invalid plate event to anpr_events_rejected")
+			}
+		}
+		s.logger(ctx).Warn().
+			Str("plate", normalized).
+			Str("camera_id", payload.CameraID).
+			Str("country", country).
+			Str("policy", s.Config().Plate.Policy).
+			Str("violation", violation).
+			Msg("plate failed guardrails")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, violation)
+	}
+
+	// Известные ошибки распознавания (псевдонимы) сразу относятся к основному номеру
+	normalized, err := s.resolvePlateAlias(ctx, normalized)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check duplicate event: %w", err)
+		return nil, fmt.Errorf("failed to resolve plate alias: %w", err)
 	}
-	if recent {
-		s.log.Warn().
+
+	// Время приёма: ретрансляторы и импорт передают исходное received_at, иначе — время сервера
+	receivedAt := s.clock.Now()
+	if payload.ReceivedAt != nil && !payload.ReceivedAt.IsZero() && !payload.ReceivedAt.After(receivedAt) {
+		receivedAt = *payload.ReceivedAt
+	}
+	payload.ReceivedAt = &receivedAt
+
+	// Учитываем расхождение часов камеры; при включённой автокоррекции event_time сдвигается
+	camera := s.lookupCamera(ctx, payload.CameraID)
+	clockCorrection := s.observeCameraClock(ctx, camera, &payload, receivedAt)
+
+	// Камера после перезагрузки переотправляет события многочасовой давности: такое событие сохраняется
+	// с пометкой late, а расхождение времени объясняется опозданием, а не часами камеры
+	late := s.isLateEvent(&payload, receivedAt)
+	if late {
+		s.logger(ctx).Warn().
+			Str("plate", normalized).
+			Str("camera_id", payload.CameraID).
+			Time("event_time", payload.EventTime).
+			Dur("age", receivedAt.Sub(payload.EventTime)).
+			Msg("event arrived later than max event age, flagging late")
+	}
+
+	// Проверка расхождения часов камеры и сервера
+	eventTimeSkewed := false
+	if skew := receivedAt.Sub(payload.EventTime); !late && s.Config().Ingest.MaxClockSkew > 0 && absDuration(skew) > s.Config().Ingest.MaxClockSkew {
+		if s.Config().Ingest.ClockSkewPolicy == config.ClockSkewPolicyReject {
+			s.logger(ctx).Warn().
+				Str("plate", normalized).
+				Str("camera_id", payload.CameraID).
+				Time("event_time", payload.EventTime).
+				Dur("skew", skew).
+				Msg("event_time skew exceeds limit, rejecting event")
+			return nil, fmt.Errorf("%w: event_time differs from server time by %s (max %s)", ErrInvalidInput, skew.Round(time.Second), s.Config().Ingest.MaxClockSkew)
+		}
+		eventTimeSkewed = true
+		s.logger(ctx).Warn().
 			Str("plate", normalized).
 			Str("camera_id", payload.CameraID).
-			Msg("duplicate event detected within 5 minutes, skipping save")
-		return nil, ErrDuplicateEvent
+			Time("event_time", payload.EventTime).
+			Dur("skew", skew).
+			Msg("event_time skew exceeds limit, flagging event")
 	}
 
-	plateID, err := s.repo.GetOrCreatePlate(ctx, normalized, payload.Plate)
-	if err != nil {
-		s.log.Error().
-			Err(err).
-			Str("normalized", normalized).
-			Str("original", payload.Plate).
-			Msg("failed to get or create plate")
-		return nil, fmt.Errorf("failed to get or create plate: %w", err)
+	// События вне расписания камеры сохраняются, но не участвуют в подсчёте рейсов и оповещениях
+	outOfSchedule := !s.cameraArmed(camera, payload.EventTime)
+	if outOfSchedule {
+		s.logger(ctx).Info().
+			Str("plate", normalized).
+			Str("camera_id", payload.CameraID).
+			Time("event_time", payload.EventTime).
+			Msg("event outside camera armed schedule, flagging out_of_schedule")
 	}
 
-	s.log.Info().
-		Str("plate_id", plateID.String()).
-		Str("normalized", normalized).
-		Str("original", payload.Plate).
-		Msg("plate retrieved or created successfully")
+	// Дедупликация: если тот же номер с этой камеры уже был в окне ±5 минут — считаем дублем
+	// (флаг dedup можно выключить для камеры, например при проверке двух камер на одном въезде)
+	if s.FeatureEnabled(ctx, config.FeatureDedup, payload.CameraID) {
+		recent, err := s.repo.ExistsRecentEvent(ctx, normalized, payload.CameraID, payload.EventTime, 5*time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check duplicate event: %w", err)
+		}
+		if recent {
+			s.logger(ctx).Warn().
+				Str("plate", normalized).
+				Str("camera_id", payload.CameraID).
+				Msg("duplicate event detected within 5 minutes, skipping save")
+			return nil, ErrDuplicateEvent
+		}
+	}
 
+	// Псевдоним мог заменить номер: страна определяется по основному номеру
+	if normalized != plate.Normalized {
+		plate = utils.ParsePlate(normalized)
+	}
 	// Получаем данные о транспорте из vehicles ДО сохранения события
-	vehicleData, err := s.repo.GetVehicleByPlate(ctx, normalized)
+	vehicleData, err := s.vehicleByPlate(ctx, normalized)
 	if err != nil {
-		s.log.Error().
+		s.logger(ctx).Error().
 			Err(err).
 			Str("plate", normalized).
 			Msg("failed to get vehicle data")
@@ -115,7 +280,7 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		}
 		payload.RawPayload["vehicle_year"] = vehicleData.Year
 
-		s.log.Info().
+		s.logger(ctx).Info().
 			Str("plate", normalized).
 			Str("brand", vehicleData.Brand).
 			Str("model", vehicleData.Model).
@@ -123,15 +288,22 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 			Float64("body_volume_m3", vehicleData.BodyVolumeM3).
 			Msg("vehicle data loaded from vehicles table")
 	} else {
-		s.log.Warn().
+		s.logger(ctx).Warn().
 			Str("plate", normalized).
 			Msg("vehicle not found in vehicles table (whitelist check failed)")
-		// Сохраняем отклонённое событие в anpr_events_rejected для последующего разбора
-		if errRej := s.repo.CreateRejectedEvent(ctx, eventID, plateID, normalized, payload.Plate, payload.CameraID, payload.EventTime, &payload, photoURLs); errRej != nil {
-			s.log.Error().Err(errRej).Str("plate", normalized).Msg("failed to save rejected event to anpr_events_rejected")
+		// Сохраняем отклонённое событие в anpr_events_rejected для последующего разбора; номер заводится,
+		// чтобы отклонённые проезды были видны в его истории
+		var rejectedPlateID *uuid.UUID
+		if id, errPlate := s.repo.GetOrCreatePlate(ctx, normalized, payload.Plate, plate.Country, plate.Region); errPlate != nil {
+			s.logger(ctx).Error().Err(errPlate).Str("plate", normalized).Msg("failed to get or create plate for rejected event")
+		} else {
+			rejectedPlateID = &id
+		}
+		if errRej := s.repo.CreateRejectedEvent(ctx, eventID, rejectedPlateID, repository.RejectReasonVehicleNotWhitelist, normalized, payload.Plate, payload.CameraID, payload.EventTime, &payload, photoURLs); errRej != nil {
+			s.logger(ctx).Error().Err(errRej).Str("plate", normalized).Msg("failed to save rejected event to anpr_events_rejected")
 			// Не меняем ответ клиенту — всё равно возвращаем ErrVehicleNotWhitelisted
 		} else {
-			s.log.Info().Str("plate", normalized).Str("event_id", eventID.String()).Msg("rejected event saved to anpr_events_rejected")
+			s.logger(ctx).Info().Str("plate", normalized).Str("event_id", eventID.String()).Msg("rejected event saved to anpr_events_rejected")
 		}
 		return nil, fmt.Errorf("%w: vehicle not found in vehicles table", ErrVehicleNotWhitelisted)
 	}
@@ -141,19 +313,32 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		cameraModel = defaultCameraModel
 	}
 
-	// Direction: если камера не дала direction или пришёл "unknown",
-	// ставим "entry" по умолчанию, чтобы события учитывались в tickets-сервисе.
-	dir := strings.ToLower(payload.Direction)
-	if dir == "" || dir == "unknown" {
-		dir = "entry"
+	// Направление, которого камера не прислала, выводится по настройкам камеры (см. resolveDirection)
+	var directionSource string
+	payload.Direction, directionSource = s.resolveDirection(ctx, camera, &payload, normalized)
+	if directionSource != anpr.DirectionSourceReported {
+		s.logger(ctx).Debug().
+			Str("camera_id", payload.CameraID).
+			Str("direction", payload.Direction).
+			Str("direction_source", directionSource).
+			Msg("event direction inferred")
 	}
-	payload.Direction = dir
+
+	// Тип ТС: камеры присылают его в разных словарях (VTR-коды, классы GAT, свободный текст),
+	// поэтому сохраняем исходное значение отдельно, а в vehicle_type — каноническое
+	rawVehicleType := strings.TrimSpace(payload.Vehicle.Type)
+	payload.Vehicle.Type = anpr.NormalizeVehicleType(rawVehicleType)
 
 	event := &anpr.Event{
-		ID:              eventID, // Use pre-generated ID
-		PlateID:         plateID,
-		EventPayload:    payload,
-		NormalizedPlate: normalized,
+		ID:                     eventID, // Use pre-generated ID
+		EventPayload:           payload,
+		NormalizedPlate:        normalized,
+		EventTimeSkewed:        eventTimeSkewed,
+		ClockCorrectionSeconds: clockCorrection,
+		OutOfSchedule:          outOfSchedule,
+		Late:                   late,
+		VehicleTypeRaw:         rawVehicleType,
+		DirectionSource:        directionSource,
 	}
 	event.CameraModel = cameraModel
 
@@ -195,39 +380,39 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		event.SnowVolumeConfidence = &defaultConfidence
 	}
 
-	// Вычисляем snow_volume_m3 на основе процента и объема кузова
-	// Формула: snow_volume_m3 = (snow_volume_percentage / 100) * body_volume_m3
-	// Вычисляем только если есть процент (даже если 0) И vehicle найден И у vehicle есть объем
-	if event.SnowVolumePercentage != nil {
-		s.log.Info().
-			Float64("snow_volume_percentage", *event.SnowVolumePercentage).
-			Bool("vehicle_exists", vehicleExists).
-			Bool("matched_snow", event.MatchedSnow).
-			Msg("checking conditions for volume calculation")
-
-		if vehicleExists && vehicleData.BodyVolumeM3 > 0 {
-			volumeM3 := (*event.SnowVolumePercentage / 100.0) * vehicleData.BodyVolumeM3
-			event.SnowVolumeM3 = &volumeM3
-			s.log.Info().
-				Float64("percentage", *event.SnowVolumePercentage).
-				Float64("body_volume_m3", vehicleData.BodyVolumeM3).
-				Float64("snow_volume_m3", volumeM3).
-				Msg("calculated snow volume in m3")
-		} else {
-			if !vehicleExists {
-				s.log.Warn().
-					Str("plate", normalized).
-					Msg("cannot calculate snow_volume_m3: vehicle not found")
-			} else if vehicleData.BodyVolumeM3 <= 0 {
-				s.log.Warn().
-					Str("plate", normalized).
-					Float64("body_volume_m3", vehicleData.BodyVolumeM3).
-					Msg("cannot calculate snow_volume_m3: body_volume_m3 is zero or negative")
-			}
+	// Объём снега в м³ считается по правилам calc: процент заполнения × объём кузова из справочника,
+	// иначе кубометры анализатора; объём округляется до сотых, как в актах
+	bodyVolumeM3 := 0.0
+	if vehicleExists {
+		bodyVolumeM3 = vehicleData.BodyVolumeM3
+	}
+	volume := calc.Resolve(calc.Reading{Percentage: event.SnowVolumePercentage, VolumeM3: payload.SnowVolumeM3}, bodyVolumeM3)
+	event.SnowVolumePercentage = &volume.Percentage
+	event.SnowVolumeM3 = volume.M3
+	switch {
+	case volume.M3 != nil:
+		s.logger(ctx).Info().
+			Float64("percentage", volume.Percentage).
+			Float64("body_volume_m3", bodyVolumeM3).
+			Float64("snow_volume_m3", *volume.M3).
+			Str("volume_source", volume.Source).
+			Msg("calculated snow volume in m3")
+		if volume.Mismatch {
+			s.logger(ctx).Warn().
+				Str("plate", normalized).
+				Float64("analyzer_volume_m3", *payload.SnowVolumeM3).
+				Float64("snow_volume_m3", *volume.M3).
+				Msg("analyzer snow volume disagrees with percentage, using percentage")
 		}
-	} else {
-		s.log.Warn().
-			Msg("cannot calculate snow_volume_m3: snow_volume_percentage is nil")
+	case !vehicleExists:
+		s.logger(ctx).Warn().
+			Str("plate", normalized).
+			Msg("cannot calculate snow_volume_m3: vehicle not found")
+	default:
+		s.logger(ctx).Warn().
+			Str("plate", normalized).
+			Float64("body_volume_m3", bodyVolumeM3).
+			Msg("cannot calculate snow_volume_m3: body_volume_m3 is zero or negative")
 	}
 
 	// matched_snow всегда берем из payload (если есть в JSON, иначе из RawPayload)
@@ -247,42 +432,69 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		contractorID = vehicleData.ContractorID
 	}
 
-	polygonID, err := s.repo.ResolvePolygonIDByCameraID(ctx, payload.CameraID)
+	polygonID := s.cameraPolygonID(ctx, camera, payload.CameraID)
+
+	// Машина подрядчика на полигоне, за которым подрядчик не закреплён, — событие сохраняется с пометкой
+	event.WrongDestination = s.isWrongDestination(ctx, contractorID, polygonID)
+	if event.WrongDestination {
+		s.logger(ctx).Warn().
+			Str("plate", normalized).
+			Str("camera_id", payload.CameraID).
+			Str("contractor_id", contractorID.String()).
+			Str("polygon_id", polygonID.String()).
+			Msg("vehicle arrived at a polygon its contractor is not assigned to")
+	}
+
+	// Номер создаётся только в транзакции события (SaveANPREvent). Для решения о доступе достаточно уже
+	// заведённого: у нового номера нет ни списков, ни рейсов
+	plateID, err := s.existingPlateID(ctx, normalized)
 	if err != nil {
-		s.log.Warn().
-			Err(err).
+		s.logger(ctx).Error().Err(err).Str("plate", normalized).Msg("failed to find plate")
+		return nil, fmt.Errorf("failed to find plate: %w", err)
+	}
+
+	// Решение о доступе по правилам (чёрный список, расписания, лимит и квота рейсов)
+	facts, listHits := s.evaluateAccess(ctx, plateID, normalized, contractorID, polygonID, camera, payload.Direction, payload.EventTime, outOfSchedule, late)
+	decision := decideAccess(facts)
+	event.Decision = &decision
+	event.OverQuota = decision.Reason == anpr.ReasonOverQuota
+	if event.OverQuota {
+		s.logger(ctx).Info().
+			Str("plate", normalized).
 			Str("camera_id", payload.CameraID).
-			Msg("failed to resolve polygon_id by camera_id")
+			Str("detail", decision.Detail).
+			Msg("entry is over the paid trip quota")
+	}
+	if decision.Decision == anpr.DecisionDeny {
+		s.logger(ctx).Info().
+			Str("plate", normalized).
+			Str("camera_id", payload.CameraID).
+			Str("reason", decision.Reason).
+			Str("detail", decision.Detail).
+			Msg("access denied by rules")
 	}
 
-	// Сохраняем событие с данными из vehicles (если vehicle найден)
-	if err := s.repo.CreateANPREvent(ctx, event, contractorID, polygonID); err != nil {
-		s.log.Error().
+	// Исходный payload в режиме INGEST_RAW_PAYLOAD_STORAGE=storage уходит в хранилище, в БД — только ключ
+	s.archiveRawPayload(ctx, event)
+
+	// Событие сохраняется одной транзакцией с номером и фото: параллельные первые проезды нового номера
+	// не падают на уникальном индексе, а событие не остаётся без фото — ошибка сохранения фото отклоняет приём
+	plateID, err = s.repo.SaveANPREvent(ctx, event, plate.Country, plate.Region, contractorID, polygonID, photoURLs)
+	if err != nil {
+		s.logger(ctx).Error().
 			Err(err).
 			Str("plate", normalized).
 			Str("camera_id", payload.CameraID).
+			Int("photos_count", len(photoURLs)).
 			Msg("failed to create ANPR event")
 		return nil, fmt.Errorf("failed to create ANPR event: %w", err)
 	}
-
-	// Сохраняем фотографии (если есть)
-	if len(photoURLs) > 0 {
-		if err := s.repo.CreateEventPhotos(ctx, eventID, photoURLs); err != nil {
-			s.log.Warn().
-				Err(err).
-				Str("event_id", eventID.String()).
-				Int("photos_count", len(photoURLs)).
-				Msg("failed to save event photos")
-			// Don't fail the whole request if photos fail
-		} else {
-			s.log.Info().
-				Str("event_id", eventID.String()).
-				Int("photos_count", len(photoURLs)).
-				Msg("saved event photos")
-		}
+	// Импорт и ручной ввод приходят с задержкой по определению — в SLO приёма учитываются только камеры
+	if payload.Source == anpr.SourceCamera {
+		s.observeIngestLatency(payload.CameraID, payload.EventTime)
 	}
 
-	s.log.Info().
+	s.logger(ctx).Info().
 		Str("event_id", event.ID.String()).
 		Str("plate_id", plateID.String()).
 		Str("plate", normalized).
@@ -293,13 +505,30 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		Time("event_time", payload.EventTime).
 		Msg("saved ANPR event to database")
 
+	// Въезд, пришедший не по порядку, сдвигает квоту уже принятых въездов ночи — рейсы номера пересчитываются.
+	// Опоздавший въезд сам в квоту не входит, но ночь пересчитывается и для него: её статусы могли разойтись,
+	// пока камера не могла доставить события.
+	if facts.Entry && facts.PaidTripsPerNight > 0 && decision.Decision == anpr.DecisionAllow {
+		from := nightStart(payload.EventTime, facts.Location, s.Config().Access.NightStart)
+		s.repairTrips(ctx, event, TripChangeOutOfOrder, repository.TripQuotaRepair{
+			PlateID:           plateID,
+			From:              from,
+			To:                from.AddDate(0, 0, 1),
+			TimeZone:          facts.Location.String(),
+			NightStartMinutes: nightStartMinutes(s.Config().Access.NightStart),
+			PaidTripsPerNight: facts.PaidTripsPerNight,
+		})
+	}
+
+	s.publishEventCreated(ctx, event, contractorID, polygonID, vehicleExists, photoURLs, listHits)
+
 	if vehicleExists {
-		s.log.Info().
+		s.logger(ctx).Info().
 			Str("plate_id", plateID.String()).
 			Str("plate", normalized).
 			Msg("vehicle found in vehicles table - access granted")
 	} else {
-		s.log.Info().
+		s.logger(ctx).Info().
 			Str("plate_id", plateID.String()).
 			Str("plate", normalized).
 			Msg("vehicle not found in vehicles table - access denied")
@@ -312,9 +541,40 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		VehicleExists: vehicleExists,
 		Hits:          []anpr.ListHit{}, // Оставляем пустым для обратной совместимости
 		PhotoURLs:     photoURLs,
+		Decision:      event.Decision,
+		Lists:         listHits,
+		ContractorID:  contractorID,
+		TripID:        tripID(event),
 	}, nil
 }
 
+// existingPlateID возвращает ID номера normalized или uuid.Nil, если номер ещё не заведён
+func (s *ANPRService) existingPlateID(ctx context.Context, normalized string) (uuid.UUID, error) {
+	plates, err := s.repo.FindPlatesByNormalized(ctx, normalized)
+	if err != nil || len(plates) == 0 {
+		return uuid.Nil, err
+	}
+	return plates[0].ID, nil
+}
+
+// isLateEvent сообщает, что событие камеры пришло позже EVENT_MAX_AGE после своего времени. Проверяются
+// только события камер: импорт и ручной ввод задним числом — обычное дело, а ретрансляторы передают
+// исходное received_at, поэтому буферизованные ими события опоздавшими не считаются.
+func (s *ANPRService) isLateEvent(payload *anpr.EventPayload, receivedAt time.Time) bool {
+	maxAge := s.Config().Ingest.MaxEventAge
+	return maxAge > 0 && payload.Source == anpr.SourceCamera && receivedAt.Sub(payload.EventTime) > maxAge
+}
+
+// tripID возвращает ID события, если оно учитывается в отчётах как рейс (по тем же условиям, что и
+// GetReportStats: есть вывезенный объём, событие не вне расписания камеры, не опоздавшее и не сверх квоты)
+func tripID(event *anpr.Event) *uuid.UUID {
+	if event.OutOfSchedule || event.Late || event.OverQuota || event.SnowVolumeM3 == nil || *event.SnowVolumeM3 <= 0 {
+		return nil
+	}
+	id := event.ID
+	return &id
+}
+
 func (s *ANPRService) FindPlates(ctx context.Context, plateQuery string) ([]PlateInfo, error) {
 	normalized := utils.NormalizePlate(plateQuery)
 	if normalized == "" {
@@ -326,14 +586,27 @@ func (s *ANPRService) FindPlates(ctx context.Context, plateQuery string) ([]Plat
 		return nil, fmt.Errorf("failed to find plates: %w", err)
 	}
 
+	plateIDs := make([]uuid.UUID, 0, len(plates))
+	for _, p := range plates {
+		plateIDs = append(plateIDs, p.ID)
+	}
+	// Время последнего события необязательно для ответа: при ошибке номера возвращаются без него
+	lastEventTimes, err := s.repo.GetLastEventTimes(ctx, plateIDs)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Int("plates_count", len(plateIDs)).Msg("failed to get last event times for plates")
+	}
+
 	result := make([]PlateInfo, 0, len(plates))
 	for _, p := range plates {
-		lastEventTime, _ := s.repo.GetLastEventTimeForPlate(ctx, p.ID)
 		info := PlateInfo{
-			ID:            p.ID.String(),
-			Number:        p.Number,
-			Normalized:    p.Normalized,
-			LastEventTime: lastEventTime,
+			ID:         p.ID.String(),
+			Number:     p.Number,
+			Normalized: p.Normalized,
+			Country:    p.Country,
+			Region:     p.Region,
+		}
+		if lastEventTime, ok := lastEventTimes[p.ID]; ok {
+			info.LastEventTime = &lastEventTime
 		}
 		result = append(result, info)
 	}
@@ -341,7 +614,34 @@ func (s *ANPRService) FindPlates(ctx context.Context, plateQuery string) ([]Plat
 	return result, nil
 }
 
-func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from, to *string, direction *string, limit, offset int) ([]EventInfo, error) {
+// ParseVehicleTypeFilter проверяет значение фильтра vehicle_type: допускаются только канонические типы
+func ParseVehicleTypeFilter(value string) (string, error) {
+	vt := strings.ToLower(strings.TrimSpace(value))
+	if !anpr.IsVehicleType(vt) {
+		return "", fmt.Errorf("%w: vehicle_type must be one of %s", ErrInvalidInput, strings.Join(anpr.VehicleTypes(), ", "))
+	}
+	return vt, nil
+}
+
+// ParseEventSourceFilter проверяет значение фильтра source: один или несколько источников через запятую
+func ParseEventSourceFilter(value string) ([]string, error) {
+	var sources []string
+	for _, part := range strings.Split(value, ",") {
+		source := strings.ToLower(strings.TrimSpace(part))
+		if source == "" {
+			continue
+		}
+		if !anpr.IsEventSource(source) {
+			return nil, fmt.Errorf("%w: source must be one of %s", ErrInvalidInput, strings.Join(anpr.EventSources(), ", "))
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// FindEvents ищет события по фильтрам. polygonOrgID ограничивает выборку полигонами организации
+// (пользователи LANDFILL_*), nil — без ограничения.
+func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from, to *string, timeField string, direction, vehicleType, source, polygonID *string, polygonOrgID *uuid.UUID, limit, offset int) ([]EventInfo, error) {
 	var normalizedPlate *string
 	if plateQuery != nil {
 		normalized := utils.NormalizePlate(*plateQuery)
@@ -366,6 +666,14 @@ func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from,
 		toTime = &t
 	}
 
+	timeField = strings.ToLower(strings.TrimSpace(timeField))
+	if timeField == "" {
+		timeField = repository.EventTimeFieldEventTime
+	}
+	if timeField != repository.EventTimeFieldEventTime && timeField != repository.EventTimeFieldReceivedAt {
+		return nil, fmt.Errorf("%w: time_field must be 'event_time' or 'received_at'", ErrInvalidInput)
+	}
+
 	// Валидация direction
 	var validatedDirection *string
 	if direction != nil && *direction != "" {
@@ -376,17 +684,50 @@ func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from,
 		validatedDirection = &dir
 	}
 
-	if limit <= 0 {
-		limit = 50
+	var validatedVehicleType *string
+	if vehicleType != nil && *vehicleType != "" {
+		vt, err := ParseVehicleTypeFilter(*vehicleType)
+		if err != nil {
+			return nil, err
+		}
+		validatedVehicleType = &vt
 	}
-	if limit > 100 {
-		limit = 100
+
+	var sources []string
+	if source != nil {
+		parsed, err := ParseEventSourceFilter(*source)
+		if err != nil {
+			return nil, err
+		}
+		sources = parsed
 	}
-	if offset < 0 {
-		offset = 0
+
+	var validatedPolygonID *uuid.UUID
+	if polygonID != nil && *polygonID != "" {
+		id, err := uuid.Parse(strings.TrimSpace(*polygonID))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		validatedPolygonID = &id
+	}
+
+	if offset > repository.MaxEventSearchOffset {
+		return nil, fmt.Errorf("%w: offset must not exceed %d, narrow the time range instead", ErrInvalidInput, repository.MaxEventSearchOffset)
 	}
 
-	events, err := s.repo.FindEvents(ctx, normalizedPlate, fromTime, toTime, validatedDirection, limit, offset)
+	events, err := s.repo.FindEvents(ctx, repository.EventSearch{
+		NormalizedPlate: normalizedPlate,
+		From:            fromTime,
+		To:              toTime,
+		TimeField:       timeField,
+		Direction:       validatedDirection,
+		VehicleType:     validatedVehicleType,
+		Sources:         sources,
+		PolygonID:       validatedPolygonID,
+		PolygonOrgID:    polygonOrgID,
+		Limit:           limit,
+		Offset:          offset,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find events: %w", err)
 	}
@@ -407,7 +748,7 @@ func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from,
 		// Загружаем фотографии для каждого события
 		photos, err := s.repo.GetEventPhotos(ctx, e.ID)
 		if err != nil {
-			s.log.Warn().Err(err).Str("event_id", e.ID.String()).Msg("failed to get event photos")
+			s.logger(ctx).Warn().Err(err).Str("event_id", e.ID.String()).Msg("failed to get event photos")
 			photos = []repository.EventPhoto{}
 		}
 
@@ -423,6 +764,7 @@ func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from,
 			CameraID:          e.CameraID,
 			CameraModel:       e.CameraModel,
 			Direction:         e.Direction,
+			DirectionSource:   e.DirectionSource,
 			Lane:              e.Lane,
 			RawPlate:          e.RawPlate,
 			NormalizedPlate:   e.NormalizedPlate,
@@ -436,9 +778,19 @@ func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from,
 			VehicleSpeed:      e.VehicleSpeed,
 			SnapshotURL:       e.SnapshotURL,
 			EventTime:         e.EventTime,
+			ReceivedAt:        e.ReceivedAt,
+			Source:            e.Source,
+			EventTimeSkewed:   e.EventTimeSkewed,
+			OutOfSchedule:     e.OutOfSchedule,
+			Late:              e.Late,
+			WrongDestination:  e.WrongDestination,
+			OverQuota:         e.OverQuota,
+			TripRevision:      e.TripRevision,
+			Decision:          eventDecision(e.AccessDecision, e.DecisionReason, e.DecisionDetail),
 			SnowVolumeM3:      e.SnowVolumeM3,
 			PolygonID:         polygonID,
 			Photos:            photoURLs, // Добавляем фотографии
+			PhotoRenditions:   photoRenditions(photos),
 		}
 		result = append(result, info)
 	}
@@ -455,7 +807,7 @@ func (s *ANPRService) GetEventsByPlateAndTime(ctx context.Context, normalizedPla
 
 	events, err := s.repo.FindEventsByPlateAndTime(ctx, normalizedPlate, from, to, direction)
 	if err != nil {
-		s.log.Error().
+		s.logger(ctx).Error().
 			Err(err).
 			Str("plate", normalizedPlate).
 			Time("from", from).
@@ -480,7 +832,7 @@ func (s *ANPRService) GetEventsByPlateAndTime(ctx context.Context, normalizedPla
 		// Загружаем фотографии для каждого события
 		photos, err := s.repo.GetEventPhotos(ctx, e.ID)
 		if err != nil {
-			s.log.Warn().Err(err).Str("event_id", e.ID.String()).Msg("failed to get event photos")
+			s.logger(ctx).Warn().Err(err).Str("event_id", e.ID.String()).Msg("failed to get event photos")
 			photos = []repository.EventPhoto{}
 		}
 
@@ -496,6 +848,7 @@ func (s *ANPRService) GetEventsByPlateAndTime(ctx context.Context, normalizedPla
 			CameraID:          e.CameraID,
 			CameraModel:       e.CameraModel,
 			Direction:         e.Direction,
+			DirectionSource:   e.DirectionSource,
 			Lane:              e.Lane,
 			RawPlate:          e.RawPlate,
 			NormalizedPlate:   e.NormalizedPlate,
@@ -509,14 +862,24 @@ func (s *ANPRService) GetEventsByPlateAndTime(ctx context.Context, normalizedPla
 			VehicleSpeed:      e.VehicleSpeed,
 			SnapshotURL:       e.SnapshotURL,
 			EventTime:         e.EventTime,
+			ReceivedAt:        e.ReceivedAt,
+			Source:            e.Source,
+			EventTimeSkewed:   e.EventTimeSkewed,
+			OutOfSchedule:     e.OutOfSchedule,
+			Late:              e.Late,
+			WrongDestination:  e.WrongDestination,
+			OverQuota:         e.OverQuota,
+			TripRevision:      e.TripRevision,
+			Decision:          eventDecision(e.AccessDecision, e.DecisionReason, e.DecisionDetail),
 			SnowVolumeM3:      e.SnowVolumeM3,
 			PolygonID:         polygonID,
 			Photos:            photoURLs, // Добавляем фотографии
+			PhotoRenditions:   photoRenditions(photos),
 		}
 		result = append(result, info)
 	}
 
-	s.log.Info().
+	s.logger(ctx).Info().
 		Str("plate", normalizedPlate).
 		Time("from", from).
 		Time("to", to).
@@ -530,17 +893,16 @@ func (s *ANPRService) GetEventsByPlateAndTime(ctx context.Context, normalizedPla
 func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*EventInfo, error) {
 	event, err := s.repo.GetEventByID(ctx, eventID)
 	if err != nil {
-		s.log.Error().Err(err).Str("event_id", eventID.String()).Msg("failed to get event by id")
-		return nil, fmt.Errorf("failed to get event: %w", err)
-	}
-	if event == nil {
-		return nil, ErrNotFound
+		if !errors.Is(err, ErrNotFound) {
+			s.logger(ctx).Error().Err(err).Str("event_id", eventID.String()).Msg("failed to get event by id")
+		}
+		return nil, err
 	}
 
 	// Получаем фотографии события
 	photos, err := s.repo.GetEventPhotos(ctx, eventID)
 	if err != nil {
-		s.log.Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to get event photos")
+		s.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to get event photos")
 		// Продолжаем без фото, если ошибка при получении фото
 		photos = []repository.EventPhoto{}
 	}
@@ -551,13 +913,18 @@ func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*Eve
 		photoURLs = append(photoURLs, photo.PhotoURL)
 	}
 
+	comments, err := s.repo.ListEventComments(ctx, eventID)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to get event comments")
+	}
+
 	// Получаем данные о водителе и подрядчике
 	var driverID, driverFullName, driverIIN, driverPhone *string
 	var contractorID, contractorName, contractorBIN *string
 
 	driverData, err := s.repo.GetDriverByVehiclePlate(ctx, event.NormalizedPlate)
 	if err != nil {
-		s.log.Warn().Err(err).Str("plate", event.NormalizedPlate).Msg("failed to get driver data")
+		s.logger(ctx).Warn().Err(err).Str("plate", event.NormalizedPlate).Msg("failed to get driver data")
 	} else if driverData != nil {
 		id := driverData.ID.String()
 		driverID = &id
@@ -568,7 +935,7 @@ func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*Eve
 
 	contractorData, err := s.repo.GetContractorByVehiclePlate(ctx, event.NormalizedPlate)
 	if err != nil {
-		s.log.Warn().Err(err).Str("plate", event.NormalizedPlate).Msg("failed to get contractor data")
+		s.logger(ctx).Warn().Err(err).Str("plate", event.NormalizedPlate).Msg("failed to get contractor data")
 	} else if contractorData != nil {
 		id := contractorData.ID.String()
 		contractorID = &id
@@ -594,6 +961,7 @@ func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*Eve
 		CameraID:          event.CameraID,
 		CameraModel:       event.CameraModel,
 		Direction:         event.Direction,
+		DirectionSource:   event.DirectionSource,
 		Lane:              event.Lane,
 		RawPlate:          event.RawPlate,
 		NormalizedPlate:   event.NormalizedPlate,
@@ -607,9 +975,21 @@ func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*Eve
 		VehicleSpeed:      event.VehicleSpeed,
 		SnapshotURL:       event.SnapshotURL,
 		EventTime:         event.EventTime,
+		ReceivedAt:        event.ReceivedAt,
+		Source:            event.Source,
+		EventTimeSkewed:   event.EventTimeSkewed,
+		OutOfSchedule:     event.OutOfSchedule,
+		Late:              event.Late,
+		WrongDestination:  event.WrongDestination,
+		OverQuota:         event.OverQuota,
+		TripRevision:      event.TripRevision,
+		Decision:          eventDecision(event.AccessDecision, event.DecisionReason, event.DecisionDetail),
 		SnowVolumeM3:      event.SnowVolumeM3,
 		PolygonID:         polygonID,
 		Photos:            photoURLs,
+		PhotoRenditions:   photoRenditions(photos),
+		RawPayload:        s.eventRawPayload(ctx, event),
+		Comments:          toEventCommentInfos(comments),
 		// Driver and contractor info
 		DriverID:       driverID,
 		DriverFullName: driverFullName,
@@ -623,71 +1003,126 @@ func (s *ANPRService) GetEventByID(ctx context.Context, eventID uuid.UUID) (*Eve
 	return &info, nil
 }
 
-// CleanupOldEvents удаляет события старше указанного количества дней
+// CleanupOldEvents мягко удаляет события старше указанного количества дней
 func (s *ANPRService) CleanupOldEvents(ctx context.Context, days int) (int64, error) {
-	deleted, err := s.repo.DeleteOldEvents(ctx, days)
+	now := s.clock.Now()
+	deleted, err := s.repo.SoftDeleteEventsBefore(ctx, now.AddDate(0, 0, -days), now)
 	if err != nil {
-		s.log.Error().Err(err).Int("days", days).Msg("failed to cleanup old events")
+		s.logger(ctx).Error().Err(err).Int("days", days).Msg("failed to cleanup old events")
 		return 0, err
 	}
 	if deleted > 0 {
-		s.log.Info().Int64("deleted_count", deleted).Int("days", days).Msg("cleaned up old events")
+		s.logger(ctx).Info().Int64("deleted_count", deleted).Int("days", days).Msg("cleaned up old events")
 	}
 	return deleted, nil
 }
 
-// DeleteOldEvents удаляет события старше указанного количества дней
+// DeleteOldEvents мягко удаляет события старше указанного количества дней. До физической очистки
+// (EVENTS_PURGE_GRACE) их можно восстановить через RestoreEvent.
 func (s *ANPRService) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
+	principal, err := requirePrincipal(ctx, canDeleteEvents)
+	if err != nil {
+		return 0, err
+	}
 	if days < 1 {
 		return 0, fmt.Errorf("%w: days must be >= 1", ErrInvalidInput)
 	}
 
-	deletedCount, err := s.repo.DeleteOldEvents(ctx, days)
+	now := s.clock.Now()
+	deletedCount, err := s.repo.SoftDeleteEventsBefore(ctx, now.AddDate(0, 0, -days), now)
 	if err != nil {
-		s.log.Error().
+		s.logger(ctx).Error().
 			Err(err).
 			Int("days", days).
 			Msg("failed to delete old events")
 		return 0, fmt.Errorf("failed to delete old events: %w", err)
 	}
 
-	s.log.Info().
+	s.logger(ctx).Info().
 		Int("days", days).
 		Int64("deleted_count", deletedCount).
+		Str("user_id", principal.UserID.String()).
 		Msg("deleted old events")
 
+	if deletedCount > 0 {
+		s.repairDeletedTrips(ctx, now.AddDate(0, 0, -days))
+	}
 	return deletedCount, nil
 }
 
-// DeleteAllEvents удаляет все события из базы данных
+// DeleteAllEvents мягко удаляет все события; до физической очистки их можно восстановить. Рейсы не
+// пересчитываются: въездов не остаётся.
 func (s *ANPRService) DeleteAllEvents(ctx context.Context) (int64, error) {
-	s.log.Warn().Msg("attempting to delete ALL events from database")
+	principal, err := requirePrincipal(ctx, canDeleteEvents)
+	if err != nil {
+		return 0, err
+	}
+	s.logger(ctx).Warn().Str("user_id", principal.UserID.String()).Msg("attempting to delete ALL events from database")
 
-	deletedCount, err := s.repo.DeleteAllEvents(ctx)
+	deletedCount, err := s.repo.SoftDeleteAllEvents(ctx, s.clock.Now())
 	if err != nil {
-		s.log.Error().
+		s.logger(ctx).Error().
 			Err(err).
 			Msg("failed to delete all events")
 		return 0, fmt.Errorf("failed to delete all events: %w", err)
 	}
 
-	s.log.Warn().
+	s.logger(ctx).Warn().
 		Int64("deleted_count", deletedCount).
 		Msg("successfully deleted ALL events from database")
 
 	return deletedCount, nil
 }
 
+// RestoreEvent восстанавливает мягко удалённое событие. ErrNotFound — события нет, оно не удалено
+// или уже физически очищено.
+func (s *ANPRService) RestoreEvent(ctx context.Context, eventID uuid.UUID) error {
+	principal, err := requirePrincipal(ctx, canDeleteEvents)
+	if err != nil {
+		return err
+	}
+	restored, err := s.repo.RestoreEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return fmt.Errorf("%w: deleted event %s", ErrNotFound, eventID)
+	}
+	s.logger(ctx).Info().
+		Str("event_id", eventID.String()).
+		Str("user_id", principal.UserID.String()).
+		Msg("event restored")
+	s.repairRestoredTrips(ctx, eventID)
+	return nil
+}
+
+// PurgeDeletedEvents физически удаляет события, мягко удалённые раньше чем EVENTS_PURGE_GRACE назад,
+// вместе с их фото
+func (s *ANPRService) PurgeDeletedEvents(ctx context.Context) error {
+	purged, err := s.repo.PurgeDeletedEvents(ctx, s.clock.Now().Add(-s.Config().Retention.PurgeGrace))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		s.logger(ctx).Info().Int64("purged", purged).Msg("deleted events purged")
+	}
+	return nil
+}
+
 // SyncVehicleToWhitelist синхронизирует номер транспортного средства в whitelist
-// Вызывается при создании/обновлении vehicle в roles сервисе
+// Вызывается при создании/обновлении vehicle в roles сервисе от имени администратора акимата или КГУ
 func (s *ANPRService) SyncVehicleToWhitelist(ctx context.Context, plateNumber string) (uuid.UUID, error) {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return uuid.Nil, err
+	}
 	plateID, err := s.repo.SyncVehicleToWhitelist(ctx, plateNumber)
 	if err != nil {
-		s.log.Error().Err(err).Str("plate_number", plateNumber).Msg("failed to sync vehicle to whitelist")
+		s.logger(ctx).Error().Err(err).Str("plate_number", plateNumber).Msg("failed to sync vehicle to whitelist")
 		return uuid.Nil, fmt.Errorf("sync vehicle to whitelist: %w", err)
 	}
+	s.InvalidateListCache()
 
-	s.log.Info().
+	s.logger(ctx).Info().
 		Str("plate_number", plateNumber).
 		Str("plate_id", plateID.String()).
 		Msg("vehicle synced to whitelist")
@@ -699,31 +1134,46 @@ type PlateInfo struct {
 	ID            string     `json:"id"`
 	Number        string     `json:"number"`
 	Normalized    string     `json:"normalized"`
+	Country       *string    `json:"country,omitempty"` // KZ, RU, KG — по формату номера
+	Region        *string    `json:"region,omitempty"`
 	LastEventTime *time.Time `json:"last_event_time,omitempty"`
 }
 
 type EventInfo struct {
-	ID                string    `json:"id"`
-	PlateID           *string   `json:"plate_id,omitempty"`
-	CameraID          string    `json:"camera_id"`
-	CameraModel       *string   `json:"camera_model,omitempty"`
-	Direction         *string   `json:"direction,omitempty"`
-	Lane              *int      `json:"lane,omitempty"`
-	RawPlate          string    `json:"raw_plate"`
-	NormalizedPlate   string    `json:"normalized_plate"`
-	Confidence        *float64  `json:"confidence,omitempty"`
-	VehicleColor      *string   `json:"vehicle_color,omitempty"`
-	VehicleType       *string   `json:"vehicle_type,omitempty"`
-	VehicleBrand      *string   `json:"vehicle_brand,omitempty"`
-	VehicleModel      *string   `json:"vehicle_model,omitempty"`
-	VehicleCountry    *string   `json:"vehicle_country,omitempty"`
-	VehiclePlateColor *string   `json:"vehicle_plate_color,omitempty"`
-	VehicleSpeed      *float64  `json:"vehicle_speed,omitempty"`
-	SnapshotURL       *string   `json:"snapshot_url,omitempty"`
-	EventTime         time.Time `json:"event_time"`
-	SnowVolumeM3      *float64  `json:"snow_volume_m3,omitempty"`
-	PolygonID         *string   `json:"polygon_id,omitempty"`
-	Photos            []string  `json:"photos,omitempty"` // URLs фотографий (только для детального просмотра)
+	ID                string               `json:"id"`
+	PlateID           *string              `json:"plate_id,omitempty"`
+	CameraID          string               `json:"camera_id"`
+	CameraModel       *string              `json:"camera_model,omitempty"`
+	Direction         *string              `json:"direction,omitempty"`
+	DirectionSource   *string              `json:"direction_source,omitempty"` // reported — прислано камерой, иначе выведено (anpr.DirectionSource*)
+	Lane              *int                 `json:"lane,omitempty"`
+	RawPlate          string               `json:"raw_plate"`
+	NormalizedPlate   string               `json:"normalized_plate"`
+	Confidence        *float64             `json:"confidence,omitempty"`
+	VehicleColor      *string              `json:"vehicle_color,omitempty"`
+	VehicleType       *string              `json:"vehicle_type,omitempty"`
+	VehicleBrand      *string              `json:"vehicle_brand,omitempty"`
+	VehicleModel      *string              `json:"vehicle_model,omitempty"`
+	VehicleCountry    *string              `json:"vehicle_country,omitempty"`
+	VehiclePlateColor *string              `json:"vehicle_plate_color,omitempty"`
+	VehicleSpeed      *float64             `json:"vehicle_speed,omitempty"`
+	SnapshotURL       *string              `json:"snapshot_url,omitempty"`
+	EventTime         time.Time            `json:"event_time"`
+	ReceivedAt        time.Time            `json:"received_at"`
+	Source            string               `json:"source"`
+	EventTimeSkewed   bool                 `json:"event_time_skewed,omitempty"`
+	OutOfSchedule     bool                 `json:"out_of_schedule,omitempty"`
+	Late              bool                 `json:"late,omitempty"` // камера прислала событие с опозданием (EVENT_MAX_AGE)
+	WrongDestination  bool                 `json:"wrong_destination,omitempty"`
+	OverQuota         bool                 `json:"over_quota,omitempty"`    // въезд сверх квоты оплачиваемых рейсов
+	TripRevision      int                  `json:"trip_revision,omitempty"` // сколько раз пересчёт рейсов менял over_quota
+	Decision          *anpr.AccessDecision `json:"decision,omitempty"`
+	SnowVolumeM3      *float64             `json:"snow_volume_m3,omitempty"`
+	PolygonID         *string              `json:"polygon_id,omitempty"`
+	Photos            []string             `json:"photos,omitempty"` // URLs фотографий (только для детального просмотра)
+	PhotoRenditions   []PhotoRenditions    `json:"photo_renditions,omitempty"`
+	RawPayload        json.RawMessage      `json:"raw_payload,omitempty"` // исходные данные камеры (только для детального просмотра)
+	Comments          []EventCommentInfo   `json:"comments,omitempty"`    // комментарии операторов (только для детального просмотра)
 	// Driver and contractor info
 	DriverID       *string `json:"driver_id,omitempty"`
 	DriverFullName *string `json:"driver_full_name,omitempty"`
@@ -739,14 +1189,28 @@ func (s *ANPRService) GetReports(ctx context.Context, filters repository.ReportF
 	// Получаем статистику
 	stats, err := s.repo.GetReportStats(ctx, filters)
 	if err != nil {
-		s.log.Error().Err(err).Msg("failed to get report stats")
+		s.logger(ctx).Error().Err(err).Msg("failed to get report stats")
 		return nil, fmt.Errorf("failed to get report stats: %w", err)
 	}
 
+	typeStats, err := s.repo.GetVehicleTypeStats(ctx, filters)
+	if err != nil {
+		s.logger(ctx).Error().Err(err).Msg("failed to get vehicle type stats")
+		return nil, fmt.Errorf("failed to get vehicle type stats: %w", err)
+	}
+	byVehicleType := make([]VehicleTypeStatInfo, 0, len(typeStats))
+	for _, stat := range typeStats {
+		byVehicleType = append(byVehicleType, VehicleTypeStatInfo{
+			VehicleType: stat.VehicleType,
+			TotalVolume: calc.Round(stat.TotalVolume),
+			TripCount:   stat.TripCount,
+		})
+	}
+
 	// Получаем события
 	events, err := s.repo.GetReportEvents(ctx, filters)
 	if err != nil {
-		s.log.Error().Err(err).Msg("failed to get report events")
+		s.logger(ctx).Error().Err(err).Msg("failed to get report events")
 		return nil, fmt.Errorf("failed to get report events: %w", err)
 	}
 
@@ -799,7 +1263,10 @@ func (s *ANPRService) GetReports(ctx context.Context, filters repository.ReportF
 			ContractorID:      contractorID,
 			ContractorName:    e.ContractorName,
 			PolygonID:         polygonID,
+			WrongDestination:  e.WrongDestination,
 			SnowVolumeM3:      e.SnowVolumeM3,
+			TemperatureC:      e.WeatherTemperatureC,
+			SnowfallCm:        e.WeatherSnowfallCm,
 			PlatePhotoURL:     e.PlatePhotoURL,
 			BodyPhotoURL:      e.BodyPhotoURL,
 			VehicleID:         vehicleID,
@@ -807,17 +1274,32 @@ func (s *ANPRService) GetReports(ctx context.Context, filters repository.ReportF
 	}
 
 	return &ReportResult{
-		TotalVolume: stats.TotalVolume,
-		TripCount:   stats.TripCount,
-		Events:      reportEvents,
+		TotalVolume:           calc.Round(stats.TotalVolume),
+		TripCount:             stats.TripCount,
+		WrongDestinationCount: stats.WrongDestinationCount,
+		ByVehicleType:         byVehicleType,
+		Events:                reportEvents,
+		OperationalModes:      s.stormPeriods(ctx, filters),
 	}, nil
 }
 
 // ReportResult содержит результат отчета
 type ReportResult struct {
-	TotalVolume float64           `json:"total_volume"`
-	TripCount   int64             `json:"trip_count"`
-	Events      []ReportEventInfo `json:"events"`
+	TotalVolume float64 `json:"total_volume"`
+	TripCount   int64   `json:"trip_count"`
+	// WrongDestinationCount — рейсы на полигон, за которым подрядчик не закреплён
+	WrongDestinationCount int64                 `json:"wrong_destination_count"`
+	ByVehicleType         []VehicleTypeStatInfo `json:"by_vehicle_type"`
+	Events                []ReportEventInfo     `json:"events"`
+	// OperationalModes — периоды режима storm на полигонах отчёта: лимиты и расписания в них были другими
+	OperationalModes []OperationalModePeriod `json:"operational_modes"`
+}
+
+// VehicleTypeStatInfo — объём и число рейсов по типу транспорта
+type VehicleTypeStatInfo struct {
+	VehicleType string  `json:"vehicle_type"`
+	TotalVolume float64 `json:"total_volume"`
+	TripCount   int64   `json:"trip_count"`
 }
 
 type ComparisonMode string
@@ -900,7 +1382,10 @@ type ReportEventInfo struct {
 	ContractorID      *string   `json:"contractor_id,omitempty"`
 	ContractorName    *string   `json:"contractor_name,omitempty"`
 	PolygonID         *string   `json:"polygon_id,omitempty"`
+	WrongDestination  bool      `json:"wrong_destination,omitempty"`
 	SnowVolumeM3      *float64  `json:"snow_volume_m3,omitempty"`
+	TemperatureC      *float64  `json:"temperature_c,omitempty"`
+	SnowfallCm        *float64  `json:"snowfall_cm,omitempty"`
 	PlatePhotoURL     *string   `json:"plate_photo_url,omitempty"`
 	BodyPhotoURL      *string   `json:"body_photo_url,omitempty"`
 	VehicleID         *string   `json:"vehicle_id,omitempty"`
@@ -922,12 +1407,12 @@ func (s *ANPRService) GetReportsComparison(ctx context.Context, input ReportComp
 	currentFilters := input.BaseFilters
 	currentFilters.From = input.CurrentFrom
 	currentFilters.To = input.CurrentTo
-	currentFilters.UseOperationalWindow = true
+	currentFilters.OnlyInShift = true
 
 	previousFilters := input.BaseFilters
 	previousFilters.From = previousFrom
 	previousFilters.To = previousTo
-	previousFilters.UseOperationalWindow = true
+	previousFilters.OnlyInShift = true
 
 	currentStats, err := s.repo.GetReportStats(ctx, currentFilters)
 	if err != nil {
@@ -941,13 +1426,13 @@ func (s *ANPRService) GetReportsComparison(ctx context.Context, input ReportComp
 	current := ComparisonPeriodData{
 		From:        input.CurrentFrom,
 		To:          input.CurrentTo,
-		TotalVolume: currentStats.TotalVolume,
+		TotalVolume: calc.Round(currentStats.TotalVolume),
 		TripCount:   currentStats.TripCount,
 	}
 	previous := ComparisonPeriodData{
 		From:        previousFrom,
 		To:          previousTo,
-		TotalVolume: previousStats.TotalVolume,
+		TotalVolume: calc.Round(previousStats.TotalVolume),
 		TripCount:   previousStats.TripCount,
 	}
 
@@ -1005,7 +1490,7 @@ func (s *ANPRService) GetHourlyActivity(ctx context.Context, filters repository.
 		if !ok {
 			continue
 		}
-		items[itemIdx].TotalVolume = row.TotalVolume
+		items[itemIdx].TotalVolume = calc.Round(row.TotalVolume)
 		items[itemIdx].TripCount = row.TripCount
 	}
 
@@ -1087,7 +1572,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 	f := excelize.NewFile()
 	defer func() {
 		if err := f.Close(); err != nil {
-			s.log.Warn().Err(err).Msg("failed to close excel file")
+			s.logger(ctx).Warn().Err(err).Msg("failed to close excel file")
 		}
 	}()
 
@@ -1138,7 +1623,11 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 	}
 
 	// Заголовки
-	headers := []interface{}{"ТОО", "Машина", "Госномер", "Время события", "Процент", "Объем"}
+	lang := i18n.FromContext(ctx)
+	headers := []interface{}{
+		i18n.Text(lang, "report.contractor"), i18n.Text(lang, "report.vehicle"), i18n.Text(lang, "report.plate"),
+		i18n.Text(lang, "report.event_time"), i18n.Text(lang, "report.percentage"), i18n.Text(lang, "report.volume"),
+	}
 	cell, err := excelize.CoordinatesToCellName(1, 1)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get cell name: %w", err)
@@ -1154,7 +1643,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 		TopLeftCell: "A2",
 		ActivePane:  "bottomLeft",
 	}); err != nil {
-		s.log.Warn().Err(err).Msg("failed to freeze panes")
+		s.logger(ctx).Warn().Err(err).Msg("failed to freeze panes")
 	}
 
 	// Устанавливаем ширину колонок
@@ -1221,7 +1710,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 		rowNum++
 
 		// Строка с итогами группы
-		totalText := fmt.Sprintf("Итого %s: %d рейсов, %.2f м³", contractorName, count, volume)
+		totalText := i18n.Text(lang, "report.group_total", contractorName, count, volume)
 		cell, _ = excelize.CoordinatesToCellName(1, rowNum)
 		if err := sw.SetRow(cell, []interface{}{totalText, "", "", "", "", ""}, excelize.RowOpts{StyleID: totalStyle}); err != nil {
 			return fmt.Errorf("failed to set group total row: %w", err)
@@ -1246,7 +1735,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 		}
 
 		for _, event := range events {
-			contractorName := "Не назначено"
+			contractorName := i18n.Text(lang, "report.unassigned")
 			if event.ContractorName != nil && *event.ContractorName != "" && *event.ContractorName != "Не назначено" {
 				contractorName = *event.ContractorName
 			}
@@ -1270,7 +1759,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 				}
 
 				// Заголовок новой группы
-				groupHeader := fmt.Sprintf("ТОО: %s", contractorName)
+				groupHeader := i18n.Text(lang, "report.group", contractorName)
 				cell, _ := excelize.CoordinatesToCellName(1, rowNum)
 				if err := sw.SetRow(cell, []interface{}{groupHeader, "", "", "", "", ""}, excelize.RowOpts{StyleID: groupHeaderStyle}); err != nil {
 					return nil, "", fmt.Errorf("failed to set group header row: %w", err)
@@ -1301,7 +1790,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 
 			// Форматируем процент и объем отдельно
 			percentageStr := formatPercentage(event.SnowVolumePercentage)
-			volumeStr := formatVolume(event.SnowVolumeM3)
+			volumeStr := formatVolume(lang, event.SnowVolumeM3)
 
 			// Записываем строку данных
 			cell, _ := excelize.CoordinatesToCellName(1, rowNum)
@@ -1321,8 +1810,8 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 			currentGroupCount++
 			totalCount++
 			if event.SnowVolumeM3 != nil {
-				currentGroupVolume += *event.SnowVolumeM3
-				totalVolume += *event.SnowVolumeM3
+				currentGroupVolume = calc.Add(currentGroupVolume, *event.SnowVolumeM3)
+				totalVolume = calc.Add(totalVolume, *event.SnowVolumeM3)
 			}
 
 			rowNum++
@@ -1365,7 +1854,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 		rowNum++
 
 		// Общий итог
-		totalText := fmt.Sprintf("ВСЕГО: %d рейсов, %.2f м³", totalCount, totalVolume)
+		totalText := i18n.Text(lang, "report.total", totalCount, totalVolume)
 		cell, _ = excelize.CoordinatesToCellName(1, rowNum)
 		if err := sw.SetRow(cell, []interface{}{totalText, "", "", "", "", ""}, excelize.RowOpts{StyleID: totalStyle}); err != nil {
 			return nil, "", fmt.Errorf("failed to set total row: %w", err)
@@ -1392,7 +1881,7 @@ func (s *ANPRService) generateExcelReport(ctx context.Context, filters repositor
 		colStart, _ := excelize.CoordinatesToCellName(4, 2)
 		colEnd, _ := excelize.CoordinatesToCellName(4, lastRow)
 		if err := f.SetCellStyle(sheetName, colStart, colEnd, dateTimeStyle); err != nil {
-			s.log.Warn().Err(err).Msg("failed to set datetime style for column D")
+			s.logger(ctx).Warn().Err(err).Msg("failed to set datetime style for column D")
 		}
 	}
 
@@ -1439,12 +1928,12 @@ func formatPercentage(percentage *float64) string {
 	return fmt.Sprintf("%.2f%%", *percentage)
 }
 
-// formatVolume форматирует объем в м³
-func formatVolume(volumeM3 *float64) string {
+// formatVolume форматирует объем в м³ на языке отчёта
+func formatVolume(lang i18n.Lang, volumeM3 *float64) string {
 	if volumeM3 == nil || *volumeM3 <= 0 {
 		return ""
 	}
-	return fmt.Sprintf("%.2f м³", *volumeM3)
+	return i18n.Text(lang, "report.volume_m3", *volumeM3)
 }
 
 // generateFilename генерирует имя файла для Excel выгрузки
@@ -1454,3 +1943,13 @@ func generateFilename(from, to time.Time) string {
 	toStr := to.Format("2006-01-02")
 	return fmt.Sprintf("anpr-events_%s_%s.xlsx", fromStr, toStr)
 }
+
+// normalizeEventDirection приводит направление к нижнему регистру. Если камера не дала direction или
+// пришёл "unknown", ставим "entry" по умолчанию, чтобы события учитывались в tickets-сервисе.
+func normalizeEventDirection(direction string) string {
+	dir := strings.ToLower(direction)
+	if dir == "" || dir == "unknown" {
+		return "entry"
+	}
+	return dir
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"anpr-service/internal/model"
+)
+
+// ErrForbidden — у пользователя запроса нет прав на операцию
+var ErrForbidden = errors.New("insufficient permissions")
+
+// requirePrincipal возвращает пользователя из ctx (см. model.WithPrincipal), если allowed разрешает
+// ему операцию. Без пользователя в контексте операция запрещена.
+func requirePrincipal(ctx context.Context, allowed func(model.Principal) bool) (model.Principal, error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok || !allowed(principal) {
+		return principal, ErrForbidden
+	}
+	return principal, nil
+}
+
+// canManageLists — списки номеров, в том числе белый список из vehicles, меняют администраторы акимата и КГУ ЗКХ
+func canManageLists(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin || p.Role == model.UserRoleKguZkhAdmin
+}
+
+// canDeleteEvents — события удаляет только администратор акимата
+func canDeleteEvents(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canReprocessEvents — повторный разбор payload камер запускает администратор акимата
+func canReprocessEvents(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canLockBilling — ведомость оплаты после выставления счёта блокирует администратор акимата
+func canLockBilling(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canSwitchPolygonMode — режим storm при снежном ЧП объявляют администраторы акимата и КГУ ЗКХ
+func canSwitchPolygonMode(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin || p.Role == model.UserRoleKguZkhAdmin
+}
+
+// canManageFeatureFlags — флаги возможностей для площадок переопределяет администратор акимата
+func canManageFeatureFlags(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canCommentEvents — комментарии к событиям оставляют сотрудники акимата, КГУ ЗКХ и полигонов
+func canCommentEvents(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu() || p.IsLandfill()
+}
+
+// canManageJobs — периодические задачи просматривает и запускает вручную администратор акимата
+func canManageJobs(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canViewConfig — настройки сервиса просматривает администратор акимата
+func canViewConfig(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canViewUsage — использование сервиса по организациям просматривает администратор акимата
+func canViewUsage(p model.Principal) bool {
+	return p.Role == model.UserRoleAkimatAdmin
+}
+
+// canViewEvidence — пакеты доказательств рейсов для разбора споров выгружают сотрудники акимата, КГУ ЗКХ и полигонов
+func canViewEvidence(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu() || p.IsLandfill()
+}
+
+// canViewTraffic — скорость и загрузку полос камер смотрят сотрудники акимата, КГУ ЗКХ и полигонов
+func canViewTraffic(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu() || p.IsLandfill()
+}
+
+// canWatchPlates — уведомления о проездах номеров заводят себе инспекторы акимата и КГУ ЗКХ
+func canWatchPlates(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu()
+}
+
+// canSaveSearches — поиски событий сохраняют и получают по расписанию сотрудники акимата, КГУ ЗКХ и полигонов
+func canSaveSearches(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu() || p.IsLandfill()
+}
+
+// canStreamEvents — события за период потоком (для ETL) выгружают сотрудники акимата, КГУ ЗКХ и полигонов
+func canStreamEvents(p model.Principal) bool {
+	return p.IsAkimat() || p.IsKgu() || p.IsLandfill()
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestSyncVehicleToWhitelistPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    model.UserRole
+		noUser  bool
+		allowed bool
+	}{
+		{name: "akimat admin", role: model.UserRoleAkimatAdmin, allowed: true},
+		{name: "kgu admin", role: model.UserRoleKguZkhAdmin, allowed: true},
+		{name: "kgu user", role: model.UserRoleKguZkhUser},
+		{name: "contractor", role: model.UserRoleContractorAdmin},
+		{name: "no principal in context", noUser: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			ctx := context.Background()
+			if !tt.noUser {
+				ctx = model.WithPrincipal(ctx, model.Principal{UserID: uuid.New(), Role: tt.role})
+			}
+			if tt.allowed {
+				store.EXPECT().SyncVehicleToWhitelist(gomock.Any(), "123ABC02").Return(uuid.New(), nil)
+			}
+
+			_, err := svc.SyncVehicleToWhitelist(ctx, "123ABC02")
+			if tt.allowed && err != nil {
+				t.Fatalf("SyncVehicleToWhitelist() error = %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Fatalf("SyncVehicleToWhitelist() error = %v, want ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestDeleteEventsRequiresAkimatAdmin(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	kgu := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhAdmin})
+	if _, err := svc.DeleteOldEvents(kgu, 30); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("DeleteOldEvents() error = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.DeleteAllEvents(kgu); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("DeleteAllEvents() error = %v, want ErrForbidden", err)
+	}
+
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	cutoff := testNow.AddDate(0, 0, -30)
+	store.EXPECT().SoftDeleteEventsBefore(gomock.Any(), cutoff, testNow).Return(int64(5), nil)
+	// Рейсы ночи на границе удаления пересчитываются
+	store.EXPECT().ListTripPlates(gomock.Any(), cutoff, gomock.Any()).Return(nil, nil)
+	deleted, err := svc.DeleteOldEvents(admin, 30)
+	if err != nil || deleted != 5 {
+		t.Fatalf("DeleteOldEvents() = %d, %v; want 5, nil", deleted, err)
+	}
+}
+
+func TestRestoreEvent(t *testing.T) {
+	eventID := uuid.New()
+	tests := []struct {
+		name     string
+		role     model.UserRole
+		restored bool
+		wantErr  error
+	}{
+		{name: "restores deleted event", role: model.UserRoleAkimatAdmin, restored: true},
+		{name: "event not deleted or purged", role: model.UserRoleAkimatAdmin, wantErr: ErrNotFound},
+		{name: "kgu cannot restore", role: model.UserRoleKguZkhAdmin, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: tt.role})
+			if tt.role == model.UserRoleAkimatAdmin {
+				store.EXPECT().RestoreEvent(gomock.Any(), eventID).Return(tt.restored, nil)
+				if tt.restored {
+					store.EXPECT().GetEventByID(gomock.Any(), eventID).Return(&repository.ANPREvent{ID: eventID}, nil)
+				}
+			}
+
+			err := svc.RestoreEvent(ctx, eventID)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("RestoreEvent() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestoreEvent() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
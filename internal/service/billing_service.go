@@ -0,0 +1,292 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/snow/calc"
+)
+
+const (
+	BillingFormatCSV  = "csv"
+	BillingFormatJSON = "json"
+
+	BillingStatusOpen   = "open"
+	BillingStatusLocked = "locked"
+
+	// BillingHeaderSignature — заголовок с HMAC-SHA256 тела выгрузки (sha256=<hex>)
+	BillingHeaderSignature = "X-Billing-Signature"
+
+	// billingPeriodLayout — формат месяца ведомости
+	billingPeriodLayout = "2006-01"
+)
+
+var (
+	// ErrBillingNotConfigured — не задан ключ подписи BILLING_SIGNING_KEY
+	ErrBillingNotConfigured = errors.New("billing export is not configured")
+	// ErrBillingPeriodLocked — ведомость месяца уже заблокирована
+	ErrBillingPeriodLocked = errors.New("billing period is already locked")
+)
+
+// BillingLine — оплачиваемые рейсы машины подрядчика: рейсы × объём кузова
+type BillingLine struct {
+	repository.BillingLine
+	BilledVolumeM3 float64 `json:"billed_volume_m3"`
+}
+
+// BillingContractorTotal — итог ведомости по подрядчику
+type BillingContractorTotal struct {
+	ContractorID   uuid.UUID `json:"contractor_id"`
+	ContractorName string    `json:"contractor_name"`
+	Vehicles       int       `json:"vehicles"`
+	Trips          int64     `json:"trips"`
+	BilledVolumeM3 float64   `json:"billed_volume_m3"`
+}
+
+// BillingStatement — ведомость оплаты рейсов за месяц
+type BillingStatement struct {
+	Period string    `json:"period"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	// Status — open (пересчитывается при выгрузке) или locked (счёт выставлен, строки заморожены)
+	Status      string     `json:"status"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	// Checksum — sha256 строк; одинаков у выгрузок, между которыми данные не менялись
+	Checksum    string                   `json:"checksum"`
+	Contractors []BillingContractorTotal `json:"contractors"`
+	Lines       []BillingLine            `json:"lines"`
+}
+
+// BillingStatement возвращает ведомость месяца period (YYYY-MM). Ведомость открытого месяца
+// пересчитывается, но сохраняется заново только при изменении строк, поэтому повторная выгрузка
+// без новых данных отдаёт тот же документ. Ведомость заблокированного месяца не пересчитывается.
+func (s *ANPRService) BillingStatement(ctx context.Context, period string) (*BillingStatement, error) {
+	from, to, err := s.billingPeriodBounds(period)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if !from.Before(now) {
+		return nil, fmt.Errorf("%w: billing period %s has not started yet", ErrInvalidInput, period)
+	}
+
+	stored, err := s.repo.GetBillingPeriod(ctx, period)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if stored != nil && stored.LockedAt != nil {
+		return newBillingStatement(stored)
+	}
+
+	lines, err := s.repo.GetBillingLines(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		lines = []repository.BillingLine{}
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode billing lines: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if stored != nil && stored.Checksum == checksum {
+		return newBillingStatement(stored)
+	}
+
+	billing := &repository.BillingPeriod{
+		Period:     period,
+		PeriodFrom: from,
+		PeriodTo:   to,
+		Lines:      data,
+		Checksum:   checksum,
+		// Postgres хранит время с точностью до микросекунд: повторная выгрузка должна совпасть побайтно
+		GeneratedAt: now.Truncate(time.Microsecond),
+	}
+	if err := s.repo.SaveBillingPeriod(ctx, billing); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("period", period).Int("lines", len(lines)).Str("checksum", checksum).Msg("billing statement generated")
+	return newBillingStatement(billing)
+}
+
+// ExportBillingStatement выгружает ведомость месяца в CSV или JSON и подписывает тело выгрузки
+// HMAC-SHA256 с ключом BILLING_SIGNING_KEY. Возвращает тело, имя файла и подпись.
+func (s *ANPRService) ExportBillingStatement(ctx context.Context, period, format string) ([]byte, string, string, error) {
+	key := s.Config().Billing.SigningKey
+	if key == "" {
+		return nil, "", "", ErrBillingNotConfigured
+	}
+	if format == "" {
+		format = BillingFormatCSV
+	}
+	if format != BillingFormatCSV && format != BillingFormatJSON {
+		return nil, "", "", fmt.Errorf("%w: format must be %q or %q", ErrInvalidInput, BillingFormatCSV, BillingFormatJSON)
+	}
+
+	statement, err := s.BillingStatement(ctx, period)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var data []byte
+	if format == BillingFormatJSON {
+		data, err = json.Marshal(statement)
+	} else {
+		data, err = encodeBillingCSV(statement)
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to encode billing statement: %w", err)
+	}
+
+	filename := fmt.Sprintf("anpr-billing_%s.%s", period, format)
+	return data, filename, SignBillingExport(key, data), nil
+}
+
+// LockBillingPeriod блокирует ведомость закончившегося месяца после выставления счёта: дальнейшие
+// выгрузки отдают сохранённые строки, даже если события месяца изменятся. Доступно администратору акимата.
+func (s *ANPRService) LockBillingPeriod(ctx context.Context, period string) (*BillingStatement, error) {
+	principal, err := requirePrincipal(ctx, canLockBilling)
+	if err != nil {
+		return nil, err
+	}
+	_, to, err := s.billingPeriodBounds(period)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if to.After(now) {
+		return nil, fmt.Errorf("%w: billing period %s has not ended yet", ErrInvalidInput, period)
+	}
+
+	statement, err := s.BillingStatement(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	if statement.Status == BillingStatusLocked {
+		return nil, ErrBillingPeriodLocked
+	}
+	locked, err := s.repo.LockBillingPeriod(ctx, period, principal.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrBillingPeriodLocked
+	}
+
+	lockedAt := now.UTC()
+	statement.Status = BillingStatusLocked
+	statement.LockedAt = &lockedAt
+	s.logger(ctx).Info().
+		Str("period", period).
+		Str("checksum", statement.Checksum).
+		Str("locked_by", principal.UserID.String()).
+		Msg("billing period locked")
+	return statement, nil
+}
+
+// SignBillingExport возвращает подпись тела выгрузки для заголовка X-Billing-Signature
+func SignBillingExport(key string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// billingPeriodBounds возвращает границы месяца [from, to) в поясе BILLING_TIMEZONE
+func (s *ANPRService) billingPeriodBounds(period string) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(s.Config().Billing.TimeZone)
+	if err != nil {
+		loc = s.defaultCameraLocation()
+	}
+	from, err := time.ParseInLocation(billingPeriodLayout, period, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: period must be YYYY-MM", ErrInvalidInput)
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+func newBillingStatement(billing *repository.BillingPeriod) (*BillingStatement, error) {
+	var stored []repository.BillingLine
+	if err := json.Unmarshal(billing.Lines, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode billing lines: %w", err)
+	}
+
+	statement := &BillingStatement{
+		Period:      billing.Period,
+		From:        billing.PeriodFrom.UTC(),
+		To:          billing.PeriodTo.UTC(),
+		Status:      BillingStatusOpen,
+		GeneratedAt: billing.GeneratedAt.UTC(),
+		Checksum:    billing.Checksum,
+		Contractors: []BillingContractorTotal{},
+		Lines:       make([]BillingLine, 0, len(stored)),
+	}
+	if billing.LockedAt != nil {
+		lockedAt := billing.LockedAt.UTC()
+		statement.Status = BillingStatusLocked
+		statement.LockedAt = &lockedAt
+	}
+
+	// Строки упорядочены по подрядчику, поэтому итоги собираются за один проход
+	for _, line := range stored {
+		billed := calc.Trips(line.Trips, line.BodyVolumeM3)
+		statement.Lines = append(statement.Lines, BillingLine{BillingLine: line, BilledVolumeM3: billed})
+
+		last := len(statement.Contractors) - 1
+		if last < 0 || statement.Contractors[last].ContractorID != line.ContractorID {
+			statement.Contractors = append(statement.Contractors, BillingContractorTotal{
+				ContractorID:   line.ContractorID,
+				ContractorName: line.ContractorName,
+			})
+			last++
+		}
+		total := &statement.Contractors[last]
+		total.Vehicles++
+		total.Trips += line.Trips
+		total.BilledVolumeM3 = calc.Add(total.BilledVolumeM3, billed)
+	}
+	return statement, nil
+}
+
+func encodeBillingCSV(statement *BillingStatement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"period", "contractor_id", "contractor_name", "vehicle_id", "plate_number", "body_volume_m3", "trips", "billed_volume_m3"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, line := range statement.Lines {
+		record := []string{
+			statement.Period,
+			line.ContractorID.String(),
+			line.ContractorName,
+			line.VehicleID.String(),
+			line.PlateNumber,
+			strconv.FormatFloat(line.BodyVolumeM3, 'f', calc.VolumeDecimals, 64),
+			strconv.FormatInt(line.Trips, 10),
+			strconv.FormatFloat(line.BilledVolumeM3, 'f', calc.VolumeDecimals, 64),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func billingTestConfig() *config.Config {
+	return &config.Config{Billing: config.BillingConfig{SigningKey: "billing-secret", TimeZone: "UTC"}}
+}
+
+func TestBillingStatementTotals(t *testing.T) {
+	svc, store := newTestService(t, billingTestConfig())
+	contractorA, contractorB := uuid.New(), uuid.New()
+	lines := []repository.BillingLine{
+		{ContractorID: contractorA, ContractorName: "A", VehicleID: uuid.New(), PlateNumber: "111AAA02", BodyVolumeM3: 20, Trips: 3},
+		{ContractorID: contractorA, ContractorName: "A", VehicleID: uuid.New(), PlateNumber: "222AAA02", BodyVolumeM3: 12.5, Trips: 2},
+		{ContractorID: contractorB, ContractorName: "B", VehicleID: uuid.New(), PlateNumber: "333BBB02", BodyVolumeM3: 18, Trips: 1},
+	}
+	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().GetBillingPeriod(gomock.Any(), "2024-12").Return(nil, repository.ErrBillingPeriodNotFound)
+	store.EXPECT().GetBillingLines(gomock.Any(), from, from.AddDate(0, 1, 0)).Return(lines, nil)
+	store.EXPECT().SaveBillingPeriod(gomock.Any(), gomock.Any()).Return(nil)
+
+	statement, err := svc.BillingStatement(context.Background(), "2024-12")
+	if err != nil {
+		t.Fatalf("BillingStatement() error = %v", err)
+	}
+	if statement.Status != BillingStatusOpen || len(statement.Lines) != 3 {
+		t.Fatalf("statement = %+v", statement)
+	}
+	if statement.Lines[1].BilledVolumeM3 != 25 {
+		t.Errorf("billed volume = %v, want 25", statement.Lines[1].BilledVolumeM3)
+	}
+	want := []BillingContractorTotal{
+		{ContractorID: contractorA, ContractorName: "A", Vehicles: 2, Trips: 5, BilledVolumeM3: 85},
+		{ContractorID: contractorB, ContractorName: "B", Vehicles: 1, Trips: 1, BilledVolumeM3: 18},
+	}
+	if len(statement.Contractors) != len(want) {
+		t.Fatalf("contractors = %+v, want %+v", statement.Contractors, want)
+	}
+	for i := range want {
+		if statement.Contractors[i] != want[i] {
+			t.Errorf("contractor %d = %+v, want %+v", i, statement.Contractors[i], want[i])
+		}
+	}
+}
+
+func TestBillingStatementRegeneration(t *testing.T) {
+	lines := []repository.BillingLine{{ContractorID: uuid.New(), VehicleID: uuid.New(), PlateNumber: "111AAA02", BodyVolumeM3: 20, Trips: 3}}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	generatedAt := testNow.Add(-24 * time.Hour)
+	lockedAt := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		stored    *repository.BillingPeriod
+		current   []repository.BillingLine
+		wantSaved bool
+	}{
+		{
+			name:    "unchanged data keeps the stored statement",
+			stored:  &repository.BillingPeriod{Period: "2024-12", Lines: data, Checksum: checksum, GeneratedAt: generatedAt},
+			current: lines,
+		},
+		{
+			name:      "changed data is saved again",
+			stored:    &repository.BillingPeriod{Period: "2024-12", Lines: []byte("[]"), Checksum: "stale", GeneratedAt: generatedAt},
+			current:   lines,
+			wantSaved: true,
+		},
+		{
+			name:   "locked period is not recomputed",
+			stored: &repository.BillingPeriod{Period: "2024-12", Lines: data, Checksum: "locked", GeneratedAt: generatedAt, LockedAt: &lockedAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, billingTestConfig())
+			store.EXPECT().GetBillingPeriod(gomock.Any(), "2024-12").Return(tt.stored, nil)
+			if tt.current != nil {
+				store.EXPECT().GetBillingLines(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.current, nil)
+			}
+			if tt.wantSaved {
+				store.EXPECT().SaveBillingPeriod(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			statement, err := svc.BillingStatement(context.Background(), "2024-12")
+			if err != nil {
+				t.Fatalf("BillingStatement() error = %v", err)
+			}
+			if tt.wantSaved == statement.GeneratedAt.Equal(generatedAt) {
+				t.Errorf("generated_at = %v, saved = %v", statement.GeneratedAt, tt.wantSaved)
+			}
+			if (tt.stored.LockedAt != nil) != (statement.Status == BillingStatusLocked) {
+				t.Errorf("status = %q", statement.Status)
+			}
+		})
+	}
+}
+
+func TestLockBillingPeriod(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	kgu := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhAdmin})
+	lockedAt := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		period  string
+		stored  *repository.BillingPeriod
+		wantErr error
+	}{
+		{name: "only akimat admin", ctx: kgu, period: "2024-12", wantErr: ErrForbidden},
+		{name: "current month cannot be locked", ctx: admin, period: "2025-01", wantErr: ErrInvalidInput},
+		{name: "already locked", ctx: admin, period: "2024-12", stored: &repository.BillingPeriod{Period: "2024-12", Lines: []byte("[]"), LockedAt: &lockedAt}, wantErr: ErrBillingPeriodLocked},
+		{name: "lock", ctx: admin, period: "2024-12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, billingTestConfig())
+			if tt.wantErr != ErrForbidden && tt.wantErr != ErrInvalidInput {
+				var getErr error
+				if tt.stored == nil {
+					getErr = repository.ErrBillingPeriodNotFound
+				}
+				store.EXPECT().GetBillingPeriod(gomock.Any(), tt.period).Return(tt.stored, getErr)
+			}
+			if tt.wantErr == nil {
+				store.EXPECT().GetBillingLines(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+				store.EXPECT().SaveBillingPeriod(gomock.Any(), gomock.Any()).Return(nil)
+				store.EXPECT().LockBillingPeriod(gomock.Any(), tt.period, gomock.Any(), testNow).Return(true, nil)
+			}
+
+			statement, err := svc.LockBillingPeriod(tt.ctx, tt.period)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("LockBillingPeriod() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LockBillingPeriod() error = %v", err)
+			}
+			if statement.Status != BillingStatusLocked || statement.LockedAt == nil {
+				t.Errorf("statement = %+v, want locked", statement)
+			}
+		})
+	}
+}
+
+func TestExportBillingStatementSignature(t *testing.T) {
+	svc, store := newTestService(t, billingTestConfig())
+	store.EXPECT().GetBillingPeriod(gomock.Any(), "2024-12").Return(nil, repository.ErrBillingPeriodNotFound)
+	store.EXPECT().GetBillingLines(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	store.EXPECT().SaveBillingPeriod(gomock.Any(), gomock.Any()).Return(nil)
+
+	data, filename, signature, err := svc.ExportBillingStatement(context.Background(), "2024-12", "")
+	if err != nil {
+		t.Fatalf("ExportBillingStatement() error = %v", err)
+	}
+	if filename != "anpr-billing_2024-12.csv" {
+		t.Errorf("filename = %q", filename)
+	}
+	if signature != SignBillingExport("billing-secret", data) {
+		t.Errorf("signature %q does not match body", signature)
+	}
+
+	unsigned, _ := newTestService(t, nil)
+	if _, _, _, err := unsigned.ExportBillingStatement(context.Background(), "2024-12", ""); !errors.Is(err, ErrBillingNotConfigured) {
+		t.Errorf("error without key = %v, want ErrBillingNotConfigured", err)
+	}
+}
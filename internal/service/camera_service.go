@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// minClockSkewSamples — минимальное число измерений, после которого поправка часов считается надёжной
+const minClockSkewSamples = 5
+
+type CameraInfo struct {
+	ID                 string     `json:"id"`
+	Name               *string    `json:"name,omitempty"`
+	TimeZone           string     `json:"timezone"`
+	ClockSkewSeconds   *float64   `json:"clock_skew_seconds,omitempty"`
+	ClockSkewSamples   int        `json:"clock_skew_samples"`
+	ClockSkewUpdatedAt *time.Time `json:"clock_skew_updated_at,omitempty"`
+	ClockAutoCorrect   bool       `json:"clock_auto_correct"`
+	ArmedSchedule      *string    `json:"armed_schedule,omitempty"`
+	HTTPHost           *string    `json:"http_host,omitempty"`
+	WhitelistSync      bool       `json:"whitelist_sync"`
+	WhitelistSyncedAt  *time.Time `json:"whitelist_synced_at,omitempty"`
+	PolygonID          *string    `json:"polygon_id,omitempty"`
+	Latitude           *float64   `json:"latitude,omitempty"`
+	Longitude          *float64   `json:"longitude,omitempty"`
+	DefaultDirection   *string    `json:"default_direction,omitempty"`
+	DirectionInference *string    `json:"direction_inference,omitempty"`
+	LaneDirections     *string    `json:"lane_directions,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type UpdateCameraInput struct {
+	Name             *string
+	TimeZone         *string
+	ClockAutoCorrect *bool
+	ArmedSchedule    *string
+	HTTPHost         *string
+	WhitelistSync    *bool
+	PolygonID        *string // пусто — отвязать камеру от полигона
+	Latitude         *float64
+	Longitude        *float64
+	// Направление без данных камеры (см. resolveDirection); пусто — сбросить
+	DefaultDirection   *string
+	DirectionInference *string
+	LaneDirections     *string
+}
+
+// CameraLocation возвращает часовой пояс камеры для разбора локального времени события.
+// Если камера не зарегистрирована или пояс не задан, используется CAMERA_DEFAULT_TIMEZONE.
+func (s *ANPRService) CameraLocation(ctx context.Context, cameraID string) *time.Location {
+	return s.cameraLocation(s.lookupCamera(ctx, cameraID))
+}
+
+// lookupCamera получает камеру из реестра; ошибки логируются и трактуются как отсутствие камеры,
+// чтобы недоступность реестра не блокировала приём событий
+func (s *ANPRService) lookupCamera(ctx context.Context, cameraID string) *repository.Camera {
+	cameraID = strings.TrimSpace(cameraID)
+	if cameraID == "" {
+		return nil
+	}
+	camera, err := s.repo.GetCamera(ctx, cameraID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("camera_id", cameraID).Msg("failed to load camera settings")
+		return nil
+	}
+	return camera
+}
+
+func (s *ANPRService) cameraLocation(camera *repository.Camera) *time.Location {
+	if camera == nil || camera.TimeZone == nil || *camera.TimeZone == "" {
+		return s.defaultCameraLocation()
+	}
+	loc, err := time.LoadLocation(*camera.TimeZone)
+	if err != nil {
+		s.log.Warn().Err(err).Str("camera_id", camera.ID).Str("timezone", *camera.TimeZone).Msg("invalid camera timezone, using default")
+		return s.defaultCameraLocation()
+	}
+	return loc
+}
+
+// cameraArmed проверяет, попадает ли событие в расписание работы камеры.
+// Камеры без расписания (или с некорректным расписанием) считаются активными всегда.
+func (s *ANPRService) cameraArmed(camera *repository.Camera, eventTime time.Time) bool {
+	if camera == nil || camera.ArmedSchedule == nil {
+		return true
+	}
+	schedule, err := ParseArmedSchedule(*camera.ArmedSchedule)
+	if err != nil {
+		s.log.Warn().Err(err).Str("camera_id", camera.ID).Msg("invalid camera armed schedule, treating camera as armed")
+		return true
+	}
+	return schedule.Contains(eventTime, s.cameraLocation(camera))
+}
+
+func (s *ANPRService) defaultCameraLocation() *time.Location {
+	loc, err := time.LoadLocation(s.Config().Ingest.DefaultCameraTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ListCameras возвращает реестр камер
+func (s *ANPRService) ListCameras(ctx context.Context) ([]CameraInfo, error) {
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cameras: %w", err)
+	}
+
+	result := make([]CameraInfo, 0, len(cameras))
+	for _, camera := range cameras {
+		result = append(result, s.toCameraInfo(camera))
+	}
+	return result, nil
+}
+
+// UpdateCamera регистрирует камеру или обновляет её настройки
+func (s *ANPRService) UpdateCamera(ctx context.Context, cameraID string, input UpdateCameraInput) (*CameraInfo, error) {
+	cameraID = strings.TrimSpace(cameraID)
+	if cameraID == "" {
+		return nil, fmt.Errorf("%w: camera id is required", ErrInvalidInput)
+	}
+
+	camera, err := s.repo.GetCamera(ctx, cameraID)
+	if errors.Is(err, ErrNotFound) {
+		camera = &repository.Camera{ID: cameraID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		camera.Name = &name
+	}
+	if input.TimeZone != nil {
+		tz := strings.TrimSpace(*input.TimeZone)
+		if tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil {
+				return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, tz)
+			}
+			camera.TimeZone = &tz
+		} else {
+			camera.TimeZone = nil
+		}
+	}
+
+	if input.ClockAutoCorrect != nil {
+		camera.ClockAutoCorrect = *input.ClockAutoCorrect
+	}
+	if input.ArmedSchedule != nil {
+		schedule := strings.TrimSpace(*input.ArmedSchedule)
+		if schedule != "" {
+			if _, err := ParseArmedSchedule(schedule); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+			}
+			camera.ArmedSchedule = &schedule
+		} else {
+			camera.ArmedSchedule = nil
+		}
+	}
+
+	if input.HTTPHost != nil {
+		host := strings.TrimSpace(*input.HTTPHost)
+		if host != "" {
+			if u, err := url.Parse(host); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("%w: http_host must be an http(s) url", ErrInvalidInput)
+			}
+			camera.HTTPHost = &host
+		} else {
+			camera.HTTPHost = nil
+		}
+	}
+
+	if input.WhitelistSync != nil {
+		camera.WhitelistSync = *input.WhitelistSync
+	}
+
+	if input.PolygonID != nil {
+		raw := strings.TrimSpace(*input.PolygonID)
+		if raw != "" {
+			polygonID, err := uuid.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+			}
+			if _, err := s.repo.GetPolygon(ctx, polygonID); errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: unknown polygon %s", ErrInvalidInput, polygonID)
+			} else if err != nil {
+				return nil, err
+			}
+			camera.PolygonID = &polygonID
+		} else {
+			camera.PolygonID = nil
+		}
+	}
+
+	if input.Latitude != nil || input.Longitude != nil {
+		if input.Latitude == nil || input.Longitude == nil {
+			return nil, fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidInput)
+		}
+		if *input.Latitude < -90 || *input.Latitude > 90 || *input.Longitude < -180 || *input.Longitude > 180 {
+			return nil, fmt.Errorf("%w: coordinates are out of range", ErrInvalidInput)
+		}
+		camera.Latitude = input.Latitude
+		camera.Longitude = input.Longitude
+	}
+
+	if input.DefaultDirection != nil {
+		direction := strings.ToLower(strings.TrimSpace(*input.DefaultDirection))
+		if direction != "" && !isDirection(direction) {
+			return nil, fmt.Errorf("%w: default_direction must be entry or exit", ErrInvalidInput)
+		}
+		camera.DefaultDirection = optionalString(direction)
+	}
+	if input.LaneDirections != nil {
+		lanes := strings.TrimSpace(*input.LaneDirections)
+		if _, err := ParseLaneDirections(lanes); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		camera.LaneDirections = optionalString(lanes)
+	}
+	if input.DirectionInference != nil {
+		mode := strings.ToLower(strings.TrimSpace(*input.DirectionInference))
+		if mode != "" && mode != DirectionInferenceLane && mode != DirectionInferenceAlternate {
+			return nil, fmt.Errorf("%w: direction_inference must be %s or %s", ErrInvalidInput, DirectionInferenceLane, DirectionInferenceAlternate)
+		}
+		camera.DirectionInference = optionalString(mode)
+	}
+	if stringOrEmpty(camera.DirectionInference) == DirectionInferenceLane && camera.LaneDirections == nil {
+		return nil, fmt.Errorf("%w: lane_directions are required for direction_inference=%s", ErrInvalidInput, DirectionInferenceLane)
+	}
+
+	if err := s.repo.UpsertCamera(ctx, camera); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().Str("camera_id", cameraID).Msg("camera settings updated")
+
+	info := s.toCameraInfo(*camera)
+	return &info, nil
+}
+
+func (s *ANPRService) toCameraInfo(camera repository.Camera) CameraInfo {
+	tz := s.Config().Ingest.DefaultCameraTimeZone
+	if camera.TimeZone != nil && *camera.TimeZone != "" {
+		tz = *camera.TimeZone
+	}
+	var httpHost *string
+	if camera.HTTPHost != nil {
+		host := redactURL(*camera.HTTPHost)
+		httpHost = &host
+	}
+	var polygonID *string
+	if camera.PolygonID != nil {
+		id := camera.PolygonID.String()
+		polygonID = &id
+	}
+	return CameraInfo{
+		ID:                 camera.ID,
+		Name:               camera.Name,
+		TimeZone:           tz,
+		ClockSkewSeconds:   camera.ClockSkewSeconds,
+		ClockSkewSamples:   camera.ClockSkewSamples,
+		ClockSkewUpdatedAt: camera.ClockSkewUpdatedAt,
+		ClockAutoCorrect:   camera.ClockAutoCorrect,
+		ArmedSchedule:      camera.ArmedSchedule,
+		HTTPHost:           httpHost,
+		WhitelistSync:      camera.WhitelistSync,
+		WhitelistSyncedAt:  camera.WhitelistSyncedAt,
+		PolygonID:          polygonID,
+		Latitude:           camera.Latitude,
+		Longitude:          camera.Longitude,
+		DefaultDirection:   camera.DefaultDirection,
+		DirectionInference: camera.DirectionInference,
+		LaneDirections:     camera.LaneDirections,
+		CreatedAt:          camera.CreatedAt,
+		UpdatedAt:          camera.UpdatedAt,
+	}
+}
+
+// observeCameraClock учитывает расхождение часов камеры (event_time - время приёма) и,
+// если для камеры включена автокоррекция, сдвигает event_time на измеренную поправку.
+// Возвращает применённую поправку в секундах (nil — время не корректировалось).
+func (s *ANPRService) observeCameraClock(ctx context.Context, camera *repository.Camera, payload *anpr.EventPayload, receivedAt time.Time) *float64 {
+	if camera == nil {
+		return nil
+	}
+
+	skew := payload.EventTime.Sub(receivedAt)
+	if absDuration(skew) <= s.Config().Ingest.ClockSkewSampleLimit {
+		if err := s.repo.RecordCameraClockSkew(ctx, camera.ID, skew.Seconds()); err != nil {
+			s.logger(ctx).Warn().Err(err).Str("camera_id", camera.ID).Msg("failed to record camera clock skew")
+		}
+	}
+
+	if !camera.ClockAutoCorrect || camera.ClockSkewSeconds == nil || camera.ClockSkewSamples < minClockSkewSamples {
+		return nil
+	}
+
+	offset := *camera.ClockSkewSeconds
+	if payload.RawPayload == nil {
+		payload.RawPayload = make(map[string]interface{})
+	}
+	payload.RawPayload["camera_event_time"] = payload.EventTime.Format(time.RFC3339Nano)
+	payload.EventTime = payload.EventTime.Add(-time.Duration(offset * float64(time.Second)))
+
+	s.logger(ctx).Debug().
+		Str("camera_id", camera.ID).
+		Float64("correction_seconds", offset).
+		Time("event_time", payload.EventTime).
+		Msg("event_time corrected by measured camera clock skew")
+
+	return &offset
+}
+
+// CameraHTTPHost возвращает адрес ISAPI камеры: из реестра, а если он не задан — CAMERA_HTTP_HOST
+func (s *ANPRService) CameraHTTPHost(ctx context.Context, cameraID string) (string, error) {
+	camera, err := s.repo.GetCamera(ctx, strings.TrimSpace(cameraID))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	if camera != nil && camera.HTTPHost != nil && *camera.HTTPHost != "" {
+		return *camera.HTTPHost, nil
+	}
+	if s.Config().Camera.HTTPHost == "" {
+		return "", fmt.Errorf("%w: camera http host is not configured", ErrInvalidInput)
+	}
+	return s.Config().Camera.HTTPHost, nil
+}
+
+// redactURL скрывает пароль в адресе камеры
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	return u.Redacted()
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// CameraFeatureCollection — камеры на карте (GeoJSON FeatureCollection, RFC 7946)
+type CameraFeatureCollection struct {
+	Type     string          `json:"type"`
+	Features []CameraFeature `json:"features"`
+}
+
+// CameraFeature — камера как точка GeoJSON
+type CameraFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Geometry   GeoJSONPoint      `json:"geometry"`
+	Properties CameraMapProperty `json:"properties"`
+}
+
+// GeoJSONPoint — точка GeoJSON; координаты в порядке [долгота, широта]
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// CameraMapProperty — состояние камеры для всплывающей подсказки на карте
+type CameraMapProperty struct {
+	CameraLiveness
+	PolygonID *string `json:"polygon_id,omitempty"`
+}
+
+// CameraMap возвращает зарегистрированные камеры с координатами, их статусом и временем последнего
+// события. Камеры без координат на карту не попадают.
+func (s *ANPRService) CameraMap(ctx context.Context, now time.Time) (*CameraFeatureCollection, error) {
+	cameras, err := s.repo.ListCamerasWithLastEvent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cameras for map: %w", err)
+	}
+
+	result := &CameraFeatureCollection{Type: "FeatureCollection", Features: []CameraFeature{}}
+	for _, camera := range cameras {
+		if camera.Latitude == nil || camera.Longitude == nil {
+			continue
+		}
+		props := CameraMapProperty{CameraLiveness: s.cameraLiveness(camera, now)}
+		if camera.PolygonID != nil {
+			id := camera.PolygonID.String()
+			props.PolygonID = &id
+		}
+		result.Features = append(result.Features, CameraFeature{
+			Type: "Feature",
+			ID:   camera.ID,
+			Geometry: GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{*camera.Longitude, *camera.Latitude},
+			},
+			Properties: props,
+		})
+	}
+	return result, nil
+}
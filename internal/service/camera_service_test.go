@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+func TestCameraMap(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Health.CameraSilenceThreshold = 30 * time.Minute
+	svc, store := newTestService(t, cfg)
+
+	lat, lon := 43.2389, 76.8897
+	lastEvent := testNow.Add(-2 * time.Hour)
+	store.EXPECT().ListCamerasWithLastEvent(gomock.Any()).Return([]repository.CameraLastEvent{
+		{Camera: repository.Camera{ID: "cam-map", Latitude: &lat, Longitude: &lon}, LastEventAt: &lastEvent},
+		{Camera: repository.Camera{ID: "cam-hidden"}},
+	}, nil)
+
+	got, err := svc.CameraMap(context.Background(), testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "FeatureCollection" || len(got.Features) != 1 {
+		t.Fatalf("got %+v, want one feature", got)
+	}
+	feature := got.Features[0]
+	if feature.ID != "cam-map" || feature.Geometry.Coordinates != [2]float64{lon, lat} {
+		t.Errorf("unexpected feature %+v", feature)
+	}
+	if feature.Properties.Status != CameraStatusSilent || feature.Properties.LastEventAt == nil {
+		t.Errorf("unexpected properties %+v", feature.Properties)
+	}
+}
+
+func TestUpdateCameraCoordinates(t *testing.T) {
+	coord := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		lat, lon *float64
+		wantErr  error
+	}{
+		{name: "both set", lat: coord(43.2), lon: coord(76.9)},
+		{name: "latitude only", lat: coord(43.2), wantErr: ErrInvalidInput},
+		{name: "out of range", lat: coord(91), lon: coord(76.9), wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, &config.Config{})
+			store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(&repository.Camera{ID: "cam-1"}, nil)
+			if tt.wantErr == nil {
+				store.EXPECT().UpsertCamera(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			info, err := svc.UpdateCamera(context.Background(), "cam-1", UpdateCameraInput{Latitude: tt.lat, Longitude: tt.lon})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (info.Latitude == nil || *info.Latitude != *tt.lat) {
+				t.Errorf("latitude = %v, want %v", info.Latitude, *tt.lat)
+			}
+		})
+	}
+}
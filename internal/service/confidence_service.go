@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// confidenceBucketWidth — ширина интервала уверенности в отчёте (см. repository.ConfidenceStat)
+	confidenceBucketWidth = 0.05
+	// defaultConfidenceMaxErrorRate — допустимая доля исправленных номеров выше предлагаемого порога
+	defaultConfidenceMaxErrorRate = 0.02
+	// confidenceMinSamples — сколько событий выше порога нужно, чтобы его предлагать
+	confidenceMinSamples = 30
+)
+
+// ConfidenceBucketInfo — события камеры с уверенностью в [From, To) и доля исправленных вручную
+type ConfidenceBucketInfo struct {
+	From           float64 `json:"from"`
+	To             float64 `json:"to"`
+	Events         int64   `json:"events"`
+	Corrected      int64   `json:"corrected"`
+	CorrectionRate float64 `json:"correction_rate"`
+}
+
+// CameraConfidenceInfo — калибровка уверенности распознавания камеры
+type CameraConfidenceInfo struct {
+	CameraID       string                 `json:"camera_id"`
+	Events         int64                  `json:"events"`
+	Corrected      int64                  `json:"corrected"`
+	CorrectionRate float64                `json:"correction_rate"`
+	Buckets        []ConfidenceBucketInfo `json:"buckets"`
+	// SuggestedMinConfidence — наименьший порог уверенности, при котором среди событий не ниже порога доля
+	// исправленных не больше max_error_rate; nil — событий слишком мало или порог недостижим
+	SuggestedMinConfidence *float64 `json:"suggested_min_confidence,omitempty"`
+	// EventsBelowSuggested — сколько событий периода оказались бы ниже предлагаемого порога
+	EventsBelowSuggested int64 `json:"events_below_suggested,omitempty"`
+}
+
+// ConfidenceReport — сравнение уверенности камер с ручными исправлениями номеров
+type ConfidenceReport struct {
+	From         time.Time              `json:"from"`
+	To           time.Time              `json:"to"`
+	MaxErrorRate float64                `json:"max_error_rate"`
+	Cameras      []CameraConfidenceInfo `json:"cameras"`
+}
+
+// ConfidenceCalibration сравнивает уверенность распознавания камер с исходом ручной проверки: номер события
+// исправлен, если оператор слил его с основным номером или он заменён псевдонимом. По каждой камере
+// предлагается порог уверенности, выше которого доля исправлений не больше maxErrorRate (по умолчанию 2%).
+func (s *ANPRService) ConfidenceCalibration(ctx context.Context, query TrafficQuery, maxErrorRate string) (*ConfidenceReport, error) {
+	maxRate := defaultConfidenceMaxErrorRate
+	if raw := strings.TrimSpace(maxErrorRate); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed >= 1 {
+			return nil, fmt.Errorf("%w: max_error_rate must be between 0 and 1", ErrInvalidInput)
+		}
+		maxRate = parsed
+	}
+	filters, err := s.trafficFilters(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetConfidenceStats(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConfidenceReport{From: filters.From, To: filters.To, MaxErrorRate: maxRate, Cameras: []CameraConfidenceInfo{}}
+	// Строки упорядочены по камере и интервалу
+	for _, row := range rows {
+		if len(report.Cameras) == 0 || report.Cameras[len(report.Cameras)-1].CameraID != row.CameraID {
+			report.Cameras = append(report.Cameras, CameraConfidenceInfo{CameraID: row.CameraID})
+		}
+		camera := &report.Cameras[len(report.Cameras)-1]
+		camera.Events += row.Events
+		camera.Corrected += row.Corrected
+		camera.Buckets = append(camera.Buckets, ConfidenceBucketInfo{
+			From:           roundRate(float64(row.Bucket) * confidenceBucketWidth),
+			To:             roundRate(float64(row.Bucket+1) * confidenceBucketWidth),
+			Events:         row.Events,
+			Corrected:      row.Corrected,
+			CorrectionRate: correctionRate(row.Corrected, row.Events),
+		})
+	}
+	for i := range report.Cameras {
+		camera := &report.Cameras[i]
+		camera.CorrectionRate = correctionRate(camera.Corrected, camera.Events)
+		suggestConfidenceThreshold(camera, maxRate)
+	}
+	return report, nil
+}
+
+// suggestConfidenceThreshold идёт от самых уверенных интервалов к менее уверенным и запоминает нижнюю
+// границу последнего интервала, при котором доля исправлений среди событий не ниже границы допустима
+func suggestConfidenceThreshold(camera *CameraConfidenceInfo, maxRate float64) {
+	var events, corrected int64
+	for i := len(camera.Buckets) - 1; i >= 0; i-- {
+		bucket := camera.Buckets[i]
+		events += bucket.Events
+		corrected += bucket.Corrected
+		if events >= confidenceMinSamples && float64(corrected) <= maxRate*float64(events) {
+			threshold := bucket.From
+			camera.SuggestedMinConfidence = &threshold
+			camera.EventsBelowSuggested = camera.Events - events
+		}
+	}
+}
+
+func correctionRate(corrected, events int64) float64 {
+	if events == 0 {
+		return 0
+	}
+	return roundRate(float64(corrected) / float64(events))
+}
+
+// roundRate округляет долю до 0.001
+func roundRate(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anpr-service/internal/config"
+)
+
+// ConfigInfo — действующая конфигурация для администратора
+type ConfigInfo struct {
+	// Config — настройки по секциям; секреты скрыты
+	Config map[string]any `json:"config"`
+	// Reloadable — переменные, изменения которых в файле конфигурации применяются без перезапуска
+	Reloadable []string `json:"reloadable"`
+	// File — файл конфигурации, за которым следит сервис (пусто — только переменные окружения)
+	File string `json:"file,omitempty"`
+	// ReloadedAt — когда настройки последний раз менялись без перезапуска
+	ReloadedAt *time.Time `json:"reloaded_at,omitempty"`
+}
+
+// AdminConfig возвращает действующую конфигурацию со скрытыми секретами. Доступно администратору акимата.
+func (s *ANPRService) AdminConfig(ctx context.Context) (*ConfigInfo, error) {
+	if _, err := requirePrincipal(ctx, canViewConfig); err != nil {
+		return nil, err
+	}
+	info := &ConfigInfo{
+		Config:     s.Config().Masked(),
+		Reloadable: config.ReloadableKeys(),
+		File:       s.live.File(),
+	}
+	if reloadedAt := s.live.ReloadedAt(); !reloadedAt.IsZero() {
+		info.ReloadedAt = &reloadedAt
+	}
+	return info, nil
+}
+
+// WatchConfig следит за файлом конфигурации и применяет изменения перезагружаемых настроек.
+// Конфигурация с ошибкой не применяется; изменения остальных настроек ждут перезапуска.
+func (s *ANPRService) WatchConfig(ctx context.Context) {
+	file := s.live.Watch(ctx, s.clock.Now, func(result config.ReloadResult, err error) {
+		if err != nil {
+			s.log.Error().Err(err).Msg("config file changed but is invalid, keeping current settings")
+			return
+		}
+		if len(result.Changed) > 0 {
+			s.log.Info().Strs("changed", result.Changed).Msg("config reloaded")
+		}
+		if result.RestartRequired {
+			s.log.Warn().Msg("config file has changes that take effect only after restart")
+		}
+	})
+	if file == "" {
+		s.log.Info().Msg("no config file found, config reload disabled")
+		return
+	}
+	s.log.Info().Str("file", file).Msg("watching config file for changes")
+}
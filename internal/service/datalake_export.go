@@ -0,0 +1,284 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// DataLakeSchemaVersion — версия схемы строк выгрузки. Меняется, когда поле удаляется или меняет тип;
+// новые поля добавляются без смены версии. Версия входит в путь выгрузки, поэтому файлы разных версий
+// не смешиваются.
+const DataLakeSchemaVersion = 1
+
+// dataLakePageSize — размер страницы при выборке событий для выгрузки
+const dataLakePageSize = 1000
+
+// ErrDataLakeNotConfigured — выгрузка включена, но хранилище для неё не задано
+var ErrDataLakeNotConfigured = errors.New("data lake storage is not configured")
+
+// DataLakeStore — хранилище выгрузки (бакет DATALAKE_BUCKET или бакет фото)
+type DataLakeStore interface {
+	Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+}
+
+// UseDataLake задаёт хранилище ночной выгрузки событий
+func (s *ANPRService) UseDataLake(store DataLakeStore) {
+	s.dataLake = store
+}
+
+// DataLakeEvent — строка выгрузки (NDJSON): событие без фото и исходного payload камеры
+type DataLakeEvent struct {
+	ID               uuid.UUID  `json:"id"`
+	EventTime        time.Time  `json:"event_time"`
+	ReceivedAt       time.Time  `json:"received_at"`
+	CameraID         string     `json:"camera_id"`
+	PolygonID        *uuid.UUID `json:"polygon_id"`
+	ContractorID     *uuid.UUID `json:"contractor_id"`
+	Plate            string     `json:"plate"`
+	Direction        *string    `json:"direction"`
+	Lane             *int       `json:"lane"`
+	Confidence       *float64   `json:"confidence"`
+	VehicleType      *string    `json:"vehicle_type"`
+	VehicleColor     *string    `json:"vehicle_color"`
+	VehicleCountry   *string    `json:"vehicle_country"`
+	VehicleSpeed     *float64   `json:"vehicle_speed"`
+	Source           string     `json:"source"`
+	SnowVolumeM3     *float64   `json:"snow_volume_m3"`
+	MatchedSnow      bool       `json:"matched_snow"`
+	OutOfSchedule    bool       `json:"out_of_schedule"`
+	Late             bool       `json:"late"`
+	WrongDestination bool       `json:"wrong_destination"`
+	OverQuota        bool       `json:"over_quota"`
+	AccessDecision   *string    `json:"access_decision"`
+	DecisionReason   *string    `json:"decision_reason"`
+}
+
+// DataLakeColumn — поле строки выгрузки в манифесте
+type DataLakeColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// DataLakeFile — файл выгрузки в манифесте
+type DataLakeFile struct {
+	Key    string `json:"key"`
+	Rows   int    `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// DataLakeManifest — описание выгрузки за день. Записывается последним: выгрузка без манифеста не завершена.
+type DataLakeManifest struct {
+	SchemaVersion int    `json:"schema_version"`
+	Dataset       string `json:"dataset"`
+	Date          string `json:"date"`
+	// From, To — границы дня [from, to) в поясе JOB_TIMEZONE
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	Format      string           `json:"format"`
+	Compression string           `json:"compression"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Rows        int              `json:"rows"`
+	Files       []DataLakeFile   `json:"files"`
+	Columns     []DataLakeColumn `json:"columns"`
+}
+
+// ExportDataLakeYesterday выгружает события прошедшего дня (по JOB_TIMEZONE); задача планировщика
+func (s *ANPRService) ExportDataLakeYesterday(ctx context.Context) error {
+	loc, err := time.LoadLocation(s.Config().Jobs.TimeZone)
+	if err != nil {
+		return fmt.Errorf("invalid job timezone: %w", err)
+	}
+	now := s.clock.Now().In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+	_, err = s.ExportDataLakeDay(ctx, day)
+	return err
+}
+
+// ExportDataLakeDay выгружает события дня day (полночь в нужном поясе) в DATALAKE_PREFIX:
+// {prefix}/events/v{версия}/date=YYYY-MM-DD/part-NNNNN.ndjson.gz и manifest.json рядом с ними.
+// Повторная выгрузка того же дня перезаписывает файлы и манифест.
+func (s *ANPRService) ExportDataLakeDay(ctx context.Context, day time.Time) (*DataLakeManifest, error) {
+	if s.dataLake == nil {
+		return nil, ErrDataLakeNotConfigured
+	}
+	cfg := s.Config().DataLake
+	from, to := day, day.AddDate(0, 0, 1)
+	dir := fmt.Sprintf("%s/events/v%d/date=%s", cfg.Prefix, DataLakeSchemaVersion, day.Format(time.DateOnly))
+
+	manifest := &DataLakeManifest{
+		SchemaVersion: DataLakeSchemaVersion,
+		Dataset:       "anpr_events",
+		Date:          day.Format(time.DateOnly),
+		From:          from,
+		To:            to,
+		Format:        "ndjson",
+		Compression:   "gzip",
+		Files:         []DataLakeFile{},
+		Columns:       dataLakeColumns(),
+	}
+
+	part := newDataLakePart()
+	flush := func() error {
+		if part.rows == 0 {
+			return nil
+		}
+		key := fmt.Sprintf("%s/part-%05d.ndjson.gz", dir, len(manifest.Files))
+		file, err := part.upload(ctx, s.dataLake, key)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, file)
+		manifest.Rows += file.Rows
+		part = newDataLakePart()
+		return nil
+	}
+
+	afterTime, afterID := time.Time{}, uuid.Nil
+	for {
+		events, err := s.repo.ListEventsForExport(ctx, from, to, afterTime, afterID, dataLakePageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			if err := part.write(toDataLakeEvent(&events[i])); err != nil {
+				return nil, err
+			}
+			if part.rows >= cfg.PartRows {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(events) < dataLakePageSize {
+			break
+		}
+		last := events[len(events)-1]
+		afterTime, afterID = last.EventTime, last.ID
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	manifest.GeneratedAt = s.clock.Now().UTC()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data lake manifest: %w", err)
+	}
+	if _, err := s.dataLake.Upload(ctx, dir+"/manifest.json", bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to upload data lake manifest: %w", err)
+	}
+	s.log.Info().
+		Str("date", manifest.Date).
+		Int("rows", manifest.Rows).
+		Int("files", len(manifest.Files)).
+		Str("prefix", dir).
+		Msg("events exported to data lake")
+	return manifest, nil
+}
+
+func toDataLakeEvent(e *repository.ANPREvent) DataLakeEvent {
+	return DataLakeEvent{
+		ID:               e.ID,
+		EventTime:        e.EventTime.UTC(),
+		ReceivedAt:       e.ReceivedAt.UTC(),
+		CameraID:         e.CameraID,
+		PolygonID:        e.PolygonID,
+		ContractorID:     e.ContractorID,
+		Plate:            e.NormalizedPlate,
+		Direction:        e.Direction,
+		Lane:             e.Lane,
+		Confidence:       e.Confidence,
+		VehicleType:      e.VehicleType,
+		VehicleColor:     e.VehicleColor,
+		VehicleCountry:   e.VehicleCountry,
+		VehicleSpeed:     e.VehicleSpeed,
+		Source:           e.Source,
+		SnowVolumeM3:     e.SnowVolumeM3,
+		MatchedSnow:      e.MatchedSnow,
+		OutOfSchedule:    e.OutOfSchedule,
+		Late:             e.Late,
+		WrongDestination: e.WrongDestination,
+		OverQuota:        e.OverQuota,
+		AccessDecision:   e.AccessDecision,
+		DecisionReason:   e.DecisionReason,
+	}
+}
+
+// dataLakePart — файл выгрузки, собираемый в памяти
+type dataLakePart struct {
+	buf  bytes.Buffer
+	gz   *gzip.Writer
+	enc  *json.Encoder
+	rows int
+}
+
+func newDataLakePart() *dataLakePart {
+	p := &dataLakePart{}
+	p.gz = gzip.NewWriter(&p.buf)
+	p.enc = json.NewEncoder(p.gz)
+	return p
+}
+
+func (p *dataLakePart) write(event DataLakeEvent) error {
+	if err := p.enc.Encode(event); err != nil {
+		return fmt.Errorf("failed to encode data lake row: %w", err)
+	}
+	p.rows++
+	return nil
+}
+
+func (p *dataLakePart) upload(ctx context.Context, store DataLakeStore, key string) (DataLakeFile, error) {
+	if err := p.gz.Close(); err != nil {
+		return DataLakeFile{}, fmt.Errorf("failed to compress data lake part: %w", err)
+	}
+	data := p.buf.Bytes()
+	sum := sha256.Sum256(data)
+	if _, err := store.Upload(ctx, key, bytes.NewReader(data), int64(len(data)), "application/gzip"); err != nil {
+		return DataLakeFile{}, fmt.Errorf("failed to upload data lake part %s: %w", key, err)
+	}
+	return DataLakeFile{Key: key, Rows: p.rows, Bytes: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// dataLakeColumns описывает поля DataLakeEvent для манифеста
+func dataLakeColumns() []DataLakeColumn {
+	t := reflect.TypeOf(DataLakeEvent{})
+	columns := make([]DataLakeColumn, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		typ := field.Type
+		column := DataLakeColumn{Name: name}
+		if typ.Kind() == reflect.Pointer {
+			column.Nullable = true
+			typ = typ.Elem()
+		}
+		switch {
+		case typ == reflect.TypeOf(time.Time{}):
+			column.Type = "timestamp"
+		case typ == reflect.TypeOf(uuid.UUID{}):
+			column.Type = "uuid"
+		case typ.Kind() == reflect.Int:
+			column.Type = "int64"
+		default:
+			column.Type = typ.Kind().String()
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
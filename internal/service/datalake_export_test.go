@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+// memoryDataLake — хранилище выгрузки в памяти
+type memoryDataLake map[string][]byte
+
+func (m memoryDataLake) Upload(_ context.Context, key string, body io.Reader, _ int64, _ string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	m[key] = data
+	return key, nil
+}
+
+func TestExportDataLakeYesterday(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Jobs.TimeZone = "Asia/Almaty"
+	cfg.DataLake = config.DataLakeConfig{Enabled: true, Prefix: "datalake/anpr", PartRows: 2}
+	svc, store := newTestService(t, cfg)
+	lake := memoryDataLake{}
+	svc.UseDataLake(lake)
+
+	// testNow — 16 января 03:00 в Алматы, выгружается 15 января
+	loc, _ := time.LoadLocation("Asia/Almaty")
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, loc)
+	events := []repository.ANPREvent{
+		{ID: uuid.New(), CameraID: "cam-1", NormalizedPlate: "123ABC02", EventTime: from.Add(time.Hour), Source: "camera"},
+		{ID: uuid.New(), CameraID: "cam-1", NormalizedPlate: "456DEF02", EventTime: from.Add(2 * time.Hour), Source: "camera"},
+		{ID: uuid.New(), CameraID: "cam-2", NormalizedPlate: "789GHI02", EventTime: from.Add(3 * time.Hour), Source: "manual"},
+	}
+	store.EXPECT().ListEventsForExport(gomock.Any(), from, from.AddDate(0, 0, 1), time.Time{}, uuid.Nil, dataLakePageSize).Return(events, nil)
+
+	if err := svc.ExportDataLakeYesterday(context.Background()); err != nil {
+		t.Fatalf("ExportDataLakeYesterday() error = %v", err)
+	}
+
+	dir := "datalake/anpr/events/v1/date=2025-01-15"
+	var manifest DataLakeManifest
+	if err := json.Unmarshal(lake[dir+"/manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if manifest.SchemaVersion != DataLakeSchemaVersion || manifest.Rows != 3 || len(manifest.Files) != 2 || len(manifest.Columns) == 0 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	var plates []string
+	for i, file := range manifest.Files {
+		if want := fmt.Sprintf("%s/part-%05d.ndjson.gz", dir, i); file.Key != want {
+			t.Errorf("file %d key = %s, want %s", i, file.Key, want)
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(lake[file.Key]))
+		if err != nil {
+			t.Fatalf("gzip %s: %v", file.Key, err)
+		}
+		scanner := bufio.NewScanner(reader)
+		rows := 0
+		for scanner.Scan() {
+			var row DataLakeEvent
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				t.Fatalf("row in %s: %v", file.Key, err)
+			}
+			plates = append(plates, row.Plate)
+			rows++
+		}
+		if rows != file.Rows {
+			t.Errorf("%s has %d rows, manifest says %d", file.Key, rows, file.Rows)
+		}
+	}
+	if len(plates) != 3 || plates[0] != "123ABC02" || plates[2] != "789GHI02" {
+		t.Fatalf("exported plates = %v", plates)
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"database/sql"
+
+	"anpr-service/internal/metrics"
+)
+
+// DBStatsSource — состояние пула соединений БД (реализуется *sql.DB)
+type DBStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// UseDBStats добавляет в метрики сервиса (WriteMetrics) состояние пула соединений БД
+func (s *ANPRService) UseDBStats(source DBStatsSource) {
+	s.dbStats = source
+}
+
+// writeDBPoolMetrics пишет состояние пула database/sql: те же значения, что database_pool в /health/full
+func writeDBPoolMetrics(w *metrics.Writer, stats sql.DBStats) {
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"anpr_db_pool_max_open_connections", "Maximum number of open DB connections (DB_MAX_OPEN_CONNS).", stats.MaxOpenConnections},
+		{"anpr_db_pool_open_connections", "Established DB connections, in use and idle.", stats.OpenConnections},
+		{"anpr_db_pool_in_use", "DB connections currently in use.", stats.InUse},
+		{"anpr_db_pool_idle", "Idle DB connections.", stats.Idle},
+	}
+	for _, g := range gauges {
+		w.Header(g.name, g.help, metrics.TypeGauge)
+		w.Sample(g.name, float64(g.value))
+	}
+
+	counters := []struct {
+		name, help string
+		value      float64
+	}{
+		{"anpr_db_pool_wait_count_total", "Queries that waited for a free DB connection.", float64(stats.WaitCount)},
+		{"anpr_db_pool_wait_duration_seconds_total", "Total time queries waited for a free DB connection.", stats.WaitDuration.Seconds()},
+		{"anpr_db_pool_max_idle_closed_total", "DB connections closed because of the idle pool limit.", float64(stats.MaxIdleClosed)},
+		{"anpr_db_pool_max_idle_time_closed_total", "DB connections closed after the maximum idle time.", float64(stats.MaxIdleTimeClosed)},
+		{"anpr_db_pool_max_lifetime_closed_total", "DB connections closed after the maximum lifetime.", float64(stats.MaxLifetimeClosed)},
+	}
+	for _, c := range counters {
+		w.Header(c.name, c.help, metrics.TypeCounter)
+		w.Sample(c.name, c.value)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeDBStats sql.DBStats
+
+func (f fakeDBStats) Stats() sql.DBStats { return sql.DBStats(f) }
+
+func TestWriteMetricsDBPool(t *testing.T) {
+	svc, _ := newTestService(t, sloTestConfig())
+	svc.UseDBStats(fakeDBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    12,
+		InUse:              9,
+		Idle:               3,
+		WaitCount:          42,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxLifetimeClosed:  7,
+	})
+
+	var out strings.Builder
+	if err := svc.WriteMetrics(&out); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"# TYPE anpr_db_pool_in_use gauge\n",
+		"anpr_db_pool_max_open_connections 25\n",
+		"anpr_db_pool_open_connections 12\n",
+		"anpr_db_pool_in_use 9\n",
+		"anpr_db_pool_idle 3\n",
+		"# TYPE anpr_db_pool_wait_count_total counter\n",
+		"anpr_db_pool_wait_count_total 42\n",
+		"anpr_db_pool_wait_duration_seconds_total 1.5\n",
+		"anpr_db_pool_max_lifetime_closed_total 7\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("metrics missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/repository"
+)
+
+// DeadLetterHeaders — заголовки запроса, которые сохраняются вместе с непринятым уведомлением
+var DeadLetterHeaders = []string{"Content-Type", "User-Agent", "X-Event-Source", "X-Request-ID"}
+
+// deadLetterSourceHeader — заголовок с источником события (как X-Event-Source у приёма)
+const deadLetterSourceHeader = "X-Event-Source"
+
+// DeadLetterInput — уведомление камеры, которое не удалось принять
+type DeadLetterInput struct {
+	// Endpoint — имя адаптера формата (ingest.AdapterHikvision), им уведомление разбирается при повторе
+	Endpoint    string
+	CameraID    string // camera_id из строки запроса
+	RemoteAddr  string
+	ContentType string
+	Headers     map[string]string
+	Body        []byte
+	Err         error
+}
+
+// DeadLetterInfo — непринятое уведомление без тела
+type DeadLetterInfo struct {
+	ID              uuid.UUID         `json:"id"`
+	Endpoint        string            `json:"endpoint"`
+	CameraID        string            `json:"camera_id,omitempty"`
+	RemoteAddr      string            `json:"remote_addr,omitempty"`
+	ContentType     string            `json:"content_type,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	PayloadSize     int64             `json:"payload_size"`
+	Error           string            `json:"error"`
+	ReceivedAt      time.Time         `json:"received_at"`
+	ReplayedAt      *time.Time        `json:"replayed_at,omitempty"`
+	ReplayedEventID *uuid.UUID        `json:"replayed_event_id,omitempty"`
+	ReplayError     *string           `json:"replay_error,omitempty"`
+}
+
+// DeadLetterReplayResult — результат повторной обработки уведомления
+type DeadLetterReplayResult struct {
+	DeadLetterID uuid.UUID `json:"dead_letter_id"`
+	EventID      uuid.UUID `json:"event_id"`
+	// Rejected — номер не найден в vehicles, событие сохранено в anpr_events_rejected
+	Rejected bool `json:"rejected"`
+}
+
+// SaveDeadLetter сохраняет уведомление, которое не удалось разобрать, чтобы его можно было изучить и
+// обработать повторно после исправления разбора
+func (s *ANPRService) SaveDeadLetter(ctx context.Context, input DeadLetterInput) error {
+	letter := &repository.DeadLetter{
+		Endpoint:    input.Endpoint,
+		CameraID:    input.CameraID,
+		RemoteAddr:  input.RemoteAddr,
+		ContentType: input.ContentType,
+		Payload:     input.Body,
+		ReceivedAt:  s.clock.Now(),
+	}
+	if input.Err != nil {
+		letter.Error = input.Err.Error()
+	}
+	if len(input.Headers) > 0 {
+		headers, err := json.Marshal(input.Headers)
+		if err != nil {
+			return fmt.Errorf("failed to encode dead letter headers: %w", err)
+		}
+		letter.Headers = headers
+	}
+	if letter.Payload == nil {
+		letter.Payload = []byte{}
+	}
+	if err := s.repo.CreateDeadLetter(ctx, letter); err != nil {
+		return err
+	}
+	s.logger(ctx).Warn().
+		Str("dead_letter_id", letter.ID.String()).
+		Str("endpoint", letter.Endpoint).
+		Str("remote_addr", letter.RemoteAddr).
+		Int("payload_size", len(letter.Payload)).
+		Str("error", letter.Error).
+		Msg("camera push saved to dead letters")
+	return nil
+}
+
+// ListDeadLetters возвращает непринятые уведомления, новые первыми. Доступно администратору акимата.
+func (s *ANPRService) ListDeadLetters(ctx context.Context, pendingOnly bool, limit, offset int) ([]DeadLetterInfo, error) {
+	if _, err := requirePrincipal(ctx, canReprocessEvents); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	letters, err := s.repo.ListDeadLetters(ctx, pendingOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]DeadLetterInfo, 0, len(letters))
+	for i := range letters {
+		infos = append(infos, toDeadLetterInfo(&letters[i]))
+	}
+	return infos, nil
+}
+
+// GetDeadLetterPayload возвращает тело непринятого уведомления и его Content-Type
+func (s *ANPRService) GetDeadLetterPayload(ctx context.Context, id uuid.UUID) ([]byte, string, error) {
+	if _, err := requirePrincipal(ctx, canReprocessEvents); err != nil {
+		return nil, "", err
+	}
+	letter, err := s.repo.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return letter.Payload, letter.ContentType, nil
+}
+
+// ReplayDeadLetter заново разбирает сохранённое уведомление и принимает событие так же, как приём
+// Hikvision. Ошибка повторной обработки записывается в уведомление, и его можно повторить снова.
+func (s *ANPRService) ReplayDeadLetter(ctx context.Context, id uuid.UUID) (*DeadLetterReplayResult, error) {
+	if _, err := requirePrincipal(ctx, canReprocessEvents); err != nil {
+		return nil, err
+	}
+	letter, err := s.repo.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if letter.ReplayedAt != nil {
+		return nil, fmt.Errorf("%w: dead letter was already replayed as event %s", ErrInvalidInput, letter.ReplayedEventID)
+	}
+
+	eventID := s.NewEventID()
+	result := &DeadLetterReplayResult{DeadLetterID: letter.ID, EventID: eventID}
+	replayErr := s.replayDeadLetter(ctx, letter, eventID)
+	if errors.Is(replayErr, ErrVehicleNotWhitelisted) {
+		result.Rejected = true
+		replayErr = nil
+	}
+	if replayErr != nil {
+		message := replayErr.Error()
+		if err := s.repo.MarkDeadLetterReplayed(ctx, letter.ID, s.clock.Now(), nil, &message); err != nil {
+			return nil, err
+		}
+		return nil, replayErr
+	}
+	if err := s.repo.MarkDeadLetterReplayed(ctx, letter.ID, s.clock.Now(), &eventID, nil); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("dead_letter_id", letter.ID.String()).
+		Str("event_id", eventID.String()).
+		Bool("rejected", result.Rejected).
+		Msg("dead letter replayed")
+	return result, nil
+}
+
+func (s *ANPRService) replayDeadLetter(ctx context.Context, letter *repository.DeadLetter, eventID uuid.UUID) error {
+	adapter, err := s.IngestAdapter(letter.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: unsupported dead letter endpoint %q", ErrInvalidInput, letter.Endpoint)
+	}
+	headers := decodeDeadLetterHeaders(letter.Headers)
+	req := &ingest.Request{
+		ContentType: letter.ContentType,
+		Header:      http.Header{},
+		Query:       url.Values{},
+		Body:        letter.Payload,
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if letter.CameraID != "" {
+		req.Query.Set("camera_id", letter.CameraID)
+	}
+
+	result, err := adapter.Parse(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	// Сигнал состояния имеет смысл только в момент приёма — событие из него не создаётся
+	if result.Signal != nil {
+		return fmt.Errorf("%w: notification is a %s camera signal, not a plate event", ErrInvalidInput, result.Signal.EventType)
+	}
+	payload := *result.Payload
+	// Время приёма — когда уведомление пришло на сервис, а не received_at из тела открытого маршрута
+	payload.ReceivedAt = &letter.ReceivedAt
+	if payload.EventTime.IsZero() {
+		payload.EventTime = letter.ReceivedAt
+	}
+	if payload.Source == "" {
+		payload.Source = strings.TrimSpace(headers[deadLetterSourceHeader])
+	}
+	_, err = s.ProcessIncomingEvent(ctx, payload, s.Config().Camera.Model, eventID, nil)
+	return err
+}
+
+// PurgeDeadLetters удаляет непринятые уведомления старше DEAD_LETTERS_RETENTION
+func (s *ANPRService) PurgeDeadLetters(ctx context.Context) error {
+	purged, err := s.repo.PurgeDeadLetters(ctx, s.clock.Now().Add(-s.Config().Retention.DeadLetterRetention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		s.logger(ctx).Info().Int64("purged", purged).Msg("dead letters purged")
+	}
+	return nil
+}
+
+func toDeadLetterInfo(letter *repository.DeadLetter) DeadLetterInfo {
+	return DeadLetterInfo{
+		ID:              letter.ID,
+		Endpoint:        letter.Endpoint,
+		CameraID:        letter.CameraID,
+		RemoteAddr:      letter.RemoteAddr,
+		ContentType:     letter.ContentType,
+		Headers:         decodeDeadLetterHeaders(letter.Headers),
+		PayloadSize:     letter.PayloadSize,
+		Error:           letter.Error,
+		ReceivedAt:      letter.ReceivedAt,
+		ReplayedAt:      letter.ReplayedAt,
+		ReplayedEventID: letter.ReplayedEventID,
+		ReplayError:     letter.ReplayError,
+	}
+}
+
+func decodeDeadLetterHeaders(data []byte) map[string]string {
+	headers := map[string]string{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &headers)
+	}
+	return headers
+}
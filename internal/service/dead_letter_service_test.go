@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestReplayDeadLetter(t *testing.T) {
+	id := uuid.New()
+	replayedAt := testNow.Add(-time.Hour)
+	eventID := uuid.New()
+
+	tests := []struct {
+		name       string
+		role       model.UserRole
+		letter     *repository.DeadLetter
+		getErr     error
+		wantMarked bool
+		wantErr    error
+	}{
+		{name: "missing dead letter", role: model.UserRoleAkimatAdmin, getErr: repository.ErrDeadLetterNotFound, wantErr: ErrNotFound},
+		{
+			name:    "already replayed",
+			role:    model.UserRoleAkimatAdmin,
+			letter:  &repository.DeadLetter{ID: id, Endpoint: ingest.AdapterHikvision, ReplayedAt: &replayedAt, ReplayedEventID: &eventID},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:       "still unparsable payload records replay error",
+			role:       model.UserRoleAkimatAdmin,
+			letter:     &repository.DeadLetter{ID: id, Endpoint: ingest.AdapterHikvision, ContentType: "text/plain", Payload: []byte("garbage")},
+			wantMarked: true,
+			wantErr:    ErrInvalidInput,
+		},
+		{
+			name:       "camera signal is not an event",
+			role:       model.UserRoleAkimatAdmin,
+			letter:     &repository.DeadLetter{ID: id, Endpoint: ingest.AdapterHikvision, ContentType: "application/xml", Payload: []byte(`<EventNotificationAlert><eventType>videoloss</eventType><channelID>1</channelID></EventNotificationAlert>`)},
+			wantMarked: true,
+			wantErr:    ErrInvalidInput,
+		},
+		{name: "kgu cannot replay", role: model.UserRoleKguZkhAdmin, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: tt.role})
+			if tt.role == model.UserRoleAkimatAdmin {
+				store.EXPECT().GetDeadLetter(gomock.Any(), id).Return(tt.letter, tt.getErr)
+			}
+			if tt.wantMarked {
+				store.EXPECT().MarkDeadLetterReplayed(gomock.Any(), id, testNow, nil, gomock.Not(gomock.Nil())).Return(nil)
+			}
+
+			_, err := svc.ReplayDeadLetter(ctx, id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplayDeadLetter() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
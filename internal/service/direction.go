@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// Режимы вывода направления камеры, если она его не присылает
+const (
+	// DirectionInferenceLane — направление по номеру полосы (lane_directions камеры)
+	DirectionInferenceLane = "lane"
+	// DirectionInferenceAlternate — чередование: после въезда номера на полигон — выезд, и наоборот
+	DirectionInferenceAlternate = "alternate"
+)
+
+// LaneDirections — направления проезда по номерам полос камеры
+type LaneDirections map[int]string
+
+// ParseLaneDirections разбирает направления полос вида "1:entry,2:exit"
+func ParseLaneDirections(value string) (LaneDirections, error) {
+	lanes := LaneDirections{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		laneStr, direction, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid lane direction %q, expected LANE:entry|exit", part)
+		}
+		lane, err := strconv.Atoi(strings.TrimSpace(laneStr))
+		if err != nil || lane <= 0 {
+			return nil, fmt.Errorf("invalid lane number in %q", part)
+		}
+		direction = strings.ToLower(strings.TrimSpace(direction))
+		if !isDirection(direction) {
+			return nil, fmt.Errorf("invalid direction in %q, expected entry or exit", part)
+		}
+		lanes[lane] = direction
+	}
+	return lanes, nil
+}
+
+func isDirection(value string) bool {
+	return value == anpr.DirectionEntry || value == anpr.DirectionExit
+}
+
+// resolveDirection определяет направление события и его источник (anpr.DirectionSource*). Направление
+// камеры берётся как есть; если его нет, оно выводится по настройкам камеры: режимом direction_inference,
+// затем default_direction. Без настроек событие считается въездом, как и раньше.
+func (s *ANPRService) resolveDirection(ctx context.Context, camera *repository.Camera, payload *anpr.EventPayload, normalizedPlate string) (string, string) {
+	reported := strings.ToLower(strings.TrimSpace(payload.Direction))
+	if reported != "" && reported != "unknown" {
+		return reported, anpr.DirectionSourceReported
+	}
+	if camera == nil {
+		return anpr.DirectionEntry, anpr.DirectionSourceFallback
+	}
+
+	switch stringOrEmpty(camera.DirectionInference) {
+	case DirectionInferenceLane:
+		if camera.LaneDirections != nil && payload.Lane > 0 {
+			lanes, err := ParseLaneDirections(*camera.LaneDirections)
+			if err != nil {
+				s.logger(ctx).Warn().Err(err).Str("camera_id", camera.ID).Msg("invalid camera lane directions, skipping lane inference")
+			} else if direction, ok := lanes[payload.Lane]; ok {
+				return direction, anpr.DirectionSourceLane
+			}
+		}
+	case DirectionInferenceAlternate:
+		if direction, ok := s.alternateDirection(ctx, camera, payload, normalizedPlate); ok {
+			return direction, anpr.DirectionSourceSequence
+		}
+	}
+
+	if camera.DefaultDirection != nil && isDirection(*camera.DefaultDirection) {
+		return *camera.DefaultDirection, anpr.DirectionSourceCameraDefault
+	}
+	return anpr.DirectionEntry, anpr.DirectionSourceFallback
+}
+
+// alternateDirection выводит направление по предыдущему проезду номера на том же полигоне (или той же
+// камере, если она не привязана к полигону) в пределах tripPassWindow: после въезда — выезд, иначе въезд
+func (s *ANPRService) alternateDirection(ctx context.Context, camera *repository.Camera, payload *anpr.EventPayload, normalizedPlate string) (string, bool) {
+	previous, err := s.repo.FindEventsByPlateAndTime(ctx, normalizedPlate, payload.EventTime.Add(-tripPassWindow), payload.EventTime, nil)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("camera_id", camera.ID).Msg("failed to load previous passes, skipping direction inference")
+		return "", false
+	}
+	// События упорядочены по времени: ищем последний проезд с направлением
+	for i := len(previous) - 1; i >= 0; i-- {
+		event := previous[i]
+		if camera.PolygonID != nil {
+			if !samePolygon(event.PolygonID, camera.PolygonID) {
+				continue
+			}
+		} else if event.CameraID != camera.ID {
+			continue
+		}
+		if stringOrEmpty(event.Direction) == anpr.DirectionEntry {
+			return anpr.DirectionExit, true
+		}
+		if stringOrEmpty(event.Direction) == anpr.DirectionExit {
+			return anpr.DirectionEntry, true
+		}
+	}
+	return anpr.DirectionEntry, true
+}
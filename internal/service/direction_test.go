@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func TestResolveDirection(t *testing.T) {
+	polygonID := uuid.New()
+	otherPolygon := uuid.New()
+	entry, exit := anpr.DirectionEntry, anpr.DirectionExit
+	lane, alternate := DirectionInferenceLane, DirectionInferenceAlternate
+	lanes := "1:entry, 2:exit"
+
+	tests := []struct {
+		name       string
+		camera     *repository.Camera
+		direction  string
+		lane       int
+		previous   []repository.ANPREvent
+		wantDir    string
+		wantSource string
+	}{
+		{name: "reported by camera", camera: &repository.Camera{ID: "cam-1", DefaultDirection: &exit}, direction: "EXIT", wantDir: exit, wantSource: anpr.DirectionSourceReported},
+		{name: "unregistered camera falls back to entry", direction: "unknown", wantDir: entry, wantSource: anpr.DirectionSourceFallback},
+		{name: "camera default", camera: &repository.Camera{ID: "cam-1", DefaultDirection: &exit}, wantDir: exit, wantSource: anpr.DirectionSourceCameraDefault},
+		{name: "lane mapping", camera: &repository.Camera{ID: "cam-1", DirectionInference: &lane, LaneDirections: &lanes}, lane: 2, wantDir: exit, wantSource: anpr.DirectionSourceLane},
+		{name: "unmapped lane uses camera default", camera: &repository.Camera{ID: "cam-1", DirectionInference: &lane, LaneDirections: &lanes, DefaultDirection: &exit}, lane: 3, wantDir: exit, wantSource: anpr.DirectionSourceCameraDefault},
+		{
+			name:   "alternate after entry on the polygon",
+			camera: &repository.Camera{ID: "cam-1", PolygonID: &polygonID, DirectionInference: &alternate},
+			previous: []repository.ANPREvent{
+				{CameraID: "cam-2", PolygonID: &polygonID, Direction: &entry},
+				{CameraID: "cam-1", PolygonID: &otherPolygon, Direction: &exit},
+			},
+			wantDir: exit, wantSource: anpr.DirectionSourceSequence,
+		},
+		{
+			name:     "alternate after exit",
+			camera:   &repository.Camera{ID: "cam-1", DirectionInference: &alternate},
+			previous: []repository.ANPREvent{{CameraID: "cam-1", Direction: &entry}, {CameraID: "cam-1", Direction: &exit}},
+			wantDir:  entry, wantSource: anpr.DirectionSourceSequence,
+		},
+		{name: "alternate without previous passes", camera: &repository.Camera{ID: "cam-1", DirectionInference: &alternate}, wantDir: entry, wantSource: anpr.DirectionSourceSequence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			payload := &anpr.EventPayload{CameraID: "cam-1", Direction: tt.direction, Lane: tt.lane, EventTime: testNow}
+			if tt.camera != nil && tt.camera.DirectionInference != nil && *tt.camera.DirectionInference == alternate {
+				store.EXPECT().FindEventsByPlateAndTime(gomock.Any(), "123ABC02", testNow.Add(-tripPassWindow), testNow, nil).Return(tt.previous, nil)
+			}
+
+			gotDir, gotSource := svc.resolveDirection(context.Background(), tt.camera, payload, "123ABC02")
+			if gotDir != tt.wantDir || gotSource != tt.wantSource {
+				t.Fatalf("resolveDirection() = %s (%s), want %s (%s)", gotDir, gotSource, tt.wantDir, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestParseLaneDirectionsInvalid(t *testing.T) {
+	for _, value := range []string{"1", "0:entry", "x:exit", "1:left"} {
+		if _, err := ParseLaneDirections(value); err == nil {
+			t.Errorf("ParseLaneDirections(%q) error = nil, want error", value)
+		}
+	}
+}
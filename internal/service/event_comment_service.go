@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// eventCommentMaxLength — предельная длина комментария в символах
+const eventCommentMaxLength = 2000
+
+// EventCommentInfo — комментарий оператора к событию
+type EventCommentInfo struct {
+	ID         string    `json:"id"`
+	EventID    string    `json:"event_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorRole string    `json:"author_role"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AddEventComment добавляет комментарий к событию от имени пользователя запроса
+func (s *ANPRService) AddEventComment(ctx context.Context, eventID uuid.UUID, body string) (*EventCommentInfo, error) {
+	principal, err := requirePrincipal(ctx, canCommentEvents)
+	if err != nil {
+		return nil, err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment body is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(body) > eventCommentMaxLength {
+		return nil, fmt.Errorf("%w: comment must be at most %d characters", ErrInvalidInput, eventCommentMaxLength)
+	}
+
+	if _, err := s.repo.GetEventByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	comment := &repository.EventComment{
+		EventID:    eventID,
+		AuthorID:   principal.UserID,
+		AuthorRole: string(principal.Role),
+		Body:       body,
+		CreatedAt:  s.clock.Now(),
+	}
+	if err := s.repo.CreateEventComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("event_id", eventID.String()).
+		Str("user_id", principal.UserID.String()).
+		Msg("event comment added")
+	info := toEventCommentInfo(*comment)
+	return &info, nil
+}
+
+// ListEventComments возвращает комментарии к событию в порядке добавления
+func (s *ANPRService) ListEventComments(ctx context.Context, eventID uuid.UUID) ([]EventCommentInfo, error) {
+	if _, err := s.repo.GetEventByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.ListEventComments(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return toEventCommentInfos(comments), nil
+}
+
+func toEventCommentInfos(comments []repository.EventComment) []EventCommentInfo {
+	infos := make([]EventCommentInfo, 0, len(comments))
+	for _, comment := range comments {
+		infos = append(infos, toEventCommentInfo(comment))
+	}
+	return infos
+}
+
+func toEventCommentInfo(comment repository.EventComment) EventCommentInfo {
+	return EventCommentInfo{
+		ID:         comment.ID.String(),
+		EventID:    comment.EventID.String(),
+		AuthorID:   comment.AuthorID.String(),
+		AuthorRole: comment.AuthorRole,
+		Body:       comment.Body,
+		CreatedAt:  comment.CreatedAt,
+	}
+}
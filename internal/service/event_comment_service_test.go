@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestAddEventComment(t *testing.T) {
+	eventID := uuid.New()
+	userID := uuid.New()
+	tests := []struct {
+		name    string
+		role    model.UserRole
+		body    string
+		missing bool
+		wantErr error
+	}{
+		{name: "operator comment", role: model.UserRoleKguZkhUser, body: "  машина была полупустой, объём скорректирован вручную "},
+		{name: "landfill user comment", role: model.UserRoleLandfillUser, body: "шлагбаум открыт вручную"},
+		{name: "empty body", role: model.UserRoleAkimatUser, body: "   ", wantErr: ErrInvalidInput},
+		{name: "too long", role: model.UserRoleAkimatUser, body: strings.Repeat("я", eventCommentMaxLength+1), wantErr: ErrInvalidInput},
+		{name: "event not found", role: model.UserRoleAkimatUser, body: "проверить", missing: true, wantErr: ErrNotFound},
+		{name: "driver cannot comment", role: model.UserRoleDriver, body: "проверить", wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: userID, Role: tt.role})
+
+			var saved *repository.EventComment
+			if !errors.Is(tt.wantErr, ErrForbidden) && !errors.Is(tt.wantErr, ErrInvalidInput) {
+				if tt.missing {
+					store.EXPECT().GetEventByID(gomock.Any(), eventID).Return(nil, fmt.Errorf("%w: %s", repository.ErrEventNotFound, eventID))
+				} else {
+					store.EXPECT().GetEventByID(gomock.Any(), eventID).Return(&repository.ANPREvent{ID: eventID}, nil)
+				}
+			}
+			if tt.wantErr == nil {
+				store.EXPECT().CreateEventComment(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, comment *repository.EventComment) error {
+						saved = comment
+						return nil
+					})
+			}
+
+			got, err := svc.AddEventComment(ctx, eventID, tt.body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AddEventComment() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddEventComment() error = %v", err)
+			}
+			if saved.AuthorID != userID || saved.AuthorRole != string(tt.role) {
+				t.Errorf("author = %s/%s, want %s/%s", saved.AuthorID, saved.AuthorRole, userID, tt.role)
+			}
+			if got.Body != strings.TrimSpace(tt.body) || !saved.CreatedAt.Equal(testNow) {
+				t.Errorf("comment = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// eventStreamMaxPeriod — наибольший период потоковой выгрузки событий
+const eventStreamMaxPeriod = 31 * 24 * time.Hour
+
+// EventFilters — фильтры событий, как query-параметры /api/v1/events (сохранённые поиски, потоковая выгрузка)
+type EventFilters struct {
+	Plate       string `json:"plate,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+	VehicleType string `json:"vehicle_type,omitempty"`
+	Source      string `json:"source,omitempty"`
+	PolygonID   string `json:"polygon_id,omitempty"`
+	TimeField   string `json:"time_field,omitempty"`
+}
+
+// eventFiltersSearch проверяет фильтры так же, как FindEvents, и превращает их в параметры выборки.
+// polygonOrgID ограничивает выборку полигонами организации.
+func eventFiltersSearch(filters EventFilters, polygonOrgID *uuid.UUID) (repository.EventSearch, error) {
+	query := repository.EventSearch{TimeField: filters.TimeField, PolygonOrgID: polygonOrgID}
+	if query.TimeField == "" {
+		query.TimeField = repository.EventTimeFieldEventTime
+	}
+	if query.TimeField != repository.EventTimeFieldEventTime && query.TimeField != repository.EventTimeFieldReceivedAt {
+		return query, fmt.Errorf("%w: time_field must be 'event_time' or 'received_at'", ErrInvalidInput)
+	}
+	if plate := utils.NormalizePlate(filters.Plate); plate != "" {
+		query.NormalizedPlate = &plate
+	}
+	if filters.Direction != "" {
+		direction := strings.ToLower(filters.Direction)
+		if direction != "entry" && direction != "exit" {
+			return query, fmt.Errorf("%w: direction must be 'entry' or 'exit'", ErrInvalidInput)
+		}
+		query.Direction = &direction
+	}
+	if filters.VehicleType != "" {
+		vehicleType, err := ParseVehicleTypeFilter(filters.VehicleType)
+		if err != nil {
+			return query, err
+		}
+		query.VehicleType = &vehicleType
+	}
+	sources, err := ParseEventSourceFilter(filters.Source)
+	if err != nil {
+		return query, err
+	}
+	query.Sources = sources
+	if filters.PolygonID != "" {
+		polygonID, err := uuid.Parse(filters.PolygonID)
+		if err != nil {
+			return query, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		query.PolygonID = &polygonID
+	}
+	return query, nil
+}
+
+// EventStreamQuery — параметры потоковой выгрузки событий: период [From, To) по TimeField обязателен
+type EventStreamQuery struct {
+	From string // RFC 3339
+	To   string // RFC 3339
+	EventFilters
+}
+
+// StreamEvents передаёт emit события за период по возрастанию времени в виде строк выгрузки для аналитиков
+// (DataLakeEvent: без фото и исходного payload). Параметры проверяются до первой строки, поэтому ошибка без
+// вызовов emit — ошибка запроса. События читаются из курсора по мере того, как emit их принимает: медленный
+// потребитель притормаживает чтение, и выгрузка за ночь не загружается в память. Пользователи полигона
+// получают только события полигонов своей организации.
+func (s *ANPRService) StreamEvents(ctx context.Context, query EventStreamQuery, emit func(DataLakeEvent) error) error {
+	principal, err := requirePrincipal(ctx, canStreamEvents)
+	if err != nil {
+		return err
+	}
+	from, err := time.Parse(time.RFC3339, strings.TrimSpace(query.From))
+	if err != nil {
+		return fmt.Errorf("%w: from is required, use RFC3339", ErrInvalidInput)
+	}
+	to, err := time.Parse(time.RFC3339, strings.TrimSpace(query.To))
+	if err != nil {
+		return fmt.Errorf("%w: to is required, use RFC3339", ErrInvalidInput)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: to time must be after from time", ErrInvalidInput)
+	}
+	if to.Sub(from) > eventStreamMaxPeriod {
+		return fmt.Errorf("%w: period must not exceed %d days", ErrInvalidInput, int(eventStreamMaxPeriod.Hours()/24))
+	}
+
+	var polygonOrgID *uuid.UUID
+	if principal.IsLandfill() {
+		polygonOrgID = &principal.OrgID
+	}
+	search, err := eventFiltersSearch(query.EventFilters, polygonOrgID)
+	if err != nil {
+		return err
+	}
+	// Конец периода не включается, чтобы соседние периоды не давали одно событие дважды
+	// (время в PostgreSQL хранится с точностью до микросекунды)
+	end := to.Add(-time.Microsecond)
+	search.From, search.To = &from, &end
+
+	rows := 0
+	err = s.repo.StreamEvents(ctx, search, func(event *repository.ANPREvent) error {
+		rows++
+		return emit(toDataLakeEvent(event))
+	})
+	if err != nil {
+		return err
+	}
+	s.logger(ctx).Info().
+		Str("user_id", principal.UserID.String()).
+		Time("from", from).
+		Time("to", to).
+		Int("rows", rows).
+		Msg("events streamed")
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestStreamEvents(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	orgID := uuid.New()
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleLandfillUser, OrgID: orgID})
+	from := time.Date(2025, 1, 14, 20, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 15, 4, 0, 0, 0, time.UTC)
+
+	events := []repository.ANPREvent{
+		{ID: uuid.New(), CameraID: "gate-1", NormalizedPlate: "123ABC02", EventTime: from.Add(time.Hour)},
+		{ID: uuid.New(), CameraID: "gate-1", NormalizedPlate: "777AAA02", EventTime: from.Add(2 * time.Hour)},
+	}
+	store.EXPECT().StreamEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, search repository.EventSearch, fn func(*repository.ANPREvent) error) error {
+			if search.PolygonOrgID == nil || *search.PolygonOrgID != orgID || *search.Direction != "entry" {
+				t.Fatalf("unexpected search: %+v", search)
+			}
+			if !search.From.Equal(from) || !search.To.Before(to) || to.Sub(*search.To) > time.Millisecond {
+				t.Fatalf("period = [%v, %v], want [%v, %v)", search.From, search.To, from, to)
+			}
+			for i := range events {
+				if err := fn(&events[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	var plates []string
+	query := EventStreamQuery{From: from.Format(time.RFC3339), To: to.Format(time.RFC3339), EventFilters: EventFilters{Direction: "entry"}}
+	err := svc.StreamEvents(ctx, query, func(event DataLakeEvent) error {
+		plates = append(plates, event.Plate)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamEvents() error = %v", err)
+	}
+	if len(plates) != 2 || plates[0] != "123ABC02" {
+		t.Fatalf("streamed plates = %v", plates)
+	}
+}
+
+func TestStreamEventsInvalid(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	contractor := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleContractorAdmin})
+	night := EventStreamQuery{From: "2025-01-14T20:00:00Z", To: "2025-01-15T04:00:00Z"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		query   EventStreamQuery
+		wantErr error
+	}{
+		{name: "contractor", ctx: contractor, query: night, wantErr: ErrForbidden},
+		{name: "missing period", ctx: admin, query: EventStreamQuery{}, wantErr: ErrInvalidInput},
+		{name: "reversed period", ctx: admin, query: EventStreamQuery{From: night.To, To: night.From}, wantErr: ErrInvalidInput},
+		{name: "period too long", ctx: admin, query: EventStreamQuery{From: "2024-01-01T00:00:00Z", To: "2025-01-01T00:00:00Z"}, wantErr: ErrInvalidInput},
+		{name: "bad source", ctx: admin, query: EventStreamQuery{From: night.From, To: night.To, EventFilters: EventFilters{Source: "fax"}}, wantErr: ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, nil)
+			err := svc.StreamEvents(tt.ctx, tt.query, func(DataLakeEvent) error { return nil })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("StreamEvents() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
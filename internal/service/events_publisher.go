@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/eventbus"
+)
+
+// EventCreatedMessage — сообщение топика eventbus.TopicEventCreated
+type EventCreatedMessage struct {
+	EventID       uuid.UUID  `json:"event_id"`
+	PlateID       uuid.UUID  `json:"plate_id"`
+	Plate         string     `json:"plate"`
+	RawPlate      string     `json:"raw_plate"`
+	CameraID      string     `json:"camera_id"`
+	Direction     string     `json:"direction"`
+	EventTime     time.Time  `json:"event_time"`
+	ReceivedAt    *time.Time `json:"received_at,omitempty"`
+	Source        string     `json:"source"`
+	PolygonID     *uuid.UUID `json:"polygon_id,omitempty"`
+	ContractorID  *uuid.UUID `json:"contractor_id,omitempty"`
+	VehicleExists bool       `json:"vehicle_exists"`
+	SnowVolumeM3  *float64   `json:"snow_volume_m3,omitempty"`
+	// SnowVolumePercentage — заполнение кузова снегом по оценке камеры, %
+	SnowVolumePercentage *float64 `json:"snow_volume_percentage,omitempty"`
+	MatchedSnow          bool     `json:"matched_snow"`
+	EventTimeSkewed      bool     `json:"event_time_skewed,omitempty"`
+	OutOfSchedule        bool     `json:"out_of_schedule,omitempty"`
+	// Late — событие пришло с опозданием: подписчикам, реагирующим на проезд в реальном времени, его стоит пропустить
+	Late             bool                 `json:"late,omitempty"`
+	WrongDestination bool                 `json:"wrong_destination,omitempty"`
+	OverQuota        bool                 `json:"over_quota,omitempty"`
+	Decision         *anpr.AccessDecision `json:"decision,omitempty"`
+	Photos           []string             `json:"photos,omitempty"`
+	// Lists — списки, в которых состоит номер на момент события
+	Lists []anpr.ListHit `json:"lists,omitempty"`
+}
+
+// publishEventCreated публикует сохранённое событие в шину. Ошибка публикации не прерывает приём:
+// событие уже сохранено в БД, а подписчики могут догрузить пропущенное из API.
+func (s *ANPRService) publishEventCreated(ctx context.Context, event *anpr.Event, contractorID, polygonID *uuid.UUID, vehicleExists bool, photoURLs []string, lists []anpr.ListHit) {
+	if s.bus == nil {
+		return
+	}
+
+	msg := EventCreatedMessage{
+		EventID:              event.ID,
+		PlateID:              event.PlateID,
+		Plate:                event.NormalizedPlate,
+		RawPlate:             event.Plate,
+		CameraID:             event.CameraID,
+		Direction:            event.Direction,
+		EventTime:            event.EventTime,
+		ReceivedAt:           event.ReceivedAt,
+		Source:               event.Source,
+		PolygonID:            polygonID,
+		ContractorID:         contractorID,
+		VehicleExists:        vehicleExists,
+		SnowVolumeM3:         event.SnowVolumeM3,
+		SnowVolumePercentage: event.SnowVolumePercentage,
+		MatchedSnow:          event.MatchedSnow,
+		EventTimeSkewed:      event.EventTimeSkewed,
+		OutOfSchedule:        event.OutOfSchedule,
+		Late:                 event.Late,
+		WrongDestination:     event.WrongDestination,
+		OverQuota:            event.OverQuota,
+		Decision:             event.Decision,
+		Photos:               photoURLs,
+		Lists:                lists,
+	}
+	if err := s.bus.Publish(ctx, eventbus.TopicEventCreated, msg); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Msg("failed to publish event to event bus")
+	}
+}
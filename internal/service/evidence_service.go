@@ -0,0 +1,298 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/ingest/hikvisionpush"
+	"anpr-service/internal/repository"
+)
+
+// tripPassWindow — в каком окне от проезда рейса ищется встречный проезд (выезд после въезда или
+// въезд перед выездом)
+const tripPassWindow = 12 * time.Hour
+
+// evidencePhotoMaxBytes — предельный размер фото, который попадает в пакет
+const evidencePhotoMaxBytes = 20 << 20
+
+// TripEvidence — ZIP-пакет доказательств рейса
+type TripEvidence struct {
+	Body []byte
+	// Cached — пакет отдан из хранилища, а не собран заново
+	Cached bool
+}
+
+// TripEvidenceManifest — оглавление пакета (manifest.json)
+type TripEvidenceManifest struct {
+	TripID      string                `json:"trip_id"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Passes      []TripEvidencePass    `json:"passes"`
+	Missing     []TripEvidenceMissing `json:"missing,omitempty"`
+}
+
+// TripEvidencePass — проезд рейса в пакете и его файлы
+type TripEvidencePass struct {
+	Direction string    `json:"direction"`
+	EventID   string    `json:"event_id"`
+	EventTime time.Time `json:"event_time"`
+	Files     []string  `json:"files"`
+}
+
+// TripEvidenceMissing — что не удалось положить в пакет
+type TripEvidenceMissing struct {
+	EventID string `json:"event_id,omitempty"`
+	Item    string `json:"item"`
+	Reason  string `json:"reason"`
+}
+
+// tripEvidenceKey — ключ собранного пакета в хранилище
+func tripEvidenceKey(tripID uuid.UUID) string {
+	return "evidence/trips/" + tripID.String() + ".zip"
+}
+
+// GetTripEvidence возвращает ZIP с карточками событий, фото и исходным уведомлением камеры для обоих
+// проездов рейса (въезд и выезд). Пакет собирается по запросу; полный пакет (найдены оба проезда)
+// сохраняется в хранилище и при следующих запросах отдаётся оттуда.
+func (s *ANPRService) GetTripEvidence(ctx context.Context, tripID uuid.UUID) (*TripEvidence, error) {
+	if _, err := requirePrincipal(ctx, canViewEvidence); err != nil {
+		return nil, err
+	}
+	event, err := s.repo.GetEventByID(ctx, tripID)
+	if errors.Is(err, ErrEventNotFound) || (err == nil && !isTripEvent(event)) {
+		return nil, fmt.Errorf("%w: trip not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip event: %w", err)
+	}
+
+	key := tripEvidenceKey(tripID)
+	if cached := s.cachedTripEvidence(ctx, key); cached != nil {
+		return &TripEvidence{Body: cached, Cached: true}, nil
+	}
+
+	passes := []repository.ANPREvent{*event}
+	other, err := s.findOtherPass(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if other != nil {
+		passes = append(passes, *other)
+	}
+
+	body, err := s.buildTripEvidence(ctx, tripID, passes)
+	if err != nil {
+		return nil, err
+	}
+	if other != nil && s.archive != nil {
+		if _, err := s.archive.Upload(ctx, key, bytes.NewReader(body), int64(len(body)), "application/zip"); err != nil {
+			s.logger(ctx).Warn().Err(err).Str("trip_id", tripID.String()).Msg("failed to cache trip evidence")
+		}
+	}
+	s.logger(ctx).Info().
+		Str("trip_id", tripID.String()).
+		Int("passes", len(passes)).
+		Int("size", len(body)).
+		Msg("trip evidence generated")
+	return &TripEvidence{Body: body}, nil
+}
+
+// isTripEvent сообщает, что событие учитывается как рейс (те же условия, что у tripID)
+func isTripEvent(event *repository.ANPREvent) bool {
+	return !event.OutOfSchedule && !event.Late && !event.OverQuota && event.SnowVolumeM3 != nil && *event.SnowVolumeM3 > 0
+}
+
+// cachedTripEvidence читает сохранённый пакет; nil — пакета нет или хранилище недоступно
+func (s *ANPRService) cachedTripEvidence(ctx context.Context, key string) []byte {
+	if s.archive == nil {
+		return nil
+	}
+	body, _, _, err := s.archive.Open(ctx, key)
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("key", key).Msg("failed to read cached trip evidence")
+		return nil
+	}
+	return data
+}
+
+// findOtherPass находит встречный проезд того же номера на том же полигоне: ближайший выезд после въезда
+// рейса или ближайший въезд перед выездом. nil — проезд не найден.
+func (s *ANPRService) findOtherPass(ctx context.Context, event *repository.ANPREvent) (*repository.ANPREvent, error) {
+	direction := normalizeEventDirection(stringOrEmpty(event.Direction))
+	want, from, to := "exit", event.EventTime, event.EventTime.Add(tripPassWindow)
+	if direction == "exit" {
+		want, from, to = "entry", event.EventTime.Add(-tripPassWindow), event.EventTime
+	}
+	candidates, err := s.repo.FindEventsByPlateAndTime(ctx, event.NormalizedPlate, from, to, &want)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trip passes: %w", err)
+	}
+
+	var found *repository.ANPREvent
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.ID == event.ID || !samePolygon(candidate.PolygonID, event.PolygonID) {
+			continue
+		}
+		// Кандидаты упорядочены по времени: выезд — первый после въезда, въезд — последний перед выездом
+		found = candidate
+		if want == "exit" {
+			break
+		}
+	}
+	return found, nil
+}
+
+func samePolygon(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// buildTripEvidence собирает ZIP: для каждого проезда папка {direction}/ с event.json, фото и
+// camera.xml (или camera.json / raw_payload.json), в корне manifest.json
+func (s *ANPRService) buildTripEvidence(ctx context.Context, tripID uuid.UUID, passes []repository.ANPREvent) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	manifest := TripEvidenceManifest{TripID: tripID.String(), GeneratedAt: s.clock.Now().UTC()}
+
+	for _, pass := range passes {
+		info, err := s.GetEventByID(ctx, pass.ID)
+		if err != nil {
+			return nil, err
+		}
+		dir := normalizeEventDirection(stringOrEmpty(pass.Direction))
+		entry := TripEvidencePass{Direction: dir, EventID: pass.ID.String(), EventTime: pass.EventTime}
+		add := func(name string, data []byte) error {
+			path := dir + "/" + name
+			w, err := archive.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: pass.EventTime})
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			entry.Files = append(entry.Files, path)
+			return nil
+		}
+
+		eventJSON, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode trip event: %w", err)
+		}
+		if err := add("event.json", eventJSON); err != nil {
+			return nil, fmt.Errorf("write trip evidence: %w", err)
+		}
+
+		for i, photoURL := range info.Photos {
+			data, err := s.evidencePhoto(ctx, photoURL)
+			if err != nil {
+				manifest.Missing = append(manifest.Missing, TripEvidenceMissing{EventID: entry.EventID, Item: photoURL, Reason: err.Error()})
+				continue
+			}
+			if err := add(fmt.Sprintf("photo-%d%s", i+1, photoExt(photoURL)), data); err != nil {
+				return nil, fmt.Errorf("write trip evidence: %w", err)
+			}
+		}
+
+		name, notification := cameraNotification(info.RawPayload)
+		if notification == nil {
+			manifest.Missing = append(manifest.Missing, TripEvidenceMissing{EventID: entry.EventID, Item: "camera notification", Reason: "raw payload is not stored"})
+		} else if err := add(name, notification); err != nil {
+			return nil, fmt.Errorf("write trip evidence: %w", err)
+		}
+		manifest.Passes = append(manifest.Passes, entry)
+	}
+	if len(passes) < 2 {
+		manifest.Missing = append(manifest.Missing, TripEvidenceMissing{Item: "opposite pass", Reason: "no matching pass found"})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode trip evidence manifest: %w", err)
+	}
+	w, err := archive.Create("manifest.json")
+	if err == nil {
+		_, err = w.Write(manifestJSON)
+	}
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("write trip evidence: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// evidencePhoto читает фото события из хранилища по его ссылке
+func (s *ANPRService) evidencePhoto(ctx context.Context, photoURL string) ([]byte, error) {
+	key, ok := photoObjectKey(photoURL)
+	if !ok {
+		return nil, errors.New("photo is not in the photo storage")
+	}
+	if s.archive == nil {
+		return nil, errors.New("photo storage is not configured")
+	}
+	body, _, _, err := s.archive.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(io.LimitReader(body, evidencePhotoMaxBytes))
+}
+
+// photoObjectKey выделяет ключ объекта из ссылки на фото события (anpr_events/...), независимо от
+// того, какое хранилище ссылку выдало
+func photoObjectKey(photoURL string) (string, bool) {
+	path := photoURL
+	if parsed, err := url.Parse(photoURL); err == nil {
+		path = parsed.Path
+	}
+	idx := strings.Index(path, "anpr_events/")
+	if idx < 0 {
+		return "", false
+	}
+	return path[idx:], true
+}
+
+// photoExt — расширение файла фото по ссылке (.jpg по умолчанию)
+func photoExt(photoURL string) string {
+	key, _ := photoObjectKey(photoURL)
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		return key[dot:]
+	}
+	return ".jpg"
+}
+
+// cameraNotification возвращает исходное уведомление камеры из payload события: XML Hikvision, JSON
+// Hikvision или, если формат другой, весь payload
+func cameraNotification(payload json.RawMessage) (string, []byte) {
+	if len(payload) == 0 {
+		return "", nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err == nil {
+		if text, _ := raw["xml"].(string); strings.TrimSpace(text) != "" {
+			return "camera.xml", []byte(text)
+		}
+		if text, _ := raw[hikvisionpush.RawJSONKey].(string); strings.TrimSpace(text) != "" {
+			return "camera.json", []byte(text)
+		}
+	}
+	return "raw_payload.json", payload
+}
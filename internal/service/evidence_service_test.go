@@ -0,0 +1,122 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestGetTripEvidence(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	archive := &fakeArchive{objects: map[string][]byte{}}
+	svc.UsePayloadArchive(archive)
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhUser})
+
+	polygonID := uuid.New()
+	volume := 12.5
+	entryDir, exitDir := "entry", "exit"
+	entry := &repository.ANPREvent{
+		ID: uuid.New(), CameraID: "gate-in", PolygonID: &polygonID, Direction: &entryDir, NormalizedPlate: "123ABC02",
+		EventTime: testNow.Add(-2 * time.Hour), SnowVolumeM3: &volume, RawPayload: datatypes.JSON(`{"xml":"<EventNotificationAlert/>"}`),
+	}
+	exit := repository.ANPREvent{
+		ID: uuid.New(), CameraID: "gate-out", PolygonID: &polygonID, Direction: &exitDir, NormalizedPlate: "123ABC02",
+		EventTime: testNow.Add(-90 * time.Minute), RawPayload: datatypes.JSON(`{"plate":"123ABC02"}`),
+	}
+	otherPolygon := uuid.New()
+	elsewhere := repository.ANPREvent{ID: uuid.New(), PolygonID: &otherPolygon, Direction: &exitDir, EventTime: testNow.Add(-100 * time.Minute)}
+
+	photoKey := "anpr_events/2025-01-16/gate-in/01-00-00-123ABC02/" + entry.ID.String() + "-photo-0.jpg"
+	archive.objects[photoKey] = []byte("jpeg")
+
+	store.EXPECT().GetEventByID(gomock.Any(), entry.ID).Return(entry, nil).AnyTimes()
+	store.EXPECT().GetEventByID(gomock.Any(), exit.ID).Return(&exit, nil).AnyTimes()
+	store.EXPECT().FindEventsByPlateAndTime(gomock.Any(), "123ABC02", entry.EventTime, entry.EventTime.Add(tripPassWindow), &exitDir).
+		Return([]repository.ANPREvent{elsewhere, exit}, nil)
+	store.EXPECT().GetEventPhotos(gomock.Any(), entry.ID).Return([]repository.EventPhoto{
+		{PhotoURL: "https://cdn.example.com/" + photoKey},
+		{PhotoURL: "https://camera.example.com/snapshot.jpg"},
+	}, nil)
+	store.EXPECT().GetEventPhotos(gomock.Any(), exit.ID).Return(nil, nil)
+	store.EXPECT().ListEventComments(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	store.EXPECT().GetDriverByVehiclePlate(gomock.Any(), "123ABC02").Return(nil, nil).Times(2)
+	store.EXPECT().GetContractorByVehiclePlate(gomock.Any(), "123ABC02").Return(nil, nil).Times(2)
+
+	evidence, err := svc.GetTripEvidence(ctx, entry.ID)
+	if err != nil || evidence.Cached {
+		t.Fatalf("GetTripEvidence() = %+v, %v", evidence, err)
+	}
+	files := unzipEvidence(t, evidence.Body)
+	for _, name := range []string{"manifest.json", "entry/event.json", "entry/photo-1.jpg", "entry/camera.xml", "exit/event.json", "exit/raw_payload.json"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("evidence has no %s: %v", name, evidenceFileNames(files))
+		}
+	}
+	if string(files["entry/camera.xml"]) != "<EventNotificationAlert/>" || string(files["entry/photo-1.jpg"]) != "jpeg" {
+		t.Fatalf("unexpected evidence content: %q %q", files["entry/camera.xml"], files["entry/photo-1.jpg"])
+	}
+	var manifest TripEvidenceManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if len(manifest.Passes) != 2 || manifest.Passes[1].EventID != exit.ID.String() || len(manifest.Missing) != 1 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	// Полный пакет сохранён и отдаётся из хранилища без повторной сборки
+	if _, ok := archive.objects[tripEvidenceKey(entry.ID)]; !ok {
+		t.Fatal("complete evidence was not cached")
+	}
+	cached, err := svc.GetTripEvidence(ctx, entry.ID)
+	if err != nil || !cached.Cached || !bytes.Equal(cached.Body, evidence.Body) {
+		t.Fatalf("GetTripEvidence() second call = %v, %v; want cached package", cached.Cached, err)
+	}
+
+	// Проезд без объёма снега — не рейс
+	if _, err := svc.GetTripEvidence(ctx, exit.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetTripEvidence(exit) error = %v, want ErrNotFound", err)
+	}
+	driver := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleDriver})
+	if _, err := svc.GetTripEvidence(driver, entry.ID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("GetTripEvidence(driver) error = %v, want ErrForbidden", err)
+	}
+}
+
+func unzipEvidence(t *testing.T, body []byte) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = data
+	}
+	return files
+}
+
+func evidenceFileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
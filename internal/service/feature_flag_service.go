@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+// featureFlagCacheTTL — сколько переопределения флагов берутся из памяти: изменения через API этой реплики
+// видны сразу, других реплик — не позже чем через этот срок
+const featureFlagCacheTTL = 30 * time.Second
+
+// FeatureFlagOverrideInfo — переопределение флага для всех (global), организации или камеры
+type FeatureFlagOverrideInfo struct {
+	Scope     string    `json:"scope"`
+	ScopeID   string    `json:"scope_id,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	Reason    *string   `json:"reason,omitempty"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeatureFlagInfo — флаг возможности: Default — из FEATURE_FLAGS, Enabled — для всех с учётом переопределения
+// global. Переопределения организаций и камер действуют поверх Enabled.
+type FeatureFlagInfo struct {
+	Name      string                    `json:"name"`
+	Default   bool                      `json:"default"`
+	Enabled   bool                      `json:"enabled"`
+	Overrides []FeatureFlagOverrideInfo `json:"overrides"`
+}
+
+// FeatureFlagOverrideInput — переопределение флага. ScopeID — id организации или камеры, для global — пусто.
+type FeatureFlagOverrideInput struct {
+	Scope   string
+	ScopeID string
+	Enabled *bool
+	Reason  string
+}
+
+// featureFlagCache — переопределения флагов в памяти: флаги проверяются на горячем пути приёма событий
+type featureFlagCache struct {
+	mu        sync.Mutex
+	loaded    bool
+	loadedAt  time.Time
+	overrides []repository.FeatureFlagOverride
+}
+
+func (c *featureFlagCache) get(now time.Time) ([]repository.FeatureFlagOverride, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded || now.Sub(c.loadedAt) >= featureFlagCacheTTL {
+		return nil, false
+	}
+	return c.overrides, true
+}
+
+func (c *featureFlagCache) store(overrides []repository.FeatureFlagOverride, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overrides, c.loaded, c.loadedAt = overrides, true, now
+}
+
+func (c *featureFlagCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = false
+}
+
+func (s *ANPRService) featureFlagOverrides(ctx context.Context) ([]repository.FeatureFlagOverride, error) {
+	now := s.clock.Now()
+	if overrides, ok := s.flags.get(now); ok {
+		return overrides, nil
+	}
+	overrides, err := s.repo.ListFeatureFlagOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.flags.store(overrides, now)
+	return overrides, nil
+}
+
+// FeatureEnabled проверяет, включён ли флаг для камеры cameraID. Порядок: переопределение камеры,
+// организации-оператора полигона камеры, global, затем FEATURE_FLAGS. Если переопределения не загрузились,
+// действует FEATURE_FLAGS.
+func (s *ANPRService) FeatureEnabled(ctx context.Context, flag, cameraID string) bool {
+	enabled := s.Config().FeatureFlags[flag]
+	overrides, err := s.featureFlagOverrides(ctx)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("flag", flag).Msg("failed to load feature flag overrides, using defaults")
+		return enabled
+	}
+
+	var global, organization *repository.FeatureFlagOverride
+	orgOverrides := map[string]*repository.FeatureFlagOverride{}
+	for i := range overrides {
+		override := &overrides[i]
+		if override.Flag != flag {
+			continue
+		}
+		switch override.Scope {
+		case repository.FeatureFlagScopeCamera:
+			if override.ScopeID == cameraID {
+				return override.Enabled
+			}
+		case repository.FeatureFlagScopeOrganization:
+			orgOverrides[override.ScopeID] = override
+		case repository.FeatureFlagScopeGlobal:
+			global = override
+		}
+	}
+	// Организация камеры ищется, только если у флага есть переопределения организаций
+	if len(orgOverrides) > 0 {
+		if orgID := s.cameraOrganization(ctx, cameraID); orgID != nil {
+			organization = orgOverrides[orgID.String()]
+		}
+	}
+	switch {
+	case organization != nil:
+		return organization.Enabled
+	case global != nil:
+		return global.Enabled
+	}
+	return enabled
+}
+
+// cameraOrganization — организация-оператор полигона камеры; nil — камера не привязана к полигону с организацией
+func (s *ANPRService) cameraOrganization(ctx context.Context, cameraID string) *uuid.UUID {
+	camera := s.lookupCamera(ctx, cameraID)
+	if camera == nil || camera.PolygonID == nil {
+		return nil
+	}
+	polygon, err := s.repo.GetPolygon(ctx, *camera.PolygonID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("camera_id", cameraID).Msg("failed to load camera polygon for feature flags")
+		return nil
+	}
+	return polygon.OrganizationID
+}
+
+// ListFeatureFlags возвращает известные флаги с их значениями и переопределениями
+func (s *ANPRService) ListFeatureFlags(ctx context.Context) ([]FeatureFlagInfo, error) {
+	overrides, err := s.featureFlagOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defaults := s.Config().FeatureFlags
+	result := make([]FeatureFlagInfo, 0, len(config.FeatureFlagNames))
+	for _, name := range config.FeatureFlagNames {
+		info := FeatureFlagInfo{
+			Name:      name,
+			Default:   defaults[name],
+			Enabled:   defaults[name],
+			Overrides: []FeatureFlagOverrideInfo{},
+		}
+		for _, override := range overrides {
+			if override.Flag != name {
+				continue
+			}
+			if override.Scope == repository.FeatureFlagScopeGlobal {
+				info.Enabled = override.Enabled
+			}
+			info.Overrides = append(info.Overrides, toFeatureFlagOverrideInfo(override))
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// SetFeatureFlagOverride переопределяет флаг для всех, организации или камеры
+func (s *ANPRService) SetFeatureFlagOverride(ctx context.Context, flag string, input FeatureFlagOverrideInput) (*FeatureFlagInfo, error) {
+	principal, err := requirePrincipal(ctx, canManageFeatureFlags)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(config.FeatureFlagNames, flag) {
+		return nil, fmt.Errorf("%w: unknown feature flag %q", ErrNotFound, flag)
+	}
+	scope, scopeID, err := parseFeatureFlagScope(input.Scope, input.ScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Enabled == nil {
+		return nil, fmt.Errorf("%w: enabled is required", ErrInvalidInput)
+	}
+
+	override := &repository.FeatureFlagOverride{
+		Flag:      flag,
+		Scope:     scope,
+		ScopeID:   scopeID,
+		Enabled:   *input.Enabled,
+		UpdatedBy: principal.UserID,
+	}
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		override.Reason = &reason
+	}
+	if err := s.repo.UpsertFeatureFlagOverride(ctx, override); err != nil {
+		return nil, err
+	}
+	s.flags.invalidate()
+	s.logger(ctx).Info().
+		Str("flag", flag).
+		Str("scope", scope).
+		Str("scope_id", scopeID).
+		Bool("enabled", override.Enabled).
+		Str("user_id", principal.UserID.String()).
+		Msg("feature flag override set")
+
+	return s.featureFlagInfo(ctx, flag)
+}
+
+// DeleteFeatureFlagOverride снимает переопределение флага: в области снова действует значение уровнем выше
+func (s *ANPRService) DeleteFeatureFlagOverride(ctx context.Context, flag, scope, scopeID string) (*FeatureFlagInfo, error) {
+	principal, err := requirePrincipal(ctx, canManageFeatureFlags)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(config.FeatureFlagNames, flag) {
+		return nil, fmt.Errorf("%w: unknown feature flag %q", ErrNotFound, flag)
+	}
+	scope, scopeID, err = parseFeatureFlagScope(scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.repo.DeleteFeatureFlagOverride(ctx, flag, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, ErrNotFound
+	}
+	s.flags.invalidate()
+	s.logger(ctx).Info().
+		Str("flag", flag).
+		Str("scope", scope).
+		Str("scope_id", scopeID).
+		Str("user_id", principal.UserID.String()).
+		Msg("feature flag override removed")
+
+	return s.featureFlagInfo(ctx, flag)
+}
+
+func (s *ANPRService) featureFlagInfo(ctx context.Context, flag string) (*FeatureFlagInfo, error) {
+	flags, err := s.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range flags {
+		if info.Name == flag {
+			return &info, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// parseFeatureFlagScope проверяет область переопределения: id организации — UUID, id камеры — непустой,
+// у global id нет
+func parseFeatureFlagScope(scope, scopeID string) (string, string, error) {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	scopeID = strings.TrimSpace(scopeID)
+	switch scope {
+	case repository.FeatureFlagScopeGlobal:
+		if scopeID != "" {
+			return "", "", fmt.Errorf("%w: scope_id must be empty for global scope", ErrInvalidInput)
+		}
+	case repository.FeatureFlagScopeOrganization:
+		orgID, err := uuid.Parse(scopeID)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: scope_id must be an organization id", ErrInvalidInput)
+		}
+		scopeID = orgID.String()
+	case repository.FeatureFlagScopeCamera:
+		if scopeID == "" {
+			return "", "", fmt.Errorf("%w: scope_id must be a camera id", ErrInvalidInput)
+		}
+	default:
+		return "", "", fmt.Errorf("%w: scope must be one of %s, %s, %s", ErrInvalidInput,
+			repository.FeatureFlagScopeGlobal, repository.FeatureFlagScopeOrganization, repository.FeatureFlagScopeCamera)
+	}
+	return scope, scopeID, nil
+}
+
+func toFeatureFlagOverrideInfo(override repository.FeatureFlagOverride) FeatureFlagOverrideInfo {
+	return FeatureFlagOverrideInfo{
+		Scope:     override.Scope,
+		ScopeID:   override.ScopeID,
+		Enabled:   override.Enabled,
+		Reason:    override.Reason,
+		UpdatedBy: override.UpdatedBy.String(),
+		UpdatedAt: override.UpdatedAt,
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	orgID, polygonID := uuid.New(), uuid.New()
+	override := func(scope, scopeID string, enabled bool) repository.FeatureFlagOverride {
+		return repository.FeatureFlagOverride{Flag: config.FeatureDedup, Scope: scope, ScopeID: scopeID, Enabled: enabled}
+	}
+	cameraOnPolygon := func(store *mocks.MockANPRStoreMockRecorder) {
+		store.GetCamera(gomock.Any(), "cam-1").Return(&repository.Camera{ID: "cam-1", PolygonID: &polygonID}, nil)
+		store.GetPolygon(gomock.Any(), polygonID).Return(&repository.Polygon{ID: polygonID, OrganizationID: &orgID}, nil)
+	}
+
+	tests := []struct {
+		name      string
+		overrides []repository.FeatureFlagOverride
+		loadErr   error
+		setup     func(store *mocks.MockANPRStoreMockRecorder)
+		want      bool
+	}{
+		{name: "default from config", want: true},
+		{
+			name:      "global override",
+			overrides: []repository.FeatureFlagOverride{override(repository.FeatureFlagScopeGlobal, "", false)},
+			want:      false,
+		},
+		{
+			name: "organization wins over global",
+			overrides: []repository.FeatureFlagOverride{
+				override(repository.FeatureFlagScopeGlobal, "", true),
+				override(repository.FeatureFlagScopeOrganization, orgID.String(), false),
+			},
+			setup: cameraOnPolygon,
+			want:  false,
+		},
+		{
+			name: "other organization falls back to global",
+			overrides: []repository.FeatureFlagOverride{
+				override(repository.FeatureFlagScopeGlobal, "", false),
+				override(repository.FeatureFlagScopeOrganization, uuid.NewString(), true),
+			},
+			setup: cameraOnPolygon,
+			want:  false,
+		},
+		{
+			name: "camera wins over organization",
+			overrides: []repository.FeatureFlagOverride{
+				override(repository.FeatureFlagScopeOrganization, orgID.String(), false),
+				override(repository.FeatureFlagScopeCamera, "cam-1", true),
+			},
+			want: true,
+		},
+		{
+			name: "other flags are ignored",
+			overrides: []repository.FeatureFlagOverride{
+				{Flag: config.FeatureAsyncIngest, Scope: repository.FeatureFlagScopeCamera, ScopeID: "cam-1", Enabled: false},
+			},
+			want: true,
+		},
+		{name: "load error keeps default", loadErr: errors.New("db down"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(tt.overrides, tt.loadErr)
+			if tt.setup != nil {
+				tt.setup(store.EXPECT())
+			}
+			if got := svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1"); got != tt.want {
+				t.Errorf("FeatureEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeatureFlagOverridesCache(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	manual := svc.clock.(*clock.Manual)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil).Times(2)
+
+	svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1")
+	manual.Advance(featureFlagCacheTTL - time.Second)
+	svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1")
+	manual.Advance(time.Second)
+	svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1")
+}
+
+func TestSetFeatureFlagOverride(t *testing.T) {
+	userID, orgID := uuid.New(), uuid.New()
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: userID, Role: model.UserRoleAkimatAdmin})
+	kgu := model.WithPrincipal(context.Background(), model.Principal{UserID: userID, Role: model.UserRoleKguZkhAdmin})
+	on := true
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		flag    string
+		input   FeatureFlagOverrideInput
+		want    *repository.FeatureFlagOverride
+		wantErr error
+	}{
+		{
+			name:  "organization override",
+			ctx:   admin,
+			flag:  config.FeatureAsyncIngest,
+			input: FeatureFlagOverrideInput{Scope: " Organization ", ScopeID: orgID.String(), Enabled: &on, Reason: "пилот на Шаховском"},
+			want:  &repository.FeatureFlagOverride{Flag: config.FeatureAsyncIngest, Scope: repository.FeatureFlagScopeOrganization, ScopeID: orgID.String(), Enabled: true},
+		},
+		{
+			name:  "camera override",
+			ctx:   admin,
+			flag:  config.FeatureDedup,
+			input: FeatureFlagOverrideInput{Scope: "camera", ScopeID: " gate-2 ", Enabled: &on},
+			want:  &repository.FeatureFlagOverride{Flag: config.FeatureDedup, Scope: repository.FeatureFlagScopeCamera, ScopeID: "gate-2", Enabled: true},
+		},
+		{name: "unknown flag", ctx: admin, flag: "barrier_control", input: FeatureFlagOverrideInput{Scope: "global", Enabled: &on}, wantErr: ErrNotFound},
+		{name: "global with id", ctx: admin, flag: config.FeatureDedup, input: FeatureFlagOverrideInput{Scope: "global", ScopeID: "x", Enabled: &on}, wantErr: ErrInvalidInput},
+		{name: "organization id is not uuid", ctx: admin, flag: config.FeatureDedup, input: FeatureFlagOverrideInput{Scope: "organization", ScopeID: "akimat", Enabled: &on}, wantErr: ErrInvalidInput},
+		{name: "unknown scope", ctx: admin, flag: config.FeatureDedup, input: FeatureFlagOverrideInput{Scope: "polygon", Enabled: &on}, wantErr: ErrInvalidInput},
+		{name: "enabled required", ctx: admin, flag: config.FeatureDedup, input: FeatureFlagOverrideInput{Scope: "global"}, wantErr: ErrInvalidInput},
+		{name: "kgu admin forbidden", ctx: kgu, flag: config.FeatureDedup, input: FeatureFlagOverrideInput{Scope: "global", Enabled: &on}, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			var saved *repository.FeatureFlagOverride
+			if tt.want != nil {
+				store.EXPECT().UpsertFeatureFlagOverride(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, override *repository.FeatureFlagOverride) error {
+						saved = override
+						return nil
+					})
+				store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).DoAndReturn(
+					func(context.Context) ([]repository.FeatureFlagOverride, error) {
+						return []repository.FeatureFlagOverride{*saved}, nil
+					})
+			}
+
+			info, err := svc.SetFeatureFlagOverride(tt.ctx, tt.flag, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetFeatureFlagOverride() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				return
+			}
+			if saved.Flag != tt.want.Flag || saved.Scope != tt.want.Scope || saved.ScopeID != tt.want.ScopeID ||
+				saved.Enabled != tt.want.Enabled || saved.UpdatedBy != userID {
+				t.Errorf("saved override = %+v, want %+v", saved, tt.want)
+			}
+			if info.Name != tt.flag || len(info.Overrides) != 1 {
+				t.Errorf("flag info = %+v", info)
+			}
+		})
+	}
+}
+
+func TestSetFeatureFlagOverrideInvalidatesCache(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	off := false
+	disabled := repository.FeatureFlagOverride{Flag: config.FeatureDedup, Scope: repository.FeatureFlagScopeCamera, ScopeID: "cam-1"}
+
+	gomock.InOrder(
+		store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil),
+		store.EXPECT().UpsertFeatureFlagOverride(gomock.Any(), gomock.Any()).Return(nil),
+		store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return([]repository.FeatureFlagOverride{disabled}, nil),
+	)
+
+	if !svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1") {
+		t.Fatal("dedup must be enabled by default")
+	}
+	if _, err := svc.SetFeatureFlagOverride(admin, config.FeatureDedup, FeatureFlagOverrideInput{Scope: "camera", ScopeID: "cam-1", Enabled: &off}); err != nil {
+		t.Fatalf("SetFeatureFlagOverride() error = %v", err)
+	}
+	if svc.FeatureEnabled(context.Background(), config.FeatureDedup, "cam-1") {
+		t.Error("dedup must be disabled for cam-1 right after the override")
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"anpr-service/internal/repository"
+)
+
+const (
+	CameraStatusOK     = "ok"
+	CameraStatusSilent = "silent"
+	CameraStatusIdle   = "idle"
+	// CameraStatusVideoLoss — камера на связи, но сообщает о пропаже видеосигнала (videoloss)
+	CameraStatusVideoLoss = "video_loss"
+)
+
+// CameraLiveness — состояние камеры для /health/full
+type CameraLiveness struct {
+	CameraID            string     `json:"camera_id"`
+	Name                *string    `json:"name,omitempty"`
+	LastEventAt         *time.Time `json:"last_event_at,omitempty"`
+	LastEventAgeSeconds *int64     `json:"last_event_age_seconds,omitempty"`
+	LastHeartbeatAt     *time.Time `json:"last_heartbeat_at,omitempty"`
+	VideoLossSince      *time.Time `json:"video_loss_since,omitempty"`
+	ExpectedActive      bool       `json:"expected_active"`
+	Status              string     `json:"status"`
+}
+
+// CameraLiveness возвращает возраст последнего события по каждой зарегистрированной камере.
+// Камера считается молчащей (silent), если она должна работать (идёт смена) и не присылала ни событий,
+// ни heartbeat дольше HEALTH_CAMERA_SILENCE_THRESHOLD. Камера, сообщившая о пропаже видеосигнала,
+// получает статус video_loss. Вне смены камера получает статус idle.
+func (s *ANPRService) CameraLiveness(ctx context.Context, now time.Time) ([]CameraLiveness, error) {
+	cameras, err := s.repo.ListCamerasWithLastEvent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera liveness: %w", err)
+	}
+
+	result := make([]CameraLiveness, 0, len(cameras))
+	for _, camera := range cameras {
+		result = append(result, s.cameraLiveness(camera, now))
+	}
+	return result, nil
+}
+
+// cameraLiveness определяет статус камеры по времени её последнего события и сигнала состояния
+func (s *ANPRService) cameraLiveness(camera repository.CameraLastEvent, now time.Time) CameraLiveness {
+	threshold := s.Config().Health.CameraSilenceThreshold
+	item := CameraLiveness{
+		CameraID:        camera.ID,
+		Name:            camera.Name,
+		LastEventAt:     camera.LastEventAt,
+		LastHeartbeatAt: camera.LastHeartbeatAt,
+		VideoLossSince:  camera.VideoLossSince,
+	}
+	if camera.LastEventAt != nil {
+		age := int64(now.Sub(*camera.LastEventAt).Seconds())
+		item.LastEventAgeSeconds = &age
+	}
+
+	// Смена должна идти уже не меньше порога, иначе камера только «заступила» и молчание ожидаемо
+	schedule := s.cameraWorkingHours(camera.Camera)
+	loc := s.cameraLocation(&camera.Camera)
+	item.ExpectedActive = schedule.Contains(now, loc) && schedule.Contains(now.Add(-threshold), loc)
+
+	// Heartbeat подтверждает, что камера на связи, даже если машин не было
+	lastSeen := camera.LastEventAt
+	if camera.LastHeartbeatAt != nil && (lastSeen == nil || camera.LastHeartbeatAt.After(*lastSeen)) {
+		lastSeen = camera.LastHeartbeatAt
+	}
+
+	switch {
+	case !item.ExpectedActive:
+		item.Status = CameraStatusIdle
+	case lastSeen == nil || now.Sub(*lastSeen) > threshold:
+		item.Status = CameraStatusSilent
+	case camera.VideoLossSince != nil:
+		item.Status = CameraStatusVideoLoss
+	default:
+		item.Status = CameraStatusOK
+	}
+	return item
+}
+
+// cameraWorkingHours возвращает расписание, в которое от камеры ожидаются события:
+// собственное расписание камеры, а если его нет — общая рабочая смена из конфигурации
+func (s *ANPRService) cameraWorkingHours(camera repository.Camera) ArmedSchedule {
+	value := s.Config().Health.CameraWorkingHours
+	if camera.ArmedSchedule != nil && *camera.ArmedSchedule != "" {
+		value = *camera.ArmedSchedule
+	}
+	schedule, err := ParseArmedSchedule(value)
+	if err != nil {
+		s.log.Warn().Err(err).Str("camera_id", camera.ID).Msg("invalid camera working hours, treating camera as always active")
+		return nil
+	}
+	return schedule
+}
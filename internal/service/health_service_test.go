@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+func TestCameraLivenessSignals(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Health.CameraSilenceThreshold = 30 * time.Minute
+	svc, store := newTestService(t, cfg)
+
+	oldEvent := testNow.Add(-2 * time.Hour)
+	heartbeat := testNow.Add(-time.Minute)
+	lostAt := testNow.Add(-10 * time.Minute)
+	store.EXPECT().ListCamerasWithLastEvent(gomock.Any()).Return([]repository.CameraLastEvent{
+		{Camera: repository.Camera{ID: "cam-silent"}, LastEventAt: &oldEvent},
+		{Camera: repository.Camera{ID: "cam-heartbeat", LastHeartbeatAt: &heartbeat}, LastEventAt: &oldEvent},
+		{Camera: repository.Camera{ID: "cam-video-loss", LastHeartbeatAt: &heartbeat, VideoLossSince: &lostAt}},
+		{Camera: repository.Camera{ID: "cam-gone", LastHeartbeatAt: &oldEvent, VideoLossSince: &oldEvent}},
+	}, nil)
+
+	got, err := svc.CameraLiveness(context.Background(), testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"cam-silent":     CameraStatusSilent,
+		"cam-heartbeat":  CameraStatusOK,
+		"cam-video-loss": CameraStatusVideoLoss,
+		"cam-gone":       CameraStatusSilent,
+	}
+	for _, camera := range got {
+		if camera.Status != want[camera.CameraID] {
+			t.Errorf("%s: status = %s, want %s", camera.CameraID, camera.Status, want[camera.CameraID])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/ingest/generic"
+	"anpr-service/internal/ingest/hikvisionpush"
+)
+
+// newIngestRegistry регистрирует встроенные адаптеры форматов камер
+func (s *ANPRService) newIngestRegistry() *ingest.Registry {
+	return ingest.NewRegistry(
+		&generic.Adapter{
+			Strict: func() bool { return s.Config().Ingest.PayloadValidation == config.PayloadValidationStrict },
+			Log:    s.log,
+		},
+		&hikvisionpush.Adapter{
+			Parts:           s.Config().Ingest.HikvisionParts,
+			DefaultCameraID: s.Config().Camera.HTTPHost,
+			Locator:         s,
+			Log:             s.log,
+		},
+	)
+}
+
+// IngestAdapter возвращает адаптер формата камеры по имени (ingest.AdapterGeneric, ingest.AdapterHikvision)
+// Паника при разборе возвращается как *ingest.PanicError.
+func (s *ANPRService) IngestAdapter(name string) (ingest.Adapter, error) {
+	adapter, err := s.adapters.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return recoveringAdapter{Adapter: adapter, svc: s}, nil
+}
+
+// RegisterIngestAdapter добавляет адаптер нового формата камер
+func (s *ANPRService) RegisterIngestAdapter(adapter ingest.Adapter) error {
+	return s.adapters.Register(adapter)
+}
+
+// RecordCameraSignal отмечает сигнал состояния камеры для мониторинга живости (/health/full):
+// время последнего сигнала и пропажу видеосигнала
+func (s *ANPRService) RecordCameraSignal(ctx context.Context, signal ingest.Signal) error {
+	if signal.CameraID == "" {
+		return fmt.Errorf("%w: camera_id is required", ErrInvalidInput)
+	}
+	if signal.VideoLoss != nil && *signal.VideoLoss {
+		s.logger(ctx).Warn().Str("camera_id", signal.CameraID).Msg("camera reports video loss")
+	}
+	return s.repo.RecordCameraSignal(ctx, signal.CameraID, s.clock.Now(), signal.VideoLoss)
+}
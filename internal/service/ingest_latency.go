@@ -0,0 +1,168 @@
+package service
+
+import (
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"anpr-service/internal/metrics"
+)
+
+const (
+	// maxLatencySamples — наибольшее число задержек камеры в окне SLO_METRICS_WINDOW; при переполнении
+	// вытесняются старые
+	maxLatencySamples = 10000
+	// maxLatencyCameras — наибольшее число камер с отдельными метриками: camera_id приходит от камеры,
+	// и ошибочные идентификаторы не должны раздувать число рядов. Остальные учитываются как otherLatencyCamera.
+	maxLatencyCameras  = 500
+	otherLatencyCamera = "_other"
+)
+
+// latencyQuantiles — перцентили задержки в метриках
+var latencyQuantiles = []float64{0.5, 0.95, 0.99}
+
+type latencySample struct {
+	at      time.Time
+	seconds float64
+}
+
+// latencySeries — задержки приёма: скользящее окно для перцентилей и накопительные счётчики
+type latencySeries struct {
+	samples  []latencySample
+	count    uint64
+	sum      float64
+	breaches uint64
+}
+
+func (l *latencySeries) observe(sample latencySample, window time.Duration, breach bool) {
+	l.count++
+	l.sum += sample.seconds
+	if breach {
+		l.breaches++
+	}
+	l.samples = append(l.samples, sample)
+	l.trim(sample.at, window)
+	if len(l.samples) > maxLatencySamples {
+		l.samples = slices.Delete(l.samples, 0, len(l.samples)-maxLatencySamples)
+	}
+}
+
+// trim отбрасывает задержки старше окна
+func (l *latencySeries) trim(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	drop := sort.Search(len(l.samples), func(i int) bool { return l.samples[i].at.After(cutoff) })
+	if drop > 0 {
+		l.samples = slices.Delete(l.samples, 0, drop)
+	}
+}
+
+// quantiles — перцентили latencyQuantiles по окну (ближайший ранг); NaN, если событий в окне не было
+func (l *latencySeries) quantiles() []float64 {
+	result := make([]float64, len(latencyQuantiles))
+	if len(l.samples) == 0 {
+		for i := range result {
+			result[i] = math.NaN()
+		}
+		return result
+	}
+	values := make([]float64, len(l.samples))
+	for i, sample := range l.samples {
+		values[i] = sample.seconds
+	}
+	slices.Sort(values)
+	for i, q := range latencyQuantiles {
+		rank := int(math.Ceil(q*float64(len(values)))) - 1
+		result[i] = values[max(rank, 0)]
+	}
+	return result
+}
+
+// latencyTracker — задержки приёма событий камер от event_time до записи в БД (для /internal/metrics)
+type latencyTracker struct {
+	mu      sync.Mutex
+	all     latencySeries
+	cameras map[string]*latencySeries
+}
+
+func (t *latencyTracker) observe(cameraID string, latency time.Duration, at time.Time, window, target time.Duration) {
+	sample := latencySample{at: at, seconds: max(latency, 0).Seconds()}
+	breach := latency > target
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cameras == nil {
+		t.cameras = make(map[string]*latencySeries)
+	}
+	series, ok := t.cameras[cameraID]
+	if !ok {
+		if len(t.cameras) >= maxLatencyCameras {
+			cameraID = otherLatencyCamera
+		}
+		if series, ok = t.cameras[cameraID]; !ok {
+			series = &latencySeries{}
+			t.cameras[cameraID] = series
+		}
+	}
+	series.observe(sample, window, breach)
+	t.all.observe(sample, window, breach)
+}
+
+// observeIngestLatency учитывает задержку записанного события камеры в метриках SLO
+func (s *ANPRService) observeIngestLatency(cameraID string, eventTime time.Time) {
+	cfg := s.Config().SLO
+	now := s.clock.Now()
+	s.latency.observe(cameraID, now.Sub(eventTime), now, cfg.MetricsWindow, cfg.IngestLatencyTarget)
+}
+
+// WriteMetrics пишет метрики сервиса в текстовом формате Prometheus
+func (s *ANPRService) WriteMetrics(out io.Writer) error {
+	cfg := s.Config().SLO
+	now := s.clock.Now()
+	w := metrics.NewWriter(out)
+
+	w.Header("anpr_ingest_latency_slo_target_seconds", "Target latency from camera event_time to DB commit (SLO_INGEST_LATENCY_TARGET).", metrics.TypeGauge)
+	w.Sample("anpr_ingest_latency_slo_target_seconds", cfg.IngestLatencyTarget.Seconds())
+	w.Header("anpr_ingest_latency_slo_objective", "Share of camera events that must meet the latency target (SLO_INGEST_OBJECTIVE).", metrics.TypeGauge)
+	w.Sample("anpr_ingest_latency_slo_objective", cfg.IngestObjective)
+	if s.dbStats != nil {
+		writeDBPoolMetrics(w, s.dbStats.Stats())
+	}
+
+	t := &s.latency
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.all.trim(now, cfg.MetricsWindow)
+	w.Header("anpr_ingest_latency_seconds", "Latency from camera event_time to DB commit; quantiles over SLO_METRICS_WINDOW.", metrics.TypeSummary)
+	writeLatencySummary(w, "anpr_ingest_latency_seconds", &t.all)
+
+	cameraIDs := make([]string, 0, len(t.cameras))
+	for cameraID, series := range t.cameras {
+		series.trim(now, cfg.MetricsWindow)
+		cameraIDs = append(cameraIDs, cameraID)
+	}
+	slices.Sort(cameraIDs)
+
+	w.Header("anpr_camera_ingest_latency_seconds", "Per-camera latency from event_time to DB commit; quantiles over SLO_METRICS_WINDOW.", metrics.TypeSummary)
+	for _, cameraID := range cameraIDs {
+		writeLatencySummary(w, "anpr_camera_ingest_latency_seconds", t.cameras[cameraID], metrics.Label{Name: "camera_id", Value: cameraID})
+	}
+	w.Header("anpr_camera_ingest_latency_slo_breaches_total", "Camera events committed later than the latency target.", metrics.TypeCounter)
+	for _, cameraID := range cameraIDs {
+		w.Sample("anpr_camera_ingest_latency_slo_breaches_total", float64(t.cameras[cameraID].breaches), metrics.Label{Name: "camera_id", Value: cameraID})
+	}
+	return w.Flush()
+}
+
+func writeLatencySummary(w *metrics.Writer, name string, series *latencySeries, labels ...metrics.Label) {
+	for i, value := range series.quantiles() {
+		quantile := metrics.Label{Name: "quantile", Value: strconv.FormatFloat(latencyQuantiles[i], 'g', -1, 64)}
+		w.Sample(name, value, append(slices.Clone(labels), quantile)...)
+	}
+	w.Sample(name+"_sum", series.sum, labels...)
+	w.Sample(name+"_count", float64(series.count), labels...)
+}
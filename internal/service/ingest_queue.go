@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/domain/anpr"
+)
+
+// Повторы события из очереди после временной ошибки (БД недоступна, таймаут): ответ камере уже
+// отправлен, и она событие не повторит. Пауза перед n-й попыткой — (n-1) × ingestQueueRetryBackoff.
+const (
+	ingestQueueAttempts     = 3
+	ingestQueueRetryBackoff = 500 * time.Millisecond
+)
+
+// IngestQueue — ограниченная очередь событий для асинхронного сохранения (INGEST_MODE=async).
+// Handler отвечает камере 202 сразу после постановки в очередь, а пул воркеров сохраняет события в БД.
+// Если очередь заполнена, Enqueue возвращает false и handler сохраняет событие синхронно.
+type IngestQueue struct {
+	svc     *ANPRService
+	log     zerolog.Logger
+	jobs    chan ingestJob
+	workers int
+	wg      sync.WaitGroup
+	// stop отменяется при выходе из Close: если он не дождался воркеров, паузы перед повтором
+	// прерываются, и событие сразу уходит в dead letters
+	stop   context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+
+	enqueued  atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+	overflow  atomic.Int64
+}
+
+type ingestJob struct {
+	ctx                context.Context
+	payload            anpr.EventPayload
+	defaultCameraModel string
+	eventID            uuid.UUID
+	photoURLs          []string
+	enqueuedAt         time.Time
+}
+
+// IngestQueueStats — счётчики очереди для /health/full
+type IngestQueueStats struct {
+	Depth     int   `json:"depth"`
+	Capacity  int   `json:"capacity"`
+	Workers   int   `json:"workers"`
+	Enqueued  int64 `json:"enqueued"`
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+	Retried   int64 `json:"retried"`
+	Overflow  int64 `json:"overflow"`
+}
+
+func NewIngestQueue(svc *ANPRService, size, workers int, log zerolog.Logger) *IngestQueue {
+	stop, cancel := context.WithCancel(context.Background())
+	return &IngestQueue{
+		svc:     svc,
+		log:     log,
+		jobs:    make(chan ingestJob, size),
+		workers: workers,
+		stop:    stop,
+		cancel:  cancel,
+	}
+}
+
+// Start запускает пул воркеров
+func (q *IngestQueue) Start() {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.run()
+	}
+}
+
+// Enqueue ставит событие в очередь. Контекст запроса отвязывается от отмены: логгер с request_id
+// и трассировка сохраняются, но ответ камере не прерывает сохранение.
+// false — очередь переполнена или остановлена, событие нужно обработать синхронно.
+func (q *IngestQueue) Enqueue(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string, eventID uuid.UUID, photoURLs []string) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	job := ingestJob{
+		ctx:                context.WithoutCancel(ctx),
+		payload:            payload,
+		defaultCameraModel: defaultCameraModel,
+		eventID:            eventID,
+		photoURLs:          photoURLs,
+		enqueuedAt:         q.svc.clock.Now(),
+	}
+	select {
+	case q.jobs <- job:
+		q.enqueued.Add(1)
+		return true
+	default:
+		q.overflow.Add(1)
+		return false
+	}
+}
+
+// Close перестаёт принимать события и ждёт, пока воркеры сохранят уже поставленные в очередь.
+// Если ctx истекает раньше, оставшиеся события теряются — их число попадает в лог, а события,
+// ждущие повтора, сохраняются в dead letters без оставшихся попыток.
+func (q *IngestQueue) Close(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.jobs)
+	q.mu.Unlock()
+	defer q.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.log.Error().Int("pending", len(q.jobs)).Msg("ingest queue was not drained before shutdown")
+		return ctx.Err()
+	}
+}
+
+// Stats возвращает текущую глубину очереди и счётчики
+func (q *IngestQueue) Stats() IngestQueueStats {
+	return IngestQueueStats{
+		Depth:     len(q.jobs),
+		Capacity:  cap(q.jobs),
+		Workers:   q.workers,
+		Enqueued:  q.enqueued.Load(),
+		Processed: q.processed.Load(),
+		Failed:    q.failed.Load(),
+		Retried:   q.retried.Load(),
+		Overflow:  q.overflow.Load(),
+	}
+}
+
+func (q *IngestQueue) run() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		q.process(job)
+	}
+}
+
+func (q *IngestQueue) process(job ingestJob) {
+	log := q.svc.logger(job.ctx)
+	wait := clock.Since(q.svc.clock, job.enqueuedAt)
+
+	result, err := q.svc.ProcessIncomingEvent(job.ctx, job.payload, job.defaultCameraModel, job.eventID, job.photoURLs)
+	for attempt := 2; attempt <= ingestQueueAttempts && isTransientIngestError(err); attempt++ {
+		if clock.Sleep(q.stop, q.svc.clock, time.Duration(attempt-1)*ingestQueueRetryBackoff) != nil {
+			break
+		}
+		log.Warn().Err(err).
+			Str("event_id", job.eventID.String()).
+			Int("attempt", attempt).
+			Msg("retrying queued ANPR event")
+		q.retried.Add(1)
+		result, err = q.svc.ProcessIncomingEvent(job.ctx, job.payload, job.defaultCameraModel, job.eventID, job.photoURLs)
+	}
+	if err != nil {
+		q.failed.Add(1)
+		// Ответ камере уже отправлен: событие после паники или временной ошибки, не прошедшей за все попытки,
+		// сохраняется в dead letters для повтора
+		lost := IsIngestPanic(err) || isTransientIngestError(err)
+		if lost {
+			if err := q.svc.SaveEventDeadLetter(job.ctx, job.payload, err); err != nil {
+				log.Error().Err(err).Str("event_id", job.eventID.String()).Msg("failed to save dead letter")
+			}
+		}
+		entry := log.Warn()
+		if lost {
+			entry = log.Error()
+		}
+		entry.Err(err).
+			Str("event_id", job.eventID.String()).
+			Str("plate", job.payload.Plate).
+			Str("camera_id", job.payload.CameraID).
+			Dur("queue_wait", wait).
+			Msg("failed to process queued ANPR event")
+		return
+	}
+
+	q.processed.Add(1)
+	log.Info().
+		Str("event_id", result.EventID.String()).
+		Str("plate_id", result.PlateID.String()).
+		Str("plate", result.Plate).
+		Int("hits_count", len(result.Hits)).
+		Dur("queue_wait", wait).
+		Msg("successfully processed queued ANPR event")
+}
+
+// isTransientIngestError сообщает, что событие не сохранено из-за ошибки, которая может не повториться
+// (БД, хранилище). Отклонённое по правилам приёма событие (невалидное, дубль, нет в whitelist) и паника
+// повтором не исправляются. Событие, сохранённое попыткой, которая всё же вернула ошибку, при повторе
+// отклоняется как дубль.
+func isTransientIngestError(err error) bool {
+	return err != nil && !IsIngestPanic(err) &&
+		!errors.Is(err, ErrInvalidInput) && !errors.Is(err, ErrDuplicateEvent) && !errors.Is(err, ErrVehicleNotWhitelisted)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func TestIngestQueueOverflow(t *testing.T) {
+	svc, _ := newTestService(t, nil)
+	q := NewIngestQueue(svc, 2, 1, zerolog.Nop())
+	ctx := context.Background()
+	payload := anpr.EventPayload{Plate: "123ABC02", CameraID: "cam-1"}
+
+	for i := 0; i < 2; i++ {
+		if !q.Enqueue(ctx, payload, "", uuid.New(), nil) {
+			t.Fatalf("Enqueue #%d = false, want true", i+1)
+		}
+	}
+	if q.Enqueue(ctx, payload, "", uuid.New(), nil) {
+		t.Fatal("Enqueue into full queue = true, want false")
+	}
+
+	stats := q.Stats()
+	if stats.Depth != 2 || stats.Enqueued != 2 || stats.Overflow != 1 {
+		t.Errorf("Stats() = %+v, want depth=2 enqueued=2 overflow=1", stats)
+	}
+}
+
+func TestIngestQueueClosed(t *testing.T) {
+	q := NewIngestQueue(nil, 1, 1, zerolog.Nop())
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if q.Enqueue(context.Background(), anpr.EventPayload{}, "", uuid.New(), nil) {
+		t.Error("Enqueue after Close = true, want false")
+	}
+
+	var nilQueue *IngestQueue
+	if nilQueue.Enqueue(context.Background(), anpr.EventPayload{}, "", uuid.New(), nil) {
+		t.Error("Enqueue on nil queue = true, want false")
+	}
+}
+
+func TestIngestQueueProcessRetriesTransientErrors(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	tests := []struct {
+		name           string
+		aliasErrs      []error
+		stopped        bool
+		wantDeadLetter bool
+		wantRetried    int64
+		wantBackoff    time.Duration
+	}{
+		{
+			// Дубль — не потеря события, в dead letters он не попадает
+			name:        "retry finds the event already saved",
+			aliasErrs:   []error{dbErr, nil},
+			wantRetried: 1,
+			wantBackoff: ingestQueueRetryBackoff,
+		},
+		{
+			name:           "dead letter after all attempts",
+			aliasErrs:      []error{dbErr, dbErr, dbErr},
+			wantDeadLetter: true,
+			wantRetried:    2,
+			wantBackoff:    3 * ingestQueueRetryBackoff,
+		},
+		{
+			// Close не дождался воркеров: повторов нет, событие сразу в dead letters
+			name:           "stopped queue skips retries",
+			aliasErrs:      []error{dbErr},
+			stopped:        true,
+			wantDeadLetter: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			payload := testPayload()
+			for _, err := range tt.aliasErrs {
+				store.EXPECT().ResolvePlateAlias(gomock.Any(), "123ABC02").Return("", err)
+			}
+			if tt.wantDeadLetter {
+				store.EXPECT().CreateDeadLetter(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter *repository.DeadLetter) error {
+					if letter.CameraID != "cam-1" || letter.Error != "failed to resolve plate alias: connection refused" {
+						t.Errorf("unexpected dead letter: %+v", letter)
+					}
+					return nil
+				})
+			} else {
+				// Первая попытка сохранила событие, но вернула ошибку: вторая находит его дублем
+				expectUnregisteredCamera(store)
+				store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+				store.EXPECT().ExistsRecentEvent(gomock.Any(), "123ABC02", "cam-1", payload.EventTime, 5*time.Minute).Return(true, nil)
+			}
+
+			q := NewIngestQueue(svc, 1, 1, zerolog.Nop())
+			if tt.stopped {
+				q.cancel()
+			}
+			q.process(ingestJob{ctx: context.Background(), payload: payload, eventID: uuid.New(), enqueuedAt: testNow})
+
+			if stats := q.Stats(); stats.Retried != tt.wantRetried || stats.Failed != 1 {
+				t.Errorf("Stats() = %+v, want retried=%d failed=1", stats, tt.wantRetried)
+			}
+			// Паузы перед повторами идут по часам сервиса
+			if got := clock.Since(svc.clock, testNow); got != tt.wantBackoff {
+				t.Errorf("backoff = %v, want %v", got, tt.wantBackoff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/ingest"
+)
+
+// IngestPanicStats — перехваченные паники приёма для /health/full
+type IngestPanicStats struct {
+	Total int64 `json:"total"`
+	// ByStage — число паник по этапам (ingest.StageParse, ingest.StageProcess)
+	ByStage   map[string]int64 `json:"by_stage"`
+	LastAt    *time.Time       `json:"last_at,omitempty"`
+	LastError string           `json:"last_error,omitempty"`
+}
+
+// panicCounter — счётчики перехваченных паник
+type panicCounter struct {
+	mu        sync.Mutex
+	byStage   map[string]int64
+	total     int64
+	lastAt    time.Time
+	lastError string
+}
+
+func (c *panicCounter) record(err *ingest.PanicError, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byStage == nil {
+		c.byStage = map[string]int64{}
+	}
+	c.byStage[err.Stage]++
+	c.total++
+	c.lastAt = at
+	c.lastError = err.Error()
+}
+
+// IngestPanicStats возвращает счётчики перехваченных паник приёма
+func (s *ANPRService) IngestPanicStats() IngestPanicStats {
+	c := &s.panics
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := IngestPanicStats{Total: c.total, ByStage: map[string]int64{}, LastError: c.lastError}
+	for stage, n := range c.byStage {
+		stats.ByStage[stage] = n
+	}
+	if !c.lastAt.IsZero() {
+		lastAt := c.lastAt
+		stats.LastAt = &lastAt
+	}
+	return stats
+}
+
+// recoverIngest превращает панику приёма в *ingest.PanicError и учитывает её в IngestPanicStats.
+// Вызывается только через defer: recover работает лишь в отложенной функции.
+func (s *ANPRService) recoverIngest(ctx context.Context, stage, adapter string, err *error) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	panicErr := &ingest.PanicError{Stage: stage, Adapter: adapter, Value: value, Stack: debug.Stack()}
+	s.panics.record(panicErr, s.clock.Now())
+	s.logger(ctx).Error().
+		Str("stage", stage).
+		Str("adapter", adapter).
+		Interface("panic", value).
+		Str("stack", string(panicErr.Stack)).
+		Msg("recovered from panic during ingest")
+	*err = panicErr
+}
+
+// IsIngestPanic сообщает, что ошибка приёма — перехваченная паника (см. ingest.PanicError)
+func IsIngestPanic(err error) bool {
+	var panicErr *ingest.PanicError
+	return errors.As(err, &panicErr)
+}
+
+// recoveringAdapter перехватывает панику разбора: одно испорченное уведомление не должно ронять
+// воркер или полагаться на общий Recovery gin
+type recoveringAdapter struct {
+	ingest.Adapter
+	svc *ANPRService
+}
+
+func (a recoveringAdapter) Parse(ctx context.Context, req *ingest.Request) (result *ingest.Result, err error) {
+	defer a.svc.recoverIngest(ctx, ingest.StageParse, a.Name(), &err)
+	return a.Adapter.Parse(ctx, req)
+}
+
+// SaveEventDeadLetter сохраняет в dead letters уже разобранное событие (очередь приёма, где тела запроса
+// нет): оно записывается JSON собственного формата и при повторе разбирается адаптером generic
+func (s *ANPRService) SaveEventDeadLetter(ctx context.Context, payload anpr.EventPayload, cause error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.SaveDeadLetter(ctx, DeadLetterInput{
+		Endpoint:    ingest.AdapterGeneric,
+		CameraID:    payload.CameraID,
+		ContentType: "application/json",
+		Body:        body,
+		Err:         cause,
+	})
+}
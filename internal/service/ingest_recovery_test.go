@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/ingest"
+	"anpr-service/internal/repository"
+)
+
+// panickingAdapter — адаптер, который падает на любом запросе
+type panickingAdapter struct{}
+
+func (panickingAdapter) Name() string { return "broken" }
+
+func (panickingAdapter) Parse(context.Context, *ingest.Request) (*ingest.Result, error) {
+	var payload *anpr.EventPayload
+	return &ingest.Result{Payload: &anpr.EventPayload{Plate: payload.Plate}}, nil
+}
+
+func TestIngestPanicRecovery(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	if err := svc.RegisterIngestAdapter(panickingAdapter{}); err != nil {
+		t.Fatalf("RegisterIngestAdapter: %v", err)
+	}
+
+	adapter, err := svc.IngestAdapter("broken")
+	if err != nil {
+		t.Fatalf("IngestAdapter: %v", err)
+	}
+	result, err := adapter.Parse(context.Background(), &ingest.Request{Body: []byte("{}")})
+	var panicErr *ingest.PanicError
+	if result != nil || !errors.As(err, &panicErr) || panicErr.Stage != ingest.StageParse || panicErr.Adapter != "broken" {
+		t.Fatalf("Parse() = %v, %v; want *ingest.PanicError at parse", result, err)
+	}
+
+	// Паника при обработке в воркере очереди: событие уходит в dead letters для повтора адаптером generic
+	store.EXPECT().ResolvePlateAlias(gomock.Any(), "123ABC02").DoAndReturn(func(context.Context, string) (string, error) {
+		panic("alias table corrupted")
+	})
+	store.EXPECT().CreateDeadLetter(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter *repository.DeadLetter) error {
+		var payload anpr.EventPayload
+		if err := json.Unmarshal(letter.Payload, &payload); err != nil {
+			t.Errorf("dead letter payload is not an event: %v", err)
+		}
+		if letter.Endpoint != ingest.AdapterGeneric || letter.CameraID != "cam-1" || payload.Plate != "123 abc-02" ||
+			letter.Error != "panic during process: alias table corrupted" {
+			t.Errorf("unexpected dead letter: %+v", letter)
+		}
+		return nil
+	})
+	q := NewIngestQueue(svc, 1, 1, zerolog.Nop())
+	q.process(ingestJob{ctx: context.Background(), payload: testPayload(), eventID: uuid.New(), enqueuedAt: time.Now()})
+
+	stats := svc.IngestPanicStats()
+	if stats.Total != 2 || stats.ByStage[ingest.StageParse] != 1 || stats.ByStage[ingest.StageProcess] != 1 ||
+		stats.LastAt == nil || !stats.LastAt.Equal(testNow) {
+		t.Fatalf("IngestPanicStats() = %+v", stats)
+	}
+	if q.Stats().Failed != 1 {
+		t.Fatalf("queue stats = %+v, want failed=1", q.Stats())
+	}
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/scheduler"
+)
+
+// Периодические задачи планировщика
+const (
+	JobWhitelistSync             = "whitelist_sync"
+	JobDBQuotaCheck              = "db_quota_check"
+	JobEventPartitionMaintenance = "event_partition_maintenance"
+	JobDeletedEventsPurge        = "deleted_events_purge"
+	JobDeadLetterPurge           = "dead_letter_purge"
+	JobWhitelistReconciliation   = "whitelist_reconciliation"
+	JobListExpiryCleanup         = "list_expiry_cleanup"
+	JobHistoryPurge              = "job_history_purge"
+	JobDataLakeExport            = "datalake_export"
+	JobSavedSearchDelivery       = "saved_search_delivery"
+	JobEventStatsRefresh         = "event_stats_refresh"
+)
+
+// jobRunsDefaultLimit / jobRunsMaxLimit — размер страницы истории запусков задачи
+const (
+	jobRunsDefaultLimit = 50
+	jobRunsMaxLimit     = 500
+)
+
+var (
+	// ErrJobRunning — задача уже выполняется на этой реплике
+	ErrJobRunning = errors.New("job is already running")
+	// ErrSchedulerNotConfigured — планировщик не создан (см. NewScheduler)
+	ErrSchedulerNotConfigured = errors.New("job scheduler is not configured")
+)
+
+// JobInfo — периодическая задача для администратора
+type JobInfo struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Timeout  string `json:"timeout"`
+	// Running — задача выполняется на этой реплике
+	Running bool `json:"running"`
+	// NextRunAt — следующий запуск по расписанию (только на реплике, где работает планировщик)
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	// LastRun — последний запуск на любой реплике
+	LastRun *JobRunInfo `json:"last_run,omitempty"`
+}
+
+// JobRunInfo — запуск задачи
+type JobRunInfo struct {
+	ID          uuid.UUID  `json:"id"`
+	Job         string     `json:"job"`
+	Trigger     string     `json:"trigger"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DurationMs  *int64     `json:"duration_ms,omitempty"`
+	TriggeredBy *uuid.UUID `json:"triggered_by,omitempty"`
+}
+
+// scheduledJob — задача и её расписание по умолчанию: spec или интервал из настроек *_INTERVAL
+type scheduledJob struct {
+	job      scheduler.Job
+	interval time.Duration
+	spec     string
+}
+
+// NewScheduler создаёт планировщик с периодическими задачами сервиса. Расписание задачи берётся из
+// JOB_SCHEDULES, а если его там нет — «@every <интервал>» из её *_INTERVAL (0 — задача выключена).
+// Запускать планировщик нужно на лидере (Run), запускать задачи вручную можно на любой реплике.
+func (s *ANPRService) NewScheduler() (*scheduler.Scheduler, error) {
+	cfg := s.Config()
+	loc, err := time.LoadLocation(cfg.Jobs.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job timezone: %w", err)
+	}
+
+	jobs := []scheduledJob{
+		{job: scheduler.Job{Name: JobWhitelistSync, RunOnStart: true, Run: s.SyncAllCameraWhitelists}, interval: cfg.Camera.WhitelistSyncInterval},
+		{job: scheduler.Job{Name: JobEventPartitionMaintenance, RunOnStart: true, Run: s.EnsureEventPartitions}, interval: time.Hour},
+		{job: scheduler.Job{Name: JobDeletedEventsPurge, Run: s.PurgeDeletedEvents}, interval: cfg.Retention.PurgeInterval},
+		{job: scheduler.Job{Name: JobDeadLetterPurge, Run: s.PurgeDeadLetters}, interval: cfg.Retention.PurgeInterval},
+		{job: scheduler.Job{Name: JobWhitelistReconciliation, Run: func(ctx context.Context) error {
+			_, err := s.ReconcileVehicleWhitelist(ctx)
+			return err
+		}}, interval: cfg.Lists.WhitelistReconcileInterval},
+		{job: scheduler.Job{Name: JobListExpiryCleanup, Run: s.DeleteExpiredListItems}, interval: cfg.Lists.ExpiryCleanupInterval},
+		{job: scheduler.Job{Name: JobHistoryPurge, Run: s.PurgeJobHistory}, interval: 24 * time.Hour},
+		{job: scheduler.Job{Name: JobEventStatsRefresh, Run: s.RefreshEventStats}, interval: cfg.Stats.RefreshInterval},
+	}
+	// Без квоты (DB_QUOTA_MB=0) проверять нечего
+	if cfg.Quota.DBBytes > 0 {
+		jobs = append(jobs, scheduledJob{job: scheduler.Job{Name: JobDBQuotaCheck, RunOnStart: true, Run: func(ctx context.Context) error {
+			_, err := s.CheckDBQuota(ctx)
+			return err
+		}}, interval: cfg.Quota.CheckInterval})
+	}
+	// Выгрузка прошедшего дня для аналитиков — ночью, когда поздние события камер уже приняты
+	if cfg.DataLake.Enabled {
+		jobs = append(jobs, scheduledJob{job: scheduler.Job{Name: JobDataLakeExport, Run: s.ExportDataLakeYesterday}, spec: "30 2 * * *"})
+	}
+	// Сохранённые поиски выгружаются, только если настроен хотя бы один канал доставки (UseReportDelivery)
+	if s.reportTelegram != nil || s.reportMailer != nil {
+		jobs = append(jobs, scheduledJob{job: scheduler.Job{Name: JobSavedSearchDelivery, Run: s.DeliverSavedSearches}, interval: savedSearchDeliveryInterval})
+	}
+
+	known := make(map[string]bool, len(jobs))
+	for _, j := range jobs {
+		known[j.job.Name] = true
+	}
+	for name := range cfg.Jobs.Schedules {
+		// Задачи проверки квоты и выгрузок существуют всегда, но выключенными не создаются
+		if !known[name] && name != JobDBQuotaCheck && name != JobDataLakeExport && name != JobSavedSearchDelivery {
+			return nil, fmt.Errorf("JOB_SCHEDULES: unknown job %q", name)
+		}
+	}
+
+	sched := scheduler.New(scheduler.Options{
+		Location: loc,
+		Jitter:   cfg.Jobs.Jitter,
+		Timeout:  cfg.Jobs.Timeout,
+	}, jobHistory{repo: s.repo}, s.clock, s.ids, s.log)
+	for _, j := range jobs {
+		spec, ok := cfg.Jobs.Schedules[j.job.Name]
+		switch {
+		case ok && spec == config.JobScheduleOff:
+			continue
+		case ok:
+			j.job.Spec = spec
+		case j.spec != "":
+			j.job.Spec = j.spec
+		case j.interval > 0:
+			j.job.Spec = "@every " + j.interval.String()
+		default:
+			continue
+		}
+		if err := sched.Add(j.job); err != nil {
+			return nil, err
+		}
+	}
+	s.jobs = sched
+	return sched, nil
+}
+
+// ListJobs возвращает периодические задачи с последним запуском. Доступно администратору акимата.
+func (s *ANPRService) ListJobs(ctx context.Context) ([]JobInfo, error) {
+	if _, err := requirePrincipal(ctx, canManageJobs); err != nil {
+		return nil, err
+	}
+	if s.jobs == nil {
+		return nil, ErrSchedulerNotConfigured
+	}
+	latest, err := s.repo.ListLatestJobRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns := make(map[string]*JobRunInfo, len(latest))
+	for i := range latest {
+		lastRuns[latest[i].Job] = toJobRunInfo(&latest[i])
+	}
+
+	jobs := s.jobs.Jobs()
+	result := make([]JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, JobInfo{
+			Name:      job.Name,
+			Schedule:  job.Spec,
+			Timeout:   job.Timeout.String(),
+			Running:   job.Running,
+			NextRunAt: job.NextRunAt,
+			LastRun:   lastRuns[job.Name],
+		})
+	}
+	return result, nil
+}
+
+// ListJobRuns возвращает последние запуски задачи, новые первыми. Доступно администратору акимата.
+func (s *ANPRService) ListJobRuns(ctx context.Context, name string, limit int) ([]JobRunInfo, error) {
+	if _, err := requirePrincipal(ctx, canManageJobs); err != nil {
+		return nil, err
+	}
+	if err := s.requireJob(name); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = jobRunsDefaultLimit
+	}
+	if limit > jobRunsMaxLimit {
+		limit = jobRunsMaxLimit
+	}
+	runs, err := s.repo.ListJobRuns(ctx, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]JobRunInfo, 0, len(runs))
+	for i := range runs {
+		result = append(result, *toJobRunInfo(&runs[i]))
+	}
+	return result, nil
+}
+
+// TriggerJob запускает задачу вручную на этой реплике и возвращает начатый запуск, не дожидаясь его
+// окончания. Доступно администратору акимата.
+func (s *ANPRService) TriggerJob(ctx context.Context, name string) (*JobRunInfo, error) {
+	principal, err := requirePrincipal(ctx, canManageJobs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireJob(name); err != nil {
+		return nil, err
+	}
+	userID := principal.UserID
+	run, err := s.jobs.Trigger(ctx, name, &userID)
+	if errors.Is(err, scheduler.ErrJobRunning) {
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("job", name).
+		Str("run_id", run.ID.String()).
+		Str("user_id", userID.String()).
+		Msg("job triggered manually")
+	return &JobRunInfo{
+		ID:          run.ID,
+		Job:         run.Job,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt,
+		TriggeredBy: run.TriggeredBy,
+	}, nil
+}
+
+// PurgeJobHistory удаляет запуски задач старше JOB_HISTORY_RETENTION
+func (s *ANPRService) PurgeJobHistory(ctx context.Context) error {
+	purged, err := s.repo.PurgeJobRuns(ctx, s.clock.Now().Add(-s.Config().Jobs.HistoryRetention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		s.logger(ctx).Info().Int64("purged", purged).Msg("job history purged")
+	}
+	return nil
+}
+
+func (s *ANPRService) requireJob(name string) error {
+	if s.jobs == nil {
+		return ErrSchedulerNotConfigured
+	}
+	for _, job := range s.jobs.Jobs() {
+		if job.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: job %s", ErrNotFound, name)
+}
+
+func toJobRunInfo(run *repository.JobRun) *JobRunInfo {
+	info := &JobRunInfo{
+		ID:          run.ID,
+		Job:         run.Job,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+		TriggeredBy: run.TriggeredBy,
+	}
+	if run.Error != nil {
+		info.Error = *run.Error
+	}
+	if run.FinishedAt != nil {
+		ms := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+		info.DurationMs = &ms
+	}
+	return info
+}
+
+// jobHistory — журнал запусков планировщика в anpr_job_runs
+type jobHistory struct {
+	repo repository.ANPRStore
+}
+
+func (h jobHistory) StartRun(ctx context.Context, run *scheduler.Run) error {
+	return h.repo.CreateJobRun(ctx, &repository.JobRun{
+		ID:          run.ID,
+		Job:         run.Job,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt,
+		TriggeredBy: run.TriggeredBy,
+	})
+}
+
+func (h jobHistory) FinishRun(ctx context.Context, run *scheduler.Run) error {
+	var errMsg *string
+	if run.Error != "" {
+		errMsg = &run.Error
+	}
+	finishedAt := time.Time{}
+	if run.FinishedAt != nil {
+		finishedAt = *run.FinishedAt
+	}
+	return h.repo.FinishJobRun(ctx, run.ID, run.Status, errMsg, finishedAt)
+}
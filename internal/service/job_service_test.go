@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestNewSchedulerSchedules(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules map[string]string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "intervals",
+			want: map[string]string{
+				JobWhitelistSync:             "@every 15m0s",
+				JobEventPartitionMaintenance: "@every 1h0m0s",
+				JobDeletedEventsPurge:        "@every 1h0m0s",
+				JobDeadLetterPurge:           "@every 1h0m0s",
+				JobHistoryPurge:              "@every 24h0m0s",
+			},
+		},
+		{
+			name:      "overrides",
+			schedules: map[string]string{JobDeletedEventsPurge: "0 3 * * *", JobWhitelistSync: config.JobScheduleOff, JobListExpiryCleanup: "@hourly"},
+			want: map[string]string{
+				JobEventPartitionMaintenance: "@every 1h0m0s",
+				JobDeletedEventsPurge:        "0 3 * * *",
+				JobDeadLetterPurge:           "@every 1h0m0s",
+				JobListExpiryCleanup:         "@hourly",
+				JobHistoryPurge:              "@every 24h0m0s",
+			},
+		},
+		{
+			name:      "unknown job",
+			schedules: map[string]string{"archive": "@daily"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Camera.WhitelistSyncInterval = 15 * time.Minute
+			cfg.Retention.PurgeInterval = time.Hour
+			cfg.Jobs.TimeZone = "UTC"
+			cfg.Jobs.Schedules = tt.schedules
+			svc, _ := newTestService(t, cfg)
+
+			sched, err := svc.NewScheduler()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewScheduler: %v", err)
+			}
+			got := map[string]string{}
+			for _, job := range sched.Jobs() {
+				got[job.Name] = job.Spec
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("jobs = %v, want %v", got, tt.want)
+			}
+			for name, spec := range tt.want {
+				if got[name] != spec {
+					t.Fatalf("job %s schedule = %q, want %q (all: %v)", name, got[name], spec, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTriggerJob(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Jobs.TimeZone = "UTC"
+	cfg.Jobs.HistoryRetention = 24 * time.Hour
+	svc, store := newTestService(t, cfg)
+	if _, err := svc.NewScheduler(); err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+
+	admin := model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin}
+	ctx := model.WithPrincipal(context.Background(), admin)
+
+	if _, err := svc.TriggerJob(model.WithPrincipal(context.Background(), model.Principal{Role: model.UserRoleAkimatUser}), JobHistoryPurge); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := svc.TriggerJob(ctx, "archive"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	finished := make(chan struct{})
+	store.EXPECT().CreateJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *repository.JobRun) error {
+		if run.Job != JobHistoryPurge || run.Trigger != "manual" || run.TriggeredBy == nil || *run.TriggeredBy != admin.UserID {
+			t.Errorf("unexpected job run: %+v", run)
+		}
+		return nil
+	})
+	store.EXPECT().PurgeJobRuns(gomock.Any(), testNow.Add(-24*time.Hour)).Return(int64(3), nil)
+	store.EXPECT().FinishJobRun(gomock.Any(), gomock.Any(), "succeeded", nil, testNow).DoAndReturn(
+		func(context.Context, uuid.UUID, string, *string, time.Time) error {
+			close(finished)
+			return nil
+		})
+
+	run, err := svc.TriggerJob(ctx, JobHistoryPurge)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	if run.Status != "running" || run.Job != JobHistoryPurge {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not finish")
+	}
+}
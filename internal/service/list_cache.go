@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// listMembershipCache — членство номеров в списках в памяти. На горячем пути приёма событий
+// проверка чёрного списка не делает запросов к БД. Кэш сбрасывается при изменении списков через сервис,
+// а изменения из других реплик и из SQL (anpr_sync_vehicle_to_whitelist) подхватываются по версии
+// данных lists в RunListCacheRefresh. Срок действия записей проверяется при каждом обращении, поэтому
+// начало и истечение временной записи не требуют перезагрузки.
+type listMembershipCache struct {
+	// loadMu не даёт параллельным событиям загружать кэш одновременно после сброса
+	loadMu sync.Mutex
+
+	mu         sync.RWMutex
+	loaded     bool
+	version    int64
+	generation uint64
+	membership map[uuid.UUID][]repository.ListMembership
+}
+
+// lookup возвращает списки, записи которых о номере действуют в момент now
+func (c *listMembershipCache) lookup(plateID uuid.UUID, now time.Time) ([]anpr.ListHit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.loaded {
+		return nil, false
+	}
+	var hits []anpr.ListHit
+	for _, m := range c.membership[plateID] {
+		if m.ActiveAt(now) {
+			hits = append(hits, m.ListHit)
+		}
+	}
+	return hits, true
+}
+
+// begin возвращает поколение кэша перед загрузкой
+func (c *listMembershipCache) begin() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// store сохраняет загруженные данные, если кэш не сбрасывали после begin
+// (иначе данные могли устареть ещё во время загрузки)
+func (c *listMembershipCache) store(generation uint64, version int64, membership map[uuid.UUID][]repository.ListMembership) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return false
+	}
+	c.loaded = true
+	c.version = version
+	c.membership = membership
+	return true
+}
+
+func (c *listMembershipCache) invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.loaded = false
+	c.membership = nil
+	c.mu.Unlock()
+}
+
+// current возвращает версию загруженных данных (false — кэш пуст)
+func (c *listMembershipCache) current() (int64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version, c.loaded
+}
+
+// findListsForPlate возвращает списки, в которых состоит номер: из кэша, а при выключенном кэше
+// или ошибке его загрузки — запросом к БД
+func (s *ANPRService) findListsForPlate(ctx context.Context, plateID uuid.UUID) ([]anpr.ListHit, error) {
+	if !s.Config().Lists.CacheEnabled {
+		return s.repo.FindListsForPlate(ctx, plateID)
+	}
+	if hits, ok := s.lists.lookup(plateID, s.clock.Now()); ok {
+		return hits, nil
+	}
+
+	s.lists.loadMu.Lock()
+	defer s.lists.loadMu.Unlock()
+	if hits, ok := s.lists.lookup(plateID, s.clock.Now()); ok {
+		return hits, nil
+	}
+	if err := s.reloadListCache(ctx); err != nil {
+		s.logger(ctx).Warn().Err(err).Msg("failed to load list membership cache, querying database")
+		return s.repo.FindListsForPlate(ctx, plateID)
+	}
+	if hits, ok := s.lists.lookup(plateID, s.clock.Now()); ok {
+		return hits, nil
+	}
+	// Кэш сбросили во время загрузки
+	return s.repo.FindListsForPlate(ctx, plateID)
+}
+
+// reloadListCache загружает членство в списках целиком. Версия читается до загрузки: если списки
+// изменятся во время загрузки, следующая проверка версии перезагрузит кэш ещё раз.
+func (s *ANPRService) reloadListCache(ctx context.Context) error {
+	generation := s.lists.begin()
+	version, err := s.repo.GetDataVersion(ctx, repository.DataVersionScopeLists)
+	if err != nil {
+		return err
+	}
+	membership, err := s.repo.LoadListMembership(ctx)
+	if err != nil {
+		return err
+	}
+	if !s.lists.store(generation, version, membership) {
+		return nil
+	}
+	s.logger(ctx).Debug().Int64("version", version).Int("plates", len(membership)).Msg("list membership cache loaded")
+	return nil
+}
+
+// InvalidateListCache сбрасывает кэш членства в списках; следующая проверка номера загрузит его заново
+func (s *ANPRService) InvalidateListCache() {
+	s.lists.invalidate()
+}
+
+// RunListCacheRefresh с периодом interval сверяет версию данных lists и перезагружает кэш,
+// если списки изменились в обход сервиса. Блокируется до отмены ctx.
+func (s *ANPRService) RunListCacheRefresh(ctx context.Context, interval time.Duration) {
+	if !s.Config().Lists.CacheEnabled || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cached, loaded := s.lists.current()
+		if !loaded {
+			continue
+		}
+		version, err := s.repo.GetDataVersion(ctx, repository.DataVersionScopeLists)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger(ctx).Warn().Err(err).Msg("failed to check lists data version")
+			}
+			continue
+		}
+		if version == cached {
+			continue
+		}
+		s.lists.loadMu.Lock()
+		err = s.reloadListCache(ctx)
+		s.lists.loadMu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			s.logger(ctx).Warn().Err(err).Msg("failed to refresh list membership cache")
+			s.lists.invalidate()
+		}
+	}
+}
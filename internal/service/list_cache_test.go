@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func TestListMembershipCache(t *testing.T) {
+	var c listMembershipCache
+	plateID := uuid.New()
+	hits := []repository.ListMembership{{ListHit: anpr.ListHit{ListID: uuid.New(), ListName: "default_blacklist", ListType: "BLACKLIST"}}}
+
+	if _, ok := c.lookup(plateID, testNow); ok {
+		t.Fatal("lookup on empty cache = ok")
+	}
+
+	if !c.store(c.begin(), 7, map[uuid.UUID][]repository.ListMembership{plateID: hits}) {
+		t.Fatal("store() = false on fresh generation")
+	}
+	if got, ok := c.lookup(plateID, testNow); !ok || len(got) != 1 {
+		t.Fatalf("lookup() = %v, %v", got, ok)
+	}
+	if got, ok := c.lookup(uuid.New(), testNow); !ok || len(got) != 0 {
+		t.Errorf("lookup(unknown plate) = %v, %v; want no hits from loaded cache", got, ok)
+	}
+	if version, ok := c.current(); !ok || version != 7 {
+		t.Errorf("current() = %d, %v", version, ok)
+	}
+
+	// Сброс во время загрузки: данные загрузки не сохраняются
+	generation := c.begin()
+	c.invalidate()
+	if c.store(generation, 8, map[uuid.UUID][]repository.ListMembership{}) {
+		t.Error("store() after invalidate = true, want stale load discarded")
+	}
+	if _, ok := c.lookup(plateID, testNow); ok {
+		t.Error("lookup after invalidate = ok")
+	}
+}
+
+func TestListMembershipCacheValidity(t *testing.T) {
+	var c listMembershipCache
+	plateID := uuid.New()
+	from := testNow.Add(-time.Hour)
+	until := testNow.Add(time.Hour)
+	c.store(c.begin(), 1, map[uuid.UUID][]repository.ListMembership{plateID: {{
+		ListHit:    anpr.ListHit{ListID: uuid.New(), ListName: "rented", ListType: "WHITELIST"},
+		ValidFrom:  &from,
+		ValidUntil: &until,
+	}}})
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "before valid_from", at: from.Add(-time.Minute), want: 0},
+		{name: "at valid_from", at: from, want: 1},
+		{name: "inside window", at: testNow, want: 1},
+		{name: "at valid_until", at: until, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.lookup(plateID, tt.at)
+			if !ok || len(got) != tt.want {
+				t.Errorf("lookup() = %v, %v; want %d hits", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// Форматы файлов обмена списками
+const (
+	ListFileFormatCSV  = "csv"
+	ListFileFormatXLSX = "xlsx"
+)
+
+const (
+	// listImportMaxRows — сколько строк с номерами принимается из одного файла
+	listImportMaxRows = 10000
+	// listExchangeSheet — лист XLSX-выгрузки
+	listExchangeSheet = "List"
+)
+
+// listExchangeHeader — колонки файла обмена; импорт принимает и русские, и казахские названия (см. listImportColumns)
+var listExchangeHeader = []string{"plate", "note", "valid_from", "valid_until", "added_at"}
+
+// listImportColumns — названия колонок файла импорта (в нижнем регистре) и поле записи списка
+var listImportColumns = map[string]string{
+	"plate": "plate", "plate_number": "plate", "номер": "plate", "госномер": "plate", "гос. номер": "plate",
+	"гос номер": "plate", "нөмір": "plate", "мемлекеттік нөмір": "plate",
+	"note": "note", "примечание": "note", "комментарий": "note", "причина": "note", "ескертпе": "note",
+	"valid_from": "valid_from", "действует с": "valid_from",
+	"valid_until": "valid_until", "действует до": "valid_until",
+}
+
+// listImportDateLayouts — форматы дат в файле импорта; время без смещения — по CAMERA_DEFAULT_TIMEZONE
+var listImportDateLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	"02.01.2006 15:04:05", "02.01.2006 15:04", "02.01.2006",
+	"01-02-06", "1/2/06",
+}
+
+// ListImportReport — итог загрузки файла в список
+type ListImportReport struct {
+	ListID string `json:"list_id"`
+	DryRun bool   `json:"dry_run"`
+	// Rows — строк с номерами (без заголовка и пустых строк)
+	Rows int `json:"rows"`
+	// Imported — номеров добавлено в список или обновлено (при dry_run — будет)
+	Imported int `json:"imported"`
+	// Duplicates — повторы номера в файле; учитывается первая строка
+	Duplicates int `json:"duplicates"`
+	// Errors — строки, которые не загружены
+	Errors []ListImportError `json:"errors"`
+}
+
+// ListImportError — ошибка строки файла импорта
+type ListImportError struct {
+	// Row — номер строки в файле (с 1, включая заголовок)
+	Row   int    `json:"row"`
+	Plate string `json:"plate,omitempty"`
+	Error string `json:"error"`
+}
+
+// listImportRow — проверенная строка файла импорта
+type listImportRow struct {
+	row        int
+	input      ListEntryInput
+	normalized string
+	country    string
+	region     string
+}
+
+// ExportListFile выгружает номера списка в CSV или XLSX для обмена с ведомствами без интеграции по API.
+// Колонки совпадают с форматом импорта, поэтому выгруженный файл можно загрузить в другой список.
+func (s *ANPRService) ExportListFile(ctx context.Context, listID uuid.UUID, format string) ([]byte, string, error) {
+	if format == "" {
+		format = ListFileFormatCSV
+	}
+	if format != ListFileFormatCSV && format != ListFileFormatXLSX {
+		return nil, "", fmt.Errorf("%w: format must be %q or %q", ErrInvalidInput, ListFileFormatCSV, ListFileFormatXLSX)
+	}
+	list, err := s.repo.GetList(ctx, listID)
+	if err != nil {
+		return nil, "", err
+	}
+	entries, err := s.repo.GetListEntries(ctx, listID, 0, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := make([][]string, 0, len(entries)+1)
+	records = append(records, listExchangeHeader)
+	for _, e := range entries {
+		records = append(records, []string{e.Number, stringOrEmpty(e.Note), formatOptionalTime(e.ValidFrom), formatOptionalTime(e.ValidUntil), e.CreatedAt.UTC().Format(time.RFC3339)})
+	}
+
+	var data []byte
+	if format == ListFileFormatXLSX {
+		data, err = encodeListXLSX(records)
+	} else {
+		data, err = encodeListCSV(records)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode list: %w", err)
+	}
+	filename := fmt.Sprintf("anpr-list_%s_%s.%s", listFileSlug(list.Name), s.clock.Now().Format("2006-01-02"), format)
+	return data, filename, nil
+}
+
+// ImportListFile загружает номера из CSV или XLSX в список: формат определяется по содержимому, заголовок
+// необязателен (без него колонки идут в порядке plate, note, valid_from, valid_until). Строки с ошибками
+// пропускаются и попадают в отчёт, остальные добавляются как через AddListEntry. С dryRun файл только проверяется.
+func (s *ANPRService) ImportListFile(ctx context.Context, listID uuid.UUID, filename string, data []byte, dryRun bool) (*ListImportReport, error) {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := decodeListFile(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	rows, report := s.validateListImport(records)
+	report.ListID = listID.String()
+	report.DryRun = dryRun
+	if report.Rows > listImportMaxRows {
+		return nil, fmt.Errorf("%w: file has %d rows, maximum allowed is %d", ErrInvalidInput, report.Rows, listImportMaxRows)
+	}
+	if dryRun {
+		report.Imported = len(rows)
+		return report, nil
+	}
+
+	for _, row := range rows {
+		plateID, err := s.repo.GetOrCreatePlate(ctx, row.normalized, row.input.Plate, row.country, row.region)
+		if err == nil {
+			err = s.repo.UpsertListItem(ctx, &repository.ListItem{
+				ListID:     listID,
+				PlateID:    plateID,
+				Note:       trimNote(row.input.Note),
+				ValidFrom:  row.input.ValidFrom,
+				ValidUntil: row.input.ValidUntil,
+			})
+		}
+		if err != nil {
+			// Уже загруженные строки остаются в списке: повторная загрузка того же файла их только обновит
+			s.InvalidateListCache()
+			return nil, fmt.Errorf("failed to import row %d: %w", row.row, err)
+		}
+		report.Imported++
+	}
+	if report.Imported > 0 {
+		s.InvalidateListCache()
+	}
+
+	s.logger(ctx).Info().
+		Str("list", list.Name).
+		Str("file", filename).
+		Int("rows", report.Rows).
+		Int("imported", report.Imported).
+		Int("errors", len(report.Errors)).
+		Msg("list imported from file")
+	return report, nil
+}
+
+// validateListImport разбирает строки файла: номер обязателен и проходит проверку PLATE_*, срок действия —
+// как у AddListEntry. Повторы номера не считаются ошибкой.
+func (s *ANPRService) validateListImport(records [][]string) ([]listImportRow, *ListImportReport) {
+	report := &ListImportReport{Errors: []ListImportError{}}
+	if len(records) == 0 {
+		return nil, report
+	}
+
+	columns := map[string]int{"plate": 0, "note": 1, "valid_from": 2, "valid_until": 3}
+	first := 0
+	if header := listImportHeader(records[0]); header != nil {
+		columns, first = header, 1
+	}
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	loc := s.defaultCameraLocation()
+	seen := make(map[string]bool)
+	var rows []listImportRow
+	for i := first; i < len(records); i++ {
+		record := records[i]
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		report.Rows++
+		rowNum := i + 1
+		raw := cell(record, "plate")
+		fail := func(format string, args ...any) {
+			report.Errors = append(report.Errors, ListImportError{Row: rowNum, Plate: raw, Error: fmt.Sprintf(format, args...)})
+		}
+
+		plate := utils.ParsePlate(raw)
+		if plate.Normalized == "" {
+			fail("plate is empty")
+			continue
+		}
+		if violation := plateViolation(plate.Normalized, s.plateRule(plate.Country)); violation != "" {
+			fail("%s", violation)
+			continue
+		}
+		validFrom, err := parseListImportTime(cell(record, "valid_from"), loc, false)
+		if err != nil {
+			fail("invalid valid_from: %v", err)
+			continue
+		}
+		validUntil, err := parseListImportTime(cell(record, "valid_until"), loc, true)
+		if err != nil {
+			fail("invalid valid_until: %v", err)
+			continue
+		}
+		if err := s.validateListItemValidity(validFrom, validUntil); err != nil {
+			fail("%s", strings.TrimPrefix(err.Error(), ErrInvalidInput.Error()+": "))
+			continue
+		}
+		if seen[plate.Normalized] {
+			report.Duplicates++
+			continue
+		}
+		seen[plate.Normalized] = true
+
+		var note *string
+		if text := cell(record, "note"); text != "" {
+			note = &text
+		}
+		rows = append(rows, listImportRow{
+			row:        rowNum,
+			input:      ListEntryInput{Plate: raw, Note: note, ValidFrom: validFrom, ValidUntil: validUntil},
+			normalized: plate.Normalized,
+			country:    plate.Country,
+			region:     plate.Region,
+		})
+	}
+	return rows, report
+}
+
+// listImportHeader возвращает колонки по заголовку или nil, если первая строка — не заголовок (в ней нет колонки номера)
+func listImportHeader(record []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := listImportColumns[name]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["plate"]; !ok {
+		return nil
+	}
+	return columns
+}
+
+// parseListImportTime разбирает срок действия. Дата без времени в valid_until включает весь день.
+func parseListImportTime(value string, loc *time.Location, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	for _, layout := range listImportDateLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		if endOfDay && !strings.Contains(layout, "15") {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%q is not a date (use RFC3339, YYYY-MM-DD or DD.MM.YYYY)", value)
+}
+
+// decodeListFile читает строки CSV или первого листа XLSX. XLSX узнаётся по сигнатуре ZIP, CSV — с
+// разделителем «,» или «;» (его сохраняет Excel с русскими региональными настройками).
+func decodeListFile(filename string, data []byte) ([][]string, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidInput)
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot read xlsx: %v", ErrInvalidInput, err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("%w: xlsx has no sheets", ErrInvalidInput)
+		}
+		rows, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot read xlsx: %v", ErrInvalidInput, err)
+		}
+		return rows, nil
+	}
+	if ext := strings.ToLower(filepath.Ext(filename)); ext == ".xls" || ext == ".xlsx" {
+		return nil, fmt.Errorf("%w: file is not a valid xlsx (save .xls as .xlsx or csv)", ErrInvalidInput)
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: csv must be UTF-8 encoded", ErrInvalidInput)
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read csv: %v", ErrInvalidInput, err)
+	}
+	return records, nil
+}
+
+func encodeListCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeListXLSX(records [][]string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", listExchangeSheet); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(listExchangeSheet)
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		row := make([]interface{}, len(record))
+		for j, value := range record {
+			row[j] = value
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := sw.SetRow(cell, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// listFileSlug — имя списка для имени файла: буквы и цифры, остальное заменяется на «-»
+func listFileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "list"
+	}
+	return slug
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestImportListFile(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhAdmin})
+	listID := uuid.New()
+	// Excel с русскими региональными настройками сохраняет CSV с «;»
+	file := "\ufeffГосномер;Примечание;Действует до\n" +
+		"123 ABC 02;штраф;20.01.2025\n" +
+		";;\n" +
+		"1;;\n" +
+		"777AAA02;;2025-01-01\n" +
+		"123ABC02;повтор;\n" +
+		"555 BBB 01;;\n"
+
+	svc, store := newTestService(t, nil)
+	store.EXPECT().GetList(gomock.Any(), listID).Return(&repository.List{ID: listID, Name: "kgu_blacklist"}, nil)
+	store.EXPECT().GetOrCreatePlate(gomock.Any(), "123ABC02", "123 ABC 02", gomock.Any(), gomock.Any()).Return(uuid.New(), nil)
+	store.EXPECT().GetOrCreatePlate(gomock.Any(), "555BBB01", "555 BBB 01", gomock.Any(), gomock.Any()).Return(uuid.New(), nil)
+	store.EXPECT().UpsertListItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, item *repository.ListItem) error {
+		if item.ListID != listID {
+			t.Fatalf("unexpected list item: %+v", item)
+		}
+		return nil
+	}).Times(2)
+
+	report, err := svc.ImportListFile(admin, listID, "blacklist.csv", []byte(file), false)
+	if err != nil {
+		t.Fatalf("ImportListFile() error = %v", err)
+	}
+	if report.Rows != 5 || report.Imported != 2 || report.Duplicates != 1 || len(report.Errors) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Errors[0].Row != 4 || report.Errors[1].Row != 5 || report.Errors[1].Error != "valid_until is in the past" {
+		t.Fatalf("unexpected errors: %+v", report.Errors)
+	}
+}
+
+func TestImportListFileDryRun(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	listID := uuid.New()
+	svc, store := newTestService(t, nil)
+	store.EXPECT().GetList(gomock.Any(), listID).Return(&repository.List{ID: listID, Name: "kgu_blacklist"}, nil)
+
+	// Без заголовка колонки идут в порядке plate, note, valid_from, valid_until
+	report, err := svc.ImportListFile(admin, listID, "list.csv", []byte("123ABC02,note,,2025-01-20T00:00:00+05:00\n"), true)
+	if err != nil {
+		t.Fatalf("ImportListFile() error = %v", err)
+	}
+	if !report.DryRun || report.Rows != 1 || report.Imported != 1 || len(report.Errors) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	inspector := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatUser})
+	if _, err := svc.ImportListFile(inspector, listID, "list.csv", []byte("123ABC02\n"), true); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("ImportListFile() error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestExportListFileRoundTrip(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	listID, targetID := uuid.New(), uuid.New()
+	note := "штраф"
+	until := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	svc, store := newTestService(t, nil)
+	store.EXPECT().GetList(gomock.Any(), listID).Return(&repository.List{ID: listID, Name: "KGU blacklist"}, nil)
+	store.EXPECT().GetListEntries(gomock.Any(), listID, 0, 0).Return([]repository.ListEntry{
+		{PlateID: uuid.New(), Number: "123 ABC 02", Normalized: "123ABC02", Note: &note, ValidUntil: &until, CreatedAt: testNow},
+	}, nil)
+
+	data, filename, err := svc.ExportListFile(context.Background(), listID, ListFileFormatXLSX)
+	if err != nil {
+		t.Fatalf("ExportListFile() error = %v", err)
+	}
+	if filename != "anpr-list_kgu-blacklist_2025-01-15.xlsx" {
+		t.Fatalf("filename = %q", filename)
+	}
+
+	store.EXPECT().GetList(gomock.Any(), targetID).Return(&repository.List{ID: targetID, Name: "copy"}, nil)
+	store.EXPECT().GetOrCreatePlate(gomock.Any(), "123ABC02", "123 ABC 02", gomock.Any(), gomock.Any()).Return(uuid.New(), nil)
+	store.EXPECT().UpsertListItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, item *repository.ListItem) error {
+		if item.Note == nil || *item.Note != note || item.ValidUntil == nil || !item.ValidUntil.Equal(until) || item.ValidFrom != nil {
+			t.Fatalf("unexpected list item: %+v", item)
+		}
+		return nil
+	})
+	report, err := svc.ImportListFile(admin, targetID, filename, data, false)
+	if err != nil || report.Imported != 1 {
+		t.Fatalf("ImportListFile() = %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// ListInfo — список номеров (whitelist/blacklist) для API
+type ListInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description *string   `json:"description,omitempty"`
+	ItemCount   int64     `json:"item_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListEntryInfo — номер в списке
+type ListEntryInfo struct {
+	PlateID    string    `json:"plate_id"`
+	Plate      string    `json:"plate"`
+	Normalized string    `json:"normalized"`
+	Note       *string   `json:"note,omitempty"`
+	AddedAt    time.Time `json:"added_at"`
+	// ValidFrom, ValidUntil — срок действия временной записи; нет — без ограничения
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+// ListEntryInput — номер, добавляемый в список
+type ListEntryInput struct {
+	Plate      string
+	Note       *string
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+// ListLists возвращает списки номеров с количеством записей
+func (s *ANPRService) ListLists(ctx context.Context) ([]ListInfo, error) {
+	lists, err := s.repo.ListLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ListInfo, 0, len(lists))
+	for _, l := range lists {
+		result = append(result, ListInfo{
+			ID:          l.ID.String(),
+			Name:        l.Name,
+			Type:        l.Type,
+			Description: l.Description,
+			ItemCount:   l.ItemCount,
+			CreatedAt:   l.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// GetListEntries возвращает номера списка с пагинацией (limit по умолчанию 100, максимум 1000)
+func (s *ANPRService) GetListEntries(ctx context.Context, listID uuid.UUID, limit, offset int) ([]ListEntryInfo, error) {
+	if _, err := s.repo.GetList(ctx, listID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.repo.GetListEntries(ctx, listID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ListEntryInfo, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, toListEntryInfo(e))
+	}
+	return result, nil
+}
+
+// AddListEntry добавляет номер в список. Если номер уже в списке, меняется срок действия записи
+// (и примечание, если оно передано).
+func (s *ANPRService) AddListEntry(ctx context.Context, listID uuid.UUID, input ListEntryInput) (*ListEntryInfo, error) {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return nil, err
+	}
+	plate := utils.ParsePlate(input.Plate)
+	normalized := plate.Normalized
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: plate is empty", ErrInvalidInput)
+	}
+	if err := s.validateListItemValidity(input.ValidFrom, input.ValidUntil); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	plateID, err := s.repo.GetOrCreatePlate(ctx, normalized, input.Plate, plate.Country, plate.Region)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.UpsertListItem(ctx, &repository.ListItem{
+		ListID:     listID,
+		PlateID:    plateID,
+		Note:       trimNote(input.Note),
+		ValidFrom:  input.ValidFrom,
+		ValidUntil: input.ValidUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.InvalidateListCache()
+
+	s.logger(ctx).Info().
+		Str("list", list.Name).
+		Str("plate", normalized).
+		Bool("temporary", input.ValidFrom != nil || input.ValidUntil != nil).
+		Msg("plate added to list")
+	return s.getListEntry(ctx, listID, plateID)
+}
+
+// SetListEntryValidity меняет срок действия записи списка; nil снимает ограничение
+func (s *ANPRService) SetListEntryValidity(ctx context.Context, listID, plateID uuid.UUID, validFrom, validUntil *time.Time) (*ListEntryInfo, error) {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return nil, err
+	}
+	if err := s.validateListItemValidity(validFrom, validUntil); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.SetListItemValidity(ctx, listID, plateID, validFrom, validUntil)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrNotFound
+	}
+	s.InvalidateListCache()
+	return s.getListEntry(ctx, listID, plateID)
+}
+
+// RemoveListEntry убирает номер из списка
+func (s *ANPRService) RemoveListEntry(ctx context.Context, listID, plateID uuid.UUID) error {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return err
+	}
+	removed, err := s.repo.RemoveListItem(ctx, listID, plateID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotFound
+	}
+	s.InvalidateListCache()
+	s.logger(ctx).Info().Str("list_id", listID.String()).Str("plate_id", plateID.String()).Msg("plate removed from list")
+	return nil
+}
+
+// DeleteExpiredListItems удаляет истёкшие записи списков. Решения о доступе не зависят от очистки
+// (срок проверяется при каждом обращении): она только не даёт спискам разрастаться.
+func (s *ANPRService) DeleteExpiredListItems(ctx context.Context) error {
+	deleted, err := s.repo.DeleteExpiredListItems(ctx, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		s.InvalidateListCache()
+		s.logger(ctx).Info().Int64("deleted", deleted).Msg("expired list items deleted")
+	}
+	return nil
+}
+
+// validateListItemValidity проверяет срок действия записи: конец позже начала и ещё не наступил
+func (s *ANPRService) validateListItemValidity(validFrom, validUntil *time.Time) error {
+	if validUntil == nil {
+		return nil
+	}
+	if validFrom != nil && !validUntil.After(*validFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidInput)
+	}
+	if !validUntil.After(s.clock.Now()) {
+		return fmt.Errorf("%w: valid_until is in the past", ErrInvalidInput)
+	}
+	return nil
+}
+
+func (s *ANPRService) getListEntry(ctx context.Context, listID, plateID uuid.UUID) (*ListEntryInfo, error) {
+	entry, err := s.repo.GetListEntry(ctx, listID, plateID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrNotFound
+	}
+	info := toListEntryInfo(*entry)
+	return &info, nil
+}
+
+func toListEntryInfo(e repository.ListEntry) ListEntryInfo {
+	return ListEntryInfo{
+		PlateID:    e.PlateID.String(),
+		Plate:      e.Number,
+		Normalized: e.Normalized,
+		Note:       e.Note,
+		AddedAt:    e.CreatedAt,
+		ValidFrom:  e.ValidFrom,
+		ValidUntil: e.ValidUntil,
+	}
+}
+
+// DataVersion возвращает версию данных области (repository.DataVersionScope*).
+// Версия только растёт, поэтому опрашивающему клиенту достаточно сравнить её с сохранённой.
+func (s *ANPRService) DataVersion(ctx context.Context, scope string) (int64, error) {
+	return s.repo.GetDataVersion(ctx, scope)
+}
+
+// DataVersions возвращает версии данных нескольких областей — для ответов, собранных из данных разных областей
+func (s *ANPRService) DataVersions(ctx context.Context, scopes ...string) ([]repository.DataVersion, error) {
+	return s.repo.GetDataVersions(ctx, scopes)
+}
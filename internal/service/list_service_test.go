@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+func TestAddListEntryTemporary(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhAdmin})
+	listID := uuid.New()
+	plateID := uuid.New()
+	tonight := testNow.Add(12 * time.Hour)
+	earlier := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		validFrom  *time.Time
+		validUntil *time.Time
+		wantErr    error
+	}{
+		{name: "one night", validFrom: &earlier, validUntil: &tonight},
+		{name: "until before from", validFrom: &tonight, validUntil: &earlier, wantErr: ErrInvalidInput},
+		{name: "already expired", validUntil: &earlier, wantErr: ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			if tt.wantErr == nil {
+				store.EXPECT().GetList(gomock.Any(), listID).Return(&repository.List{ID: listID, Name: "default_whitelist"}, nil)
+				store.EXPECT().GetOrCreatePlate(gomock.Any(), "123ABC02", "123 abc-02", utils.PlateCountryKZ, "02").Return(plateID, nil)
+				store.EXPECT().UpsertListItem(gomock.Any(), &repository.ListItem{
+					ListID: listID, PlateID: plateID, ValidFrom: tt.validFrom, ValidUntil: tt.validUntil,
+				}).Return(nil)
+				store.EXPECT().GetListEntry(gomock.Any(), listID, plateID).Return(&repository.ListEntry{
+					PlateID: plateID, Normalized: "123ABC02", ValidFrom: tt.validFrom, ValidUntil: tt.validUntil,
+				}, nil)
+			}
+
+			entry, err := svc.AddListEntry(admin, listID, ListEntryInput{Plate: "123 abc-02", ValidFrom: tt.validFrom, ValidUntil: tt.validUntil})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AddListEntry() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddListEntry() error = %v", err)
+			}
+			if entry.ValidUntil == nil || !entry.ValidUntil.Equal(tonight) {
+				t.Errorf("valid_until = %v, want %v", entry.ValidUntil, tonight)
+			}
+		})
+	}
+}
+
+func TestAddListEntryRequiresListManager(t *testing.T) {
+	svc, _ := newTestService(t, nil)
+	user := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatUser})
+	if _, err := svc.AddListEntry(user, uuid.New(), ListEntryInput{Plate: "123ABC02"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("AddListEntry() error = %v, want ErrForbidden", err)
+	}
+}
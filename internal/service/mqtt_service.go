@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/mqtt"
+	"anpr-service/internal/repository"
+)
+
+// MQTTPublisher публикует сообщение в топик брокера (реализуется *mqtt.Publisher)
+type MQTTPublisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+}
+
+// mqttEventMessage — компактное сообщение о событии для SCADA
+type mqttEventMessage struct {
+	EventID      uuid.UUID `json:"id"`
+	Plate        string    `json:"plate"`
+	CameraID     string    `json:"camera"`
+	Direction    string    `json:"dir"`
+	Time         int64     `json:"ts"` // Unix-секунды времени события
+	Decision     string    `json:"decision,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	MatchedSnow  bool      `json:"snow"`
+	SnowVolumeM3 *float64  `json:"volume_m3,omitempty"`
+	Lists        []string  `json:"lists,omitempty"` // типы списков номера
+}
+
+// EnqueueMQTT — обработчик топика eventbus.TopicEventCreated: ставит компактное сообщение о событии
+// в outbox MQTT, откуда его публикует RunMQTTRelay
+func (s *ANPRService) EnqueueMQTT(ctx context.Context, topic string, payload []byte) error {
+	var msg EventCreatedMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return fmt.Errorf("decode %s message: %w", topic, err)
+	}
+
+	body, err := json.Marshal(compactMQTTMessage(msg))
+	if err != nil {
+		return fmt.Errorf("encode mqtt message: %w", err)
+	}
+	return s.repo.EnqueueMQTTMessage(ctx, &repository.MQTTMessage{
+		EventID: msg.EventID,
+		Topic:   mqtt.Topic(s.Config().MQTT.TopicPrefix, msg.CameraID),
+		Payload: body,
+	})
+}
+
+func compactMQTTMessage(msg EventCreatedMessage) mqttEventMessage {
+	compact := mqttEventMessage{
+		EventID:      msg.EventID,
+		Plate:        msg.Plate,
+		CameraID:     msg.CameraID,
+		Direction:    msg.Direction,
+		Time:         msg.EventTime.Unix(),
+		MatchedSnow:  msg.MatchedSnow,
+		SnowVolumeM3: msg.SnowVolumeM3,
+	}
+	if msg.Decision != nil {
+		compact.Decision = msg.Decision.Decision
+		compact.Reason = msg.Decision.Reason
+	}
+	for _, hit := range msg.Lists {
+		if !slices.Contains(compact.Lists, hit.ListType) {
+			compact.Lists = append(compact.Lists, hit.ListType)
+		}
+	}
+	return compact
+}
+
+// RunMQTTRelay с периодом interval публикует сообщения из outbox MQTT. Блокируется до отмены ctx.
+func (s *ANPRService) RunMQTTRelay(ctx context.Context, interval time.Duration, publisher MQTTPublisher) {
+	if publisher == nil || interval <= 0 {
+		return
+	}
+	s.runRelay(ctx, "mqtt", interval, s.Config().MQTT.BatchSize, func(ctx context.Context) (int, error) {
+		return s.publishMQTT(ctx, publisher)
+	})
+}
+
+// publishMQTT публикует одну пачку сообщений и возвращает её размер
+func (s *ANPRService) publishMQTT(ctx context.Context, publisher MQTTPublisher) (int, error) {
+	messages, err := s.repo.ClaimMQTTMessages(ctx, s.Config().MQTT.BatchSize, 2*s.Config().MQTT.PublishTimeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	retry := relayRetry{
+		MaxAttempts: s.Config().MQTT.MaxAttempts,
+		BackoffBase: s.Config().MQTT.BackoffBase,
+		BackoffMax:  s.Config().MQTT.BackoffMax,
+	}
+	for _, m := range messages {
+		if err := publisher.Publish(ctx, m.Topic, m.Payload); err != nil {
+			attempt := m.Attempts + 1
+			next := retry.next(s.clock.Now(), attempt)
+			if markErr := s.repo.MarkMQTTAttemptFailed(ctx, m.ID, err.Error(), next); markErr != nil {
+				s.logger(ctx).Warn().Err(markErr).Str("message_id", m.ID.String()).Msg("failed to record mqtt attempt")
+			}
+			event := s.logger(ctx).Warn().Err(err).Str("event_id", m.EventID.String()).Str("topic", m.Topic).Int("attempt", attempt)
+			if next == nil {
+				event.Msg("mqtt publish failed permanently")
+			} else {
+				event.Time("next_attempt_at", *next).Msg("mqtt publish failed, will retry")
+			}
+			continue
+		}
+		if err := s.repo.MarkMQTTMessageSent(ctx, m.ID); err != nil {
+			s.logger(ctx).Warn().Err(err).Str("message_id", m.ID.String()).Msg("failed to mark mqtt message sent")
+		}
+	}
+	return len(messages), nil
+}
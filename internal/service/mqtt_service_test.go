@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func TestEnqueueMQTT(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MQTT.TopicPrefix = "snowops/anpr"
+	svc, store := newTestService(t, cfg)
+
+	volume := 12.5
+	event := EventCreatedMessage{
+		EventID:      uuid.New(),
+		Plate:        "123ABC02",
+		CameraID:     "gate/1",
+		Direction:    "entry",
+		EventTime:    testNow,
+		MatchedSnow:  true,
+		SnowVolumeM3: &volume,
+		Decision:     &anpr.AccessDecision{Decision: "ALLOW", Reason: "registered_vehicle"},
+		Lists:        []anpr.ListHit{{ListType: "WHITELIST"}, {ListType: "WHITELIST"}},
+	}
+	payload, _ := json.Marshal(event)
+
+	store.EXPECT().EnqueueMQTTMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, msg *repository.MQTTMessage) error {
+		if msg.EventID != event.EventID || msg.Topic != "snowops/anpr/gate_1" {
+			t.Errorf("message = %s %s, want event id and per-camera topic", msg.EventID, msg.Topic)
+		}
+		want := `{"id":"` + event.EventID.String() + `","plate":"123ABC02","camera":"gate/1","dir":"entry","ts":1736978400,` +
+			`"decision":"ALLOW","reason":"registered_vehicle","snow":true,"volume_m3":12.5,"lists":["WHITELIST"]}`
+		if string(msg.Payload) != want {
+			t.Errorf("payload = %s\nwant %s", msg.Payload, want)
+		}
+		return nil
+	})
+
+	if err := svc.EnqueueMQTT(context.Background(), "anpr.event.created", payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeMQTTPublisher struct {
+	err       error
+	published []string
+}
+
+func (p *fakeMQTTPublisher) Publish(_ context.Context, topic string, _ []byte) error {
+	p.published = append(p.published, topic)
+	return p.err
+}
+
+func TestPublishMQTT(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MQTT = config.MQTTConfig{PublishTimeout: time.Second, MaxAttempts: 2, BackoffBase: time.Second, BackoffMax: time.Minute, BatchSize: 10}
+
+	t.Run("sent", func(t *testing.T) {
+		svc, store := newTestService(t, cfg)
+		msg := repository.MQTTMessage{ID: uuid.New(), Topic: "snowops/anpr/cam-1"}
+		store.EXPECT().ClaimMQTTMessages(gomock.Any(), 10, gomock.Any()).Return([]repository.MQTTMessage{msg}, nil)
+		store.EXPECT().MarkMQTTMessageSent(gomock.Any(), msg.ID).Return(nil)
+
+		pub := &fakeMQTTPublisher{}
+		if n, err := svc.publishMQTT(context.Background(), pub); err != nil || n != 1 {
+			t.Fatalf("publishMQTT() = %d, %v", n, err)
+		}
+		if len(pub.published) != 1 || pub.published[0] != msg.Topic {
+			t.Errorf("published = %v", pub.published)
+		}
+	})
+
+	t.Run("broker down", func(t *testing.T) {
+		svc, store := newTestService(t, cfg)
+		first := repository.MQTTMessage{ID: uuid.New(), Topic: "t"}
+		last := repository.MQTTMessage{ID: uuid.New(), Topic: "t", Attempts: 1}
+		store.EXPECT().ClaimMQTTMessages(gomock.Any(), 10, gomock.Any()).Return([]repository.MQTTMessage{first, last}, nil)
+		next := testNow.Add(time.Second)
+		store.EXPECT().MarkMQTTAttemptFailed(gomock.Any(), first.ID, "broker down", &next).Return(nil)
+		store.EXPECT().MarkMQTTAttemptFailed(gomock.Any(), last.ID, "broker down", gomock.Nil()).Return(nil)
+
+		if _, err := svc.publishMQTT(context.Background(), &fakeMQTTPublisher{err: errors.New("broker down")}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
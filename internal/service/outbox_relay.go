@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anpr-service/internal/lifecycle"
+)
+
+// relayRetry — политика повторов outbox-доставки (вебхуки, MQTT)
+type relayRetry struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// next возвращает время следующей попытки после неудачной попытки номер attempt (с 1);
+// nil — попытки исчерпаны
+func (r relayRetry) next(now time.Time, attempt int) *time.Time {
+	if attempt >= r.MaxAttempts {
+		return nil
+	}
+	t := now.Add(relayBackoff(attempt, r.BackoffBase, r.BackoffMax))
+	return &t
+}
+
+// relayBackoff возвращает паузу перед следующей попыткой: base·2^(attempt-1), не больше max
+func relayBackoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return min(delay, max)
+}
+
+// runRelay — общий цикл outbox-доставки: с периодом interval вызывает drain, который отправляет
+// одну пачку и возвращает её размер. Пока выбираются полные пачки, очередь не пуста — следующая
+// отправляется без ожидания тика. Блокируется до отмены ctx; начатая пачка при этом дописывается
+// (отметки о доставке не теряются, см. lifecycle.Detach).
+func (s *ANPRService) runRelay(ctx context.Context, name string, interval time.Duration, batchSize int, drain func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			batchCtx, cancel := lifecycle.Detach(ctx)
+			n, err := drain(batchCtx)
+			cancel()
+			if err != nil {
+				if batchCtx.Err() == nil {
+					s.logger(ctx).Warn().Err(err).Str("relay", name).Msg("outbox relay failed")
+				}
+				break
+			}
+			if n < batchSize {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelayBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{40, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := relayBackoff(tt.attempt, base, max); got != tt.want {
+			t.Errorf("relayBackoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRelayRetryNext(t *testing.T) {
+	retry := relayRetry{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour}
+	if next := retry.next(testNow, 1); next == nil || !next.Equal(testNow.Add(time.Minute)) {
+		t.Errorf("next after attempt 1 = %v, want %v", next, testNow.Add(time.Minute))
+	}
+	if next := retry.next(testNow, 3); next != nil {
+		t.Errorf("next after last attempt = %v, want nil", next)
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anpr-service/internal/config"
+)
+
+// EnsureEventPartitions заранее создаёт секции anpr_events: от текущего интервала (и предыдущего —
+// для запоздавших событий) на EVENTS_PARTITION_PREMAKE интервалов вперёд
+func (s *ANPRService) EnsureEventPartitions(ctx context.Context) error {
+	daily := s.Config().Partition.Interval == config.EventPartitionDay
+	step := 7 * 24 * time.Hour
+	if daily {
+		step = 24 * time.Hour
+	}
+
+	now := s.clock.Now()
+	created, err := s.repo.EnsureEventPartitions(ctx, now.Add(-step), now.Add(time.Duration(s.Config().Partition.Premake)*step), daily)
+	for _, name := range created {
+		s.logger(ctx).Info().Str("partition", name).Msg("event partition created")
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/photohash"
+	"anpr-service/internal/repository"
+)
+
+// defaultPhotoDuplicatePeriod — период списка совпадений фото по умолчанию
+const defaultPhotoDuplicatePeriod = 7 * 24 * time.Hour
+
+// UploadedPhoto — загруженное фото события с его хешами
+type UploadedPhoto struct {
+	EventID   uuid.UUID
+	EventTime time.Time
+	CameraID  string
+	Plate     string
+	PhotoURL  string
+	Hash      photohash.Hash
+	// Bytes — сколько байт загружено в хранилище (оригинал и копии), для учёта использования
+	Bytes int64
+}
+
+// PhotoDuplicateEvent — событие, к которому приложено совпавшее фото
+type PhotoDuplicateEvent struct {
+	EventID   string    `json:"event_id"`
+	EventTime time.Time `json:"event_time"`
+	CameraID  string    `json:"camera_id"`
+	Plate     string    `json:"plate"`
+	PhotoURL  string    `json:"photo_url"`
+}
+
+// PhotoDuplicateInfo — фото события, совпавшее с фото ранее присланного события
+type PhotoDuplicateInfo struct {
+	PhotoDuplicateEvent
+	// Exact — побайтовая копия; иначе почти дубликат с расстоянием Distance между перцептивными хешами
+	Exact       bool                `json:"exact"`
+	Distance    int                 `json:"distance"`
+	DuplicateOf PhotoDuplicateEvent `json:"duplicate_of"`
+}
+
+// RegisterEventPhoto сохраняет хеши фото события и ищет среди фото других событий его копию или
+// почти дубликат (расстояние перцептивных хешей не больше INGEST_PHOTO_HASH_MAX_DISTANCE).
+// Совпадение запоминается у нового фото и попадает в ListPhotoDuplicates.
+func (s *ANPRService) RegisterEventPhoto(ctx context.Context, photo UploadedPhoto) error {
+	if photo.Bytes > 0 {
+		s.recordIngestUsage(photo.CameraID, 0, photo.Bytes)
+	}
+	record := &repository.PhotoHash{
+		EventID:   photo.EventID,
+		EventTime: photo.EventTime,
+		CameraID:  photo.CameraID,
+		Plate:     photo.Plate,
+		PhotoURL:  photo.PhotoURL,
+		SHA256:    photo.Hash.SHA256,
+	}
+	var perceptual *int64
+	if photo.Hash.Perceptual != nil {
+		value := int64(*photo.Hash.Perceptual)
+		record.PHash = &value
+		if s.Config().Ingest.PhotoHashMaxDistance > 0 {
+			perceptual = &value
+		}
+	}
+
+	candidates, err := s.repo.FindPhotoHashCandidates(ctx, record.SHA256, perceptual, photo.EventID)
+	if err != nil {
+		return err
+	}
+	var match *repository.PhotoHash
+	bestDistance := 0
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.SHA256 == record.SHA256 {
+			match, bestDistance = candidate, 0
+			break
+		}
+		if perceptual == nil || candidate.PHash == nil {
+			continue
+		}
+		distance := photohash.Distance(uint64(*perceptual), uint64(*candidate.PHash))
+		if distance <= s.Config().Ingest.PhotoHashMaxDistance && (match == nil || distance < bestDistance) {
+			match, bestDistance = candidate, distance
+		}
+	}
+	if match != nil {
+		record.DuplicateOfEventID = &match.EventID
+		record.DuplicateOfPhotoURL = &match.PhotoURL
+		record.HashDistance = &bestDistance
+	}
+
+	if err := s.repo.CreatePhotoHash(ctx, record); err != nil {
+		return err
+	}
+	if match == nil {
+		return nil
+	}
+	s.logger(ctx).Warn().
+		Str("event_id", photo.EventID.String()).
+		Str("camera_id", photo.CameraID).
+		Str("plate", photo.Plate).
+		Str("duplicate_of_event_id", match.EventID.String()).
+		Bool("exact", match.SHA256 == record.SHA256).
+		Int("distance", bestDistance).
+		Msg("event photo was already submitted with another event")
+	return nil
+}
+
+// ListPhotoDuplicates возвращает фото, совпавшие с фото ранее присланных событий, за период по времени
+// события (по умолчанию — последние 7 дней)
+func (s *ANPRService) ListPhotoDuplicates(ctx context.Context, from, to *time.Time, cameraID *string, exactOnly bool, limit, offset int) ([]PhotoDuplicateInfo, error) {
+	periodTo := s.clock.Now()
+	if to != nil {
+		periodTo = *to
+	}
+	periodFrom := periodTo.Add(-defaultPhotoDuplicatePeriod)
+	if from != nil {
+		periodFrom = *from
+	}
+	if periodTo.Before(periodFrom) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	duplicates, err := s.repo.ListPhotoDuplicates(ctx, repository.PhotoDuplicateFilter{
+		From:      periodFrom,
+		To:        periodTo,
+		CameraID:  cameraID,
+		ExactOnly: exactOnly,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PhotoDuplicateInfo, 0, len(duplicates))
+	for _, d := range duplicates {
+		result = append(result, PhotoDuplicateInfo{
+			PhotoDuplicateEvent: PhotoDuplicateEvent{
+				EventID:   d.EventID.String(),
+				EventTime: d.EventTime,
+				CameraID:  d.CameraID,
+				Plate:     d.Plate,
+				PhotoURL:  d.PhotoURL,
+			},
+			Exact:    d.Exact,
+			Distance: d.HashDistance,
+			DuplicateOf: PhotoDuplicateEvent{
+				EventID:   d.DuplicateOfEventID.String(),
+				EventTime: d.DuplicateOfEventTime,
+				CameraID:  d.DuplicateOfCameraID,
+				Plate:     d.DuplicateOfPlate,
+				PhotoURL:  d.DuplicateOfPhotoURL,
+			},
+		})
+	}
+	return result, nil
+}
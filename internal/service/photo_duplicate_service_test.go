@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/photohash"
+	"anpr-service/internal/repository"
+)
+
+func TestRegisterEventPhoto(t *testing.T) {
+	eventID, earlierID, laterID := uuid.New(), uuid.New(), uuid.New()
+	phash := uint64(0xF0F0_F0F0_0F0F_0F0F)
+	int64Hash := func(v uint64) *int64 { h := int64(v); return &h }
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		perceptual  *uint64
+		candidates  []repository.PhotoHash
+		// wantNearDup — кандидаты запрашиваются с перцептивным хешем
+		wantNearDup  bool
+		wantMatch    *uuid.UUID
+		wantDistance int
+	}{
+		{
+			name:        "new photo",
+			maxDistance: 2,
+			perceptual:  &phash,
+			wantNearDup: true,
+		},
+		{
+			name:        "exact copy wins over closer near duplicate",
+			maxDistance: 2,
+			perceptual:  &phash,
+			candidates: []repository.PhotoHash{
+				{EventID: earlierID, PhotoURL: "earlier.jpg", SHA256: "other", PHash: int64Hash(phash)},
+				{EventID: laterID, PhotoURL: "later.jpg", SHA256: "abc", PHash: int64Hash(phash ^ 0b1)},
+			},
+			wantNearDup: true,
+			wantMatch:   &laterID,
+		},
+		{
+			name:        "closest near duplicate",
+			maxDistance: 2,
+			perceptual:  &phash,
+			candidates: []repository.PhotoHash{
+				{EventID: earlierID, PhotoURL: "earlier.jpg", SHA256: "e", PHash: int64Hash(phash ^ 0b11)},
+				{EventID: laterID, PhotoURL: "later.jpg", SHA256: "l", PHash: int64Hash(phash ^ 0b1)},
+			},
+			wantNearDup:  true,
+			wantMatch:    &laterID,
+			wantDistance: 1,
+		},
+		{
+			name:        "candidate sharing a hash part is too far",
+			maxDistance: 2,
+			perceptual:  &phash,
+			candidates: []repository.PhotoHash{
+				{EventID: earlierID, PhotoURL: "earlier.jpg", SHA256: "e", PHash: int64Hash(phash ^ 0b111)},
+			},
+			wantNearDup: true,
+		},
+		{
+			name:        "near duplicates disabled",
+			maxDistance: 0,
+			perceptual:  &phash,
+			candidates: []repository.PhotoHash{
+				{EventID: earlierID, PhotoURL: "earlier.jpg", SHA256: "abc"},
+			},
+			wantMatch: &earlierID,
+		},
+		{
+			name:        "undecodable image matches by sha only",
+			maxDistance: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Ingest.PhotoHashMaxDistance = tt.maxDistance
+			svc, store := newTestService(t, cfg)
+
+			store.EXPECT().FindPhotoHashCandidates(gomock.Any(), "abc", gomock.Any(), eventID).
+				DoAndReturn(func(_ context.Context, _ string, perceptual *int64, _ uuid.UUID) ([]repository.PhotoHash, error) {
+					if (perceptual != nil) != tt.wantNearDup {
+						t.Errorf("perceptual hash passed = %v, want %v", perceptual != nil, tt.wantNearDup)
+					}
+					return tt.candidates, nil
+				})
+			store.EXPECT().CreatePhotoHash(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, hash *repository.PhotoHash) error {
+				if (hash.PHash != nil) != (tt.perceptual != nil) {
+					t.Errorf("stored phash = %v, want set %v", hash.PHash, tt.perceptual != nil)
+				}
+				if tt.wantMatch == nil {
+					if hash.DuplicateOfEventID != nil {
+						t.Errorf("duplicate_of = %s, want none", hash.DuplicateOfEventID)
+					}
+					return nil
+				}
+				if hash.DuplicateOfEventID == nil || *hash.DuplicateOfEventID != *tt.wantMatch {
+					t.Errorf("duplicate_of = %v, want %s", hash.DuplicateOfEventID, tt.wantMatch)
+				} else if *hash.HashDistance != tt.wantDistance {
+					t.Errorf("distance = %d, want %d", *hash.HashDistance, tt.wantDistance)
+				}
+				return nil
+			})
+
+			err := svc.RegisterEventPhoto(context.Background(), UploadedPhoto{
+				EventID:   eventID,
+				EventTime: testNow,
+				CameraID:  "cam-1",
+				Plate:     "123ABC02",
+				PhotoURL:  "new.jpg",
+				Hash:      photohash.Hash{SHA256: "abc", Perceptual: tt.perceptual},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
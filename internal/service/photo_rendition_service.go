@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"anpr-service/internal/imaging"
+	"anpr-service/internal/repository"
+)
+
+// PhotoRenditionInput — размер обработанного оригинала и ссылки на его копии по имени (imaging.Rendition*)
+type PhotoRenditionInput struct {
+	Width  int
+	Height int
+	URLs   map[string]string
+}
+
+// PhotoRenditions — фото события и его уменьшенные копии; копий нет у фото, загруженных без обработки
+type PhotoRenditions struct {
+	Original  string  `json:"original"`
+	Thumbnail *string `json:"thumbnail,omitempty"`
+	Medium    *string `json:"medium,omitempty"`
+}
+
+// SavePhotoRendition запоминает копии загруженного фото, чтобы отдавать их вместе с событием
+func (s *ANPRService) SavePhotoRendition(ctx context.Context, photoURL string, input PhotoRenditionInput) error {
+	if photoURL == "" || input.Width <= 0 || input.Height <= 0 {
+		return fmt.Errorf("%w: photo url and size are required", ErrInvalidInput)
+	}
+	rendition := &repository.PhotoRendition{
+		PhotoURL: photoURL,
+		Width:    input.Width,
+		Height:   input.Height,
+	}
+	if url, ok := input.URLs[imaging.RenditionThumbnail]; ok {
+		rendition.ThumbnailURL = &url
+	}
+	if url, ok := input.URLs[imaging.RenditionMedium]; ok {
+		rendition.MediumURL = &url
+	}
+	return s.repo.UpsertPhotoRendition(ctx, rendition)
+}
+
+// photoRenditions — копии фото события в порядке показа
+func photoRenditions(photos []repository.EventPhoto) []PhotoRenditions {
+	result := make([]PhotoRenditions, 0, len(photos))
+	for _, photo := range photos {
+		result = append(result, PhotoRenditions{
+			Original:  photo.PhotoURL,
+			Thumbnail: photo.ThumbnailURL,
+			Medium:    photo.MediumURL,
+		})
+	}
+	return result
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// PlateAliasInfo — псевдоним номера для API
+type PlateAliasInfo struct {
+	Alias     string    `json:"alias"`
+	PlateID   string    `json:"plate_id"`
+	Note      *string   `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PlateMergeInfo — итог слияния номеров
+type PlateMergeInfo struct {
+	PlateID        string `json:"plate_id"`
+	Plate          string `json:"plate"`
+	MergedPlate    string `json:"merged_plate"` // нормализованный номер слитой записи, теперь псевдоним
+	Events         int64  `json:"events"`
+	RejectedEvents int64  `json:"rejected_events"`
+	ListItems      int64  `json:"list_items"`
+}
+
+// MergePlates сливает номер sourceID (ошибка распознавания) с основным номером targetID: события,
+// отклонённые проезды и членство в списках переносятся, а нормализованный номер sourceID становится
+// псевдонимом, чтобы следующие распознавания сразу попадали на основной номер
+func (s *ANPRService) MergePlates(ctx context.Context, targetID, sourceID uuid.UUID, note *string) (*PlateMergeInfo, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("%w: cannot merge a plate into itself", ErrInvalidInput)
+	}
+	target, err := s.repo.GetPlateByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.repo.GetPlateByID(ctx, sourceID)
+	if errors.Is(err, ErrPlateNotFound) {
+		return nil, fmt.Errorf("source %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNotVehiclePlate(ctx, source.Normalized); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.MergePlates(ctx, targetID, sourceID, trimNote(note))
+	if err != nil {
+		return nil, err
+	}
+	s.InvalidateListCache()
+	// Перенесённые въезды меняют порядок въездов основного номера — его рейсы пересчитываются
+	if result.EventsFrom != nil && result.EventsTo != nil {
+		s.repairPlateTrips(ctx, TripChangePlateMerge, target.ID, target.Normalized, *result.EventsFrom, *result.EventsTo)
+	}
+
+	s.logger(ctx).Info().
+		Str("plate", target.Normalized).
+		Str("merged_plate", source.Normalized).
+		Int64("events", result.Events).
+		Int64("rejected_events", result.RejectedEvents).
+		Int64("list_items", result.ListItems).
+		Msg("plates merged")
+
+	return &PlateMergeInfo{
+		PlateID:        target.ID.String(),
+		Plate:          target.Number,
+		MergedPlate:    source.Normalized,
+		Events:         result.Events,
+		RejectedEvents: result.RejectedEvents,
+		ListItems:      result.ListItems,
+	}, nil
+}
+
+// ListPlateAliases возвращает псевдонимы номера
+func (s *ANPRService) ListPlateAliases(ctx context.Context, plateID uuid.UUID) ([]PlateAliasInfo, error) {
+	if _, err := s.repo.GetPlateByID(ctx, plateID); err != nil {
+		return nil, err
+	}
+
+	aliases, err := s.repo.ListPlateAliases(ctx, plateID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PlateAliasInfo, 0, len(aliases))
+	for _, a := range aliases {
+		result = append(result, toPlateAliasInfo(a))
+	}
+	return result, nil
+}
+
+// AddPlateAlias объявляет alias ошибочным вариантом номера plateID. Если запись с таким номером уже
+// есть (по нему были проезды), её нужно слить через MergePlates, чтобы не потерять историю.
+func (s *ANPRService) AddPlateAlias(ctx context.Context, plateID uuid.UUID, alias string, note *string) (*PlateAliasInfo, error) {
+	normalized := utils.NormalizePlate(alias)
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: alias is required", ErrInvalidInput)
+	}
+
+	plate, err := s.repo.GetPlateByID(ctx, plateID)
+	if err != nil {
+		return nil, err
+	}
+	if normalized == plate.Normalized {
+		return nil, fmt.Errorf("%w: alias matches the plate itself", ErrInvalidInput)
+	}
+
+	existing, err := s.repo.FindPlatesByNormalized(ctx, normalized)
+	if err != nil {
+		return nil, fmt.Errorf("find plate %s: %w", normalized, err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: plate %s already exists, merge it instead", ErrInvalidInput, normalized)
+	}
+	if err := s.ensureNotVehiclePlate(ctx, normalized); err != nil {
+		return nil, err
+	}
+
+	record := &repository.PlateAlias{Alias: normalized, PlateID: plateID, Note: trimNote(note)}
+	created, err := s.repo.CreatePlateAlias(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: alias %s already exists", ErrInvalidInput, normalized)
+	}
+
+	s.logger(ctx).Info().Str("plate", plate.Normalized).Str("alias", normalized).Msg("plate alias created")
+	info := toPlateAliasInfo(*record)
+	return &info, nil
+}
+
+// DeletePlateAlias удаляет псевдоним номера; следующие распознавания alias снова создадут отдельный номер
+func (s *ANPRService) DeletePlateAlias(ctx context.Context, plateID uuid.UUID, alias string) error {
+	normalized := utils.NormalizePlate(alias)
+	deleted, err := s.repo.DeletePlateAlias(ctx, plateID, normalized)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	s.logger(ctx).Info().Str("plate_id", plateID.String()).Str("alias", normalized).Msg("plate alias deleted")
+	return nil
+}
+
+// resolvePlateAlias заменяет известную ошибку распознавания основным номером
+func (s *ANPRService) resolvePlateAlias(ctx context.Context, normalized string) (string, error) {
+	canonical, err := s.repo.ResolvePlateAlias(ctx, normalized)
+	if err != nil {
+		return "", err
+	}
+	if canonical == "" {
+		return normalized, nil
+	}
+	s.logger(ctx).Info().Str("alias", normalized).Str("plate", canonical).Msg("plate alias resolved")
+	return canonical, nil
+}
+
+// ensureNotVehiclePlate не даёт объявить ошибкой распознавания номер зарегистрированной машины
+func (s *ANPRService) ensureNotVehiclePlate(ctx context.Context, normalized string) error {
+	vehicle, err := s.vehicleByPlate(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if vehicle != nil {
+		return fmt.Errorf("%w: plate %s belongs to a registered vehicle", ErrInvalidInput, normalized)
+	}
+	return nil
+}
+
+func toPlateAliasInfo(a repository.PlateAlias) PlateAliasInfo {
+	return PlateAliasInfo{
+		Alias:     a.Alias,
+		PlateID:   a.PlateID.String(),
+		Note:      a.Note,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func trimNote(note *string) *string {
+	if note == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
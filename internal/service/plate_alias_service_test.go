@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestProcessIncomingEventResolvesAlias(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	payload := testPayload()
+	payload.Plate = "097cp02"
+
+	store.EXPECT().ResolvePlateAlias(gomock.Any(), "097CP02").Return("O97CP02", nil)
+	expectUnregisteredCamera(store)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+	store.EXPECT().ExistsRecentEvent(gomock.Any(), "O97CP02", "cam-1", payload.EventTime, 5*time.Minute).Return(true, nil)
+
+	if _, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil); !errors.Is(err, ErrDuplicateEvent) {
+		t.Fatalf("error = %v, want ErrDuplicateEvent", err)
+	}
+}
+
+func TestMergePlates(t *testing.T) {
+	target := &repository.Plate{ID: uuid.New(), Number: "O97CP02", Normalized: "O97CP02"}
+	source := &repository.Plate{ID: uuid.New(), Number: "097CP02", Normalized: "097CP02"}
+
+	tests := []struct {
+		name    string
+		setup   func(e *mocks.MockANPRStoreMockRecorder)
+		wantErr error
+	}{
+		{
+			name: "merges and records alias",
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.GetVehicleByPlate(gomock.Any(), "097CP02").Return(nil, nil)
+				e.MergePlates(gomock.Any(), target.ID, source.ID, nil).Return(&repository.PlateMergeResult{Events: 7, ListItems: 1}, nil)
+			},
+		},
+		{
+			name: "registered vehicle plate is not merged",
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.GetVehicleByPlate(gomock.Any(), "097CP02").Return(&repository.VehicleData{}, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			store.EXPECT().GetPlateByID(gomock.Any(), target.ID).Return(target, nil)
+			store.EXPECT().GetPlateByID(gomock.Any(), source.ID).Return(source, nil)
+			tt.setup(store.EXPECT())
+
+			result, err := svc.MergePlates(context.Background(), target.ID, source.ID, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (result.MergedPlate != "097CP02" || result.Events != 7 || result.ListItems != 1) {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestMergePlatesSourceNotFound(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	targetID, sourceID := uuid.New(), uuid.New()
+	store.EXPECT().GetPlateByID(gomock.Any(), targetID).Return(&repository.Plate{ID: targetID, Normalized: "O97CP02"}, nil)
+	store.EXPECT().GetPlateByID(gomock.Any(), sourceID).Return(nil, fmt.Errorf("%w: %s", repository.ErrPlateNotFound, sourceID))
+
+	_, err := svc.MergePlates(context.Background(), targetID, sourceID, nil)
+	if !errors.Is(err, ErrPlateNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrPlateNotFound", err)
+	}
+	if !strings.HasPrefix(err.Error(), "source plate not found") {
+		t.Errorf("err = %q, want source plate context", err)
+	}
+}
+
+func TestMergePlatesIntoItself(t *testing.T) {
+	svc, _ := newTestService(t, nil)
+	id := uuid.New()
+	if _, err := svc.MergePlates(context.Background(), id, id, nil); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestAddPlateAlias(t *testing.T) {
+	plate := &repository.Plate{ID: uuid.New(), Number: "O97CP02", Normalized: "O97CP02"}
+
+	tests := []struct {
+		name    string
+		alias   string
+		setup   func(e *mocks.MockANPRStoreMockRecorder)
+		wantErr error
+	}{
+		{
+			name:  "creates normalized alias",
+			alias: "097 cp-02",
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.FindPlatesByNormalized(gomock.Any(), "097CP02").Return(nil, nil)
+				e.GetVehicleByPlate(gomock.Any(), "097CP02").Return(nil, nil)
+				e.CreatePlateAlias(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *repository.PlateAlias) (bool, error) {
+					if a.Alias != "097CP02" || a.PlateID != plate.ID {
+						t.Errorf("unexpected alias: %+v", a)
+					}
+					return true, nil
+				})
+			},
+		},
+		{
+			name:    "alias equals plate",
+			alias:   "o97cp02",
+			setup:   func(e *mocks.MockANPRStoreMockRecorder) {},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "existing plate must be merged",
+			alias: "097CP02",
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.FindPlatesByNormalized(gomock.Any(), "097CP02").Return([]repository.Plate{{ID: uuid.New()}}, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "duplicate alias",
+			alias: "097CP02",
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.FindPlatesByNormalized(gomock.Any(), "097CP02").Return(nil, nil)
+				e.GetVehicleByPlate(gomock.Any(), "097CP02").Return(nil, nil)
+				e.CreatePlateAlias(gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			store.EXPECT().GetPlateByID(gomock.Any(), plate.ID).Return(plate, nil)
+			tt.setup(store.EXPECT())
+
+			_, err := svc.AddPlateAlias(context.Background(), plate.ID, tt.alias, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
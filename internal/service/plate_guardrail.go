@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"anpr-service/internal/config"
+)
+
+// plateRule возвращает правило проверки номера для страны из события (или общее правило)
+func (s *ANPRService) plateRule(country string) config.PlateRule {
+	if rule, ok := s.Config().Plate.Countries[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return rule
+	}
+	return s.Config().Plate.Default
+}
+
+// plateViolation проверяет нормализованный номер на длину и набор символов.
+// Возвращает описание нарушения или пустую строку, если номер допустим.
+func plateViolation(normalized string, rule config.PlateRule) string {
+	length := utf8.RuneCountInString(normalized)
+	if length < rule.MinLength || length > rule.MaxLength {
+		return fmt.Sprintf("plate length %d is outside %d-%d", length, rule.MinLength, rule.MaxLength)
+	}
+	for _, r := range normalized {
+		if !plateCharAllowed(r, rule.Charset) {
+			return fmt.Sprintf("plate contains unsupported character %q", r)
+		}
+	}
+	return ""
+}
+
+func plateCharAllowed(r rune, charset string) bool {
+	if charset == config.PlateCharsetLatin {
+		return (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"anpr-service/internal/config"
+)
+
+func TestPlateViolation(t *testing.T) {
+	alnum := config.PlateRule{MinLength: 4, MaxLength: 10, Charset: config.PlateCharsetAlnum}
+	latin := config.PlateRule{MinLength: 7, MaxLength: 8, Charset: config.PlateCharsetLatin}
+
+	tests := []struct {
+		name  string
+		plate string
+		rule  config.PlateRule
+		ok    bool
+	}{
+		{name: "regular kz plate", plate: "123ABC02", rule: alnum, ok: true},
+		{name: "single character", plate: "1", rule: alnum, ok: false},
+		{name: "ocr noise", plate: "123ABC02123ABC02123ABC02123ABC", rule: alnum, ok: false},
+		{name: "min length boundary", plate: "A123", rule: alnum, ok: true},
+		{name: "max length boundary", plate: "A123456789", rule: alnum, ok: true},
+		{name: "cyrillic allowed by alnum", plate: "А123ВС77", rule: alnum, ok: true},
+		{name: "cyrillic rejected by latin", plate: "А123ВС77", rule: latin, ok: false},
+		{name: "punctuation", plate: "123AB.02", rule: alnum, ok: false},
+		{name: "country length", plate: "123ABC", rule: latin, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := plateViolation(tt.plate, tt.rule)
+			if (violation == "") != tt.ok {
+				t.Errorf("plateViolation(%q) = %q, want ok=%v", tt.plate, violation, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPlateRuleByCountry(t *testing.T) {
+	s := &ANPRService{live: config.NewLive(&config.Config{Plate: config.PlateConfig{
+		Default:   config.PlateRule{MinLength: 4, MaxLength: 10, Charset: config.PlateCharsetAlnum},
+		Countries: map[string]config.PlateRule{"KZ": {MinLength: 7, MaxLength: 8, Charset: config.PlateCharsetLatin}},
+	}})}
+
+	if rule := s.plateRule(" kz "); rule.MinLength != 7 {
+		t.Errorf("plateRule(kz) = %+v, want KZ rule", rule)
+	}
+	if rule := s.plateRule(""); rule.MinLength != 4 {
+		t.Errorf("plateRule(\"\") = %+v, want default rule", rule)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// PolygonModeInfo — режим работы полигона для API. ChangedBy/ChangedAt пустые, если режим не переключался.
+type PolygonModeInfo struct {
+	PolygonID string     `json:"polygon_id"`
+	Mode      string     `json:"mode"`
+	Reason    *string    `json:"reason,omitempty"`
+	ChangedBy *string    `json:"changed_by,omitempty"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+}
+
+// PolygonModeInput — переключение режима полигона; Reason — основание (например, номер распоряжения)
+type PolygonModeInput struct {
+	Mode   string
+	Reason string
+}
+
+// OperationalModePeriod — период режима storm на полигоне, пересекающийся с периодом отчёта.
+// To пустой — режим действует до сих пор.
+type OperationalModePeriod struct {
+	PolygonID string     `json:"polygon_id"`
+	Mode      string     `json:"mode"`
+	Reason    *string    `json:"reason,omitempty"`
+	From      time.Time  `json:"from"`
+	To        *time.Time `json:"to,omitempty"`
+}
+
+// ListPolygonModes возвращает текущий режим каждого полигона
+func (s *ANPRService) ListPolygonModes(ctx context.Context) ([]PolygonModeInfo, error) {
+	polygons, err := s.repo.ListPolygons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.ListCurrentPolygonModes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[uuid.UUID]repository.PolygonModeChange, len(changes))
+	for _, change := range changes {
+		current[change.PolygonID] = change
+	}
+
+	result := make([]PolygonModeInfo, 0, len(polygons))
+	for _, polygon := range polygons {
+		if change, ok := current[polygon.ID]; ok {
+			result = append(result, toPolygonModeInfo(change))
+			continue
+		}
+		result = append(result, PolygonModeInfo{PolygonID: polygon.ID.String(), Mode: anpr.ModeNormal})
+	}
+	return result, nil
+}
+
+// SetPolygonMode переключает режим полигона и записывает, кто и когда это сделал. Повторное включение
+// уже действующего режима ничего не записывает.
+func (s *ANPRService) SetPolygonMode(ctx context.Context, polygonID uuid.UUID, input PolygonModeInput) (*PolygonModeInfo, error) {
+	principal, err := requirePrincipal(ctx, canSwitchPolygonMode)
+	if err != nil {
+		return nil, err
+	}
+	mode := strings.ToLower(strings.TrimSpace(input.Mode))
+	if !anpr.IsOperationalMode(mode) {
+		return nil, fmt.Errorf("%w: mode must be one of %s, %s", ErrInvalidInput, anpr.ModeNormal, anpr.ModeStorm)
+	}
+	polygon, err := s.repo.GetPolygon(ctx, polygonID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	current, err := s.repo.GetPolygonMode(ctx, polygonID, now)
+	if err != nil {
+		return nil, err
+	}
+	unchanged := (current == nil && mode == anpr.ModeNormal) || (current != nil && current.Mode == mode)
+	if unchanged {
+		info := PolygonModeInfo{PolygonID: polygonID.String(), Mode: mode}
+		if current != nil {
+			info = toPolygonModeInfo(*current)
+		}
+		return &info, nil
+	}
+
+	change := &repository.PolygonModeChange{
+		PolygonID: polygonID,
+		Mode:      mode,
+		ChangedBy: principal.UserID,
+		ChangedAt: now,
+	}
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		change.Reason = &reason
+	}
+	if err := s.repo.CreatePolygonModeChange(ctx, change); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Warn().
+		Str("polygon_id", polygonID.String()).
+		Str("polygon", polygon.Name).
+		Str("mode", mode).
+		Str("user_id", principal.UserID.String()).
+		Msg("polygon operational mode switched")
+
+	info := toPolygonModeInfo(*change)
+	return &info, nil
+}
+
+// ListPolygonModeHistory возвращает журнал переключений режима полигона, новые — первыми
+func (s *ANPRService) ListPolygonModeHistory(ctx context.Context, polygonID uuid.UUID) ([]PolygonModeInfo, error) {
+	if _, err := s.repo.GetPolygon(ctx, polygonID); err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.ListPolygonModeChanges(ctx, &polygonID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PolygonModeInfo, 0, len(changes))
+	for _, change := range slices.Backward(changes) {
+		result = append(result, toPolygonModeInfo(change))
+	}
+	return result, nil
+}
+
+// polygonMode — режим полигона в момент at; без полигона, без переключений или при ошибке — normal
+func (s *ANPRService) polygonMode(ctx context.Context, polygonID *uuid.UUID, at time.Time) string {
+	if polygonID == nil {
+		return anpr.ModeNormal
+	}
+	change, err := s.repo.GetPolygonMode(ctx, *polygonID, at)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("polygon_id", polygonID.String()).Msg("failed to load polygon mode, assuming normal")
+		return anpr.ModeNormal
+	}
+	if change == nil {
+		return anpr.ModeNormal
+	}
+	return change.Mode
+}
+
+// stormPeriods возвращает периоды режима storm на полигонах отчёта, пересекающиеся с [From, To]. Это
+// пометка к отчёту: при ошибке она логируется, а отчёт строится без неё.
+func (s *ANPRService) stormPeriods(ctx context.Context, filters repository.ReportFilters) []OperationalModePeriod {
+	to := filters.To
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	changes, err := s.repo.ListPolygonModeChanges(ctx, filters.PolygonID, to)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Msg("failed to load polygon mode changes for report")
+		return nil
+	}
+	var inScope map[uuid.UUID]bool
+	if filters.PolygonOrgID != nil && len(changes) > 0 {
+		polygons, err := s.repo.ListPolygons(ctx)
+		if err != nil {
+			s.logger(ctx).Warn().Err(err).Msg("failed to load polygons for report mode periods")
+			return nil
+		}
+		inScope = make(map[uuid.UUID]bool, len(polygons))
+		for _, polygon := range polygons {
+			if polygon.OrganizationID != nil && *polygon.OrganizationID == *filters.PolygonOrgID {
+				inScope[polygon.ID] = true
+			}
+		}
+	}
+
+	periods := make([]OperationalModePeriod, 0)
+	open := make(map[uuid.UUID]int)
+	for _, change := range changes {
+		if inScope != nil && !inScope[change.PolygonID] {
+			continue
+		}
+		idx, stormOpen := open[change.PolygonID]
+		switch {
+		case change.Mode == anpr.ModeStorm && !stormOpen:
+			open[change.PolygonID] = len(periods)
+			periods = append(periods, OperationalModePeriod{
+				PolygonID: change.PolygonID.String(),
+				Mode:      anpr.ModeStorm,
+				Reason:    change.Reason,
+				From:      change.ChangedAt,
+			})
+		case change.Mode != anpr.ModeStorm && stormOpen:
+			end := change.ChangedAt
+			periods[idx].To = &end
+			delete(open, change.PolygonID)
+		}
+	}
+	return slices.DeleteFunc(periods, func(period OperationalModePeriod) bool {
+		return !filters.From.IsZero() && period.To != nil && period.To.Before(filters.From)
+	})
+}
+
+func toPolygonModeInfo(change repository.PolygonModeChange) PolygonModeInfo {
+	changedBy := change.ChangedBy.String()
+	changedAt := change.ChangedAt
+	return PolygonModeInfo{
+		PolygonID: change.PolygonID.String(),
+		Mode:      change.Mode,
+		Reason:    change.Reason,
+		ChangedBy: &changedBy,
+		ChangedAt: &changedAt,
+	}
+}
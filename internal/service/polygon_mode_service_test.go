@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestSetPolygonMode(t *testing.T) {
+	polygonID, userID := uuid.New(), uuid.New()
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: userID, Role: model.UserRoleKguZkhAdmin})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		input     PolygonModeInput
+		setup     func(store *mocks.MockANPRStoreMockRecorder)
+		wantMode  string
+		wantWrite bool
+		wantErr   error
+	}{
+		{
+			name:  "storm declared",
+			ctx:   admin,
+			input: PolygonModeInput{Mode: " Storm ", Reason: "распоряжение №12"},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygon(gomock.Any(), polygonID).Return(&repository.Polygon{ID: polygonID, Name: "Шаховское"}, nil)
+				store.GetPolygonMode(gomock.Any(), polygonID, testNow).Return(nil, nil)
+			},
+			wantMode:  anpr.ModeStorm,
+			wantWrite: true,
+		},
+		{
+			name:  "already normal is not recorded",
+			ctx:   admin,
+			input: PolygonModeInput{Mode: "normal"},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygon(gomock.Any(), polygonID).Return(&repository.Polygon{ID: polygonID}, nil)
+				store.GetPolygonMode(gomock.Any(), polygonID, testNow).Return(nil, nil)
+			},
+			wantMode: anpr.ModeNormal,
+		},
+		{
+			name:    "unknown mode",
+			ctx:     admin,
+			input:   PolygonModeInput{Mode: "holiday"},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "unknown polygon",
+			ctx:   admin,
+			input: PolygonModeInput{Mode: "storm"},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygon(gomock.Any(), polygonID).Return(nil, repository.ErrPolygonNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "contractor cannot switch",
+			ctx:     model.WithPrincipal(context.Background(), model.Principal{UserID: userID, Role: model.UserRoleContractorAdmin}),
+			input:   PolygonModeInput{Mode: "storm"},
+			wantErr: ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, &config.Config{})
+			if tt.setup != nil {
+				tt.setup(store.EXPECT())
+			}
+			if tt.wantWrite {
+				store.EXPECT().CreatePolygonModeChange(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, change *repository.PolygonModeChange) error {
+					if change.ChangedBy != userID || !change.ChangedAt.Equal(testNow) || change.Reason == nil {
+						t.Errorf("unexpected audit record: %+v", change)
+					}
+					return nil
+				})
+			}
+			info, err := svc.SetPolygonMode(tt.ctx, polygonID, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && info.Mode != tt.wantMode {
+				t.Fatalf("mode = %q, want %q", info.Mode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestStormPeriods(t *testing.T) {
+	shahovskoye, yakor := uuid.New(), uuid.New()
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	changes := []repository.PolygonModeChange{
+		// Шторм закончился до начала отчёта — не попадает
+		{PolygonID: shahovskoye, Mode: anpr.ModeStorm, ChangedAt: at(1, 20)},
+		{PolygonID: shahovskoye, Mode: anpr.ModeNormal, ChangedAt: at(3, 8)},
+		// Начался до отчёта и закончился внутри
+		{PolygonID: yakor, Mode: anpr.ModeStorm, ChangedAt: at(9, 20)},
+		{PolygonID: shahovskoye, Mode: anpr.ModeStorm, ChangedAt: at(12, 18)},
+		{PolygonID: yakor, Mode: anpr.ModeNormal, ChangedAt: at(11, 6)},
+	}
+
+	svc, store := newTestService(t, &config.Config{})
+	filters := repository.ReportFilters{From: at(10, 0), To: at(15, 0)}
+	store.EXPECT().ListPolygonModeChanges(gomock.Any(), nil, filters.To).Return(changes, nil)
+
+	periods := svc.stormPeriods(context.Background(), filters)
+	if len(periods) != 2 {
+		t.Fatalf("periods = %+v, want 2", periods)
+	}
+	if periods[0].PolygonID != yakor.String() || periods[0].To == nil || !periods[0].To.Equal(at(11, 6)) {
+		t.Errorf("first period = %+v", periods[0])
+	}
+	if periods[1].PolygonID != shahovskoye.String() || !periods[1].From.Equal(at(12, 18)) || periods[1].To != nil {
+		t.Errorf("second period = %+v, want open storm on shahovskoye", periods[1])
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// PolygonInfo — полигон для API
+type PolygonInfo struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	OrganizationID *string   `json:"organization_id,omitempty"`
+	Latitude       *float64  `json:"latitude,omitempty"`
+	Longitude      *float64  `json:"longitude,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PolygonInput — поля полигона; nil — не менять, пустой organization_id отвязывает организацию
+type PolygonInput struct {
+	Name           *string
+	OrganizationID *string
+	// Latitude, Longitude — координаты для погоды, задаются вместе
+	Latitude  *float64
+	Longitude *float64
+}
+
+// ListPolygons возвращает реестр полигонов
+func (s *ANPRService) ListPolygons(ctx context.Context) ([]PolygonInfo, error) {
+	polygons, err := s.repo.ListPolygons(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PolygonInfo, 0, len(polygons))
+	for _, polygon := range polygons {
+		result = append(result, toPolygonInfo(polygon))
+	}
+	return result, nil
+}
+
+// CreatePolygon добавляет полигон в реестр
+func (s *ANPRService) CreatePolygon(ctx context.Context, input PolygonInput) (*PolygonInfo, error) {
+	if input.Name == nil {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	polygon := &repository.Polygon{}
+	if err := s.applyPolygonInput(ctx, polygon, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePolygon(ctx, polygon); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("polygon_id", polygon.ID.String()).Str("name", polygon.Name).Msg("polygon created")
+
+	info := toPolygonInfo(*polygon)
+	return &info, nil
+}
+
+// UpdatePolygon меняет имя полигона или организацию-оператора
+func (s *ANPRService) UpdatePolygon(ctx context.Context, id uuid.UUID, input PolygonInput) (*PolygonInfo, error) {
+	polygon, err := s.repo.GetPolygon(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPolygonInput(ctx, polygon, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePolygon(ctx, polygon); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("polygon_id", id.String()).Msg("polygon updated")
+
+	info := toPolygonInfo(*polygon)
+	return &info, nil
+}
+
+// DeletePolygon удаляет полигон из реестра; камеры полигона отвязываются
+func (s *ANPRService) DeletePolygon(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeletePolygon(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	s.logger(ctx).Info().Str("polygon_id", id.String()).Msg("polygon deleted")
+	return nil
+}
+
+func (s *ANPRService) applyPolygonInput(ctx context.Context, polygon *repository.Polygon, input PolygonInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidInput)
+		}
+		existing, err := s.repo.GetPolygonByName(ctx, name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if existing != nil && existing.ID != polygon.ID {
+			return fmt.Errorf("%w: polygon %q already exists", ErrInvalidInput, name)
+		}
+		polygon.Name = name
+	}
+	if input.OrganizationID != nil {
+		raw := strings.TrimSpace(*input.OrganizationID)
+		if raw == "" {
+			polygon.OrganizationID = nil
+		} else {
+			orgID, err := uuid.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("%w: invalid organization_id", ErrInvalidInput)
+			}
+			polygon.OrganizationID = &orgID
+		}
+	}
+	if input.Latitude != nil || input.Longitude != nil {
+		if input.Latitude == nil || input.Longitude == nil {
+			return fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidInput)
+		}
+		if *input.Latitude < -90 || *input.Latitude > 90 || *input.Longitude < -180 || *input.Longitude > 180 {
+			return fmt.Errorf("%w: coordinates are out of range", ErrInvalidInput)
+		}
+		polygon.Latitude = input.Latitude
+		polygon.Longitude = input.Longitude
+	}
+	return nil
+}
+
+// cameraPolygonID — полигон события: из реестра камер, а для камер без привязки — по старому
+// псевдониму camera_id. Ошибка не мешает приёму события: оно сохраняется без полигона.
+func (s *ANPRService) cameraPolygonID(ctx context.Context, camera *repository.Camera, cameraID string) *uuid.UUID {
+	if camera != nil && camera.PolygonID != nil {
+		return camera.PolygonID
+	}
+	polygonID, err := s.repo.ResolvePolygonIDByCameraID(ctx, cameraID)
+	if err != nil {
+		s.logger(ctx).Warn().
+			Err(err).
+			Str("camera_id", cameraID).
+			Msg("failed to resolve polygon_id by camera_id")
+		return nil
+	}
+	return polygonID
+}
+
+func toPolygonInfo(polygon repository.Polygon) PolygonInfo {
+	info := PolygonInfo{
+		ID:        polygon.ID.String(),
+		Name:      polygon.Name,
+		Latitude:  polygon.Latitude,
+		Longitude: polygon.Longitude,
+		CreatedAt: polygon.CreatedAt,
+		UpdatedAt: polygon.UpdatedAt,
+	}
+	if polygon.OrganizationID != nil {
+		id := polygon.OrganizationID.String()
+		info.OrganizationID = &id
+	}
+	return info
+}
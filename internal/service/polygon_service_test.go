@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestCreatePolygon(t *testing.T) {
+	orgID := uuid.New()
+	name := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		input   PolygonInput
+		setup   func(store *mocks.MockANPRStoreMockRecorder)
+		wantErr error
+	}{
+		{
+			name:  "created with organization",
+			input: PolygonInput{Name: name(" Шаховское "), OrganizationID: name(orgID.String())},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygonByName(gomock.Any(), "Шаховское").Return(nil, repository.ErrPolygonNotFound)
+				store.CreatePolygon(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p *repository.Polygon) error {
+					if p.Name != "Шаховское" || p.OrganizationID == nil || *p.OrganizationID != orgID {
+						t.Errorf("unexpected polygon: %+v", p)
+					}
+					p.ID = uuid.New()
+					return nil
+				})
+			},
+		},
+		{
+			name:    "name is required",
+			input:   PolygonInput{},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "duplicate name",
+			input: PolygonInput{Name: name("якорь")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygonByName(gomock.Any(), "якорь").Return(&repository.Polygon{ID: uuid.New(), Name: "Якорь"}, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "invalid organization",
+			input: PolygonInput{Name: name("Якорь"), OrganizationID: name("not-a-uuid")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygonByName(gomock.Any(), "Якорь").Return(nil, repository.ErrPolygonNotFound)
+			},
+			wantErr: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, &config.Config{})
+			if tt.setup != nil {
+				tt.setup(store.EXPECT())
+			}
+			_, err := svc.CreatePolygon(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCameraPolygonID(t *testing.T) {
+	registered, legacy := uuid.New(), uuid.New()
+
+	t.Run("camera registry wins", func(t *testing.T) {
+		svc, _ := newTestService(t, &config.Config{})
+		got := svc.cameraPolygonID(context.Background(), &repository.Camera{ID: "shahovskoye", PolygonID: &registered}, "shahovskoye")
+		if got == nil || *got != registered {
+			t.Fatalf("polygon = %v, want %s", got, registered)
+		}
+	})
+
+	t.Run("unbound camera falls back to alias", func(t *testing.T) {
+		svc, store := newTestService(t, &config.Config{})
+		store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), "shahovskoye").Return(&legacy, nil)
+		got := svc.cameraPolygonID(context.Background(), &repository.Camera{ID: "shahovskoye"}, "shahovskoye")
+		if got == nil || *got != legacy {
+			t.Fatalf("polygon = %v, want %s", got, legacy)
+		}
+	})
+
+	t.Run("lookup failure leaves event without polygon", func(t *testing.T) {
+		svc, store := newTestService(t, &config.Config{})
+		store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), "cam-1").Return(nil, errors.New("db down"))
+		if got := svc.cameraPolygonID(context.Background(), nil, "cam-1"); got != nil {
+			t.Fatalf("polygon = %v, want nil", got)
+		}
+	})
+}
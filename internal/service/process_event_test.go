@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/idgen"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+	"anpr-service/internal/utils"
+)
+
+var testNow = time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T, cfg *config.Config) (*ANPRService, *mocks.MockANPRStore) {
+	t.Helper()
+	store := mocks.NewMockANPRStore(gomock.NewController(t))
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	cfg.Plate.Default = config.PlateRule{MinLength: 4, MaxLength: 12, Charset: config.PlateCharsetAlnum}
+	if cfg.Plate.Policy == "" {
+		cfg.Plate.Policy = config.PlatePolicyReject
+	}
+	if cfg.Ingest.DefaultCameraTimeZone == "" {
+		cfg.Ingest.DefaultCameraTimeZone = "UTC"
+	}
+	if cfg.FeatureFlags == nil {
+		cfg.FeatureFlags = map[string]bool{config.FeatureDedup: true}
+	}
+	svc := NewANPRService(store, nil, cfg, zerolog.Nop(), clock.NewManual(testNow), idgen.NewSequence())
+	return svc, store
+}
+
+func testPayload() anpr.EventPayload {
+	return anpr.EventPayload{
+		CameraID:  "cam-1",
+		Plate:     "123 abc-02",
+		EventTime: testNow.Add(-time.Second),
+	}
+}
+
+// expectNoAlias — номер события не объявлен псевдонимом другого номера
+func expectNoAlias(store *mocks.MockANPRStore) {
+	store.EXPECT().ResolvePlateAlias(gomock.Any(), "123ABC02").Return("", nil)
+}
+
+// expectUnregisteredCamera — камера не зарегистрирована в реестре: без расписания и коррекции часов
+func expectUnregisteredCamera(store *mocks.MockANPRStore) {
+	store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(nil, repository.ErrCameraNotFound)
+}
+
+func TestProcessIncomingEventValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload func(p *anpr.EventPayload)
+	}{
+		{name: "missing plate", payload: func(p *anpr.EventPayload) { p.Plate = "" }},
+		{name: "missing camera", payload: func(p *anpr.EventPayload) { p.CameraID = "" }},
+		{name: "missing event time", payload: func(p *anpr.EventPayload) { p.EventTime = time.Time{} }},
+		{name: "plate is only separators", payload: func(p *anpr.EventPayload) { p.Plate = " - " }},
+		{name: "plate too short", payload: func(p *anpr.EventPayload) { p.Plate = "1" }},
+		{name: "unknown source", payload: func(p *anpr.EventPayload) { p.Source = "scanner" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Мок без ожиданий: любое обращение к хранилищу провалит тест
+			svc, _ := newTestService(t, nil)
+			payload := testPayload()
+			tt.payload(&payload)
+
+			_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("error = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestProcessIncomingEventFlagsInvalidPlate(t *testing.T) {
+	svc, store := newTestService(t, &config.Config{Plate: config.PlateConfig{Policy: config.PlatePolicyFlag}})
+	payload := testPayload()
+	payload.Plate = "1"
+	eventID := uuid.New()
+
+	store.EXPECT().CreateRejectedEvent(gomock.Any(), eventID, nil, repository.RejectReasonInvalidPlate,
+		"1", "1", "cam-1", payload.EventTime, gomock.Any(), gomock.Nil()).Return(nil)
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", eventID, nil)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestProcessIncomingEventPlateRuleByDetectedCountry(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Plate.Countries = map[string]config.PlateRule{
+		utils.PlateCountryRU: {MinLength: 8, MaxLength: 8, Charset: config.PlateCharsetLatin},
+	}
+	// Мок без ожиданий: номер отклоняется до обращения к хранилищу
+	svc, _ := newTestService(t, cfg)
+	payload := testPayload()
+	payload.Plate = "А 123 ВС 777"
+	payload.Vehicle.Country = "KZ"
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("error = %v, want ErrInvalidInput by the RU plate rule", err)
+	}
+}
+
+func TestProcessIncomingEventRejectsClockSkew(t *testing.T) {
+	svc, store := newTestService(t, &config.Config{Ingest: config.IngestConfig{
+		MaxClockSkew:    time.Hour,
+		ClockSkewPolicy: config.ClockSkewPolicyReject,
+	}})
+	payload := testPayload()
+	payload.EventTime = testNow.Add(-3 * time.Hour)
+	expectNoAlias(store)
+	expectUnregisteredCamera(store)
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestProcessIncomingEventFlagsLateEvent(t *testing.T) {
+	svc, store := newTestService(t, &config.Config{Ingest: config.IngestConfig{
+		MaxClockSkew:    10 * time.Minute,
+		ClockSkewPolicy: config.ClockSkewPolicyReject,
+		MaxEventAge:     time.Hour,
+	}})
+	payload := testPayload()
+	payload.EventTime = testNow.Add(-3 * time.Hour)
+	percentage := 40.0
+	payload.SnowVolumePercentage = &percentage
+	plateID := uuid.New()
+
+	expectNoAlias(store)
+	expectUnregisteredCamera(store)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+	store.EXPECT().ExistsRecentEvent(gomock.Any(), "123ABC02", "cam-1", payload.EventTime, 5*time.Minute).Return(false, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), "123ABC02").Return(&repository.VehicleData{BodyVolumeM3: 20}, nil)
+	store.EXPECT().FindPlatesByNormalized(gomock.Any(), "123ABC02").Return([]repository.Plate{{ID: plateID}}, nil)
+	store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), "cam-1").Return(nil, nil)
+	store.EXPECT().FindListsForPlate(gomock.Any(), plateID).Return(nil, nil)
+	// Квота и число рейсов за ночь не запрашиваются: опоздавшее событие в них не участвует
+	var saved *anpr.Event
+	store.EXPECT().SaveANPREvent(gomock.Any(), gomock.Any(), utils.PlateCountryKZ, "02", nil, nil, nil).
+		DoAndReturn(func(_ context.Context, event *anpr.Event, _, _ string, _, _ *uuid.UUID, _ []string) (uuid.UUID, error) {
+			saved = event
+			return plateID, nil
+		})
+
+	result, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("ProcessIncomingEvent() error = %v, want the late event saved instead of rejected for clock skew", err)
+	}
+	if !saved.Late || saved.EventTimeSkewed {
+		t.Fatalf("late = %v, skewed = %v, want a late event without the clock skew flag", saved.Late, saved.EventTimeSkewed)
+	}
+	if result.Decision == nil || result.Decision.Reason != anpr.ReasonLateEvent || result.TripID != nil {
+		t.Fatalf("unexpected result: decision %+v, trip %v", result.Decision, result.TripID)
+	}
+}
+
+func TestProcessIncomingEventDuplicate(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	payload := testPayload()
+	expectNoAlias(store)
+	expectUnregisteredCamera(store)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+	store.EXPECT().ExistsRecentEvent(gomock.Any(), "123ABC02", "cam-1", payload.EventTime, 5*time.Minute).Return(true, nil)
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+	if !errors.Is(err, ErrDuplicateEvent) {
+		t.Fatalf("error = %v, want ErrDuplicateEvent", err)
+	}
+}
+
+func TestProcessIncomingEventVehicleNotWhitelisted(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	payload := testPayload()
+	eventID := uuid.New()
+	plateID := uuid.New()
+	photos := []string{"https://example.com/event-photo-1.jpg"}
+
+	expectNoAlias(store)
+	expectUnregisteredCamera(store)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+	store.EXPECT().ExistsRecentEvent(gomock.Any(), "123ABC02", "cam-1", payload.EventTime, 5*time.Minute).Return(false, nil)
+	store.EXPECT().GetOrCreatePlate(gomock.Any(), "123ABC02", "123 abc-02", utils.PlateCountryKZ, "02").Return(plateID, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), "123ABC02").Return(nil, nil)
+	store.EXPECT().CreateRejectedEvent(gomock.Any(), eventID, &plateID, repository.RejectReasonVehicleNotWhitelist,
+		"123ABC02", "123 abc-02", "cam-1", payload.EventTime, gomock.Any(), photos).Return(nil)
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", eventID, photos)
+	if !errors.Is(err, ErrVehicleNotWhitelisted) {
+		t.Fatalf("error = %v, want ErrVehicleNotWhitelisted", err)
+	}
+}
+
+func TestProcessIncomingEventSavesEvent(t *testing.T) {
+	tests := []struct {
+		name         string
+		lists        []anpr.ListHit
+		quota        *repository.PlateTripQuota
+		mode         *repository.PolygonModeChange
+		newPlate     bool
+		tripsTonight int64
+		wantDecision string
+		wantReason   string
+		wantDetail   string
+	}{
+		{
+			name:         "registered vehicle is allowed",
+			wantDecision: anpr.DecisionAllow,
+			wantReason:   anpr.ReasonRegisteredVehicle,
+		},
+		{
+			// Номер заводится только в транзакции события
+			name:         "first pass of a new plate",
+			newPlate:     true,
+			wantDecision: anpr.DecisionAllow,
+			wantReason:   anpr.ReasonRegisteredVehicle,
+		},
+		{
+			name:         "blacklisted vehicle is denied but saved",
+			lists:        []anpr.ListHit{{ListID: uuid.New(), ListName: "stolen", ListType: "BLACKLIST"}},
+			wantDecision: anpr.DecisionDeny,
+			wantReason:   anpr.ReasonBlacklisted,
+		},
+		{
+			name:         "entry over paid trip quota is allowed but not billed",
+			quota:        &repository.PlateTripQuota{NormalizedPlate: "123ABC02", PaidTripsPerNight: 3},
+			tripsTonight: 3,
+			wantDecision: anpr.DecisionAllow,
+			wantReason:   anpr.ReasonOverQuota,
+		},
+		{
+			name:         "storm mode is noted in decision",
+			mode:         &repository.PolygonModeChange{Mode: anpr.ModeStorm},
+			wantDecision: anpr.DecisionAllow,
+			wantReason:   anpr.ReasonRegisteredVehicle,
+			wantDetail:   "polygon is in storm mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			payload := testPayload()
+			percentage := 40.0
+			payload.SnowVolumePercentage = &percentage
+			eventID := uuid.New()
+			plateID := uuid.New()
+			polygonID := uuid.New()
+			photos := []string{"https://example.com/event-photo-1.jpg"}
+
+			expectNoAlias(store)
+			expectUnregisteredCamera(store)
+			store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+			store.EXPECT().ExistsRecentEvent(gomock.Any(), "123ABC02", "cam-1", payload.EventTime, 5*time.Minute).Return(false, nil)
+			store.EXPECT().GetVehicleByPlate(gomock.Any(), "123ABC02").Return(&repository.VehicleData{
+				Brand:        "KAMAZ",
+				BodyVolumeM3: 20,
+			}, nil)
+			store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), "cam-1").Return(&polygonID, nil)
+			existingPlateID := plateID
+			if tt.newPlate {
+				existingPlateID = uuid.Nil
+				store.EXPECT().FindPlatesByNormalized(gomock.Any(), "123ABC02").Return(nil, nil)
+			} else {
+				store.EXPECT().FindPlatesByNormalized(gomock.Any(), "123ABC02").Return([]repository.Plate{{ID: plateID}}, nil)
+			}
+			store.EXPECT().FindListsForPlate(gomock.Any(), existingPlateID).Return(tt.lists, nil)
+			store.EXPECT().GetPolygonMode(gomock.Any(), polygonID, payload.EventTime).Return(tt.mode, nil)
+			store.EXPECT().GetPlateTripQuota(gomock.Any(), "123ABC02").Return(tt.quota, nil)
+			if tt.quota != nil {
+				store.EXPECT().CountAllowedEntries(gomock.Any(), existingPlateID, gomock.Any(), payload.EventTime).Return(tt.tripsTonight, nil)
+				// Въезды этой ночи пришли по порядку — пересчёт ничего не меняет
+				store.EXPECT().RepairTripQuota(gomock.Any(), gomock.Any()).Return(nil, nil)
+			}
+
+			var saved *anpr.Event
+			store.EXPECT().SaveANPREvent(gomock.Any(), gomock.Any(), utils.PlateCountryKZ, "02", nil, &polygonID, photos).
+				DoAndReturn(func(_ context.Context, event *anpr.Event, _, _ string, _, _ *uuid.UUID, _ []string) (uuid.UUID, error) {
+					saved = event
+					return plateID, nil
+				})
+
+			result, err := svc.ProcessIncomingEvent(context.Background(), payload, "default-model", eventID, photos)
+			if err != nil {
+				t.Fatalf("ProcessIncomingEvent() error = %v", err)
+			}
+
+			if result.EventID != eventID || result.PlateID != plateID || result.Plate != "123ABC02" || !result.VehicleExists {
+				t.Fatalf("unexpected result %+v", result)
+			}
+			if result.Decision == nil || result.Decision.Decision != tt.wantDecision || result.Decision.Reason != tt.wantReason {
+				t.Fatalf("decision = %+v, want %s/%s", result.Decision, tt.wantDecision, tt.wantReason)
+			}
+			if tt.wantDetail != "" && result.Decision.Detail != tt.wantDetail {
+				t.Errorf("decision detail = %q, want %q", result.Decision.Detail, tt.wantDetail)
+			}
+			if len(result.Lists) != len(tt.lists) {
+				t.Errorf("lists = %+v, want %+v", result.Lists, tt.lists)
+			}
+			overQuota := tt.wantReason == anpr.ReasonOverQuota
+			if overQuota && result.TripID != nil {
+				t.Errorf("trip id = %v, want nil for an entry over quota", result.TripID)
+			}
+			if !overQuota && (result.TripID == nil || *result.TripID != eventID) {
+				t.Errorf("trip id = %v, want event id %s", result.TripID, eventID)
+			}
+
+			if saved == nil {
+				t.Fatal("event was not saved")
+			}
+			if saved.OverQuota != overQuota {
+				t.Errorf("over_quota = %v, want %v", saved.OverQuota, overQuota)
+			}
+			if saved.Direction != "entry" {
+				t.Errorf("direction = %q, want default entry", saved.Direction)
+			}
+			if saved.CameraModel != "default-model" {
+				t.Errorf("camera model = %q, want default-model", saved.CameraModel)
+			}
+			if saved.Vehicle.Brand != "KAMAZ" {
+				t.Errorf("brand = %q, want value from vehicles", saved.Vehicle.Brand)
+			}
+			if saved.SnowVolumeM3 == nil || *saved.SnowVolumeM3 != 8 {
+				t.Errorf("snow volume m3 = %v, want 8", saved.SnowVolumeM3)
+			}
+			if saved.Source != anpr.SourceCamera {
+				t.Errorf("source = %q, want default %q", saved.Source, anpr.SourceCamera)
+			}
+			if saved.ReceivedAt == nil || !saved.ReceivedAt.Equal(testNow) {
+				t.Errorf("received_at = %v, want clock time %v", saved.ReceivedAt, testNow)
+			}
+		})
+	}
+}
+
+func TestProcessIncomingEventSaveFailure(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	payload := testPayload()
+	plateID := uuid.New()
+
+	expectNoAlias(store)
+	expectUnregisteredCamera(store)
+	store.EXPECT().ListFeatureFlagOverrides(gomock.Any()).Return(nil, nil)
+	store.EXPECT().ExistsRecentEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), gomock.Any()).Return(&repository.VehicleData{}, nil)
+	store.EXPECT().FindPlatesByNormalized(gomock.Any(), gomock.Any()).Return([]repository.Plate{{ID: plateID}}, nil)
+	store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), gomock.Any()).Return(nil, nil)
+	store.EXPECT().FindListsForPlate(gomock.Any(), plateID).Return(nil, nil)
+	store.EXPECT().GetPlateTripQuota(gomock.Any(), gomock.Any()).Return(nil, nil)
+	dbErr := errors.New("connection reset")
+	store.EXPECT().SaveANPREvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uuid.Nil, dbErr)
+
+	_, err := svc.ProcessIncomingEvent(context.Background(), payload, "", uuid.New(), nil)
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("error = %v, want wrapped %v", err, dbErr)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"anpr-service/internal/eventbus"
+)
+
+const (
+	QuotaLevelDisabled = "disabled"
+	QuotaLevelOK       = "ok"
+	QuotaLevelWarning  = "warning"
+	QuotaLevelCritical = "critical"
+)
+
+// DBQuotaStatus — использование квоты на размер БД
+type DBQuotaStatus struct {
+	Level              string     `json:"level"`
+	QuotaBytes         int64      `json:"quota_bytes"`
+	UsedBytes          int64      `json:"used_bytes"`
+	EventsTableBytes   int64      `json:"events_table_bytes"`
+	UsagePercent       float64    `json:"usage_percent"`
+	CheckedAt          *time.Time `json:"checked_at,omitempty"`
+	AutoTighten        bool       `json:"auto_tighten"`
+	RetentionDays      *int       `json:"retention_days,omitempty"` // срок хранения после последнего автоматического сокращения
+	LastTightenedAt    *time.Time `json:"last_tightened_at,omitempty"`
+	LastTightenDeleted int64      `json:"last_tighten_deleted,omitempty"`
+}
+
+// quotaTracker хранит результат последней проверки квоты между запусками фоновой задачи
+type quotaTracker struct {
+	mu     sync.Mutex
+	status DBQuotaStatus
+}
+
+// quotaLevel определяет уровень заполнения по порогам в процентах
+func quotaLevel(usagePercent, warnPercent, criticalPercent float64) string {
+	switch {
+	case usagePercent >= criticalPercent:
+		return QuotaLevelCritical
+	case usagePercent >= warnPercent:
+		return QuotaLevelWarning
+	default:
+		return QuotaLevelOK
+	}
+}
+
+// tightenedRetentionDays возвращает новый срок хранения: на день меньше возраста самого старого события,
+// но не меньше минимального. false — сокращать уже некуда.
+func tightenedRetentionDays(oldestAgeDays, minDays int) (int, bool) {
+	target := oldestAgeDays - 1
+	if target < minDays {
+		return minDays, oldestAgeDays > minDays
+	}
+	return target, true
+}
+
+// DBQuotaStatus возвращает результат последней проверки квоты
+func (s *ANPRService) DBQuotaStatus() DBQuotaStatus {
+	if s.Config().Quota.DBBytes <= 0 {
+		return DBQuotaStatus{Level: QuotaLevelDisabled}
+	}
+	s.quota.mu.Lock()
+	defer s.quota.mu.Unlock()
+	status := s.quota.status
+	if status.Level == "" {
+		status.Level = QuotaLevelOK
+		status.QuotaBytes = s.Config().Quota.DBBytes
+		status.AutoTighten = s.Config().Quota.AutoTighten
+	}
+	return status
+}
+
+// CheckDBQuota измеряет размер БД, пишет предупреждения при пересечении порогов и,
+// если включено DB_QUOTA_AUTO_TIGHTEN, при критическом уровне удаляет самые старые события
+// (по одному дню за проверку, не моложе DB_QUOTA_MIN_RETENTION_DAYS).
+// Postgres не возвращает место ОС после DELETE, но переиспользует его, поэтому рост БД останавливается.
+func (s *ANPRService) CheckDBQuota(ctx context.Context) (DBQuotaStatus, error) {
+	cfg := s.Config().Quota
+	if cfg.DBBytes <= 0 {
+		return DBQuotaStatus{Level: QuotaLevelDisabled}, nil
+	}
+
+	size, err := s.repo.GetDatabaseSize(ctx)
+	if err != nil {
+		return DBQuotaStatus{}, err
+	}
+
+	now := s.clock.Now()
+	usage := float64(size.DatabaseBytes) / float64(cfg.DBBytes) * 100
+
+	s.quota.mu.Lock()
+	status := s.quota.status
+	s.quota.mu.Unlock()
+
+	previousLevel := status.Level
+	status.Level = quotaLevel(usage, cfg.WarnPercent, cfg.CriticalPercent)
+	status.QuotaBytes = cfg.DBBytes
+	status.UsedBytes = size.DatabaseBytes
+	status.EventsTableBytes = size.EventsBytes
+	status.UsagePercent = usage
+	status.CheckedAt = &now
+	status.AutoTighten = cfg.AutoTighten
+
+	logEvent := s.logger(ctx).Info()
+	switch status.Level {
+	case QuotaLevelWarning:
+		logEvent = s.logger(ctx).Warn()
+	case QuotaLevelCritical:
+		logEvent = s.logger(ctx).Error()
+	}
+	if status.Level != QuotaLevelOK {
+		logEvent.
+			Int64("used_bytes", size.DatabaseBytes).
+			Int64("events_bytes", size.EventsBytes).
+			Int64("quota_bytes", cfg.DBBytes).
+			Float64("usage_percent", usage).
+			Msg("database size approaching quota")
+	}
+
+	if status.Level == QuotaLevelCritical && cfg.AutoTighten {
+		if err := s.tightenRetention(ctx, &status, now); err != nil {
+			s.logger(ctx).Error().Err(err).Msg("failed to tighten event retention")
+		}
+	}
+
+	s.quota.mu.Lock()
+	s.quota.status = status
+	s.quota.mu.Unlock()
+
+	if previousLevel != "" && previousLevel != status.Level && s.bus != nil {
+		if err := s.bus.Publish(ctx, eventbus.TopicDBQuota, status); err != nil {
+			s.logger(ctx).Warn().Err(err).Msg("failed to publish db quota status")
+		}
+	}
+
+	return status, nil
+}
+
+func (s *ANPRService) tightenRetention(ctx context.Context, status *DBQuotaStatus, now time.Time) error {
+	oldest, err := s.repo.GetOldestEventTime(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest == nil {
+		return nil
+	}
+
+	ageDays := int(now.Sub(*oldest).Hours() / 24)
+	days, ok := tightenedRetentionDays(ageDays, s.Config().Quota.MinRetentionDays)
+	if !ok {
+		s.logger(ctx).Error().
+			Int("min_retention_days", s.Config().Quota.MinRetentionDays).
+			Msg("database over quota but events are already at minimum retention")
+		return nil
+	}
+
+	deleted, err := s.repo.DeleteOldEvents(ctx, days)
+	if err != nil {
+		return fmt.Errorf("delete events older than %d days: %w", days, err)
+	}
+
+	status.RetentionDays = &days
+	status.LastTightenedAt = &now
+	status.LastTightenDeleted = deleted
+
+	s.logger(ctx).Warn().
+		Int("retention_days", days).
+		Int64("deleted_count", deleted).
+		Msg("event retention tightened due to database quota")
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestQuotaLevel(t *testing.T) {
+	tests := []struct {
+		usage float64
+		want  string
+	}{
+		{10, QuotaLevelOK},
+		{79.9, QuotaLevelOK},
+		{80, QuotaLevelWarning},
+		{94, QuotaLevelWarning},
+		{95, QuotaLevelCritical},
+		{120, QuotaLevelCritical},
+	}
+	for _, tt := range tests {
+		if got := quotaLevel(tt.usage, 80, 95); got != tt.want {
+			t.Errorf("quotaLevel(%v) = %s, want %s", tt.usage, got, tt.want)
+		}
+	}
+}
+
+func TestTightenedRetentionDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldestAge int
+		minDays   int
+		wantDays  int
+		wantOK    bool
+	}{
+		{"one day per check", 90, 30, 89, true},
+		{"clamped to minimum", 30, 30, 30, false},
+		{"just above minimum", 31, 30, 30, true},
+		{"younger than minimum", 5, 30, 30, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, ok := tightenedRetentionDays(tt.oldestAge, tt.minDays)
+			if days != tt.wantDays || ok != tt.wantOK {
+				t.Fatalf("tightenedRetentionDays(%d, %d) = %d, %v; want %d, %v", tt.oldestAge, tt.minDays, days, ok, tt.wantDays, tt.wantOK)
+			}
+		})
+	}
+}
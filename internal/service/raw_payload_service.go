@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// rawPayloadMaxBytes — payload больше этого размера не читается из хранилища в детальную карточку события
+const rawPayloadMaxBytes = 16 << 20
+
+// PayloadArchive — хранилище, куда выносятся исходные payload событий (реализуется *storage.FailoverStore)
+type PayloadArchive interface {
+	Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error)
+}
+
+// UsePayloadArchive задаёт хранилище payload событий. Оно нужно и при INGEST_RAW_PAYLOAD_STORAGE=db:
+// события, сохранённые в режиме storage, читаются из него же.
+func (s *ANPRService) UsePayloadArchive(archive PayloadArchive) {
+	s.archive = archive
+}
+
+// rawPayloadKey — ключ объекта с payload события
+func rawPayloadKey(eventID uuid.UUID) string {
+	return "raw_payloads/" + eventID.String() + ".json"
+}
+
+// archiveRawPayload в режиме INGEST_RAW_PAYLOAD_STORAGE=storage загружает payload события в хранилище
+// и убирает его из события, оставляя ключ. Если загрузить не удалось, payload сохраняется в БД как раньше.
+func (s *ANPRService) archiveRawPayload(ctx context.Context, event *anpr.Event) {
+	if s.Config().Ingest.RawPayloadStorage != config.RawPayloadStorageObject || s.archive == nil || len(event.RawPayload) == 0 {
+		return
+	}
+	body, err := json.Marshal(event.RawPayload)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Msg("failed to encode raw payload, keeping it in the database")
+		return
+	}
+	key := rawPayloadKey(event.ID)
+	if _, err := s.archive.Upload(ctx, key, bytes.NewReader(body), int64(len(body)), "application/json"); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Msg("failed to archive raw payload, keeping it in the database")
+		return
+	}
+	event.RawPayloadKey = key
+	event.RawPayload = nil
+}
+
+// eventRawPayload возвращает payload события из БД или, если он вынесен, из хранилища.
+// Недоступность хранилища не ошибка: карточка события отдаётся без payload.
+func (s *ANPRService) eventRawPayload(ctx context.Context, event *repository.ANPREvent) json.RawMessage {
+	if len(event.RawPayload) > 0 {
+		return json.RawMessage(event.RawPayload)
+	}
+	if event.RawPayloadKey == nil || s.archive == nil {
+		return nil
+	}
+
+	body, _, _, err := s.archive.Open(ctx, *event.RawPayloadKey)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Str("key", *event.RawPayloadKey).Msg("failed to load archived raw payload")
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, rawPayloadMaxBytes))
+	if err != nil || !json.Valid(data) {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Str("key", *event.RawPayloadKey).Msg("archived raw payload is unreadable")
+		return nil
+	}
+	return json.RawMessage(data)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// fakeArchive — хранилище payload в памяти; err возвращается из Upload
+type fakeArchive struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (a *fakeArchive) Upload(_ context.Context, key string, body io.Reader, _ int64, _ string) (string, error) {
+	if a.err != nil {
+		return "", a.err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	a.objects[key] = data
+	return "https://storage.example.com/" + key, nil
+}
+
+func (a *fakeArchive) Open(_ context.Context, key string) (io.ReadCloser, int64, string, error) {
+	data, ok := a.objects[key]
+	if !ok {
+		return nil, 0, "", errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), "application/json", nil
+}
+
+func TestArchiveRawPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		uploadErr   error
+		wantArchive bool
+	}{
+		{name: "db mode keeps payload in the database", mode: config.RawPayloadStorageDB},
+		{name: "storage mode moves payload to storage", mode: config.RawPayloadStorageObject, wantArchive: true},
+		{name: "upload failure keeps payload in the database", mode: config.RawPayloadStorageObject, uploadErr: errors.New("bucket unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Ingest.RawPayloadStorage = tt.mode
+			svc, _ := newTestService(t, cfg)
+			archive := &fakeArchive{objects: map[string][]byte{}, err: tt.uploadErr}
+			svc.UsePayloadArchive(archive)
+
+			event := &anpr.Event{ID: uuid.New()}
+			event.RawPayload = map[string]interface{}{"xml": "<EventNotificationAlert/>"}
+			svc.archiveRawPayload(context.Background(), event)
+
+			key := rawPayloadKey(event.ID)
+			if !tt.wantArchive {
+				if event.RawPayloadKey != "" || event.RawPayload == nil || len(archive.objects) != 0 {
+					t.Fatalf("payload was archived: key=%q payload=%v", event.RawPayloadKey, event.RawPayload)
+				}
+				return
+			}
+			if event.RawPayloadKey != key || event.RawPayload != nil {
+				t.Fatalf("key = %q, payload = %v; want key %q and no payload", event.RawPayloadKey, event.RawPayload, key)
+			}
+
+			// Детальная карточка события читает вынесенный payload из хранилища
+			raw := svc.eventRawPayload(context.Background(), &repository.ANPREvent{ID: event.ID, RawPayloadKey: &key})
+			var got map[string]interface{}
+			if err := json.Unmarshal(raw, &got); err != nil || got["xml"] != "<EventNotificationAlert/>" {
+				t.Fatalf("eventRawPayload() = %s, err = %v", raw, err)
+			}
+		})
+	}
+}
+
+func TestEventRawPayloadPrefersDatabase(t *testing.T) {
+	svc, _ := newTestService(t, nil)
+	svc.UsePayloadArchive(&fakeArchive{objects: map[string][]byte{}})
+
+	key := "raw_payloads/missing.json"
+	event := &repository.ANPREvent{ID: uuid.New(), RawPayload: datatypes.JSON(`{"xml":"db"}`)}
+	if raw := svc.eventRawPayload(context.Background(), event); string(raw) != `{"xml":"db"}` {
+		t.Fatalf("eventRawPayload() = %s, want payload from the database", raw)
+	}
+
+	event = &repository.ANPREvent{ID: uuid.New(), RawPayloadKey: &key}
+	if raw := svc.eventRawPayload(context.Background(), event); raw != nil {
+		t.Fatalf("eventRawPayload() = %s, want nil for a missing object", raw)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/ingest/hikvisionpush"
+	"anpr-service/internal/repository"
+)
+
+const (
+	// reprocessBatchSize — сколько событий читается из БД за один запрос
+	reprocessBatchSize = 500
+	// reprocessMaxRange — самый длинный период одного запуска, чтобы запрос укладывался в таймаут
+	reprocessMaxRange = 31 * 24 * time.Hour
+	// reprocessMaxDiffs — сколько изменённых событий перечисляется в ответе; счётчики считаются по всем
+	reprocessMaxDiffs = 1000
+)
+
+// ReprocessFieldChange — изменение одной колонки события; null — значения нет
+type ReprocessFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ReprocessEventDiff — изменения события после повторного разбора
+type ReprocessEventDiff struct {
+	EventID   uuid.UUID              `json:"event_id"`
+	EventTime time.Time              `json:"event_time"`
+	Changes   []ReprocessFieldChange `json:"changes"`
+}
+
+// ReprocessResult — итог повторного разбора payload за период
+type ReprocessResult struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	DryRun bool      `json:"dry_run"`
+	// Scanned — события периода с сохранённым payload
+	Scanned int `json:"scanned"`
+	// Skipped — payload без уведомления камеры, недоступный или неразборный
+	Skipped int `json:"skipped"`
+	Changed int `json:"changed"`
+	// Updated — сохранённые изменения; в режиме dry_run всегда 0
+	Updated        int                  `json:"updated"`
+	Diffs          []ReprocessEventDiff `json:"diffs"`
+	DiffsTruncated bool                 `json:"diffs_truncated"`
+}
+
+// ReprocessEvents заново разбирает уведомления камер событий [from, to) и пересчитывает производные колонки
+// (repository.EventDerivedColumns) так же, как при приёме. Номер и время события не меняются: от них
+// зависят рейсы, решения о доступе и поправка часов. В режиме dryRun изменения только возвращаются.
+func (s *ANPRService) ReprocessEvents(ctx context.Context, from, to time.Time, dryRun bool) (*ReprocessResult, error) {
+	if _, err := requirePrincipal(ctx, canReprocessEvents); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if to.Sub(from) > reprocessMaxRange {
+		return nil, fmt.Errorf("%w: period must not exceed %d days", ErrInvalidInput, int(reprocessMaxRange.Hours()/24))
+	}
+
+	result := &ReprocessResult{From: from.UTC(), To: to.UTC(), DryRun: dryRun, Diffs: []ReprocessEventDiff{}}
+	afterTime, afterID := from, uuid.Nil
+	for {
+		events, err := s.repo.ListRawPayloadEvents(ctx, from, to, afterTime, afterID, reprocessBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			event := &events[i]
+			result.Scanned++
+
+			updated, ok := s.reparseEvent(ctx, event)
+			if !ok {
+				result.Skipped++
+				continue
+			}
+			changes := diffDerivedFields(event, updated)
+			if len(changes) == 0 {
+				continue
+			}
+			result.Changed++
+			if len(result.Diffs) < reprocessMaxDiffs {
+				result.Diffs = append(result.Diffs, ReprocessEventDiff{EventID: event.ID, EventTime: event.EventTime.UTC(), Changes: changes})
+			} else {
+				result.DiffsTruncated = true
+			}
+			if dryRun {
+				continue
+			}
+			if err := s.repo.UpdateEventDerivedFields(ctx, updated); err != nil {
+				return nil, err
+			}
+			result.Updated++
+		}
+		if len(events) < reprocessBatchSize {
+			break
+		}
+		last := events[len(events)-1]
+		afterTime, afterID = last.EventTime, last.ID
+	}
+
+	s.logger(ctx).Info().
+		Time("from", from).
+		Time("to", to).
+		Bool("dry_run", dryRun).
+		Int("scanned", result.Scanned).
+		Int("skipped", result.Skipped).
+		Int("changed", result.Changed).
+		Int("updated", result.Updated).
+		Msg("events reprocessed")
+	return result, nil
+}
+
+// reparseEvent разбирает уведомление камеры (XML или JSON) из payload события и возвращает копию
+// события с пересчитанными производными колонками; false — уведомления нет или оно не разбирается
+func (s *ANPRService) reparseEvent(ctx context.Context, event *repository.ANPREvent) (*repository.ANPREvent, bool) {
+	var raw map[string]interface{}
+	payload := s.eventRawPayload(ctx, event)
+	if len(payload) == 0 || json.Unmarshal(payload, &raw) != nil {
+		return nil, false
+	}
+	var notification *hikvision.Notification
+	if text, _ := raw["xml"].(string); strings.TrimSpace(text) != "" {
+		notification = &hikvision.Notification{Format: hikvision.FormatXML, Body: []byte(text)}
+	} else if text, _ := raw[hikvisionpush.RawJSONKey].(string); strings.TrimSpace(text) != "" {
+		notification = &hikvision.Notification{Format: hikvision.FormatJSON, Body: []byte(text)}
+	} else {
+		return nil, false
+	}
+	hikEvent, err := notification.Event()
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", event.ID.String()).Msg("stored camera notification is unparsable")
+		return nil, false
+	}
+	parsed := hikEvent.ToEventPayload(nil, s.CameraLocation(ctx, event.CameraID))
+
+	updated := *event
+	// Модель камеры без deviceName при приёме берётся из настроек, поэтому пустое значение её не стирает
+	if parsed.CameraModel != "" {
+		updated.CameraModel = optionalString(parsed.CameraModel)
+	}
+	// Направление, которого нет в уведомлении, было выведено при приёме (resolveDirection) и остаётся прежним
+	if direction := strings.ToLower(strings.TrimSpace(parsed.Direction)); direction != "" && direction != "unknown" {
+		updated.Direction = optionalString(direction)
+		updated.DirectionSource = optionalString(anpr.DirectionSourceReported)
+	}
+	updated.Lane = nil
+	if parsed.Lane != 0 {
+		updated.Lane = &parsed.Lane
+	}
+	updated.Confidence = nil
+	if parsed.Confidence != 0 {
+		updated.Confidence = &parsed.Confidence
+	}
+	rawVehicleType := strings.TrimSpace(parsed.Vehicle.Type)
+	updated.VehicleType = optionalString(anpr.NormalizeVehicleType(rawVehicleType))
+	updated.VehicleTypeRaw = optionalString(rawVehicleType)
+	updated.VehicleColor = optionalString(parsed.Vehicle.Color)
+	updated.VehicleBrand = optionalString(parsed.Vehicle.Brand)
+	updated.VehicleModel = optionalString(parsed.Vehicle.Model)
+	updated.VehicleCountry = optionalString(parsed.Vehicle.Country)
+	updated.VehiclePlateColor = optionalString(parsed.Vehicle.PlateColor)
+	updated.VehicleSpeed = parsed.Vehicle.Speed
+	updated.SnapshotURL = optionalString(parsed.SnapshotURL)
+	return &updated, true
+}
+
+// diffDerivedFields перечисляет производные колонки, значения которых различаются
+func diffDerivedFields(old, updated *repository.ANPREvent) []ReprocessFieldChange {
+	var changes []ReprocessFieldChange
+	changes = appendFieldChange(changes, "camera_model", old.CameraModel, updated.CameraModel)
+	changes = appendFieldChange(changes, "direction", old.Direction, updated.Direction)
+	changes = appendFieldChange(changes, "direction_source", old.DirectionSource, updated.DirectionSource)
+	changes = appendFieldChange(changes, "lane", old.Lane, updated.Lane)
+	changes = appendFieldChange(changes, "confidence", old.Confidence, updated.Confidence)
+	changes = appendFieldChange(changes, "vehicle_color", old.VehicleColor, updated.VehicleColor)
+	changes = appendFieldChange(changes, "vehicle_type", old.VehicleType, updated.VehicleType)
+	changes = appendFieldChange(changes, "vehicle_type_raw", old.VehicleTypeRaw, updated.VehicleTypeRaw)
+	changes = appendFieldChange(changes, "vehicle_brand", old.VehicleBrand, updated.VehicleBrand)
+	changes = appendFieldChange(changes, "vehicle_model", old.VehicleModel, updated.VehicleModel)
+	changes = appendFieldChange(changes, "vehicle_country", old.VehicleCountry, updated.VehicleCountry)
+	changes = appendFieldChange(changes, "vehicle_plate_color", old.VehiclePlateColor, updated.VehiclePlateColor)
+	changes = appendFieldChange(changes, "vehicle_speed", old.VehicleSpeed, updated.VehicleSpeed)
+	changes = appendFieldChange(changes, "snapshot_url", old.SnapshotURL, updated.SnapshotURL)
+	return changes
+}
+
+func appendFieldChange[T comparable](changes []ReprocessFieldChange, field string, old, updated *T) []ReprocessFieldChange {
+	if old == nil && updated == nil {
+		return changes
+	}
+	if old != nil && updated != nil && *old == *updated {
+		return changes
+	}
+	return append(changes, ReprocessFieldChange{Field: field, Old: old, New: updated})
+}
+
+// optionalString возвращает nil для пустой строки — так производные колонки сохраняются при приёме
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
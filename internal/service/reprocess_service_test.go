@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+const reprocessTestXML = `<EventNotificationAlert>
+	<channelID>1</channelID>
+	<deviceName>DS-TCG405</deviceName>
+	<ANPR><licensePlate>123ABC02</licensePlate><direction>forward</direction><laneNo>2</laneNo></ANPR>
+	<vehicleInfo><color>white</color><vehicleType>truck</vehicleType></vehicleInfo>
+	<VehicleGATInfo><colorByGAT>H</colorByGAT></VehicleGATInfo>
+</EventNotificationAlert>`
+
+func TestReprocessEvents(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	rawPayload, _ := json.Marshal(map[string]string{"xml": reprocessTestXML})
+
+	newEvents := func() []repository.ANPREvent {
+		lane := 2
+		return []repository.ANPREvent{
+			{
+				// Событие, сохранённое до исправления разбора: цвет из кода GAT, тип без нормализации
+				ID:           uuid.New(),
+				CameraID:     "1",
+				CameraModel:  stringPtr("DS-TCG405"),
+				Direction:    stringPtr("forward"),
+				Lane:         &lane,
+				VehicleColor: stringPtr("H"),
+				VehicleType:  stringPtr("truck"),
+				EventTime:    from.Add(time.Hour),
+				RawPayload:   datatypes.JSON(rawPayload),
+			},
+			{
+				ID:         uuid.New(),
+				CameraID:   "1",
+				EventTime:  from.Add(2 * time.Hour),
+				RawPayload: datatypes.JSON(`{"xml":"not xml"}`),
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		role        model.UserRole
+		from, to    time.Time
+		dryRun      bool
+		wantUpdated int
+		wantErr     error
+	}{
+		{name: "dry run only reports changes", role: model.UserRoleAkimatAdmin, from: from, to: to, dryRun: true},
+		{name: "updates changed events", role: model.UserRoleAkimatAdmin, from: from, to: to, wantUpdated: 1},
+		{name: "empty period", role: model.UserRoleAkimatAdmin, from: to, to: from, wantErr: ErrInvalidInput},
+		{name: "period too long", role: model.UserRoleAkimatAdmin, from: from, to: from.AddDate(0, 2, 0), wantErr: ErrInvalidInput},
+		{name: "kgu cannot reprocess", role: model.UserRoleKguZkhAdmin, from: from, to: to, wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: tt.role})
+			events := newEvents()
+			if tt.wantErr == nil {
+				store.EXPECT().ListRawPayloadEvents(gomock.Any(), tt.from, tt.to, tt.from, uuid.Nil, reprocessBatchSize).Return(events, nil)
+				store.EXPECT().GetCamera(gomock.Any(), "1").Return(nil, repository.ErrCameraNotFound).AnyTimes()
+			}
+			if tt.wantUpdated > 0 {
+				store.EXPECT().UpdateEventDerivedFields(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event *repository.ANPREvent) error {
+					if event.ID != events[0].ID || event.VehicleColor == nil || *event.VehicleColor != "white" {
+						t.Errorf("UpdateEventDerivedFields() event = %+v", event)
+					}
+					return nil
+				})
+			}
+
+			result, err := svc.ReprocessEvents(ctx, tt.from, tt.to, tt.dryRun)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReprocessEvents() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReprocessEvents() error = %v", err)
+			}
+			if result.Scanned != 2 || result.Skipped != 1 || result.Changed != 1 || result.Updated != tt.wantUpdated {
+				t.Fatalf("ReprocessEvents() = %+v", result)
+			}
+
+			changed := map[string]bool{}
+			for _, change := range result.Diffs[0].Changes {
+				changed[change.Field] = true
+			}
+			for _, field := range []string{"vehicle_color", "vehicle_type_raw"} {
+				if !changed[field] {
+					t.Errorf("diff has no %s: %+v", field, result.Diffs[0].Changes)
+				}
+			}
+			for _, field := range []string{"camera_model", "direction", "lane", "vehicle_type"} {
+				if changed[field] {
+					t.Errorf("diff has unchanged %s: %+v", field, result.Diffs[0].Changes)
+				}
+			}
+		})
+	}
+}
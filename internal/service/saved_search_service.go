@@ -0,0 +1,501 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/i18n"
+	"anpr-service/internal/mailer"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/snow/calc"
+)
+
+// Расписания выгрузки сохранённого поиска
+const (
+	// SavedSearchScheduleDaily — каждый день в send_at, события за прошедшие сутки
+	SavedSearchScheduleDaily = "daily"
+	// SavedSearchScheduleWeekly — по понедельникам в send_at, события за прошедшую неделю
+	SavedSearchScheduleWeekly = "weekly"
+)
+
+// Каналы доставки выгрузки сохранённого поиска
+const (
+	// ReportChannelTelegram — файлом в чат Telegram (target — ID чата)
+	ReportChannelTelegram = "telegram"
+	// ReportChannelEmail — вложением письма (target — адреса через запятую)
+	ReportChannelEmail = "email"
+)
+
+const (
+	// savedSearchDefaultSendAt — время выгрузки по расписанию, если send_at не задан
+	savedSearchDefaultSendAt = "08:00"
+	// savedSearchMaxPerUser — сколько поисков может сохранить пользователь
+	savedSearchMaxPerUser = 20
+	// savedSearchMaxNameLength — предельная длина названия в символах
+	savedSearchMaxNameLength = 100
+	// savedSearchExportMaxRows — предел строк выгрузки: больше — сузить фильтры
+	savedSearchExportMaxRows = 5000
+	// savedSearchDueBatch — сколько выгрузок по расписанию отправляется за один запуск задачи
+	savedSearchDueBatch = 20
+	// savedSearchDeliveryInterval — как часто задача ищет выгрузки, которым пора уйти
+	savedSearchDeliveryInterval = 15 * time.Minute
+)
+
+// ReportTelegram отправляет файлы в чаты (реализуется *telegram.Client)
+type ReportTelegram interface {
+	SendDocument(ctx context.Context, chatID, caption, filename string, data []byte) error
+}
+
+// ReportMailer отправляет письма (реализуется *mailer.Client)
+type ReportMailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
+
+// UseReportDelivery задаёт каналы доставки выгрузок сохранённых поисков; nil — канал не настроен
+func (s *ANPRService) UseReportDelivery(telegram ReportTelegram, mail ReportMailer) {
+	s.reportTelegram = telegram
+	s.reportMailer = mail
+}
+
+// SavedSearchInput — сохраняемый поиск. Пустой Schedule — без выгрузки по расписанию.
+type SavedSearchInput struct {
+	Name     string
+	Filters  EventFilters
+	Schedule string
+	// SendAt — время выгрузки "HH:MM" в CAMERA_DEFAULT_TIMEZONE; пусто — savedSearchDefaultSendAt
+	SendAt  string
+	Channel string
+	Target  string
+}
+
+// SavedSearchInfo — сохранённый поиск для API
+type SavedSearchInfo struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Filters   EventFilters `json:"filters"`
+	Schedule  *string      `json:"schedule,omitempty"`
+	SendAt    *string      `json:"send_at,omitempty"`
+	Channel   *string      `json:"channel,omitempty"`
+	Target    *string      `json:"target,omitempty"`
+	NextRunAt *time.Time   `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time   `json:"last_run_at,omitempty"`
+	LastError *string      `json:"last_error,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// CreateSavedSearch сохраняет поиск пользователю запроса
+func (s *ANPRService) CreateSavedSearch(ctx context.Context, input SavedSearchInput) (*SavedSearchInfo, error) {
+	principal, err := requirePrincipal(ctx, canSaveSearches)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountSavedSearches(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= savedSearchMaxPerUser {
+		return nil, fmt.Errorf("%w: at most %d saved searches per user", ErrInvalidInput, savedSearchMaxPerUser)
+	}
+
+	search := &repository.SavedSearch{UserID: principal.UserID}
+	// Пользователи полигона видят только события полигонов своей организации, выгрузки тоже
+	if principal.IsLandfill() {
+		orgID := principal.OrgID
+		search.OrganizationID = &orgID
+	}
+	if err := s.applySavedSearchInput(search, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSavedSearch(ctx, search); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("saved_search_id", search.ID.String()).
+		Str("user_id", principal.UserID.String()).
+		Str("schedule", stringOrEmpty(search.Schedule)).
+		Msg("saved search created")
+	return toSavedSearchInfo(*search), nil
+}
+
+// UpdateSavedSearch заменяет название, фильтры и расписание поиска пользователя запроса
+func (s *ANPRService) UpdateSavedSearch(ctx context.Context, id uuid.UUID, input SavedSearchInput) (*SavedSearchInfo, error) {
+	search, err := s.ownSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applySavedSearchInput(search, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSavedSearch(ctx, search); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("saved_search_id", id.String()).Msg("saved search updated")
+	return toSavedSearchInfo(*search), nil
+}
+
+// ListSavedSearches возвращает поиски пользователя запроса
+func (s *ANPRService) ListSavedSearches(ctx context.Context) ([]SavedSearchInfo, error) {
+	principal, err := requirePrincipal(ctx, canSaveSearches)
+	if err != nil {
+		return nil, err
+	}
+	searches, err := s.repo.ListSavedSearches(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SavedSearchInfo, 0, len(searches))
+	for _, search := range searches {
+		result = append(result, *toSavedSearchInfo(search))
+	}
+	return result, nil
+}
+
+// DeleteSavedSearch удаляет поиск; чужие поиски для пользователя не существуют
+func (s *ANPRService) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	principal, err := requirePrincipal(ctx, canSaveSearches)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteSavedSearch(ctx, id, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: saved search not found", ErrNotFound)
+	}
+	s.logger(ctx).Info().Str("saved_search_id", id.String()).Str("user_id", principal.UserID.String()).Msg("saved search deleted")
+	return nil
+}
+
+// ExportSavedSearch выгружает события поиска за период в CSV. from и to — RFC 3339; по умолчанию
+// последние сутки.
+func (s *ANPRService) ExportSavedSearch(ctx context.Context, id uuid.UUID, from, to string) ([]byte, string, error) {
+	search, err := s.ownSavedSearch(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	toTime := s.clock.Now()
+	if to != "" {
+		toTime, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid to time format, use RFC3339", ErrInvalidInput)
+		}
+	}
+	fromTime := toTime.Add(-24 * time.Hour)
+	if from != "" {
+		fromTime, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid from time format, use RFC3339", ErrInvalidInput)
+		}
+	}
+	if !fromTime.Before(toTime) {
+		return nil, "", fmt.Errorf("%w: to time must be after from time", ErrInvalidInput)
+	}
+	data, _, filename, err := s.savedSearchCSV(ctx, *search, fromTime, toTime)
+	return data, filename, err
+}
+
+// DeliverSavedSearches — задача JobSavedSearchDelivery: выгружает поиски, которым по расписанию пора уйти,
+// и отправляет их в Telegram или на почту. Выгрузка охватывает сутки или неделю до времени по расписанию.
+// Ошибка доставки записывается в поиск (last_error), а следующая выгрузка назначается по расписанию:
+// пропущенные выгрузки (сервис был остановлен) не догоняются.
+func (s *ANPRService) DeliverSavedSearches(ctx context.Context) error {
+	now := s.clock.Now()
+	searches, err := s.repo.ListDueSavedSearches(ctx, now, savedSearchDueBatch)
+	if err != nil {
+		return err
+	}
+	loc := s.defaultCameraLocation()
+	failed := 0
+	for _, search := range searches {
+		scheduledAt := *search.NextRunAt
+		var runErr *string
+		if err := s.deliverSavedSearch(ctx, search, scheduledAt); err != nil {
+			failed++
+			msg := err.Error()
+			runErr = &msg
+			s.logger(ctx).Warn().Err(err).Str("saved_search_id", search.ID.String()).Msg("failed to deliver saved search")
+		}
+		next := nextSavedSearchRun(stringOrEmpty(search.Schedule), stringOrEmpty(search.SendAt), now, loc)
+		if err := s.repo.FinishSavedSearchRun(ctx, search.ID, scheduledAt, s.clock.Now(), next, runErr); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d saved search deliveries failed", failed, len(searches))
+	}
+	return nil
+}
+
+// deliverSavedSearch выгружает поиск за период, который заканчивается в scheduledAt, и отправляет его
+func (s *ANPRService) deliverSavedSearch(ctx context.Context, search repository.SavedSearch, scheduledAt time.Time) error {
+	period := 24 * time.Hour
+	if stringOrEmpty(search.Schedule) == SavedSearchScheduleWeekly {
+		period = 7 * 24 * time.Hour
+	}
+	from := scheduledAt.Add(-period)
+	data, rows, filename, err := s.savedSearchCSV(ctx, search, from, scheduledAt)
+	if err != nil {
+		return err
+	}
+
+	loc := s.defaultCameraLocation()
+	lang := s.notificationLanguage()
+	caption := i18n.Text(lang, "saved_search.caption", search.Name, rows,
+		from.In(loc).Format("02.01.2006 15:04"), scheduledAt.In(loc).Format("02.01.2006 15:04"))
+	target := stringOrEmpty(search.Target)
+	switch stringOrEmpty(search.Channel) {
+	case ReportChannelTelegram:
+		if s.reportTelegram == nil {
+			return fmt.Errorf("telegram delivery is not configured")
+		}
+		return s.reportTelegram.SendDocument(ctx, target, caption, filename, data)
+	case ReportChannelEmail:
+		if s.reportMailer == nil {
+			return fmt.Errorf("email delivery is not configured")
+		}
+		return s.reportMailer.Send(ctx, mailer.Message{
+			To:          splitEmailTargets(target),
+			Subject:     caption,
+			Body:        i18n.Text(lang, "saved_search.body"),
+			Attachments: []mailer.Attachment{{Filename: filename, ContentType: "text/csv; charset=utf-8", Data: data}},
+		})
+	default:
+		return fmt.Errorf("unknown delivery channel %q", stringOrEmpty(search.Channel))
+	}
+}
+
+// ownSavedSearch возвращает поиск пользователя запроса; чужой поиск не найден
+func (s *ANPRService) ownSavedSearch(ctx context.Context, id uuid.UUID) (*repository.SavedSearch, error) {
+	principal, err := requirePrincipal(ctx, canSaveSearches)
+	if err != nil {
+		return nil, err
+	}
+	search, err := s.repo.GetSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if search.UserID != principal.UserID {
+		return nil, fmt.Errorf("%w: saved search not found", ErrNotFound)
+	}
+	return search, nil
+}
+
+// applySavedSearchInput проверяет поиск и переносит его в search. Изменение расписания назначает
+// следующую выгрузку заново и сбрасывает ошибку прошлой.
+func (s *ANPRService) applySavedSearchInput(search *repository.SavedSearch, input SavedSearchInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > savedSearchMaxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidInput, savedSearchMaxNameLength)
+	}
+	filters := EventFilters{
+		Plate:       strings.TrimSpace(input.Filters.Plate),
+		Direction:   strings.ToLower(strings.TrimSpace(input.Filters.Direction)),
+		VehicleType: strings.TrimSpace(input.Filters.VehicleType),
+		Source:      strings.TrimSpace(input.Filters.Source),
+		PolygonID:   strings.TrimSpace(input.Filters.PolygonID),
+		TimeField:   strings.ToLower(strings.TrimSpace(input.Filters.TimeField)),
+	}
+	if _, err := eventFiltersSearch(filters, nil); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search filters: %w", err)
+	}
+
+	search.Name = name
+	search.Filters = datatypes.JSON(encoded)
+	search.Schedule, search.SendAt, search.Channel, search.Target = nil, nil, nil, nil
+	search.NextRunAt, search.LastError = nil, nil
+
+	schedule := strings.ToLower(strings.TrimSpace(input.Schedule))
+	if schedule == "" {
+		return nil
+	}
+	if schedule != SavedSearchScheduleDaily && schedule != SavedSearchScheduleWeekly {
+		return fmt.Errorf("%w: schedule must be %s or %s", ErrInvalidInput, SavedSearchScheduleDaily, SavedSearchScheduleWeekly)
+	}
+	sendAt := strings.TrimSpace(input.SendAt)
+	if sendAt == "" {
+		sendAt = savedSearchDefaultSendAt
+	}
+	minutes, err := parseClockMinutes(sendAt)
+	if err != nil || minutes >= 24*60 {
+		return fmt.Errorf("%w: send_at must be HH:MM", ErrInvalidInput)
+	}
+	sendAt = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+
+	channel := strings.ToLower(strings.TrimSpace(input.Channel))
+	target := strings.TrimSpace(input.Target)
+	switch channel {
+	case ReportChannelTelegram:
+		if s.reportTelegram == nil {
+			return fmt.Errorf("%w: telegram delivery is not configured", ErrInvalidInput)
+		}
+		if target == "" {
+			return fmt.Errorf("%w: target is required (telegram chat id)", ErrInvalidInput)
+		}
+	case ReportChannelEmail:
+		if s.reportMailer == nil {
+			return fmt.Errorf("%w: email delivery is not configured", ErrInvalidInput)
+		}
+		addresses, err := mail.ParseAddressList(target)
+		if err != nil {
+			return fmt.Errorf("%w: target must be a comma-separated list of email addresses", ErrInvalidInput)
+		}
+		emails := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			emails = append(emails, address.Address)
+		}
+		target = strings.Join(emails, ",")
+	default:
+		return fmt.Errorf("%w: channel must be %s or %s", ErrInvalidInput, ReportChannelTelegram, ReportChannelEmail)
+	}
+
+	next := nextSavedSearchRun(schedule, sendAt, s.clock.Now(), s.defaultCameraLocation())
+	search.Schedule, search.SendAt, search.Channel, search.Target = &schedule, &sendAt, &channel, &target
+	search.NextRunAt = &next
+	return nil
+}
+
+// savedSearchCSV выгружает события поиска за [from, to] в CSV (время в CAMERA_DEFAULT_TIMEZONE, новые
+// события первыми, как в /api/v1/events). Возвращает файл, число событий и имя файла.
+func (s *ANPRService) savedSearchCSV(ctx context.Context, search repository.SavedSearch, from, to time.Time) ([]byte, int, string, error) {
+	var filters EventFilters
+	if err := json.Unmarshal(search.Filters, &filters); err != nil {
+		return nil, 0, "", fmt.Errorf("failed to decode saved search filters: %w", err)
+	}
+	query, err := eventFiltersSearch(filters, search.OrganizationID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	query.From, query.To = &from, &to
+	query.Limit = repository.MaxEventSearchLimit
+
+	var events []repository.ANPREvent
+	for offset := 0; ; offset += query.Limit {
+		query.Offset = offset
+		page, err := s.repo.FindEvents(ctx, query)
+		if err != nil {
+			return nil, 0, "", fmt.Errorf("failed to find events: %w", err)
+		}
+		events = append(events, page...)
+		if len(events) > savedSearchExportMaxRows {
+			return nil, 0, "", fmt.Errorf("%w: more than %d events, narrow the filters", ErrTooManyRows, savedSearchExportMaxRows)
+		}
+		if len(page) < query.Limit {
+			break
+		}
+	}
+
+	loc := s.defaultCameraLocation()
+	data, err := encodeSavedSearchCSV(events, loc)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to encode saved search export: %w", err)
+	}
+	filename := fmt.Sprintf("anpr-events_%s_%s.csv", from.In(loc).Format("2006-01-02"), to.In(loc).Format("2006-01-02"))
+	return data, len(events), filename, nil
+}
+
+// nextSavedSearchRun возвращает первое время выгрузки по расписанию позже after: send_at ("HH:MM") в loc
+// каждый день или по понедельникам
+func nextSavedSearchRun(schedule, sendAt string, after time.Time, loc *time.Location) time.Time {
+	minutes, err := parseClockMinutes(sendAt)
+	if err != nil {
+		minutes, _ = parseClockMinutes(savedSearchDefaultSendAt)
+	}
+	local := after.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+	for !next.After(after) || (schedule == SavedSearchScheduleWeekly && next.Weekday() != time.Monday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, minutes/60, minutes%60, 0, 0, loc)
+	}
+	return next
+}
+
+func encodeSavedSearchCSV(events []repository.ANPREvent, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"event_time", "plate", "raw_plate", "camera_id", "direction", "lane", "vehicle_type", "vehicle_country", "confidence", "source", "polygon_id", "snow_volume_m3"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		record := []string{
+			e.EventTime.In(loc).Format("2006-01-02 15:04:05"),
+			e.NormalizedPlate,
+			e.RawPlate,
+			e.CameraID,
+			stringOrEmpty(e.Direction),
+			"",
+			stringOrEmpty(e.VehicleType),
+			stringOrEmpty(e.VehicleCountry),
+			"",
+			e.Source,
+			uuidOrEmpty(e.PolygonID),
+			"",
+		}
+		if e.Lane != nil {
+			record[5] = strconv.Itoa(*e.Lane)
+		}
+		if e.Confidence != nil {
+			record[8] = strconv.FormatFloat(*e.Confidence, 'f', 2, 64)
+		}
+		if e.SnowVolumeM3 != nil {
+			record[11] = strconv.FormatFloat(*e.SnowVolumeM3, 'f', calc.VolumeDecimals, 64)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// splitEmailTargets разбирает адреса получателей, сохранённые через запятую
+func splitEmailTargets(target string) []string {
+	var emails []string
+	for _, part := range strings.Split(target, ",") {
+		if email := strings.TrimSpace(part); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+func toSavedSearchInfo(search repository.SavedSearch) *SavedSearchInfo {
+	info := &SavedSearchInfo{
+		ID:        search.ID.String(),
+		Name:      search.Name,
+		Schedule:  search.Schedule,
+		SendAt:    search.SendAt,
+		Channel:   search.Channel,
+		Target:    search.Target,
+		NextRunAt: search.NextRunAt,
+		LastRunAt: search.LastRunAt,
+		LastError: search.LastError,
+		CreatedAt: search.CreatedAt,
+		UpdatedAt: search.UpdatedAt,
+	}
+	// Фильтры сохраняются только сервисом, поэтому всегда разбираются
+	_ = json.Unmarshal(search.Filters, &info.Filters)
+	return info
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/mailer"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+type fakeReportTelegram struct {
+	chatID, caption, filename string
+	data                      []byte
+}
+
+func (f *fakeReportTelegram) SendDocument(_ context.Context, chatID, caption, filename string, data []byte) error {
+	f.chatID, f.caption, f.filename, f.data = chatID, caption, filename, data
+	return nil
+}
+
+type fakeReportMailer struct {
+	err  error
+	sent []mailer.Message
+}
+
+func (f *fakeReportMailer) Send(_ context.Context, msg mailer.Message) error {
+	f.sent = append(f.sent, msg)
+	return f.err
+}
+
+func TestNextSavedSearchRun(t *testing.T) {
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Skip("Asia/Almaty timezone is not available")
+	}
+	// testNow — 16.01.2025 03:00 по Алматы (четверг)
+	tests := []struct {
+		name     string
+		schedule string
+		sendAt   string
+		want     time.Time
+	}{
+		{name: "daily later today", schedule: SavedSearchScheduleDaily, sendAt: "08:00", want: time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{name: "daily tomorrow", schedule: SavedSearchScheduleDaily, sendAt: "02:30", want: time.Date(2025, 1, 16, 21, 30, 0, 0, time.UTC)},
+		{name: "daily exactly now", schedule: SavedSearchScheduleDaily, sendAt: "03:00", want: time.Date(2025, 1, 16, 22, 0, 0, 0, time.UTC)},
+		{name: "weekly next monday", schedule: SavedSearchScheduleWeekly, sendAt: "08:00", want: time.Date(2025, 1, 20, 3, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextSavedSearchRun(tt.schedule, tt.sendAt, testNow, almaty); !got.Equal(tt.want) {
+				t.Fatalf("nextSavedSearchRun() = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSavedSearch(t *testing.T) {
+	inspector := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatUser})
+	contractor := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleContractorAdmin})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   SavedSearchInput
+		wantErr error
+	}{
+		{name: "contractor", ctx: contractor, input: SavedSearchInput{Name: "night"}, wantErr: ErrForbidden},
+		{name: "missing name", ctx: inspector, input: SavedSearchInput{Name: " "}, wantErr: ErrInvalidInput},
+		{name: "bad direction", ctx: inspector, input: SavedSearchInput{Name: "night", Filters: EventFilters{Direction: "north"}}, wantErr: ErrInvalidInput},
+		{name: "bad schedule", ctx: inspector, input: SavedSearchInput{Name: "night", Schedule: "hourly"}, wantErr: ErrInvalidInput},
+		{name: "bad send_at", ctx: inspector, input: SavedSearchInput{Name: "night", Schedule: "daily", SendAt: "25:00", Channel: "telegram", Target: "-100"}, wantErr: ErrInvalidInput},
+		{name: "bad email", ctx: inspector, input: SavedSearchInput{Name: "night", Schedule: "daily", Channel: "email", Target: "dispatcher"}, wantErr: ErrInvalidInput},
+		{name: "without schedule", ctx: inspector, input: SavedSearchInput{Name: "night", Filters: EventFilters{Plate: "123 abc 02"}}},
+		{name: "scheduled", ctx: inspector, input: SavedSearchInput{Name: "night", Schedule: "Weekly", SendAt: "7:30", Channel: "email", Target: "a@example.kz, Dispatcher <b@example.kz>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			svc.UseReportDelivery(&fakeReportTelegram{}, &fakeReportMailer{})
+			if !errors.Is(tt.wantErr, ErrForbidden) {
+				store.EXPECT().CountSavedSearches(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+			}
+			if tt.wantErr == nil {
+				store.EXPECT().CreateSavedSearch(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			search, err := svc.CreateSavedSearch(tt.ctx, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateSavedSearch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if tt.input.Schedule == "" {
+				if search.NextRunAt != nil || search.Filters.Plate != "123 abc 02" {
+					t.Fatalf("unexpected search: %+v", search)
+				}
+				return
+			}
+			if *search.Schedule != SavedSearchScheduleWeekly || *search.SendAt != "07:30" || *search.Target != "a@example.kz,b@example.kz" {
+				t.Fatalf("unexpected schedule: %+v", search)
+			}
+			if search.NextRunAt == nil || search.NextRunAt.Weekday() != time.Monday {
+				t.Fatalf("next run = %v, want a Monday", search.NextRunAt)
+			}
+		})
+	}
+}
+
+func TestDeliverSavedSearches(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ingest.DefaultCameraTimeZone = "UTC"
+	svc, store := newTestService(t, cfg)
+	chat := &fakeReportTelegram{}
+	mail := &fakeReportMailer{err: errors.New("mailbox unavailable")}
+	svc.UseReportDelivery(chat, mail)
+
+	daily, weekly := SavedSearchScheduleDaily, SavedSearchScheduleWeekly
+	sendAt := "08:00"
+	telegramChannel, emailChannel := ReportChannelTelegram, ReportChannelEmail
+	chatID, emails := "-100", "a@example.kz,b@example.kz"
+	dueAt := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	orgID := uuid.New()
+	night := repository.SavedSearch{
+		ID: uuid.New(), Name: "Ночь", Filters: datatypes.JSON(`{"plate":"123ABC02","direction":"entry"}`),
+		Schedule: &daily, SendAt: &sendAt, Channel: &telegramChannel, Target: &chatID, NextRunAt: &dueAt,
+	}
+	week := repository.SavedSearch{
+		ID: uuid.New(), Name: "Неделя", Filters: datatypes.JSON(`{}`), OrganizationID: &orgID,
+		Schedule: &weekly, SendAt: &sendAt, Channel: &emailChannel, Target: &emails, NextRunAt: &dueAt,
+	}
+	store.EXPECT().ListDueSavedSearches(gomock.Any(), testNow, savedSearchDueBatch).Return([]repository.SavedSearch{night, week}, nil)
+
+	store.EXPECT().FindEvents(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, search repository.EventSearch) ([]repository.ANPREvent, error) {
+		if !search.To.Equal(dueAt) {
+			t.Fatalf("window ends at %v, want %v", search.To, dueAt)
+		}
+		if search.PolygonOrgID != nil {
+			if *search.PolygonOrgID != orgID || !search.From.Equal(dueAt.Add(-7*24*time.Hour)) {
+				t.Fatalf("unexpected weekly search: %+v", search)
+			}
+			return nil, nil
+		}
+		if *search.NormalizedPlate != "123ABC02" || *search.Direction != "entry" || !search.From.Equal(dueAt.Add(-24*time.Hour)) {
+			t.Fatalf("unexpected daily search: %+v", search)
+		}
+		return []repository.ANPREvent{{CameraID: "gate-1", RawPlate: "123 ABC 02", NormalizedPlate: "123ABC02", EventTime: dueAt.Add(-time.Hour), Source: "camera"}}, nil
+	}).Times(2)
+
+	nextDaily := time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC)
+	nextWeekly := time.Date(2025, 1, 20, 8, 0, 0, 0, time.UTC)
+	store.EXPECT().FinishSavedSearchRun(gomock.Any(), night.ID, dueAt, testNow, nextDaily, (*string)(nil)).Return(nil)
+	store.EXPECT().FinishSavedSearchRun(gomock.Any(), week.ID, dueAt, testNow, nextWeekly, gomock.Not(gomock.Nil())).Return(nil)
+
+	if err := svc.DeliverSavedSearches(context.Background()); err == nil {
+		t.Fatal("DeliverSavedSearches() error = nil, want the failed email delivery reported")
+	}
+	if chat.chatID != "-100" || chat.filename != "anpr-events_2025-01-14_2025-01-15.csv" || !strings.Contains(string(chat.data), "123ABC02,123 ABC 02,gate-1") {
+		t.Fatalf("unexpected telegram delivery: %+v (%s)", chat, chat.data)
+	}
+	if len(mail.sent) != 1 || len(mail.sent[0].To) != 2 || len(mail.sent[0].Attachments) != 1 {
+		t.Fatalf("unexpected email delivery: %+v", mail.sent)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeWindow — интервал времени суток в минутах от полуночи; end < start означает переход через полночь
+type timeWindow struct {
+	start int
+	end   int
+}
+
+// ArmedSchedule — расписание, в которое камера учитывает события (например, ночная смена).
+// Пустое расписание означает, что камера активна круглосуточно.
+type ArmedSchedule []timeWindow
+
+// ParseArmedSchedule разбирает расписание вида "20:00-06:00" или "08:00-12:00,20:00-23:30".
+func ParseArmedSchedule(value string) (ArmedSchedule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	var schedule ArmedSchedule
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.Split(part, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid schedule window %q, expected HH:MM-HH:MM", part)
+		}
+		start, err := parseClockMinutes(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+		}
+		end, err := parseClockMinutes(bounds[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("invalid schedule window %q: start equals end", part)
+		}
+		schedule = append(schedule, timeWindow{start: start, end: end})
+	}
+	return schedule, nil
+}
+
+// Contains проверяет, попадает ли момент t (в часовом поясе loc) в расписание
+func (s ArmedSchedule) Contains(t time.Time, loc *time.Location) bool {
+	if len(s) == 0 {
+		return true
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	for _, w := range s {
+		if w.start < w.end {
+			if minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		// Окно через полночь, например 20:00-06:00
+		if minute >= w.start || minute < w.end {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClockMinutes(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", value)
+	}
+	if hours == 24 && minutes != 0 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hours*60 + minutes, nil
+}
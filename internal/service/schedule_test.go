@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArmedScheduleContains(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+
+	tests := []struct {
+		name     string
+		schedule string
+		at       time.Time
+		want     bool
+	}{
+		{
+			name:     "empty schedule is always armed",
+			schedule: "",
+			at:       time.Date(2025, 1, 15, 12, 0, 0, 0, almaty),
+			want:     true,
+		},
+		{
+			name:     "night window before midnight",
+			schedule: "20:00-06:00",
+			at:       time.Date(2025, 1, 15, 22, 30, 0, 0, almaty),
+			want:     true,
+		},
+		{
+			name:     "night window after midnight",
+			schedule: "20:00-06:00",
+			at:       time.Date(2025, 1, 15, 5, 59, 0, 0, almaty),
+			want:     true,
+		},
+		{
+			name:     "daytime outside night window",
+			schedule: "20:00-06:00",
+			at:       time.Date(2025, 1, 15, 12, 0, 0, 0, almaty),
+			want:     false,
+		},
+		{
+			name:     "end bound is exclusive",
+			schedule: "20:00-06:00",
+			at:       time.Date(2025, 1, 15, 6, 0, 0, 0, almaty),
+			want:     false,
+		},
+		{
+			name:     "evaluated in camera zone",
+			schedule: "20:00-06:00",
+			at:       time.Date(2025, 1, 15, 16, 0, 0, 0, time.UTC), // 21:00 Almaty
+			want:     true,
+		},
+		{
+			name:     "multiple windows",
+			schedule: "08:00-10:00, 20:00-23:00",
+			at:       time.Date(2025, 1, 15, 9, 15, 0, 0, almaty),
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseArmedSchedule(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseArmedSchedule(%q) error = %v", tt.schedule, err)
+			}
+			if got := schedule.Contains(tt.at, almaty); got != tt.want {
+				t.Fatalf("Contains(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArmedScheduleInvalid(t *testing.T) {
+	for _, value := range []string{"20:00", "25:00-06:00", "20:00-20:00", "ab:cd-06:00", "20:61-06:00"} {
+		if _, err := ParseArmedSchedule(value); err == nil {
+			t.Errorf("ParseArmedSchedule(%q) expected error", value)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/snow/calc"
+)
+
+// ShiftInfo — смена календаря смен для API
+type ShiftInfo struct {
+	ID        string  `json:"id"`
+	PolygonID *string `json:"polygon_id,omitempty"`
+	Name      string  `json:"name"`
+	ValidFrom string  `json:"valid_from"`
+	ValidTo   *string `json:"valid_to,omitempty"`
+	// Start, End — "HH:MM" в поясе TimeZone; End не позже Start — смена через полночь
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	TimeZone  string    `json:"time_zone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ShiftInput — поля смены; nil — не менять. Пустой polygon_id — смена всех полигонов, пустой valid_to —
+// бессрочная смена, пустой time_zone — CAMERA_DEFAULT_TIMEZONE.
+type ShiftInput struct {
+	PolygonID *string
+	Name      *string
+	// ValidFrom, ValidTo — сезон "YYYY-MM-DD": даты начала смен, ValidTo включительно
+	ValidFrom *string
+	ValidTo   *string
+	Start     *string
+	End       *string
+	TimeZone  *string
+}
+
+// ShiftReportItem — рейсы одной смены
+type ShiftReportItem struct {
+	ShiftID   string  `json:"shift_id"`
+	ShiftName string  `json:"shift_name"`
+	PolygonID *string `json:"polygon_id,omitempty"`
+	// ShiftDate — дата начала смены в её часовом поясе: ночь с 15 на 16 января — 2025-01-15
+	ShiftDate   string  `json:"shift_date"`
+	TotalVolume float64 `json:"total_volume"`
+	TripCount   int64   `json:"trip_count"`
+}
+
+// ShiftReportTotals — объём и число рейсов
+type ShiftReportTotals struct {
+	TotalVolume float64 `json:"total_volume"`
+	TripCount   int64   `json:"trip_count"`
+}
+
+// ShiftReport — рейсы периода по сменам. OutsideShift — рейсы вне окна смены своего полигона
+// (или на полигоне без смены в календаре), в смены они не входят, но входят в Total.
+type ShiftReport struct {
+	Shifts       []ShiftReportItem `json:"shifts"`
+	OutsideShift ShiftReportTotals `json:"outside_shift"`
+	Total        ShiftReportTotals `json:"total"`
+	// OperationalModes — периоды режима storm на полигонах отчёта
+	OperationalModes []OperationalModePeriod `json:"operational_modes"`
+}
+
+// ListShifts возвращает календарь смен
+func (s *ANPRService) ListShifts(ctx context.Context) ([]ShiftInfo, error) {
+	shifts, err := s.repo.ListShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ShiftInfo, 0, len(shifts))
+	for _, shift := range shifts {
+		result = append(result, toShiftInfo(shift))
+	}
+	return result, nil
+}
+
+// CreateShift добавляет смену в календарь
+func (s *ANPRService) CreateShift(ctx context.Context, input ShiftInput) (*ShiftInfo, error) {
+	if input.Name == nil || input.ValidFrom == nil || input.Start == nil || input.End == nil {
+		return nil, fmt.Errorf("%w: name, valid_from, start and end are required", ErrInvalidInput)
+	}
+	shift := &repository.Shift{}
+	if err := s.applyShiftInput(ctx, shift, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateShift(ctx, shift); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("shift_id", shift.ID.String()).Str("name", shift.Name).Msg("shift created")
+
+	info := toShiftInfo(*shift)
+	return &info, nil
+}
+
+// UpdateShift меняет смену. Отчёты за прошедшие смены пересчитываются по новому окну: чтобы сохранить
+// старые итоги, закройте сезон смены (valid_to) и добавьте новую.
+func (s *ANPRService) UpdateShift(ctx context.Context, id uuid.UUID, input ShiftInput) (*ShiftInfo, error) {
+	shift, err := s.repo.GetShift(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyShiftInput(ctx, shift, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShift(ctx, shift); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("shift_id", id.String()).Msg("shift updated")
+
+	info := toShiftInfo(*shift)
+	return &info, nil
+}
+
+// DeleteShift удаляет смену из календаря
+func (s *ANPRService) DeleteShift(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteShift(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	s.logger(ctx).Info().Str("shift_id", id.String()).Msg("shift deleted")
+	return nil
+}
+
+// GetShiftReport возвращает объём и число рейсов за период по сменам календаря смен
+func (s *ANPRService) GetShiftReport(ctx context.Context, filters repository.ReportFilters) (*ShiftReport, error) {
+	rows, err := s.repo.GetShiftStats(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift stats: %w", err)
+	}
+	shifts, err := s.repo.ListShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]repository.Shift, len(shifts))
+	for _, shift := range shifts {
+		byID[shift.ID] = shift
+	}
+
+	report := &ShiftReport{Shifts: make([]ShiftReportItem, 0, len(rows))}
+	for _, row := range rows {
+		report.Total.TotalVolume = calc.Add(report.Total.TotalVolume, row.TotalVolume)
+		report.Total.TripCount += row.TripCount
+		if row.ShiftID == nil || row.ShiftDate == nil {
+			report.OutsideShift.TotalVolume = calc.Add(report.OutsideShift.TotalVolume, row.TotalVolume)
+			report.OutsideShift.TripCount += row.TripCount
+			continue
+		}
+		item := ShiftReportItem{
+			ShiftID:     row.ShiftID.String(),
+			ShiftDate:   row.ShiftDate.Format(time.DateOnly),
+			TotalVolume: calc.Round(row.TotalVolume),
+			TripCount:   row.TripCount,
+		}
+		// Смену могли удалить между запросами: строка остаётся, но без имени
+		if shift, ok := byID[*row.ShiftID]; ok {
+			item.ShiftName = shift.Name
+			if shift.PolygonID != nil {
+				polygonID := shift.PolygonID.String()
+				item.PolygonID = &polygonID
+			}
+		}
+		report.Shifts = append(report.Shifts, item)
+	}
+	report.OperationalModes = s.stormPeriods(ctx, filters)
+	return report, nil
+}
+
+func (s *ANPRService) applyShiftInput(ctx context.Context, shift *repository.Shift, input ShiftInput) error {
+	if input.PolygonID != nil {
+		raw := strings.TrimSpace(*input.PolygonID)
+		if raw == "" {
+			shift.PolygonID = nil
+		} else {
+			polygonID, err := uuid.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+			}
+			if _, err := s.repo.GetPolygon(ctx, polygonID); errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: polygon %s not found", ErrInvalidInput, polygonID)
+			} else if err != nil {
+				return err
+			}
+			shift.PolygonID = &polygonID
+		}
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidInput)
+		}
+		shift.Name = name
+	}
+	if input.ValidFrom != nil {
+		validFrom, err := time.Parse(time.DateOnly, strings.TrimSpace(*input.ValidFrom))
+		if err != nil {
+			return fmt.Errorf("%w: valid_from must be YYYY-MM-DD", ErrInvalidInput)
+		}
+		shift.ValidFrom = validFrom
+	}
+	if input.ValidTo != nil {
+		raw := strings.TrimSpace(*input.ValidTo)
+		if raw == "" {
+			shift.ValidTo = nil
+		} else {
+			validTo, err := time.Parse(time.DateOnly, raw)
+			if err != nil {
+				return fmt.Errorf("%w: valid_to must be YYYY-MM-DD", ErrInvalidInput)
+			}
+			shift.ValidTo = &validTo
+		}
+	}
+	if shift.ValidTo != nil && shift.ValidTo.Before(shift.ValidFrom) {
+		return fmt.Errorf("%w: valid_to must not be before valid_from", ErrInvalidInput)
+	}
+	if input.Start != nil {
+		minute, err := parseShiftClock(*input.Start)
+		if err != nil {
+			return fmt.Errorf("%w: invalid start: %v", ErrInvalidInput, err)
+		}
+		shift.StartMinute = minute
+	}
+	if input.End != nil {
+		minute, err := parseShiftClock(*input.End)
+		if err != nil {
+			return fmt.Errorf("%w: invalid end: %v", ErrInvalidInput, err)
+		}
+		shift.EndMinute = minute
+	}
+	if input.TimeZone != nil {
+		shift.TimeZone = strings.TrimSpace(*input.TimeZone)
+	}
+	if shift.TimeZone == "" {
+		shift.TimeZone = s.Config().Ingest.DefaultCameraTimeZone
+	}
+	if _, err := time.LoadLocation(shift.TimeZone); err != nil {
+		return fmt.Errorf("%w: invalid time_zone", ErrInvalidInput)
+	}
+	return s.checkShiftOverlap(ctx, shift)
+}
+
+// checkShiftOverlap отвергает смену, сезон которой пересекается с сезоном другой смены того же полигона
+// (или другой общей смены): у события должна быть одна смена.
+func (s *ANPRService) checkShiftOverlap(ctx context.Context, shift *repository.Shift) error {
+	shifts, err := s.repo.ListShifts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range shifts {
+		if other.ID == shift.ID || !sameShiftPolygon(other.PolygonID, shift.PolygonID) {
+			continue
+		}
+		if seasonsOverlap(shift.ValidFrom, shift.ValidTo, other.ValidFrom, other.ValidTo) {
+			return fmt.Errorf("%w: season overlaps shift %q", ErrInvalidInput, other.Name)
+		}
+	}
+	return nil
+}
+
+func sameShiftPolygon(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// seasonsOverlap — сезоны [fromA, toA] и [fromB, toB] пересекаются; nil — сезон без конца
+func seasonsOverlap(fromA time.Time, toA *time.Time, fromB time.Time, toB *time.Time) bool {
+	return (toB == nil || !fromA.After(*toB)) && (toA == nil || !fromB.After(*toA))
+}
+
+// parseShiftClock разбирает "HH:MM"; в отличие от расписаний камер, 24:00 не допускается — это 00:00
+func parseShiftClock(value string) (int, error) {
+	minute, err := parseClockMinutes(value)
+	if err != nil {
+		return 0, err
+	}
+	if minute >= 24*60 {
+		return 0, fmt.Errorf("invalid time %q, use 00:00", strings.TrimSpace(value))
+	}
+	return minute, nil
+}
+
+func formatShiftClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func toShiftInfo(shift repository.Shift) ShiftInfo {
+	info := ShiftInfo{
+		ID:        shift.ID.String(),
+		Name:      shift.Name,
+		ValidFrom: shift.ValidFrom.Format(time.DateOnly),
+		Start:     formatShiftClock(shift.StartMinute),
+		End:       formatShiftClock(shift.EndMinute),
+		TimeZone:  shift.TimeZone,
+		CreatedAt: shift.CreatedAt,
+		UpdatedAt: shift.UpdatedAt,
+	}
+	if shift.PolygonID != nil {
+		id := shift.PolygonID.String()
+		info.PolygonID = &id
+	}
+	if shift.ValidTo != nil {
+		validTo := shift.ValidTo.Format(time.DateOnly)
+		info.ValidTo = &validTo
+	}
+	return info
+}
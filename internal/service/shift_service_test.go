@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestCreateShift(t *testing.T) {
+	polygonID := uuid.New()
+	str := func(v string) *string { return &v }
+	winter := repository.Shift{ID: uuid.New(), Name: "Зима", ValidFrom: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)}
+	closed := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	closedWinter := winter
+	closedWinter.ValidTo = &closed
+
+	tests := []struct {
+		name    string
+		input   ShiftInput
+		setup   func(store *mocks.MockANPRStoreMockRecorder)
+		want    func(t *testing.T, shift *repository.Shift)
+		wantErr error
+	}{
+		{
+			name:  "polygon night shift",
+			input: ShiftInput{PolygonID: str(polygonID.String()), Name: str(" Ночь "), ValidFrom: str("2025-11-01"), ValidTo: str("2026-03-31"), Start: str("20:00"), End: str("06:00")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygon(gomock.Any(), polygonID).Return(&repository.Polygon{ID: polygonID}, nil)
+				store.ListShifts(gomock.Any()).Return([]repository.Shift{winter}, nil)
+			},
+			want: func(t *testing.T, shift *repository.Shift) {
+				if shift.Name != "Ночь" || shift.StartMinute != 1200 || shift.EndMinute != 360 || shift.ValidTo == nil {
+					t.Errorf("unexpected shift: %+v", shift)
+				}
+				if shift.TimeZone != "Asia/Qyzylorda" {
+					t.Errorf("time zone = %q, want CAMERA_DEFAULT_TIMEZONE", shift.TimeZone)
+				}
+			},
+		},
+		{
+			name:  "next season after closed one",
+			input: ShiftInput{Name: str("Ночь"), ValidFrom: str("2025-04-01"), Start: str("22:00"), End: str("00:00")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.ListShifts(gomock.Any()).Return([]repository.Shift{closedWinter}, nil)
+			},
+		},
+		{
+			name:  "overlapping global season",
+			input: ShiftInput{Name: str("Ночь"), ValidFrom: str("2025-11-01"), Start: str("20:00"), End: str("06:00")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.ListShifts(gomock.Any()).Return([]repository.Shift{winter}, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "missing fields",
+			input:   ShiftInput{Name: str("Ночь")},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "24:00 is not a shift bound",
+			input:   ShiftInput{Name: str("Ночь"), ValidFrom: str("2025-11-01"), Start: str("20:00"), End: str("24:00")},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "season ends before it starts",
+			input:   ShiftInput{Name: str("Ночь"), ValidFrom: str("2025-11-01"), ValidTo: str("2025-10-01"), Start: str("20:00"), End: str("06:00")},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "invalid time zone",
+			input:   ShiftInput{Name: str("Ночь"), ValidFrom: str("2025-11-01"), Start: str("20:00"), End: str("06:00"), TimeZone: str("Mars/Olympus")},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:  "unknown polygon",
+			input: ShiftInput{PolygonID: str(polygonID.String()), Name: str("Ночь"), ValidFrom: str("2025-11-01"), Start: str("20:00"), End: str("06:00")},
+			setup: func(store *mocks.MockANPRStoreMockRecorder) {
+				store.GetPolygon(gomock.Any(), polygonID).Return(nil, repository.ErrPolygonNotFound)
+			},
+			wantErr: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Ingest.DefaultCameraTimeZone = "Asia/Qyzylorda"
+			svc, store := newTestService(t, cfg)
+			if tt.setup != nil {
+				tt.setup(store.EXPECT())
+			}
+			if tt.wantErr == nil {
+				store.EXPECT().CreateShift(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, shift *repository.Shift) error {
+					if tt.want != nil {
+						tt.want(t, shift)
+					}
+					shift.ID = uuid.New()
+					return nil
+				})
+			}
+			_, err := svc.CreateShift(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetShiftReport(t *testing.T) {
+	shiftID, polygonID := uuid.New(), uuid.New()
+	night := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	svc, store := newTestService(t, &config.Config{})
+	store.EXPECT().GetShiftStats(gomock.Any(), gomock.Any()).Return([]repository.ShiftStat{
+		{ShiftID: &shiftID, ShiftDate: &night, TotalVolume: 27.185, TripCount: 3},
+		{TotalVolume: 4.5, TripCount: 1},
+	}, nil)
+	store.EXPECT().ListShifts(gomock.Any()).Return([]repository.Shift{{ID: shiftID, PolygonID: &polygonID, Name: "Ночь"}}, nil)
+	store.EXPECT().ListPolygonModeChanges(gomock.Any(), nil, testNow).Return(nil, nil)
+
+	report, err := svc.GetShiftReport(context.Background(), repository.ReportFilters{})
+	if err != nil {
+		t.Fatalf("GetShiftReport() error = %v", err)
+	}
+	if len(report.Shifts) != 1 {
+		t.Fatalf("shifts = %+v", report.Shifts)
+	}
+	item := report.Shifts[0]
+	if item.ShiftName != "Ночь" || item.ShiftDate != "2025-01-15" || item.TotalVolume != 27.19 || item.TripCount != 3 {
+		t.Errorf("shift = %+v", item)
+	}
+	if item.PolygonID == nil || *item.PolygonID != polygonID.String() {
+		t.Errorf("polygon_id = %v, want %s", item.PolygonID, polygonID)
+	}
+	if report.OutsideShift != (ShiftReportTotals{TotalVolume: 4.5, TripCount: 1}) {
+		t.Errorf("outside_shift = %+v", report.OutsideShift)
+	}
+	if report.Total != (ShiftReportTotals{TotalVolume: 31.69, TripCount: 4}) {
+		t.Errorf("total = %+v", report.Total)
+	}
+}
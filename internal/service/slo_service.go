@@ -0,0 +1,98 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"anpr-service/internal/repository"
+)
+
+// ingestSLOPeriod — период сводки соблюдения SLO приёма
+const ingestSLOPeriod = 24 * time.Hour
+
+// IngestSLOStat — соблюдение SLO приёма камерой (или всеми камерами) за период
+type IngestSLOStat struct {
+	CameraID string `json:"camera_id,omitempty"`
+	Events   int64  `json:"events"`
+	// WithinTarget — события, записанные в БД не позже target_seconds после event_time
+	WithinTarget int64   `json:"within_target"`
+	P50Seconds   float64 `json:"p50_seconds"`
+	P95Seconds   float64 `json:"p95_seconds"`
+	P99Seconds   float64 `json:"p99_seconds"`
+	// Compliance — доля событий в пределах цели; без событий — 1
+	Compliance float64 `json:"compliance"`
+	Compliant  bool    `json:"compliant"`
+}
+
+// IngestSLOReport — соблюдение SLO приёма за последние сутки. Cameras — сначала камеры с худшим соблюдением.
+type IngestSLOReport struct {
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	TargetSeconds float64         `json:"target_seconds"`
+	Objective     float64         `json:"objective"`
+	Overall       IngestSLOStat   `json:"overall"`
+	Cameras       []IngestSLOStat `json:"cameras"`
+}
+
+// IngestSLO возвращает соблюдение SLO задержки приёма (от event_time камеры до записи в БД) за последние
+// сутки по камерам. Учитываются только события камер: импорт и ручной ввод приходят с задержкой намеренно.
+func (s *ANPRService) IngestSLO(ctx context.Context) (*IngestSLOReport, error) {
+	cfg := s.Config().SLO
+	now := s.clock.Now()
+	report := &IngestSLOReport{
+		From:          now.Add(-ingestSLOPeriod),
+		To:            now,
+		TargetSeconds: cfg.IngestLatencyTarget.Seconds(),
+		Objective:     cfg.IngestObjective,
+		Overall:       IngestSLOStat{Compliance: 1, Compliant: true},
+		Cameras:       []IngestSLOStat{},
+	}
+
+	rows, err := s.repo.GetIngestLatencyStats(ctx, report.From, cfg.IngestLatencyTarget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest latency stats: %w", err)
+	}
+	for _, row := range rows {
+		stat := toIngestSLOStat(row, cfg.IngestObjective)
+		if row.CameraID == nil {
+			report.Overall = stat
+			continue
+		}
+		report.Cameras = append(report.Cameras, stat)
+	}
+	slices.SortStableFunc(report.Cameras, func(a, b IngestSLOStat) int {
+		return cmp.Or(cmp.Compare(a.Compliance, b.Compliance), strings.Compare(a.CameraID, b.CameraID))
+	})
+	return report, nil
+}
+
+func toIngestSLOStat(row repository.IngestLatencyStat, objective float64) IngestSLOStat {
+	stat := IngestSLOStat{
+		Events:       row.Events,
+		WithinTarget: row.WithinTarget,
+		P50Seconds:   roundTo(row.P50, 3),
+		P95Seconds:   roundTo(row.P95, 3),
+		P99Seconds:   roundTo(row.P99, 3),
+		Compliance:   1,
+		Compliant:    true,
+	}
+	if row.CameraID != nil {
+		stat.CameraID = *row.CameraID
+	}
+	if row.Events > 0 {
+		compliance := float64(row.WithinTarget) / float64(row.Events)
+		stat.Compliance = roundTo(compliance, 4)
+		stat.Compliant = compliance >= objective
+	}
+	return stat
+}
+
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	return math.Round(value*scale) / scale
+}
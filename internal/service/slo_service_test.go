@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/clock"
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+func sloTestConfig() *config.Config {
+	return &config.Config{SLO: config.SLOConfig{
+		IngestLatencyTarget: 30 * time.Second,
+		IngestObjective:     0.99,
+		MetricsWindow:       5 * time.Minute,
+	}}
+}
+
+func TestIngestSLO(t *testing.T) {
+	svc, store := newTestService(t, sloTestConfig())
+	gate, yard := "gate-1", "yard-2"
+	store.EXPECT().GetIngestLatencyStats(gomock.Any(), testNow.Add(-24*time.Hour), 30*time.Second).Return([]repository.IngestLatencyStat{
+		{CameraID: &gate, Events: 200, WithinTarget: 199, P50: 1.2, P95: 4.5, P99: 29.99},
+		{CameraID: &yard, Events: 100, WithinTarget: 90, P50: 2, P95: 45.1234, P99: 120},
+		{Events: 300, WithinTarget: 289, P50: 1.5, P95: 40, P99: 110},
+	}, nil)
+
+	report, err := svc.IngestSLO(context.Background())
+	if err != nil {
+		t.Fatalf("IngestSLO() error = %v", err)
+	}
+	if report.TargetSeconds != 30 || report.Objective != 0.99 || !report.To.Equal(testNow) {
+		t.Fatalf("report header = %+v", report)
+	}
+	if report.Overall.Events != 300 || report.Overall.Compliant {
+		t.Fatalf("overall = %+v, want 300 events not compliant", report.Overall)
+	}
+	if len(report.Cameras) != 2 {
+		t.Fatalf("cameras = %+v", report.Cameras)
+	}
+	worst, best := report.Cameras[0], report.Cameras[1]
+	if worst.CameraID != yard || worst.Compliance != 0.9 || worst.Compliant || worst.P95Seconds != 45.123 {
+		t.Errorf("worst camera = %+v", worst)
+	}
+	if best.CameraID != gate || best.Compliance != 0.995 || !best.Compliant {
+		t.Errorf("best camera = %+v", best)
+	}
+}
+
+func TestIngestSLOWithoutEvents(t *testing.T) {
+	svc, store := newTestService(t, sloTestConfig())
+	store.EXPECT().GetIngestLatencyStats(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	report, err := svc.IngestSLO(context.Background())
+	if err != nil {
+		t.Fatalf("IngestSLO() error = %v", err)
+	}
+	if !report.Overall.Compliant || report.Overall.Compliance != 1 || report.Cameras == nil {
+		t.Errorf("report = %+v, want compliant with empty cameras", report)
+	}
+}
+
+func TestWriteMetricsIngestLatency(t *testing.T) {
+	svc, _ := newTestService(t, sloTestConfig())
+	manual := svc.clock.(*clock.Manual)
+
+	// Событие вне окна метрик: в счётчиках остаётся, из перцентилей уходит
+	svc.observeIngestLatency("gate-1", testNow.Add(-time.Minute))
+	manual.Advance(10 * time.Minute)
+	for _, latency := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 40 * time.Second} {
+		svc.observeIngestLatency("gate-1", manual.Now().Add(-latency))
+	}
+	svc.observeIngestLatency("yard-2", manual.Now().Add(-5*time.Second))
+
+	var out strings.Builder
+	if err := svc.WriteMetrics(&out); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"anpr_ingest_latency_slo_target_seconds 30\n",
+		"# TYPE anpr_ingest_latency_seconds summary\n",
+		`anpr_ingest_latency_seconds{quantile="0.5"} 3` + "\n",
+		"anpr_ingest_latency_seconds_count 6\n",
+		`anpr_camera_ingest_latency_seconds{camera_id="gate-1",quantile="0.5"} 2` + "\n",
+		`anpr_camera_ingest_latency_seconds{camera_id="gate-1",quantile="0.99"} 40` + "\n",
+		`anpr_camera_ingest_latency_seconds_count{camera_id="gate-1"} 5` + "\n",
+		`anpr_camera_ingest_latency_slo_breaches_total{camera_id="gate-1"} 2` + "\n",
+		`anpr_camera_ingest_latency_slo_breaches_total{camera_id="yard-2"} 0` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("metrics missing %q:\n%s", want, text)
+		}
+	}
+}
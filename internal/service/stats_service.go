@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// HourlyStatsItem — события камеры за час
+type HourlyStatsItem struct {
+	Bucket   time.Time `json:"bucket"`
+	CameraID string    `json:"camera_id"`
+	Events   int64     `json:"events"`
+	Entries  int64     `json:"entries"`
+	Exits    int64     `json:"exits"`
+}
+
+// HourlyStats — почасовые счётчики событий камер. RefreshedAt — время последнего пересчёта: события,
+// принятые после него, в счётчиках ещё не учтены.
+type HourlyStats struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	TimeZone    string            `json:"timezone"`
+	RefreshedAt *time.Time        `json:"refreshed_at,omitempty"`
+	Items       []HourlyStatsItem `json:"items"`
+}
+
+// HourlyStats возвращает число событий камер по часам из почасовых счётчиков (см. RefreshEventStats), не
+// обращаясь к anpr_events. Часы выводятся в CAMERA_DEFAULT_TIMEZONE; права и фильтры — как у аналитики
+// скорости и полос.
+func (s *ANPRService) HourlyStats(ctx context.Context, query TrafficQuery) (*HourlyStats, error) {
+	filters, err := s.trafficFilters(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetHourlyEventCounts(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	refreshedAt, err := s.repo.GetHourlyEventCountsRefreshedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.defaultCameraLocation()
+	items := make([]HourlyStatsItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, HourlyStatsItem{
+			Bucket:   row.Bucket.In(loc),
+			CameraID: row.CameraID,
+			Events:   row.Events,
+			Entries:  row.Entries,
+			Exits:    row.Exits,
+		})
+	}
+	return &HourlyStats{From: filters.From, To: filters.To, TimeZone: loc.String(), RefreshedAt: refreshedAt, Items: items}, nil
+}
+
+// RefreshEventStats — задача JobEventStatsRefresh: пересчитывает почасовые счётчики событий за последние
+// STATS_REFRESH_LOOKBACK
+func (s *ANPRService) RefreshEventStats(ctx context.Context) error {
+	from := s.clock.Now().Add(-s.Config().Stats.RefreshLookback)
+	rows, err := s.repo.RefreshHourlyEventCounts(ctx, from)
+	if err != nil {
+		return err
+	}
+	s.logger(ctx).Debug().Time("from", from).Int64("rows", rows).Msg("hourly event counts refreshed")
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestHourlyStats(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ingest.DefaultCameraTimeZone = "Asia/Almaty"
+	svc, store := newTestService(t, cfg)
+	orgID := uuid.New()
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleLandfillAdmin, OrgID: orgID})
+
+	hour := testNow.Add(-time.Hour).Truncate(time.Hour)
+	refreshedAt := testNow.Add(-2 * time.Minute)
+	store.EXPECT().GetHourlyEventCounts(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filters repository.TrafficFilters) ([]repository.HourlyEventCount, error) {
+			if filters.PolygonOrgID == nil || *filters.PolygonOrgID != orgID || filters.CameraID == nil || *filters.CameraID != "gate-1" {
+				t.Fatalf("unexpected filters: %+v", filters)
+			}
+			return []repository.HourlyEventCount{{Bucket: hour, CameraID: "gate-1", Events: 7, Entries: 4, Exits: 3}}, nil
+		})
+	store.EXPECT().GetHourlyEventCountsRefreshedAt(gomock.Any()).Return(&refreshedAt, nil)
+
+	stats, err := svc.HourlyStats(ctx, TrafficQuery{CameraID: "gate-1"})
+	if err != nil {
+		t.Fatalf("HourlyStats() error = %v", err)
+	}
+	if stats.TimeZone != "Asia/Almaty" || stats.RefreshedAt == nil || len(stats.Items) != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if item := stats.Items[0]; !item.Bucket.Equal(hour) || item.Bucket.Location().String() != "Asia/Almaty" || item.Events != 7 || item.Entries != 4 {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+}
+
+func TestRefreshEventStats(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Stats.RefreshLookback = 48 * time.Hour
+	svc, store := newTestService(t, cfg)
+	store.EXPECT().RefreshHourlyEventCounts(gomock.Any(), testNow.Add(-48*time.Hour)).Return(int64(12), nil)
+
+	if err := svc.RefreshEventStats(context.Background()); err != nil {
+		t.Fatalf("RefreshEventStats() error = %v", err)
+	}
+}
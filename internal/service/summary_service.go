@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/i18n"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/snow/calc"
+)
+
+// summaryUnmatchedLimit — сколько номеров без сопоставленного снега перечисляется в сводке
+const summaryUnmatchedLimit = 10
+
+// summaryNotificationKind — тип уведомления Telegram для ночной сводки
+const summaryNotificationKind = "nightly_summary"
+
+// summaryGrace — сколько после SUMMARY_SEND_AT сводка ещё отправляется (если сервис был перезапущен в 07:00)
+const summaryGrace = 6 * time.Hour
+
+// SummarySubscriptionInfo — настройки ночной сводки пользователя
+type SummarySubscriptionInfo struct {
+	Enabled        bool      `json:"enabled"`
+	TelegramChatID string    `json:"telegram_chat_id,omitempty"`
+	SendAt         string    `json:"send_at"`
+	TimeZone       string    `json:"timezone"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// SummarySubscriptionInput — изменение подписки; nil — не менять
+type SummarySubscriptionInput struct {
+	Enabled        *bool
+	TelegramChatID *string
+}
+
+// NightlySummary — сводка подрядчика за ночь
+type NightlySummary struct {
+	ContractorID    uuid.UUID               `json:"contractor_id"`
+	From            time.Time               `json:"from"`
+	To              time.Time               `json:"to"`
+	TripCount       int64                   `json:"trip_count"`
+	TotalVolumeM3   float64                 `json:"total_volume_m3"`
+	UnmatchedPlates []repository.PlateCount `json:"unmatched_plates"`
+}
+
+// GetSummarySubscription возвращает подписку пользователя на ночную сводку (выключена, если её нет)
+func (s *ANPRService) GetSummarySubscription(ctx context.Context, userID uuid.UUID) (*SummarySubscriptionInfo, error) {
+	sub, err := s.repo.GetSummarySubscription(ctx, userID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return s.toSummarySubscriptionInfo(sub), nil
+}
+
+// SetSummarySubscription включает или выключает ночную сводку пользователю подрядчика contractorID.
+// Для включения нужен чат Telegram, куда бот будет присылать сводку.
+func (s *ANPRService) SetSummarySubscription(ctx context.Context, userID, contractorID uuid.UUID, input SummarySubscriptionInput) (*SummarySubscriptionInfo, error) {
+	sub, err := s.repo.GetSummarySubscription(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		sub = &repository.SummarySubscription{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+	sub.ContractorID = contractorID
+	if input.TelegramChatID != nil {
+		sub.TelegramChatID = strings.TrimSpace(*input.TelegramChatID)
+	}
+	if input.Enabled != nil {
+		sub.Enabled = *input.Enabled
+	}
+	if sub.Enabled && sub.TelegramChatID == "" {
+		return nil, fmt.Errorf("%w: telegram_chat_id is required to enable the summary", ErrInvalidInput)
+	}
+
+	if err := s.repo.UpsertSummarySubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("user_id", userID.String()).
+		Str("contractor_id", contractorID.String()).
+		Bool("enabled", sub.Enabled).
+		Msg("nightly summary subscription updated")
+	return s.toSummarySubscriptionInfo(sub), nil
+}
+
+func (s *ANPRService) toSummarySubscriptionInfo(sub *repository.SummarySubscription) *SummarySubscriptionInfo {
+	info := &SummarySubscriptionInfo{SendAt: s.Config().Summary.SendAt, TimeZone: s.Config().Summary.TimeZone}
+	if sub != nil {
+		info.Enabled = sub.Enabled
+		info.TelegramChatID = sub.TelegramChatID
+		info.UpdatedAt = sub.UpdatedAt
+	}
+	return info
+}
+
+// RunNightlySummaries раз в минуту проверяет, наступило ли время сводки (SUMMARY_SEND_AT), и ставит
+// сводки подписчикам в очередь уведомлений Telegram. Ключ уведомления — подрядчик, пользователь и
+// дата, поэтому при нескольких репликах или перезапуске сводка не дублируется.
+func (s *ANPRService) RunNightlySummaries(ctx context.Context) {
+	if !s.Config().Summary.Enabled || s.Config().Telegram.BotToken == "" {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var lastSent time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sendAt, ok := s.summaryDue(s.clock.Now())
+		if !ok || sendAt.Equal(lastSent) {
+			continue
+		}
+		if err := s.SendNightlySummaries(ctx, sendAt); err != nil {
+			if ctx.Err() == nil {
+				s.logger(ctx).Warn().Err(err).Msg("failed to send nightly summaries")
+			}
+			continue
+		}
+		lastSent = sendAt
+	}
+}
+
+// summaryDue возвращает сегодняшний момент отправки сводки, если он наступил не позже summaryGrace назад
+func (s *ANPRService) summaryDue(now time.Time) (time.Time, bool) {
+	loc := s.summaryLocation()
+	sendAt := nightStart(now, loc, s.Config().Summary.SendAt)
+	return sendAt, now.Sub(sendAt) < summaryGrace
+}
+
+// SendNightlySummaries собирает сводку за ночь, закончившуюся в sendAt, по каждому подрядчику
+// с подписчиками и ставит её в очередь уведомлений
+func (s *ANPRService) SendNightlySummaries(ctx context.Context, sendAt time.Time) error {
+	subs, err := s.repo.ListEnabledSummarySubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	byContractor := map[uuid.UUID][]repository.SummarySubscription{}
+	var contractors []uuid.UUID
+	for _, sub := range subs {
+		if _, ok := byContractor[sub.ContractorID]; !ok {
+			contractors = append(contractors, sub.ContractorID)
+		}
+		byContractor[sub.ContractorID] = append(byContractor[sub.ContractorID], sub)
+	}
+
+	from := nightStart(sendAt.Add(-time.Minute), s.summaryLocation(), s.Config().Access.NightStart)
+	day := sendAt.In(s.summaryLocation()).Format("2006-01-02")
+	for _, contractorID := range contractors {
+		summary, err := s.NightlySummary(ctx, contractorID, from, sendAt)
+		if err != nil {
+			return err
+		}
+		text := s.formatNightlySummary(summary)
+
+		var notifications []repository.TelegramNotification
+		for _, sub := range byContractor[contractorID] {
+			notifications = append(notifications, repository.TelegramNotification{
+				DedupKey: fmt.Sprintf("summary:%s:%s:%s", contractorID, sub.UserID, day),
+				ChatID:   sub.TelegramChatID,
+				Kind:     summaryNotificationKind,
+				Text:     text,
+			})
+		}
+		if err := s.repo.EnqueueTelegramNotifications(ctx, notifications); err != nil {
+			return err
+		}
+	}
+	s.logger(ctx).Info().Int("contractors", len(contractors)).Int("subscribers", len(subs)).Msg("nightly summaries queued")
+	return nil
+}
+
+// NightlySummary считает рейсы, объём и номера без сопоставленного снега подрядчика за [from, to)
+func (s *ANPRService) NightlySummary(ctx context.Context, contractorID uuid.UUID, from, to time.Time) (*NightlySummary, error) {
+	stats, err := s.repo.GetReportStats(ctx, repository.ReportFilters{
+		ContractorID: &contractorID,
+		From:         from,
+		To:           to.Add(-time.Nanosecond),
+	})
+	if err != nil {
+		return nil, err
+	}
+	unmatched, err := s.repo.GetContractorUnmatchedPlates(ctx, contractorID, from, to, summaryUnmatchedLimit)
+	if err != nil {
+		return nil, err
+	}
+	if unmatched == nil {
+		unmatched = []repository.PlateCount{}
+	}
+	return &NightlySummary{
+		ContractorID:    contractorID,
+		From:            from,
+		To:              to,
+		TripCount:       stats.TripCount,
+		TotalVolumeM3:   calc.Round(stats.TotalVolume),
+		UnmatchedPlates: unmatched,
+	}, nil
+}
+
+func (s *ANPRService) formatNightlySummary(summary *NightlySummary) string {
+	loc := s.summaryLocation()
+	lang := s.notificationLanguage()
+	var b strings.Builder
+	b.WriteString(i18n.Text(lang, "summary.title", summary.From.In(loc).Format("02.01.2006 15:04"), summary.To.In(loc).Format("02.01.2006 15:04")) + "\n")
+	b.WriteString(i18n.Text(lang, "summary.trips", summary.TripCount) + "\n")
+	b.WriteString(i18n.Text(lang, "summary.volume", summary.TotalVolumeM3))
+	if len(summary.UnmatchedPlates) == 0 {
+		return b.String()
+	}
+	plates := make([]string, 0, len(summary.UnmatchedPlates))
+	for _, p := range summary.UnmatchedPlates {
+		if p.Count > 1 {
+			plates = append(plates, fmt.Sprintf("%s ×%d", p.Plate, p.Count))
+		} else {
+			plates = append(plates, p.Plate)
+		}
+	}
+	b.WriteString("\n" + i18n.Text(lang, "summary.unmatched", strings.Join(plates, ", ")))
+	return b.String()
+}
+
+func (s *ANPRService) summaryLocation() *time.Location {
+	loc, err := time.LoadLocation(s.Config().Summary.TimeZone)
+	if err != nil {
+		return s.defaultCameraLocation()
+	}
+	return loc
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+func summaryTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Summary = config.SummaryConfig{Enabled: true, SendAt: "07:00", TimeZone: "UTC"}
+	cfg.Access.NightStart = "18:00"
+	cfg.Telegram.BotToken = "token"
+	return cfg
+}
+
+func TestSummaryDue(t *testing.T) {
+	svc, _ := newTestService(t, summaryTestConfig())
+	morning := time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		due  bool
+	}{
+		{"before send time", morning.Add(-time.Minute), false},
+		{"at send time", morning, true},
+		{"within grace", morning.Add(5 * time.Hour), true},
+		{"after grace", morning.Add(summaryGrace), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sendAt, due := svc.summaryDue(tt.now)
+			if due != tt.due {
+				t.Fatalf("due = %v, want %v (sendAt %v)", due, tt.due, sendAt)
+			}
+			if due && !sendAt.Equal(morning) {
+				t.Errorf("sendAt = %v, want %v", sendAt, morning)
+			}
+		})
+	}
+}
+
+func TestSendNightlySummaries(t *testing.T) {
+	svc, store := newTestService(t, summaryTestConfig())
+	contractorID := uuid.New()
+	users := []uuid.UUID{uuid.New(), uuid.New()}
+	sendAt := time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+
+	store.EXPECT().ListEnabledSummarySubscriptions(gomock.Any()).Return([]repository.SummarySubscription{
+		{UserID: users[0], ContractorID: contractorID, TelegramChatID: "111", Enabled: true},
+		{UserID: users[1], ContractorID: contractorID, TelegramChatID: "222", Enabled: true},
+	}, nil)
+	store.EXPECT().GetReportStats(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, f repository.ReportFilters) (*repository.ReportStats, error) {
+		if f.ContractorID == nil || *f.ContractorID != contractorID || !f.From.Equal(from) || !f.To.Before(sendAt) {
+			t.Errorf("unexpected report filters: %+v", f)
+		}
+		return &repository.ReportStats{TripCount: 12, TotalVolume: 240}, nil
+	})
+	store.EXPECT().GetContractorUnmatchedPlates(gomock.Any(), contractorID, from, sendAt, summaryUnmatchedLimit).
+		Return([]repository.PlateCount{{Plate: "123ABC02", Count: 3}, {Plate: "777AAA02", Count: 1}}, nil)
+	store.EXPECT().EnqueueTelegramNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.TelegramNotification) error {
+		if len(got) != 2 {
+			t.Fatalf("got %d notifications, want one per subscriber", len(got))
+		}
+		wantKey := "summary:" + contractorID.String() + ":" + users[0].String() + ":2025-01-16"
+		if got[0].DedupKey != wantKey || got[0].ChatID != "111" || got[1].ChatID != "222" || got[0].Kind != summaryNotificationKind {
+			t.Errorf("unexpected notifications: %+v", got)
+		}
+		for _, want := range []string{"Рейсов: 12", "240.0 м³", "123ABC02 ×3", "777AAA02"} {
+			if !strings.Contains(got[0].Text, want) {
+				t.Errorf("summary text %q does not contain %q", got[0].Text, want)
+			}
+		}
+		return nil
+	})
+
+	if err := svc.SendNightlySummaries(context.Background(), sendAt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetSummarySubscription(t *testing.T) {
+	enabled := true
+	chatID := " 12345 "
+
+	tests := []struct {
+		name     string
+		existing *repository.SummarySubscription
+		input    SummarySubscriptionInput
+		wantErr  error
+	}{
+		{"enable without chat", nil, SummarySubscriptionInput{Enabled: &enabled}, ErrInvalidInput},
+		{"enable with chat", nil, SummarySubscriptionInput{Enabled: &enabled, TelegramChatID: &chatID}, nil},
+		{"enable with stored chat", &repository.SummarySubscription{TelegramChatID: "12345"}, SummarySubscriptionInput{Enabled: &enabled}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, summaryTestConfig())
+			userID, contractorID := uuid.New(), uuid.New()
+			var getErr error
+			if tt.existing == nil {
+				getErr = repository.ErrSummarySubscriptionNotFound
+			}
+			store.EXPECT().GetSummarySubscription(gomock.Any(), userID).Return(tt.existing, getErr)
+			if tt.wantErr == nil {
+				store.EXPECT().UpsertSummarySubscription(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, sub *repository.SummarySubscription) error {
+					if sub.ContractorID != contractorID || sub.TelegramChatID != "12345" || !sub.Enabled {
+						t.Errorf("unexpected subscription: %+v", sub)
+					}
+					return nil
+				})
+			}
+
+			info, err := svc.SetSummarySubscription(context.Background(), userID, contractorID, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (!info.Enabled || info.SendAt != "07:00") {
+				t.Errorf("unexpected info: %+v", info)
+			}
+		})
+	}
+}
+
+func TestFormatNightlySummaryLanguage(t *testing.T) {
+	cfg := summaryTestConfig()
+	cfg.NotificationLanguage = "kk"
+	svc, _ := newTestService(t, cfg)
+	summary := &NightlySummary{
+		From:            time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC),
+		To:              time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC),
+		TripCount:       12,
+		TotalVolumeM3:   240,
+		UnmatchedPlates: []repository.PlateCount{{Plate: "123ABC02", Count: 3}},
+	}
+
+	want := "15.01.2025 18:00 – 16.01.2025 07:00 түнгі жиынтық\nРейстер: 12\nШығарылған қар: 240.0 м³\nҚары сәйкестендірілмеген: 123ABC02 ×3"
+	if got := svc.formatNightlySummary(summary); got != want {
+		t.Fatalf("formatNightlySummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/i18n"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/telegram"
+)
+
+// Параметры outbox уведомлений. Bot API ограничивает бота ~20 сообщениями в минуту на группу,
+// поэтому пачки небольшие, а 429 откладывает повтор на присланный retry_after.
+const (
+	telegramPollInterval = 2 * time.Second
+	telegramBatchSize    = 20
+	telegramBackoffBase  = 10 * time.Second
+	telegramBackoffMax   = 10 * time.Minute
+	// telegramMaxPhotoBytes — фото больше этого размера отправляется ссылкой в тексте
+	telegramMaxPhotoBytes = 10 << 20
+)
+
+// TelegramSender отправляет сообщения в чаты (реализуется *telegram.Client)
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID, text string) error
+	SendPhoto(ctx context.Context, chatID, caption string, photo []byte) error
+}
+
+// NotifyTelegram — обработчик топика eventbus.TopicEventCreated: ставит в очередь уведомления
+// о номерах из чёрного списка и о перегрузе кузова. О номере из чёрного списка уведомляет всегда. О перегрузе
+// опоздавшего события (late) не уведомляет — проезд давно состоялся, и реагировать поздно; события вне
+// расписания камеры — тоже, они не учитываются в рейсах.
+func (s *ANPRService) NotifyTelegram(ctx context.Context, topic string, payload []byte) error {
+	var msg EventCreatedMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return fmt.Errorf("decode %s message: %w", topic, err)
+	}
+	routine := !msg.Late && !msg.OutOfSchedule
+
+	cfg := s.Config().Telegram
+	lang := s.notificationLanguage()
+	var photoURL *string
+	if len(msg.Photos) > 0 {
+		photoURL = &msg.Photos[0]
+	}
+
+	var notifications []repository.TelegramNotification
+	if cfg.NotifyEnabled(config.TelegramNotifyBlacklist) {
+		if lists := blacklistNames(msg.Lists); len(lists) > 0 {
+			text := i18n.Text(lang, "notify.blacklist", msg.Plate, strings.Join(lists, ", "), s.telegramEventDetails(ctx, msg))
+			notifications = append(notifications, s.telegramNotifications(config.TelegramNotifyBlacklist, "blacklist:"+msg.EventID.String(), text, photoURL)...)
+		}
+	}
+	if routine && cfg.NotifyEnabled(config.TelegramNotifyOverload) && msg.SnowVolumePercentage != nil && *msg.SnowVolumePercentage > cfg.OverloadPercent {
+		text := i18n.Text(lang, "notify.overload", msg.Plate, *msg.SnowVolumePercentage)
+		if msg.SnowVolumeM3 != nil {
+			text += i18n.Text(lang, "notify.overload_volume", *msg.SnowVolumeM3)
+		}
+		text += "\n" + s.telegramEventDetails(ctx, msg)
+		notifications = append(notifications, s.telegramNotifications(config.TelegramNotifyOverload, "overload:"+msg.EventID.String(), text, photoURL)...)
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+	return s.repo.EnqueueTelegramNotifications(ctx, notifications)
+}
+
+// RunCameraOfflineNotifier с периодом interval ставит в очередь уведомления о молчащих камерах
+// (см. CameraLiveness). Ключ инцидента — камера и время её последнего события, поэтому о
+// каждом простое сообщается один раз, сколько бы реплик его ни обнаружило.
+func (s *ANPRService) RunCameraOfflineNotifier(ctx context.Context, interval time.Duration) {
+	if !s.Config().Telegram.NotifyEnabled(config.TelegramNotifyCameraOffline) || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.notifyOfflineCameras(ctx); err != nil && ctx.Err() == nil {
+			s.logger(ctx).Warn().Err(err).Msg("failed to check offline cameras")
+		}
+	}
+}
+
+func (s *ANPRService) notifyOfflineCameras(ctx context.Context) error {
+	now := s.clock.Now()
+	cameras, err := s.CameraLiveness(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	lang := s.notificationLanguage()
+	var notifications []repository.TelegramNotification
+	for _, camera := range cameras {
+		if camera.Status != CameraStatusSilent && camera.Status != CameraStatusVideoLoss {
+			continue
+		}
+		name := camera.CameraID
+		if camera.Name != nil && *camera.Name != "" {
+			name = fmt.Sprintf("%s (%s)", *camera.Name, camera.CameraID)
+		}
+		if camera.Status == CameraStatusVideoLoss {
+			loc := s.CameraLocation(ctx, camera.CameraID)
+			key := fmt.Sprintf("camera_video_loss:%s:%d", camera.CameraID, camera.VideoLossSince.Unix())
+			text := i18n.Text(lang, "notify.video_loss",
+				name, camera.VideoLossSince.In(loc).Format("02.01.2006 15:04"), now.Sub(*camera.VideoLossSince).Round(time.Minute))
+			notifications = append(notifications, s.telegramNotifications(config.TelegramNotifyCameraOffline, key, text, nil)...)
+			continue
+		}
+		key := "camera_offline:" + camera.CameraID + ":never"
+		text := i18n.Text(lang, "notify.camera_never", name)
+		if camera.LastEventAt != nil {
+			key = fmt.Sprintf("camera_offline:%s:%d", camera.CameraID, camera.LastEventAt.Unix())
+			loc := s.CameraLocation(ctx, camera.CameraID)
+			text = i18n.Text(lang, "notify.camera_silent",
+				name, camera.LastEventAt.In(loc).Format("02.01.2006 15:04"), now.Sub(*camera.LastEventAt).Round(time.Minute))
+		}
+		// После heartbeat камера могла снова замолчать с тем же последним событием — это новое отключение
+		if camera.LastHeartbeatAt != nil && (camera.LastEventAt == nil || camera.LastHeartbeatAt.After(*camera.LastEventAt)) {
+			key = fmt.Sprintf("camera_offline:%s:%d", camera.CameraID, camera.LastHeartbeatAt.Unix())
+		}
+		notifications = append(notifications, s.telegramNotifications(config.TelegramNotifyCameraOffline, key, text, nil)...)
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return s.repo.EnqueueTelegramNotifications(ctx, notifications)
+}
+
+// RunTelegramRelay отправляет уведомления из outbox. Блокируется до отмены ctx.
+func (s *ANPRService) RunTelegramRelay(ctx context.Context, sender TelegramSender) {
+	if sender == nil {
+		return
+	}
+	client := &http.Client{Timeout: s.Config().Telegram.Timeout}
+	s.runRelay(ctx, "telegram", telegramPollInterval, telegramBatchSize, func(ctx context.Context) (int, error) {
+		return s.sendTelegram(ctx, sender, client)
+	})
+}
+
+// sendTelegram отправляет одну пачку уведомлений и возвращает её размер
+func (s *ANPRService) sendTelegram(ctx context.Context, sender TelegramSender, photos *http.Client) (int, error) {
+	notifications, err := s.repo.ClaimTelegramNotifications(ctx, telegramBatchSize, 2*s.Config().Telegram.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	retry := relayRetry{
+		MaxAttempts: s.Config().Telegram.MaxAttempts,
+		BackoffBase: telegramBackoffBase,
+		BackoffMax:  telegramBackoffMax,
+	}
+	for _, n := range notifications {
+		err := s.sendTelegramNotification(ctx, sender, photos, n)
+		if err == nil {
+			if err := s.repo.MarkTelegramNotificationSent(ctx, n.ID); err != nil {
+				s.logger(ctx).Warn().Err(err).Str("notification_id", n.ID.String()).Msg("failed to mark telegram notification sent")
+			}
+			continue
+		}
+
+		attempt := n.Attempts + 1
+		next := retry.next(s.clock.Now(), attempt)
+		var apiErr *telegram.APIError
+		if next != nil && errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			t := s.clock.Now().Add(apiErr.RetryAfter)
+			next = &t
+		}
+		if markErr := s.repo.MarkTelegramAttemptFailed(ctx, n.ID, err.Error(), next); markErr != nil {
+			s.logger(ctx).Warn().Err(markErr).Str("notification_id", n.ID.String()).Msg("failed to record telegram attempt")
+		}
+		event := s.logger(ctx).Warn().Err(err).Str("chat_id", n.ChatID).Str("kind", n.Kind).Int("attempt", attempt)
+		if next == nil {
+			event.Msg("telegram notification failed permanently")
+		} else {
+			event.Time("next_attempt_at", *next).Msg("telegram notification failed, will retry")
+		}
+	}
+	return len(notifications), nil
+}
+
+// sendTelegramNotification отправляет уведомление; фото встраивается в сообщение, а если его не удалось
+// скачать — уходит текст со ссылкой на фото
+func (s *ANPRService) sendTelegramNotification(ctx context.Context, sender TelegramSender, photos *http.Client, n repository.TelegramNotification) error {
+	if n.PhotoURL == nil {
+		return sender.SendMessage(ctx, n.ChatID, n.Text)
+	}
+	photo, err := downloadPhoto(ctx, photos, *n.PhotoURL)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("photo_url", *n.PhotoURL).Msg("failed to download photo for telegram, sending link")
+		return sender.SendMessage(ctx, n.ChatID, n.Text+"\n"+i18n.Text(s.notificationLanguage(), "notify.photo", *n.PhotoURL))
+	}
+	return sender.SendPhoto(ctx, n.ChatID, n.Text, photo)
+}
+
+func downloadPhoto(ctx context.Context, client *http.Client, photoURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, photoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("photo responded with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, telegramMaxPhotoBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > telegramMaxPhotoBytes {
+		return nil, errors.New("photo is too large")
+	}
+	return data, nil
+}
+
+// telegramNotifications размножает уведомление по всем настроенным чатам
+func (s *ANPRService) telegramNotifications(kind, key, text string, photoURL *string) []repository.TelegramNotification {
+	result := make([]repository.TelegramNotification, 0, len(s.Config().Telegram.ChatIDs))
+	for _, chatID := range s.Config().Telegram.ChatIDs {
+		result = append(result, repository.TelegramNotification{
+			DedupKey: key,
+			ChatID:   chatID,
+			Kind:     kind,
+			Text:     text,
+			PhotoURL: photoURL,
+		})
+	}
+	return result
+}
+
+// telegramEventDetails — строки с камерой, направлением и местным временем события
+func (s *ANPRService) telegramEventDetails(ctx context.Context, msg EventCreatedMessage) string {
+	loc := s.CameraLocation(ctx, msg.CameraID)
+	lang := s.notificationLanguage()
+	details := i18n.Text(lang, "notify.event_camera", msg.CameraID)
+	if msg.Direction != "" {
+		details += fmt.Sprintf(", %s", msg.Direction)
+	}
+	return details + "\n" + i18n.Text(lang, "notify.event_time", msg.EventTime.In(loc).Format("02.01.2006 15:04:05"))
+}
+
+// notificationLanguage — язык уведомлений и выгрузок по расписанию (NOTIFICATION_LANGUAGE)
+func (s *ANPRService) notificationLanguage() i18n.Lang {
+	if lang, ok := i18n.Parse(s.Config().NotificationLanguage); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+func blacklistNames(hits []anpr.ListHit) []string {
+	var names []string
+	for _, hit := range hits {
+		if strings.EqualFold(hit.ListType, "BLACKLIST") {
+			names = append(names, hit.ListName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func telegramTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Telegram = config.TelegramConfig{
+		BotToken:        "token",
+		ChatIDs:         []string{"-100", "-200"},
+		Notify:          []string{config.TelegramNotifyBlacklist, config.TelegramNotifyOverload, config.TelegramNotifyCameraOffline},
+		OverloadPercent: 100,
+		Timeout:         time.Second,
+		MaxAttempts:     3,
+	}
+	cfg.Health.CameraSilenceThreshold = 30 * time.Minute
+	return cfg
+}
+
+func TestNotifyTelegram(t *testing.T) {
+	svc, store := newTestService(t, telegramTestConfig())
+	store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(nil, repository.ErrCameraNotFound).AnyTimes()
+
+	percent, volume := 120.0, 24.0
+	msg := EventCreatedMessage{
+		EventID:              uuid.New(),
+		Plate:                "123ABC02",
+		CameraID:             "cam-1",
+		Direction:            "entry",
+		EventTime:            testNow,
+		SnowVolumeM3:         &volume,
+		SnowVolumePercentage: &percent,
+		Photos:               []string{"https://photos.example/1.jpg"},
+		Lists:                []anpr.ListHit{{ListName: "default_blacklist", ListType: "BLACKLIST"}},
+	}
+	payload, _ := json.Marshal(msg)
+
+	store.EXPECT().EnqueueTelegramNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.TelegramNotification) error {
+		if len(got) != 4 {
+			t.Fatalf("got %d notifications, want blacklist and overload for 2 chats", len(got))
+		}
+		if got[0].Kind != config.TelegramNotifyBlacklist || got[0].DedupKey != "blacklist:"+msg.EventID.String() || got[1].ChatID != "-200" {
+			t.Errorf("unexpected blacklist notifications: %+v", got[:2])
+		}
+		if !strings.Contains(got[0].Text, "default_blacklist") || got[0].PhotoURL == nil {
+			t.Errorf("blacklist notification = %q, photo %v", got[0].Text, got[0].PhotoURL)
+		}
+		if got[2].Kind != config.TelegramNotifyOverload || !strings.Contains(got[2].Text, "120%") {
+			t.Errorf("overload notification = %+v", got[2])
+		}
+		return nil
+	})
+
+	if err := svc.NotifyTelegram(context.Background(), "anpr.event.created", payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNotifyTelegramSkipsOrdinaryEvents(t *testing.T) {
+	svc, _ := newTestService(t, telegramTestConfig())
+	percent := 80.0
+	payload, _ := json.Marshal(EventCreatedMessage{
+		EventID:              uuid.New(),
+		CameraID:             "cam-1",
+		SnowVolumePercentage: &percent,
+		Lists:                []anpr.ListHit{{ListType: "WHITELIST"}},
+	})
+	// Ни одного ожидания на store: уведомлений нет, в БД не пишем
+	if err := svc.NotifyTelegram(context.Background(), "anpr.event.created", payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Опоздавшие и внерасписательные события не дают уведомлений о перегрузе, но чёрный список важнее
+func TestNotifyTelegramLateAndOutOfScheduleEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  EventCreatedMessage
+	}{
+		{name: "late", msg: EventCreatedMessage{Late: true}},
+		{name: "out of schedule", msg: EventCreatedMessage{OutOfSchedule: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, telegramTestConfig())
+			store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(nil, repository.ErrCameraNotFound).AnyTimes()
+			percent := 120.0
+			tt.msg.EventID = uuid.New()
+			tt.msg.CameraID = "cam-1"
+			tt.msg.SnowVolumePercentage = &percent
+			tt.msg.Lists = []anpr.ListHit{{ListName: "default_blacklist", ListType: "BLACKLIST"}}
+			payload, _ := json.Marshal(tt.msg)
+
+			store.EXPECT().EnqueueTelegramNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.TelegramNotification) error {
+				if len(got) != 2 {
+					t.Fatalf("got %d notifications, want blacklist only for 2 chats: %+v", len(got), got)
+				}
+				for _, n := range got {
+					if n.Kind != config.TelegramNotifyBlacklist {
+						t.Errorf("unexpected %s notification", n.Kind)
+					}
+				}
+				return nil
+			})
+			if err := svc.NotifyTelegram(context.Background(), "anpr.event.created", payload); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNotifyOfflineCameras(t *testing.T) {
+	svc, store := newTestService(t, telegramTestConfig())
+	lastEvent := testNow.Add(-2 * time.Hour)
+	recent := testNow.Add(-time.Minute)
+	store.EXPECT().ListCamerasWithLastEvent(gomock.Any()).Return([]repository.CameraLastEvent{
+		{Camera: repository.Camera{ID: "cam-silent"}, LastEventAt: &lastEvent},
+		{Camera: repository.Camera{ID: "cam-ok"}, LastEventAt: &recent},
+	}, nil)
+	store.EXPECT().GetCamera(gomock.Any(), "cam-silent").Return(nil, repository.ErrCameraNotFound)
+	store.EXPECT().EnqueueTelegramNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.TelegramNotification) error {
+		want := "camera_offline:cam-silent:" + strconv.FormatInt(lastEvent.Unix(), 10)
+		if len(got) != 2 || got[0].DedupKey != want || got[0].PhotoURL != nil {
+			t.Errorf("notifications = %+v, want 2 with key %s", got, want)
+		}
+		return nil
+	})
+
+	if err := svc.notifyOfflineCameras(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeTelegramSender struct {
+	messages []string
+	photos   int
+}
+
+func (f *fakeTelegramSender) SendMessage(_ context.Context, _, text string) error {
+	f.messages = append(f.messages, text)
+	return nil
+}
+
+func (f *fakeTelegramSender) SendPhoto(_ context.Context, _, _ string, _ []byte) error {
+	f.photos++
+	return errors.New("telegram api: 400 Bad Request: wrong file")
+}
+
+func TestSendTelegram(t *testing.T) {
+	svc, store := newTestService(t, telegramTestConfig())
+
+	photos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("jpeg"))
+	}))
+	defer photos.Close()
+
+	missing, present := photos.URL+"/missing.jpg", photos.URL+"/1.jpg"
+	withMissingPhoto := repository.TelegramNotification{ID: uuid.New(), ChatID: "-100", Text: "blacklist", PhotoURL: &missing}
+	withPhoto := repository.TelegramNotification{ID: uuid.New(), ChatID: "-100", Text: "overload", PhotoURL: &present, Attempts: 2}
+
+	store.EXPECT().ClaimTelegramNotifications(gomock.Any(), telegramBatchSize, gomock.Any()).
+		Return([]repository.TelegramNotification{withMissingPhoto, withPhoto}, nil)
+	// Фото не скачалось — текст со ссылкой уходит как обычное сообщение
+	store.EXPECT().MarkTelegramNotificationSent(gomock.Any(), withMissingPhoto.ID).Return(nil)
+	// Bot API отклонил фото на последней попытке — уведомление помечается failed
+	store.EXPECT().MarkTelegramAttemptFailed(gomock.Any(), withPhoto.ID, gomock.Any(), gomock.Nil()).Return(nil)
+
+	sender := &fakeTelegramSender{}
+	if n, err := svc.sendTelegram(context.Background(), sender, photos.Client()); err != nil || n != 2 {
+		t.Fatalf("sendTelegram() = %d, %v", n, err)
+	}
+	if len(sender.messages) != 1 || sender.messages[0] != "blacklist\nФото: "+missing || sender.photos != 1 {
+		t.Errorf("messages = %q, photos = %d", sender.messages, sender.photos)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+// Типы записей таймлайна номера
+const (
+	TimelineEvent       = "event"
+	TimelineTrip        = "trip"
+	TimelineRejected    = "rejected"
+	TimelineListAdded   = "list_added"
+	TimelineListRemoved = "list_removed"
+)
+
+const (
+	defaultTimelinePeriod = 30 * 24 * time.Hour
+	maxTimelineItems      = 1000
+)
+
+// TimelineItem — запись таймлайна номера. Набор заполненных полей зависит от типа.
+type TimelineItem struct {
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	EventID       *string   `json:"event_id,omitempty"`
+	CameraID      *string   `json:"camera_id,omitempty"`
+	Direction     *string   `json:"direction,omitempty"`
+	SnowVolumeM3  *float64  `json:"snow_volume_m3,omitempty"`
+	OutOfSchedule bool      `json:"out_of_schedule,omitempty"`
+	Late          bool      `json:"late,omitempty"`
+	Decision      *string   `json:"decision,omitempty"`
+	ListID        *string   `json:"list_id,omitempty"`
+	ListName      *string   `json:"list_name,omitempty"`
+	ListType      *string   `json:"list_type,omitempty"`
+	Reason        *string   `json:"reason,omitempty"`
+	Note          *string   `json:"note,omitempty"`
+}
+
+type PlateTimeline struct {
+	PlateID   string         `json:"plate_id"`
+	Plate     string         `json:"plate"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Items     []TimelineItem `json:"items"`
+	Truncated bool           `json:"truncated,omitempty"`
+}
+
+// GetPlateTimeline объединяет события (рейсы отдельно), отклонённые проезды и изменения списков
+// номера в одну хронологическую ленту. По умолчанию — последние 30 дней.
+func (s *ANPRService) GetPlateTimeline(ctx context.Context, plateID uuid.UUID, from, to *time.Time) (*PlateTimeline, error) {
+	plate, err := s.repo.GetPlateByID(ctx, plateID)
+	if err != nil {
+		return nil, err
+	}
+
+	periodTo := s.clock.Now()
+	if to != nil {
+		periodTo = *to
+	}
+	periodFrom := periodTo.Add(-defaultTimelinePeriod)
+	if from != nil {
+		periodFrom = *from
+	}
+	if periodTo.Before(periodFrom) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+
+	events, err := s.repo.FindPlateEvents(ctx, plateID, periodFrom, periodTo, maxTimelineItems+1)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := s.repo.FindPlateRejectedEvents(ctx, plateID, periodFrom, periodTo, maxTimelineItems+1)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.repo.GetPlateListHistory(ctx, plateID, periodFrom, periodTo)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TimelineItem, 0, len(events)+len(rejected)+len(history))
+	for _, e := range events {
+		eventID := e.ID.String()
+		cameraID := e.CameraID
+		item := TimelineItem{
+			Type:          TimelineEvent,
+			Time:          e.EventTime,
+			EventID:       &eventID,
+			CameraID:      &cameraID,
+			Direction:     e.Direction,
+			SnowVolumeM3:  e.SnowVolumeM3,
+			OutOfSchedule: e.OutOfSchedule,
+			Late:          e.Late,
+			Decision:      e.AccessDecision,
+			Reason:        e.DecisionReason,
+		}
+		// Рейс — разрешённое событие с объёмом снега, учитываемое в отчётах (не сверх квоты)
+		denied := e.AccessDecision != nil && *e.AccessDecision == anpr.DecisionDeny
+		if e.SnowVolumeM3 != nil && *e.SnowVolumeM3 > 0 && !e.OutOfSchedule && !e.Late && !e.OverQuota && !denied {
+			item.Type = TimelineTrip
+		}
+		items = append(items, item)
+	}
+	for _, e := range rejected {
+		eventID := e.ID.String()
+		cameraID := e.CameraID
+		reason := e.RejectReason
+		decision := anpr.DecisionDeny
+		items = append(items, TimelineItem{
+			Type:     TimelineRejected,
+			Time:     e.EventTime,
+			EventID:  &eventID,
+			CameraID: &cameraID,
+			Decision: &decision,
+			Reason:   &reason,
+		})
+	}
+	for _, h := range history {
+		listID := h.ListID.String()
+		itemType := TimelineListAdded
+		if h.Action == "removed" {
+			itemType = TimelineListRemoved
+		}
+		items = append(items, TimelineItem{
+			Type:     itemType,
+			Time:     h.ChangedAt,
+			ListID:   &listID,
+			ListName: h.ListName,
+			ListType: h.ListType,
+			Note:     h.Note,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time.Before(items[j].Time)
+	})
+
+	truncated := false
+	if len(items) > maxTimelineItems {
+		items = items[:maxTimelineItems]
+		truncated = true
+	}
+
+	return &PlateTimeline{
+		PlateID:   plate.ID.String(),
+		Plate:     plate.Normalized,
+		From:      periodFrom,
+		To:        periodTo,
+		Items:     items,
+		Truncated: truncated,
+	}, nil
+}
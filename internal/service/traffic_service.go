@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// trafficReportMaxDays — наибольший период аналитики скорости и полос
+const trafficReportMaxDays = 92
+
+// Интервалы группировки загрузки полос
+const (
+	TrafficIntervalHour = "hour"
+	TrafficIntervalDay  = "day"
+)
+
+// TrafficQuery — параметры аналитики скорости и полос в виде query-параметров запроса
+type TrafficQuery struct {
+	From      string // RFC 3339; по умолчанию to минус сутки
+	To        string // RFC 3339; по умолчанию сейчас
+	CameraID  string
+	PolygonID string
+	// Interval — группировка загрузки полос: hour (по умолчанию) или day
+	Interval string
+}
+
+// CameraSpeedInfo — скорость проездов камеры, км/ч
+type CameraSpeedInfo struct {
+	CameraID string  `json:"camera_id"`
+	Samples  int64   `json:"samples"`
+	Avg      float64 `json:"avg"`
+	P50      float64 `json:"p50"`
+	P85      float64 `json:"p85"`
+	P95      float64 `json:"p95"`
+	Max      float64 `json:"max"`
+}
+
+// SpeedReport — скорость проездов по камерам за период
+type SpeedReport struct {
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Cameras []CameraSpeedInfo `json:"cameras"`
+}
+
+// LaneUsageItem — проезды по полосе камеры за интервал. Share — доля полосы среди проездов камеры за
+// интервал: перекос в одну полосу при низкой скорости говорит об очереди у ворот.
+type LaneUsageItem struct {
+	Bucket   time.Time `json:"bucket"`
+	CameraID string    `json:"camera_id"`
+	Lane     int       `json:"lane"`
+	Events   int64     `json:"events"`
+	Share    float64   `json:"share"`
+	AvgSpeed *float64  `json:"avg_speed,omitempty"`
+}
+
+// LaneUsageReport — загрузка полос камер по интервалам
+type LaneUsageReport struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Interval string          `json:"interval"`
+	TimeZone string          `json:"timezone"`
+	Items    []LaneUsageItem `json:"items"`
+}
+
+// SpeedReport возвращает среднюю скорость и перцентили (p50, p85, p95) по камерам. Учитываются события,
+// в которых камера измерила скорость.
+func (s *ANPRService) SpeedReport(ctx context.Context, query TrafficQuery) (*SpeedReport, error) {
+	filters, err := s.trafficFilters(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetCameraSpeedStats(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	cameras := make([]CameraSpeedInfo, 0, len(rows))
+	for _, row := range rows {
+		cameras = append(cameras, CameraSpeedInfo{
+			CameraID: row.CameraID,
+			Samples:  row.Samples,
+			Avg:      roundSpeed(row.AvgSpeed),
+			P50:      roundSpeed(row.P50Speed),
+			P85:      roundSpeed(row.P85Speed),
+			P95:      roundSpeed(row.P95Speed),
+			Max:      roundSpeed(row.MaxSpeed),
+		})
+	}
+	return &SpeedReport{From: filters.From, To: filters.To, Cameras: cameras}, nil
+}
+
+// LaneUsage возвращает проезды по полосам камер за каждый час или день (в CAMERA_DEFAULT_TIMEZONE)
+func (s *ANPRService) LaneUsage(ctx context.Context, query TrafficQuery) (*LaneUsageReport, error) {
+	interval := strings.ToLower(strings.TrimSpace(query.Interval))
+	if interval == "" {
+		interval = TrafficIntervalHour
+	}
+	if interval != TrafficIntervalHour && interval != TrafficIntervalDay {
+		return nil, fmt.Errorf("%w: interval must be %s or %s", ErrInvalidInput, TrafficIntervalHour, TrafficIntervalDay)
+	}
+	filters, err := s.trafficFilters(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.defaultCameraLocation()
+	rows, err := s.repo.GetLaneUsageStats(ctx, filters, interval, loc.String())
+	if err != nil {
+		return nil, err
+	}
+
+	type bucketKey struct {
+		bucket   int64
+		cameraID string
+	}
+	totals := map[bucketKey]int64{}
+	for _, row := range rows {
+		totals[bucketKey{row.Bucket.Unix(), row.CameraID}] += row.Events
+	}
+	items := make([]LaneUsageItem, 0, len(rows))
+	for _, row := range rows {
+		item := LaneUsageItem{
+			Bucket:   row.Bucket.In(loc),
+			CameraID: row.CameraID,
+			Lane:     row.Lane,
+			Events:   row.Events,
+		}
+		if total := totals[bucketKey{row.Bucket.Unix(), row.CameraID}]; total > 0 {
+			item.Share = roundRate(float64(row.Events) / float64(total))
+		}
+		if row.AvgSpeed != nil {
+			avg := roundSpeed(*row.AvgSpeed)
+			item.AvgSpeed = &avg
+		}
+		items = append(items, item)
+	}
+	return &LaneUsageReport{From: filters.From, To: filters.To, Interval: interval, TimeZone: loc.String(), Items: items}, nil
+}
+
+// trafficFilters проверяет права и разбирает параметры запроса. Пользователи полигона видят только
+// камеры полигонов своей организации.
+func (s *ANPRService) trafficFilters(ctx context.Context, query TrafficQuery) (repository.TrafficFilters, error) {
+	principal, err := requirePrincipal(ctx, canViewTraffic)
+	if err != nil {
+		return repository.TrafficFilters{}, err
+	}
+
+	filters := repository.TrafficFilters{To: s.clock.Now()}
+	if query.To != "" {
+		filters.To, err = time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return filters, fmt.Errorf("%w: invalid to time format, use RFC3339", ErrInvalidInput)
+		}
+	}
+	filters.From = filters.To.Add(-24 * time.Hour)
+	if query.From != "" {
+		filters.From, err = time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return filters, fmt.Errorf("%w: invalid from time format, use RFC3339", ErrInvalidInput)
+		}
+	}
+	if !filters.From.Before(filters.To) {
+		return filters, fmt.Errorf("%w: to time must be after from time", ErrInvalidInput)
+	}
+	if filters.To.Sub(filters.From) > trafficReportMaxDays*24*time.Hour {
+		return filters, fmt.Errorf("%w: period must not exceed %d days", ErrInvalidInput, trafficReportMaxDays)
+	}
+
+	if cameraID := strings.TrimSpace(query.CameraID); cameraID != "" {
+		filters.CameraID = &cameraID
+	}
+	if raw := strings.TrimSpace(query.PolygonID); raw != "" {
+		polygonID, err := uuid.Parse(raw)
+		if err != nil {
+			return filters, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		filters.PolygonID = &polygonID
+	}
+	if principal.IsLandfill() {
+		filters.PolygonOrgID = &principal.OrgID
+	}
+	return filters, nil
+}
+
+// roundSpeed округляет скорость до 0.1 км/ч
+func roundSpeed(value float64) float64 {
+	return math.Round(value*10) / 10
+}
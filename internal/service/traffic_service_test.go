@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestLaneUsage(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ingest.DefaultCameraTimeZone = "Asia/Almaty"
+	svc, store := newTestService(t, cfg)
+	orgID := uuid.New()
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleLandfillUser, OrgID: orgID})
+
+	hour := testNow.Add(-time.Hour).Truncate(time.Hour)
+	slow := 4.04
+	store.EXPECT().GetLaneUsageStats(gomock.Any(), gomock.Any(), TrafficIntervalHour, "Asia/Almaty").DoAndReturn(
+		func(_ context.Context, filters repository.TrafficFilters, _, _ string) ([]repository.LaneUsageStat, error) {
+			if filters.PolygonOrgID == nil || *filters.PolygonOrgID != orgID || !filters.To.Equal(testNow) || !filters.From.Equal(testNow.Add(-24*time.Hour)) {
+				t.Fatalf("unexpected filters: %+v", filters)
+			}
+			return []repository.LaneUsageStat{
+				{Bucket: hour, CameraID: "gate-1", Lane: 1, Events: 3, AvgSpeed: &slow},
+				{Bucket: hour, CameraID: "gate-1", Lane: 2, Events: 1},
+				{Bucket: hour, CameraID: "gate-2", Lane: 1, Events: 5},
+			}, nil
+		})
+
+	report, err := svc.LaneUsage(ctx, TrafficQuery{})
+	if err != nil {
+		t.Fatalf("LaneUsage() error = %v", err)
+	}
+	if report.Interval != TrafficIntervalHour || report.TimeZone != "Asia/Almaty" || len(report.Items) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	first := report.Items[0]
+	if first.Share != 0.75 || first.AvgSpeed == nil || *first.AvgSpeed != 4 || report.Items[1].Share != 0.25 || report.Items[2].Share != 1 {
+		t.Fatalf("unexpected lane shares: %+v", report.Items)
+	}
+}
+
+func TestTrafficFiltersInvalid(t *testing.T) {
+	admin := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleAkimatAdmin})
+	contractor := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleContractorAdmin})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		query   TrafficQuery
+		wantErr error
+	}{
+		{name: "contractor", ctx: contractor, wantErr: ErrForbidden},
+		{name: "bad from", ctx: admin, query: TrafficQuery{From: "yesterday"}, wantErr: ErrInvalidInput},
+		{name: "reversed period", ctx: admin, query: TrafficQuery{From: "2025-01-15T00:00:00Z", To: "2025-01-14T00:00:00Z"}, wantErr: ErrInvalidInput},
+		{name: "period too long", ctx: admin, query: TrafficQuery{From: "2024-01-01T00:00:00Z", To: "2025-01-01T00:00:00Z"}, wantErr: ErrInvalidInput},
+		{name: "bad polygon", ctx: admin, query: TrafficQuery{PolygonID: "north"}, wantErr: ErrInvalidInput},
+		{name: "bad interval", ctx: admin, query: TrafficQuery{Interval: "week"}, wantErr: ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, nil)
+			if _, err := svc.LaneUsage(tt.ctx, tt.query); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LaneUsage() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfidenceCalibration(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhAdmin})
+
+	store.EXPECT().GetConfidenceStats(gomock.Any(), gomock.Any()).Return([]repository.ConfidenceStat{
+		{CameraID: "gate-1", Bucket: 14, Events: 20, Corrected: 6},
+		{CameraID: "gate-1", Bucket: 17, Events: 20, Corrected: 1},
+		{CameraID: "gate-1", Bucket: 19, Events: 60, Corrected: 1},
+		{CameraID: "gate-2", Bucket: 19, Events: 10},
+	}, nil)
+
+	report, err := svc.ConfidenceCalibration(ctx, TrafficQuery{}, "0.03")
+	if err != nil {
+		t.Fatalf("ConfidenceCalibration() error = %v", err)
+	}
+	if report.MaxErrorRate != 0.03 || len(report.Cameras) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	gate := report.Cameras[0]
+	if gate.Events != 100 || gate.Corrected != 8 || gate.CorrectionRate != 0.08 || len(gate.Buckets) != 3 {
+		t.Fatalf("unexpected camera totals: %+v", gate)
+	}
+	if b := gate.Buckets[0]; b.From != 0.7 || b.To != 0.75 || b.CorrectionRate != 0.3 {
+		t.Fatalf("unexpected bucket: %+v", b)
+	}
+	// 0.85 и выше: 2 исправления на 80 событий (2.5%) — в пределах 3%; с 0.70 — 8 на 100
+	if gate.SuggestedMinConfidence == nil || *gate.SuggestedMinConfidence != 0.85 || gate.EventsBelowSuggested != 20 {
+		t.Fatalf("suggested threshold = %v (%d below), want 0.85 (20 below)", gate.SuggestedMinConfidence, gate.EventsBelowSuggested)
+	}
+	// Слишком мало событий для порога
+	if report.Cameras[1].SuggestedMinConfidence != nil {
+		t.Fatalf("suggested threshold for a quiet camera = %v, want none", *report.Cameras[1].SuggestedMinConfidence)
+	}
+
+	if _, err := svc.ConfidenceCalibration(ctx, TrafficQuery{}, "5"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("ConfidenceCalibration(max_error_rate=5) error = %v, want ErrInvalidInput", err)
+	}
+}
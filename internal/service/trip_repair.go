@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/eventbus"
+	"anpr-service/internal/repository"
+)
+
+// Причины пересчёта рейсов (TripChangedMessage.Reason)
+const (
+	// TripChangeOutOfOrder — пришёл въезд раньше уже принятых въездов той же ночи
+	TripChangeOutOfOrder = "out_of_order"
+	// TripChangePlateMerge — события ошибочного номера перенесены на основной слиянием номеров
+	TripChangePlateMerge = "plate_merge"
+	// TripChangeEventDeleted — удалены въезды, занимавшие место в квоте ночи
+	TripChangeEventDeleted = "event_deleted"
+	// TripChangeEventRestored — восстановлен удалённый въезд
+	TripChangeEventRestored = "event_restored"
+)
+
+// TripChangedMessage — сообщение топика eventbus.TopicTripChanged: пересчёт изменил статус оплаты уже
+// принятого въезда. TripRevision растёт с каждым изменением, поэтому потребитель применяет сообщение,
+// только если его версия больше сохранённой, и не зависит от порядка доставки.
+type TripChangedMessage struct {
+	EventID      uuid.UUID `json:"event_id"`
+	PlateID      uuid.UUID `json:"plate_id"`
+	Plate        string    `json:"plate"`
+	CameraID     string    `json:"camera_id"`
+	EventTime    time.Time `json:"event_time"`
+	MatchedSnow  bool      `json:"matched_snow"`
+	SnowVolumeM3 *float64  `json:"snow_volume_m3,omitempty"`
+	OverQuota    bool      `json:"over_quota"`
+	TripRevision int       `json:"trip_revision"`
+	Reason       string    `json:"reason"`
+}
+
+// repairTrips пересчитывает квоту оплачиваемых рейсов номера (см. repository.RepairTripQuota) и публикует
+// изменения в шину. current — только что принятое событие: его over_quota исправляется на месте, а не
+// публикуется отдельно (оно уйдёт в TopicEventCreated); решение при въезде не меняется. Ошибка пересчёта не прерывает вызывающего:
+// событие уже сохранено, а следующий пересчёт той же ночи исправит статусы.
+func (s *ANPRService) repairTrips(ctx context.Context, current *anpr.Event, reason string, repair repository.TripQuotaRepair) {
+	changes, err := s.repo.RepairTripQuota(ctx, repair)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("plate_id", repair.PlateID.String()).Str("reason", reason).Msg("failed to repair trips")
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+	s.logger(ctx).Info().
+		Str("plate_id", repair.PlateID.String()).
+		Str("reason", reason).
+		Time("from", repair.From).
+		Time("to", repair.To).
+		Int("changed", len(changes)).
+		Msg("trips repaired")
+
+	for _, change := range changes {
+		if current != nil && change.EventID == current.ID {
+			current.OverQuota = change.OverQuota
+			continue
+		}
+		if s.bus == nil {
+			continue
+		}
+		msg := TripChangedMessage{
+			EventID:      change.EventID,
+			PlateID:      change.PlateID,
+			Plate:        change.NormalizedPlate,
+			CameraID:     change.CameraID,
+			EventTime:    change.EventTime,
+			MatchedSnow:  change.MatchedSnow,
+			SnowVolumeM3: change.SnowVolumeM3,
+			OverQuota:    change.OverQuota,
+			TripRevision: change.TripRevision,
+			Reason:       reason,
+		}
+		if err := s.bus.Publish(ctx, eventbus.TopicTripChanged, msg); err != nil {
+			s.logger(ctx).Warn().Err(err).Str("event_id", change.EventID.String()).Msg("failed to publish trip change to event bus")
+		}
+	}
+}
+
+// repairPlateTrips пересчитывает рейсы номера за ночи, в которые попадает [from, to]. Ночи считаются в поясе
+// CAMERA_DEFAULT_TIMEZONE, квота — текущая квота номера.
+func (s *ANPRService) repairPlateTrips(ctx context.Context, reason string, plateID uuid.UUID, normalized string, from, to time.Time) {
+	paid := s.paidTripsPerNight(ctx, normalized)
+	if paid <= 0 {
+		return
+	}
+	loc := s.defaultCameraLocation()
+	clock := s.Config().Access.NightStart
+	s.repairTrips(ctx, nil, reason, repository.TripQuotaRepair{
+		PlateID:           plateID,
+		From:              nightStart(from, loc, clock),
+		To:                nightStart(to, loc, clock).AddDate(0, 0, 1),
+		TimeZone:          loc.String(),
+		NightStartMinutes: nightStartMinutes(clock),
+		PaidTripsPerNight: paid,
+	})
+}
+
+// repairRestoredTrips пересчитывает рейсы ночи восстановленного въезда: он снова занимает место в квоте
+func (s *ANPRService) repairRestoredTrips(ctx context.Context, eventID uuid.UUID) {
+	event, err := s.repo.GetEventByID(ctx, eventID)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("event_id", eventID.String()).Msg("failed to load restored event for trip repair")
+		return
+	}
+	if !countsInTripQuota(event) {
+		return
+	}
+	s.repairPlateTrips(ctx, TripChangeEventRestored, *event.PlateID, event.NormalizedPlate, event.EventTime, event.EventTime)
+}
+
+// repairDeletedTrips пересчитывает рейсы ночи, в которую попала граница удаления cutoff: удалённые въезды до
+// неё освобождают место в квоте для оставшихся после. Ночи целиком до границы пересчитывать не нужно —
+// в них въездов не осталось.
+func (s *ANPRService) repairDeletedTrips(ctx context.Context, cutoff time.Time) {
+	loc := s.defaultCameraLocation()
+	nightEnd := nightStart(cutoff, loc, s.Config().Access.NightStart).AddDate(0, 0, 1)
+	plates, err := s.repo.ListTripPlates(ctx, cutoff, nightEnd)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Time("cutoff", cutoff).Msg("failed to list plates for trip repair")
+		return
+	}
+	for _, plate := range plates {
+		s.repairPlateTrips(ctx, TripChangeEventDeleted, plate.PlateID, plate.NormalizedPlate, cutoff, cutoff)
+	}
+}
+
+// countsInTripQuota сообщает, занимает ли событие место в квоте рейсов (как в repository.RepairTripQuota)
+func countsInTripQuota(event *repository.ANPREvent) bool {
+	return event.PlateID != nil && !event.Late &&
+		event.Direction != nil && *event.Direction == string(anpr.DirectionEntry) &&
+		event.AccessDecision != nil && *event.AccessDecision == string(anpr.DecisionAllow)
+}
+
+// paidTripsPerNight возвращает действующую квоту оплачиваемых рейсов номера: квота номера, иначе квота
+// подрядчика его машины, иначе ACCESS_PAID_TRIPS_PER_NIGHT (тот же порядок, что в evaluateAccess)
+func (s *ANPRService) paidTripsPerNight(ctx context.Context, normalized string) int {
+	quota, err := s.repo.GetPlateTripQuota(ctx, normalized)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("plate", normalized).Msg("failed to load plate trip quota")
+	}
+	if quota != nil {
+		return quota.PaidTripsPerNight
+	}
+	paid := s.Config().Access.PaidTripsPerNight
+	vehicle, err := s.vehicleByPlate(ctx, normalized)
+	if err != nil || vehicle == nil || vehicle.ContractorID == nil {
+		return paid
+	}
+	rule, err := s.repo.GetContractorAccessRule(ctx, *vehicle.ContractorID)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("contractor_id", vehicle.ContractorID.String()).Msg("failed to load contractor access rule")
+	}
+	if rule != nil && rule.PaidTripsPerNight != nil {
+		paid = *rule.PaidTripsPerNight
+	}
+	return paid
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/eventbus"
+	"anpr-service/internal/repository"
+)
+
+func TestRepairTrips(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	bus := eventbus.NewInProcess(zerolog.Nop())
+	svc.bus = bus
+
+	var mu sync.Mutex
+	var published []TripChangedMessage
+	if _, err := bus.Subscribe(eventbus.TopicTripChanged, func(_ context.Context, _ string, payload []byte) error {
+		var msg TripChangedMessage
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+		mu.Lock()
+		published = append(published, msg)
+		mu.Unlock()
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	plateID := uuid.New()
+	night := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	// Въезд в 21:00 пришёл после въезда в 22:00, который был принят как оплачиваемый при квоте 1
+	current := &anpr.Event{
+		ID:       uuid.New(),
+		PlateID:  plateID,
+		Decision: &anpr.AccessDecision{Decision: anpr.DecisionAllow, Reason: anpr.ReasonOverQuota, Detail: "1 of 1 paid trips per night already used"},
+	}
+	current.OverQuota = true
+	later := repository.TripChange{EventID: uuid.New(), PlateID: plateID, NormalizedPlate: "123ABC02", CameraID: "gate-1", EventTime: night.Add(2 * time.Hour), OverQuota: true, TripRevision: 1}
+	repair := repository.TripQuotaRepair{PlateID: plateID, From: night, To: night.AddDate(0, 0, 1), TimeZone: "UTC", NightStartMinutes: 20 * 60, PaidTripsPerNight: 1}
+	store.EXPECT().RepairTripQuota(gomock.Any(), repair).Return([]repository.TripChange{
+		{EventID: current.ID, PlateID: plateID, EventTime: night.Add(time.Hour), OverQuota: false, TripRevision: 1},
+		later,
+	}, nil)
+
+	svc.repairTrips(context.Background(), current, TripChangeOutOfOrder, repair)
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Меняется только статус оплаты, решение при въезде остаётся
+	if current.OverQuota || current.Decision.Reason != anpr.ReasonOverQuota || current.Decision.Detail == "" {
+		t.Fatalf("current event was not repaired: over_quota=%v decision=%+v", current.OverQuota, current.Decision)
+	}
+	if len(published) != 1 {
+		t.Fatalf("published %d trip changes, want 1 (the current event goes out as event created): %+v", len(published), published)
+	}
+	if msg := published[0]; msg.EventID != later.EventID || !msg.OverQuota || msg.TripRevision != 1 || msg.Reason != TripChangeOutOfOrder {
+		t.Fatalf("unexpected trip change: %+v", msg)
+	}
+}
+
+func TestRepairRestoredTrips(t *testing.T) {
+	plateID := uuid.New()
+	eventTime := time.Date(2025, 1, 15, 21, 0, 0, 0, time.UTC)
+	entry, exit, allow, deny := anpr.DirectionEntry, anpr.DirectionExit, anpr.DecisionAllow, "DENY"
+
+	tests := []struct {
+		name       string
+		event      repository.ANPREvent
+		wantRepair bool
+	}{
+		{name: "allowed entry", event: repository.ANPREvent{PlateID: &plateID, Direction: &entry, AccessDecision: &allow}, wantRepair: true},
+		{name: "exit", event: repository.ANPREvent{PlateID: &plateID, Direction: &exit, AccessDecision: &allow}},
+		{name: "denied entry", event: repository.ANPREvent{PlateID: &plateID, Direction: &entry, AccessDecision: &deny}},
+		{name: "late entry", event: repository.ANPREvent{PlateID: &plateID, Direction: &entry, AccessDecision: &allow, Late: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, nil)
+			event := tt.event
+			event.ID, event.NormalizedPlate, event.EventTime = uuid.New(), "123ABC02", eventTime
+			store.EXPECT().GetEventByID(gomock.Any(), event.ID).Return(&event, nil)
+			if tt.wantRepair {
+				store.EXPECT().GetPlateTripQuota(gomock.Any(), "123ABC02").Return(&repository.PlateTripQuota{PaidTripsPerNight: 1}, nil)
+				store.EXPECT().RepairTripQuota(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, repair repository.TripQuotaRepair) ([]repository.TripChange, error) {
+						if repair.PlateID != plateID || eventTime.Before(repair.From) || !eventTime.Before(repair.To) {
+							t.Errorf("repair %+v does not cover the restored entry", repair)
+						}
+						return nil, nil
+					})
+			}
+
+			svc.repairRestoredTrips(context.Background(), event.ID)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// defaultBlacklistName — список, в который по умолчанию попадают номера из разбора
+const defaultBlacklistName = "default_blacklist"
+
+// Действия разбора нераспознанных номеров
+const (
+	UnmatchedActionWhitelist = "whitelist"
+	UnmatchedActionBlacklist = "blacklist"
+)
+
+const (
+	defaultUnmatchedPeriod = 7 * 24 * time.Hour
+	// unmatchedSamples — сколько последних снимков номера показывается в разборе
+	unmatchedSamples = 3
+)
+
+// UnmatchedPlateInfo — номер, которого нет ни в vehicles, ни в списках, с его отклонёнными проездами за период
+type UnmatchedPlateInfo struct {
+	PlateID    string    `json:"plate_id"`
+	Plate      string    `json:"plate"`
+	Normalized string    `json:"normalized"`
+	EventCount int64     `json:"event_count"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	Cameras    []string  `json:"cameras"`
+	Snapshots  []string  `json:"snapshots"`
+}
+
+// UnmatchedReviewInput — решение по номеру из разбора. ListID — конкретный список нужного типа,
+// иначе default_whitelist или default_blacklist.
+type UnmatchedReviewInput struct {
+	Action string
+	ListID *uuid.UUID
+	Note   *string
+}
+
+// UnmatchedReviewResult — список, в который добавлен номер
+type UnmatchedReviewResult struct {
+	PlateID  string `json:"plate_id"`
+	Plate    string `json:"plate"`
+	ListID   string `json:"list_id"`
+	ListName string `json:"list_name"`
+	ListType string `json:"list_type"`
+	Added    bool   `json:"added"` // false — номер уже был в списке
+}
+
+// ListUnmatchedPlates возвращает номера, проезды которых отклонялись из-за отсутствия в vehicles и которые
+// ещё не разобраны (не добавлены ни в один список). По умолчанию — последние 7 дней.
+func (s *ANPRService) ListUnmatchedPlates(ctx context.Context, from, to *time.Time, limit, offset int) ([]UnmatchedPlateInfo, error) {
+	periodTo := s.clock.Now()
+	if to != nil {
+		periodTo = *to
+	}
+	periodFrom := periodTo.Add(-defaultUnmatchedPeriod)
+	if from != nil {
+		periodFrom = *from
+	}
+	if periodTo.Before(periodFrom) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	plates, err := s.repo.ListUnmatchedPlates(ctx, periodFrom, periodTo, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	plateIDs := make([]uuid.UUID, 0, len(plates))
+	for _, p := range plates {
+		plateIDs = append(plateIDs, p.PlateID)
+	}
+	samples, err := s.repo.GetRejectedPhotoSamples(ctx, plateIDs, periodFrom, periodTo, unmatchedSamples)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[uuid.UUID][]string, len(plates))
+	for _, sample := range samples {
+		var urls []string
+		if err := json.Unmarshal(sample.PhotoURLs, &urls); err != nil || len(urls) == 0 {
+			continue
+		}
+		// Первое фото проезда — обзорный кадр с номером
+		snapshots[sample.PlateID] = append(snapshots[sample.PlateID], urls[0])
+	}
+
+	result := make([]UnmatchedPlateInfo, 0, len(plates))
+	for _, p := range plates {
+		info := UnmatchedPlateInfo{
+			PlateID:    p.PlateID.String(),
+			Plate:      p.Number,
+			Normalized: p.Normalized,
+			EventCount: p.EventCount,
+			FirstSeen:  p.FirstSeen,
+			LastSeen:   p.LastSeen,
+			Cameras:    []string{},
+			Snapshots:  snapshots[p.PlateID],
+		}
+		if p.Cameras != "" {
+			info.Cameras = strings.Split(p.Cameras, ",")
+		}
+		if info.Snapshots == nil {
+			info.Snapshots = []string{}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// ReviewUnmatchedPlate добавляет номер из разбора в белый или чёрный список, после чего он
+// пропадает из ListUnmatchedPlates
+func (s *ANPRService) ReviewUnmatchedPlate(ctx context.Context, plateID uuid.UUID, input UnmatchedReviewInput) (*UnmatchedReviewResult, error) {
+	var listType, defaultList string
+	switch strings.ToLower(strings.TrimSpace(input.Action)) {
+	case UnmatchedActionWhitelist:
+		listType, defaultList = "WHITELIST", defaultWhitelistName
+	case UnmatchedActionBlacklist:
+		listType, defaultList = "BLACKLIST", defaultBlacklistName
+	default:
+		return nil, fmt.Errorf("%w: action must be %q or %q", ErrInvalidInput, UnmatchedActionWhitelist, UnmatchedActionBlacklist)
+	}
+
+	plate, err := s.repo.GetPlateByID(ctx, plateID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.reviewList(ctx, input.ListID, defaultList)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(list.Type, listType) {
+		return nil, fmt.Errorf("%w: list %s is not a %s", ErrInvalidInput, list.Name, listType)
+	}
+
+	added, err := s.repo.AddPlateToList(ctx, list.ID, plate.ID, trimNote(input.Note))
+	if err != nil {
+		return nil, err
+	}
+	s.InvalidateListCache()
+
+	s.logger(ctx).Info().
+		Str("plate", plate.Normalized).
+		Str("list", list.Name).
+		Bool("added", added).
+		Msg("unmatched plate reviewed")
+
+	return &UnmatchedReviewResult{
+		PlateID:  plate.ID.String(),
+		Plate:    plate.Number,
+		ListID:   list.ID.String(),
+		ListName: list.Name,
+		ListType: list.Type,
+		Added:    added,
+	}, nil
+}
+
+func (s *ANPRService) reviewList(ctx context.Context, listID *uuid.UUID, defaultName string) (*repository.List, error) {
+	if listID == nil {
+		return s.repo.GetListByName(ctx, defaultName)
+	}
+	return s.repo.GetList(ctx, *listID)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/repository/mocks"
+)
+
+func TestListUnmatchedPlates(t *testing.T) {
+	svc, store := newTestService(t, &config.Config{})
+	plateA, plateB := uuid.New(), uuid.New()
+	from := testNow.Add(-defaultUnmatchedPeriod)
+
+	store.EXPECT().ListUnmatchedPlates(gomock.Any(), from, testNow, 100, 0).Return([]repository.UnmatchedPlate{
+		{PlateID: plateA, Number: "123 ABC 02", Normalized: "123ABC02", EventCount: 5, Cameras: "cam-1,cam-2"},
+		{PlateID: plateB, Number: "777AAA02", Normalized: "777AAA02", EventCount: 1},
+	}, nil)
+	store.EXPECT().GetRejectedPhotoSamples(gomock.Any(), []uuid.UUID{plateA, plateB}, from, testNow, unmatchedSamples).Return([]repository.RejectedPhotos{
+		{PlateID: plateA, PhotoURLs: datatypes.JSON(`["https://photos.example/a1.jpg","https://photos.example/a1-plate.jpg"]`)},
+		{PlateID: plateA, PhotoURLs: datatypes.JSON(`["https://photos.example/a2.jpg"]`)},
+		{PlateID: plateB, PhotoURLs: datatypes.JSON(`[]`)},
+	}, nil)
+
+	plates, err := svc.ListUnmatchedPlates(context.Background(), nil, nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plates) != 2 {
+		t.Fatalf("got %d plates, want 2", len(plates))
+	}
+	if got := plates[0]; len(got.Cameras) != 2 || len(got.Snapshots) != 2 || got.Snapshots[0] != "https://photos.example/a1.jpg" {
+		t.Errorf("unexpected first plate: %+v", got)
+	}
+	if got := plates[1]; len(got.Cameras) != 0 || got.Snapshots == nil || len(got.Snapshots) != 0 {
+		t.Errorf("unexpected second plate: %+v", got)
+	}
+}
+
+func TestListUnmatchedPlatesRejectsInvertedPeriod(t *testing.T) {
+	svc, _ := newTestService(t, &config.Config{})
+	from := testNow
+	to := testNow.Add(-time.Hour)
+	if _, err := svc.ListUnmatchedPlates(context.Background(), &from, &to, 0, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestReviewUnmatchedPlate(t *testing.T) {
+	plateID := uuid.New()
+	whitelist := &repository.List{ID: uuid.New(), Name: defaultWhitelistName, Type: "WHITELIST"}
+	blacklist := &repository.List{ID: uuid.New(), Name: defaultBlacklistName, Type: "BLACKLIST"}
+
+	tests := []struct {
+		name    string
+		input   UnmatchedReviewInput
+		setup   func(e *mocks.MockANPRStoreMockRecorder)
+		wantErr error
+		want    *repository.List
+	}{
+		{
+			name:  "whitelist into default list",
+			input: UnmatchedReviewInput{Action: "whitelist"},
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.GetListByName(gomock.Any(), defaultWhitelistName).Return(whitelist, nil)
+				e.AddPlateToList(gomock.Any(), whitelist.ID, plateID, nil).Return(true, nil)
+			},
+			want: whitelist,
+		},
+		{
+			name:  "blacklist into chosen list",
+			input: UnmatchedReviewInput{Action: "BLACKLIST", ListID: &blacklist.ID},
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.GetList(gomock.Any(), blacklist.ID).Return(blacklist, nil)
+				e.AddPlateToList(gomock.Any(), blacklist.ID, plateID, nil).Return(false, nil)
+			},
+			want: blacklist,
+		},
+		{
+			name:  "list type must match action",
+			input: UnmatchedReviewInput{Action: "whitelist", ListID: &blacklist.ID},
+			setup: func(e *mocks.MockANPRStoreMockRecorder) {
+				e.GetList(gomock.Any(), blacklist.ID).Return(blacklist, nil)
+			},
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "unknown action",
+			input:   UnmatchedReviewInput{Action: "ignore"},
+			wantErr: ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, &config.Config{})
+			if tt.setup != nil {
+				store.EXPECT().GetPlateByID(gomock.Any(), plateID).Return(&repository.Plate{ID: plateID, Number: "123ABC02", Normalized: "123ABC02"}, nil)
+				tt.setup(store.EXPECT())
+			}
+
+			result, err := svc.ReviewUnmatchedPlate(context.Background(), plateID, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && (result.ListID != tt.want.ID.String() || result.ListType != tt.want.Type) {
+				t.Errorf("result = %+v, want list %s", result, tt.want.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/lifecycle"
+	"anpr-service/internal/repository"
+)
+
+// usageReportMaxDays — наибольший период отчёта об использовании
+const usageReportMaxDays = 366
+
+// UsageInfo — использование сервиса организацией за сутки
+type UsageInfo struct {
+	Day string `json:"day"`
+	// OrganizationID — организация; нулевой UUID — события полигонов без организации
+	OrganizationID uuid.UUID `json:"organization_id"`
+	EventsIngested int64     `json:"events_ingested"`
+	StorageBytes   int64     `json:"storage_bytes"`
+	APICalls       int64     `json:"api_calls"`
+}
+
+// usageCounts — накопленные, но ещё не записанные в БД счётчики
+type usageCounts struct {
+	events   int64
+	bytes    int64
+	apiCalls int64
+}
+
+// usageMeter копит счётчики использования в памяти реплики до сброса в БД (см. FlushUsage).
+// Приём событий учитывается по камере (организация определяется при сбросе через полигон камеры),
+// обращения к API — сразу по организации пользователя.
+type usageMeter struct {
+	mu      sync.Mutex
+	cameras map[usageCameraKey]*usageCounts
+	orgs    map[usageOrgKey]*usageCounts
+}
+
+type usageCameraKey struct {
+	day      time.Time
+	cameraID string
+}
+
+type usageOrgKey struct {
+	day   time.Time
+	orgID uuid.UUID
+}
+
+func (m *usageMeter) addCamera(key usageCameraKey, delta usageCounts) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cameras == nil {
+		m.cameras = map[usageCameraKey]*usageCounts{}
+	}
+	counts, ok := m.cameras[key]
+	if !ok {
+		counts = &usageCounts{}
+		m.cameras[key] = counts
+	}
+	counts.add(delta)
+}
+
+func (m *usageMeter) addOrg(key usageOrgKey, delta usageCounts) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.orgs == nil {
+		m.orgs = map[usageOrgKey]*usageCounts{}
+	}
+	counts, ok := m.orgs[key]
+	if !ok {
+		counts = &usageCounts{}
+		m.orgs[key] = counts
+	}
+	counts.add(delta)
+}
+
+// drain забирает накопленные счётчики
+func (m *usageMeter) drain() (map[usageCameraKey]*usageCounts, map[usageOrgKey]*usageCounts) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cameras, orgs := m.cameras, m.orgs
+	m.cameras, m.orgs = nil, nil
+	return cameras, orgs
+}
+
+func (c *usageCounts) add(delta usageCounts) {
+	c.events += delta.events
+	c.bytes += delta.bytes
+	c.apiCalls += delta.apiCalls
+}
+
+// usageDay — сутки учёта использования в поясе ведомости оплаты (BILLING_TIMEZONE)
+func (s *ANPRService) usageDay(at time.Time) time.Time {
+	loc, err := time.LoadLocation(s.Config().Billing.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := at.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RecordAPICall учитывает обращение пользователя организации к API
+func (s *ANPRService) RecordAPICall(orgID uuid.UUID) {
+	if !s.Config().Usage.Enabled {
+		return
+	}
+	s.usage.addOrg(usageOrgKey{day: s.usageDay(s.clock.Now()), orgID: orgID}, usageCounts{apiCalls: 1})
+}
+
+// recordIngestUsage учитывает принятое камерой событие и объём загруженных фото
+func (s *ANPRService) recordIngestUsage(cameraID string, events, bytes int64) {
+	if !s.Config().Usage.Enabled {
+		return
+	}
+	s.usage.addCamera(usageCameraKey{day: s.usageDay(s.clock.Now()), cameraID: cameraID}, usageCounts{events: events, bytes: bytes})
+}
+
+// FlushUsage записывает накопленные счётчики использования в БД. Если запись не удалась, счётчики
+// возвращаются в память и уйдут со следующим сбросом.
+func (s *ANPRService) FlushUsage(ctx context.Context) error {
+	cameras, orgs := s.usage.drain()
+	if len(cameras) == 0 && len(orgs) == 0 {
+		return nil
+	}
+
+	totals := map[usageOrgKey]*usageCounts{}
+	for key, counts := range orgs {
+		totals[key] = counts
+	}
+	if len(cameras) > 0 {
+		cameraOrgs, err := s.cameraOrganizations(ctx, cameras)
+		if err != nil {
+			s.restoreUsage(cameras, orgs)
+			return err
+		}
+		for key, counts := range cameras {
+			orgKey := usageOrgKey{day: key.day, orgID: cameraOrgs[key.cameraID]}
+			if total, ok := totals[orgKey]; ok {
+				total.add(*counts)
+			} else {
+				copied := *counts
+				totals[orgKey] = &copied
+			}
+		}
+	}
+
+	now := s.clock.Now()
+	rows := make([]repository.UsageDaily, 0, len(totals))
+	for key, counts := range totals {
+		rows = append(rows, repository.UsageDaily{
+			Day:            key.day,
+			OrganizationID: key.orgID,
+			EventsIngested: counts.events,
+			StorageBytes:   counts.bytes,
+			APICalls:       counts.apiCalls,
+			UpdatedAt:      now,
+		})
+	}
+	if err := s.repo.AddUsage(ctx, rows); err != nil {
+		s.restoreUsage(cameras, orgs)
+		return err
+	}
+	return nil
+}
+
+func (s *ANPRService) restoreUsage(cameras map[usageCameraKey]*usageCounts, orgs map[usageOrgKey]*usageCounts) {
+	for key, counts := range cameras {
+		s.usage.addCamera(key, *counts)
+	}
+	for key, counts := range orgs {
+		s.usage.addOrg(key, *counts)
+	}
+}
+
+// cameraOrganizations определяет организацию полигона каждой камеры; камера без полигона или полигон без
+// организации — нулевой UUID
+func (s *ANPRService) cameraOrganizations(ctx context.Context, cameras map[usageCameraKey]*usageCounts) (map[string]uuid.UUID, error) {
+	polygons, err := s.repo.ListPolygons(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list polygons: %w", err)
+	}
+	polygonOrgs := make(map[uuid.UUID]uuid.UUID, len(polygons))
+	for _, polygon := range polygons {
+		if polygon.OrganizationID != nil {
+			polygonOrgs[polygon.ID] = *polygon.OrganizationID
+		}
+	}
+
+	result := map[string]uuid.UUID{}
+	for key := range cameras {
+		if _, ok := result[key.cameraID]; ok {
+			continue
+		}
+		orgID := uuid.Nil
+		if polygonID := s.cameraPolygonID(ctx, s.lookupCamera(ctx, key.cameraID), key.cameraID); polygonID != nil {
+			orgID = polygonOrgs[*polygonID]
+		}
+		result[key.cameraID] = orgID
+	}
+	return result, nil
+}
+
+// RunUsageMetering раз в USAGE_FLUSH_INTERVAL записывает счётчики использования реплики в БД.
+// Блокируется до отмены ctx; при остановке записывает остаток.
+func (s *ANPRService) RunUsageMetering(ctx context.Context) {
+	if !s.Config().Usage.Enabled {
+		return
+	}
+	ticker := time.NewTicker(s.Config().Usage.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := lifecycle.Detach(ctx)
+			if err := s.FlushUsage(flushCtx); err != nil {
+				s.log.Warn().Err(err).Msg("failed to flush usage on shutdown")
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+		if err := s.FlushUsage(ctx); err != nil && ctx.Err() == nil {
+			s.log.Warn().Err(err).Msg("failed to flush usage")
+		}
+	}
+}
+
+// UsageReport возвращает использование по организациям за дни [from, to] (YYYY-MM-DD, по умолчанию —
+// последние 30 дней). Счётчики реплик попадают в отчёт с задержкой до USAGE_FLUSH_INTERVAL.
+// Доступно администратору акимата.
+func (s *ANPRService) UsageReport(ctx context.Context, from, to, organizationID string) ([]UsageInfo, error) {
+	if _, err := requirePrincipal(ctx, canViewUsage); err != nil {
+		return nil, err
+	}
+
+	toDay := s.usageDay(s.clock.Now())
+	if to != "" {
+		parsed, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidInput)
+		}
+		toDay = parsed
+	}
+	fromDay := toDay.AddDate(0, 0, -29)
+	if from != "" {
+		parsed, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidInput)
+		}
+		fromDay = parsed
+	}
+	if fromDay.After(toDay) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidInput)
+	}
+	if toDay.Sub(fromDay) >= usageReportMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: period must not exceed %d days", ErrInvalidInput, usageReportMaxDays)
+	}
+
+	var orgID *uuid.UUID
+	if organizationID != "" {
+		parsed, err := uuid.Parse(organizationID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid organization_id", ErrInvalidInput)
+		}
+		orgID = &parsed
+	}
+
+	rows, err := s.repo.ListUsage(ctx, fromDay, toDay, orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]UsageInfo, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, UsageInfo{
+			Day:            row.Day.Format(time.DateOnly),
+			OrganizationID: row.OrganizationID,
+			EventsIngested: row.EventsIngested,
+			StorageBytes:   row.StorageBytes,
+			APICalls:       row.APICalls,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+func TestFlushUsage(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Usage.Enabled = true
+	cfg.Billing.TimeZone = "Asia/Almaty"
+	svc, store := newTestService(t, cfg)
+	ctx := context.Background()
+
+	orgID, polygonID, userOrgID := uuid.New(), uuid.New(), uuid.New()
+	svc.recordIngestUsage("cam-1", 1, 0)
+	svc.recordIngestUsage("cam-1", 0, 2048)
+	svc.recordIngestUsage("cam-1", 1, 0)
+	svc.recordIngestUsage("cam-2", 1, 0)
+	svc.RecordAPICall(userOrgID)
+	svc.RecordAPICall(orgID)
+
+	// Первая запись не удалась: счётчики остаются до следующего сброса
+	store.EXPECT().ListPolygons(gomock.Any()).Return(nil, errors.New("connection refused"))
+	if err := svc.FlushUsage(ctx); err == nil {
+		t.Fatal("FlushUsage() error = nil, want error")
+	}
+
+	store.EXPECT().ListPolygons(gomock.Any()).Return([]repository.Polygon{{ID: polygonID, OrganizationID: &orgID}}, nil)
+	store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(&repository.Camera{ID: "cam-1", PolygonID: &polygonID}, nil)
+	store.EXPECT().GetCamera(gomock.Any(), "cam-2").Return(nil, repository.ErrCameraNotFound)
+	store.EXPECT().ResolvePolygonIDByCameraID(gomock.Any(), "cam-2").Return(nil, nil)
+	var rows []repository.UsageDaily
+	store.EXPECT().AddUsage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.UsageDaily) error {
+		rows = got
+		return nil
+	})
+	if err := svc.FlushUsage(ctx); err != nil {
+		t.Fatalf("FlushUsage() error = %v", err)
+	}
+
+	// 22:00 UTC — уже следующие сутки в Алматы
+	day := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	want := map[uuid.UUID]repository.UsageDaily{
+		orgID:     {EventsIngested: 2, StorageBytes: 2048, APICalls: 1},
+		uuid.Nil:  {EventsIngested: 1},
+		userOrgID: {APICalls: 1},
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].OrganizationID.String() < rows[j].OrganizationID.String() })
+	if len(rows) != len(want) {
+		t.Fatalf("AddUsage rows = %+v, want %d rows", rows, len(want))
+	}
+	for _, row := range rows {
+		w := want[row.OrganizationID]
+		if !row.Day.Equal(day) || row.EventsIngested != w.EventsIngested || row.StorageBytes != w.StorageBytes || row.APICalls != w.APICalls {
+			t.Errorf("row for %s = %+v, want %+v on %s", row.OrganizationID, row, w, day.Format(time.DateOnly))
+		}
+	}
+
+	// Всё записано: следующему сбросу нечего писать
+	if err := svc.FlushUsage(ctx); err != nil {
+		t.Fatalf("empty FlushUsage() error = %v", err)
+	}
+}
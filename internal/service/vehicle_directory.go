@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"anpr-service/internal/client/roles"
+	"anpr-service/internal/repository"
+)
+
+// VehicleDirectory ищет транспорт подрядчиков в roles-сервисе (реализуется *roles.Vehicles)
+type VehicleDirectory interface {
+	VehicleByPlate(ctx context.Context, plate string) (*roles.Vehicle, error)
+}
+
+// UseVehicleDirectory переключает поиск транспорта с таблицы vehicles общей БД на roles-сервис
+func (s *ANPRService) UseVehicleDirectory(directory VehicleDirectory) {
+	s.vehicles = directory
+}
+
+// vehicleByPlate возвращает активный транспорт с нормализованным номером; nil — не найден
+func (s *ANPRService) vehicleByPlate(ctx context.Context, normalized string) (*repository.VehicleData, error) {
+	if s.vehicles == nil {
+		return s.repo.GetVehicleByPlate(ctx, normalized)
+	}
+	vehicle, err := s.vehicles.VehicleByPlate(ctx, normalized)
+	if err != nil || vehicle == nil {
+		return nil, err
+	}
+	return &repository.VehicleData{
+		Brand:        vehicle.Brand,
+		Model:        vehicle.Model,
+		Color:        vehicle.Color,
+		Year:         vehicle.Year,
+		BodyVolumeM3: vehicle.BodyVolumeM3,
+		ContractorID: vehicle.ContractorID,
+	}, nil
+}
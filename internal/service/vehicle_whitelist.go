@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"anpr-service/internal/utils"
+)
+
+// RemoveVehicleFromWhitelist убирает номер деактивированного транспорта из default_whitelist.
+// Возвращает false, если номера там не было. Доступно тем же ролям, что и SyncVehicleToWhitelist.
+func (s *ANPRService) RemoveVehicleFromWhitelist(ctx context.Context, plateNumber string) (bool, error) {
+	if _, err := requirePrincipal(ctx, canManageLists); err != nil {
+		return false, err
+	}
+	normalized := utils.NormalizePlate(plateNumber)
+	if normalized == "" {
+		return false, fmt.Errorf("%w: plate_number is empty", ErrInvalidInput)
+	}
+
+	removed, err := s.repo.RemoveVehicleFromWhitelist(ctx, normalized)
+	if err != nil {
+		return false, err
+	}
+	if removed {
+		s.InvalidateListCache()
+	}
+	s.logger(ctx).Info().
+		Str("plate", normalized).
+		Bool("removed", removed).
+		Msg("vehicle removed from whitelist")
+	return removed, nil
+}
+
+// ReconcileVehicleWhitelist сверяет записи default_whitelist из vehicles с источником транспорта
+// (таблица vehicles или roles-сервис) и возвращает число удалённых. Номер, который не удалось
+// проверить, остаётся в списке до следующей сверки.
+func (s *ANPRService) ReconcileVehicleWhitelist(ctx context.Context) (int, error) {
+	plates, err := s.repo.ListVehicleWhitelistPlates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, failed := 0, 0
+	for _, plate := range plates {
+		if ctx.Err() != nil {
+			return removed, ctx.Err()
+		}
+		vehicle, err := s.vehicleByPlate(ctx, plate)
+		if err != nil {
+			failed++
+			s.logger(ctx).Warn().Err(err).Str("plate", plate).Msg("failed to check vehicle for whitelist reconciliation")
+			continue
+		}
+		if vehicle != nil {
+			continue
+		}
+		ok, err := s.repo.RemoveVehicleFromWhitelist(ctx, plate)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		s.InvalidateListCache()
+	}
+	if removed > 0 || failed > 0 {
+		s.logger(ctx).Info().
+			Int("checked", len(plates)).
+			Int("removed", removed).
+			Int("failed", failed).
+			Msg("vehicle whitelist reconciled")
+	}
+	return removed, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/repository"
+)
+
+func TestReconcileVehicleWhitelist(t *testing.T) {
+	svc, store := newTestService(t, nil)
+
+	store.EXPECT().ListVehicleWhitelistPlates(gomock.Any()).Return([]string{"111AAA01", "222BBB02", "333CCC03"}, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), "111AAA01").Return(&repository.VehicleData{Brand: "KAMAZ"}, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), "222BBB02").Return(nil, nil)
+	store.EXPECT().GetVehicleByPlate(gomock.Any(), "333CCC03").Return(nil, errors.New("connection reset"))
+	store.EXPECT().RemoveVehicleFromWhitelist(gomock.Any(), "222BBB02").Return(true, nil)
+
+	removed, err := svc.ReconcileVehicleWhitelist(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcileVehicleWhitelist() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1 (active vehicle kept, unchecked plate left for next run)", removed)
+	}
+}
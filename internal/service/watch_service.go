@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/i18n"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// Каналы уведомлений отслеживания номеров
+const (
+	// WatchChannelTelegram — сообщение ботом в чат Telegram (target — ID чата)
+	WatchChannelTelegram = "telegram"
+)
+
+const (
+	// plateWatchNotificationKind — тип уведомления Telegram о проезде отслеживаемого номера
+	plateWatchNotificationKind = "plate_watch"
+	// plateWatchDefaultTTL — срок отслеживания, если expires_at не задан
+	plateWatchDefaultTTL = 30 * 24 * time.Hour
+	// plateWatchMaxTTL — наибольший срок отслеживания
+	plateWatchMaxTTL = 180 * 24 * time.Hour
+	// plateWatchMaxPerUser — сколько действующих отслеживаний может быть у пользователя
+	plateWatchMaxPerUser = 50
+	// plateWatchMinChars — сколько символов шаблона, кроме * и ?, нужно, чтобы не отслеживать все номера подряд
+	plateWatchMinChars = 3
+	// plateWatchMaxLength — предельная длина шаблона в символах
+	plateWatchMaxLength = 20
+)
+
+// PlateWatchInfo — отслеживание номера для API
+type PlateWatchInfo struct {
+	ID             string     `json:"id"`
+	Pattern        string     `json:"pattern"`
+	Channel        string     `json:"channel"`
+	Target         string     `json:"target"`
+	Note           *string    `json:"note,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Expired        bool       `json:"expired"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+}
+
+// PlateWatchInput — новое отслеживание номера
+type PlateWatchInput struct {
+	// Pattern — номер в любом формате; * — любые символы, ? — один символ
+	Pattern string
+	Channel string
+	Target  string
+	Note    *string
+	// ExpiresAt — до какого момента уведомлять; nil — plateWatchDefaultTTL от текущего момента
+	ExpiresAt *time.Time
+}
+
+// CreatePlateWatch добавляет отслеживание номера пользователю запроса
+func (s *ANPRService) CreatePlateWatch(ctx context.Context, input PlateWatchInput) (*PlateWatchInfo, error) {
+	principal, err := requirePrincipal(ctx, canWatchPlates)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := normalizeWatchPattern(input.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	channel := strings.ToLower(strings.TrimSpace(input.Channel))
+	if channel == "" {
+		channel = WatchChannelTelegram
+	}
+	if channel != WatchChannelTelegram {
+		return nil, fmt.Errorf("%w: channel must be %s", ErrInvalidInput, WatchChannelTelegram)
+	}
+	if s.Config().Telegram.BotToken == "" {
+		return nil, fmt.Errorf("%w: telegram notifications are not configured", ErrInvalidInput)
+	}
+	target := strings.TrimSpace(input.Target)
+	if target == "" {
+		return nil, fmt.Errorf("%w: target is required (telegram chat id)", ErrInvalidInput)
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(plateWatchDefaultTTL)
+	if input.ExpiresAt != nil {
+		expiresAt = *input.ExpiresAt
+	}
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidInput)
+	}
+	if expiresAt.Sub(now) > plateWatchMaxTTL {
+		return nil, fmt.Errorf("%w: expires_at must be within %d days", ErrInvalidInput, int(plateWatchMaxTTL.Hours()/24))
+	}
+
+	count, err := s.repo.CountPlateWatches(ctx, principal.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if count >= plateWatchMaxPerUser {
+		return nil, fmt.Errorf("%w: at most %d active watches per user", ErrInvalidInput, plateWatchMaxPerUser)
+	}
+
+	watch := &repository.PlateWatch{
+		UserID:    principal.UserID,
+		Pattern:   pattern,
+		Channel:   channel,
+		Target:    target,
+		Note:      trimNote(input.Note),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreatePlateWatch(ctx, watch); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().
+		Str("watch_id", watch.ID.String()).
+		Str("user_id", principal.UserID.String()).
+		Str("pattern", pattern).
+		Time("expires_at", expiresAt).
+		Msg("plate watch created")
+	info := toPlateWatchInfo(*watch, now)
+	return &info, nil
+}
+
+// ListPlateWatches возвращает отслеживания пользователя запроса, в том числе истёкшие
+func (s *ANPRService) ListPlateWatches(ctx context.Context) ([]PlateWatchInfo, error) {
+	principal, err := requirePrincipal(ctx, canWatchPlates)
+	if err != nil {
+		return nil, err
+	}
+	watches, err := s.repo.ListPlateWatches(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	result := make([]PlateWatchInfo, 0, len(watches))
+	for _, watch := range watches {
+		result = append(result, toPlateWatchInfo(watch, now))
+	}
+	return result, nil
+}
+
+// DeletePlateWatch удаляет отслеживание; чужие отслеживания для пользователя не существуют
+func (s *ANPRService) DeletePlateWatch(ctx context.Context, id uuid.UUID) error {
+	principal, err := requirePrincipal(ctx, canWatchPlates)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeletePlateWatch(ctx, id, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: plate watch not found", ErrNotFound)
+	}
+	s.logger(ctx).Info().Str("watch_id", id.String()).Str("user_id", principal.UserID.String()).Msg("plate watch deleted")
+	return nil
+}
+
+// NotifyPlateWatches — обработчик топика eventbus.TopicEventCreated: ставит в очередь уведомления
+// владельцам действующих отслеживаний, под шаблон которых подходит номер события. Ключ уведомления —
+// отслеживание и событие, поэтому при нескольких репликах уведомление не дублируется.
+// Опоздавшие события (late) пропускаются, как и в NotifyTelegram.
+func (s *ANPRService) NotifyPlateWatches(ctx context.Context, topic string, payload []byte) error {
+	var msg EventCreatedMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return fmt.Errorf("decode %s message: %w", topic, err)
+	}
+	if msg.Late {
+		return nil
+	}
+
+	now := s.clock.Now()
+	watches, err := s.repo.ListActivePlateWatches(ctx, now)
+	if err != nil {
+		return err
+	}
+	var photoURL *string
+	if len(msg.Photos) > 0 {
+		photoURL = &msg.Photos[0]
+	}
+
+	lang := s.notificationLanguage()
+	var notifications []repository.TelegramNotification
+	var matched []uuid.UUID
+	for _, watch := range watches {
+		if !watchMatches(watch.Pattern, msg.Plate) {
+			continue
+		}
+		text := i18n.Text(lang, "notify.watch", msg.Plate, watch.Pattern, s.telegramEventDetails(ctx, msg))
+		if watch.Note != nil {
+			text += "\n" + i18n.Text(lang, "notify.watch_note", *watch.Note)
+		}
+		notifications = append(notifications, repository.TelegramNotification{
+			DedupKey: fmt.Sprintf("watch:%s:%s", watch.ID, msg.EventID),
+			ChatID:   watch.Target,
+			Kind:     plateWatchNotificationKind,
+			Text:     text,
+			PhotoURL: photoURL,
+		})
+		matched = append(matched, watch.ID)
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := s.repo.EnqueueTelegramNotifications(ctx, notifications); err != nil {
+		return err
+	}
+	// Время срабатывания только для списка отслеживаний: ошибка не повод повторять уведомления
+	if err := s.repo.TouchPlateWatches(ctx, matched, now); err != nil {
+		s.logger(ctx).Warn().Err(err).Int("watches", len(matched)).Msg("failed to record plate watch notification time")
+	}
+	return nil
+}
+
+// normalizeWatchPattern нормализует шаблон как номер (см. utils.NormalizePlate) и проверяет, что в нём
+// только буквы, цифры, * и ? и достаточно символов, кроме подстановочных
+func normalizeWatchPattern(raw string) (string, error) {
+	pattern := utils.NormalizePlate(raw)
+	if pattern == "" {
+		return "", fmt.Errorf("%w: pattern is required", ErrInvalidInput)
+	}
+	chars := 0
+	for _, r := range pattern {
+		switch {
+		case r == '*' || r == '?':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			chars++
+		default:
+			return "", fmt.Errorf("%w: pattern may contain only letters, digits, * and ?", ErrInvalidInput)
+		}
+	}
+	if chars < plateWatchMinChars {
+		return "", fmt.Errorf("%w: pattern must contain at least %d letters or digits", ErrInvalidInput, plateWatchMinChars)
+	}
+	if utf8.RuneCountInString(pattern) > plateWatchMaxLength {
+		return "", fmt.Errorf("%w: pattern must be at most %d characters", ErrInvalidInput, plateWatchMaxLength)
+	}
+	return pattern, nil
+}
+
+// watchMatches сверяет нормализованный номер с шаблоном отслеживания
+func watchMatches(pattern, plate string) bool {
+	matched, err := path.Match(pattern, plate)
+	return err == nil && matched
+}
+
+func toPlateWatchInfo(watch repository.PlateWatch, now time.Time) PlateWatchInfo {
+	return PlateWatchInfo{
+		ID:             watch.ID.String(),
+		Pattern:        watch.Pattern,
+		Channel:        watch.Channel,
+		Target:         watch.Target,
+		Note:           watch.Note,
+		ExpiresAt:      watch.ExpiresAt,
+		Expired:        !watch.ExpiresAt.After(now),
+		CreatedAt:      watch.CreatedAt,
+		LastNotifiedAt: watch.LastNotifiedAt,
+	}
+}
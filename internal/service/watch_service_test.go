@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+func TestNotifyPlateWatches(t *testing.T) {
+	svc, store := newTestService(t, nil)
+	store.EXPECT().GetCamera(gomock.Any(), "cam-1").Return(nil, repository.ErrCameraNotFound).AnyTimes()
+
+	note := "угнан 12.01"
+	exact := repository.PlateWatch{ID: uuid.New(), Pattern: "123ABC02", Target: "-100", Note: &note}
+	region := repository.PlateWatch{ID: uuid.New(), Pattern: "???ABC*", Target: "-200"}
+	other := repository.PlateWatch{ID: uuid.New(), Pattern: "777*", Target: "-300"}
+	store.EXPECT().ListActivePlateWatches(gomock.Any(), testNow).Return([]repository.PlateWatch{exact, region, other}, nil)
+
+	msg := EventCreatedMessage{EventID: uuid.New(), Plate: "123ABC02", CameraID: "cam-1", Direction: "entry", EventTime: testNow}
+	payload, _ := json.Marshal(msg)
+
+	store.EXPECT().EnqueueTelegramNotifications(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.TelegramNotification) error {
+		if len(got) != 2 {
+			t.Fatalf("got %d notifications, want 2 matching watches", len(got))
+		}
+		if got[0].ChatID != "-100" || got[0].Kind != plateWatchNotificationKind || got[0].DedupKey != "watch:"+exact.ID.String()+":"+msg.EventID.String() {
+			t.Errorf("unexpected notification: %+v", got[0])
+		}
+		if !strings.Contains(got[0].Text, note) || got[1].ChatID != "-200" {
+			t.Errorf("unexpected notifications: %+v", got)
+		}
+		return nil
+	})
+	store.EXPECT().TouchPlateWatches(gomock.Any(), []uuid.UUID{exact.ID, region.ID}, testNow).Return(nil)
+
+	if err := svc.NotifyPlateWatches(context.Background(), "anpr.event.created", payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreatePlateWatch(t *testing.T) {
+	inspector := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleKguZkhUser})
+	contractor := model.WithPrincipal(context.Background(), model.Principal{UserID: uuid.New(), Role: model.UserRoleContractorAdmin})
+	tooLate := testNow.Add(365 * 24 * time.Hour)
+	past := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   PlateWatchInput
+		wantErr error
+	}{
+		{name: "contractor", ctx: contractor, input: PlateWatchInput{Pattern: "123ABC02", Target: "-100"}, wantErr: ErrForbidden},
+		{name: "wildcards only", ctx: inspector, input: PlateWatchInput{Pattern: "*02", Target: "-100"}, wantErr: ErrInvalidInput},
+		{name: "unsupported characters", ctx: inspector, input: PlateWatchInput{Pattern: "123[AB]02", Target: "-100"}, wantErr: ErrInvalidInput},
+		{name: "unknown channel", ctx: inspector, input: PlateWatchInput{Pattern: "123ABC02", Channel: "sms", Target: "-100"}, wantErr: ErrInvalidInput},
+		{name: "missing target", ctx: inspector, input: PlateWatchInput{Pattern: "123ABC02"}, wantErr: ErrInvalidInput},
+		{name: "expired", ctx: inspector, input: PlateWatchInput{Pattern: "123ABC02", Target: "-100", ExpiresAt: &past}, wantErr: ErrInvalidInput},
+		{name: "too long", ctx: inspector, input: PlateWatchInput{Pattern: "123ABC02", Target: "-100", ExpiresAt: &tooLate}, wantErr: ErrInvalidInput},
+		{name: "created", ctx: inspector, input: PlateWatchInput{Pattern: "123 abc *", Target: " -100 "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newTestService(t, telegramTestConfig())
+			if tt.wantErr == nil {
+				store.EXPECT().CountPlateWatches(gomock.Any(), gomock.Any(), testNow).Return(int64(0), nil)
+				store.EXPECT().CreatePlateWatch(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			watch, err := svc.CreatePlateWatch(tt.ctx, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreatePlateWatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (watch.Pattern != "123ABC*" || watch.Target != "-100" || watch.Channel != WatchChannelTelegram || !watch.ExpiresAt.Equal(testNow.Add(plateWatchDefaultTTL))) {
+				t.Errorf("unexpected watch: %+v", watch)
+			}
+		})
+	}
+}
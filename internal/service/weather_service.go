@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/weather"
+)
+
+// WeatherProvider возвращает почасовую погоду в точке (реализуется *weather.Client)
+type WeatherProvider interface {
+	Hourly(ctx context.Context, latitude, longitude float64, from, to time.Time) ([]weather.Observation, error)
+}
+
+// RunWeatherSync сразу и затем с периодом WEATHER_POLL_INTERVAL загружает погоду по полигонам и
+// проставляет её событиям. Блокируется до отмены ctx.
+func (s *ANPRService) RunWeatherSync(ctx context.Context, provider WeatherProvider) {
+	if provider == nil || !s.Config().Weather.Enabled {
+		return
+	}
+	ticker := time.NewTicker(s.Config().Weather.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncWeather(ctx, provider); err != nil && ctx.Err() == nil {
+			s.logger(ctx).Warn().Err(err).Msg("failed to sync weather")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncWeather загружает погоду за последние WEATHER_LOOKBACK по каждому полигону с координатами и
+// проставляет её событиям этого периода. Сохраняются только прошедшие часы: прогноз на текущий час
+// в событие не попадает, оно получит погоду при следующей синхронизации.
+func (s *ANPRService) SyncWeather(ctx context.Context, provider WeatherProvider) error {
+	locations, err := s.repo.ListWeatherLocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	from := now.Add(-s.Config().Weather.Lookback)
+	var stored int
+	for _, location := range locations {
+		hourly, err := provider.Hourly(ctx, location.Latitude, location.Longitude, from, now)
+		if err != nil {
+			// Один недоступный полигон не мешает остальным
+			s.logger(ctx).Warn().Err(err).Str("polygon_id", location.PolygonID.String()).Msg("failed to fetch weather")
+			continue
+		}
+		observations := make([]repository.WeatherObservation, 0, len(hourly))
+		for _, obs := range hourly {
+			if obs.Hour.After(now) || !obs.Hour.After(from) {
+				continue
+			}
+			observations = append(observations, repository.WeatherObservation{
+				PolygonID:    location.PolygonID,
+				ObservedHour: obs.Hour,
+				TemperatureC: obs.TemperatureC,
+				SnowfallCm:   obs.SnowfallCm,
+			})
+		}
+		if err := s.repo.UpsertWeatherObservations(ctx, observations); err != nil {
+			return err
+		}
+		stored += len(observations)
+	}
+
+	updated, err := s.repo.ApplyWeatherToEvents(ctx, from, now)
+	if err != nil {
+		return err
+	}
+	s.logger(ctx).Debug().
+		Int("polygons", len(locations)).
+		Int("observations", stored).
+		Int64("events", updated).
+		Msg("weather synced")
+	return nil
+}
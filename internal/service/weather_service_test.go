@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/weather"
+)
+
+type fakeWeatherProvider struct {
+	observations map[float64][]weather.Observation // по широте
+	err          error
+}
+
+func (f *fakeWeatherProvider) Hourly(_ context.Context, latitude, _ float64, _, _ time.Time) ([]weather.Observation, error) {
+	if f.err != nil && latitude < 0 {
+		return nil, f.err
+	}
+	return f.observations[latitude], nil
+}
+
+func TestSyncWeather(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Weather = config.WeatherConfig{Enabled: true, Lookback: 24 * time.Hour}
+	svc, store := newTestService(t, cfg)
+
+	polygonID, brokenID := uuid.New(), uuid.New()
+	temp, snow := -6.0, 1.2
+	from := testNow.Add(-24 * time.Hour)
+	provider := &fakeWeatherProvider{
+		observations: map[float64][]weather.Observation{
+			43.2: {
+				{Hour: from, TemperatureC: &temp},                                       // до периода
+				{Hour: testNow.Add(-time.Hour), TemperatureC: &temp, SnowfallCm: &snow}, // прошедший час
+				{Hour: testNow.Add(time.Hour), TemperatureC: &temp},                     // прогноз
+			},
+		},
+		err: errors.New("provider down"),
+	}
+
+	store.EXPECT().ListWeatherLocations(gomock.Any()).Return([]repository.WeatherLocation{
+		{PolygonID: polygonID, Latitude: 43.2, Longitude: 76.9},
+		{PolygonID: brokenID, Latitude: -1, Longitude: 0},
+	}, nil)
+	store.EXPECT().UpsertWeatherObservations(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, got []repository.WeatherObservation) error {
+		if len(got) != 1 || got[0].PolygonID != polygonID || !got[0].ObservedHour.Equal(testNow.Add(-time.Hour)) || *got[0].SnowfallCm != snow {
+			t.Errorf("observations = %+v, want only the past hour", got)
+		}
+		return nil
+	})
+	store.EXPECT().ApplyWeatherToEvents(gomock.Any(), from, testNow).Return(int64(3), nil)
+
+	if err := svc.SyncWeather(context.Background(), provider); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+// Заголовки запроса вебхука. Подпись — HMAC-SHA256 секрета подписки от "<timestamp>.<тело>"
+// в hex: подписчик проверяет её и отбрасывает запросы со старым timestamp (защита от повтора).
+const (
+	WebhookHeaderSignature = "X-ANPR-Signature"
+	WebhookHeaderTimestamp = "X-ANPR-Timestamp"
+	WebhookHeaderDelivery  = "X-ANPR-Delivery"
+	WebhookHeaderTopic     = "X-ANPR-Topic"
+)
+
+// webhookListTypes — типы списков, по которым можно фильтровать подписку
+var webhookListTypes = []string{"WHITELIST", "BLACKLIST"}
+
+// WebhookSubscriptionInfo — подписка для API. Secret заполняется только в ответе на создание.
+type WebhookSubscriptionInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	CameraIDs   []string  `json:"camera_ids"`
+	ListTypes   []string  `json:"list_types"`
+	MatchedSnow *bool     `json:"matched_snow,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookInput — поля подписки; nil — не менять (при создании Name и URL обязательны,
+// пустой Secret генерируется)
+type WebhookInput struct {
+	Name      *string
+	URL       *string
+	Secret    *string
+	CameraIDs *[]string
+	ListTypes *[]string
+	// MatchedSnow — фильтр по matched_snow; ClearMatchedSnow снимает фильтр
+	MatchedSnow      *bool
+	ClearMatchedSnow bool
+	Active           *bool
+}
+
+// WebhookDeliveryInfo — строка журнала доставок для API
+type WebhookDeliveryInfo struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"event_id"`
+	Topic          string     `json:"topic"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// webhookEnvelope — тело запроса к подписчику
+type webhookEnvelope struct {
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// ListWebhooks возвращает все подписки (без секретов)
+func (s *ANPRService) ListWebhooks(ctx context.Context) ([]WebhookSubscriptionInfo, error) {
+	subs, err := s.repo.ListWebhookSubscriptions(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]WebhookSubscriptionInfo, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, toWebhookSubscriptionInfo(sub))
+	}
+	return result, nil
+}
+
+// CreateWebhook регистрирует подписку. Секрет возвращается только в этом ответе.
+func (s *ANPRService) CreateWebhook(ctx context.Context, input WebhookInput) (*WebhookSubscriptionInfo, error) {
+	if input.Name == nil || input.URL == nil {
+		return nil, fmt.Errorf("%w: name and url are required", ErrInvalidInput)
+	}
+	sub := &repository.WebhookSubscription{Active: true}
+	if err := applyWebhookInput(sub, input); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+
+	if err := s.repo.CreateWebhookSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("webhook_id", sub.ID.String()).Str("url", sub.URL).Msg("webhook subscription created")
+
+	info := toWebhookSubscriptionInfo(*sub)
+	info.Secret = sub.Secret
+	return &info, nil
+}
+
+// UpdateWebhook изменяет подписку
+func (s *ANPRService) UpdateWebhook(ctx context.Context, id uuid.UUID, input WebhookInput) (*WebhookSubscriptionInfo, error) {
+	sub, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWebhookInput(sub, input); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		return nil, fmt.Errorf("%w: secret cannot be empty", ErrInvalidInput)
+	}
+
+	if err := s.repo.UpdateWebhookSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.logger(ctx).Info().Str("webhook_id", sub.ID.String()).Msg("webhook subscription updated")
+
+	info := toWebhookSubscriptionInfo(*sub)
+	return &info, nil
+}
+
+// DeleteWebhook удаляет подписку и её журнал доставок
+func (s *ANPRService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: webhook subscription not found", ErrNotFound)
+	}
+	s.logger(ctx).Info().Str("webhook_id", id.String()).Msg("webhook subscription deleted")
+	return nil
+}
+
+// ListWebhookDeliveries возвращает журнал доставок подписки
+func (s *ANPRService) ListWebhookDeliveries(ctx context.Context, id uuid.UUID, status string, limit, offset int) ([]WebhookDeliveryInfo, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && status != repository.WebhookStatusPending && status != repository.WebhookStatusDelivered && status != repository.WebhookStatusFailed {
+		return nil, fmt.Errorf("%w: status must be pending, delivered or failed", ErrInvalidInput)
+	}
+	if _, err := s.repo.GetWebhookSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := s.repo.ListWebhookDeliveries(ctx, id, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]WebhookDeliveryInfo, 0, len(deliveries))
+	for _, d := range deliveries {
+		info := WebhookDeliveryInfo{
+			ID:             d.ID.String(),
+			EventID:        d.EventID.String(),
+			Topic:          d.Topic,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		}
+		if d.Status == repository.WebhookStatusPending {
+			next := d.NextAttemptAt
+			info.NextAttemptAt = &next
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// RetryWebhookDelivery возвращает доставку (обычно failed) в очередь с новым счётчиком попыток
+func (s *ANPRService) RetryWebhookDelivery(ctx context.Context, id, deliveryID uuid.UUID) error {
+	ok, err := s.repo.RetryWebhookDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: webhook delivery not found", ErrNotFound)
+	}
+	return nil
+}
+
+// EnqueueWebhooks — обработчик топика eventbus.TopicEventCreated: ставит событие в очередь доставки
+// всем активным подпискам, чьи фильтры ему соответствуют
+func (s *ANPRService) EnqueueWebhooks(ctx context.Context, topic string, payload []byte) error {
+	var msg EventCreatedMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return fmt.Errorf("decode %s message: %w", topic, err)
+	}
+
+	subs, err := s.repo.ListWebhookSubscriptions(ctx, true)
+	if err != nil {
+		return err
+	}
+	var matched []repository.WebhookSubscription
+	for _, sub := range subs {
+		if webhookMatches(sub, msg) {
+			matched = append(matched, sub)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookEnvelope{Topic: topic, Data: payload})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	deliveries := make([]repository.WebhookDelivery, 0, len(matched))
+	for _, sub := range matched {
+		deliveries = append(deliveries, repository.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        msg.EventID,
+			Topic:          topic,
+			Payload:        body,
+		})
+	}
+	return s.repo.EnqueueWebhookDeliveries(ctx, deliveries)
+}
+
+// webhookMatches проверяет фильтры подписки: камера, тип списка номера и matched_snow
+func webhookMatches(sub repository.WebhookSubscription, msg EventCreatedMessage) bool {
+	if len(sub.CameraIDs) > 0 && !slices.Contains(sub.CameraIDs, msg.CameraID) {
+		return false
+	}
+	if sub.MatchedSnow != nil && *sub.MatchedSnow != msg.MatchedSnow {
+		return false
+	}
+	if len(sub.ListTypes) > 0 {
+		for _, hit := range msg.Lists {
+			if slices.Contains(sub.ListTypes, strings.ToUpper(hit.ListType)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// RunWebhookDelivery с периодом interval отправляет доставки, которым пора уйти. Блокируется до отмены ctx.
+func (s *ANPRService) RunWebhookDelivery(ctx context.Context, interval time.Duration) {
+	if !s.Config().Webhooks.Enabled || interval <= 0 {
+		return
+	}
+	client := &http.Client{Timeout: s.Config().Webhooks.Timeout}
+	s.runRelay(ctx, "webhooks", interval, s.Config().Webhooks.BatchSize, func(ctx context.Context) (int, error) {
+		return s.deliverWebhooks(ctx, client)
+	})
+}
+
+// deliverWebhooks отправляет одну пачку доставок и возвращает её размер
+func (s *ANPRService) deliverWebhooks(ctx context.Context, client *http.Client) (int, error) {
+	// Lease с запасом на таймаут запроса: строка не уйдёт второй раз, пока идёт отправка
+	deliveries, err := s.repo.ClaimWebhookDeliveries(ctx, s.Config().Webhooks.BatchSize, 2*s.Config().Webhooks.Timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := map[uuid.UUID]*repository.WebhookSubscription{}
+	for _, d := range deliveries {
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			sub, err = s.repo.GetWebhookSubscription(ctx, d.SubscriptionID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return 0, err
+			}
+			subs[d.SubscriptionID] = sub
+		}
+		if sub == nil || !sub.Active {
+			// Подписку отключили после постановки в очередь
+			s.recordWebhookFailure(ctx, d, nil, "subscription is inactive", false)
+			continue
+		}
+
+		statusCode, err := s.sendWebhook(ctx, client, *sub, d)
+		if err == nil {
+			if err := s.repo.MarkWebhookDelivered(ctx, d.ID, statusCode); err != nil {
+				s.logger(ctx).Warn().Err(err).Str("delivery_id", d.ID.String()).Msg("failed to mark webhook delivered")
+			}
+			continue
+		}
+		var code *int
+		if statusCode != 0 {
+			code = &statusCode
+		}
+		s.recordWebhookFailure(ctx, d, code, err.Error(), true)
+	}
+	return len(deliveries), nil
+}
+
+func (s *ANPRService) recordWebhookFailure(ctx context.Context, d repository.WebhookDelivery, statusCode *int, errMsg string, retry bool) {
+	var next *time.Time
+	attempt := d.Attempts + 1
+	if retry {
+		next = s.webhookRetry().next(s.clock.Now(), attempt)
+	}
+	if err := s.repo.MarkWebhookAttemptFailed(ctx, d.ID, statusCode, errMsg, next); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("delivery_id", d.ID.String()).Msg("failed to record webhook attempt")
+		return
+	}
+
+	event := s.logger(ctx).Warn().
+		Str("delivery_id", d.ID.String()).
+		Str("webhook_id", d.SubscriptionID.String()).
+		Int("attempt", attempt).
+		Str("error", errMsg)
+	if next == nil {
+		event.Msg("webhook delivery failed permanently")
+		return
+	}
+	event.Time("next_attempt_at", *next).Msg("webhook delivery failed, will retry")
+}
+
+// sendWebhook отправляет доставку подписчику. Успехом считается любой ответ 2xx.
+func (s *ANPRService) sendWebhook(ctx context.Context, client *http.Client, sub repository.WebhookSubscription, d repository.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(s.clock.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "anpr-service-webhooks")
+	req.Header.Set(WebhookHeaderTopic, d.Topic)
+	req.Header.Set(WebhookHeaderDelivery, d.ID.String())
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhookPayload(sub.Secret, timestamp, d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload вычисляет подпись тела запроса вебхука (hex HMAC-SHA256 от "<timestamp>.<body>")
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ANPRService) webhookRetry() relayRetry {
+	return relayRetry{
+		MaxAttempts: s.Config().Webhooks.MaxAttempts,
+		BackoffBase: s.Config().Webhooks.BackoffBase,
+		BackoffMax:  s.Config().Webhooks.BackoffMax,
+	}
+}
+
+func applyWebhookInput(sub *repository.WebhookSubscription, input WebhookInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name cannot be empty", ErrInvalidInput)
+		}
+		sub.Name = name
+	}
+	if input.URL != nil {
+		u, err := url.Parse(strings.TrimSpace(*input.URL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidInput)
+		}
+		sub.URL = u.String()
+	}
+	if input.Secret != nil {
+		sub.Secret = strings.TrimSpace(*input.Secret)
+	}
+	if input.CameraIDs != nil {
+		var ids []string
+		for _, id := range *input.CameraIDs {
+			if id = strings.TrimSpace(id); id != "" && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		sub.CameraIDs = ids
+	}
+	if input.ListTypes != nil {
+		var types []string
+		for _, t := range *input.ListTypes {
+			t = strings.ToUpper(strings.TrimSpace(t))
+			if !slices.Contains(webhookListTypes, t) {
+				return fmt.Errorf("%w: list_types must contain only %s", ErrInvalidInput, strings.Join(webhookListTypes, ", "))
+			}
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+		sub.ListTypes = types
+	}
+	if input.ClearMatchedSnow {
+		sub.MatchedSnow = nil
+	} else if input.MatchedSnow != nil {
+		matched := *input.MatchedSnow
+		sub.MatchedSnow = &matched
+	}
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toWebhookSubscriptionInfo(sub repository.WebhookSubscription) WebhookSubscriptionInfo {
+	cameraIDs := []string(sub.CameraIDs)
+	if cameraIDs == nil {
+		cameraIDs = []string{}
+	}
+	listTypes := []string(sub.ListTypes)
+	if listTypes == nil {
+		listTypes = []string{}
+	}
+	return WebhookSubscriptionInfo{
+		ID:          sub.ID.String(),
+		Name:        sub.Name,
+		URL:         sub.URL,
+		CameraIDs:   cameraIDs,
+		ListTypes:   listTypes,
+		MatchedSnow: sub.MatchedSnow,
+		Active:      sub.Active,
+		CreatedAt:   sub.CreatedAt,
+		UpdatedAt:   sub.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+func TestWebhookMatches(t *testing.T) {
+	yes, no := true, false
+	msg := EventCreatedMessage{
+		CameraID:    "cam-1",
+		MatchedSnow: true,
+		Lists:       []anpr.ListHit{{ListType: "BLACKLIST"}},
+	}
+
+	tests := []struct {
+		name string
+		sub  repository.WebhookSubscription
+		want bool
+	}{
+		{"no filters", repository.WebhookSubscription{}, true},
+		{"camera match", repository.WebhookSubscription{CameraIDs: []string{"cam-2", "cam-1"}}, true},
+		{"camera mismatch", repository.WebhookSubscription{CameraIDs: []string{"cam-2"}}, false},
+		{"list type match", repository.WebhookSubscription{ListTypes: []string{"BLACKLIST"}}, true},
+		{"list type mismatch", repository.WebhookSubscription{ListTypes: []string{"WHITELIST"}}, false},
+		{"matched snow", repository.WebhookSubscription{MatchedSnow: &yes}, true},
+		{"not matched snow", repository.WebhookSubscription{MatchedSnow: &no}, false},
+		{"all filters", repository.WebhookSubscription{CameraIDs: []string{"cam-1"}, ListTypes: []string{"BLACKLIST"}, MatchedSnow: &yes}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookMatches(tt.sub, msg); got != tt.want {
+				t.Errorf("webhookMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendWebhookSignsBody(t *testing.T) {
+	svc, _ := newTestService(t, nil)
+	body := []byte(`{"topic":"anpr.event.created","data":{}}`)
+
+	var gotSignature, gotTimestamp string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(WebhookHeaderSignature)
+		gotTimestamp = r.Header.Get(WebhookHeaderTimestamp)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sub := repository.WebhookSubscription{URL: srv.URL, Secret: "s3cret"}
+	code, err := svc.sendWebhook(context.Background(), srv.Client(), sub, repository.WebhookDelivery{ID: uuid.New(), Payload: body})
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("sendWebhook() = %d, %v", code, err)
+	}
+	if gotTimestamp != "1736978400" {
+		t.Errorf("timestamp = %q, want clock time", gotTimestamp)
+	}
+	if want := "sha256=" + SignWebhookPayload("s3cret", gotTimestamp, gotBody); gotSignature != want {
+		t.Errorf("signature = %q, want %q", gotSignature, want)
+	}
+}
+
+func TestDeliverWebhooksSchedulesRetry(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Webhooks = config.WebhookConfig{Enabled: true, Timeout: time.Second, MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour, BatchSize: 10}
+	svc, store := newTestService(t, cfg)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sub := &repository.WebhookSubscription{ID: uuid.New(), URL: srv.URL, Secret: "s", Active: true}
+	retry := repository.WebhookDelivery{ID: uuid.New(), SubscriptionID: sub.ID, Attempts: 1, Payload: datatypes.JSON(`{}`)}
+	last := repository.WebhookDelivery{ID: uuid.New(), SubscriptionID: sub.ID, Attempts: 2, Payload: datatypes.JSON(`{}`)}
+
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), 10, gomock.Any()).Return([]repository.WebhookDelivery{retry, last}, nil)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), sub.ID).Return(sub, nil)
+	// Вторая попытка: пауза base·2
+	store.EXPECT().MarkWebhookAttemptFailed(gomock.Any(), retry.ID, gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, code *int, _ string, next *time.Time) error {
+			if code == nil || *code != http.StatusBadGateway {
+				t.Errorf("status code = %v, want 502", code)
+			}
+			if next == nil || !next.Equal(testNow.Add(2*time.Minute)) {
+				t.Errorf("next attempt = %v, want %v", next, testNow.Add(2*time.Minute))
+			}
+			return nil
+		})
+	// Третья попытка из трёх: доставка становится failed
+	store.EXPECT().MarkWebhookAttemptFailed(gomock.Any(), last.ID, gomock.Any(), gomock.Any(), gomock.Nil()).Return(nil)
+
+	n, err := svc.deliverWebhooks(context.Background(), srv.Client())
+	if err != nil || n != 2 {
+		t.Fatalf("deliverWebhooks() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"anpr-service/internal/hikvision"
+)
+
+// defaultWhitelistName — список, который выгружается в камеры
+const defaultWhitelistName = "default_whitelist"
+
+// ErrCameraUnavailable — камера не ответила или вернула ошибку ISAPI
+var ErrCameraUnavailable = errors.New("camera unavailable")
+
+// WhitelistSyncResult — итог выгрузки белого списка в камеру
+type WhitelistSyncResult struct {
+	CameraID string    `json:"camera_id"`
+	Total    int       `json:"total"`
+	Added    int       `json:"added"`
+	Removed  int       `json:"removed"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// SyncCameraWhitelist приводит бортовой белый список камеры к default_whitelist: добавляет недостающие
+// номера и удаляет устаревшие. Так шлагбаум пропускает машины, даже если сервис недоступен.
+func (s *ANPRService) SyncCameraWhitelist(ctx context.Context, cameraID string) (*WhitelistSyncResult, error) {
+	cameraID = strings.TrimSpace(cameraID)
+	host, err := s.CameraHTTPHost(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := hikvision.NewClient(host, s.Config().Camera.Username, s.Config().Camera.Password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	// Выгрузка в камеру — точка сверки со списками в БД, заодно сбрасываем кэш членства
+	s.InvalidateListCache()
+
+	desired, err := s.repo.ListPlatesInList(ctx, defaultWhitelistName)
+	if err != nil {
+		return nil, err
+	}
+	onCamera, err := client.ListAllowListPlates(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCameraUnavailable, err)
+	}
+
+	toAdd, toRemove := diffWhitelist(desired, onCamera)
+	if err := client.DeleteAllowListPlates(ctx, "", toRemove); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCameraUnavailable, err)
+	}
+	if err := client.AddAllowListPlates(ctx, "", toAdd); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCameraUnavailable, err)
+	}
+
+	syncedAt := s.clock.Now()
+	if err := s.repo.MarkCameraWhitelistSynced(ctx, cameraID, syncedAt); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("camera_id", cameraID).Msg("failed to record whitelist sync time")
+	}
+
+	s.logger(ctx).Info().
+		Str("camera_id", cameraID).
+		Int("total", len(desired)).
+		Int("added", len(toAdd)).
+		Int("removed", len(toRemove)).
+		Msg("camera whitelist synced")
+
+	return &WhitelistSyncResult{
+		CameraID: cameraID,
+		Total:    len(desired),
+		Added:    len(toAdd),
+		Removed:  len(toRemove),
+		SyncedAt: syncedAt,
+	}, nil
+}
+
+// SyncAllCameraWhitelists выгружает белый список во все камеры с whitelist_sync=true. Сбой одной камеры
+// не останавливает выгрузку в остальные; ошибка возвращается, если не удалось выгрузить хотя бы в одну.
+func (s *ANPRService) SyncAllCameraWhitelists(ctx context.Context) error {
+	cameras, err := s.repo.ListWhitelistSyncCameras(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list cameras for whitelist sync: %w", err)
+	}
+	failed := 0
+	for _, camera := range cameras {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.SyncCameraWhitelist(ctx, camera.ID); err != nil {
+			failed++
+			s.logger(ctx).Warn().Err(err).Str("camera_id", camera.ID).Msg("failed to sync camera whitelist")
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("whitelist sync failed for %d of %d cameras", failed, len(cameras))
+	}
+	return nil
+}
+
+// diffWhitelist сравнивает желаемый список с бортовым списком камеры: возвращает номера для добавления
+// и id записей белого списка камеры, которых нет в desired. Чёрный список камеры не трогаем.
+func diffWhitelist(desired []string, onCamera []hikvision.ListedPlate) ([]string, []string) {
+	want := make(map[string]struct{}, len(desired))
+	for _, plate := range desired {
+		want[plate] = struct{}{}
+	}
+
+	present := make(map[string]struct{}, len(onCamera))
+	var toRemove []string
+	for _, entry := range onCamera {
+		if !strings.EqualFold(entry.ListType, "whiteList") {
+			continue
+		}
+		plate := strings.ToUpper(strings.TrimSpace(entry.Plate))
+		if _, ok := want[plate]; !ok {
+			toRemove = append(toRemove, entry.ID)
+			continue
+		}
+		if _, dup := present[plate]; dup {
+			// Дубликат на камере — оставляем одну запись
+			toRemove = append(toRemove, entry.ID)
+			continue
+		}
+		present[plate] = struct{}{}
+	}
+
+	var toAdd []string
+	for _, plate := range desired {
+		if _, ok := present[plate]; !ok {
+			toAdd = append(toAdd, plate)
+			present[plate] = struct{}{}
+		}
+	}
+	return toAdd, toRemove
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"anpr-service/internal/hikvision"
+)
+
+func TestDiffWhitelist(t *testing.T) {
+	desired := []string{"123ABC02", "456DEF02", "789GHI02"}
+	onCamera := []hikvision.ListedPlate{
+		{ID: "1", Plate: "123ABC02", ListType: "whiteList"},
+		{ID: "2", Plate: "000OLD02", ListType: "whiteList"},
+		{ID: "3", Plate: "123ABC02", ListType: "whiteList"},
+		{ID: "4", Plate: "666BAD02", ListType: "blackList"},
+		{ID: "5", Plate: "456def02", ListType: "whiteList"},
+	}
+
+	toAdd, toRemove := diffWhitelist(desired, onCamera)
+
+	if want := []string{"789GHI02"}; !reflect.DeepEqual(toAdd, want) {
+		t.Errorf("toAdd = %v, want %v", toAdd, want)
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(toRemove, want) {
+		t.Errorf("toRemove = %v, want %v", toRemove, want)
+	}
+}
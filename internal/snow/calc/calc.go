@@ -0,0 +1,118 @@
+// Package calc — единые правила расчёта объёма снега: перевод процента заполнения кузова в кубометры,
+// округление и сложение объёмов. Приём событий, отчёты и биллинг считают объём только через этот пакет,
+// чтобы итоги сходились с актами приёмки, которые ведутся в кубометрах с точностью до сотых.
+package calc
+
+import "math"
+
+const (
+	// VolumeDecimals — знаков после запятой в объёме, м³ (как в актах приёмки)
+	VolumeDecimals = 2
+	// Tolerance — расхождение объёмов, м³, в пределах которого они считаются равными (единица округления)
+	Tolerance = 0.01
+)
+
+// Источник объёма события (Volume.Source)
+const (
+	SourcePercentage = "percentage" // процент заполнения × объём кузова из справочника
+	SourceAnalyzer   = "analyzer"   // кубометры, присланные анализатором
+)
+
+// Reading — показания анализатора снега: процент заполнения кузова и/или объём в м³ (nil — не прислано)
+type Reading struct {
+	Percentage *float64
+	VolumeM3   *float64
+}
+
+// Volume — объём снега события после применения правил
+type Volume struct {
+	// Percentage — процент заполнения кузова (0–100)
+	Percentage float64
+	// M3 — объём, округлённый до VolumeDecimals; nil — объём не определить (нет объёма кузова и м³ от анализатора)
+	M3 *float64
+	// Source — SourcePercentage или SourceAnalyzer; пусто, если M3 == nil
+	Source string
+	// Mismatch — анализатор прислал и процент, и кубометры, и они расходятся больше Tolerance
+	Mismatch bool
+}
+
+// Resolve определяет объём снега события. Процент заполнения приоритетнее кубометров анализатора: объём
+// кузова берётся из справочника транспорта и одинаков для всех анализаторов. Кубометры анализатора
+// используются, если процента нет (или он нулевой) либо объём кузова неизвестен (bodyVolumeM3 <= 0);
+// процент тогда выводится из них.
+func Resolve(reading Reading, bodyVolumeM3 float64) Volume {
+	var volume Volume
+	if reading.Percentage != nil {
+		volume.Percentage = clampPercentage(*reading.Percentage)
+	}
+	reported := reading.VolumeM3 != nil && *reading.VolumeM3 > 0
+
+	switch {
+	case bodyVolumeM3 > 0 && volume.Percentage > 0:
+		m3, _ := FromPercentage(volume.Percentage, bodyVolumeM3)
+		volume.M3, volume.Source = &m3, SourcePercentage
+		volume.Mismatch = reported && !Equal(m3, *reading.VolumeM3)
+	case reported:
+		m3 := Round(*reading.VolumeM3)
+		volume.M3, volume.Source = &m3, SourceAnalyzer
+		if percentage, ok := ToPercentage(m3, bodyVolumeM3); ok {
+			volume.Percentage = percentage
+		}
+	case bodyVolumeM3 > 0 && reading.Percentage != nil:
+		// Пустой кузов: объём известен и равен нулю
+		m3 := 0.0
+		volume.M3, volume.Source = &m3, SourcePercentage
+	}
+	return volume
+}
+
+// FromPercentage переводит процент заполнения кузова в кубометры. ok == false, если объём кузова неизвестен.
+func FromPercentage(percentage, bodyVolumeM3 float64) (float64, bool) {
+	if bodyVolumeM3 <= 0 {
+		return 0, false
+	}
+	return Round(clampPercentage(percentage) / 100 * bodyVolumeM3), true
+}
+
+// ToPercentage переводит кубометры в процент заполнения кузова (с точностью до сотых процента, не больше 100).
+// ok == false, если объём кузова неизвестен.
+func ToPercentage(m3, bodyVolumeM3 float64) (float64, bool) {
+	if bodyVolumeM3 <= 0 {
+		return 0, false
+	}
+	return Round(clampPercentage(m3 / bodyVolumeM3 * 100)), true
+}
+
+// Trips — объём за trips рейсов машины с кузовом bodyVolumeM3 (оплата по полному кузову)
+func Trips(trips int64, bodyVolumeM3 float64) float64 {
+	return Round(float64(trips) * bodyVolumeM3)
+}
+
+// Add прибавляет объём к итогу. Каждое слагаемое и итог округляются, поэтому итог не зависит от порядка
+// сложения и совпадает с суммой строк акта.
+func Add(total, m3 float64) float64 {
+	return Round(Round(total) + Round(m3))
+}
+
+// Round округляет объём до VolumeDecimals, половину — от нуля. Двоичная погрешность float64 сначала
+// отбрасывается, чтобы 1.005 округлялось до 1.01, как в акте, а не до 1.00.
+func Round(m3 float64) float64 {
+	scale := math.Pow10(VolumeDecimals)
+	return math.Round(math.Round(m3*scale*1e4)/1e4) / scale
+}
+
+// Equal — объёмы совпадают с точностью до Tolerance
+func Equal(a, b float64) bool {
+	return math.Abs(Round(a)-Round(b)) <= Tolerance+1e-9
+}
+
+func clampPercentage(percentage float64) float64 {
+	switch {
+	case math.IsNaN(percentage) || percentage < 0:
+		return 0
+	case percentage > 100:
+		return 100
+	default:
+		return percentage
+	}
+}
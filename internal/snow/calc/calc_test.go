@@ -0,0 +1,74 @@
+package calc
+
+import "testing"
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		reading    Reading
+		body       float64
+		wantPct    float64
+		wantM3     *float64
+		wantSource string
+		mismatch   bool
+	}{
+		{name: "percentage", reading: Reading{Percentage: ptr(75.5)}, body: 18, wantPct: 75.5, wantM3: ptr(13.59), wantSource: SourcePercentage},
+		{name: "percentage over 100", reading: Reading{Percentage: ptr(300)}, body: 18, wantPct: 100, wantM3: ptr(18), wantSource: SourcePercentage},
+		{name: "empty body", reading: Reading{Percentage: ptr(0)}, body: 18, wantM3: ptr(0), wantSource: SourcePercentage},
+		{name: "percentage without body volume", reading: Reading{Percentage: ptr(50)}, wantPct: 50},
+		{name: "analyzer m3", reading: Reading{Percentage: ptr(0), VolumeM3: ptr(9.005)}, body: 18, wantPct: 50.06, wantM3: ptr(9.01), wantSource: SourceAnalyzer},
+		{name: "analyzer m3 without body volume", reading: Reading{VolumeM3: ptr(12.345)}, wantM3: ptr(12.35), wantSource: SourceAnalyzer},
+		{name: "analyzer agrees", reading: Reading{Percentage: ptr(50), VolumeM3: ptr(9.01)}, body: 18, wantPct: 50, wantM3: ptr(9), wantSource: SourcePercentage},
+		{name: "analyzer disagrees", reading: Reading{Percentage: ptr(50), VolumeM3: ptr(12)}, body: 18, wantPct: 50, wantM3: ptr(9), wantSource: SourcePercentage, mismatch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(tt.reading, tt.body)
+			if got.Percentage != tt.wantPct || got.Source != tt.wantSource || got.Mismatch != tt.mismatch {
+				t.Fatalf("Resolve() = %+v", got)
+			}
+			if (got.M3 == nil) != (tt.wantM3 == nil) || (got.M3 != nil && *got.M3 != *tt.wantM3) {
+				t.Fatalf("Resolve() m3 = %v, want %v", got.M3, tt.wantM3)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{1.005, 1.01},
+		{2.675, 2.68},
+		{0.125, 0.13},
+		{-1.005, -1.01},
+		{13.594999, 13.59},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.in); got != tt.want {
+			t.Errorf("Round(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddMatchesActRows(t *testing.T) {
+	// Строки акта: объём каждого рейса округлён до сотых, итог — их сумма
+	rows := []float64{13.594, 13.594, 13.594, 0.1, 0.2}
+	var total float64
+	for _, m3 := range rows {
+		total = Add(total, m3)
+	}
+	if total != 41.07 {
+		t.Fatalf("total = %v, want 41.07", total)
+	}
+	if got := Trips(3, 12.345); got != 37.04 {
+		t.Fatalf("Trips() = %v, want 37.04", got)
+	}
+	if !Equal(9, 9.01) || Equal(9, 9.02) {
+		t.Fatal("Equal() does not respect Tolerance")
+	}
+}
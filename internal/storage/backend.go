@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Backend — хранилище объектов: бакет S3-совместимого хранилища или каталог на диске
+type Backend interface {
+	Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error)
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, key string) error
+	Ping(ctx context.Context) error
+	URL(key string) string
+}
+
+// Основные хранилища фото (STORAGE_BACKEND)
+const (
+	BackendR2    = "r2"
+	BackendS3    = "s3"
+	BackendMinIO = "minio"
+	BackendLocal = "local"
+)
+
+// BackendFromEnv возвращает выбранное в STORAGE_BACKEND хранилище (по умолчанию r2)
+func BackendFromEnv() string {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND")))
+	if backend == "" {
+		return BackendR2
+	}
+	return backend
+}
+
+// NewPrimaryFromEnv создаёт основное хранилище фото по STORAGE_BACKEND. Возвращает ErrNotConfigured,
+// если для выбранного хранилища не заданы параметры.
+func NewPrimaryFromEnv() (Backend, error) {
+	switch backend := BackendFromEnv(); backend {
+	case BackendR2:
+		return asBackend(NewR2ClientFromEnv())
+	case BackendS3, BackendMinIO:
+		return asBackend(NewS3ClientFromEnv(backend))
+	case BackendLocal:
+		return asBackend(NewLocalStoreFromEnv())
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND must be one of %q, %q, %q, %q", BackendR2, BackendS3, BackendMinIO, BackendLocal)
+	}
+}
+
+// NewSecondaryFromEnv создаёт резервное хранилище: каталог STORAGE_SECONDARY_DIR или резервный бакет
+// основного хранилища (R2_SECONDARY_BUCKET, S3_SECONDARY_BUCKET). Возвращает ErrNotConfigured, если резерв не задан.
+func NewSecondaryFromEnv() (Backend, error) {
+	if dir := strings.TrimSpace(os.Getenv("STORAGE_SECONDARY_DIR")); dir != "" {
+		return asBackend(NewLocalStore(dir, ""))
+	}
+	switch backend := BackendFromEnv(); backend {
+	case BackendR2:
+		return asBackend(NewSecondaryR2ClientFromEnv())
+	case BackendS3, BackendMinIO:
+		return asBackend(NewSecondaryS3ClientFromEnv(backend))
+	default:
+		return nil, ErrNotConfigured
+	}
+}
+
+// NewDataLakeFromEnv создаёт клиент бакета выгрузки для аналитиков DATALAKE_BUCKET с учётными данными
+// основного хранилища (R2 или S3/MinIO). Возвращает ErrNotConfigured, если бакет не задан — тогда выгрузка
+// пишется в бакет фото.
+func NewDataLakeFromEnv() (Backend, error) {
+	bucket := strings.TrimSpace(os.Getenv("DATALAKE_BUCKET"))
+	if bucket == "" {
+		return nil, ErrNotConfigured
+	}
+	switch backend := BackendFromEnv(); backend {
+	case BackendR2:
+		return asBackend(newR2ClientFromEnv(bucket))
+	case BackendS3, BackendMinIO:
+		return asBackend(newS3ClientFromEnv(backend, bucket))
+	default:
+		return nil, fmt.Errorf("DATALAKE_BUCKET requires STORAGE_BACKEND %q, %q or %q", BackendR2, BackendS3, BackendMinIO)
+	}
+}
+
+// asBackend не даёт типизированному nil-указателю превратиться в ненулевой интерфейс
+func asBackend[T Backend](store T, err error) (Backend, error) {
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewPrimaryFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+		wantURL string
+	}{
+		{
+			name:    "r2 by default",
+			env:     map[string]string{"R2_ENDPOINT": "https://account.r2.example", "R2_ACCESS_KEY_ID": "key", "R2_SECRET_ACCESS_KEY": "secret", "R2_BUCKET": "photos"},
+			wantURL: "https://account.r2.example/photos/a/b.jpg",
+		},
+		{
+			name:    "r2 not configured",
+			env:     map[string]string{},
+			wantErr: ErrNotConfigured,
+		},
+		{
+			name:    "amazon s3 without endpoint",
+			env:     map[string]string{"STORAGE_BACKEND": "s3", "S3_ACCESS_KEY_ID": "key", "S3_SECRET_ACCESS_KEY": "secret", "S3_BUCKET": "photos", "S3_REGION": "eu-central-1"},
+			wantURL: "https://photos.s3.eu-central-1.amazonaws.com/a/b.jpg",
+		},
+		{
+			name:    "minio requires endpoint",
+			env:     map[string]string{"STORAGE_BACKEND": "minio", "S3_ACCESS_KEY_ID": "key", "S3_SECRET_ACCESS_KEY": "secret", "S3_BUCKET": "photos"},
+			wantErr: ErrNotConfigured,
+		},
+		{
+			name:    "minio behind public url",
+			env:     map[string]string{"STORAGE_BACKEND": "MinIO", "S3_ENDPOINT": "http://minio:9000", "S3_ACCESS_KEY_ID": "key", "S3_SECRET_ACCESS_KEY": "secret", "S3_BUCKET": "photos", "S3_PUBLIC_BASE_URL": "https://cdn.example/"},
+			wantURL: "https://cdn.example/photos/a/b.jpg",
+		},
+		{
+			name:    "local served by the service",
+			env:     map[string]string{"STORAGE_BACKEND": "local", "STORAGE_LOCAL_PUBLIC_BASE_URL": "http://anpr.local/api/v1/photos/"},
+			wantURL: "http://anpr.local/api/v1/photos/a/b.jpg",
+		},
+		{
+			name:    "unknown backend",
+			env:     map[string]string{"STORAGE_BACKEND": "ftp"},
+			wantErr: errors.New("any"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"STORAGE_BACKEND", "STORAGE_LOCAL_DIR", "STORAGE_LOCAL_PUBLIC_BASE_URL",
+				"R2_ENDPOINT", "R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY", "R2_BUCKET", "R2_PUBLIC_BASE_URL",
+				"S3_ENDPOINT", "S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_BUCKET", "S3_REGION", "S3_PUBLIC_BASE_URL"} {
+				t.Setenv(key, "")
+			}
+			if tt.env["STORAGE_BACKEND"] == "local" {
+				t.Setenv("STORAGE_LOCAL_DIR", t.TempDir())
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			backend, err := NewPrimaryFromEnv()
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if errors.Is(tt.wantErr, ErrNotConfigured) && !errors.Is(err, ErrNotConfigured) {
+					t.Errorf("err = %v, want ErrNotConfigured", err)
+				}
+				if backend != nil {
+					t.Errorf("backend = %T, want nil", backend)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := backend.URL("a/b.jpg"); got != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/secrets"
+)
+
+// CheckEnv проверяет настройки хранилища фото без подключения к нему и возвращает все найденные ошибки.
+// Хранилище может быть не настроено совсем (фото тогда не сохраняются), но не наполовину.
+func CheckEnv() []string {
+	var problems []string
+	backend := BackendFromEnv()
+	var required []string
+	switch backend {
+	case BackendR2:
+		required = []string{"R2_ENDPOINT", "R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY", "R2_BUCKET"}
+	case BackendS3:
+		required = []string{"S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_BUCKET"}
+	case BackendMinIO:
+		required = []string{"S3_ENDPOINT", "S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_BUCKET"}
+	case BackendLocal:
+		if strings.TrimSpace(os.Getenv("STORAGE_LOCAL_DIR")) == "" {
+			problems = append(problems, "STORAGE_LOCAL_DIR is required for STORAGE_BACKEND=local")
+		}
+	default:
+		return []string{fmt.Sprintf("STORAGE_BACKEND must be one of %q, %q, %q, %q", BackendR2, BackendS3, BackendMinIO, BackendLocal)}
+	}
+
+	var set, missing []string
+	for _, name := range required {
+		if envSet(name) {
+			set = append(set, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(set) > 0 && len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("%s storage is partially configured, missing %s", backend, strings.Join(missing, ", ")))
+	}
+
+	for _, name := range []string{"R2_ENDPOINT", "S3_ENDPOINT"} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			if err := config.CheckURL(value, "http", "https"); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is invalid: %v", name, err))
+			}
+		}
+	}
+	if backend == BackendR2 || backend == BackendS3 || backend == BackendMinIO {
+		prefix := "S3"
+		if backend == BackendR2 {
+			prefix = "R2"
+		}
+		if _, _, err := credentialsFromEnv(prefix+"_ACCESS_KEY_ID", prefix+"_SECRET_ACCESS_KEY"); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// envSet проверяет, задана ли переменная или файл её секрета (<NAME>_FILE)
+func envSet(name string) bool {
+	return strings.TrimSpace(os.Getenv(name)) != "" || strings.TrimSpace(os.Getenv(name+secrets.FileSuffix)) != ""
+}
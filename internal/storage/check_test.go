@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{name: "not configured", env: map[string]string{}},
+		{
+			name: "r2 complete",
+			env:  map[string]string{"R2_ENDPOINT": "https://account.r2.example", "R2_ACCESS_KEY_ID": "key", "R2_SECRET_ACCESS_KEY": "secret", "R2_BUCKET": "photos"},
+		},
+		{
+			name: "r2 partial",
+			env:  map[string]string{"R2_ENDPOINT": "account.r2.example", "R2_BUCKET": "photos"},
+			want: []string{"r2 storage is partially configured, missing R2_ACCESS_KEY_ID, R2_SECRET_ACCESS_KEY", "R2_ENDPOINT is invalid"},
+		},
+		{
+			name: "secret file missing",
+			env:  map[string]string{"STORAGE_BACKEND": "s3", "S3_ACCESS_KEY_ID": "key", "S3_SECRET_ACCESS_KEY_FILE": "/nonexistent/secret", "S3_BUCKET": "photos"},
+			want: []string{"S3_SECRET_ACCESS_KEY: failed to read secret file"},
+		},
+		{
+			name: "local without dir",
+			env:  map[string]string{"STORAGE_BACKEND": "local"},
+			want: []string{"STORAGE_LOCAL_DIR is required"},
+		},
+		{
+			name: "unknown backend",
+			env:  map[string]string{"STORAGE_BACKEND": "ftp"},
+			want: []string{"STORAGE_BACKEND must be one of"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"STORAGE_BACKEND", "STORAGE_LOCAL_DIR", "R2_ENDPOINT", "R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY",
+				"R2_BUCKET", "S3_ENDPOINT", "S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_SECRET_ACCESS_KEY_FILE", "S3_BUCKET"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			problems := CheckEnv()
+			if len(problems) != len(tt.want) {
+				t.Fatalf("CheckEnv() = %q, want %d problems", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(problems[i], want) {
+					t.Errorf("problem %d = %q, want prefix %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
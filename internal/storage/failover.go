@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+
+	"anpr-service/internal/tracing"
+)
+
+const (
+	defaultFailoverThreshold   = 3
+	defaultReplicationInterval = time.Minute
+)
+
+// FailoverOptions — настройки переключения на резервное хранилище
+type FailoverOptions struct {
+	// Threshold — после стольких ошибок подряд основное хранилище перестаёт опрашиваться при загрузке
+	// до восстановления (его проверяет фоновая репликация)
+	Threshold int
+	// ReplicationInterval — период проверки основного хранилища и переноса объектов из резерва
+	ReplicationInterval time.Duration
+}
+
+// FailoverOptionsFromEnv читает STORAGE_FAILOVER_THRESHOLD и STORAGE_REPLICATION_INTERVAL
+func FailoverOptionsFromEnv() FailoverOptions {
+	opts := FailoverOptions{
+		Threshold:           defaultFailoverThreshold,
+		ReplicationInterval: defaultReplicationInterval,
+	}
+	if value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("STORAGE_FAILOVER_THRESHOLD"))); err == nil && value > 0 {
+		opts.Threshold = value
+	}
+	if value, err := time.ParseDuration(strings.TrimSpace(os.Getenv("STORAGE_REPLICATION_INTERVAL"))); err == nil && value > 0 {
+		opts.ReplicationInterval = value
+	}
+	return opts
+}
+
+// FailoverStatus — состояние хранилища фотографий для health-check
+type FailoverStatus struct {
+	SecondaryConfigured bool       `json:"secondary_configured"`
+	FailoverActive      bool       `json:"failover_active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailoverSince       *time.Time `json:"failover_since,omitempty"`
+	LastReplicatedAt    *time.Time `json:"last_replicated_at,omitempty"`
+}
+
+// FailoverStore загружает объекты в основное хранилище, а при его ошибке — в резервное.
+// Объекты из резерва фоново переносятся в основное после его восстановления. Возвращаемый URL
+// всегда указывает на основное хранилище (ключ один и тот же), поэтому ссылки в БД не меняются:
+// на время сбоя фото недоступно по ссылке, но не теряется.
+type FailoverStore struct {
+	primary   Backend
+	secondary Backend
+	opts      FailoverOptions
+	log       zerolog.Logger
+
+	mu               sync.Mutex
+	failures         int
+	failoverSince    *time.Time
+	lastReplicatedAt *time.Time
+}
+
+func NewFailoverStore(primary, secondary Backend, opts FailoverOptions, log zerolog.Logger) *FailoverStore {
+	if opts.Threshold <= 0 {
+		opts.Threshold = defaultFailoverThreshold
+	}
+	if opts.ReplicationInterval <= 0 {
+		opts.ReplicationInterval = defaultReplicationInterval
+	}
+	return &FailoverStore{
+		primary:   primary,
+		secondary: secondary,
+		opts:      opts,
+		log:       log,
+	}
+}
+
+// Upload загружает объект в основное хранилище, а при его недоступности — в резервное
+func (s *FailoverStore) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if s == nil || s.primary == nil {
+		return "", ErrNotConfigured
+	}
+
+	ctx, span := tracing.Start(ctx, "storage.upload",
+		attribute.String("storage.key", key),
+		attribute.Int64("storage.size", size),
+	)
+	defer span.End()
+
+	url, backend, err := s.upload(ctx, key, body, size, contentType)
+	span.SetAttributes(attribute.String("storage.backend", backend))
+	tracing.RecordError(span, err)
+	return url, err
+}
+
+// upload выполняет загрузку и возвращает, в какое хранилище (primary/secondary) попал объект
+func (s *FailoverStore) upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, string, error) {
+	if s.secondary == nil {
+		url, err := s.primary.Upload(ctx, key, body, size, contentType)
+		return url, "primary", err
+	}
+
+	if !s.FailoverActive() {
+		rewind, err := rewindable(&body)
+		if err != nil {
+			return "", "primary", err
+		}
+		url, err := s.primary.Upload(ctx, key, body, size, contentType)
+		if err == nil {
+			s.recordSuccess()
+			return url, "primary", nil
+		}
+		if errors.Is(err, ErrNotConfigured) || ctx.Err() != nil {
+			return "", "primary", err
+		}
+		s.recordFailure(err)
+		if err := rewind(); err != nil {
+			return "", "primary", fmt.Errorf("rewind upload body: %w", err)
+		}
+	}
+
+	if _, err := s.secondary.Upload(ctx, key, body, size, contentType); err != nil {
+		return "", "secondary", fmt.Errorf("primary and secondary storage upload failed: %w", err)
+	}
+	s.log.Warn().Str("key", key).Msg("object stored in secondary storage, pending replication to primary")
+	return s.primary.URL(key), "secondary", nil
+}
+
+// Ping проверяет основное хранилище
+func (s *FailoverStore) Ping(ctx context.Context) error {
+	if s == nil || s.primary == nil {
+		return ErrNotConfigured
+	}
+	return s.primary.Ping(ctx)
+}
+
+// ServesObjects сообщает, что основное хранилище — локальный каталог и фото отдаёт сам сервис
+// (GET /api/v1/photos/*key); бакеты отдают объекты по своим ссылкам
+func (s *FailoverStore) ServesObjects() bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.primary.(*LocalStore)
+	return ok
+}
+
+// Open открывает объект основного хранилища
+func (s *FailoverStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error) {
+	if s == nil || s.primary == nil {
+		return nil, 0, "", ErrNotConfigured
+	}
+	return s.primary.Open(ctx, key)
+}
+
+// Delete удаляет объект из основного хранилища и из резервного: во время сбоя объект мог попасть туда
+func (s *FailoverStore) Delete(ctx context.Context, key string) error {
+	if s == nil || s.primary == nil {
+		return ErrNotConfigured
+	}
+	err := s.primary.Delete(ctx, key)
+	if s.secondary != nil {
+		err = errors.Join(err, s.secondary.Delete(ctx, key))
+	}
+	return err
+}
+
+// FailoverActive сообщает, что загрузки сейчас идут сразу в резервное хранилище
+func (s *FailoverStore) FailoverActive() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failoverSince != nil
+}
+
+func (s *FailoverStore) Status() FailoverStatus {
+	if s == nil {
+		return FailoverStatus{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return FailoverStatus{
+		SecondaryConfigured: s.secondary != nil,
+		FailoverActive:      s.failoverSince != nil,
+		ConsecutiveFailures: s.failures,
+		FailoverSince:       s.failoverSince,
+		LastReplicatedAt:    s.lastReplicatedAt,
+	}
+}
+
+func (s *FailoverStore) recordSuccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = 0
+	if s.failoverSince != nil {
+		s.log.Info().Msg("primary storage recovered, failover disabled")
+	}
+	s.failoverSince = nil
+}
+
+func (s *FailoverStore) recordFailure(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	s.log.Warn().Err(err).Int("consecutive_failures", s.failures).Msg("primary storage upload failed, using secondary")
+	if s.failures >= s.opts.Threshold && s.failoverSince == nil {
+		now := time.Now()
+		s.failoverSince = &now
+		s.log.Error().Int("threshold", s.opts.Threshold).Msg("primary storage failing repeatedly, switching uploads to secondary")
+	}
+}
+
+// RunReplication периодически проверяет основное хранилище и переносит в него объекты из резерва.
+// Блокируется до отмены ctx.
+func (s *FailoverStore) RunReplication(ctx context.Context) {
+	if s == nil || s.primary == nil || s.secondary == nil {
+		return
+	}
+	ticker := time.NewTicker(s.opts.ReplicationInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Replicate(ctx); err != nil && ctx.Err() == nil {
+			s.log.Warn().Err(err).Msg("storage re-replication failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replicate переносит объекты из резервного хранилища в основное, если основное доступно.
+// Возвращает число перенесённых объектов.
+func (s *FailoverStore) Replicate(ctx context.Context) (int, error) {
+	if err := s.primary.Ping(ctx); err != nil {
+		return 0, fmt.Errorf("primary storage still unavailable: %w", err)
+	}
+	s.recordSuccess()
+
+	keys, err := s.secondary.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, key := range keys {
+		if err := s.replicateObject(ctx, key); err != nil {
+			return moved, fmt.Errorf("replicate %s: %w", key, err)
+		}
+		moved++
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.lastReplicatedAt = &now
+	s.mu.Unlock()
+
+	if moved > 0 {
+		s.log.Info().Int("objects", moved).Msg("objects re-replicated from secondary to primary storage")
+	}
+	return moved, nil
+}
+
+func (s *FailoverStore) replicateObject(ctx context.Context, key string) error {
+	body, size, contentType, err := s.secondary.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if _, err := s.primary.Upload(ctx, key, body, size, contentType); err != nil {
+		return err
+	}
+	return s.secondary.Delete(ctx, key)
+}
+
+// rewindable позволяет повторно прочитать тело загрузки для записи в резерв:
+// для io.Seeker запоминает позицию, остальные потоки буферизует в памяти
+func rewindable(body *io.Reader) (func() error, error) {
+	if seeker, ok := (*body).(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			return func() error {
+				_, err := seeker.Seek(offset, io.SeekStart)
+				return err
+			}, nil
+		}
+	}
+	data, err := io.ReadAll(*body)
+	if err != nil {
+		return nil, fmt.Errorf("read upload body: %w", err)
+	}
+	reader := bytes.NewReader(data)
+	*body = reader
+	return func() error {
+		_, err := reader.Seek(0, io.SeekStart)
+		return err
+	}, nil
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// flakyBackend — основное хранилище в памяти, которое можно «уронить»
+type flakyBackend struct {
+	down    bool
+	objects map[string][]byte
+}
+
+func (b *flakyBackend) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if b.down {
+		return "", errors.New("bucket unavailable")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	b.objects[key] = data
+	return b.URL(key), nil
+}
+
+func (b *flakyBackend) Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error) {
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, 0, "", errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), "image/jpeg", nil
+}
+
+func (b *flakyBackend) List(ctx context.Context) ([]string, error) {
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (b *flakyBackend) Delete(ctx context.Context, key string) error {
+	delete(b.objects, key)
+	return nil
+}
+
+func (b *flakyBackend) Ping(ctx context.Context) error {
+	if b.down {
+		return errors.New("bucket unavailable")
+	}
+	return nil
+}
+
+func (b *flakyBackend) URL(key string) string {
+	return "https://primary.example/" + key
+}
+
+func TestFailoverStoreFallsBackAndReplicates(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyBackend{down: true, objects: map[string][]byte{}}
+	secondary, err := NewLocalStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	store := NewFailoverStore(primary, secondary, FailoverOptions{Threshold: 2}, zerolog.Nop())
+
+	keys := []string{"anpr_events/2025-01-10/cam/a.jpg", "anpr_events/2025-01-10/cam/b.jpg"}
+	for _, key := range keys {
+		// Тело без Seek проверяет буферизацию для повторной записи
+		url, err := store.Upload(ctx, key, io.MultiReader(bytes.NewReader([]byte("photo-"+key))), 6+int64(len(key)), "image/jpeg")
+		if err != nil {
+			t.Fatalf("Upload(%s): %v", key, err)
+		}
+		if url != primary.URL(key) {
+			t.Fatalf("Upload(%s) url = %s, want primary url", key, url)
+		}
+	}
+	if !store.FailoverActive() {
+		t.Fatalf("failover should be active after %d failures", len(keys))
+	}
+	if _, err := store.Replicate(ctx); err == nil {
+		t.Fatalf("Replicate should fail while primary is down")
+	}
+
+	primary.down = false
+	moved, err := store.Replicate(ctx)
+	if err != nil {
+		t.Fatalf("Replicate: %v", err)
+	}
+	if moved != len(keys) {
+		t.Fatalf("Replicate moved %d objects, want %d", moved, len(keys))
+	}
+	if store.FailoverActive() {
+		t.Fatalf("failover should be disabled after primary recovery")
+	}
+	for _, key := range keys {
+		if got := string(primary.objects[key]); got != "photo-"+key {
+			t.Fatalf("primary object %s = %q", key, got)
+		}
+	}
+	if left, _ := secondary.List(ctx); len(left) != 0 {
+		t.Fatalf("secondary still holds %v", left)
+	}
+}
+
+func TestFailoverStoreDeleteRemovesBothCopies(t *testing.T) {
+	ctx := context.Background()
+	key := "anpr_events/2025-01-10/cam/a.jpg"
+	primary := &flakyBackend{objects: map[string][]byte{key: []byte("photo")}}
+	secondary := &flakyBackend{objects: map[string][]byte{key: []byte("photo")}}
+	store := NewFailoverStore(primary, secondary, FailoverOptions{}, zerolog.Nop())
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(primary.objects) != 0 || len(secondary.objects) != 0 {
+		t.Errorf("objects left: primary %v, secondary %v", primary.objects, secondary.objects)
+	}
+}
+
+func TestLocalStoreRejectsPathEscape(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	if _, err := store.Upload(context.Background(), "../../etc/passwd", bytes.NewReader([]byte("x")), 1, "text/plain"); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	keys, _ := store.List(context.Background())
+	if len(keys) != 1 || keys[0] != "etc/passwd" {
+		t.Fatalf("keys = %v, want object kept inside root", keys)
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore — хранилище объектов на локальном диске: основное на полигонах без интернета
+// или резерв на время недоступности бакета
+type LocalStore struct {
+	root string
+	// publicBaseURL — адрес, по которому объекты отдаются клиентам; пустой — ссылки file://
+	publicBaseURL string
+}
+
+func NewLocalStore(root, publicBaseURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create local storage dir: %w", err)
+	}
+	return &LocalStore{root: root, publicBaseURL: strings.TrimRight(publicBaseURL, "/")}, nil
+}
+
+// NewLocalStoreFromEnv создаёт основное хранилище в каталоге STORAGE_LOCAL_DIR со ссылками
+// от STORAGE_LOCAL_PUBLIC_BASE_URL
+func NewLocalStoreFromEnv() (*LocalStore, error) {
+	dir := strings.TrimSpace(os.Getenv("STORAGE_LOCAL_DIR"))
+	if dir == "" {
+		return nil, ErrNotConfigured
+	}
+	return NewLocalStore(dir, strings.TrimSpace(os.Getenv("STORAGE_LOCAL_PUBLIC_BASE_URL")))
+}
+
+func (l *LocalStore) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("local storage mkdir failed: %w", err)
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы репликация не подхватила недописанный объект
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("local storage create failed: %w", err)
+	}
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("local storage write failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("local storage write failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("local storage rename failed: %w", err)
+	}
+	return l.URL(key), nil
+}
+
+func (l *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("local storage open failed: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, "", fmt.Errorf("local storage stat failed: %w", err)
+	}
+	return f, info.Size(), mime.TypeByExtension(filepath.Ext(path)), nil
+}
+
+func (l *LocalStore) List(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("local storage list failed: %w", err)
+	}
+	return keys, nil
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("local storage delete failed: %w", err)
+	}
+	return nil
+}
+
+// Ping проверяет, что каталог хранилища доступен
+func (l *LocalStore) Ping(ctx context.Context) error {
+	if _, err := os.Stat(l.root); err != nil {
+		return fmt.Errorf("local storage unavailable: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalStore) URL(key string) string {
+	if l.publicBaseURL != "" {
+		return l.publicBaseURL + "/" + strings.TrimLeft(key, "/")
+	}
+	return "file://" + filepath.ToSlash(filepath.Join(l.root, filepath.FromSlash(key)))
+}
+
+// path переводит ключ объекта в путь внутри корня хранилища, не допуская выхода за его пределы
+func (l *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + strings.TrimLeft(key, "/"))
+	if clean == "/" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"anpr-service/internal/secrets"
+	"anpr-service/internal/tracing"
+)
+
+var ErrNotConfigured = errors.New("photo storage is not configured")
+
+// S3Client — бакет S3-совместимого хранилища: Cloudflare R2, Amazon S3 или MinIO
+type S3Client struct {
+	client        *s3.Client
+	provider      string
+	bucket        string
+	endpoint      string
+	region        string
+	publicBaseURL string
+}
+
+type s3Config struct {
+	Provider      string
+	Endpoint      string
+	AccessKey     string
+	SecretKey     string
+	Bucket        string
+	Region        string
+	PublicBaseURL string
+	PathStyle     bool
+}
+
+func NewR2ClientFromEnv() (*S3Client, error) {
+	return newR2ClientFromEnv(strings.TrimSpace(os.Getenv("R2_BUCKET")))
+}
+
+// NewSecondaryR2ClientFromEnv создаёт клиент резервного бакета R2_SECONDARY_BUCKET
+// с теми же учётными данными и endpoint, что и основной
+func NewSecondaryR2ClientFromEnv() (*S3Client, error) {
+	return newR2ClientFromEnv(strings.TrimSpace(os.Getenv("R2_SECONDARY_BUCKET")))
+}
+
+func newR2ClientFromEnv(bucket string) (*S3Client, error) {
+	accessKey, secretKey, err := credentialsFromEnv("R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY")
+	if err != nil {
+		return nil, err
+	}
+	cfg := s3Config{
+		Provider:      BackendR2,
+		Endpoint:      strings.TrimSpace(os.Getenv("R2_ENDPOINT")),
+		AccessKey:     accessKey,
+		SecretKey:     secretKey,
+		Bucket:        bucket,
+		Region:        strings.TrimSpace(os.Getenv("R2_REGION")),
+		PublicBaseURL: strings.TrimRight(strings.TrimSpace(os.Getenv("R2_PUBLIC_BASE_URL")), "/"),
+		PathStyle:     true,
+	}
+	if cfg.Endpoint == "" {
+		return nil, ErrNotConfigured
+	}
+	if cfg.Region == "" {
+		cfg.Region = "auto"
+	}
+	return newS3Client(cfg)
+}
+
+// NewS3ClientFromEnv создаёт клиент Amazon S3 (provider = s3) или MinIO (provider = minio) по переменным S3_*.
+// Для MinIO обязателен S3_ENDPOINT и всегда используется path-style адресация.
+func NewS3ClientFromEnv(provider string) (*S3Client, error) {
+	return newS3ClientFromEnv(provider, strings.TrimSpace(os.Getenv("S3_BUCKET")))
+}
+
+// NewSecondaryS3ClientFromEnv создаёт клиент резервного бакета S3_SECONDARY_BUCKET
+func NewSecondaryS3ClientFromEnv(provider string) (*S3Client, error) {
+	return newS3ClientFromEnv(provider, strings.TrimSpace(os.Getenv("S3_SECONDARY_BUCKET")))
+}
+
+func newS3ClientFromEnv(provider, bucket string) (*S3Client, error) {
+	accessKey, secretKey, err := credentialsFromEnv("S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY")
+	if err != nil {
+		return nil, err
+	}
+	pathStyle, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("S3_FORCE_PATH_STYLE")))
+	cfg := s3Config{
+		Provider:      provider,
+		Endpoint:      strings.TrimSpace(os.Getenv("S3_ENDPOINT")),
+		AccessKey:     accessKey,
+		SecretKey:     secretKey,
+		Bucket:        bucket,
+		Region:        strings.TrimSpace(os.Getenv("S3_REGION")),
+		PublicBaseURL: strings.TrimRight(strings.TrimSpace(os.Getenv("S3_PUBLIC_BASE_URL")), "/"),
+		PathStyle:     pathStyle || provider == BackendMinIO,
+	}
+	if provider == BackendMinIO && cfg.Endpoint == "" {
+		return nil, ErrNotConfigured
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return newS3Client(cfg)
+}
+
+// credentialsFromEnv читает ключи доступа через провайдеры секретов: значение переменной, ссылку
+// file: или vault: либо файл из <NAME>_FILE
+func credentialsFromEnv(accessKeyVar, secretKeyVar string) (string, string, error) {
+	resolver, err := secrets.FromEnv()
+	if err != nil {
+		return "", "", err
+	}
+	accessKey, err := resolver.Lookup(context.Background(), accessKeyVar, os.Getenv)
+	if err != nil {
+		return "", "", err
+	}
+	secretKey, err := resolver.Lookup(context.Background(), secretKeyVar, os.Getenv)
+	if err != nil {
+		return "", "", err
+	}
+	return accessKey, secretKey, nil
+}
+
+func newS3Client(cfg s3Config) (*S3Client, error) {
+	if cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.Bucket == "" {
+		return nil, ErrNotConfigured
+	}
+
+	awsCfg := aws.Config{
+		Region:      cfg.Region,
+		Credentials: credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, ""),
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		// Без endpoint (Amazon S3) адрес бакета определяет SDK по региону
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.PathStyle
+	})
+
+	return &S3Client{
+		client:        client,
+		provider:      cfg.Provider,
+		bucket:        cfg.Bucket,
+		endpoint:      strings.TrimRight(cfg.Endpoint, "/"),
+		region:        cfg.Region,
+		publicBaseURL: cfg.PublicBaseURL,
+	}, nil
+}
+
+func (c *S3Client) Upload(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if c == nil || c.client == nil {
+		return "", ErrNotConfigured
+	}
+	if size <= 0 {
+		return "", fmt.Errorf("empty file")
+	}
+
+	// Имя спана по провайдеру: r2.upload, s3.upload, minio.upload
+	ctx, span := tracing.Start(ctx, c.provider+".upload",
+		attribute.String("s3.bucket", c.bucket),
+		attribute.String("s3.key", key),
+		attribute.Int64("s3.size", size),
+	)
+	defer span.End()
+
+	input := &s3.PutObjectInput{
+		Bucket:        &c.bucket,
+		Key:           &key,
+		Body:          body,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+	if _, err := c.client.PutObject(ctx, input); err != nil {
+		tracing.RecordError(span, err)
+		return "", fmt.Errorf("%s upload failed: %w", c.provider, err)
+	}
+	return c.objectURL(key), nil
+}
+
+// Ping проверяет доступность бакета (HeadBucket)
+func (c *S3Client) Ping(ctx context.Context) error {
+	if c == nil || c.client == nil {
+		return ErrNotConfigured
+	}
+	if _, err := c.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &c.bucket}); err != nil {
+		return fmt.Errorf("%s head bucket failed: %w", c.provider, err)
+	}
+	return nil
+}
+
+// Open открывает объект бакета для чтения
+func (c *S3Client) Open(ctx context.Context, key string) (io.ReadCloser, int64, string, error) {
+	if c == nil || c.client == nil {
+		return nil, 0, "", ErrNotConfigured
+	}
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &key})
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("%s get object failed: %w", c.provider, err)
+	}
+	return out.Body, aws.ToInt64(out.ContentLength), aws.ToString(out.ContentType), nil
+}
+
+// List возвращает ключи всех объектов бакета
+func (c *S3Client) List(ctx context.Context) ([]string, error) {
+	if c == nil || c.client == nil {
+		return nil, ErrNotConfigured
+	}
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{Bucket: &c.bucket})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s list objects failed: %w", c.provider, err)
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+// Delete удаляет объект из бакета
+func (c *S3Client) Delete(ctx context.Context, key string) error {
+	if c == nil || c.client == nil {
+		return ErrNotConfigured
+	}
+	if _, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: &c.bucket, Key: &key}); err != nil {
+		return fmt.Errorf("%s delete object failed: %w", c.provider, err)
+	}
+	return nil
+}
+
+// URL возвращает публичный адрес объекта
+func (c *S3Client) URL(key string) string {
+	return c.objectURL(key)
+}
+
+func (c *S3Client) objectURL(key string) string {
+	trimmedKey := strings.TrimLeft(key, "/")
+	switch {
+	case c.publicBaseURL != "":
+		return fmt.Sprintf("%s/%s/%s", c.publicBaseURL, c.bucket, trimmedKey)
+	case c.endpoint != "":
+		return fmt.Sprintf("%s/%s/%s", c.endpoint, c.bucket, trimmedKey)
+	default:
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.bucket, c.region, trimmedKey)
+	}
+}
@@ -0,0 +1,137 @@
+// Package telegram — минимальный клиент Telegram Bot API для уведомлений диспетчеров.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// captionLimit — максимальная длина подписи к фото и файлу в Bot API
+const captionLimit = 1024
+
+// Client отправляет сообщения от имени бота
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// APIError — ошибка, которую вернул Bot API. RetryAfter заполняется при 429.
+type APIError struct {
+	StatusCode  int
+	Description string
+	RetryAfter  time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("telegram api: %d %s (retry after %s)", e.StatusCode, e.Description, e.RetryAfter)
+	}
+	return fmt.Sprintf("telegram api: %d %s", e.StatusCode, e.Description)
+}
+
+// SendMessage отправляет текстовое сообщение
+func (c *Client) SendMessage(ctx context.Context, chatID, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, "sendMessage", "application/json", bytes.NewReader(body))
+}
+
+// SendPhoto отправляет фото (загружается файлом, а не ссылкой: бакет с фото может быть недоступен
+// серверам Telegram) с подписью. Подпись длиннее лимита Bot API обрезается.
+func (c *Client) SendPhoto(ctx context.Context, chatID, caption string, photo []byte) error {
+	return c.sendFile(ctx, "sendPhoto", "photo", chatID, caption, "snapshot.jpg", photo)
+}
+
+// SendDocument отправляет файл filename (выгрузку, отчёт) с подписью
+func (c *Client) SendDocument(ctx context.Context, chatID, caption, filename string, data []byte) error {
+	return c.sendFile(ctx, "sendDocument", "document", chatID, caption, filename, data)
+}
+
+// sendFile загружает файл методом method в поле field формы
+func (c *Client) sendFile(ctx context.Context, method, field, chatID, caption, filename string, data []byte) error {
+	if runes := []rune(caption); len(runes) > captionLimit {
+		caption = string(runes[:captionLimit-1]) + "…"
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("chat_id", chatID)
+	_ = writer.WriteField("caption", caption)
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return c.call(ctx, method, writer.FormDataContentType(), &body)
+}
+
+func (c *Client) call(ctx context.Context, method, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// Ошибка net/http содержит URL запроса, а в нём токен бота
+		return fmt.Errorf("telegram %s request failed: %w", method, stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: unexpected response with status %d", method, resp.StatusCode)
+	}
+	if !result.OK {
+		return &APIError{
+			StatusCode:  resp.StatusCode,
+			Description: result.Description,
+			RetryAfter:  time.Duration(result.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	return nil
+}
+
+// stripURL убирает URL из ошибки *url.Error
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
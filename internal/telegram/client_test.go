@@ -0,0 +1,61 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendMessageRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botsecret-token/sendMessage" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`))
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "secret-token", time.Second).SendMessage(context.Background(), "-100", "hello")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != 7*time.Second {
+		t.Fatalf("err = %v, want APIError with retry after 7s", err)
+	}
+}
+
+func TestRequestErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // соединение будет отклонено
+
+	err := NewClient(srv.URL, "secret-token", time.Second).SendPhoto(context.Background(), "-100", "caption", []byte("jpeg"))
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("err = %v, want error without bot token", err)
+	}
+}
+
+func TestSendDocument(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botsecret-token/sendDocument" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			t.Fatalf("document part: %v", err)
+		}
+		defer file.Close()
+		if header.Filename != "events.csv" || r.FormValue("chat_id") != "-100" || r.FormValue("caption") != "Выгрузка" {
+			t.Errorf("unexpected form: file %q, chat %q, caption %q", header.Filename, r.FormValue("chat_id"), r.FormValue("caption"))
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "secret-token", time.Second).SendDocument(context.Background(), "-100", "Выгрузка", "events.csv", []byte("a,b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,14 +1,71 @@
 package utils
 
 import (
+	"regexp"
 	"strings"
 )
 
+// Страны, номера которых распознаются по формату
+const (
+	PlateCountryKZ = "KZ"
+	PlateCountryRU = "RU"
+	PlateCountryKG = "KG"
+)
+
+// ParsedPlate — нормализованный номер и страна, определённая по его формату.
+// Country и Region пустые, если формат не распознан.
+type ParsedPlate struct {
+	Normalized string
+	Country    string
+	Region     string
+}
+
+// plateLookalikes — кириллические буквы, которые на номерах совпадают по начертанию с латинскими.
+// Камеры читают их то кириллицей, то латиницей; в нормализованном номере всегда латиница.
+var plateLookalikes = strings.NewReplacer(
+	"А", "A", "В", "B", "Е", "E", "К", "K", "М", "M", "Н", "H",
+	"О", "O", "Р", "P", "С", "C", "Т", "T", "У", "Y", "Х", "X",
+)
+
+var (
+	// Казахстан: 123ABC02 (физлица) и 123AB02 (юрлица), регион — последние две цифры; флаг KZ слева
+	kzPlatePattern = regexp.MustCompile(`^(?:KZ)?([0-9]{3}[A-Z]{2,3}([0-9]{2}))$`)
+	// Россия: A123BC77 или A123BC777, только буквы-двойники кириллицы; надпись RUS у региона.
+	// На местах букв камеры часто читают О как ноль — такой номер тоже распознаётся.
+	ruPlatePattern = regexp.MustCompile(`^([ABEKMHOPCTYX0])([0-9]{3})([ABEKMHOPCTYX0]{2})([0-9]{2,3})(?:RUS)?$`)
+	// Кыргызстан: 01KG123ABC, регион — первые две цифры, флаг KG между регионом и номером
+	kgPlatePattern = regexp.MustCompile(`^([0-9]{2})(?:KG)?([0-9]{3}[A-Z]{3})$`)
+)
+
+// NormalizePlate приводит номер к виду, по которому он хранится и ищется (см. ParsePlate)
 func NormalizePlate(raw string) string {
+	return ParsePlate(raw).Normalized
+}
+
+// ParsePlate нормализует номер и определяет страну по формату. Номер без пробелов и дефисов в верхнем
+// регистре сверяется с форматами Казахстана, России и Кыргызстана (с заменой кириллических двойников на
+// латиницу); у распознанного номера убираются надписи флага (KZ, RUS, KG) и исправляются буквы по правилам
+// страны. Номер неизвестного формата только очищается от пробелов и дефисов.
+func ParsePlate(raw string) ParsedPlate {
 	normalized := strings.TrimSpace(raw)
 	normalized = strings.ReplaceAll(normalized, " ", "")
 	normalized = strings.ReplaceAll(normalized, "-", "")
 	normalized = strings.ToUpper(normalized)
-	return normalized
-}
 
+	latin := plateLookalikes.Replace(normalized)
+	if m := kzPlatePattern.FindStringSubmatch(latin); m != nil {
+		return ParsedPlate{Normalized: m[1], Country: PlateCountryKZ, Region: m[2]}
+	}
+	if m := ruPlatePattern.FindStringSubmatch(latin); m != nil {
+		letters := strings.ReplaceAll(m[1]+m[3], "0", "O")
+		return ParsedPlate{
+			Normalized: letters[:1] + m[2] + letters[1:] + m[4],
+			Country:    PlateCountryRU,
+			Region:     m[4],
+		}
+	}
+	if m := kgPlatePattern.FindStringSubmatch(latin); m != nil {
+		return ParsedPlate{Normalized: m[1] + m[2], Country: PlateCountryKG, Region: m[1]}
+	}
+	return ParsedPlate{Normalized: normalized}
+}
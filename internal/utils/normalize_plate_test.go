@@ -52,3 +52,34 @@ func TestNormalizePlate(t *testing.T) {
 	}
 }
 
+func TestParsePlate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		country string
+		region  string
+	}{
+		{name: "kz individual", input: "123 ABC 02", want: "123ABC02", country: PlateCountryKZ, region: "02"},
+		{name: "kz legal entity", input: "123 AB 02", want: "123AB02", country: PlateCountryKZ, region: "02"},
+		{name: "kz flag read by camera", input: "KZ 123 ABC 02", want: "123ABC02", country: PlateCountryKZ, region: "02"},
+		{name: "kz cyrillic lookalikes", input: "123 АВС 02", want: "123ABC02", country: PlateCountryKZ, region: "02"},
+		{name: "ru cyrillic", input: "А 123 ВС 77", want: "A123BC77", country: PlateCountryRU, region: "77"},
+		{name: "ru latin three digit region", input: "a123bc777", want: "A123BC777", country: PlateCountryRU, region: "777"},
+		{name: "ru rus suffix", input: "А123ВС 77 RUS", want: "A123BC77", country: PlateCountryRU, region: "77"},
+		{name: "ru zero read in letter position", input: "0123ОО77", want: "O123OO77", country: PlateCountryRU, region: "77"},
+		{name: "kg", input: "01 KG 123 ABC", want: "01123ABC", country: PlateCountryKG, region: "01"},
+		{name: "kg without flag", input: "01-123-ABC", want: "01123ABC", country: PlateCountryKG, region: "01"},
+		{name: "unknown format keeps characters", input: "ж 12-34", want: "Ж1234"},
+		{name: "empty", input: "  ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParsePlate(tt.input)
+			if got.Normalized != tt.want || got.Country != tt.country || got.Region != tt.region {
+				t.Errorf("ParsePlate(%q) = %+v, want %s (%s, region %q)", tt.input, got, tt.want, tt.country, tt.region)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package weather — клиент почасовой погоды Open-Meteo (https://open-meteo.com) для событий на полигонах.
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// hourLayout — формат времени в ответе Open-Meteo (timezone=GMT)
+const hourLayout = "2006-01-02T15:04"
+
+// Observation — погода за час, начинающийся в Hour (UTC). Значения nil, если провайдер их не знает.
+type Observation struct {
+	Hour         time.Time
+	TemperatureC *float64
+	// SnowfallCm — снег, выпавший за предыдущий час, см
+	SnowfallCm *float64
+}
+
+// Client запрашивает почасовую погоду по координатам
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Hourly возвращает почасовую температуру и снегопад в точке за дни с from по to (UTC, включительно)
+func (c *Client) Hourly(ctx context.Context, latitude, longitude float64, from, to time.Time) ([]Observation, error) {
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(latitude, 'f', 4, 64))
+	query.Set("longitude", strconv.FormatFloat(longitude, 'f', 4, 64))
+	query.Set("hourly", "temperature_2m,snowfall")
+	query.Set("timezone", "GMT")
+	query.Set("start_date", from.UTC().Format("2006-01-02"))
+	query.Set("end_date", to.UTC().Format("2006-01-02"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/forecast?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Reason string `json:"reason"`
+		Hourly struct {
+			Time        []string   `json:"time"`
+			Temperature []*float64 `json:"temperature_2m"`
+			Snowfall    []*float64 `json:"snowfall"`
+		} `json:"hourly"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("weather: unexpected response with status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather: status %d: %s", resp.StatusCode, result.Reason)
+	}
+
+	hourly := result.Hourly
+	observations := make([]Observation, 0, len(hourly.Time))
+	for i, raw := range hourly.Time {
+		hour, err := time.ParseInLocation(hourLayout, raw, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("weather: invalid hour %q: %w", raw, err)
+		}
+		obs := Observation{Hour: hour}
+		if i < len(hourly.Temperature) {
+			obs.TemperatureC = hourly.Temperature[i]
+		}
+		if i < len(hourly.Snowfall) {
+			obs.SnowfallCm = hourly.Snowfall[i]
+		}
+		observations = append(observations, obs)
+	}
+	return observations, nil
+}
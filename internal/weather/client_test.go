@@ -0,0 +1,49 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHourly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/forecast" || q.Get("start_date") != "2025-01-14" || q.Get("end_date") != "2025-01-15" || q.Get("latitude") != "43.2389" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"hourly":{"time":["2025-01-15T21:00","2025-01-15T22:00"],"temperature_2m":[-7.5,null],"snowfall":[0.7,0]}}`))
+	}))
+	defer srv.Close()
+
+	from := time.Date(2025, 1, 14, 22, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 15, 22, 30, 0, 0, time.UTC)
+	got, err := NewClient(srv.URL, time.Second).Hourly(context.Background(), 43.2389, 76.8897, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d observations, want 2", len(got))
+	}
+	if !got[0].Hour.Equal(time.Date(2025, 1, 15, 21, 0, 0, 0, time.UTC)) || *got[0].TemperatureC != -7.5 || *got[0].SnowfallCm != 0.7 {
+		t.Errorf("unexpected first observation %+v", got[0])
+	}
+	if got[1].TemperatureC != nil || got[1].SnowfallCm == nil {
+		t.Errorf("unexpected second observation %+v", got[1])
+	}
+}
+
+func TestHourlyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, time.Second).Hourly(context.Background(), 100, 0, time.Now(), time.Now())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}